@@ -27,13 +27,16 @@ import (
 	cliflag "k8s.io/component-base/cli/flag"
 	"k8s.io/klog/v2"
 
+	"github.com/karmada-io/dashboard/cmd/api/app/routes/backup"
 	packagemgmt "github.com/karmada-io/dashboard/cmd/api/app/routes/mgmt/package"
+	"github.com/karmada-io/dashboard/cmd/api/app/routes/terminal"
 
 	"github.com/karmada-io/dashboard/cmd/api/app/options"
 	"github.com/karmada-io/dashboard/cmd/api/app/router"
 	_ "github.com/karmada-io/dashboard/cmd/api/app/routes/aggregated"               // Importing route packages forces route registration
+	_ "github.com/karmada-io/dashboard/cmd/api/app/routes/apply"                    // Importing route packages forces route registration
+	_ "github.com/karmada-io/dashboard/cmd/api/app/routes/audit"                    // Importing route packages forces route registration
 	_ "github.com/karmada-io/dashboard/cmd/api/app/routes/auth"                     // Importing route packages forces route registration
-	_ "github.com/karmada-io/dashboard/cmd/api/app/routes/backup"                   // Importing route packages forces route registration
 	_ "github.com/karmada-io/dashboard/cmd/api/app/routes/cluster"                  // Importing route packages forces route registration
 	_ "github.com/karmada-io/dashboard/cmd/api/app/routes/clusteroverridepolicy"    // Importing route packages forces route registration
 	_ "github.com/karmada-io/dashboard/cmd/api/app/routes/clusterpropagationpolicy" // Importing route packages forces route registration
@@ -53,17 +56,20 @@ import (
 	_ "github.com/karmada-io/dashboard/cmd/api/app/routes/propagationpolicy"  // Importing route packages forces route registration
 	_ "github.com/karmada-io/dashboard/cmd/api/app/routes/secret"             // Importing route packages forces route registration
 	_ "github.com/karmada-io/dashboard/cmd/api/app/routes/service"            // Importing route packages forces route registration
+	_ "github.com/karmada-io/dashboard/cmd/api/app/routes/setting/group"      // Importing route packages forces route registration
 	_ "github.com/karmada-io/dashboard/cmd/api/app/routes/setting/monitoring" // Importing route packages forces route registration
 	_ "github.com/karmada-io/dashboard/cmd/api/app/routes/setting/user"       // Importing route packages forces route registration
 	_ "github.com/karmada-io/dashboard/cmd/api/app/routes/statefulset"        // Importing route packages forces route registration
-	_ "github.com/karmada-io/dashboard/cmd/api/app/routes/terminal"           // Importing route packages forces route registration
 	_ "github.com/karmada-io/dashboard/cmd/api/app/routes/unstructured"       // Importing route packages forces route registration
+	"github.com/karmada-io/dashboard/pkg/audit"
 	"github.com/karmada-io/dashboard/pkg/auth"
 	"github.com/karmada-io/dashboard/pkg/auth/fga"
+	"github.com/karmada-io/dashboard/pkg/auth/oidc"
 	"github.com/karmada-io/dashboard/pkg/client"
 	"github.com/karmada-io/dashboard/pkg/config"
 	"github.com/karmada-io/dashboard/pkg/environment"
 	"github.com/karmada-io/dashboard/pkg/etcd"
+	"github.com/karmada-io/dashboard/pkg/recording"
 )
 
 // NewAPICommand creates a *cobra.Command object with default parameters
@@ -129,9 +135,45 @@ func run(ctx context.Context, opts *options.Options) error {
 	}
 	klog.InfoS("OpenFGA service initialized", "apiURL", opts.OpenFGAAPIURL)
 
+	// Initialize token signing keys and lifetimes
+	if err := auth.InitSigningKeys(opts.JWTSigningKeys); err != nil {
+		klog.ErrorS(err, "Failed to initialize token signing keys")
+		return err
+	}
+	auth.SetTokenLifetimes(opts.AccessTokenTTL, opts.RefreshTokenTTL)
+	auth.SetPasswordPolicy(auth.PasswordPolicy{
+		MinLength:        opts.PasswordMinLength,
+		RequireMixedCase: opts.PasswordRequireMixedCase,
+		RequireDigit:     opts.PasswordRequireDigit,
+		RequireSymbol:    opts.PasswordRequireSymbol,
+		HistorySize:      opts.PasswordHistory,
+		MaxAge:           opts.PasswordMaxAge,
+		MaxFailedLogins:  opts.LoginMaxFailedAttempts,
+		LockoutDuration:  opts.LoginLockoutDuration,
+		RequireTwoFactor: opts.RequireTwoFactor,
+	})
+
 	// Initialize etcd client for user management
 	initEtcdClient(ctx, opts)
 
+	// Initialize the audit log
+	if err := initAuditLog(ctx, opts); err != nil {
+		klog.ErrorS(err, "Failed to initialize audit log")
+		return err
+	}
+
+	// Initialize terminal session recording
+	if err := initTerminalRecording(opts); err != nil {
+		klog.ErrorS(err, "Failed to initialize terminal recording")
+		return err
+	}
+
+	// Initialize OIDC single sign-on
+	if err := initOIDCProvider(ctx, opts); err != nil {
+		klog.ErrorS(err, "Failed to initialize OIDC provider")
+		return err
+	}
+
 	// Initialize Porch API options
 	if err := initPorchAPI(opts); err != nil {
 		klog.ErrorS(err, "Failed to initialize Porch API")
@@ -139,6 +181,12 @@ func run(ctx context.Context, opts *options.Options) error {
 	}
 
 	ensureAPIServerConnectionOrDie()
+	backup.StartPruner(ctx, opts.BackupPruneInterval)
+	backup.StartNotifier(ctx, opts.BackupScheduleCheckInterval)
+	backup.StartRegistryHealthChecker(ctx, opts.RegistryHealthCheckInterval)
+	terminal.SetIdleTimeout(opts.TerminalIdleTimeout)
+	terminal.StartNodeShellCollector(ctx, opts.NodeShellCollectInterval)
+	client.SetDiscoveryCacheTTL(opts.DiscoveryCacheTTL)
 	serve(opts)
 	config.InitDashboardConfig(client.InClusterClient(), ctx.Done())
 	<-ctx.Done()
@@ -146,6 +194,99 @@ func run(ctx context.Context, opts *options.Options) error {
 	return nil
 }
 
+func initOIDCProvider(ctx context.Context, opts *options.Options) error {
+	if opts.OIDCIssuerURL == "" {
+		klog.InfoS("OIDC issuer URL is not configured. Single sign-on is disabled")
+		return nil
+	}
+
+	groupRoles, err := oidc.ParseGroupRoles(opts.OIDCGroupRoles)
+	if err != nil {
+		return err
+	}
+	clientSecret := opts.OIDCClientSecret
+	if clientSecret == "" {
+		clientSecret = os.Getenv("KARMADA_DASHBOARD_OIDC_CLIENT_SECRET")
+	}
+
+	return oidc.InitOIDCProvider(ctx, oidc.Config{
+		IssuerURL:     opts.OIDCIssuerURL,
+		ClientID:      opts.OIDCClientID,
+		ClientSecret:  clientSecret,
+		RedirectURL:   opts.OIDCRedirectURL,
+		Scopes:        opts.OIDCScopes,
+		UsernameClaim: opts.OIDCUsernameClaim,
+		GroupsClaim:   opts.OIDCGroupsClaim,
+		GroupRoles:    groupRoles,
+	})
+}
+
+func initAuditLog(ctx context.Context, opts *options.Options) error {
+	switch opts.AuditSink {
+	case "none":
+		klog.InfoS("Audit log is disabled")
+	case "file":
+		sink, err := audit.NewFileSink(opts.AuditFilePath)
+		if err != nil {
+			return err
+		}
+		audit.Init(sink)
+		klog.InfoS("Audit log initialized", "sink", "file", "path", opts.AuditFilePath)
+	case "etcd":
+		if auth.GetUserManager() == nil {
+			klog.InfoS("etcd is not available, audit log is disabled")
+			return nil
+		}
+		etcdClient, err := etcd.GetEtcdClient(nil)
+		if err != nil {
+			klog.ErrorS(err, "Failed to get etcd client, audit log is disabled")
+			return nil
+		}
+		sink := audit.NewEtcdSink(etcdClient, opts.AuditRetention)
+		sink.StartPruning(ctx, time.Hour)
+		audit.Init(sink)
+		klog.InfoS("Audit log initialized", "sink", "etcd", "retention", opts.AuditRetention)
+	default:
+		return fmt.Errorf("unknown audit sink %q, expected etcd, file or none", opts.AuditSink)
+	}
+	return nil
+}
+
+func initTerminalRecording(opts *options.Options) error {
+	switch opts.TerminalRecordingStorage {
+	case "none":
+		klog.InfoS("Terminal recording is disabled")
+	case "directory":
+		store, err := recording.NewDirectoryStore(opts.TerminalRecordingDir)
+		if err != nil {
+			return err
+		}
+		recording.Init(store)
+		klog.InfoS("Terminal recording initialized", "storage", "directory", "dir", opts.TerminalRecordingDir)
+	case "s3":
+		accessKeyID := opts.TerminalRecordingS3AccessKey
+		if accessKeyID == "" {
+			accessKeyID = os.Getenv("AWS_ACCESS_KEY_ID")
+		}
+		store, err := recording.NewS3Store(recording.S3Config{
+			Endpoint:        opts.TerminalRecordingS3Endpoint,
+			Bucket:          opts.TerminalRecordingS3Bucket,
+			Region:          opts.TerminalRecordingS3Region,
+			Prefix:          opts.TerminalRecordingS3Prefix,
+			AccessKeyID:     accessKeyID,
+			SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		})
+		if err != nil {
+			return err
+		}
+		recording.Init(store)
+		klog.InfoS("Terminal recording initialized", "storage", "s3", "endpoint", opts.TerminalRecordingS3Endpoint, "bucket", opts.TerminalRecordingS3Bucket)
+	default:
+		return fmt.Errorf("unknown terminal recording storage %q, expected directory, s3 or none", opts.TerminalRecordingStorage)
+	}
+	return nil
+}
+
 func initPorchAPI(opts *options.Options) error {
 	// Initialize package management for Porch API
 	packagemgmt.Initialize(opts)
@@ -165,7 +306,7 @@ func initEtcdClient(ctx context.Context, opts *options.Options) {
 	adminPassword := os.Getenv("KARMADA_DASHBOARD_ADMIN_PASSWORD")
 	if adminPassword == "" {
 		adminPassword = "admin123" // Default admin password if not specified
-		klog.InfoS("Using default admin password for initialization")
+		klog.InfoS("WARNING: KARMADA_DASHBOARD_ADMIN_PASSWORD is not set, bootstrapping the admin user with the default password. It has to be changed on the first login")
 	}
 
 	// Get etcd host and port from command line flags
@@ -272,6 +413,10 @@ func ensureAdminUserCreated(ctx context.Context, adminPassword string) {
 		klog.ErrorS(err, "Failed to create admin user")
 		return
 	}
+	// A bootstrapped password is known to whoever deployed the dashboard
+	if err := userManager.RequirePasswordChange(ctx, "admin"); err != nil {
+		klog.ErrorS(err, "Failed to require password change for admin user")
+	}
 
 	klog.InfoS("Admin user created successfully")
 }
@@ -18,6 +18,7 @@ package options
 
 import (
 	"net"
+	"time"
 
 	"github.com/spf13/pflag"
 )
@@ -42,6 +43,42 @@ type Options struct {
 	OpenFGAAPIURL                 string
 	PorchAPIURL                   string
 	SkipPorchTLSVerify            bool
+	OIDCIssuerURL                 string
+	OIDCClientID                  string
+	OIDCClientSecret              string
+	OIDCRedirectURL               string
+	OIDCScopes                    []string
+	OIDCUsernameClaim             string
+	OIDCGroupsClaim               string
+	OIDCGroupRoles                []string
+	JWTSigningKeys                []string
+	AccessTokenTTL                time.Duration
+	RefreshTokenTTL               time.Duration
+	PasswordMinLength             int
+	PasswordRequireMixedCase      bool
+	PasswordRequireDigit          bool
+	PasswordRequireSymbol         bool
+	PasswordHistory               int
+	PasswordMaxAge                time.Duration
+	LoginMaxFailedAttempts        int
+	LoginLockoutDuration          time.Duration
+	RequireTwoFactor              bool
+	AuditSink                     string
+	AuditFilePath                 string
+	AuditRetention                time.Duration
+	BackupPruneInterval           time.Duration
+	BackupScheduleCheckInterval   time.Duration
+	RegistryHealthCheckInterval   time.Duration
+	TerminalRecordingStorage      string
+	TerminalRecordingDir          string
+	TerminalRecordingS3Endpoint   string
+	TerminalRecordingS3Bucket     string
+	TerminalRecordingS3Region     string
+	TerminalRecordingS3Prefix     string
+	TerminalRecordingS3AccessKey  string
+	TerminalIdleTimeout           time.Duration
+	NodeShellCollectInterval      time.Duration
+	DiscoveryCacheTTL             time.Duration
 }
 
 // NewOptions returns initialized Options.
@@ -72,4 +109,40 @@ func (o *Options) AddFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&o.OpenFGAAPIURL, "openfga-api-url", "http://openfga.karmada-system.svc:8080", "The URL for the OpenFGA API server")
 	fs.StringVar(&o.PorchAPIURL, "porch-api", "", "The URL for the Porch API server")
 	fs.BoolVar(&o.SkipPorchTLSVerify, "skip-porch-tls-verify", false, "Skip TLS certificate verification when connecting to the Porch API")
+	fs.StringVar(&o.OIDCIssuerURL, "oidc-issuer-url", "", "The URL of the OIDC identity provider. Single sign-on is disabled when empty")
+	fs.StringVar(&o.OIDCClientID, "oidc-client-id", "", "The client ID of the dashboard at the OIDC identity provider")
+	fs.StringVar(&o.OIDCClientSecret, "oidc-client-secret", "", "The client secret of the dashboard at the OIDC identity provider, defaults to the KARMADA_DASHBOARD_OIDC_CLIENT_SECRET environment variable")
+	fs.StringVar(&o.OIDCRedirectURL, "oidc-redirect-url", "", "The callback URL registered at the OIDC identity provider, e.g. https://dashboard.example.com/api/v1/oidc/callback")
+	fs.StringSliceVar(&o.OIDCScopes, "oidc-scopes", []string{"profile", "email", "groups"}, "Scopes requested from the OIDC identity provider in addition to openid")
+	fs.StringVar(&o.OIDCUsernameClaim, "oidc-username-claim", "email", "The ID token claim used as dashboard username")
+	fs.StringVar(&o.OIDCGroupsClaim, "oidc-groups-claim", "groups", "The ID token claim that lists the groups of the user")
+	fs.StringSliceVar(&o.OIDCGroupRoles, "oidc-group-roles", nil, "Mappings of identity provider groups to dashboard roles as <group>=<role>, where role is admin, basic_user or group:<dashboard group>. When set, the role of OIDC users is updated on every login")
+	fs.StringSliceVar(&o.JWTSigningKeys, "jwt-signing-keys", nil, "Files with PEM encoded RSA, ECDSA P-256 or Ed25519 private keys for signing dashboard tokens. The first key signs new tokens, the others are only accepted for verification. Defaults to the HMAC secret from KARMADA_DASHBOARD_JWT_SECRET")
+	fs.DurationVar(&o.AccessTokenTTL, "access-token-ttl", 15*time.Minute, "How long dashboard access tokens are valid")
+	fs.DurationVar(&o.RefreshTokenTTL, "refresh-token-ttl", 7*24*time.Hour, "How long a login session can be refreshed without signing in again")
+	fs.IntVar(&o.PasswordMinLength, "password-min-length", 8, "Minimum length of user passwords")
+	fs.BoolVar(&o.PasswordRequireMixedCase, "password-require-mixed-case", true, "Require upper and lower case letters in user passwords")
+	fs.BoolVar(&o.PasswordRequireDigit, "password-require-digit", true, "Require a digit in user passwords")
+	fs.BoolVar(&o.PasswordRequireSymbol, "password-require-symbol", false, "Require a symbol in user passwords")
+	fs.IntVar(&o.PasswordHistory, "password-history", 5, "Number of previous passwords a user cannot use again, 0 allows reuse")
+	fs.DurationVar(&o.PasswordMaxAge, "password-max-age", 0, "How long a password is valid before it has to be changed, 0 disables expiry")
+	fs.IntVar(&o.LoginMaxFailedAttempts, "login-max-failed-attempts", 5, "Consecutive failed logins after which a user is locked out, 0 disables lockout")
+	fs.DurationVar(&o.LoginLockoutDuration, "login-lockout-duration", 15*time.Minute, "How long a user stays locked out after too many failed logins")
+	fs.BoolVar(&o.RequireTwoFactor, "require-2fa", false, "Require TOTP two-factor authentication for password users, who have to enroll on their next login")
+	fs.StringVar(&o.AuditSink, "audit-sink", "etcd", "Where the audit log of mutating API calls is kept: etcd, file or none")
+	fs.StringVar(&o.AuditFilePath, "audit-file-path", "/var/log/karmada-dashboard/audit.log", "The JSON lines file the audit log is appended to when --audit-sink is file")
+	fs.DurationVar(&o.AuditRetention, "audit-retention", 30*24*time.Hour, "How long audit events are kept in etcd, 0 keeps them forever")
+	fs.DurationVar(&o.BackupPruneInterval, "backup-prune-interval", time.Hour, "How often the retention policies of backups are applied, 0 disables automatic pruning")
+	fs.DurationVar(&o.BackupScheduleCheckInterval, "backup-schedule-check-interval", 5*time.Minute, "How often scheduled backups are checked for missed runs to notify of, 0 disables the checks")
+	fs.DurationVar(&o.RegistryHealthCheckInterval, "registry-health-check-interval", time.Hour, "How often the credentials of backup registries are tested, 0 disables the checks")
+	fs.StringVar(&o.TerminalRecordingStorage, "terminal-recording-storage", "none", "Where recordings of terminal sessions are stored: directory, s3 or none. With none, sessions cannot be recorded and sessions a recording policy applies to are refused")
+	fs.StringVar(&o.TerminalRecordingDir, "terminal-recording-dir", "/var/lib/karmada-dashboard/recordings", "The directory, usually on a persistent volume, terminal recordings are stored in when --terminal-recording-storage is directory")
+	fs.StringVar(&o.TerminalRecordingS3Endpoint, "terminal-recording-s3-endpoint", "", "The URL of the S3 compatible object store terminal recordings are stored in when --terminal-recording-storage is s3, e.g. https://s3.eu-west-1.amazonaws.com")
+	fs.StringVar(&o.TerminalRecordingS3Bucket, "terminal-recording-s3-bucket", "", "The bucket terminal recordings are stored in")
+	fs.StringVar(&o.TerminalRecordingS3Region, "terminal-recording-s3-region", "us-east-1", "The region of the terminal recording bucket")
+	fs.StringVar(&o.TerminalRecordingS3Prefix, "terminal-recording-s3-prefix", "terminal-recordings/", "The prefix of the terminal recording objects in the bucket")
+	fs.StringVar(&o.TerminalRecordingS3AccessKey, "terminal-recording-s3-access-key-id", "", "The access key ID for the terminal recording bucket, defaults to the AWS_ACCESS_KEY_ID environment variable. The secret access key is read from AWS_SECRET_ACCESS_KEY")
+	fs.DurationVar(&o.TerminalIdleTimeout, "terminal-idle-timeout", 30*time.Minute, "How long pod and node terminal sessions stay open without input, 0 keeps them open")
+	fs.DurationVar(&o.NodeShellCollectInterval, "node-shell-collect-interval", 5*time.Minute, "How often node shell pods whose session is gone are deleted from every cluster, 0 disables the collection")
+	fs.DurationVar(&o.DiscoveryCacheTTL, "discovery-cache-ttl", 10*time.Minute, "How long the discovered resource types of a cluster are cached for raw resource requests")
 }
@@ -0,0 +1,189 @@
+/*
+Copyright 2024 The Karmada Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/karmada-io/dashboard/pkg/audit"
+	"github.com/karmada-io/dashboard/pkg/auth"
+)
+
+const (
+	// maxAuditedBodySize bounds how much of a request body is read for the audit summary
+	maxAuditedBodySize = 1 << 20
+	// maxAuditedResponseSize bounds how much of a response is kept to find the outcome
+	maxAuditedResponseSize = 64 << 10
+)
+
+// unauditedBodyPaths are the routes whose request bodies carry credentials, only their outcome is
+// recorded.
+var unauditedBodyPaths = []string{"/login", "/logout", "/token", "/password", "/2fa", "/init-token", "/oidc", "/setting/user"}
+
+// auditResponseWriter keeps the start of the response, since handlers report failures with
+// HTTP 200 and an error code in the body.
+type auditResponseWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *auditResponseWriter) Write(b []byte) (int, error) {
+	w.capture(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *auditResponseWriter) WriteString(s string) (int, error) {
+	w.capture([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *auditResponseWriter) capture(b []byte) {
+	if room := maxAuditedResponseSize - w.body.Len(); room > 0 {
+		if len(b) > room {
+			b = b[:room]
+		}
+		w.body.Write(b)
+	}
+}
+
+// AuditMiddleware records every mutating API call: who made it, from where, what it targeted and
+// how it ended. Request bodies are summarized by their fields, never by their values.
+func AuditMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !isMutatingMethod(c.Request.Method) || !audit.Enabled() {
+			c.Next()
+			return
+		}
+
+		start := time.Now()
+		var summary *audit.RequestSummary
+		if c.Request.Body != nil && !hasUnauditedBody(c.Request.URL.Path) {
+			body, _ := io.ReadAll(io.LimitReader(c.Request.Body, maxAuditedBodySize+1))
+			// Hand the handler the whole body, including what was not read here
+			c.Request.Body = struct {
+				io.Reader
+				io.Closer
+			}{io.MultiReader(bytes.NewReader(body), c.Request.Body), c.Request.Body}
+			truncated := len(body) > maxAuditedBodySize
+			if truncated {
+				body = body[:maxAuditedBodySize]
+			}
+			summary = audit.Summarize(body, truncated)
+		}
+
+		writer := &auditResponseWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		c.Next()
+
+		event := auditEvent(c, summary)
+		event.Timestamp = start
+		event.LatencyMs = time.Since(start).Milliseconds()
+		event.StatusCode = writer.Status()
+		event.Outcome, event.Error = auditOutcome(writer.Status(), writer.body.Bytes())
+		if event.Error == "" && len(c.Errors) > 0 {
+			event.Error = c.Errors.String()
+		}
+		audit.Record(event)
+	}
+}
+
+// auditEvent describes the request of c and its target.
+func auditEvent(c *gin.Context, summary *audit.RequestSummary) *audit.Event {
+	user := auth.UserFromContext(c)
+	event := &audit.Event{
+		User:      user,
+		APIToken:  auth.APITokenFromContext(c),
+		SourceIP:  c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+		Verb:      audit.VerbForMethod(c.Request.Method),
+		Method:    c.Request.Method,
+		Route:     c.FullPath(),
+		Path:      c.Request.URL.Path,
+		Cluster:   c.Param("clustername"),
+		Request:   summary,
+	}
+
+	route := strings.TrimPrefix(c.FullPath(), v1.BasePath())
+	if rest, ok := strings.CutPrefix(route, "/member/:clustername"); ok {
+		route = rest
+	} else if rest, ok := strings.CutPrefix(route, "/mgmt-cluster"); ok {
+		route = rest
+		event.Cluster = "mgmt-cluster"
+	}
+	event.Resource, _, _ = strings.Cut(strings.TrimPrefix(route, "/"), "/")
+	if event.Resource == "_raw" {
+		event.Resource = strings.ToLower(c.Param("kind"))
+	}
+
+	event.Namespace = c.Param("namespace")
+	event.Name = c.Param("name")
+	if event.Name == "" {
+		// Routes name their target parameter after the resource, e.g. :deployment or :crdName
+		for _, param := range c.Params {
+			switch param.Key {
+			case "clustername", "namespace", "kind":
+			default:
+				event.Name = param.Value
+			}
+		}
+	}
+	if event.Resource == "namespace" && event.Namespace == "" {
+		event.Namespace = event.Name
+	}
+	if summary != nil {
+		if event.Namespace == "" {
+			event.Namespace = summary.Namespace
+		}
+		if event.Name == "" {
+			event.Name = summary.Name
+		}
+	}
+	return event
+}
+
+// auditOutcome tells from the response whether a request succeeded, and why not.
+func auditOutcome(status int, body []byte) (string, string) {
+	response := struct {
+		Code int    `json:"code"`
+		Msg  string `json:"message"`
+	}{}
+	_ = json.Unmarshal(body, &response)
+
+	if status >= http.StatusBadRequest {
+		return audit.OutcomeFailure, response.Msg
+	}
+	if response.Code != 0 && response.Code != http.StatusOK {
+		return audit.OutcomeFailure, response.Msg
+	}
+	return audit.OutcomeSuccess, ""
+}
+
+func hasUnauditedBody(path string) bool {
+	for _, p := range unauditedBodyPaths {
+		if strings.Contains(path, p) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2024 The Karmada Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/karmada-io/dashboard/pkg/audit"
+)
+
+func TestAuditEvent(t *testing.T) {
+	cases := []struct {
+		method            string
+		route             string
+		path              string
+		body              string
+		expectedCluster   string
+		expectedNamespace string
+		expectedResource  string
+		expectedName      string
+		expectedFields    []string
+	}{
+		{http.MethodPut, "/api/v1/member/:clustername/_raw/:kind/:namespace/:name", "/api/v1/member/m1/_raw/ConfigMap/team-a/cfg",
+			`{"kind":"ConfigMap","metadata":{"name":"cfg"},"data":{"password":"secret"}}`,
+			"m1", "team-a", "configmap", "cfg", []string{"data.password", "kind", "metadata.name"}},
+		{http.MethodPost, "/api/v1/member/:clustername/deployment/:namespace/:deployment/restart", "/api/v1/member/m1/deployment/team-a/web/restart", "",
+			"m1", "team-a", "deployment", "web", nil},
+		{http.MethodDelete, "/api/v1/mgmt-cluster/_raw/:kind/name/:name", "/api/v1/mgmt-cluster/_raw/Namespace/name/team-a", "",
+			"mgmt-cluster", "team-a", "namespace", "team-a", nil},
+		{http.MethodPost, "/api/v1/propagationpolicy", "/api/v1/propagationpolicy",
+			`{"namespace":"team-a","name":"pp","propagationData":"apiVersion: v1"}`,
+			"", "team-a", "propagationpolicy", "pp", []string{"name", "namespace", "propagationData"}},
+	}
+
+	gin.SetMode(gin.TestMode)
+	for _, c := range cases {
+		var event *audit.Event
+		engine := gin.New()
+		engine.Handle(c.method, c.route, func(ctx *gin.Context) {
+			event = auditEvent(ctx, audit.Summarize([]byte(c.body), false))
+		})
+		engine.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(c.method, c.path, nil))
+
+		if event == nil {
+			t.Fatalf("%s %s: route did not match", c.method, c.path)
+		}
+		if event.Cluster != c.expectedCluster || event.Namespace != c.expectedNamespace ||
+			event.Resource != c.expectedResource || event.Name != c.expectedName {
+			t.Errorf("%s %s: got target (%q, %q, %q, %q), expected (%q, %q, %q, %q)", c.method, c.path,
+				event.Cluster, event.Namespace, event.Resource, event.Name,
+				c.expectedCluster, c.expectedNamespace, c.expectedResource, c.expectedName)
+		}
+		var fields []string
+		if event.Request != nil {
+			fields = event.Request.Fields
+		}
+		if !reflect.DeepEqual(fields, c.expectedFields) {
+			t.Errorf("%s %s: got fields %v, expected %v", c.method, c.path, fields, c.expectedFields)
+		}
+	}
+}
+
+func TestAuditOutcome(t *testing.T) {
+	cases := []struct {
+		status          int
+		body            string
+		expectedOutcome string
+		expectedError   string
+	}{
+		{http.StatusOK, `{"code":200,"message":"success","data":null}`, audit.OutcomeSuccess, ""},
+		{http.StatusOK, `{"code":500,"message":"not found","data":null}`, audit.OutcomeFailure, "not found"},
+		{http.StatusForbidden, `{"code":403,"message":"forbidden"}`, audit.OutcomeFailure, "forbidden"},
+		{http.StatusNoContent, ``, audit.OutcomeSuccess, ""},
+	}
+
+	for _, c := range cases {
+		outcome, errorMessage := auditOutcome(c.status, []byte(c.body))
+		if outcome != c.expectedOutcome || errorMessage != c.expectedError {
+			t.Errorf("%d %s: got (%q, %q), expected (%q, %q)", c.status, c.body, outcome, errorMessage, c.expectedOutcome, c.expectedError)
+		}
+	}
+}
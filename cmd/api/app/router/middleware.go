@@ -18,18 +18,83 @@ package router
 
 import (
 	"context"
+	"fmt"
 	"net/http"
+	"slices"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 
 	"github.com/karmada-io/dashboard/cmd/api/app/types/common"
+	"github.com/karmada-io/dashboard/pkg/auth"
 	"github.com/karmada-io/dashboard/pkg/auth/fga"
 	"github.com/karmada-io/dashboard/pkg/client"
+	"github.com/karmada-io/dashboard/pkg/etcd"
 	utilauth "github.com/karmada-io/dashboard/pkg/util/utilauth"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/klog/v2"
 )
 
+// apiTokenDeniedPaths are the routes API tokens cannot call: those managing credentials, so that
+// a leaked token cannot be turned into a login or a token with more scopes.
+var apiTokenDeniedPaths = []string{"/api-tokens", "/login", "/logout", "/token", "/password", "/2fa", "/init-token", "/oidc", "/setting/user", "/setting/api-tokens"}
+
+// apiTokenExecPaths are the routes that open shells in containers or on nodes. They are upgraded
+// from GET requests but run arbitrary commands, so API tokens need the write scope for them.
+var apiTokenExecPaths = []string{"/terminal", "/node-terminal"}
+
+// IdentityMiddleware resolves the authenticated caller once per request and stores it in the
+// request context, so that clients created further down the call chain act on behalf of that
+// caller instead of any process-wide state. Callers using an API token act as the token owner
+// within the scopes of the token.
+func IdentityMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if token := client.GetBearerToken(c.Request); auth.IsAPIToken(token) {
+			apiToken, err := auth.AuthenticateAPIToken(c, token)
+			if err != nil {
+				c.AbortWithStatusJSON(http.StatusUnauthorized, common.BaseResponse{
+					Code: http.StatusUnauthorized,
+					Msg:  err.Error(),
+				})
+				return
+			}
+			if err := authorizeAPITokenRequest(c, apiToken); err != nil {
+				klog.InfoS("API token request denied", "id", apiToken.ID, "username", apiToken.Username, "path", c.FullPath(), "reason", err.Error())
+				c.AbortWithStatusJSON(http.StatusForbidden, common.BaseResponse{
+					Code: http.StatusForbidden,
+					Msg:  err.Error(),
+				})
+				return
+			}
+			ctx := auth.WithAPIToken(auth.WithUser(c.Request.Context(), apiToken.Username), apiToken.ID)
+			// Clusters reached outside of /member/:clustername, e.g. by aggregated lists, are checked
+			// against the token when their clients are created
+			ctx = auth.WithAPITokenClusters(ctx, apiToken.Clusters)
+			c.Request = c.Request.WithContext(ctx)
+			c.Next()
+			return
+		}
+
+		if username := utilauth.GetAuthenticatedUser(c); username != "" {
+			c.Request = c.Request.WithContext(auth.WithUser(c.Request.Context(), username))
+		}
+		c.Next()
+	}
+}
+
+// authorizeAPITokenRequest checks the route and method of c against the scopes of an API token.
+func authorizeAPITokenRequest(c *gin.Context, apiToken *etcd.APIToken) error {
+	route := strings.TrimPrefix(c.FullPath(), v1.BasePath())
+	for _, denied := range apiTokenDeniedPaths {
+		if strings.HasPrefix(route, denied) {
+			return fmt.Errorf("API tokens cannot be used to manage credentials")
+		}
+	}
+	mutating := isMutatingMethod(c.Request.Method) || slices.Contains(apiTokenExecPaths, route)
+	return auth.AuthorizeAPIToken(apiToken, mutating, c.Param("clustername"))
+}
+
 // EnsureMemberClusterMiddleware ensures that the member cluster exists.
 func EnsureMemberClusterMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -46,6 +111,138 @@ func EnsureMemberClusterMiddleware() gin.HandlerFunc {
 	}
 }
 
+// EnsureMemberClusterAccessMiddleware ensures that the caller is authorized for the member cluster in the path.
+// Read-only requests and diffs require the viewer relation, restarts require operator and every other mutating
+// request (raw resource writes, namespace create/delete and so on) requires editor. Requests for a single
+// namespace are checked against the namespace and resource kind, so grants scoped to them apply as well
+// as cluster-wide ones. Dashboard admins are allowed everything.
+func EnsureMemberClusterAccessMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		clusterName := c.Param("clustername")
+		username := auth.UserFromContext(c)
+		if username == "" {
+			klog.InfoS("No authenticated user for member cluster access", "cluster", clusterName)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, common.BaseResponse{
+				Code: http.StatusUnauthorized,
+				Msg:  "Authentication required for member cluster access",
+			})
+			return
+		}
+
+		if fga.FGAService == nil || fga.FGAService.GetClient() == nil {
+			klog.ErrorS(nil, "OpenFGA service not available for member cluster access check")
+			c.AbortWithStatusJSON(http.StatusInternalServerError, common.BaseResponse{
+				Code: http.StatusInternalServerError,
+				Msg:  "Authorization service unavailable",
+			})
+			return
+		}
+
+		relation := requiredMemberRelation(c)
+		namespace, kind := memberRequestScope(c)
+		var allowed bool
+		var err error
+		switch {
+		case namespace == "":
+			allowed, err = fga.HasClusterPermission(c, fga.FGAService.GetClient(), username, relation, clusterName)
+		case kind == "":
+			allowed, err = fga.HasNamespacePermission(c, fga.FGAService.GetClient(), username, relation, clusterName, namespace)
+		default:
+			allowed, err = fga.HasResourcePermission(c, fga.FGAService.GetClient(), username, relation, clusterName, namespace, kind)
+		}
+		if err != nil {
+			klog.ErrorS(err, "Failed to check member cluster access", "username", username, "cluster", clusterName)
+			c.AbortWithStatusJSON(http.StatusInternalServerError, common.BaseResponse{
+				Code: http.StatusInternalServerError,
+				Msg:  "Failed to verify cluster permissions",
+			})
+			return
+		}
+
+		if !allowed {
+			klog.InfoS("User is not allowed to access member cluster", "username", username, "cluster", clusterName, "method", c.Request.Method)
+			c.AbortWithStatusJSON(http.StatusForbidden, common.BaseResponse{
+				Code: http.StatusForbidden,
+				Msg:  fmt.Sprintf("User %s is not allowed to %s resources in cluster %s", username, verbForMethod(c.Request.Method), clusterName),
+			})
+			return
+		}
+
+		// Handlers create clients of the cluster for the scope authorized here
+		c.Request = c.Request.WithContext(auth.WithAuthorizedCluster(c.Request.Context(), clusterName))
+		c.Next()
+	}
+}
+
+// requiredMemberRelation returns the relation the caller needs for the member cluster request.
+func requiredMemberRelation(c *gin.Context) string {
+	switch {
+	case !isMutatingMethod(c.Request.Method):
+		return fga.RelationViewer
+	case strings.HasSuffix(c.FullPath(), "/diff"):
+		// Diffs are computed with a server-side dry run and change nothing
+		return fga.RelationViewer
+	case strings.HasSuffix(c.FullPath(), "/restart"):
+		return fga.RelationOperator
+	default:
+		return fga.RelationEditor
+	}
+}
+
+// memberMapping resolves the kinds of the raw resource routes of member clusters.
+var memberMapping = client.MemberMapping
+
+// memberRequestScope returns the namespace and resource kind a member cluster request is limited to.
+// Both are empty for cluster-wide requests, such as listing across namespaces or creating a namespace,
+// and kind is empty for requests on a namespace itself. The kinds of raw resource routes, which may
+// be given as resources or short names, are resolved to the lowercase kind grants are made for, e.g.
+// "configmap" for "cm". Kinds that cannot be resolved, and cluster-scoped ones, take cluster-wide
+// access.
+func memberRequestScope(c *gin.Context) (namespace, kind string) {
+	// The first path segment after /member/:clustername names the resource kind, e.g. "deployment"
+	_, rest, _ := strings.Cut(c.FullPath(), "/:clustername/")
+	segment, _, _ := strings.Cut(rest, "/")
+	if segment == "namespace" {
+		if isMutatingMethod(c.Request.Method) {
+			return "", ""
+		}
+		return c.Param("name"), ""
+	}
+	if namespace = c.Param("namespace"); namespace == "" {
+		return "", ""
+	}
+	if segment == "_raw" {
+		mapping, err := memberMapping(c.Param("clustername"), c.Param("group"), c.Param("version"), c.Param("kind"))
+		if err != nil {
+			klog.V(4).InfoS("Failed to resolve kind, requiring cluster-wide access", "cluster", c.Param("clustername"), "kind", c.Param("kind"), "err", err)
+			return "", ""
+		}
+		if mapping.Scope.Name() != meta.RESTScopeNameNamespace {
+			return "", ""
+		}
+		segment = mapping.GroupVersionKind.Kind
+	}
+	return namespace, strings.ToLower(segment)
+}
+
+// isMutatingMethod reports whether the HTTP method changes state on the target cluster.
+func isMutatingMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return false
+	default:
+		return true
+	}
+}
+
+// verbForMethod returns a human-readable verb for the HTTP method used in error messages.
+func verbForMethod(method string) string {
+	if isMutatingMethod(method) {
+		return "modify"
+	}
+	return "view"
+}
+
 // EnsureMgmtAdminMiddleware ensures that the user is a dashboard admin.
 func EnsureMgmtAdminMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -95,3 +292,48 @@ func EnsureMgmtAdminMiddleware() gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// EnsureDashboardAdminMiddleware ensures that the user is a dashboard admin for administrative
+// endpoints outside the management cluster, such as group management.
+func EnsureDashboardAdminMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		username := auth.UserFromContext(c)
+		if username == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, common.BaseResponse{
+				Code: http.StatusUnauthorized,
+				Msg:  "Authentication required",
+			})
+			return
+		}
+
+		if fga.FGAService == nil || fga.FGAService.GetClient() == nil {
+			klog.ErrorS(nil, "OpenFGA service not available for admin check")
+			c.AbortWithStatusJSON(http.StatusInternalServerError, common.BaseResponse{
+				Code: http.StatusInternalServerError,
+				Msg:  "Authorization service unavailable",
+			})
+			return
+		}
+
+		isAdmin, err := fga.IsDashboardAdmin(c, fga.FGAService.GetClient(), username)
+		if err != nil {
+			klog.ErrorS(err, "Failed to check if user is admin", "username", username)
+			c.AbortWithStatusJSON(http.StatusInternalServerError, common.BaseResponse{
+				Code: http.StatusInternalServerError,
+				Msg:  "Failed to verify administrator permissions",
+			})
+			return
+		}
+
+		if !isAdmin {
+			klog.InfoS("User is not admin", "username", username, "path", c.FullPath())
+			c.AbortWithStatusJSON(http.StatusForbidden, common.BaseResponse{
+				Code: http.StatusForbidden,
+				Msg:  "Administrator permissions required",
+			})
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,139 @@
+/*
+Copyright 2024 The Karmada Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/karmada-io/dashboard/pkg/auth"
+	"github.com/karmada-io/dashboard/pkg/etcd"
+)
+
+// useTestMemberMapping resolves the kinds of raw resource routes from a fixed set of resources.
+func useTestMemberMapping(t *testing.T) {
+	resources := map[string]*meta.RESTMapping{}
+	for _, r := range []struct {
+		gvk     schema.GroupVersionKind
+		scope   meta.RESTScope
+		aliases []string
+	}{
+		{schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}, meta.RESTScopeNamespace, []string{"configmap", "configmaps", "cm"}},
+		{schema.GroupVersionKind{Version: "v1", Kind: "Node"}, meta.RESTScopeRoot, []string{"node", "nodes", "no"}},
+		{schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}, meta.RESTScopeNamespace, []string{"deployment", "deployments", "deploy"}},
+	} {
+		for _, alias := range r.aliases {
+			resources[alias] = &meta.RESTMapping{GroupVersionKind: r.gvk, Scope: r.scope}
+		}
+	}
+
+	previous := memberMapping
+	memberMapping = func(_, group, version, kind string) (*meta.RESTMapping, error) {
+		mapping, found := resources[strings.ToLower(kind)]
+		if group == "core" {
+			group = ""
+		}
+		if !found || (version != "" && (mapping.GroupVersionKind.Group != group || mapping.GroupVersionKind.Version != version)) {
+			return nil, &meta.NoKindMatchError{GroupKind: schema.GroupKind{Group: group, Kind: kind}}
+		}
+		return mapping, nil
+	}
+	t.Cleanup(func() { memberMapping = previous })
+}
+
+func TestMemberRequestScope(t *testing.T) {
+	useTestMemberMapping(t)
+	cases := []struct {
+		method            string
+		route             string
+		path              string
+		expectedNamespace string
+		expectedKind      string
+		expectedRelation  string
+	}{
+		{http.MethodGet, "/member/:clustername/deployment", "/member/m1/deployment", "", "", "viewer"},
+		{http.MethodGet, "/member/:clustername/deployment/:namespace", "/member/m1/deployment/team-a", "team-a", "deployment", "viewer"},
+		{http.MethodPost, "/member/:clustername/deployment/:namespace/:deployment/restart", "/member/m1/deployment/team-a/web/restart", "team-a", "deployment", "operator"},
+		{http.MethodPut, "/member/:clustername/_raw/:kind/:namespace/:name", "/member/m1/_raw/ConfigMap/team-a/cfg", "team-a", "configmap", "editor"},
+		{http.MethodDelete, "/member/:clustername/_raw/:kind/name/:name", "/member/m1/_raw/node/name/n1", "", "", "editor"},
+		{http.MethodPost, "/member/:clustername/_raw/:kind/:namespace/:name/diff", "/member/m1/_raw/ConfigMap/team-a/cfg/diff", "team-a", "configmap", "viewer"},
+		{http.MethodGet, "/member/:clustername/_raw/gvk/:group/:version/:kind/:namespace/:name", "/member/m1/_raw/gvk/apps/v1/Deployment/team-a/web", "team-a", "deployment", "viewer"},
+		// Aliases are authorized as the kind they name
+		{http.MethodPut, "/member/:clustername/_raw/:kind/:namespace/:name", "/member/m1/_raw/cm/team-a/cfg", "team-a", "configmap", "editor"},
+		{http.MethodGet, "/member/:clustername/_raw/:kind/:namespace/:name", "/member/m1/_raw/deployments/team-a/web", "team-a", "deployment", "viewer"},
+		{http.MethodDelete, "/member/:clustername/_raw/gvk/:group/:version/:kind/:namespace/:name", "/member/m1/_raw/gvk/core/v1/configmaps/team-a/cfg", "team-a", "configmap", "editor"},
+		// Unknown and cluster-scoped kinds take cluster-wide access
+		{http.MethodGet, "/member/:clustername/_raw/:kind/:namespace/:name", "/member/m1/_raw/widget/team-a/w1", "", "", "viewer"},
+		{http.MethodGet, "/member/:clustername/_raw/gvk/:group/:version/:kind/:namespace/:name", "/member/m1/_raw/gvk/apps/v2/Deployment/team-a/web", "", "", "viewer"},
+		{http.MethodPut, "/member/:clustername/_raw/:kind/:namespace/:name", "/member/m1/_raw/node/team-a/n1", "", "", "editor"},
+		{http.MethodGet, "/member/:clustername/namespace/:name", "/member/m1/namespace/team-a", "team-a", "", "viewer"},
+		{http.MethodDelete, "/member/:clustername/namespace/:name", "/member/m1/namespace/team-a", "", "", "editor"},
+		{http.MethodGet, "/member/:clustername/node/:nodename", "/member/m1/node/n1", "", "", "viewer"},
+	}
+
+	gin.SetMode(gin.TestMode)
+	for _, c := range cases {
+		var namespace, kind, relation string
+		engine := gin.New()
+		engine.Handle(c.method, c.route, func(ctx *gin.Context) {
+			namespace, kind = memberRequestScope(ctx)
+			relation = requiredMemberRelation(ctx)
+		})
+		engine.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(c.method, c.path, nil))
+
+		if namespace != c.expectedNamespace || kind != c.expectedKind || relation != c.expectedRelation {
+			t.Errorf("%s %s: got (%q, %q, %q), expected (%q, %q, %q)", c.method, c.path,
+				namespace, kind, relation, c.expectedNamespace, c.expectedKind, c.expectedRelation)
+		}
+	}
+}
+
+func TestAuthorizeAPITokenRequest(t *testing.T) {
+	readOnly := &etcd.APIToken{Name: "ci", Scopes: []string{auth.ScopeRead}}
+	cases := []struct {
+		method  string
+		route   string
+		path    string
+		allowed bool
+	}{
+		{http.MethodGet, "/api/v1/cluster", "/api/v1/cluster", true},
+		{http.MethodPost, "/api/v1/apply", "/api/v1/apply", false},
+		{http.MethodGet, "/api/v1/terminal", "/api/v1/terminal?cluster=m1", false},
+		{http.MethodGet, "/api/v1/node-terminal", "/api/v1/node-terminal?cluster=m1", false},
+		{http.MethodGet, "/api/v1/setting/api-tokens", "/api/v1/setting/api-tokens", false},
+	}
+
+	gin.SetMode(gin.TestMode)
+	for _, c := range cases {
+		var err error
+		engine := gin.New()
+		engine.Handle(c.method, c.route, func(ctx *gin.Context) {
+			err = authorizeAPITokenRequest(ctx, readOnly)
+		})
+		engine.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(c.method, c.path, nil))
+
+		if (err == nil) != c.allowed {
+			t.Errorf("%s %s: got %v, expected allowed %v", c.method, c.path, err, c.allowed)
+		}
+	}
+}
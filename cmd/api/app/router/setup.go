@@ -37,10 +37,11 @@ func init() {
 	router = gin.Default()
 	_ = router.SetTrustedProxies(nil)
 	v1 = router.Group("/api/v1")
+	v1.Use(IdentityMiddleware(), AuditMiddleware())
 	
-	// Member cluster routes with middleware to ensure cluster exists
+	// Member cluster routes with middleware to ensure the caller is authorized and the cluster exists
 	member = v1.Group("/member/:clustername")
-	member.Use(EnsureMemberClusterMiddleware())
+	member.Use(EnsureMemberClusterAccessMiddleware(), EnsureMemberClusterMiddleware())
 	
 	// Management cluster routes with admin middleware
 	mgmt = v1.Group("/mgmt-cluster")
@@ -34,7 +34,11 @@ func handleGetAggregatedConfigMaps(c *gin.Context) {
 			continue
 		}
 
-		memberClient := client.InClusterClientForMemberCluster(cluster.ObjectMeta.Name)
+		memberClient, canView := client.MemberClusterView(c, cluster.ObjectMeta.Name, "configmap")
+		if memberClient == nil {
+			// The caller has no access to this cluster
+			continue
+		}
 		result, err := configmap.GetConfigMapList(memberClient, namespace, dataSelect)
 		if err != nil {
 			// Log error but continue with other clusters
@@ -43,6 +47,9 @@ func handleGetAggregatedConfigMaps(c *gin.Context) {
 
 		// Add cluster name to each configmap
 		for _, cm := range result.Items {
+			if !canView(cm.ObjectMeta.Namespace) {
+				continue
+			}
 			if cm.ObjectMeta.Labels == nil {
 				cm.ObjectMeta.Labels = make(map[string]string)
 			}
@@ -34,7 +34,11 @@ func handleGetAggregatedCronJobs(c *gin.Context) {
 			continue
 		}
 
-		memberClient := client.InClusterClientForMemberCluster(cluster.ObjectMeta.Name)
+		memberClient, canView := client.MemberClusterView(c, cluster.ObjectMeta.Name, "cronjob")
+		if memberClient == nil {
+			// The caller has no access to this cluster
+			continue
+		}
 		result, err := cronjob.GetCronJobList(memberClient, namespace, dataSelect)
 		if err != nil {
 			// Log error but continue with other clusters
@@ -43,6 +47,9 @@ func handleGetAggregatedCronJobs(c *gin.Context) {
 
 		// Add cluster name to each cronjob
 		for _, j := range result.Items {
+			if !canView(j.ObjectMeta.Namespace) {
+				continue
+			}
 			if j.ObjectMeta.Labels == nil {
 				j.ObjectMeta.Labels = make(map[string]string)
 			}
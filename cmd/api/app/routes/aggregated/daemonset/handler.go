@@ -34,7 +34,11 @@ func handleGetAggregatedDaemonsets(c *gin.Context) {
 			continue
 		}
 
-		memberClient := client.InClusterClientForMemberCluster(cluster.ObjectMeta.Name)
+		memberClient, canView := client.MemberClusterView(c, cluster.ObjectMeta.Name, "daemonset")
+		if memberClient == nil {
+			// The caller has no access to this cluster
+			continue
+		}
 		result, err := daemonset.GetDaemonSetList(memberClient, namespace, dataSelect)
 		if err != nil {
 			// Log error but continue with other clusters
@@ -43,6 +47,9 @@ func handleGetAggregatedDaemonsets(c *gin.Context) {
 
 		// Add cluster name to each daemonset
 		for _, d := range result.DaemonSets {
+			if !canView(d.ObjectMeta.Namespace) {
+				continue
+			}
 			if d.ObjectMeta.Labels == nil {
 				d.ObjectMeta.Labels = make(map[string]string)
 			}
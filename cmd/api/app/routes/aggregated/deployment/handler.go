@@ -50,7 +50,11 @@ func handleGetAggregatedDeployments(c *gin.Context) {
 			continue
 		}
 
-		memberClient := client.InClusterClientForMemberCluster(cluster.ObjectMeta.Name)
+		memberClient, canView := client.MemberClusterView(c, cluster.ObjectMeta.Name, "deployment")
+		if memberClient == nil {
+			// The caller has no access to this cluster
+			continue
+		}
 		result, err := deployment.GetDeploymentList(memberClient, namespace, dataSelect)
 		if err != nil {
 			// Log error but continue with other clusters
@@ -59,6 +63,9 @@ func handleGetAggregatedDeployments(c *gin.Context) {
 
 		// Add cluster information to each deployment's metadata
 		for _, d := range result.Deployments {
+			if !canView(d.ObjectMeta.Namespace) {
+				continue
+			}
 			if d.ObjectMeta.Labels == nil {
 				d.ObjectMeta.Labels = make(map[string]string)
 			}
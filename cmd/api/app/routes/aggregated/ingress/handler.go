@@ -34,7 +34,11 @@ func handleGetAggregatedIngresses(c *gin.Context) {
 			continue
 		}
 
-		memberClient := client.InClusterClientForMemberCluster(cluster.ObjectMeta.Name)
+		memberClient, canView := client.MemberClusterView(c, cluster.ObjectMeta.Name, "ingress")
+		if memberClient == nil {
+			// The caller has no access to this cluster
+			continue
+		}
 		result, err := ingress.GetIngressList(memberClient, namespace, dataSelect)
 		if err != nil {
 			// Log error but continue with other clusters
@@ -43,6 +47,9 @@ func handleGetAggregatedIngresses(c *gin.Context) {
 
 		// Add cluster name to each ingress
 		for _, i := range result.Items {
+			if !canView(i.ObjectMeta.Namespace) {
+				continue
+			}
 			if i.ObjectMeta.Labels == nil {
 				i.ObjectMeta.Labels = make(map[string]string)
 			}
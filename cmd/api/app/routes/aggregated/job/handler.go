@@ -34,7 +34,11 @@ func handleGetAggregatedJobs(c *gin.Context) {
 			continue
 		}
 
-		memberClient := client.InClusterClientForMemberCluster(cluster.ObjectMeta.Name)
+		memberClient, canView := client.MemberClusterView(c, cluster.ObjectMeta.Name, "job")
+		if memberClient == nil {
+			// The caller has no access to this cluster
+			continue
+		}
 		result, err := job.GetJobList(memberClient, namespace, dataSelect)
 		if err != nil {
 			// Log error but continue with other clusters
@@ -43,6 +47,9 @@ func handleGetAggregatedJobs(c *gin.Context) {
 
 		// Add cluster name to each job
 		for _, j := range result.Jobs {
+			if !canView(j.ObjectMeta.Namespace) {
+				continue
+			}
 			if j.ObjectMeta.Labels == nil {
 				j.ObjectMeta.Labels = make(map[string]string)
 			}
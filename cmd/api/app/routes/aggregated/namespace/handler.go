@@ -48,7 +48,11 @@ func handleGetAggregatedNamespaces(c *gin.Context) {
 			continue
 		}
 
-		memberClient := client.InClusterClientForMemberCluster(cluster.ObjectMeta.Name)
+		memberClient, canView := client.MemberClusterView(c, cluster.ObjectMeta.Name, "")
+		if memberClient == nil {
+			// The caller has no access to this cluster
+			continue
+		}
 		result, err := ns.GetNamespaceList(memberClient, dataSelect)
 		if err != nil {
 			// Log error but continue with other clusters
@@ -57,6 +61,9 @@ func handleGetAggregatedNamespaces(c *gin.Context) {
 
 		// Add cluster information to each namespace's metadata
 		for _, n := range result.Namespaces {
+			if !canView(n.ObjectMeta.Name) {
+				continue
+			}
 			n.ObjectMeta.Labels["cluster"] = cluster.ObjectMeta.Name
 			aggregatedNamespaces.Namespaces = append(aggregatedNamespaces.Namespaces, n)
 		}
@@ -53,7 +53,11 @@ func handleGetAggregatedNodes(c *gin.Context) {
 			continue
 		}
 
-		memberClient := client.InClusterClientForMemberCluster(cluster.ObjectMeta.Name)
+		memberClient := client.InClusterClientForMemberCluster(c, cluster.ObjectMeta.Name)
+		if memberClient == nil {
+			// The caller has no access to this cluster
+			continue
+		}
 		result, err := node.GetNodeList(memberClient, dataSelect)
 		if err != nil {
 			// Log error but continue with other clusters
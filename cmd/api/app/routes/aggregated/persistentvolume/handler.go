@@ -49,7 +49,11 @@ func handleGetAggregatedPersistentVolumes(c *gin.Context) {
 			continue
 		}
 
-		memberClient := client.InClusterClientForMemberCluster(cluster.ObjectMeta.Name)
+		memberClient := client.InClusterClientForMemberCluster(c, cluster.ObjectMeta.Name)
+		if memberClient == nil {
+			// The caller has no access to this cluster
+			continue
+		}
 		result, err := persistentvolume.GetPersistentVolumeList(memberClient, dataSelect)
 		if err != nil {
 			// Log error but continue with other clusters
@@ -50,7 +50,11 @@ func handleGetAggregatedPods(c *gin.Context) {
 			continue
 		}
 
-		memberClient := client.InClusterClientForMemberCluster(cluster.ObjectMeta.Name)
+		memberClient, canView := client.MemberClusterView(c, cluster.ObjectMeta.Name, "pod")
+		if memberClient == nil {
+			// The caller has no access to this cluster
+			continue
+		}
 		result, err := pod.GetPodList(memberClient, namespace, dataSelect)
 		if err != nil {
 			// Log error but continue with other clusters
@@ -59,6 +63,9 @@ func handleGetAggregatedPods(c *gin.Context) {
 
 		// Add cluster information to each pod's metadata
 		for _, p := range result.Items {
+			if !canView(p.ObjectMeta.Namespace) {
+				continue
+			}
 			if p.ObjectMeta.Labels == nil {
 				p.ObjectMeta.Labels = make(map[string]string)
 			}
@@ -79,4 +86,5 @@ func init() {
 	r := router.V1()
 	r.GET("/aggregated/pod", handleGetAggregatedPods)
 	r.GET("/aggregated/pod/:namespace", handleGetAggregatedPods)
+	r.GET("/aggregated/pod/:namespace/logs/stream", handleStreamSelectedPodLogs)
 }
@@ -0,0 +1,210 @@
+/*
+Copyright 2024 The Karmada Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pod
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+
+	"github.com/karmada-io/dashboard/cmd/api/app/types/common"
+	"github.com/karmada-io/dashboard/pkg/auth"
+	"github.com/karmada-io/dashboard/pkg/auth/fga"
+	"github.com/karmada-io/dashboard/pkg/client"
+	"github.com/karmada-io/dashboard/pkg/dataselect"
+	"github.com/karmada-io/dashboard/pkg/resource/cluster"
+	"github.com/karmada-io/dashboard/pkg/resource/pod"
+)
+
+const (
+	// maxStreamedPods bounds how many pods a selector may stream logs of at once
+	maxStreamedPods = 50
+	// logLineBuffer is how many lines log streams may get ahead of a slow client
+	logLineBuffer = 1024
+)
+
+// logStreamTarget is a pod whose logs are merged into a stream
+type logStreamTarget struct {
+	cluster string
+	client  kubernetes.Interface
+	pod     *corev1.Pod
+}
+
+// LogStreamSkippedCluster is a cluster whose pods are not part of a merged log stream
+type LogStreamSkippedCluster struct {
+	Cluster string `json:"cluster"`
+	Reason  string `json:"reason"`
+}
+
+// logEvent names the event of a log line: "log", or "error" when the stream of a container failed.
+func logEvent(line pod.LogLine) string {
+	if line.Error != "" {
+		return "error"
+	}
+	return "log"
+}
+
+// canViewPods checks, as the member cluster routes do, that username may read the pods of a
+// namespace of a cluster.
+func canViewPods(c *gin.Context, username, clusterName, namespace string) (bool, error) {
+	if fga.FGAService == nil || fga.FGAService.GetClient() == nil {
+		return false, fmt.Errorf("authorization service unavailable")
+	}
+	return fga.HasResourcePermission(c, fga.FGAService.GetClient(), username, fga.RelationViewer, clusterName, namespace, "pod")
+}
+
+// findLogStreamTargets returns the pods matching selector in the namespace of the clusters, all
+// ready clusters if none are given, and the clusters that were skipped.
+func findLogStreamTargets(c *gin.Context, namespace string, selector labels.Selector, clusterNames []string, opts pod.LogStreamOptions) ([]logStreamTarget, []LogStreamSkippedCluster, error) {
+	username := auth.UserFromContext(c)
+	clusters, err := cluster.GetClusterList(client.InClusterKarmadaClient(), dataselect.NoDataSelect)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var targets []logStreamTarget
+	var skipped []LogStreamSkippedCluster
+	for _, candidate := range clusters.Clusters {
+		name := candidate.ObjectMeta.Name
+		if len(clusterNames) > 0 && !slices.Contains(clusterNames, name) {
+			continue
+		}
+		if candidate.Ready != metav1.ConditionTrue {
+			skipped = append(skipped, LogStreamSkippedCluster{Cluster: name, Reason: "cluster is not ready"})
+			continue
+		}
+		allowed, err := canViewPods(c, username, name, namespace)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !allowed {
+			skipped = append(skipped, LogStreamSkippedCluster{Cluster: name, Reason: "access denied"})
+			continue
+		}
+		// The caller may be a viewer of the pods of the namespace only, not of the whole cluster
+		memberClient := client.InClusterClientForMemberCluster(auth.WithAuthorizedCluster(c.Request.Context(), name), name)
+		if memberClient == nil {
+			skipped = append(skipped, LogStreamSkippedCluster{Cluster: name, Reason: "access denied"})
+			continue
+		}
+
+		pods, err := memberClient.CoreV1().Pods(namespace).List(c, metav1.ListOptions{LabelSelector: selector.String()})
+		if err != nil {
+			klog.ErrorS(err, "Failed to list pods for log stream", "cluster", name, "namespace", namespace, "selector", selector.String())
+			skipped = append(skipped, LogStreamSkippedCluster{Cluster: name, Reason: err.Error()})
+			continue
+		}
+		for i := range pods.Items {
+			// Pods without the requested container are left out
+			if _, err := pod.LogContainers(&pods.Items[i], opts); err == nil {
+				targets = append(targets, logStreamTarget{cluster: name, client: memberClient, pod: &pods.Items[i]})
+			}
+		}
+	}
+	for _, name := range clusterNames {
+		if !slices.ContainsFunc(clusters.Clusters, func(c cluster.Cluster) bool { return c.ObjectMeta.Name == name }) {
+			skipped = append(skipped, LogStreamSkippedCluster{Cluster: name, Reason: "cluster not found"})
+		}
+	}
+	return targets, skipped, nil
+}
+
+// handleStreamSelectedPodLogs merges the logs of the pods matching the labelSelector query
+// parameter in a namespace of several clusters, e.g. every replica of a deployment, into one
+// stream. The clusters query parameter (comma separated) limits the clusters, all ready ones are
+// searched otherwise. The stream starts with a "sources" event listing the pods and the skipped
+// clusters, continues with "log" events tagged with their cluster, pod and container, and ends with
+// an "end" event once every pod stream ended. It takes the options of the pod log stream.
+func handleStreamSelectedPodLogs(c *gin.Context) {
+	if auth.UserFromContext(c) == "" {
+		common.FailWithStatus(c, fmt.Errorf("authentication required"), http.StatusUnauthorized)
+		return
+	}
+	namespace := c.Param("namespace")
+	if c.Query("labelSelector") == "" {
+		common.FailWithStatus(c, fmt.Errorf("the labelSelector parameter is required"), http.StatusBadRequest)
+		return
+	}
+	selector, err := labels.Parse(c.Query("labelSelector"))
+	if err != nil {
+		common.FailWithStatus(c, fmt.Errorf("invalid labelSelector: %v", err), http.StatusBadRequest)
+		return
+	}
+	opts, err := common.ParseLogStreamOptions(c)
+	if err != nil {
+		common.FailWithStatus(c, err, http.StatusBadRequest)
+		return
+	}
+	var clusterNames []string
+	for _, name := range strings.Split(c.Query("clusters"), ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			clusterNames = append(clusterNames, name)
+		}
+	}
+
+	targets, skipped, err := findLogStreamTargets(c, namespace, selector, clusterNames, opts)
+	if err != nil {
+		klog.ErrorS(err, "Failed to find pods for log stream", "namespace", namespace, "selector", selector.String())
+		common.Fail(c, err)
+		return
+	}
+	if len(targets) > maxStreamedPods {
+		common.FailWithStatus(c, fmt.Errorf("the selector matches %d pods, the logs of at most %d can be streamed together", len(targets), maxStreamedPods), http.StatusBadRequest)
+		return
+	}
+
+	stream, err := common.NewEventStream(c)
+	if err != nil {
+		klog.ErrorS(err, "Failed to start log stream", "namespace", namespace, "selector", selector.String())
+		return
+	}
+	defer stream.Close()
+
+	sources := make([]pod.LogSource, 0, len(targets))
+	for _, target := range targets {
+		sources = append(sources, pod.LogSource{Cluster: target.cluster, Namespace: target.pod.Namespace, Pod: target.pod.Name})
+	}
+	if err := stream.Send("sources", gin.H{"pods": sources, "skipped": skipped}); err != nil {
+		return
+	}
+
+	lines := make(chan pod.LogLine, logLineBuffer)
+	var wg sync.WaitGroup
+	for _, target := range targets {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_ = pod.StreamPodLogs(stream.Context(), target.client, target.cluster, target.pod, opts, lines)
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(lines)
+	}()
+	if common.ForwardEvents(stream, lines, logEvent) {
+		_ = stream.Send("end", gin.H{})
+	}
+}
@@ -34,7 +34,11 @@ func handleGetAggregatedReplicaSets(c *gin.Context) {
 			continue
 		}
 
-		memberClient := client.InClusterClientForMemberCluster(cluster.ObjectMeta.Name)
+		memberClient, canView := client.MemberClusterView(c, cluster.ObjectMeta.Name, "replicaset")
+		if memberClient == nil {
+			// The caller has no access to this cluster
+			continue
+		}
 		result, err := replicaset.GetReplicaSetList(memberClient, namespace, dataSelect)
 		if err != nil {
 			// Log error but continue with other clusters
@@ -43,6 +47,9 @@ func handleGetAggregatedReplicaSets(c *gin.Context) {
 
 		// Add cluster name to each replicaset
 		for _, rs := range result.Items {
+			if !canView(rs.ObjectMeta.Namespace) {
+				continue
+			}
 			if rs.ObjectMeta.Labels == nil {
 				rs.ObjectMeta.Labels = make(map[string]string)
 			}
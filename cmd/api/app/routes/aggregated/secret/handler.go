@@ -34,7 +34,11 @@ func handleGetAggregatedSecrets(c *gin.Context) {
 			continue
 		}
 
-		memberClient := client.InClusterClientForMemberCluster(cluster.ObjectMeta.Name)
+		memberClient, canView := client.MemberClusterView(c, cluster.ObjectMeta.Name, "secret")
+		if memberClient == nil {
+			// The caller has no access to this cluster
+			continue
+		}
 		result, err := secret.GetSecretList(memberClient, namespace, dataSelect)
 		if err != nil {
 			// Log error but continue with other clusters
@@ -43,6 +47,9 @@ func handleGetAggregatedSecrets(c *gin.Context) {
 
 		// Add cluster name to each secret
 		for _, s := range result.Secrets {
+			if !canView(s.ObjectMeta.Namespace) {
+				continue
+			}
 			if s.ObjectMeta.Labels == nil {
 				s.ObjectMeta.Labels = make(map[string]string)
 			}
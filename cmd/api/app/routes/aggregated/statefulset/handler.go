@@ -50,7 +50,11 @@ func handleGetAggregatedStatefulSets(c *gin.Context) {
 			continue
 		}
 
-		memberClient := client.InClusterClientForMemberCluster(cluster.ObjectMeta.Name)
+		memberClient, canView := client.MemberClusterView(c, cluster.ObjectMeta.Name, "statefulset")
+		if memberClient == nil {
+			// The caller has no access to this cluster
+			continue
+		}
 		result, err := statefulset.GetStatefulSetList(memberClient, namespace, dataSelect)
 		if err != nil {
 			// Log error but continue with other clusters
@@ -59,6 +63,9 @@ func handleGetAggregatedStatefulSets(c *gin.Context) {
 
 		// Add cluster information to each statefulset's metadata
 		for _, s := range result.StatefulSets {
+			if !canView(s.ObjectMeta.Namespace) {
+				continue
+			}
 			if s.ObjectMeta.Labels == nil {
 				s.ObjectMeta.Labels = make(map[string]string)
 			}
@@ -0,0 +1,161 @@
+/*
+Copyright 2024 The Karmada Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apply
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/klog/v2"
+
+	"github.com/karmada-io/dashboard/cmd/api/app/router"
+	"github.com/karmada-io/dashboard/cmd/api/app/types/common"
+	"github.com/karmada-io/dashboard/pkg/client"
+	"github.com/karmada-io/dashboard/pkg/resource/manifest"
+)
+
+// target returns the clients of the cluster a request applies manifests to.
+type target func(c *gin.Context) (dynamic.Interface, manifest.Mapper, error)
+
+func karmadaTarget(_ *gin.Context) (dynamic.Interface, manifest.Mapper, error) {
+	dynamicClient, err := client.GetKarmadaDynamicClient()
+	if err != nil {
+		return nil, nil, err
+	}
+	mapper, err := client.KarmadaRESTMapper()
+	if err != nil {
+		return nil, nil, err
+	}
+	return dynamicClient, mapper, nil
+}
+
+func memberTarget(c *gin.Context) (dynamic.Interface, manifest.Mapper, error) {
+	clusterName := c.Param("clustername")
+	dynamicClient, err := client.GetDynamicClientForMember(c, clusterName)
+	if err != nil {
+		return nil, nil, err
+	}
+	mapper, err := client.MemberRESTMapper(clusterName)
+	if err != nil {
+		return nil, nil, err
+	}
+	return dynamicClient, mapper, nil
+}
+
+func mgmtTarget(_ *gin.Context) (dynamic.Interface, manifest.Mapper, error) {
+	dynamicClient, err := client.GetDynamicClient()
+	if err != nil {
+		return nil, nil, err
+	}
+	mapper, err := client.MemberRESTMapper("mgmt-cluster")
+	if err != nil {
+		return nil, nil, err
+	}
+	return dynamicClient, mapper, nil
+}
+
+// handleApply applies the manifests of the request body, a multi-document YAML or JSON stream or a
+// tar, tar.gz or zip archive of manifests, sent as is or as the "file" field of a multipart form.
+// Objects are applied in dependency order and a result is returned for each of them, failures
+// included.
+func handleApply(getTarget target) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		opts, err := parseApplyOptions(c)
+		if err != nil {
+			common.FailWithStatus(c, err, http.StatusBadRequest)
+			return
+		}
+		data, err := readManifests(c)
+		if err != nil {
+			common.FailWithStatus(c, err, http.StatusBadRequest)
+			return
+		}
+		manifests, err := manifest.Read(data)
+		if err != nil {
+			common.FailWithStatus(c, err, http.StatusBadRequest)
+			return
+		}
+		if len(manifests) == 0 {
+			common.FailWithStatus(c, fmt.Errorf("no objects found in the manifests"), http.StatusBadRequest)
+			return
+		}
+
+		dynamicClient, mapper, err := getTarget(c)
+		if err != nil {
+			klog.ErrorS(err, "Failed to init clients to apply manifests", "cluster", c.Param("clustername"))
+			common.Fail(c, err)
+			return
+		}
+		result := manifest.Apply(c, dynamicClient, mapper, manifests, opts)
+		if result.Failed > 0 {
+			klog.InfoS("Failed to apply some manifests", "cluster", c.Param("clustername"), "failed", result.Failed, "rolledBack", result.RolledBack)
+		}
+		common.Success(c, result)
+	}
+}
+
+func parseApplyOptions(c *gin.Context) (manifest.ApplyOptions, error) {
+	dryRun, err := common.ParseDryRun(c)
+	if err != nil {
+		return manifest.ApplyOptions{}, err
+	}
+	force, err := common.ParseForce(c)
+	if err != nil {
+		return manifest.ApplyOptions{}, err
+	}
+	rollback := false
+	if value := c.Query("rollback"); value != "" {
+		if rollback, err = strconv.ParseBool(value); err != nil {
+			return manifest.ApplyOptions{}, fmt.Errorf("invalid rollback parameter %q", value)
+		}
+	}
+	return manifest.ApplyOptions{Namespace: c.Query("namespace"), Force: force, DryRun: dryRun, Rollback: rollback}, nil
+}
+
+// readManifests reads the manifests of a request, from the body or its multipart "file" field.
+func readManifests(c *gin.Context) ([]byte, error) {
+	body := c.Request.Body
+	if strings.HasPrefix(c.ContentType(), "multipart/form-data") {
+		file, _, err := c.Request.FormFile("file")
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the file of the form: %w", err)
+		}
+		defer file.Close()
+		body = file
+	}
+	data, err := io.ReadAll(io.LimitReader(body, manifest.MaxSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read request body: %w", err)
+	}
+	if len(data) > manifest.MaxSize {
+		return nil, fmt.Errorf("the manifests are larger than %d bytes", manifest.MaxSize)
+	}
+	return data, nil
+}
+
+func init() {
+	// Manifests are applied to Karmada with the credentials of the dashboard, only dashboard admins
+	// may do so
+	router.V1().POST("/apply", router.EnsureDashboardAdminMiddleware(), handleApply(karmadaTarget))
+	router.MemberV1().POST("/apply", handleApply(memberTarget))
+	router.Mgmt().POST("/apply", handleApply(mgmtTarget))
+}
@@ -0,0 +1,39 @@
+/*
+Copyright 2024 The Karmada Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apply
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/karmada-io/dashboard/cmd/api/app/router"
+)
+
+func TestApplyRequiresAuthentication(t *testing.T) {
+	manifests := "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: cfg\n  namespace: default\n"
+	cases := []string{"/api/v1/apply", "/api/v1/member/member1/apply", "/api/v1/mgmt-cluster/apply"}
+
+	for _, path := range cases {
+		recorder := httptest.NewRecorder()
+		router.Router().ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, path, strings.NewReader(manifests)))
+		if recorder.Code != http.StatusUnauthorized && !strings.Contains(recorder.Body.String(), `"code":401`) {
+			t.Errorf("POST %s without credentials: got %d %s, expected 401", path, recorder.Code, recorder.Body.String())
+		}
+	}
+}
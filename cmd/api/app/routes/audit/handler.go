@@ -0,0 +1,87 @@
+/*
+Copyright 2024 The Karmada Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package audit
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"k8s.io/klog/v2"
+
+	"github.com/karmada-io/dashboard/cmd/api/app/router"
+	"github.com/karmada-io/dashboard/cmd/api/app/types/common"
+	"github.com/karmada-io/dashboard/pkg/audit"
+	resourceaudit "github.com/karmada-io/dashboard/pkg/resource/audit"
+)
+
+// handleGetAuditEvents lists audit events, most recent first. The since and until query parameters
+// take RFC 3339 times. limit is the size of a page and continue the token returned with the previous
+// page; filterBy filters the events and sortBy sorts the events of a page.
+func handleGetAuditEvents(c *gin.Context) {
+	query := audit.Query{Continue: c.Query("continue")}
+	var err error
+	if query.Since, err = parseTimeParameter(c, "since"); err != nil {
+		common.FailWithStatus(c, err, http.StatusBadRequest)
+		return
+	}
+	if query.Until, err = parseTimeParameter(c, "until"); err != nil {
+		common.FailWithStatus(c, err, http.StatusBadRequest)
+		return
+	}
+
+	if value := c.Query("limit"); value != "" {
+		if query.Limit, err = strconv.Atoi(value); err != nil || query.Limit <= 0 {
+			common.FailWithStatus(c, fmt.Errorf("invalid limit parameter %q", value), http.StatusBadRequest)
+			return
+		}
+	}
+
+	dataSelect := common.ParseDataSelectPathParameter(c)
+	result, err := resourceaudit.GetEventList(c, query, dataSelect)
+	if errors.Is(err, audit.ErrInvalidContinue) {
+		common.FailWithStatus(c, err, http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		klog.ErrorS(err, "GetEventList failed")
+		common.Fail(c, err)
+		return
+	}
+	common.Success(c, result)
+}
+
+func parseTimeParameter(c *gin.Context, name string) (time.Time, error) {
+	value := c.Query(name)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid %s parameter, expected an RFC 3339 time: %v", name, err)
+	}
+	return t, nil
+}
+
+func init() {
+	r := router.V1().Group("/audit")
+	r.Use(router.EnsureDashboardAdminMiddleware())
+	r.GET("", handleGetAuditEvents)
+}
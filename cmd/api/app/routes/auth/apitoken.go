@@ -0,0 +1,158 @@
+/*
+Copyright 2024 The Karmada Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auth
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"k8s.io/klog/v2"
+
+	v1 "github.com/karmada-io/dashboard/cmd/api/app/types/api/v1"
+	"github.com/karmada-io/dashboard/cmd/api/app/types/common"
+	"github.com/karmada-io/dashboard/pkg/auth"
+	"github.com/karmada-io/dashboard/pkg/auth/fga"
+	"github.com/karmada-io/dashboard/pkg/etcd"
+)
+
+// handleGetAPITokens lists the API tokens of the caller.
+func handleGetAPITokens(c *gin.Context) {
+	caller := auth.UserFromContext(c)
+	if caller == "" {
+		common.FailWithStatus(c, fmt.Errorf("authentication required"), http.StatusUnauthorized)
+		return
+	}
+	listAPITokens(c, caller)
+}
+
+// handleGetAllAPITokens lists the API tokens of every user, optionally filtered by ?username=.
+func handleGetAllAPITokens(c *gin.Context) {
+	listAPITokens(c, c.Query("username"))
+}
+
+func listAPITokens(c *gin.Context, username string) {
+	tokens, err := auth.ListAPITokens(c, username)
+	if err != nil {
+		klog.ErrorS(err, "ListAPITokens failed", "username", username)
+		common.Fail(c, err)
+		return
+	}
+	result := make([]v1.APIToken, 0, len(tokens))
+	for _, token := range tokens {
+		result = append(result, toAPIToken(token))
+	}
+	common.Success(c, result)
+}
+
+// handlePostAPIToken creates an API token owned by the caller. Administrators can create tokens
+// for other users, e.g. for a service account user that only automation signs in as.
+func handlePostAPIToken(c *gin.Context) {
+	caller := auth.UserFromContext(c)
+	if caller == "" {
+		common.FailWithStatus(c, fmt.Errorf("authentication required"), http.StatusUnauthorized)
+		return
+	}
+	request := new(v1.CreateAPITokenRequest)
+	if err := c.ShouldBindJSON(request); err != nil {
+		common.FailWithStatus(c, err, http.StatusBadRequest)
+		return
+	}
+
+	owner := caller
+	if request.Username != "" && request.Username != caller {
+		if !isDashboardAdmin(c, caller) {
+			common.FailWithStatus(c, fmt.Errorf("only administrators can create tokens for other users"), http.StatusForbidden)
+			return
+		}
+		owner = request.Username
+	}
+
+	token, secret, err := auth.CreateAPIToken(c, owner, caller, auth.APITokenOptions{
+		Name:        request.Name,
+		Description: request.Description,
+		Scopes:      request.Scopes,
+		Clusters:    request.Clusters,
+		Lifetime:    time.Duration(request.ExpiresInDays) * 24 * time.Hour,
+	})
+	if err != nil {
+		klog.InfoS("API token creation rejected", "username", owner, "error", err)
+		common.FailWithStatus(c, err, http.StatusBadRequest)
+		return
+	}
+	common.Success(c, v1.CreateAPITokenResponse{
+		APIToken: toAPIToken(token),
+		Token:    secret,
+	})
+}
+
+// handleDeleteAPIToken deletes an API token of the caller.
+func handleDeleteAPIToken(c *gin.Context) {
+	caller := auth.UserFromContext(c)
+	if caller == "" {
+		common.FailWithStatus(c, fmt.Errorf("authentication required"), http.StatusUnauthorized)
+		return
+	}
+	deleteAPIToken(c, caller)
+}
+
+// handleDeleteAnyAPIToken deletes an API token of any user.
+func handleDeleteAnyAPIToken(c *gin.Context) {
+	deleteAPIToken(c, "")
+}
+
+func deleteAPIToken(c *gin.Context, username string) {
+	id := c.Param("id")
+	if err := auth.DeleteAPIToken(c, id, username); err != nil {
+		klog.ErrorS(err, "DeleteAPIToken failed", "id", id)
+		common.Fail(c, err)
+		return
+	}
+	common.Success(c, "API token deleted successfully")
+}
+
+func isDashboardAdmin(c *gin.Context, username string) bool {
+	if fga.FGAService == nil || fga.FGAService.GetClient() == nil {
+		return false
+	}
+	isAdmin, err := fga.IsDashboardAdmin(c, fga.FGAService.GetClient(), username)
+	if err != nil {
+		klog.ErrorS(err, "Failed to check if user is admin", "username", username)
+		return false
+	}
+	return isAdmin
+}
+
+func toAPIToken(token *etcd.APIToken) v1.APIToken {
+	result := v1.APIToken{
+		ID:          token.ID,
+		Name:        token.Name,
+		Description: token.Description,
+		Username:    token.Username,
+		Scopes:      token.Scopes,
+		Clusters:    token.Clusters,
+		CreatedBy:   token.CreatedBy,
+		CreatedAt:   token.CreatedAt,
+		ExpiresAt:   token.ExpiresAt,
+	}
+	if !token.LastUsedAt.IsZero() {
+		lastUsedAt := token.LastUsedAt
+		result.LastUsedAt = &lastUsedAt
+	}
+	return result
+}
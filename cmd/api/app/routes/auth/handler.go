@@ -43,7 +43,7 @@ func handleLogin(c *gin.Context) {
 		c.JSON(statusCode, common.BaseResponse{
 			Code: statusCode,
 			Msg:  err.Error(),
-			Data: nil,
+			Data: response,
 		})
 		return
 	}
@@ -102,4 +102,26 @@ func init() {
 	router.V1().POST("/login", handleLogin)
 	router.V1().GET("/me", handleMe)
 	router.V1().POST("/init-token", handleInitToken)
+	router.V1().POST("/token/refresh", handleRefreshToken)
+	router.V1().POST("/logout", handleLogout)
+	router.V1().GET("/jwks", handleJWKS)
+	router.V1().GET("/password/policy", handleGetPasswordPolicy)
+	router.V1().POST("/password/change", handleChangePassword)
+	router.V1().POST("/login/2fa", handleVerifySecondFactor)
+	router.V1().GET("/2fa", handleGetTwoFactorStatus)
+	router.V1().POST("/2fa/enroll", handleBeginEnrollment)
+	router.V1().POST("/2fa/enroll/confirm", handleConfirmEnrollment)
+	router.V1().POST("/2fa/disable", handleDisableTwoFactor)
+	router.V1().POST("/2fa/recovery-codes", handleRegenerateRecoveryCodes)
+	router.V1().GET("/oidc/config", handleOIDCConfig)
+	router.V1().GET("/oidc/login", handleOIDCLogin)
+	router.V1().GET("/oidc/callback", handleOIDCCallback)
+	router.V1().GET("/api-tokens", handleGetAPITokens)
+	router.V1().POST("/api-tokens", handlePostAPIToken)
+	router.V1().DELETE("/api-tokens/:id", handleDeleteAPIToken)
+
+	admin := router.V1().Group("/setting/api-tokens")
+	admin.Use(router.EnsureDashboardAdminMiddleware())
+	admin.GET("", handleGetAllAPITokens)
+	admin.DELETE("/:id", handleDeleteAnyAPIToken)
 }
@@ -18,6 +18,7 @@ package auth
 
 import (
 	"context"
+	goerrors "errors"
 	"net/http"
 	"time"
 
@@ -33,14 +34,45 @@ func login(spec *v1.LoginRequest, request *http.Request) (*v1.LoginResponse, int
 		ctx, cancel := context.WithTimeout(request.Context(), 5*time.Second)
 		defer cancel()
 
-		token, err := auth.AuthenticateUser(ctx, spec.Username, spec.Password)
-		if err != nil {
+		tokens, err := auth.AuthenticateUser(ctx, spec.Username, spec.Password)
+		var secondFactor *auth.SecondFactorRequiredError
+		switch {
+		case goerrors.As(err, &secondFactor):
+			return newSecondFactorResponse(spec.Username, secondFactor), http.StatusOK, nil
+		case goerrors.Is(err, auth.ErrPasswordChangeRequired):
+			// The client has to continue with /password/change
+			return &v1.LoginResponse{Username: spec.Username, PasswordChangeRequired: true}, http.StatusForbidden, err
+		case goerrors.Is(err, auth.ErrAccountLocked):
+			klog.InfoS("Login for locked user", "username", spec.Username)
+			return nil, http.StatusLocked, err
+		case err != nil:
 			klog.ErrorS(err, "Authentication failed", "username", spec.Username)
 			return nil, http.StatusUnauthorized, errors.NewUnauthorized("Invalid username or password")
 		}
 
-		return &v1.LoginResponse{Token: token}, http.StatusOK, nil
+		return newLoginResponse(spec.Username, tokens), http.StatusOK, nil
 	}
 
 	return nil, http.StatusBadRequest, errors.NewBadRequest("No valid authentication method provided")
 }
+
+// newLoginResponse returns the response for a started session.
+func newLoginResponse(username string, tokens *auth.TokenPair) *v1.LoginResponse {
+	return &v1.LoginResponse{
+		Token:        tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresAt:    tokens.ExpiresAt,
+		Username:     username,
+	}
+}
+
+// newSecondFactorResponse returns the response for a login that continues with a second step.
+func newSecondFactorResponse(username string, secondFactor *auth.SecondFactorRequiredError) *v1.LoginResponse {
+	return &v1.LoginResponse{
+		Username:                    username,
+		ExpiresAt:                   secondFactor.ExpiresAt,
+		TwoFactorRequired:           !secondFactor.Enroll,
+		TwoFactorEnrollmentRequired: secondFactor.Enroll,
+		TwoFactorToken:              secondFactor.Token,
+	}
+}
@@ -0,0 +1,151 @@
+/*
+Copyright 2024 The Karmada Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auth
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"k8s.io/klog/v2"
+
+	v1 "github.com/karmada-io/dashboard/cmd/api/app/types/api/v1"
+	"github.com/karmada-io/dashboard/cmd/api/app/types/common"
+	"github.com/karmada-io/dashboard/pkg/auth/oidc"
+)
+
+const (
+	oidcLoginPath = "/api/v1/oidc/login"
+	// oidcStateCookie binds a login to the browser that started it, so that the callback cannot
+	// complete a login started elsewhere, e.g. by an attacker signing the victim in as themselves
+	oidcStateCookie     = "karmada_dashboard_oidc_state"
+	oidcStateCookiePath = "/api/v1/oidc"
+)
+
+func handleOIDCConfig(c *gin.Context) {
+	response := v1.OIDCConfigResponse{Enabled: oidc.OIDCProvider != nil}
+	if response.Enabled {
+		response.LoginURL = oidcLoginPath
+	}
+	common.Success(c, response)
+}
+
+func handleOIDCLogin(c *gin.Context) {
+	provider := oidc.OIDCProvider
+	if provider == nil {
+		common.FailWithStatus(c, fmt.Errorf("OIDC single sign-on is not configured"), http.StatusNotFound)
+		return
+	}
+
+	redirect := c.Query("redirect")
+	if !isLocalRedirect(redirect) {
+		common.FailWithStatus(c, fmt.Errorf("redirect must be a path on the dashboard"), http.StatusBadRequest)
+		return
+	}
+
+	authURL, state, err := provider.AuthCodeURL(c, redirect)
+	if err != nil {
+		klog.ErrorS(err, "Failed to start OIDC login")
+		common.FailWithStatus(c, err, http.StatusInternalServerError)
+		return
+	}
+	setOIDCStateCookie(c, state, int(oidc.PendingLoginTTL.Seconds()))
+	c.Redirect(http.StatusFound, authURL)
+}
+
+func handleOIDCCallback(c *gin.Context) {
+	provider := oidc.OIDCProvider
+	if provider == nil {
+		common.FailWithStatus(c, fmt.Errorf("OIDC single sign-on is not configured"), http.StatusNotFound)
+		return
+	}
+
+	if errCode := c.Query("error"); errCode != "" {
+		klog.InfoS("OIDC login rejected by identity provider", "error", errCode, "description", c.Query("error_description"))
+		common.FailWithStatus(c, fmt.Errorf("login rejected by identity provider: %s", errCode), http.StatusUnauthorized)
+		return
+	}
+
+	state := c.Query("state")
+	cookie, _ := c.Cookie(oidcStateCookie)
+	setOIDCStateCookie(c, "", -1)
+	if state == "" || subtle.ConstantTimeCompare([]byte(cookie), []byte(state)) != 1 {
+		klog.InfoS("OIDC callback state does not match the login started by the browser")
+		common.FailWithStatus(c, fmt.Errorf("OIDC login failed"), http.StatusUnauthorized)
+		return
+	}
+
+	identity, redirect, err := provider.Exchange(c, c.Query("code"), state)
+	if err != nil {
+		klog.ErrorS(err, "OIDC login failed")
+		common.FailWithStatus(c, fmt.Errorf("OIDC login failed"), http.StatusUnauthorized)
+		return
+	}
+
+	tokens, err := provider.Provision(c, identity)
+	if err != nil {
+		klog.ErrorS(err, "Failed to provision OIDC user", "username", identity.Username)
+		common.FailWithStatus(c, err, http.StatusForbidden)
+		return
+	}
+	klog.InfoS("OIDC login succeeded", "username", identity.Username)
+
+	if redirect != "" {
+		// The tokens travel in the fragment so that they never reach server logs
+		fragment := url.Values{"token": {tokens.AccessToken}}
+		if tokens.RefreshToken != "" {
+			fragment.Set("refreshToken", tokens.RefreshToken)
+		}
+		c.Redirect(http.StatusFound, redirect+"#"+fragment.Encode())
+		return
+	}
+	c.JSON(http.StatusOK, common.BaseResponse{
+		Code: http.StatusOK,
+		Msg:  "success",
+		Data: v1.LoginResponse{
+			Token:        tokens.AccessToken,
+			RefreshToken: tokens.RefreshToken,
+			ExpiresAt:    tokens.ExpiresAt,
+			Username:     identity.Username,
+		},
+	})
+}
+
+// setOIDCStateCookie sets the state cookie of the login, or deletes it with a negative maxAge.
+// The identity provider sends the browser back with a top-level GET, which SameSite=Lax cookies
+// are sent with.
+func setOIDCStateCookie(c *gin.Context, state string, maxAge int) {
+	secure := c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https"
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oidcStateCookie, state, maxAge, oidcStateCookiePath, "", secure, true)
+}
+
+// isLocalRedirect reports whether redirect is empty or a path on the dashboard itself, so that
+// the login cannot be used to send tokens to other sites.
+func isLocalRedirect(redirect string) bool {
+	if redirect == "" {
+		return true
+	}
+	if !strings.HasPrefix(redirect, "/") || strings.HasPrefix(redirect, "//") || strings.Contains(redirect, "\\") {
+		return false
+	}
+	u, err := url.Parse(redirect)
+	return err == nil && u.Scheme == "" && u.Host == "" && u.Fragment == ""
+}
@@ -0,0 +1,68 @@
+/*
+Copyright 2024 The Karmada Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/karmada-io/dashboard/pkg/auth/oidc"
+)
+
+func TestOIDCCallbackRequiresStateCookie(t *testing.T) {
+	previous := oidc.OIDCProvider
+	oidc.OIDCProvider = &oidc.Provider{}
+	t.Cleanup(func() { oidc.OIDCProvider = previous })
+
+	cases := []struct {
+		name   string
+		query  string
+		cookie string
+	}{
+		{"no cookie", "?code=c&state=s1", ""},
+		{"cookie of another login", "?code=c&state=s1", "s2"},
+		{"no state", "?code=c", "s1"},
+	}
+
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.GET("/api/v1/oidc/callback", handleOIDCCallback)
+	for _, c := range cases {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/oidc/callback"+c.query, nil)
+		if c.cookie != "" {
+			req.AddCookie(&http.Cookie{Name: oidcStateCookie, Value: c.cookie})
+		}
+		recorder := httptest.NewRecorder()
+		engine.ServeHTTP(recorder, req)
+
+		if recorder.Code != http.StatusUnauthorized {
+			t.Errorf("%s: got status %d, expected %d", c.name, recorder.Code, http.StatusUnauthorized)
+		}
+		cleared := false
+		for _, cookie := range recorder.Result().Cookies() {
+			if cookie.Name == oidcStateCookie && cookie.MaxAge < 0 && cookie.HttpOnly {
+				cleared = true
+			}
+		}
+		if !cleared {
+			t.Errorf("%s: the state cookie was not cleared", c.name)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+/*
+Copyright 2024 The Karmada Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"k8s.io/klog/v2"
+
+	v1 "github.com/karmada-io/dashboard/cmd/api/app/types/api/v1"
+	"github.com/karmada-io/dashboard/cmd/api/app/types/common"
+	"github.com/karmada-io/dashboard/pkg/auth"
+)
+
+func handleGetPasswordPolicy(c *gin.Context) {
+	policy := auth.GetPasswordPolicy()
+	common.Success(c, v1.PasswordPolicy{
+		MinLength:        policy.MinLength,
+		RequireMixedCase: policy.RequireMixedCase,
+		RequireDigit:     policy.RequireDigit,
+		RequireSymbol:    policy.RequireSymbol,
+		HistorySize:      policy.HistorySize,
+		MaxAgeDays:       int(policy.MaxAge / (24 * time.Hour)),
+	})
+}
+
+// handleChangePassword changes a password with the current one. It does not require a token so
+// that users with an expired or reset password can use it to sign in.
+func handleChangePassword(c *gin.Context) {
+	request := new(v1.ChangePasswordRequest)
+	if err := c.ShouldBindJSON(request); err != nil {
+		common.FailWithStatus(c, err, http.StatusBadRequest)
+		return
+	}
+
+	tokens, err := auth.ChangePassword(c, request.Username, request.CurrentPassword, request.NewPassword, request.Code)
+	var secondFactor *auth.SecondFactorRequiredError
+	switch {
+	case errors.As(err, &secondFactor):
+		common.Success(c, newSecondFactorResponse(request.Username, secondFactor))
+		return
+	case errors.Is(err, auth.ErrInvalidCredentials), errors.Is(err, auth.ErrInvalidCode):
+		common.FailWithStatus(c, err, http.StatusUnauthorized)
+		return
+	case errors.Is(err, auth.ErrAccountLocked):
+		common.FailWithStatus(c, err, http.StatusLocked)
+		return
+	case err != nil:
+		klog.InfoS("Password change rejected", "username", request.Username, "error", err)
+		common.FailWithStatus(c, err, http.StatusBadRequest)
+		return
+	}
+	common.Success(c, newLoginResponse(request.Username, tokens))
+}
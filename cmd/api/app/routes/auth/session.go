@@ -0,0 +1,79 @@
+/*
+Copyright 2024 The Karmada Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auth
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"k8s.io/klog/v2"
+
+	v1 "github.com/karmada-io/dashboard/cmd/api/app/types/api/v1"
+	"github.com/karmada-io/dashboard/cmd/api/app/types/common"
+	"github.com/karmada-io/dashboard/pkg/auth"
+	"github.com/karmada-io/dashboard/pkg/client"
+)
+
+func handleRefreshToken(c *gin.Context) {
+	refreshRequest := new(v1.RefreshTokenRequest)
+	if err := c.ShouldBindJSON(refreshRequest); err != nil {
+		common.FailWithStatus(c, err, http.StatusBadRequest)
+		return
+	}
+
+	tokens, err := auth.RefreshSession(c, refreshRequest.RefreshToken)
+	if err != nil {
+		klog.InfoS("Refresh token rejected", "error", err)
+		common.FailWithStatus(c, fmt.Errorf("invalid refresh token"), http.StatusUnauthorized)
+		return
+	}
+	common.Success(c, v1.LoginResponse{
+		Token:        tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresAt:    tokens.ExpiresAt,
+	})
+}
+
+func handleLogout(c *gin.Context) {
+	claims, err := auth.ValidateToken(client.GetBearerToken(c.Request))
+	if err != nil {
+		common.FailWithStatus(c, fmt.Errorf("invalid authentication token"), http.StatusUnauthorized)
+		return
+	}
+
+	logoutRequest := new(v1.LogoutRequest)
+	// The body is optional
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(logoutRequest); err != nil {
+			common.FailWithStatus(c, err, http.StatusBadRequest)
+			return
+		}
+	}
+
+	if err := auth.Logout(c, claims, logoutRequest.All); err != nil {
+		klog.ErrorS(err, "Failed to log out", "username", claims.Username)
+		common.Fail(c, err)
+		return
+	}
+	klog.InfoS("User logged out", "username", claims.Username, "allSessions", logoutRequest.All)
+	common.Success(c, "ok")
+}
+
+func handleJWKS(c *gin.Context) {
+	c.JSON(http.StatusOK, auth.JWKS())
+}
@@ -0,0 +1,177 @@
+/*
+Copyright 2024 The Karmada Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"k8s.io/klog/v2"
+
+	v1 "github.com/karmada-io/dashboard/cmd/api/app/types/api/v1"
+	"github.com/karmada-io/dashboard/cmd/api/app/types/common"
+	"github.com/karmada-io/dashboard/pkg/auth"
+	"github.com/karmada-io/dashboard/pkg/client"
+)
+
+// handleVerifySecondFactor is the second login step of users with two-factor authentication.
+func handleVerifySecondFactor(c *gin.Context) {
+	request := new(v1.SecondFactorRequest)
+	if err := c.ShouldBindJSON(request); err != nil {
+		common.FailWithStatus(c, err, http.StatusBadRequest)
+		return
+	}
+
+	tokens, err := auth.VerifySecondFactor(c, request.Token, request.Code)
+	if err != nil {
+		failTwoFactor(c, err)
+		return
+	}
+	claims, err := auth.ValidateToken(tokens.AccessToken)
+	if err != nil {
+		common.Fail(c, err)
+		return
+	}
+	common.Success(c, newLoginResponse(claims.Username, tokens))
+}
+
+func handleGetTwoFactorStatus(c *gin.Context) {
+	username := auth.UserFromContext(c)
+	if username == "" {
+		common.FailWithStatus(c, fmt.Errorf("authentication required"), http.StatusUnauthorized)
+		return
+	}
+	status, err := auth.GetTwoFactorStatus(c, username)
+	if err != nil {
+		common.Fail(c, err)
+		return
+	}
+	common.Success(c, v1.TwoFactorStatus{
+		Enabled:                status.Enabled,
+		Required:               status.Required,
+		RemainingRecoveryCodes: status.RemainingRecoveryCodes,
+	})
+}
+
+func handleBeginEnrollment(c *gin.Context) {
+	username, _, ok := twoFactorUser(c)
+	if !ok {
+		return
+	}
+	enrollment, err := auth.BeginEnrollment(c, username)
+	if err != nil {
+		klog.ErrorS(err, "Failed to begin two-factor enrollment", "username", username)
+		common.FailWithStatus(c, err, http.StatusBadRequest)
+		return
+	}
+	common.Success(c, v1.TwoFactorEnrollmentResponse{Secret: enrollment.Secret, OTPAuthURL: enrollment.URL})
+}
+
+// handleConfirmEnrollment enables two-factor authentication. When the enrollment is part of a
+// login the session starts with it.
+func handleConfirmEnrollment(c *gin.Context) {
+	username, enrollmentClaims, ok := twoFactorUser(c)
+	if !ok {
+		return
+	}
+	request := new(v1.TwoFactorCodeRequest)
+	if err := c.ShouldBindJSON(request); err != nil {
+		common.FailWithStatus(c, err, http.StatusBadRequest)
+		return
+	}
+
+	codes, err := auth.ConfirmEnrollment(c, username, request.Code)
+	if err != nil {
+		failTwoFactor(c, err)
+		return
+	}
+	response := v1.RecoveryCodesResponse{RecoveryCodes: codes}
+	if enrollmentClaims != nil {
+		tokens, err := auth.FinishEnrollment(c, enrollmentClaims)
+		if err != nil {
+			common.Fail(c, err)
+			return
+		}
+		response.Login = newLoginResponse(username, tokens)
+	}
+	common.Success(c, response)
+}
+
+func handleDisableTwoFactor(c *gin.Context) {
+	username := auth.UserFromContext(c)
+	if username == "" {
+		common.FailWithStatus(c, fmt.Errorf("authentication required"), http.StatusUnauthorized)
+		return
+	}
+	request := new(v1.TwoFactorCodeRequest)
+	if err := c.ShouldBindJSON(request); err != nil {
+		common.FailWithStatus(c, err, http.StatusBadRequest)
+		return
+	}
+	if err := auth.DisableTwoFactor(c, username, request.Code); err != nil {
+		failTwoFactor(c, err)
+		return
+	}
+	common.Success(c, "Two-factor authentication disabled")
+}
+
+func handleRegenerateRecoveryCodes(c *gin.Context) {
+	username := auth.UserFromContext(c)
+	if username == "" {
+		common.FailWithStatus(c, fmt.Errorf("authentication required"), http.StatusUnauthorized)
+		return
+	}
+	request := new(v1.TwoFactorCodeRequest)
+	if err := c.ShouldBindJSON(request); err != nil {
+		common.FailWithStatus(c, err, http.StatusBadRequest)
+		return
+	}
+	codes, err := auth.RegenerateRecoveryCodes(c, username, request.Code)
+	if err != nil {
+		failTwoFactor(c, err)
+		return
+	}
+	common.Success(c, v1.RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// twoFactorUser returns the user enrolling in two-factor authentication, either signed in or
+// holding the enrollment token of a login. It writes the error response when there is none.
+func twoFactorUser(c *gin.Context) (string, *auth.Claims, bool) {
+	if username := auth.UserFromContext(c); username != "" {
+		return username, nil, true
+	}
+	claims, err := auth.ParseEnrollmentToken(client.GetBearerToken(c.Request))
+	if err != nil {
+		common.FailWithStatus(c, fmt.Errorf("authentication required"), http.StatusUnauthorized)
+		return "", nil, false
+	}
+	return claims.Username, claims, true
+}
+
+func failTwoFactor(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, auth.ErrInvalidCode):
+		common.FailWithStatus(c, err, http.StatusUnauthorized)
+	case errors.Is(err, auth.ErrAccountLocked):
+		common.FailWithStatus(c, err, http.StatusLocked)
+	default:
+		klog.InfoS("Two-factor request rejected", "error", err)
+		common.FailWithStatus(c, err, http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,138 @@
+/*
+Copyright 2024 The Karmada Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/karmada-io/dashboard/pkg/auth"
+	"github.com/karmada-io/dashboard/pkg/auth/fga"
+)
+
+func TestAuthorizeClusterWrite(t *testing.T) {
+	fga.FGAService = fga.NewServiceWithClient(fga.NewFakeClient(
+		fga.Tuple{User: "alice", Relation: fga.RelationEditor, ObjectType: fga.TypeCluster, ObjectID: "member1"},
+		fga.Tuple{User: "bob", Relation: fga.RelationViewer, ObjectType: fga.TypeCluster, ObjectID: "member1"},
+	))
+	t.Cleanup(func() { fga.FGAService = nil })
+
+	cases := []struct {
+		username      string
+		tokenClusters []string
+		cluster       string
+		status        int
+	}{
+		{"", nil, "member1", http.StatusUnauthorized},
+		{"alice", nil, "member1", http.StatusOK},
+		{"alice", nil, "member2", http.StatusForbidden},
+		{"alice", []string{"member2"}, "member1", http.StatusForbidden},
+		{"bob", nil, "member1", http.StatusForbidden},
+	}
+
+	for _, c := range cases {
+		ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+		ctx.Request = httptest.NewRequest(http.MethodPost, "/", nil)
+		ctx.Request = ctx.Request.WithContext(auth.WithAPITokenClusters(auth.WithUser(ctx.Request.Context(), c.username), c.tokenClusters))
+		if status, err := authorizeClusterWrite(ctx, c.cluster); status != c.status {
+			t.Errorf("authorizeClusterWrite(%q, %v, %q) == %d, %v, expected %d", c.username, c.tokenClusters, c.cluster, status, err, c.status)
+		}
+	}
+}
+
+func TestAuthorizePrune(t *testing.T) {
+	fga.FGAService = fga.NewServiceWithClient(fga.NewFakeClient(
+		fga.Tuple{User: "admin", Relation: fga.RelationAdmin, ObjectType: fga.TypeDashboard, ObjectID: fga.TypeDashboard},
+		fga.Tuple{User: "alice", Relation: fga.RelationEditor, ObjectType: fga.TypeCluster, ObjectID: "member1"},
+		fga.Tuple{User: "alice", Relation: fga.RelationEditor, ObjectType: fga.TypeCluster, ObjectID: "member2"},
+		fga.Tuple{User: "bob", Relation: fga.RelationViewer, ObjectType: fga.TypeCluster, ObjectID: "member1"},
+	))
+	t.Cleanup(func() { fga.FGAService = nil })
+
+	cases := []struct {
+		username string
+		clusters []interface{}
+		status   int
+	}{
+		{"", []interface{}{"member1"}, http.StatusUnauthorized},
+		{"bob", []interface{}{"member1"}, http.StatusForbidden},
+		{"alice", []interface{}{"member1"}, http.StatusOK},
+		{"alice", []interface{}{"member1", "member3"}, http.StatusForbidden},
+		{"alice", nil, http.StatusForbidden},
+		{"admin", nil, http.StatusOK},
+	}
+
+	for _, c := range cases {
+		sm := &unstructured.Unstructured{Object: map[string]interface{}{"spec": map[string]interface{}{}}}
+		if c.clusters != nil {
+			sm.Object["spec"] = map[string]interface{}{"sourceClusters": c.clusters}
+		}
+		ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+		ctx.Request = httptest.NewRequest(http.MethodPost, "/", nil)
+		ctx.Request = ctx.Request.WithContext(auth.WithUser(ctx.Request.Context(), c.username))
+		if status, err := authorizePrune(ctx, sm); status != c.status {
+			t.Errorf("authorizePrune(%q, %v) == %d, %v, expected %d", c.username, c.clusters, status, err, c.status)
+		}
+	}
+}
+
+func TestCreateRecoveryRequiresClusterWriteAccess(t *testing.T) {
+	fga.FGAService = fga.NewServiceWithClient(fga.NewFakeClient(
+		fga.Tuple{User: "alice", Relation: fga.RelationEditor, ObjectType: fga.TypeCluster, ObjectID: "member1"},
+		fga.Tuple{User: "bob", Relation: fga.RelationViewer, ObjectType: fga.TypeCluster, ObjectID: "member1"},
+		fga.Tuple{User: "bob", Relation: fga.RelationViewer, ObjectType: fga.TypeCluster, ObjectID: "member2"},
+	))
+	t.Cleanup(func() { fga.FGAService = nil })
+
+	cases := []struct {
+		name     string
+		handler  gin.HandlerFunc
+		username string
+		body     string
+		status   int
+	}{
+		{"anonymous", handleCreateRecovery, "",
+			`{"name":"r","backupId":"b","targetCluster":"member1","recoveryType":"restore","skipPreflight":true}`, http.StatusUnauthorized},
+		{"viewer skipping preflight", handleCreateRecovery, "bob",
+			`{"name":"r","backupId":"b","targetCluster":"member1","recoveryType":"restore","skipPreflight":true}`, http.StatusForbidden},
+		{"viewer", handleCreateRecovery, "bob",
+			`{"name":"r","backupId":"b","targetCluster":"member1","recoveryType":"restore"}`, http.StatusForbidden},
+		{"fan-out anonymous", handleCreateFanOutRecovery, "",
+			`{"name":"r","backupId":"b","recoveryType":"restore","targets":[{"cluster":"member1"}]}`, http.StatusUnauthorized},
+		{"fan-out viewer", handleCreateFanOutRecovery, "bob",
+			`{"name":"r","backupId":"b","recoveryType":"restore","targets":[{"cluster":"member1"}]}`, http.StatusForbidden},
+		{"fan-out editor of one target", handleCreateFanOutRecovery, "alice",
+			`{"name":"r","backupId":"b","recoveryType":"restore","targets":[{"cluster":"member1"},{"cluster":"member2"}]}`, http.StatusForbidden},
+	}
+
+	for _, c := range cases {
+		recorder := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(recorder)
+		ctx.Request = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(c.body))
+		ctx.Request.Header.Set("Content-Type", "application/json")
+		ctx.Request = ctx.Request.WithContext(auth.WithUser(ctx.Request.Context(), c.username))
+		c.handler(ctx)
+		if recorder.Code != c.status {
+			t.Errorf("%s: status == %d, expected %d: %s", c.name, recorder.Code, c.status, recorder.Body.String())
+		}
+	}
+}
@@ -38,20 +38,22 @@ import (
 
 // BackupConfiguration represents a backup configuration
 type BackupConfiguration struct {
-	ID           string         `json:"id"`
-	Name         string         `json:"name"`
-	Cluster      string         `json:"cluster"`
-	ResourceType string         `json:"resourceType"` // "pod" or "statefulset"
-	ResourceName string         `json:"resourceName"`
-	Namespace    string         `json:"namespace"`
-	Registry     RegistryInfo   `json:"registry"`
-	Repository   string         `json:"repository"`
-	Schedule     ScheduleConfig `json:"schedule"`
-	Status       string         `json:"status"`
-	LastBackup   string         `json:"lastBackup,omitempty"`
-	NextBackup   string         `json:"nextBackup,omitempty"`
-	CreatedAt    string         `json:"createdAt"`
-	UpdatedAt    string         `json:"updatedAt"`
+	ID           string           `json:"id"`
+	Name         string           `json:"name"`
+	Cluster      string           `json:"cluster"`
+	ResourceType string           `json:"resourceType"` // "pod", "statefulset", "deployment", "daemonset" or "namespace"
+	ResourceName string           `json:"resourceName"`
+	Namespace    string           `json:"namespace"`
+	Scope        *BackupScope     `json:"scope,omitempty"` // Only set for namespace backups
+	Retention    *RetentionPolicy `json:"retention,omitempty"`
+	Registry     RegistryInfo     `json:"registry"`
+	Repository   string           `json:"repository"`
+	Schedule     ScheduleConfig   `json:"schedule"`
+	Status       string           `json:"status"`
+	LastBackup   string           `json:"lastBackup,omitempty"`
+	NextBackup   string           `json:"nextBackup,omitempty"`
+	CreatedAt    string           `json:"createdAt"`
+	UpdatedAt    string           `json:"updatedAt"`
 }
 
 // RegistryInfo represents registry information for backup
@@ -70,26 +72,30 @@ type ScheduleConfig struct {
 
 // CreateBackupRequest represents the request to create a new backup
 type CreateBackupRequest struct {
-	Name         string         `json:"name" binding:"required"`
-	Cluster      string         `json:"cluster" binding:"required"`
-	ResourceType string         `json:"resourceType" binding:"required,oneof=pod statefulset"`
-	ResourceName string         `json:"resourceName" binding:"required"`
-	Namespace    string         `json:"namespace" binding:"required"`
-	RegistryID   string         `json:"registryId" binding:"required"`
-	Repository   string         `json:"repository" binding:"required"`
-	Schedule     ScheduleConfig `json:"schedule" binding:"required"`
+	Name         string           `json:"name" binding:"required"`
+	Cluster      string           `json:"cluster" binding:"required"`
+	ResourceType string           `json:"resourceType" binding:"required,oneof=pod statefulset deployment daemonset namespace"`
+	ResourceName string           `json:"resourceName" binding:"required_unless=ResourceType namespace"`
+	Namespace    string           `json:"namespace" binding:"required"`
+	Scope        *BackupScope     `json:"scope,omitempty"`
+	Retention    *RetentionPolicy `json:"retention,omitempty"`
+	RegistryID   string           `json:"registryId" binding:"required"`
+	Repository   string           `json:"repository" binding:"required"`
+	Schedule     ScheduleConfig   `json:"schedule" binding:"required"`
 }
 
 // UpdateBackupRequest represents the request to update a backup
 type UpdateBackupRequest struct {
-	Name         string         `json:"name"`
-	Cluster      string         `json:"cluster"`
-	ResourceType string         `json:"resourceType"`
-	ResourceName string         `json:"resourceName"`
-	Namespace    string         `json:"namespace"`
-	RegistryID   string         `json:"registryId"`
-	Repository   string         `json:"repository"`
-	Schedule     ScheduleConfig `json:"schedule"`
+	Name         string           `json:"name"`
+	Cluster      string           `json:"cluster"`
+	ResourceType string           `json:"resourceType" binding:"omitempty,oneof=pod statefulset deployment daemonset namespace"`
+	ResourceName string           `json:"resourceName"`
+	Namespace    string           `json:"namespace"`
+	Scope        *BackupScope     `json:"scope,omitempty"`     // Replaces the scope of a namespace backup when set
+	Retention    *RetentionPolicy `json:"retention,omitempty"` // Replaces the retention policy when set, empty keeps everything
+	RegistryID   string           `json:"registryId"`
+	Repository   string           `json:"repository"`
+	Schedule     ScheduleConfig   `json:"schedule"`
 }
 
 // BackupExecutionRequest represents a request to execute a backup immediately
@@ -178,6 +184,14 @@ func handleCreateBackup(c *gin.Context) {
 		}
 	}
 
+	if err := validateBackupTarget(req.ResourceType, req.ResourceName, req.Namespace, req.Scope); err != nil {
+		common.Fail(c, err)
+		return
+	}
+	if req.ResourceType == resourceTypeNamespace {
+		req.ResourceName = req.Namespace
+	}
+
 	// Get registry information
 	registry, err := getRegistryByID(req.RegistryID)
 	if err != nil {
@@ -190,7 +204,11 @@ func handleCreateBackup(c *gin.Context) {
 	backupID := generateBackupID(req.Name)
 
 	// Create StatefulMigration CR
-	statefulMigration := createStatefulMigrationCR(backupID, req, registry)
+	statefulMigration, err := createStatefulMigrationCR(backupID, req, registry)
+	if err != nil {
+		common.Fail(c, err)
+		return
+	}
 
 	dynamicClient, err := client.GetDynamicClient()
 	if err != nil {
@@ -238,7 +256,11 @@ func handleUpdateBackup(c *gin.Context) {
 	}
 
 	// Update the CR with new values
-	updated := updateStatefulMigrationCR(unstructuredObj, req)
+	updated, err := updateStatefulMigrationCR(unstructuredObj, req)
+	if err != nil {
+		common.Fail(c, err)
+		return
+	}
 
 	_, err = dynamicClient.Resource(statefulMigrationGVR).Namespace(defaultNamespace).Update(context.TODO(),
 		updated, metav1.UpdateOptions{})
@@ -322,16 +344,21 @@ func handleExecuteBackup(c *gin.Context) {
 	})
 }
 
-// handleGetResourcesInCluster gets available resources (pods/statefulsets) in a specific cluster
+// handleGetResourcesInCluster gets the resources in a specific cluster that can be backed up,
+// optionally filtered by the labelSelector query parameter
 func handleGetResourcesInCluster(c *gin.Context) {
 	clusterName := c.Param("cluster")
-	resourceType := c.Query("type") // "pod" or "statefulset"
+	resourceType := c.Query("type") // "pod", "statefulset", "deployment", "daemonset" or "namespace"
 	namespace := c.Query("namespace")
 
 	if resourceType == "" {
 		common.Fail(c, fmt.Errorf("resource type is required"))
 		return
 	}
+	if t, ok := backupResourceTypes[resourceType]; !ok || (!t.Workload && resourceType != resourceTypeNamespace) {
+		common.Fail(c, fmt.Errorf("unsupported resource type: %s", resourceType))
+		return
+	}
 
 	// Get member cluster client
 	memberClient, err := getMemberClusterClient(c, clusterName)
@@ -341,20 +368,47 @@ func handleGetResourcesInCluster(c *gin.Context) {
 		return
 	}
 
-	var resources []map[string]interface{}
+	resources, err := getResourcesOfType(memberClient.(dynamic.Interface), resourceType, namespace, c.Query("labelSelector"))
+	if err != nil {
+		klog.ErrorS(err, "Failed to get resources", "cluster", clusterName, "type", resourceType)
+		common.Fail(c, err)
+		return
+	}
 
-	switch resourceType {
-	case "pod":
-		resources, err = getPodsInCluster(memberClient, namespace)
-	case "statefulset":
-		resources, err = getStatefulSetsInCluster(memberClient, namespace)
-	default:
-		common.Fail(c, fmt.Errorf("unsupported resource type: %s", resourceType))
+	common.Success(c, map[string]interface{}{
+		"resources": resources,
+		"total":     len(resources),
+	})
+}
+
+// handleGetNamespaceBackupContents previews what a namespace backup captures. The scope is given by
+// the labelSelector, excludeLabelSelector and includedResources (comma separated) query parameters.
+func handleGetNamespaceBackupContents(c *gin.Context) {
+	clusterName := c.Param("cluster")
+	namespace := c.Param("namespace")
+
+	scope := &BackupScope{
+		LabelSelector:        c.Query("labelSelector"),
+		ExcludeLabelSelector: c.Query("excludeLabelSelector"),
+	}
+	if included := c.Query("includedResources"); included != "" {
+		scope.IncludedResources = strings.Split(included, ",")
+	}
+	if err := scope.validate(); err != nil {
+		common.Fail(c, err)
 		return
 	}
 
+	memberClient, err := getMemberClusterClient(c, clusterName)
 	if err != nil {
-		klog.ErrorS(err, "Failed to get resources", "cluster", clusterName, "type", resourceType)
+		klog.ErrorS(err, "Failed to get member cluster client", "cluster", clusterName)
+		common.Fail(c, err)
+		return
+	}
+
+	resources, err := getNamespaceBackupContents(memberClient.(dynamic.Interface), namespace, *scope)
+	if err != nil {
+		klog.ErrorS(err, "Failed to get namespace backup contents", "cluster", clusterName, "namespace", namespace)
 		common.Fail(c, err)
 		return
 	}
@@ -394,6 +448,11 @@ func statefulMigrationToBackup(sm *unstructured.Unstructured) BackupConfiguratio
 	if repository, found, _ := unstructured.NestedString(sm.Object, "spec", "registry", "repository"); found {
 		backup.Repository = repository
 	}
+	if strings.EqualFold(backup.ResourceType, resourceTypeNamespace) {
+		scope := backupScopeFromSpec(sm)
+		backup.Scope = &scope
+	}
+	backup.Retention = retentionFromSpec(sm)
 
 	// Extract registry info
 	if registrySecretName, found, _ := unstructured.NestedString(sm.Object, "spec", "registry", "secretRef", "name"); found {
@@ -412,12 +471,17 @@ func statefulMigrationToBackup(sm *unstructured.Unstructured) BackupConfiguratio
 			Value:   scheduleValue,
 			Enabled: true,
 		}
+		if schedule, err := parseCronSchedule(scheduleValue); err == nil {
+			if next := schedule.next(time.Now()); !next.IsZero() {
+				backup.NextBackup = next.Format(time.RFC3339)
+			}
+		}
 	}
 
 	return backup
 }
 
-func createStatefulMigrationCR(backupID string, req CreateBackupRequest, registry RegistryCredentials) *unstructured.Unstructured {
+func createStatefulMigrationCR(backupID string, req CreateBackupRequest, registry RegistryCredentials) (*unstructured.Unstructured, error) {
 	sm := &unstructured.Unstructured{}
 	sm.SetGroupVersionKind(schema.GroupVersionKind{
 		Group:   "migration.dcnlab.com",
@@ -446,22 +510,11 @@ func createStatefulMigrationCR(backupID string, req CreateBackupRequest, registr
 		cronExpression = selectionToCron(req.Schedule.Value)
 	}
 
-	// Determine API version based on resource type
-	var apiVersion string
-	switch strings.ToLower(req.ResourceType) {
-	case "pod":
-		apiVersion = "v1"
-	case "statefulset":
-		apiVersion = "apps/v1"
-	default:
-		apiVersion = "v1" // Default fallback
-	}
-
 	// Create spec according to StatefulMigration CRD format
 	spec := map[string]interface{}{
 		"sourceClusters": []string{req.Cluster},
 		"resourceRef": map[string]interface{}{
-			"apiVersion": apiVersion,
+			"apiVersion": resourceAPIVersion(req.ResourceType),
 			"kind":       req.ResourceType,
 			"name":       req.ResourceName,
 			"namespace":  req.Namespace,
@@ -475,6 +528,14 @@ func createStatefulMigrationCR(backupID string, req CreateBackupRequest, registr
 		},
 		"schedule": cronExpression, // Should be a string (cron expression)
 	}
+	if req.Scope != nil {
+		if err := req.Scope.toSpec(spec); err != nil {
+			return nil, err
+		}
+	}
+	if req.Retention != nil {
+		req.Retention.toSpec(spec)
+	}
 
 	sm.Object = map[string]interface{}{
 		"apiVersion": "migration.dcnlab.com/v1",
@@ -483,12 +544,37 @@ func createStatefulMigrationCR(backupID string, req CreateBackupRequest, registr
 		"spec":       spec,
 	}
 
-	return sm
+	return sm, nil
 }
 
-func updateStatefulMigrationCR(sm *unstructured.Unstructured, req UpdateBackupRequest) *unstructured.Unstructured {
+func updateStatefulMigrationCR(sm *unstructured.Unstructured, req UpdateBackupRequest) (*unstructured.Unstructured, error) {
 	spec, _, _ := unstructured.NestedMap(sm.Object, "spec")
 
+	// Validate the resulting target, since the update can change any part of it
+	resourceType, _, _ := unstructured.NestedString(spec, "resourceRef", "kind")
+	resourceName, _, _ := unstructured.NestedString(spec, "resourceRef", "name")
+	namespace, _, _ := unstructured.NestedString(spec, "resourceRef", "namespace")
+	if req.ResourceType != "" {
+		resourceType = req.ResourceType
+	}
+	if req.ResourceName != "" {
+		resourceName = req.ResourceName
+	}
+	if req.Namespace != "" {
+		namespace = req.Namespace
+		if strings.EqualFold(resourceType, resourceTypeNamespace) && req.ResourceName == "" {
+			resourceName = namespace
+		}
+	}
+	scope := req.Scope
+	if scope == nil && strings.EqualFold(resourceType, resourceTypeNamespace) {
+		existing := backupScopeFromSpec(sm)
+		scope = &existing
+	}
+	if err := validateBackupTarget(resourceType, resourceName, namespace, scope); err != nil {
+		return nil, err
+	}
+
 	// Update fields if provided
 	if req.Name != "" {
 		sm.SetName(req.Name)
@@ -504,28 +590,25 @@ func updateStatefulMigrationCR(sm *unstructured.Unstructured, req UpdateBackupRe
 			resourceRef = make(map[string]interface{})
 		}
 		if req.ResourceType != "" {
-			// Determine API version based on resource type
-			var apiVersion string
-			switch strings.ToLower(req.ResourceType) {
-			case "pod":
-				apiVersion = "v1"
-			case "statefulset":
-				apiVersion = "apps/v1"
-			default:
-				apiVersion = "v1" // Default fallback
-			}
-			resourceRef["apiVersion"] = apiVersion
+			resourceRef["apiVersion"] = resourceAPIVersion(req.ResourceType)
 			resourceRef["kind"] = req.ResourceType
 		}
-		if req.ResourceName != "" {
-			resourceRef["name"] = req.ResourceName
-		}
+		resourceRef["name"] = resourceName
 		if req.Namespace != "" {
 			resourceRef["namespace"] = req.Namespace
 		}
 		spec["resourceRef"] = resourceRef
 	}
 
+	// Namespace backups keep their scope, other backups have none
+	if strings.EqualFold(resourceType, resourceTypeNamespace) {
+		if err := scope.toSpec(spec); err != nil {
+			return nil, err
+		}
+	} else if err := (BackupScope{}).toSpec(spec); err != nil {
+		return nil, err
+	}
+
 	// Update registry
 	if req.RegistryID != "" || req.Repository != "" {
 		registry, _, _ := unstructured.NestedMap(spec, "registry")
@@ -549,6 +632,10 @@ func updateStatefulMigrationCR(sm *unstructured.Unstructured, req UpdateBackupRe
 		spec["registry"] = registry
 	}
 
+	if req.Retention != nil {
+		req.Retention.toSpec(spec)
+	}
+
 	if req.Schedule.Type != "" {
 		var cronExpression string
 		if req.Schedule.Type == "selection" {
@@ -568,7 +655,7 @@ func updateStatefulMigrationCR(sm *unstructured.Unstructured, req UpdateBackupRe
 	sm.SetAnnotations(annotations)
 
 	unstructured.SetNestedMap(sm.Object, spec, "spec")
-	return sm
+	return sm, nil
 }
 
 func generateBackupID(name string) string {
@@ -591,12 +678,8 @@ func selectionToCron(selection string) string {
 }
 
 func validateCronExpression(cron string) error {
-	// Basic cron validation - you might want to use a proper cron library
-	parts := strings.Fields(cron)
-	if len(parts) != 5 {
-		return fmt.Errorf("cron expression must have 5 fields")
-	}
-	return nil
+	_, err := parseCronSchedule(cron)
+	return err
 }
 
 func getRegistryByName(secretName string) (RegistryCredentials, error) {
@@ -672,103 +755,6 @@ func getMemberClusterClient(c *gin.Context, clusterName string) (interface{}, er
 	return dynamicClient, nil
 }
 
-func getPodsInCluster(client interface{}, namespace string) ([]map[string]interface{}, error) {
-	dynamicClient, ok := client.(dynamic.Interface)
-	if !ok {
-		return nil, fmt.Errorf("invalid client type for pods")
-	}
-
-	// Define Pod GVR
-	podGVR := schema.GroupVersionResource{
-		Group:    "",
-		Version:  "v1",
-		Resource: "pods",
-	}
-
-	// List pods in the specified namespace (or all namespaces if empty)
-	var unstructuredList *unstructured.UnstructuredList
-	var err error
-
-	if namespace != "" {
-		unstructuredList, err = dynamicClient.Resource(podGVR).Namespace(namespace).List(context.TODO(), metav1.ListOptions{})
-	} else {
-		unstructuredList, err = dynamicClient.Resource(podGVR).List(context.TODO(), metav1.ListOptions{})
-	}
-
-	if err != nil {
-		return nil, fmt.Errorf("failed to list pods: %v", err)
-	}
-
-	resources := make([]map[string]interface{}, 0, len(unstructuredList.Items))
-	for _, item := range unstructuredList.Items {
-		pod := map[string]interface{}{
-			"name":       item.GetName(),
-			"namespace":  item.GetNamespace(),
-			"kind":       "Pod",
-			"apiVersion": "v1",
-		}
-
-		// Get pod status
-		if status, found, _ := unstructured.NestedString(item.Object, "status", "phase"); found {
-			pod["status"] = status
-		}
-
-		resources = append(resources, pod)
-	}
-
-	return resources, nil
-}
-
-func getStatefulSetsInCluster(client interface{}, namespace string) ([]map[string]interface{}, error) {
-	dynamicClient, ok := client.(dynamic.Interface)
-	if !ok {
-		return nil, fmt.Errorf("invalid client type for statefulsets")
-	}
-
-	// Define StatefulSet GVR
-	statefulSetGVR := schema.GroupVersionResource{
-		Group:    "apps",
-		Version:  "v1",
-		Resource: "statefulsets",
-	}
-
-	// List statefulsets in the specified namespace (or all namespaces if empty)
-	var unstructuredList *unstructured.UnstructuredList
-	var err error
-
-	if namespace != "" {
-		unstructuredList, err = dynamicClient.Resource(statefulSetGVR).Namespace(namespace).List(context.TODO(), metav1.ListOptions{})
-	} else {
-		unstructuredList, err = dynamicClient.Resource(statefulSetGVR).List(context.TODO(), metav1.ListOptions{})
-	}
-
-	if err != nil {
-		return nil, fmt.Errorf("failed to list statefulsets: %v", err)
-	}
-
-	resources := make([]map[string]interface{}, 0, len(unstructuredList.Items))
-	for _, item := range unstructuredList.Items {
-		statefulSet := map[string]interface{}{
-			"name":       item.GetName(),
-			"namespace":  item.GetNamespace(),
-			"kind":       "StatefulSet",
-			"apiVersion": "apps/v1",
-		}
-
-		// Get statefulset status
-		if replicas, found, _ := unstructured.NestedInt64(item.Object, "status", "replicas"); found {
-			statefulSet["replicas"] = replicas
-		}
-		if readyReplicas, found, _ := unstructured.NestedInt64(item.Object, "status", "readyReplicas"); found {
-			statefulSet["readyReplicas"] = readyReplicas
-		}
-
-		resources = append(resources, statefulSet)
-	}
-
-	return resources, nil
-}
-
 // Register backup routes
 func init() {
 	r := router.V1()
@@ -782,6 +768,10 @@ func init() {
 		backupGroup.PUT("/:id", handleUpdateBackup)
 		backupGroup.DELETE("/:id", handleDeleteBackup)
 		backupGroup.POST("/:id/execute", handleExecuteBackup)
+		backupGroup.GET("/:id/prune", handlePreviewPrune)
+		backupGroup.POST("/:id/prune", handlePrune)
+		backupGroup.POST("/:id/verify", handleVerifyBackup)
 		backupGroup.GET("/clusters/:cluster/resources", handleGetResourcesInCluster)
+		backupGroup.GET("/clusters/:cluster/namespaces/:namespace/contents", handleGetNamespaceBackupContents)
 	}
 }
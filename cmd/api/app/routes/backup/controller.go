@@ -0,0 +1,904 @@
+/*
+Copyright 2024 The Karmada Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"text/template"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	utilversion "k8s.io/apimachinery/pkg/util/version"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/klog/v2"
+
+	"github.com/karmada-io/dashboard/cmd/api/app/types/common"
+	"github.com/karmada-io/dashboard/pkg/client"
+)
+
+// Statuses of migration controller installations
+const (
+	ControllerProgressing = "Progressing"
+	ControllerInstalled   = "Installed"
+	ControllerFailed      = "Failed"
+	ControllerRolledBack  = "RolledBack"
+)
+
+// Actions of manifest changes
+const (
+	ManifestAdded     = "Added"
+	ManifestRemoved   = "Removed"
+	ManifestChanged   = "Changed"
+	ManifestUnchanged = "Unchanged"
+)
+
+const (
+	defaultControllerVersion = "v2.0"
+	managementClusterName    = "mgmt-cluster"
+	controllerNamespace      = "stateful-migration"
+
+	// uninstallPolicyAnnotation set to "keep" leaves an object behind on uninstall, e.g. the
+	// namespace holding the backups or the CRD of the backups
+	uninstallPolicyAnnotation = "migration.dcnlab.com/uninstall-policy"
+	// controllerInstallationPrefix names the ConfigMaps recording the installation of each cluster
+	controllerInstallationPrefix = "migration-controller-"
+
+	// controllerRolloutTimeout is how long the workloads of a version have to become ready before
+	// the previous version is restored
+	controllerRolloutTimeout      = 5 * time.Minute
+	controllerRolloutPollInterval = 10 * time.Second
+)
+
+// controllerManifests holds the manifests of each controller version, in
+// manifests/<version>/management.yaml and manifests/<version>/member.yaml
+//
+//go:embed manifests
+var controllerManifests embed.FS
+
+// ManifestObject identifies an object of the controller manifests
+type ManifestObject struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Namespace  string `json:"namespace,omitempty"`
+	Name       string `json:"name"`
+	// Keep leaves the object behind on uninstall
+	Keep bool `json:"keep,omitempty"`
+	// Preexisting marks an object that existed before the dashboard installed the controller, it
+	// is left behind on uninstall and rollback like kept objects
+	Preexisting bool `json:"preexisting,omitempty"`
+}
+
+// key identifies the object across versions, so that a new API version of a kind is a change.
+func (o ManifestObject) key() string {
+	return o.Kind + "/" + o.Namespace + "/" + o.Name
+}
+
+// String describes the object in messages.
+func (o ManifestObject) String() string {
+	if o.Namespace == "" {
+		return o.Kind + " " + o.Name
+	}
+	return o.Kind + " " + o.Namespace + "/" + o.Name
+}
+
+// FieldChange is a field whose value differs between two versions of an object
+type FieldChange struct {
+	Path    string      `json:"path"`
+	Current interface{} `json:"current,omitempty"`
+	Target  interface{} `json:"target,omitempty"`
+}
+
+// ManifestChange is the change of an object between two versions of the controller manifests
+type ManifestChange struct {
+	Object ManifestObject `json:"object"`
+	Action string         `json:"action"`
+	Fields []FieldChange  `json:"fields,omitempty"`
+}
+
+// ControllerUpgradePlan lists the changes upgrading the controller of a cluster would make
+type ControllerUpgradePlan struct {
+	Cluster        string           `json:"cluster"`
+	CurrentVersion string           `json:"currentVersion,omitempty"` // Empty if the dashboard did not install the controller
+	TargetVersion  string           `json:"targetVersion"`
+	Changes        []ManifestChange `json:"changes"`
+}
+
+// ControllerInstallation records the version of the migration controller installed on a cluster
+// and the objects it consists of
+type ControllerInstallation struct {
+	Cluster         string           `json:"cluster"`
+	Version         string           `json:"version,omitempty"` // Empty when a first installation failed
+	PreviousVersion string           `json:"previousVersion,omitempty"`
+	Status          string           `json:"status"`
+	Message         string           `json:"message,omitempty"`
+	Objects         []ManifestObject `json:"objects"`
+	UpdatedAt       time.Time        `json:"updatedAt"`
+	RolloutDeadline time.Time        `json:"rolloutDeadline,omitempty"`
+}
+
+// UpgradeControllerRequest represents the request to upgrade the migration controller of a cluster
+type UpgradeControllerRequest struct {
+	Version string `json:"version" binding:"required"`
+}
+
+// isManagementCluster reports whether a cluster name refers to the management cluster.
+func isManagementCluster(clusterName string) bool {
+	return clusterName == managementClusterName || clusterName == "management"
+}
+
+// controllerVersions lists the embedded controller versions, oldest first.
+func controllerVersions() ([]string, error) {
+	entries, err := controllerManifests.ReadDir("manifests")
+	if err != nil {
+		return nil, err
+	}
+	versions := []string{}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			versions = append(versions, entry.Name())
+		}
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		a, errA := utilversion.ParseGeneric(versions[i])
+		b, errB := utilversion.ParseGeneric(versions[j])
+		if errA != nil || errB != nil {
+			return versions[i] < versions[j]
+		}
+		return a.LessThan(b)
+	})
+	return versions, nil
+}
+
+// renderControllerManifests renders the objects of a controller version for a cluster, in the
+// order they are applied.
+func renderControllerManifests(version, clusterName string) ([]*unstructured.Unstructured, error) {
+	versions, err := controllerVersions()
+	if err != nil {
+		return nil, err
+	}
+	if !slices.Contains(versions, version) {
+		return nil, fmt.Errorf("migration controller version %s is not available, available versions are %v", version, versions)
+	}
+
+	file := "member.yaml"
+	if isManagementCluster(clusterName) {
+		file = "management.yaml"
+	}
+	content, err := controllerManifests.ReadFile(path.Join("manifests", version, file))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("migration controller version %s has no %s", version, file)
+	}
+	if err != nil {
+		return nil, err
+	}
+	tmpl, err := template.New(file).Option("missingkey=error").Parse(string(content))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse manifests of version %s: %v", version, err)
+	}
+	var rendered bytes.Buffer
+	if err := tmpl.Execute(&rendered, map[string]string{
+		"Cluster":   clusterName,
+		"Namespace": controllerNamespace,
+	}); err != nil {
+		return nil, fmt.Errorf("failed to render manifests of version %s: %v", version, err)
+	}
+
+	var objects []*unstructured.Unstructured
+	decoder := yaml.NewYAMLOrJSONDecoder(&rendered, 4096)
+	for {
+		var rawObj map[string]interface{}
+		err := decoder.Decode(&rawObj)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode manifests of version %s: %v", version, err)
+		}
+		if rawObj == nil {
+			continue
+		}
+		obj := &unstructured.Unstructured{Object: rawObj}
+		if obj.GetAPIVersion() == "" || obj.GetKind() == "" || obj.GetName() == "" {
+			return nil, fmt.Errorf("manifests of version %s contain an object without apiVersion, kind or name", version)
+		}
+		objects = append(objects, obj)
+	}
+	return objects, nil
+}
+
+// manifestObjectOf identifies an object of the controller manifests.
+func manifestObjectOf(obj *unstructured.Unstructured) ManifestObject {
+	return ManifestObject{
+		APIVersion: obj.GetAPIVersion(),
+		Kind:       obj.GetKind(),
+		Namespace:  obj.GetNamespace(),
+		Name:       obj.GetName(),
+		Keep:       obj.GetAnnotations()[uninstallPolicyAnnotation] == "keep",
+	}
+}
+
+// manifestObjects identifies the objects of the controller manifests.
+func manifestObjects(objects []*unstructured.Unstructured) []ManifestObject {
+	result := make([]ManifestObject, 0, len(objects))
+	for _, obj := range objects {
+		result = append(result, manifestObjectOf(obj))
+	}
+	return result
+}
+
+// withPreexisting marks the objects that are marked preexisting in any of from.
+func withPreexisting(objects []ManifestObject, from ...[]ManifestObject) []ManifestObject {
+	preexisting := map[string]bool{}
+	for _, list := range from {
+		for _, obj := range list {
+			if obj.Preexisting {
+				preexisting[obj.key()] = true
+			}
+		}
+	}
+	result := slices.Clone(objects)
+	for i := range result {
+		result[i].Preexisting = result[i].Preexisting || preexisting[result[i].key()]
+	}
+	return result
+}
+
+// markPreexisting marks the objects that exist on the cluster already, before they are applied.
+// Objects of the previous installation keep their mark instead, since the dashboard may have
+// created them. Preexisting objects are never deleted, so that a rollback or an uninstall does not
+// remove what someone else installed.
+func markPreexisting(ctx context.Context, dynamicClient dynamic.Interface, objects []ManifestObject, previous *ControllerInstallation) ([]ManifestObject, error) {
+	installed := map[string]bool{}
+	var previousObjects []ManifestObject
+	if previous != nil {
+		previousObjects = previous.Objects
+		for _, obj := range previous.Objects {
+			installed[obj.key()] = true
+		}
+	}
+
+	result := withPreexisting(objects, previousObjects)
+	for i := range result {
+		if installed[result[i].key()] {
+			continue
+		}
+		resource, err := controllerResource(dynamicClient, result[i])
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve %s: %v", result[i], err)
+		}
+		_, err = resource.Get(ctx, result[i].Name, metav1.GetOptions{})
+		switch {
+		case err == nil:
+			result[i].Preexisting = true
+		case !apierrors.IsNotFound(err):
+			return nil, fmt.Errorf("failed to get %s: %v", result[i], err)
+		}
+	}
+	return result, nil
+}
+
+// missingObjects returns the objects of from that are not in to.
+func missingObjects(from, to []ManifestObject) []ManifestObject {
+	keys := map[string]bool{}
+	for _, obj := range to {
+		keys[obj.key()] = true
+	}
+	var missing []ManifestObject
+	for _, obj := range from {
+		if !keys[obj.key()] {
+			missing = append(missing, obj)
+		}
+	}
+	return missing
+}
+
+// diffControllerManifests lists the changes between the objects of two controller versions,
+// target objects first in their apply order and then the removed ones.
+func diffControllerManifests(current, target []*unstructured.Unstructured) []ManifestChange {
+	currentByKey := map[string]*unstructured.Unstructured{}
+	for _, obj := range current {
+		currentByKey[manifestObjectOf(obj).key()] = obj
+	}
+
+	changes := []ManifestChange{}
+	seen := map[string]bool{}
+	for _, obj := range target {
+		object := manifestObjectOf(obj)
+		seen[object.key()] = true
+		existing, ok := currentByKey[object.key()]
+		if !ok {
+			changes = append(changes, ManifestChange{Object: object, Action: ManifestAdded})
+			continue
+		}
+		change := ManifestChange{Object: object, Action: ManifestUnchanged, Fields: diffFields("", existing.Object, obj.Object)}
+		if len(change.Fields) > 0 {
+			change.Action = ManifestChanged
+		}
+		changes = append(changes, change)
+	}
+	for _, obj := range current {
+		object := manifestObjectOf(obj)
+		if !seen[object.key()] {
+			changes = append(changes, ManifestChange{Object: object, Action: ManifestRemoved})
+		}
+	}
+	return changes
+}
+
+// diffFields lists the fields that differ between two values. Maps are compared key by key and
+// lists of the same length item by item, other values as a whole.
+func diffFields(fieldPath string, current, target interface{}) []FieldChange {
+	currentMap, currentIsMap := current.(map[string]interface{})
+	targetMap, targetIsMap := target.(map[string]interface{})
+	if currentIsMap && targetIsMap {
+		keys := make([]string, 0, len(currentMap)+len(targetMap))
+		for key := range currentMap {
+			keys = append(keys, key)
+		}
+		for key := range targetMap {
+			if _, ok := currentMap[key]; !ok {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+
+		var changes []FieldChange
+		for _, key := range keys {
+			childPath := key
+			if fieldPath != "" {
+				childPath = fieldPath + "." + key
+			}
+			changes = append(changes, diffFields(childPath, currentMap[key], targetMap[key])...)
+		}
+		return changes
+	}
+
+	currentList, currentIsList := current.([]interface{})
+	targetList, targetIsList := target.([]interface{})
+	if currentIsList && targetIsList && len(currentList) == len(targetList) {
+		var changes []FieldChange
+		for i := range currentList {
+			changes = append(changes, diffFields(fieldPath+"["+strconv.Itoa(i)+"]", currentList[i], targetList[i])...)
+		}
+		return changes
+	}
+
+	if reflect.DeepEqual(current, target) {
+		return nil
+	}
+	return []FieldChange{{Path: fieldPath, Current: current, Target: target}}
+}
+
+// controllerDynamicClient returns the client of the API server the controller objects of a
+// cluster are applied to: the management cluster, or Karmada which propagates them to members.
+func controllerDynamicClient(clusterName string) (dynamic.Interface, error) {
+	if isManagementCluster(clusterName) {
+		return client.GetDynamicClient()
+	}
+	return getKarmadaDynamicClient()
+}
+
+// controllerResource returns the client of the resource of a controller object.
+func controllerResource(dynamicClient dynamic.Interface, object ManifestObject) (dynamic.ResourceInterface, error) {
+	gv, err := schema.ParseGroupVersion(object.APIVersion)
+	if err != nil {
+		return nil, err
+	}
+	gvr, err := getGVRFromGVK(gv.WithKind(object.Kind))
+	if err != nil {
+		return nil, err
+	}
+	if object.Namespace != "" {
+		return dynamicClient.Resource(gvr).Namespace(object.Namespace), nil
+	}
+	return dynamicClient.Resource(gvr), nil
+}
+
+// applyControllerObjects server-side applies controller objects in order. Conflicting fields are
+// taken over, since the dashboard owns the controller objects.
+func applyControllerObjects(ctx context.Context, dynamicClient dynamic.Interface, objects []*unstructured.Unstructured) error {
+	for _, obj := range objects {
+		object := manifestObjectOf(obj)
+		resource, err := controllerResource(dynamicClient, object)
+		if err != nil {
+			return fmt.Errorf("failed to resolve %s: %v", object, err)
+		}
+		if _, err := resource.Apply(ctx, object.Name, obj, metav1.ApplyOptions{
+			FieldManager: client.FieldManager,
+			Force:        true,
+		}); err != nil {
+			return fmt.Errorf("failed to apply %s: %v", object, err)
+		}
+	}
+	return nil
+}
+
+// deleteControllerObjects deletes controller objects in reverse order, except the kept and the
+// preexisting ones.
+func deleteControllerObjects(ctx context.Context, dynamicClient dynamic.Interface, objects []ManifestObject) error {
+	var errs []error
+	for i := len(objects) - 1; i >= 0; i-- {
+		object := objects[i]
+		if object.Keep || object.Preexisting {
+			continue
+		}
+		resource, err := controllerResource(dynamicClient, object)
+		if err == nil {
+			err = resource.Delete(ctx, object.Name, metav1.DeleteOptions{})
+		}
+		if err != nil && !apierrors.IsNotFound(err) {
+			errs = append(errs, fmt.Errorf("failed to delete %s: %v", object, err))
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// getControllerInstallation reads the installation record of a cluster and its resource version,
+// nil if the dashboard did not install the controller.
+func getControllerInstallation(ctx context.Context, clusterName string) (*ControllerInstallation, string, error) {
+	dynamicClient, err := client.GetDynamicClient()
+	if err != nil {
+		return nil, "", err
+	}
+	obj, err := dynamicClient.Resource(configMapGVR).Namespace(backupHistoryNamespace).Get(ctx,
+		controllerInstallationPrefix+clusterName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, "", nil
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	data, _, _ := unstructured.NestedStringMap(obj.Object, "data")
+	record := &ControllerInstallation{}
+	if err := json.Unmarshal([]byte(data["installation"]), record); err != nil {
+		return nil, "", fmt.Errorf("invalid installation record of cluster %s: %v", clusterName, err)
+	}
+	return record, obj.GetResourceVersion(), nil
+}
+
+// saveControllerInstallation writes the installation record of a cluster. The record is created
+// when resourceVersion is empty and updated otherwise, so concurrent writers conflict.
+func saveControllerInstallation(ctx context.Context, record *ControllerInstallation, resourceVersion string) error {
+	encoded, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata": map[string]interface{}{
+			"name":      controllerInstallationPrefix + record.Cluster,
+			"namespace": backupHistoryNamespace,
+			"labels": map[string]interface{}{
+				"app":     "migration-controller-installation",
+				"cluster": record.Cluster,
+			},
+		},
+		"data": map[string]interface{}{
+			"installation": string(encoded),
+			"version":      record.Version,
+			"status":       record.Status,
+		},
+	}}
+
+	dynamicClient, err := client.GetDynamicClient()
+	if err != nil {
+		return err
+	}
+	configMaps := dynamicClient.Resource(configMapGVR).Namespace(backupHistoryNamespace)
+	if resourceVersion == "" {
+		_, err = configMaps.Create(ctx, obj, metav1.CreateOptions{})
+		return err
+	}
+	obj.SetResourceVersion(resourceVersion)
+	_, err = configMaps.Update(ctx, obj, metav1.UpdateOptions{})
+	return err
+}
+
+// updateControllerInstallation changes the installation record of a cluster, retrying on
+// conflicts. update returns false to leave the record unchanged.
+func updateControllerInstallation(ctx context.Context, clusterName string, update func(record *ControllerInstallation) bool) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		record, resourceVersion, err := getControllerInstallation(ctx, clusterName)
+		if err != nil || record == nil {
+			return err
+		}
+		if !update(record) {
+			return nil
+		}
+		record.UpdatedAt = time.Now()
+		return saveControllerInstallation(ctx, record, resourceVersion)
+	})
+}
+
+// installMigrationController installs a version of the migration controller on a cluster, or
+// upgrades or downgrades the installed one. The objects are server-side applied and the objects
+// of the previous version that the new one lacks are deleted. If applying fails, or the workloads
+// do not become ready in time, the previous version is restored.
+func installMigrationController(ctx context.Context, clusterName, version string) (*ControllerInstallation, error) {
+	if isManagementCluster(clusterName) {
+		clusterName = managementClusterName
+	} else if _, err := client.InClusterKarmadaClient().ClusterV1alpha1().Clusters().Get(ctx, clusterName, metav1.GetOptions{}); err != nil {
+		return nil, fmt.Errorf("cluster %s not found in Karmada: %v", clusterName, err)
+	}
+	target, err := renderControllerManifests(version, clusterName)
+	if err != nil {
+		return nil, err
+	}
+	dynamicClient, err := controllerDynamicClient(clusterName)
+	if err != nil {
+		return nil, err
+	}
+
+	previous, resourceVersion, err := getControllerInstallation(ctx, clusterName)
+	if err != nil {
+		return nil, err
+	}
+	if previous != nil && previous.Status == ControllerProgressing && time.Now().Before(previous.RolloutDeadline) {
+		return nil, fmt.Errorf("version %s of the migration controller is being installed on cluster %s", previous.Version, clusterName)
+	}
+
+	objects, err := markPreexisting(ctx, dynamicClient, manifestObjects(target), previous)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	record := &ControllerInstallation{
+		Cluster:         clusterName,
+		Version:         version,
+		Status:          ControllerProgressing,
+		Objects:         objects,
+		UpdatedAt:       now,
+		RolloutDeadline: now.Add(controllerRolloutTimeout),
+	}
+	if previous != nil {
+		record.PreviousVersion = previous.Version
+		if previous.Version == version {
+			record.PreviousVersion = previous.PreviousVersion
+		}
+	}
+	if err := saveControllerInstallation(ctx, record, resourceVersion); err != nil {
+		if apierrors.IsConflict(err) || apierrors.IsAlreadyExists(err) {
+			return nil, fmt.Errorf("another installation of the migration controller on cluster %s started", clusterName)
+		}
+		return nil, fmt.Errorf("failed to record installation: %v", err)
+	}
+
+	if err := applyControllerObjects(ctx, dynamicClient, target); err != nil {
+		klog.ErrorS(err, "Failed to apply migration controller", "cluster", clusterName, "version", version)
+		return nil, rollbackMigrationController(ctx, dynamicClient, previous, record, err)
+	}
+	if previous != nil {
+		if err := deleteControllerObjects(ctx, dynamicClient, missingObjects(previous.Objects, record.Objects)); err != nil {
+			klog.ErrorS(err, "Failed to delete objects of the previous migration controller version", "cluster", clusterName, "version", previous.Version)
+		}
+	}
+
+	klog.InfoS("Applied migration controller", "cluster", clusterName, "version", version, "previousVersion", record.PreviousVersion)
+	go watchControllerRollout(dynamicClient, previous, record)
+	return record, nil
+}
+
+// rollbackMigrationController restores the version installed before a failed installation, or
+// removes the objects a failed first installation created, records the outcome and returns the
+// error to report. Kept and preexisting objects are never removed.
+func rollbackMigrationController(ctx context.Context, dynamicClient dynamic.Interface, previous, attempted *ControllerInstallation, cause error) error {
+	result := &ControllerInstallation{
+		Cluster: attempted.Cluster,
+		Status:  ControllerFailed,
+		Message: fmt.Sprintf("installing version %s failed: %v", attempted.Version, cause),
+		Objects: attempted.Objects,
+	}
+
+	switch {
+	case previous == nil || previous.Version == "":
+		// Nothing to go back to, remove what was applied
+		if err := deleteControllerObjects(ctx, dynamicClient, attempted.Objects); err != nil {
+			result.Message += fmt.Sprintf(", removing its objects failed: %v", err)
+		} else {
+			result.Objects = nil
+		}
+	case previous.Version == attempted.Version:
+		result.Version, result.PreviousVersion = previous.Version, previous.PreviousVersion
+	default:
+		objects, err := renderControllerManifests(previous.Version, attempted.Cluster)
+		if err == nil {
+			err = applyControllerObjects(ctx, dynamicClient, objects)
+		}
+		if err != nil {
+			result.Message += fmt.Sprintf(", rolling back to version %s failed: %v", previous.Version, err)
+			result.Objects = append(slices.Clone(attempted.Objects), missingObjects(previous.Objects, attempted.Objects)...)
+			break
+		}
+		result.Version, result.PreviousVersion = previous.Version, previous.PreviousVersion
+		result.Status = ControllerRolledBack
+		result.Objects = withPreexisting(manifestObjects(objects), previous.Objects, attempted.Objects)
+		result.Message += fmt.Sprintf(", rolled back to version %s", previous.Version)
+		if err := deleteControllerObjects(ctx, dynamicClient, missingObjects(attempted.Objects, result.Objects)); err != nil {
+			klog.ErrorS(err, "Failed to delete objects of the failed migration controller version", "cluster", attempted.Cluster, "version", attempted.Version)
+		}
+	}
+
+	klog.InfoS("Migration controller installation failed", "cluster", attempted.Cluster, "status", result.Status, "message", result.Message)
+	if err := updateControllerInstallation(ctx, attempted.Cluster, func(record *ControllerInstallation) bool {
+		*record = *result
+		return true
+	}); err != nil {
+		klog.ErrorS(err, "Failed to record migration controller rollback", "cluster", attempted.Cluster)
+	}
+	return errors.New(result.Message)
+}
+
+// isWorkload reports whether the rollout of an object is awaited.
+func isWorkload(object ManifestObject) bool {
+	return object.APIVersion == "apps/v1" && (object.Kind == "Deployment" || object.Kind == "DaemonSet")
+}
+
+// workloadReady reports whether a Deployment or DaemonSet runs its current spec everywhere. For
+// member clusters it reads the status Karmada aggregates from the members.
+func workloadReady(obj *unstructured.Unstructured) (bool, string) {
+	if _, found, _ := unstructured.NestedFieldNoCopy(obj.Object, "status", "observedGeneration"); found &&
+		nestedNumber(obj.Object, "status", "observedGeneration") < obj.GetGeneration() {
+		return false, "the rollout has not been observed yet"
+	}
+
+	switch obj.GetKind() {
+	case "Deployment":
+		replicas := int64(1)
+		if _, found, _ := unstructured.NestedFieldNoCopy(obj.Object, "spec", "replicas"); found {
+			replicas = nestedNumber(obj.Object, "spec", "replicas")
+		}
+		updated := nestedNumber(obj.Object, "status", "updatedReplicas")
+		available := nestedNumber(obj.Object, "status", "availableReplicas")
+		if updated < replicas || available < replicas {
+			return false, fmt.Sprintf("%d of %d replicas updated, %d available", updated, replicas, available)
+		}
+	case "DaemonSet":
+		desired := nestedNumber(obj.Object, "status", "desiredNumberScheduled")
+		updated := nestedNumber(obj.Object, "status", "updatedNumberScheduled")
+		ready := nestedNumber(obj.Object, "status", "numberReady")
+		if desired == 0 {
+			return false, "no pods scheduled yet"
+		}
+		if updated < desired || ready < desired {
+			return false, fmt.Sprintf("%d of %d pods updated, %d ready", updated, desired, ready)
+		}
+	}
+	return true, ""
+}
+
+// controllerRolloutStatus reports whether the workloads of an installation are ready, or why
+// they are not.
+func controllerRolloutStatus(ctx context.Context, dynamicClient dynamic.Interface, objects []ManifestObject) (bool, string) {
+	for _, object := range objects {
+		if !isWorkload(object) {
+			continue
+		}
+		resource, err := controllerResource(dynamicClient, object)
+		if err != nil {
+			return false, err.Error()
+		}
+		obj, err := resource.Get(ctx, object.Name, metav1.GetOptions{})
+		if err != nil {
+			return false, fmt.Sprintf("failed to get %s: %v", object, err)
+		}
+		if ready, message := workloadReady(obj); !ready {
+			return false, fmt.Sprintf("%s: %s", object, message)
+		}
+	}
+	return true, ""
+}
+
+// watchControllerRollout waits for the workloads of an installation to become ready and marks it
+// installed, or restores the previous version when the rollout deadline passes.
+func watchControllerRollout(dynamicClient dynamic.Interface, previous, record *ControllerInstallation) {
+	ctx, cancel := context.WithDeadline(context.Background(), record.RolloutDeadline)
+	defer cancel()
+	ticker := time.NewTicker(controllerRolloutPollInterval)
+	defer ticker.Stop()
+
+	// current reports whether the record still describes this installation
+	current := func(stored *ControllerInstallation) bool {
+		return stored.Status == ControllerProgressing && stored.Version == record.Version && stored.UpdatedAt.Equal(record.UpdatedAt)
+	}
+	var message string
+	for {
+		var ready bool
+		ready, message = controllerRolloutStatus(ctx, dynamicClient, record.Objects)
+		if ready {
+			if err := updateControllerInstallation(ctx, record.Cluster, func(stored *ControllerInstallation) bool {
+				if !current(stored) {
+					return false
+				}
+				stored.Status, stored.Message = ControllerInstalled, ""
+				return true
+			}); err != nil {
+				klog.ErrorS(err, "Failed to record migration controller rollout", "cluster", record.Cluster)
+			}
+			klog.InfoS("Migration controller rolled out", "cluster", record.Cluster, "version", record.Version)
+			return
+		}
+		select {
+		case <-ctx.Done():
+			rollbackCtx, rollbackCancel := context.WithTimeout(context.Background(), controllerRolloutTimeout)
+			defer rollbackCancel()
+			stored, _, err := getControllerInstallation(rollbackCtx, record.Cluster)
+			if err != nil || stored == nil || !current(stored) {
+				return
+			}
+			_ = rollbackMigrationController(rollbackCtx, dynamicClient, previous, record,
+				fmt.Errorf("the rollout did not complete within %s, %s", controllerRolloutTimeout, message))
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// uninstallMigrationController deletes the objects of the controller installed on a cluster, or
+// those of the default version if the dashboard did not install it, and its installation record.
+func uninstallMigrationController(ctx context.Context, clusterName string) error {
+	if isManagementCluster(clusterName) {
+		clusterName = managementClusterName
+	}
+	dynamicClient, err := controllerDynamicClient(clusterName)
+	if err != nil {
+		return err
+	}
+	record, _, err := getControllerInstallation(ctx, clusterName)
+	if err != nil {
+		return err
+	}
+
+	var objects []ManifestObject
+	if record != nil {
+		objects = record.Objects
+	} else {
+		rendered, err := renderControllerManifests(defaultControllerVersion, clusterName)
+		if err != nil {
+			return err
+		}
+		objects = manifestObjects(rendered)
+	}
+	if err := deleteControllerObjects(ctx, dynamicClient, objects); err != nil {
+		return err
+	}
+
+	if record != nil {
+		managementClient, err := client.GetDynamicClient()
+		if err != nil {
+			return err
+		}
+		err = managementClient.Resource(configMapGVR).Namespace(backupHistoryNamespace).Delete(ctx,
+			controllerInstallationPrefix+clusterName, metav1.DeleteOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+	}
+	klog.InfoS("Migration controller uninstallation completed", "cluster", clusterName)
+	return nil
+}
+
+// planControllerUpgrade diffs the manifests of the installed controller version of a cluster
+// against those of a target version.
+func planControllerUpgrade(ctx context.Context, clusterName, version string) (*ControllerUpgradePlan, error) {
+	if isManagementCluster(clusterName) {
+		clusterName = managementClusterName
+	}
+	target, err := renderControllerManifests(version, clusterName)
+	if err != nil {
+		return nil, err
+	}
+	record, _, err := getControllerInstallation(ctx, clusterName)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &ControllerUpgradePlan{Cluster: clusterName, TargetVersion: version}
+	var current []*unstructured.Unstructured
+	if record != nil && record.Version != "" {
+		plan.CurrentVersion = record.Version
+		if current, err = renderControllerManifests(record.Version, clusterName); err != nil {
+			return nil, err
+		}
+	}
+	plan.Changes = diffControllerManifests(current, target)
+	return plan, nil
+}
+
+// handleGetControllerVersions lists the migration controller versions that can be installed
+func handleGetControllerVersions(c *gin.Context) {
+	versions, err := controllerVersions()
+	if err != nil {
+		klog.ErrorS(err, "Failed to list migration controller versions")
+		common.Fail(c, err)
+		return
+	}
+	common.Success(c, gin.H{
+		"versions":       versions,
+		"defaultVersion": defaultControllerVersion,
+	})
+}
+
+// handleGetControllerInstallation returns the installation record of a cluster
+func handleGetControllerInstallation(c *gin.Context) {
+	clusterName := c.Param("name")
+	if isManagementCluster(clusterName) {
+		clusterName = managementClusterName
+	}
+	record, _, err := getControllerInstallation(c, clusterName)
+	if err != nil {
+		klog.ErrorS(err, "Failed to get migration controller installation", "cluster", clusterName)
+		common.Fail(c, err)
+		return
+	}
+	if record == nil {
+		common.Fail(c, fmt.Errorf("the migration controller of cluster %s was not installed by the dashboard", clusterName))
+		return
+	}
+	common.Success(c, record)
+}
+
+// handlePlanControllerUpgrade diffs the installed controller of a cluster against the version
+// given by the version query parameter, the default version if omitted
+func handlePlanControllerUpgrade(c *gin.Context) {
+	version := c.DefaultQuery("version", defaultControllerVersion)
+	plan, err := planControllerUpgrade(c, c.Param("name"), version)
+	if err != nil {
+		klog.ErrorS(err, "Failed to plan migration controller upgrade", "cluster", c.Param("name"), "version", version)
+		common.Fail(c, err)
+		return
+	}
+	common.Success(c, plan)
+}
+
+// handleUpgradeController upgrades the migration controller of a cluster
+func handleUpgradeController(c *gin.Context) {
+	var req UpgradeControllerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		klog.ErrorS(err, "Failed to bind upgrade controller request")
+		common.Fail(c, err)
+		return
+	}
+
+	record, err := installMigrationController(c, c.Param("name"), req.Version)
+	if err != nil {
+		klog.ErrorS(err, "Failed to upgrade migration controller", "cluster", c.Param("name"), "version", req.Version)
+		common.Fail(c, err)
+		return
+	}
+	common.Success(c, record)
+}
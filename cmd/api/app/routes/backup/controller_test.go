@@ -0,0 +1,304 @@
+/*
+Copyright 2024 The Karmada Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	clienttesting "k8s.io/client-go/testing"
+
+	"github.com/karmada-io/dashboard/cmd/api/app/router"
+)
+
+func TestControllerWritesRequireAuthentication(t *testing.T) {
+	cases := []string{
+		"/api/v1/backup/settings/clusters/install-controller",
+		"/api/v1/backup/settings/clusters/uninstall-controller",
+		"/api/v1/backup/settings/clusters/member1/controller-upgrade",
+	}
+
+	for _, path := range cases {
+		recorder := httptest.NewRecorder()
+		router.Router().ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, path, strings.NewReader(`{"clusterName":"member1"}`)))
+		if recorder.Code != http.StatusUnauthorized {
+			t.Errorf("POST %s without credentials: got %d %s, expected 401", path, recorder.Code, recorder.Body.String())
+		}
+	}
+}
+
+func TestRenderControllerManifests(t *testing.T) {
+	versions, err := controllerVersions()
+	if err != nil {
+		t.Fatalf("controllerVersions() failed: %v", err)
+	}
+	if !slices.Contains(versions, defaultControllerVersion) {
+		t.Fatalf("default version %s is not embedded, versions are %v", defaultControllerVersion, versions)
+	}
+
+	for _, version := range versions {
+		for _, cluster := range []string{managementClusterName, "member1"} {
+			objects, err := renderControllerManifests(version, cluster)
+			if err != nil {
+				t.Errorf("renderControllerManifests(%s, %s) failed: %v", version, cluster, err)
+				continue
+			}
+
+			names := map[string]bool{}
+			images := 0
+			for _, obj := range objects {
+				object := manifestObjectOf(obj)
+				if _, err := getGVRFromGVK(obj.GroupVersionKind()); err != nil {
+					t.Errorf("%s of version %s has no resource: %v", object, version, err)
+				}
+				if cluster != managementClusterName && object.Kind != "Namespace" && !strings.HasSuffix(object.Name, "-"+cluster) {
+					t.Errorf("%s of version %s is not specific to cluster %s", object, version, cluster)
+				}
+				names[object.Kind+"/"+object.Name] = true
+				if object.Kind == "CustomResourceDefinition" {
+					if !object.Keep {
+						t.Errorf("%s of version %s would be deleted on uninstall", object, version)
+					}
+					crdVersions, _, _ := unstructured.NestedSlice(obj.Object, "spec", "versions")
+					for _, crdVersion := range crdVersions {
+						crdVersion := crdVersion.(map[string]interface{})
+						_, hasStatus, _ := unstructured.NestedMap(crdVersion, "subresources", "status")
+						_, hasSchema, _ := unstructured.NestedMap(crdVersion, "schema", "openAPIV3Schema", "properties", "spec", "properties")
+						if !hasStatus || !hasSchema {
+							t.Errorf("%s of version %s serves %v without a status subresource or a spec schema", object, version, crdVersion["name"])
+						}
+					}
+				}
+
+				containers, _, _ := unstructured.NestedSlice(obj.Object, "spec", "template", "spec", "containers")
+				for _, container := range containers {
+					image, _, _ := unstructured.NestedString(container.(map[string]interface{}), "image")
+					if !strings.HasSuffix(image, "_"+version) {
+						t.Errorf("%s of version %s runs image %s", object, version, image)
+					}
+					images++
+				}
+			}
+			if images == 0 {
+				t.Errorf("manifests of version %s for %s have no workload", version, cluster)
+			}
+
+			// Propagation policies select the rendered objects
+			for _, obj := range objects {
+				selectors, _, _ := unstructured.NestedSlice(obj.Object, "spec", "resourceSelectors")
+				for _, selector := range selectors {
+					kind, _, _ := unstructured.NestedString(selector.(map[string]interface{}), "kind")
+					name, _, _ := unstructured.NestedString(selector.(map[string]interface{}), "name")
+					if !names[kind+"/"+name] {
+						t.Errorf("%s of version %s selects missing %s %s", manifestObjectOf(obj), version, kind, name)
+					}
+				}
+			}
+		}
+	}
+
+	if _, err := renderControllerManifests("../v2.0", "member1"); err == nil {
+		t.Errorf("renderControllerManifests succeeded for an unknown version")
+	}
+}
+
+func TestDiffControllerManifests(t *testing.T) {
+	object := func(kind, name, image string) *unstructured.Unstructured {
+		obj := &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "apps/v1",
+			"kind":       kind,
+			"metadata":   map[string]interface{}{"name": name, "namespace": controllerNamespace},
+		}}
+		if image != "" {
+			_ = unstructured.SetNestedSlice(obj.Object, []interface{}{
+				map[string]interface{}{"name": "manager", "image": image},
+			}, "spec", "template", "spec", "containers")
+		}
+		return obj
+	}
+
+	current := []*unstructured.Unstructured{
+		object("Deployment", "controller", "operator:v1"),
+		object("Deployment", "unchanged", "operator:v1"),
+		object("DaemonSet", "removed", ""),
+	}
+	target := []*unstructured.Unstructured{
+		object("ServiceAccount", "added", ""),
+		object("Deployment", "controller", "operator:v2"),
+		object("Deployment", "unchanged", "operator:v1"),
+	}
+
+	cases := []struct {
+		name   string
+		action string
+		fields []FieldChange
+	}{
+		{"added", ManifestAdded, nil},
+		{"controller", ManifestChanged, []FieldChange{
+			{Path: "spec.template.spec.containers[0].image", Current: "operator:v1", Target: "operator:v2"},
+		}},
+		{"unchanged", ManifestUnchanged, nil},
+		{"removed", ManifestRemoved, nil},
+	}
+
+	changes := diffControllerManifests(current, target)
+	if len(changes) != len(cases) {
+		t.Fatalf("diffControllerManifests() returned %d changes, expected %d", len(changes), len(cases))
+	}
+	for i, c := range cases {
+		change := changes[i]
+		if change.Object.Name != c.name || change.Action != c.action || !reflect.DeepEqual(change.Fields, c.fields) {
+			t.Errorf("change %d == %+v, expected %s %s %+v", i, change, c.action, c.name, c.fields)
+		}
+	}
+
+	// Without an installed version everything is added
+	for _, change := range diffControllerManifests(nil, target) {
+		if change.Action != ManifestAdded {
+			t.Errorf("%s is %s without an installed version", change.Object, change.Action)
+		}
+	}
+}
+
+func TestMissingObjects(t *testing.T) {
+	from := []ManifestObject{
+		{APIVersion: "v1", Kind: "ServiceAccount", Namespace: controllerNamespace, Name: "sa"},
+		{APIVersion: "apps/v1", Kind: "Deployment", Namespace: controllerNamespace, Name: "controller"},
+	}
+	to := []ManifestObject{
+		{APIVersion: "apps/v2", Kind: "Deployment", Namespace: controllerNamespace, Name: "controller"},
+	}
+
+	missing := missingObjects(from, to)
+	if len(missing) != 1 || missing[0].Name != "sa" {
+		t.Errorf("missingObjects() == %v, expected the service account only", missing)
+	}
+}
+
+func TestDeletePreexistingObjects(t *testing.T) {
+	role := &unstructured.Unstructured{}
+	role.SetAPIVersion("rbac.authorization.k8s.io/v1")
+	role.SetKind("ClusterRole")
+	role.SetName("migration-backup-controller-role")
+	dynamicClient := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), role)
+
+	previous := &ControllerInstallation{Version: "v1.0", Objects: []ManifestObject{
+		{APIVersion: "apps/v1", Kind: "Deployment", Namespace: controllerNamespace, Name: "controller", Preexisting: true},
+	}}
+	objects := []ManifestObject{
+		{APIVersion: "v1", Kind: "ServiceAccount", Namespace: controllerNamespace, Name: "migration-backup-controller"},
+		{APIVersion: "rbac.authorization.k8s.io/v1", Kind: "ClusterRole", Name: "migration-backup-controller-role"},
+		{APIVersion: "apps/v1", Kind: "Deployment", Namespace: controllerNamespace, Name: "controller"},
+	}
+	marked, err := markPreexisting(context.TODO(), dynamicClient, objects, previous)
+	if err != nil {
+		t.Fatalf("markPreexisting() failed: %v", err)
+	}
+	if marked[0].Preexisting || !marked[1].Preexisting || !marked[2].Preexisting {
+		t.Errorf("markPreexisting() == %+v, expected the cluster role and the deployment only", marked)
+	}
+
+	dynamicClient.ClearActions()
+	if err := deleteControllerObjects(context.TODO(), dynamicClient, marked); err != nil {
+		t.Fatalf("deleteControllerObjects() failed: %v", err)
+	}
+	var deleted []string
+	for _, action := range dynamicClient.Actions() {
+		if action, ok := action.(clienttesting.DeleteAction); ok {
+			deleted = append(deleted, action.GetResource().Resource+"/"+action.GetName())
+		}
+	}
+	if !reflect.DeepEqual(deleted, []string{"serviceaccounts/migration-backup-controller"}) {
+		t.Errorf("deleteControllerObjects() deleted %v, expected the service account only", deleted)
+	}
+}
+
+func TestWorkloadReady(t *testing.T) {
+	cases := []struct {
+		name   string
+		object map[string]interface{}
+		ready  bool
+	}{
+		{
+			name: "deployment available",
+			object: map[string]interface{}{
+				"kind":     "Deployment",
+				"metadata": map[string]interface{}{"generation": int64(2)},
+				"spec":     map[string]interface{}{"replicas": int64(2)},
+				"status": map[string]interface{}{
+					"observedGeneration": int64(2), "updatedReplicas": int64(2), "availableReplicas": int64(2),
+				},
+			},
+			ready: true,
+		},
+		{
+			name: "deployment rollout not observed",
+			object: map[string]interface{}{
+				"kind":     "Deployment",
+				"metadata": map[string]interface{}{"generation": int64(3)},
+				"status": map[string]interface{}{
+					"observedGeneration": int64(2), "updatedReplicas": int64(1), "availableReplicas": int64(1),
+				},
+			},
+		},
+		{
+			name: "deployment with default replicas unavailable",
+			object: map[string]interface{}{
+				"kind":   "Deployment",
+				"status": map[string]interface{}{"updatedReplicas": int64(1)},
+			},
+		},
+		{
+			name: "daemonset ready",
+			object: map[string]interface{}{
+				"kind": "DaemonSet",
+				"status": map[string]interface{}{
+					"desiredNumberScheduled": float64(3), "updatedNumberScheduled": float64(3), "numberReady": float64(3),
+				},
+			},
+			ready: true,
+		},
+		{
+			name: "daemonset updating",
+			object: map[string]interface{}{
+				"kind": "DaemonSet",
+				"status": map[string]interface{}{
+					"desiredNumberScheduled": int64(3), "updatedNumberScheduled": int64(1), "numberReady": int64(3),
+				},
+			},
+		},
+		{
+			name:   "daemonset not scheduled",
+			object: map[string]interface{}{"kind": "DaemonSet"},
+		},
+	}
+
+	for _, c := range cases {
+		ready, message := workloadReady(&unstructured.Unstructured{Object: c.object})
+		if ready != c.ready {
+			t.Errorf("%s: workloadReady() == %v (%s), expected %v", c.name, ready, message, c.ready)
+		}
+	}
+}
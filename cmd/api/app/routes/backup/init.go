@@ -21,9 +21,12 @@ package backup
 //
 // Features include:
 // - Registry management for container image storage
-// - Backup configuration and scheduling for pods and statefulsets
+// - Backup configuration and scheduling for pods, statefulsets, deployments,
+//   daemonsets and whole namespaces
+// - Verification of backup images in the registry and by sandbox restores
 // - Recovery operations for cross-cluster migration
-// - Settings for cluster management and controller deployment
+// - Notifications of failed backups, finished recoveries and missed schedules
+// - Settings for cluster management and versioned controller installs and upgrades
 //
 // The package integrates with Karmada for multi-cluster deployment
 // and uses StatefulMigration CRDs for backup/recovery operations.
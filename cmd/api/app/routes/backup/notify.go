@@ -0,0 +1,823 @@
+/*
+Copyright 2024 The Karmada Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/smtp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/klog/v2"
+
+	"github.com/karmada-io/dashboard/cmd/api/app/router"
+	"github.com/karmada-io/dashboard/cmd/api/app/types/common"
+	"github.com/karmada-io/dashboard/pkg/client"
+)
+
+// Types of notification channels
+const (
+	ChannelTypeWebhook = "webhook"
+	ChannelTypeSlack   = "slack"
+	ChannelTypeSMTP    = "smtp"
+)
+
+// Events notification rules fire on
+const (
+	EventBackupFailed      = "backupFailed"
+	EventRecoveryCompleted = "recoveryCompleted"
+	EventRecoveryFailed    = "recoveryFailed"
+	EventScheduleMissed    = "scheduleMissed"
+	// EventRegistryCredentialsExpired fires when a registry starts rejecting its credentials
+	EventRegistryCredentialsExpired = "registryCredentialsExpired"
+)
+
+const (
+	notificationChannelPrefix = "backup-notification"
+	notificationNamespace     = "karmada-system"
+
+	// notifiedAnnotation records the event a history entry or recovery was notified for, so that
+	// restarts and other replicas do not notify again
+	notifiedAnnotation = "backup.dcnlab.com/notified"
+	// missedScheduleAnnotation records the last scheduled run of a backup notified as missed
+	missedScheduleAnnotation = "backup.dcnlab.com/missed-schedule-notified"
+	// missedScheduleGrace is how late a scheduled backup may start before it counts as missed
+	missedScheduleGrace = 10 * time.Minute
+	// notificationMaxAge skips events that ended long ago, e.g. the history of backups that
+	// predates the notification channels
+	notificationMaxAge = 24 * time.Hour
+
+	// signatureHeader carries the HMAC-SHA256 of the timestamp header, a dot and the body
+	signatureHeader = "X-Dashboard-Signature"
+	timestampHeader = "X-Dashboard-Timestamp"
+	eventHeader     = "X-Dashboard-Event"
+)
+
+var secretGVR = schema.GroupVersionResource{Version: "v1", Resource: "secrets"}
+
+// notificationHTTPClient sends webhook and Slack notifications
+var notificationHTTPClient = &http.Client{Timeout: 10 * time.Second}
+
+// errAlreadyNotified is returned when another replica or an earlier run sent a notification
+var errAlreadyNotified = errors.New("already notified")
+
+// NotificationRule selects the events a channel is notified of
+type NotificationRule struct {
+	Events []string `json:"events" binding:"required,min=1,dive,oneof=backupFailed recoveryCompleted recoveryFailed scheduleMissed registryCredentialsExpired"`
+	// BackupIDs limits the rule to some backups, all backups by default
+	BackupIDs []string `json:"backupIds,omitempty"`
+	// Clusters limits the rule to backups and recoveries of some clusters, all clusters by default
+	Clusters []string `json:"clusters,omitempty"`
+}
+
+// WebhookConfig configures a generic webhook channel
+type WebhookConfig struct {
+	URL string `json:"url" binding:"required,url"`
+	// Secret signs requests with HMAC-SHA256, it is never returned by the API
+	Secret string `json:"secret,omitempty"`
+}
+
+// SlackConfig configures a Slack-compatible incoming webhook channel
+type SlackConfig struct {
+	URL string `json:"url" binding:"required,url"`
+}
+
+// SMTPConfig configures an email channel
+type SMTPConfig struct {
+	Host     string `json:"host" binding:"required"`
+	Port     int    `json:"port" binding:"required,min=1,max=65535"`
+	Username string `json:"username,omitempty"`
+	// Password is never returned by the API
+	Password string   `json:"password,omitempty"`
+	From     string   `json:"from" binding:"required,email"`
+	To       []string `json:"to" binding:"required,min=1,dive,email"`
+}
+
+// NotificationChannel is a destination of backup and recovery notifications
+type NotificationChannel struct {
+	ID          string             `json:"id"`
+	Name        string             `json:"name"`
+	Type        string             `json:"type"` // "webhook", "slack" or "smtp"
+	Description string             `json:"description"`
+	Enabled     bool               `json:"enabled"`
+	Rules       []NotificationRule `json:"rules"`
+	Webhook     *WebhookConfig     `json:"webhook,omitempty"`
+	Slack       *SlackConfig       `json:"slack,omitempty"`
+	SMTP        *SMTPConfig        `json:"smtp,omitempty"`
+	CreatedAt   string             `json:"createdAt"`
+	UpdatedAt   string             `json:"updatedAt"`
+}
+
+// NotificationChannelRequest represents the request to create or update a notification channel.
+// On update, an empty webhook secret or SMTP password keeps the stored one.
+type NotificationChannelRequest struct {
+	Name        string             `json:"name" binding:"required"`
+	Type        string             `json:"type" binding:"required,oneof=webhook slack smtp"`
+	Description string             `json:"description"`
+	Enabled     *bool              `json:"enabled,omitempty"` // Enabled by default
+	Rules       []NotificationRule `json:"rules" binding:"required,min=1,dive"`
+	Webhook     *WebhookConfig     `json:"webhook,omitempty" binding:"required_if=Type webhook"`
+	Slack       *SlackConfig       `json:"slack,omitempty" binding:"required_if=Type slack"`
+	SMTP        *SMTPConfig        `json:"smtp,omitempty" binding:"required_if=Type smtp"`
+}
+
+// Notification is sent to channels when a rule fires
+type Notification struct {
+	Event      string    `json:"event"`
+	BackupID   string    `json:"backupId,omitempty"`
+	RecoveryID string    `json:"recoveryId,omitempty"`
+	Name       string    `json:"name"`
+	Cluster    string    `json:"cluster,omitempty"`
+	Phase      string    `json:"phase,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	Message    string    `json:"message"`
+	Timestamp  time.Time `json:"timestamp"`
+}
+
+// summary returns a one line description of a notification.
+func (n Notification) summary() string {
+	switch n.Event {
+	case EventBackupFailed:
+		return fmt.Sprintf("Backup %s failed", n.Name)
+	case EventRecoveryCompleted:
+		return fmt.Sprintf("Recovery %s completed", n.Name)
+	case EventRecoveryFailed:
+		return fmt.Sprintf("Recovery %s failed", n.Name)
+	case EventScheduleMissed:
+		return fmt.Sprintf("Scheduled backup %s did not run", n.Name)
+	case EventRegistryCredentialsExpired:
+		return fmt.Sprintf("Credentials of registry %s were rejected", n.Name)
+	}
+	return fmt.Sprintf("%s: %s", n.Event, n.Name)
+}
+
+// text returns the notification as plain text for chat messages and emails.
+func (n Notification) text() string {
+	lines := []string{n.summary()}
+	if n.Cluster != "" {
+		lines = append(lines, "Cluster: "+n.Cluster)
+	}
+	if n.Message != "" {
+		lines = append(lines, n.Message)
+	}
+	if n.Error != "" {
+		lines = append(lines, "Error: "+n.Error)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// matches reports whether a rule of the channel selects a notification.
+func (ch NotificationChannel) matches(n Notification) bool {
+	if !ch.Enabled {
+		return false
+	}
+	for _, rule := range ch.Rules {
+		if !slices.Contains(rule.Events, n.Event) {
+			continue
+		}
+		if len(rule.BackupIDs) > 0 && !slices.Contains(rule.BackupIDs, n.BackupID) {
+			continue
+		}
+		if len(rule.Clusters) > 0 && !slices.Contains(rule.Clusters, n.Cluster) {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+// redacted returns the channel without its secrets, for API responses.
+func (ch NotificationChannel) redacted() NotificationChannel {
+	if ch.Webhook != nil {
+		webhook := *ch.Webhook
+		webhook.Secret = ""
+		ch.Webhook = &webhook
+	}
+	if ch.SMTP != nil {
+		smtpConfig := *ch.SMTP
+		smtpConfig.Password = ""
+		ch.SMTP = &smtpConfig
+	}
+	return ch
+}
+
+// signPayload computes the signature header of a webhook request.
+func signPayload(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// sendNotification delivers a notification to a channel.
+func sendNotification(ctx context.Context, ch NotificationChannel, n Notification) error {
+	switch {
+	case ch.Type == ChannelTypeWebhook && ch.Webhook != nil:
+		body, err := json.Marshal(n)
+		if err != nil {
+			return err
+		}
+		headers := map[string]string{eventHeader: n.Event}
+		if ch.Webhook.Secret != "" {
+			timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+			headers[timestampHeader] = timestamp
+			headers[signatureHeader] = signPayload(ch.Webhook.Secret, timestamp, body)
+		}
+		return postJSON(ctx, ch.Webhook.URL, body, headers)
+	case ch.Type == ChannelTypeSlack && ch.Slack != nil:
+		body, err := json.Marshal(map[string]string{"text": n.text()})
+		if err != nil {
+			return err
+		}
+		return postJSON(ctx, ch.Slack.URL, body, nil)
+	case ch.Type == ChannelTypeSMTP && ch.SMTP != nil:
+		return sendEmail(ch.SMTP, n)
+	}
+	return fmt.Errorf("channel %s has no %s configuration", ch.Name, ch.Type)
+}
+
+// postJSON posts a JSON body, any 2xx response counts as delivered.
+func postJSON(ctx context.Context, url string, body []byte, headers map[string]string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	resp, err := notificationHTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("endpoint returned %s", resp.Status)
+	}
+	return nil
+}
+
+// sendEmail sends a notification as plain text email. net/smtp upgrades to TLS when the server
+// offers STARTTLS, and only sends credentials over TLS or to localhost.
+func sendEmail(config *SMTPConfig, n Notification) error {
+	var auth smtp.Auth
+	if config.Username != "" {
+		auth = smtp.PlainAuth("", config.Username, config.Password, config.Host)
+	}
+	var message bytes.Buffer
+	fmt.Fprintf(&message, "From: %s\r\n", config.From)
+	fmt.Fprintf(&message, "To: %s\r\n", strings.Join(config.To, ", "))
+	fmt.Fprintf(&message, "Subject: %s\r\n", n.summary())
+	fmt.Fprintf(&message, "Date: %s\r\n", n.Timestamp.Format(time.RFC1123Z))
+	message.WriteString("MIME-Version: 1.0\r\nContent-Type: text/plain; charset=UTF-8\r\n\r\n")
+	message.WriteString(strings.ReplaceAll(n.text(), "\n", "\r\n"))
+	message.WriteString("\r\n")
+
+	addr := net.JoinHostPort(config.Host, strconv.Itoa(config.Port))
+	return smtp.SendMail(addr, auth, config.From, config.To, message.Bytes())
+}
+
+// secretToNotificationChannel reads a channel from its Secret.
+func secretToNotificationChannel(secret *corev1.Secret) (NotificationChannel, error) {
+	var channel NotificationChannel
+	if err := json.Unmarshal(secret.Data["channel"], &channel); err != nil {
+		return NotificationChannel{}, fmt.Errorf("invalid notification channel %s: %v", secret.Name, err)
+	}
+	channel.ID = secret.Labels["channel-id"]
+	channel.CreatedAt = secret.Annotations["backup.dcnlab.com/created-at"]
+	channel.UpdatedAt = secret.Annotations["backup.dcnlab.com/updated-at"]
+	if channel.CreatedAt == "" {
+		channel.CreatedAt = secret.CreationTimestamp.Format(time.RFC3339)
+	}
+	if channel.UpdatedAt == "" {
+		channel.UpdatedAt = channel.CreatedAt
+	}
+	return channel, nil
+}
+
+// listNotificationChannels lists all channels including their secrets.
+func listNotificationChannels(ctx context.Context) ([]NotificationChannel, error) {
+	dynamicClient, err := client.GetDynamicClient()
+	if err != nil {
+		return nil, err
+	}
+	list, err := dynamicClient.Resource(secretGVR).Namespace(notificationNamespace).List(ctx, metav1.ListOptions{
+		LabelSelector: "app=backup-notification-channel",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	channels := make([]NotificationChannel, 0, len(list.Items))
+	for i := range list.Items {
+		secret := &corev1.Secret{}
+		if err := convertUnstructuredToTyped(&list.Items[i], secret); err != nil {
+			klog.ErrorS(err, "Failed to convert notification channel secret", "name", list.Items[i].GetName())
+			continue
+		}
+		channel, err := secretToNotificationChannel(secret)
+		if err != nil {
+			klog.ErrorS(err, "Failed to read notification channel")
+			continue
+		}
+		channels = append(channels, channel)
+	}
+	return channels, nil
+}
+
+// getNotificationChannel gets a channel including its secrets.
+func getNotificationChannel(ctx context.Context, channelID string) (NotificationChannel, *corev1.Secret, error) {
+	dynamicClient, err := client.GetDynamicClient()
+	if err != nil {
+		return NotificationChannel{}, nil, err
+	}
+	obj, err := dynamicClient.Resource(secretGVR).Namespace(notificationNamespace).Get(ctx,
+		fmt.Sprintf("%s-%s", notificationChannelPrefix, channelID), metav1.GetOptions{})
+	if err != nil {
+		return NotificationChannel{}, nil, err
+	}
+	secret := &corev1.Secret{}
+	if err := convertUnstructuredToTyped(obj, secret); err != nil {
+		return NotificationChannel{}, nil, fmt.Errorf("failed to convert secret: %v", err)
+	}
+	channel, err := secretToNotificationChannel(secret)
+	return channel, secret, err
+}
+
+// notify sends a notification to every channel with a matching rule.
+func notify(ctx context.Context, n Notification) {
+	channels, err := listNotificationChannels(ctx)
+	if err != nil {
+		klog.ErrorS(err, "Failed to list notification channels")
+		return
+	}
+	for _, channel := range channels {
+		if !channel.matches(n) {
+			continue
+		}
+		if err := sendNotification(ctx, channel, n); err != nil {
+			klog.ErrorS(err, "Failed to send notification", "channel", channel.Name, "event", n.Event, "name", n.Name)
+			continue
+		}
+		klog.V(2).InfoS("Sent notification", "channel", channel.Name, "event", n.Event, "name", n.Name)
+	}
+}
+
+// claimNotification marks an object as notified for a value of an annotation. It fails with
+// errAlreadyNotified if the annotation has that value already, so that each event is notified
+// once even with several API server replicas.
+func claimNotification(ctx context.Context, resource schema.GroupVersionResource, namespace, name, annotation, value string) error {
+	dynamicClient, err := client.GetDynamicClient()
+	if err != nil {
+		return err
+	}
+	objects := dynamicClient.Resource(resource).Namespace(namespace)
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		obj, err := objects.Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		// Sandbox restores of backup verifications are not worth a notification
+		if obj.GetLabels()[verificationLabel] == "true" {
+			return errAlreadyNotified
+		}
+		annotations := obj.GetAnnotations()
+		if annotations[annotation] == value {
+			return errAlreadyNotified
+		}
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+		annotations[annotation] = value
+		obj.SetAnnotations(annotations)
+		_, err = objects.Update(ctx, obj, metav1.UpdateOptions{})
+		return err
+	})
+}
+
+// progressNotification turns a progress event into the notification it fires, if any.
+func progressNotification(event ProgressEvent) (Notification, bool) {
+	n := Notification{
+		BackupID:   event.BackupID,
+		RecoveryID: event.RecoveryID,
+		Name:       event.Name,
+		Cluster:    event.Cluster,
+		Phase:      event.Phase,
+		Error:      event.Error,
+		Timestamp:  time.Now(),
+	}
+	phase := strings.ToLower(event.Phase)
+	switch {
+	case event.Kind == progressKindBackupExecution && phase == "failed":
+		n.Event = EventBackupFailed
+		n.Message = fmt.Sprintf("Backup execution %s of backup %s failed.", event.Name, event.BackupID)
+	case event.Kind == progressKindRecovery && phase == "completed":
+		n.Event = EventRecoveryCompleted
+		n.Message = fmt.Sprintf("Recovery %s of backup %s completed.", event.RecoveryID, event.BackupID)
+	case event.Kind == progressKindRecovery && phase == "failed":
+		n.Event = EventRecoveryFailed
+		n.Message = fmt.Sprintf("Recovery %s of backup %s failed.", event.RecoveryID, event.BackupID)
+	default:
+		return Notification{}, false
+	}
+	// Report when the event happened rather than when it was seen
+	ended := ""
+	switch object := event.Object.(type) {
+	case RecoveryRecord:
+		n.Name = object.Name
+		ended = object.CompletedAt
+	case map[string]interface{}:
+		ended, _ = object["timestamp"].(string)
+	}
+	if timestamp, err := time.Parse(time.RFC3339, ended); err == nil {
+		n.Timestamp = timestamp
+	}
+	return n, true
+}
+
+// handleProgressEvent notifies of backup and recovery events.
+func handleProgressEvent(ctx context.Context, event ProgressEvent) {
+	if event.Action == "deleted" {
+		return
+	}
+	n, ok := progressNotification(event)
+	if !ok || time.Since(n.Timestamp) > notificationMaxAge {
+		return
+	}
+
+	resource := configMapGVR
+	if event.Kind == progressKindRecovery {
+		resource = recoveryStatefulMigrationGVR
+	}
+	err := claimNotification(ctx, resource, event.Namespace, event.Name, notifiedAnnotation, n.Event)
+	if errors.Is(err, errAlreadyNotified) || apierrors.IsNotFound(err) {
+		return
+	}
+	if err != nil {
+		klog.ErrorS(err, "Failed to record notification", "event", n.Event, "name", event.Name)
+		return
+	}
+	notify(ctx, n)
+}
+
+// watchProgressForNotifications subscribes to the progress hub until ctx is done, subscribing
+// again when the hub drops the subscription.
+func watchProgressForNotifications(ctx context.Context) {
+	for ctx.Err() == nil {
+		if err := hub.ensureStarted(); err != nil {
+			klog.ErrorS(err, "Failed to start progress watching for notifications")
+			time.Sleep(time.Minute)
+			continue
+		}
+
+		subscriber := &progressSubscriber{
+			kinds:  map[string]bool{progressKindBackupExecution: true, progressKindRecovery: true},
+			events: make(chan ProgressEvent, progressSubscriberBuffer),
+			access: map[string]bool{},
+		}
+		// The snapshot holds events that happened while no API server was running
+		for _, event := range hub.subscribe(subscriber) {
+			handleProgressEvent(ctx, event)
+		}
+	receive:
+		for {
+			select {
+			case <-ctx.Done():
+				hub.unsubscribe(subscriber)
+				return
+			case event, ok := <-subscriber.events:
+				if !ok {
+					break receive
+				}
+				handleProgressEvent(ctx, event)
+			}
+		}
+		time.Sleep(time.Second)
+	}
+}
+
+// missedRun returns the scheduled run of a backup that did not happen, or the zero time. A
+// run is missed when the next run after the last one is due for longer than the grace period.
+func missedRun(schedule *cronSchedule, lastRun, now time.Time) time.Time {
+	expected := schedule.next(lastRun)
+	if expected.IsZero() || now.Before(expected.Add(missedScheduleGrace)) {
+		return time.Time{}
+	}
+	return expected
+}
+
+// checkMissedSchedules notifies of scheduled backups whose last run is older than their schedule.
+func checkMissedSchedules(ctx context.Context) {
+	dynamicClient, err := client.GetDynamicClient()
+	if err != nil {
+		klog.ErrorS(err, "Failed to get dynamic client for schedule checks")
+		return
+	}
+	backups, err := dynamicClient.Resource(statefulMigrationGVR).List(ctx, metav1.ListOptions{
+		LabelSelector: "app=backup-migration",
+	})
+	if err != nil {
+		klog.ErrorS(err, "Failed to list backups for schedule checks")
+		return
+	}
+	history, err := dynamicClient.Resource(configMapGVR).Namespace(backupHistoryNamespace).List(ctx, metav1.ListOptions{
+		LabelSelector: "app=backup-history",
+	})
+	if err != nil {
+		klog.ErrorS(err, "Failed to list backup history for schedule checks")
+		return
+	}
+	lastRuns := map[string]time.Time{}
+	for i := range history.Items {
+		backupID := history.Items[i].GetLabels()["backup-id"]
+		if execution := configMapToBackupExecution(&history.Items[i]); execution.Timestamp.After(lastRuns[backupID]) {
+			lastRuns[backupID] = execution.Timestamp
+		}
+	}
+
+	now := time.Now()
+	for i := range backups.Items {
+		sm := &backups.Items[i]
+		expression, _, _ := unstructured.NestedString(sm.Object, "spec", "schedule")
+		schedule, err := parseCronSchedule(expression)
+		if err != nil {
+			continue
+		}
+		backupID := sm.GetLabels()["backup-id"]
+		lastRun, ran := lastRuns[backupID]
+		if !ran {
+			lastRun = sm.GetCreationTimestamp().Time
+		}
+		missed := missedRun(schedule, lastRun, now)
+		if missed.IsZero() {
+			continue
+		}
+
+		value := missed.Format(time.RFC3339)
+		err = claimNotification(ctx, statefulMigrationGVR, sm.GetNamespace(), sm.GetName(), missedScheduleAnnotation, value)
+		if errors.Is(err, errAlreadyNotified) {
+			continue
+		}
+		if err != nil {
+			klog.ErrorS(err, "Failed to record missed schedule notification", "backupID", backupID)
+			continue
+		}
+		cluster, _, _ := unstructured.NestedStringSlice(sm.Object, "spec", "sourceClusters")
+		n := Notification{
+			Event:     EventScheduleMissed,
+			BackupID:  backupID,
+			Name:      sm.GetName(),
+			Message:   fmt.Sprintf("Backup %s was scheduled to run at %s (%s) but has not run since %s.", backupID, value, expression, lastRun.Format(time.RFC3339)),
+			Timestamp: now,
+		}
+		if len(cluster) > 0 {
+			n.Cluster = cluster[0]
+		}
+		notify(ctx, n)
+	}
+}
+
+// StartNotifier sends notifications of failed backups and finished recoveries until ctx is
+// done, and checks for missed backup schedules every scheduleCheckInterval, 0 disables the
+// schedule checks.
+func StartNotifier(ctx context.Context, scheduleCheckInterval time.Duration) {
+	go watchProgressForNotifications(ctx)
+	if scheduleCheckInterval <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(scheduleCheckInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				checkMissedSchedules(ctx)
+			}
+		}
+	}()
+}
+
+// handleGetNotificationChannels lists notification channels without their secrets
+func handleGetNotificationChannels(c *gin.Context) {
+	channels, err := listNotificationChannels(c)
+	if err != nil {
+		klog.ErrorS(err, "Failed to list notification channels")
+		common.Fail(c, err)
+		return
+	}
+	for i := range channels {
+		channels[i] = channels[i].redacted()
+	}
+	common.Success(c, map[string]interface{}{
+		"channels": channels,
+		"total":    len(channels),
+	})
+}
+
+// handleCreateNotificationChannel creates a notification channel
+func handleCreateNotificationChannel(c *gin.Context) {
+	var req NotificationChannelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		klog.ErrorS(err, "Failed to bind notification channel request")
+		common.Fail(c, err)
+		return
+	}
+
+	channelID := fmt.Sprintf("%s-%d", strings.ToLower(strings.ReplaceAll(req.Name, " ", "-")), time.Now().Unix())
+	channel := channelFromRequest(NotificationChannel{}, req)
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-%s", notificationChannelPrefix, channelID),
+			Namespace: notificationNamespace,
+			Labels: map[string]string{
+				"app":        "backup-notification-channel",
+				"channel-id": channelID,
+			},
+			Annotations: map[string]string{
+				"backup.dcnlab.com/created-at": metav1.Now().Format(time.RFC3339),
+			},
+		},
+		Type: corev1.SecretTypeOpaque,
+	}
+	if err := saveNotificationChannel(c, secret, channel, true); err != nil {
+		klog.ErrorS(err, "Failed to create notification channel")
+		common.Fail(c, err)
+		return
+	}
+
+	channel, _ = secretToNotificationChannel(secret)
+	common.Success(c, channel.redacted())
+}
+
+// handleUpdateNotificationChannel replaces a notification channel
+func handleUpdateNotificationChannel(c *gin.Context) {
+	var req NotificationChannelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		klog.ErrorS(err, "Failed to bind notification channel request")
+		common.Fail(c, err)
+		return
+	}
+	existing, secret, err := getNotificationChannel(c, c.Param("id"))
+	if err != nil {
+		klog.ErrorS(err, "Failed to get notification channel", "channelID", c.Param("id"))
+		common.Fail(c, err)
+		return
+	}
+
+	channel := channelFromRequest(existing, req)
+	if secret.Annotations == nil {
+		secret.Annotations = map[string]string{}
+	}
+	secret.Annotations["backup.dcnlab.com/updated-at"] = metav1.Now().Format(time.RFC3339)
+	if err := saveNotificationChannel(c, secret, channel, false); err != nil {
+		klog.ErrorS(err, "Failed to update notification channel")
+		common.Fail(c, err)
+		return
+	}
+
+	channel, _ = secretToNotificationChannel(secret)
+	common.Success(c, channel.redacted())
+}
+
+// channelFromRequest builds a channel from a request, keeping the secrets of the existing
+// channel the request leaves empty.
+func channelFromRequest(existing NotificationChannel, req NotificationChannelRequest) NotificationChannel {
+	channel := NotificationChannel{
+		ID:          existing.ID,
+		Name:        req.Name,
+		Type:        req.Type,
+		Description: req.Description,
+		Enabled:     req.Enabled == nil || *req.Enabled,
+		Rules:       req.Rules,
+	}
+	switch req.Type {
+	case ChannelTypeWebhook:
+		channel.Webhook = req.Webhook
+		if channel.Webhook.Secret == "" && existing.Webhook != nil {
+			channel.Webhook.Secret = existing.Webhook.Secret
+		}
+	case ChannelTypeSlack:
+		channel.Slack = req.Slack
+	case ChannelTypeSMTP:
+		channel.SMTP = req.SMTP
+		if channel.SMTP.Password == "" && existing.SMTP != nil {
+			channel.SMTP.Password = existing.SMTP.Password
+		}
+	}
+	return channel
+}
+
+// saveNotificationChannel stores a channel in its Secret.
+func saveNotificationChannel(ctx context.Context, secret *corev1.Secret, channel NotificationChannel, create bool) error {
+	encoded, err := json.Marshal(channel)
+	if err != nil {
+		return err
+	}
+	secret.Data = map[string][]byte{"channel": encoded}
+
+	obj, err := convertSecretToUnstructured(secret)
+	if err != nil {
+		return err
+	}
+	obj.SetResourceVersion(secret.ResourceVersion)
+	dynamicClient, err := client.GetDynamicClient()
+	if err != nil {
+		return err
+	}
+	secrets := dynamicClient.Resource(secretGVR).Namespace(notificationNamespace)
+	if create {
+		_, err = secrets.Create(ctx, obj, metav1.CreateOptions{})
+	} else {
+		_, err = secrets.Update(ctx, obj, metav1.UpdateOptions{})
+	}
+	return err
+}
+
+// handleDeleteNotificationChannel deletes a notification channel
+func handleDeleteNotificationChannel(c *gin.Context) {
+	dynamicClient, err := client.GetDynamicClient()
+	if err != nil {
+		klog.ErrorS(err, "Failed to get dynamic client")
+		common.Fail(c, err)
+		return
+	}
+	err = dynamicClient.Resource(secretGVR).Namespace(notificationNamespace).Delete(c,
+		fmt.Sprintf("%s-%s", notificationChannelPrefix, c.Param("id")), metav1.DeleteOptions{})
+	if err != nil {
+		klog.ErrorS(err, "Failed to delete notification channel", "channelID", c.Param("id"))
+		common.Fail(c, err)
+		return
+	}
+	common.Success(c, gin.H{"message": "Notification channel deleted successfully"})
+}
+
+// handleTestNotificationChannel sends a test notification to a channel
+func handleTestNotificationChannel(c *gin.Context) {
+	channel, _, err := getNotificationChannel(c, c.Param("id"))
+	if err != nil {
+		klog.ErrorS(err, "Failed to get notification channel", "channelID", c.Param("id"))
+		common.Fail(c, err)
+		return
+	}
+	n := Notification{
+		Event:     EventBackupFailed,
+		Name:      "test",
+		Message:   fmt.Sprintf("This is a test notification of channel %s.", channel.Name),
+		Timestamp: time.Now(),
+	}
+	if err := sendNotification(c, channel, n); err != nil {
+		common.Fail(c, fmt.Errorf("failed to send test notification: %v", err))
+		return
+	}
+	common.Success(c, gin.H{"message": "Test notification sent successfully"})
+}
+
+func init() {
+	r := router.V1()
+
+	// Notification channels hold credentials of external services
+	notificationGroup := r.Group("/backup/notifications/channels")
+	notificationGroup.Use(router.EnsureDashboardAdminMiddleware())
+	{
+		notificationGroup.GET("", handleGetNotificationChannels)
+		notificationGroup.POST("", handleCreateNotificationChannel)
+		notificationGroup.PUT("/:id", handleUpdateNotificationChannel)
+		notificationGroup.DELETE("/:id", handleDeleteNotificationChannel)
+		notificationGroup.POST("/:id/test", handleTestNotificationChannel)
+	}
+}
@@ -0,0 +1,225 @@
+/*
+Copyright 2024 The Karmada Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNotificationChannelMatches(t *testing.T) {
+	channel := NotificationChannel{
+		Enabled: true,
+		Rules: []NotificationRule{
+			{Events: []string{EventBackupFailed, EventScheduleMissed}, BackupIDs: []string{"db"}},
+			{Events: []string{EventRecoveryFailed}, Clusters: []string{"member1"}},
+		},
+	}
+
+	cases := []struct {
+		notification Notification
+		matches      bool
+	}{
+		{Notification{Event: EventBackupFailed, BackupID: "db"}, true},
+		{Notification{Event: EventScheduleMissed, BackupID: "web"}, false},
+		{Notification{Event: EventRecoveryFailed, BackupID: "web", Cluster: "member1"}, true},
+		{Notification{Event: EventRecoveryFailed, Cluster: "member2"}, false},
+		{Notification{Event: EventRecoveryCompleted, BackupID: "db", Cluster: "member1"}, false},
+	}
+
+	for _, c := range cases {
+		if matches := channel.matches(c.notification); matches != c.matches {
+			t.Errorf("matches(%+v) == %v, expected %v", c.notification, matches, c.matches)
+		}
+	}
+	channel.Enabled = false
+	if channel.matches(cases[0].notification) {
+		t.Errorf("disabled channel matches")
+	}
+}
+
+func TestProgressNotification(t *testing.T) {
+	cases := []struct {
+		event ProgressEvent
+		fires string
+	}{
+		{ProgressEvent{Kind: progressKindBackupExecution, Phase: "Failed"}, EventBackupFailed},
+		{ProgressEvent{Kind: progressKindBackupExecution, Phase: "Completed"}, ""},
+		{ProgressEvent{Kind: progressKindRecovery, Phase: "completed"}, EventRecoveryCompleted},
+		{ProgressEvent{Kind: progressKindRecovery, Phase: "failed"}, EventRecoveryFailed},
+		{ProgressEvent{Kind: progressKindRecovery, Phase: "running"}, ""},
+		{ProgressEvent{Kind: progressKindCheckpointRestore, Phase: "Failed"}, ""},
+	}
+
+	for _, c := range cases {
+		n, ok := progressNotification(c.event)
+		if ok != (c.fires != "") || n.Event != c.fires {
+			t.Errorf("progressNotification(%s %s) == %q, expected %q", c.event.Kind, c.event.Phase, n.Event, c.fires)
+		}
+	}
+
+	event := ProgressEvent{Kind: progressKindRecovery, Phase: "completed",
+		Object: RecoveryRecord{Name: "drill", CompletedAt: "2024-05-15T10:00:00Z"}}
+	n, _ := progressNotification(event)
+	if n.Name != "drill" || n.Timestamp.Format(time.RFC3339) != "2024-05-15T10:00:00Z" {
+		t.Errorf("progressNotification() == %+v, expected name and completion time of the recovery", n)
+	}
+}
+
+func TestMissedRun(t *testing.T) {
+	schedule, _ := parseCronSchedule("0 * * * *")
+	lastRun := time.Date(2024, 5, 15, 10, 0, 5, 0, time.UTC)
+
+	cases := []struct {
+		now    time.Time
+		missed string
+	}{
+		{time.Date(2024, 5, 15, 10, 59, 0, 0, time.UTC), ""},
+		{time.Date(2024, 5, 15, 11, 5, 0, 0, time.UTC), ""},
+		{time.Date(2024, 5, 15, 11, 11, 0, 0, time.UTC), "2024-05-15T11:00:00Z"},
+		{time.Date(2024, 5, 15, 15, 0, 0, 0, time.UTC), "2024-05-15T11:00:00Z"},
+	}
+
+	for _, c := range cases {
+		missed := ""
+		if run := missedRun(schedule, lastRun, c.now); !run.IsZero() {
+			missed = run.Format(time.RFC3339)
+		}
+		if missed != c.missed {
+			t.Errorf("missedRun(%s) == %q, expected %q", c.now.Format(time.RFC3339), missed, c.missed)
+		}
+	}
+}
+
+func TestSendWebhookNotification(t *testing.T) {
+	notification := Notification{Event: EventBackupFailed, BackupID: "db", Name: "backup-db", Timestamp: time.Now()}
+
+	var received *http.Request
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		body, _ = io.ReadAll(r.Body)
+	}))
+	defer server.Close()
+
+	channel := NotificationChannel{Type: ChannelTypeWebhook, Webhook: &WebhookConfig{URL: server.URL, Secret: "s3cret"}}
+	if err := sendNotification(context.TODO(), channel, notification); err != nil {
+		t.Fatalf("sendNotification() failed: %v", err)
+	}
+	expected := signPayload("s3cret", received.Header.Get(timestampHeader), body)
+	if signature := received.Header.Get(signatureHeader); signature != expected {
+		t.Errorf("signature == %q, expected %q", signature, expected)
+	}
+	if event := received.Header.Get(eventHeader); event != EventBackupFailed {
+		t.Errorf("event header == %q, expected %q", event, EventBackupFailed)
+	}
+	var decoded Notification
+	if err := json.Unmarshal(body, &decoded); err != nil || decoded.BackupID != "db" {
+		t.Errorf("webhook body %s does not hold the notification", body)
+	}
+
+	channel = NotificationChannel{Type: ChannelTypeSlack, Slack: &SlackConfig{URL: server.URL}}
+	if err := sendNotification(context.TODO(), channel, notification); err != nil {
+		t.Fatalf("sendNotification() failed: %v", err)
+	}
+	var message map[string]string
+	if err := json.Unmarshal(body, &message); err != nil || !strings.HasPrefix(message["text"], "Backup backup-db failed") {
+		t.Errorf("slack body == %s", body)
+	}
+	if received.Header.Get(signatureHeader) != "" {
+		t.Errorf("slack request is signed")
+	}
+}
+
+func TestSendEmailNotification(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	// A minimal SMTP server that accepts one message
+	messages := make(chan string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		reader := bufio.NewReader(conn)
+		reply := func(line string) { io.WriteString(conn, line+"\r\n") }
+		reply("220 localhost ESMTP")
+		var data strings.Builder
+		inData := false
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			if inData {
+				if line == ".\r\n" {
+					inData = false
+					messages <- data.String()
+					reply("250 OK")
+					continue
+				}
+				data.WriteString(line)
+				continue
+			}
+			switch command := strings.ToUpper(strings.TrimSpace(line)); {
+			case strings.HasPrefix(command, "EHLO"), strings.HasPrefix(command, "HELO"):
+				reply("250 localhost")
+			case command == "DATA":
+				inData = true
+				reply("354 Go ahead")
+			case command == "QUIT":
+				reply("221 Bye")
+				return
+			default:
+				reply("250 OK")
+			}
+		}
+	}()
+
+	host, port, _ := net.SplitHostPort(listener.Addr().String())
+	portNumber, _ := strconv.Atoi(port)
+	channel := NotificationChannel{Type: ChannelTypeSMTP, SMTP: &SMTPConfig{
+		Host: host, Port: portNumber, From: "dashboard@example.com", To: []string{"ops@example.com"},
+	}}
+	notification := Notification{Event: EventScheduleMissed, Name: "backup-db", Message: "Backup db has not run.", Timestamp: time.Now()}
+	if err := sendNotification(context.TODO(), channel, notification); err != nil {
+		t.Fatalf("sendNotification() failed: %v", err)
+	}
+
+	select {
+	case message := <-messages:
+		if !strings.Contains(message, "Subject: Scheduled backup backup-db did not run") || !strings.Contains(message, "Backup db has not run.") {
+			t.Errorf("email == %q", message)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no email received")
+	}
+}
@@ -0,0 +1,437 @@
+/*
+Copyright 2024 The Karmada Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	kubeclient "k8s.io/client-go/kubernetes"
+
+	"github.com/karmada-io/dashboard/pkg/client"
+)
+
+// Preflight check states
+const (
+	PreflightPassed  = "passed"
+	PreflightWarning = "warning"
+	PreflightFailed  = "failed"
+)
+
+// defaultStorageClassAnnotation marks the storage class used for claims without one
+const defaultStorageClassAnnotation = "storageclass.kubernetes.io/is-default-class"
+
+// RecoveryRemap rewrites restored objects for their target cluster.
+type RecoveryRemap struct {
+	// StorageClasses maps storage classes of the source cluster to those of the target, the
+	// empty key stands for claims without storage class
+	StorageClasses map[string]string `json:"storageClasses,omitempty"`
+	// ImageRegistries maps registry prefixes such as docker.io/library to a mirror
+	ImageRegistries map[string]string `json:"imageRegistries,omitempty"`
+	// Labels are set on restored objects, an empty value removes the label
+	Labels map[string]string `json:"labels,omitempty"`
+	// Annotations are set on restored objects, an empty value removes the annotation
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// validate checks that the remapped names and labels are valid.
+func (r *RecoveryRemap) validate() error {
+	for source, target := range r.StorageClasses {
+		if errs := validation.IsDNS1123Subdomain(target); len(errs) > 0 {
+			return fmt.Errorf("invalid storage class %q for %q: %s", target, source, strings.Join(errs, ", "))
+		}
+	}
+	for prefix, mirror := range r.ImageRegistries {
+		if prefix == "" || mirror == "" {
+			return fmt.Errorf("image registry mappings need a prefix and a mirror")
+		}
+	}
+	for key, value := range r.Labels {
+		if errs := validation.IsQualifiedName(key); len(errs) > 0 {
+			return fmt.Errorf("invalid label key %q: %s", key, strings.Join(errs, ", "))
+		}
+		if errs := validation.IsValidLabelValue(value); len(errs) > 0 {
+			return fmt.Errorf("invalid value of label %q: %s", key, strings.Join(errs, ", "))
+		}
+	}
+	for key := range r.Annotations {
+		if errs := validation.IsQualifiedName(key); len(errs) > 0 {
+			return fmt.Errorf("invalid annotation key %q: %s", key, strings.Join(errs, ", "))
+		}
+	}
+	return nil
+}
+
+// storageClass returns the target storage class of a source storage class.
+func (r *RecoveryRemap) storageClass(source string) string {
+	if r != nil {
+		if target, ok := r.StorageClasses[source]; ok {
+			return target
+		}
+	}
+	return source
+}
+
+// toSpec converts the remapping for the StatefulMigration spec.
+func (r *RecoveryRemap) toSpec() map[string]interface{} {
+	remap := map[string]interface{}{}
+	for field, mapping := range map[string]map[string]string{
+		"storageClasses":  r.StorageClasses,
+		"imageRegistries": r.ImageRegistries,
+		"labels":          r.Labels,
+		"annotations":     r.Annotations,
+	} {
+		if len(mapping) == 0 {
+			continue
+		}
+		values := make(map[string]interface{}, len(mapping))
+		for key, value := range mapping {
+			values[key] = value
+		}
+		remap[field] = values
+	}
+	return remap
+}
+
+// mergeRemap combines the remapping of all targets with that of a single target, whose entries win.
+func mergeRemap(shared, target *RecoveryRemap) *RecoveryRemap {
+	if shared == nil && target == nil {
+		return nil
+	}
+	merged := &RecoveryRemap{}
+	for _, remap := range []*RecoveryRemap{shared, target} {
+		if remap == nil {
+			continue
+		}
+		merged.StorageClasses = mergeStringMaps(merged.StorageClasses, remap.StorageClasses)
+		merged.ImageRegistries = mergeStringMaps(merged.ImageRegistries, remap.ImageRegistries)
+		merged.Labels = mergeStringMaps(merged.Labels, remap.Labels)
+		merged.Annotations = mergeStringMaps(merged.Annotations, remap.Annotations)
+	}
+	return merged
+}
+
+func mergeStringMaps(base, override map[string]string) map[string]string {
+	if len(override) == 0 {
+		return base
+	}
+	merged := make(map[string]string, len(base)+len(override))
+	for key, value := range base {
+		merged[key] = value
+	}
+	for key, value := range override {
+		merged[key] = value
+	}
+	return merged
+}
+
+// PreflightCheck is the result of one check of a recovery target.
+type PreflightCheck struct {
+	Name    string `json:"name"`
+	Status  string `json:"status"` // "passed", "warning" or "failed"
+	Message string `json:"message"`
+}
+
+// PreflightReport is the result of all checks of a recovery target.
+type PreflightReport struct {
+	Cluster   string           `json:"cluster"`
+	Namespace string           `json:"namespace"`
+	Passed    bool             `json:"passed"`
+	Checks    []PreflightCheck `json:"checks"`
+}
+
+func (r *PreflightReport) add(name, status, format string, args ...interface{}) {
+	r.Checks = append(r.Checks, PreflightCheck{Name: name, Status: status, Message: fmt.Sprintf(format, args...)})
+	if status == PreflightFailed {
+		r.Passed = false
+	}
+}
+
+// failures summarizes the failed checks of a report.
+func (r *PreflightReport) failures() string {
+	failed := []string{}
+	for _, check := range r.Checks {
+		if check.Status == PreflightFailed {
+			failed = append(failed, fmt.Sprintf("%s: %s", check.Name, check.Message))
+		}
+	}
+	return strings.Join(failed, "; ")
+}
+
+// storageRequirements are the storage requests of the backed up resource by storage class.
+type storageRequirements map[string]resource.Quantity
+
+func (s storageRequirements) add(storageClass *string, request resource.Quantity, count int64) {
+	class := ""
+	if storageClass != nil {
+		class = *storageClass
+	}
+	total := s[class]
+	for i := int64(0); i < count; i++ {
+		total.Add(request)
+	}
+	s[class] = total
+}
+
+// classes returns the storage classes in a stable order.
+func (s storageRequirements) classes() []string {
+	classes := make([]string, 0, len(s))
+	for class := range s {
+		classes = append(classes, class)
+	}
+	sort.Strings(classes)
+	return classes
+}
+
+// getStorageRequirements collects the persistent volume claims of the backed up resource in the
+// source cluster, or the claim templates of a StatefulSet.
+func getStorageRequirements(ctx context.Context, kube kubeclient.Interface, backup BackupConfiguration) (storageRequirements, error) {
+	requirements := storageRequirements{}
+	addClaims := func(namespace string, volumes []corev1.Volume) error {
+		for _, volume := range volumes {
+			if volume.PersistentVolumeClaim == nil {
+				continue
+			}
+			pvc, err := kube.CoreV1().PersistentVolumeClaims(namespace).Get(ctx, volume.PersistentVolumeClaim.ClaimName, metav1.GetOptions{})
+			if err != nil {
+				return fmt.Errorf("failed to get persistent volume claim %s: %v", volume.PersistentVolumeClaim.ClaimName, err)
+			}
+			requirements.add(pvc.Spec.StorageClassName, pvc.Spec.Resources.Requests[corev1.ResourceStorage], 1)
+		}
+		return nil
+	}
+
+	namespace, name := backup.Namespace, backup.ResourceName
+	switch strings.ToLower(backup.ResourceType) {
+	case "pod":
+		pod, err := kube.CoreV1().Pods(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return requirements, addClaims(namespace, pod.Spec.Volumes)
+	case "deployment":
+		deployment, err := kube.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return requirements, addClaims(namespace, deployment.Spec.Template.Spec.Volumes)
+	case "daemonset":
+		daemonSet, err := kube.AppsV1().DaemonSets(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return requirements, addClaims(namespace, daemonSet.Spec.Template.Spec.Volumes)
+	case "statefulset":
+		statefulSet, err := kube.AppsV1().StatefulSets(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		replicas := int64(1)
+		if statefulSet.Spec.Replicas != nil {
+			replicas = int64(*statefulSet.Spec.Replicas)
+		}
+		for _, template := range statefulSet.Spec.VolumeClaimTemplates {
+			requirements.add(template.Spec.StorageClassName, template.Spec.Resources.Requests[corev1.ResourceStorage], replicas)
+		}
+		return requirements, addClaims(namespace, statefulSet.Spec.Template.Spec.Volumes)
+	case resourceTypeNamespace:
+		scope := BackupScope{}
+		if backup.Scope != nil {
+			scope = *backup.Scope
+		}
+		included := false
+		for _, resourceType := range scope.resources() {
+			included = included || resourceType == "persistentvolumeclaim"
+		}
+		if !included {
+			return requirements, nil
+		}
+		pvcs, err := kube.CoreV1().PersistentVolumeClaims(namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		for _, pvc := range pvcs.Items {
+			if scope.matches(pvc.Labels) {
+				requirements.add(pvc.Spec.StorageClassName, pvc.Spec.Resources.Requests[corev1.ResourceStorage], 1)
+			}
+		}
+		return requirements, nil
+	}
+	return nil, fmt.Errorf("unsupported resource type: %s", backup.ResourceType)
+}
+
+// runPreflight checks that a recovery of backup can run on a target: the migration controller is
+// installed, the namespace exists or can be created, and the storage classes the restored claims
+// need exist with enough capacity. Storage requirements come from the source cluster and are
+// passed in, nil when they could not be determined.
+func runPreflight(c *gin.Context, target RecoveryTarget, backup BackupConfiguration, requirements storageRequirements, remap *RecoveryRemap) PreflightReport {
+	namespace := target.TargetNamespace
+	if namespace == "" {
+		namespace = backup.Namespace
+	}
+	report := PreflightReport{Cluster: target.Cluster, Namespace: namespace, Passed: true, Checks: []PreflightCheck{}}
+
+	status, version, err := checkMemberMigrationController(c, target.Cluster)
+	switch {
+	case status == "installed":
+		report.add("migration-controller", PreflightPassed, "migration controller %s is installed", version)
+	case err != nil:
+		report.add("migration-controller", PreflightFailed, "migration controller is %s: %v", status, err)
+	default:
+		report.add("migration-controller", PreflightFailed, "migration controller is %s", status)
+	}
+
+	kube := client.InClusterClientForMemberCluster(c, target.Cluster)
+	if kube == nil {
+		report.add("cluster-access", PreflightFailed, "cluster %s is not accessible", target.Cluster)
+		return report
+	}
+	checkNamespace(c, kube, &report)
+
+	if requirements == nil {
+		report.add("storage-class", PreflightWarning, "storage requirements of the backup could not be determined")
+		return report
+	}
+	if len(requirements) == 0 {
+		report.add("storage-class", PreflightPassed, "the backup has no persistent volumes")
+		return report
+	}
+	for _, sourceClass := range requirements.classes() {
+		checkStorageClass(c, kube, &report, remap.storageClass(sourceClass), sourceClass, requirements[sourceClass])
+	}
+	return report
+}
+
+// checkNamespace checks that the target namespace exists, or that the caller can create it.
+func checkNamespace(ctx context.Context, kube kubeclient.Interface, report *PreflightReport) {
+	_, err := kube.CoreV1().Namespaces().Get(ctx, report.Namespace, metav1.GetOptions{})
+	if err == nil {
+		report.add("namespace", PreflightPassed, "namespace %s exists", report.Namespace)
+		return
+	}
+	if !apierrors.IsNotFound(err) {
+		report.add("namespace", PreflightFailed, "failed to get namespace %s: %v", report.Namespace, err)
+		return
+	}
+
+	namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: report.Namespace}}
+	if _, err := kube.CoreV1().Namespaces().Create(ctx, namespace, metav1.CreateOptions{DryRun: []string{metav1.DryRunAll}}); err != nil {
+		report.add("namespace", PreflightFailed, "namespace %s cannot be created: %v", report.Namespace, err)
+		return
+	}
+	report.add("namespace", PreflightPassed, "namespace %s will be created", report.Namespace)
+}
+
+// checkStorageClass checks that a storage class exists in the target cluster and that the
+// namespace quota and the capacity reported by its driver fit the requested storage.
+func checkStorageClass(ctx context.Context, kube kubeclient.Interface, report *PreflightReport, class, sourceClass string, request resource.Quantity) {
+	if class == "" {
+		classes, err := kube.StorageV1().StorageClasses().List(ctx, metav1.ListOptions{})
+		if err != nil {
+			report.add("storage-class", PreflightFailed, "failed to list storage classes: %v", err)
+			return
+		}
+		for i := range classes.Items {
+			if classes.Items[i].Annotations[defaultStorageClassAnnotation] == "true" {
+				class = classes.Items[i].Name
+			}
+		}
+		if class == "" {
+			report.add("storage-class", PreflightFailed, "claims without storage class need a default storage class, map them to an existing class")
+			return
+		}
+	} else if _, err := kube.StorageV1().StorageClasses().Get(ctx, class, metav1.GetOptions{}); err != nil {
+		if apierrors.IsNotFound(err) {
+			report.add("storage-class", PreflightFailed, "storage class %s does not exist, map %q to an existing class", class, sourceClass)
+		} else {
+			report.add("storage-class", PreflightFailed, "failed to get storage class %s: %v", class, err)
+		}
+		return
+	}
+	report.add("storage-class", PreflightPassed, "storage class %s exists", class)
+
+	checkStorageQuota(ctx, kube, report, class, request)
+	checkStorageCapacity(ctx, kube, report, class, request)
+}
+
+// checkStorageQuota checks the storage request against the resource quotas of the target namespace.
+func checkStorageQuota(ctx context.Context, kube kubeclient.Interface, report *PreflightReport, class string, request resource.Quantity) {
+	quotas, err := kube.CoreV1().ResourceQuotas(report.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		report.add("storage-quota", PreflightWarning, "failed to list resource quotas: %v", err)
+		return
+	}
+	classResource := corev1.ResourceName(class + ".storageclass.storage.k8s.io/requests.storage")
+	for _, quota := range quotas.Items {
+		for _, name := range []corev1.ResourceName{corev1.ResourceRequestsStorage, classResource} {
+			hard, limited := quota.Status.Hard[name]
+			if !limited {
+				continue
+			}
+			available := hard.DeepCopy()
+			available.Sub(quota.Status.Used[name])
+			if available.Cmp(request) < 0 {
+				report.add("storage-quota", PreflightFailed, "quota %s allows %s more %s, %s are needed", quota.Name, available.String(), name, request.String())
+				return
+			}
+		}
+	}
+	report.add("storage-quota", PreflightPassed, "%s of %s fit the quotas of namespace %s", request.String(), class, report.Namespace)
+}
+
+// checkStorageCapacity checks the storage request against the capacity CSI drivers report for
+// the storage class. Most drivers do not report capacity, which is only a warning.
+func checkStorageCapacity(ctx context.Context, kube kubeclient.Interface, report *PreflightReport, class string, request resource.Quantity) {
+	capacities, err := kube.StorageV1().CSIStorageCapacities(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		report.add("storage-capacity", PreflightWarning, "failed to list storage capacities: %v", err)
+		return
+	}
+	total, reported := totalStorageCapacity(capacities.Items, class)
+	if !reported {
+		report.add("storage-capacity", PreflightWarning, "the driver of storage class %s does not report capacity, %s are needed", class, request.String())
+		return
+	}
+	if total.Cmp(request) < 0 {
+		report.add("storage-capacity", PreflightFailed, "storage class %s has %s available, %s are needed", class, total.String(), request.String())
+		return
+	}
+	report.add("storage-capacity", PreflightPassed, "storage class %s has %s available, %s are needed", class, total.String(), request.String())
+}
+
+// totalStorageCapacity sums the capacity reported for a storage class across topology segments.
+func totalStorageCapacity(capacities []storagev1.CSIStorageCapacity, class string) (resource.Quantity, bool) {
+	total := resource.Quantity{}
+	reported := false
+	for _, capacity := range capacities {
+		if capacity.StorageClassName != class || capacity.Capacity == nil {
+			continue
+		}
+		total.Add(*capacity.Capacity)
+		reported = true
+	}
+	return total, reported
+}
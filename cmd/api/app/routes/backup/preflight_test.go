@@ -0,0 +1,137 @@
+/*
+Copyright 2024 The Karmada Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestMergeRemap(t *testing.T) {
+	shared := &RecoveryRemap{
+		StorageClasses: map[string]string{"standard": "gp3", "fast": "io2"},
+		Labels:         map[string]string{"drill": "true"},
+	}
+	target := &RecoveryRemap{
+		StorageClasses:  map[string]string{"fast": "premium"},
+		ImageRegistries: map[string]string{"docker.io": "mirror.example.com"},
+	}
+
+	merged := mergeRemap(shared, target)
+	expected := &RecoveryRemap{
+		StorageClasses:  map[string]string{"standard": "gp3", "fast": "premium"},
+		ImageRegistries: map[string]string{"docker.io": "mirror.example.com"},
+		Labels:          map[string]string{"drill": "true"},
+	}
+	if !reflect.DeepEqual(merged, expected) {
+		t.Errorf("mergeRemap() == %+v, expected %+v", merged, expected)
+	}
+	if shared.StorageClasses["fast"] != "io2" {
+		t.Errorf("mergeRemap() modified the shared remapping")
+	}
+	if mergeRemap(nil, nil) != nil {
+		t.Errorf("mergeRemap(nil, nil) != nil")
+	}
+	if class := merged.storageClass("standard"); class != "gp3" {
+		t.Errorf("storageClass(standard) == %s, expected gp3", class)
+	}
+	if class := merged.storageClass("local"); class != "local" {
+		t.Errorf("storageClass(local) == %s, expected local", class)
+	}
+}
+
+func TestRecoveryRemapValidate(t *testing.T) {
+	cases := []struct {
+		remap RecoveryRemap
+		valid bool
+	}{
+		{RecoveryRemap{StorageClasses: map[string]string{"": "gp3"}, Labels: map[string]string{"app.kubernetes.io/part-of": "drill"}}, true},
+		{RecoveryRemap{StorageClasses: map[string]string{"standard": "GP3"}}, false},
+		{RecoveryRemap{ImageRegistries: map[string]string{"docker.io": ""}}, false},
+		{RecoveryRemap{Labels: map[string]string{"drill": "not valid"}}, false},
+		{RecoveryRemap{Annotations: map[string]string{"bad key!": "x"}}, false},
+	}
+
+	for _, c := range cases {
+		if err := c.remap.validate(); (err == nil) != c.valid {
+			t.Errorf("validate(%+v) == %v, expected valid %v", c.remap, err, c.valid)
+		}
+	}
+}
+
+func TestCheckStorageClass(t *testing.T) {
+	capacity := resource.MustParse("50Gi")
+	objects := []runtime.Object{
+		&storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{
+			Name:        "standard",
+			Annotations: map[string]string{defaultStorageClassAnnotation: "true"},
+		}},
+		&storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: "fast"}},
+		&storagev1.CSIStorageCapacity{
+			ObjectMeta:       metav1.ObjectMeta{Name: "fast-zone-a", Namespace: "kube-system"},
+			StorageClassName: "fast",
+			Capacity:         &capacity,
+		},
+		&corev1.ResourceQuota{
+			ObjectMeta: metav1.ObjectMeta{Name: "storage", Namespace: "shop"},
+			Status: corev1.ResourceQuotaStatus{
+				Hard: corev1.ResourceList{corev1.ResourceRequestsStorage: resource.MustParse("100Gi")},
+				Used: corev1.ResourceList{corev1.ResourceRequestsStorage: resource.MustParse("80Gi")},
+			},
+		},
+	}
+
+	cases := []struct {
+		class   string
+		request string
+		checks  map[string]string
+	}{
+		{"", "10Gi", map[string]string{"storage-class": PreflightPassed, "storage-quota": PreflightPassed, "storage-capacity": PreflightWarning}},
+		{"fast", "10Gi", map[string]string{"storage-class": PreflightPassed, "storage-quota": PreflightPassed, "storage-capacity": PreflightPassed}},
+		{"fast", "30Gi", map[string]string{"storage-class": PreflightPassed, "storage-quota": PreflightFailed, "storage-capacity": PreflightPassed}},
+		{"missing", "1Gi", map[string]string{"storage-class": PreflightFailed}},
+	}
+
+	for _, c := range cases {
+		kube := fake.NewSimpleClientset(objects...)
+		report := PreflightReport{Cluster: "member1", Namespace: "shop", Passed: true}
+		checkStorageClass(context.TODO(), kube, &report, c.class, c.class, resource.MustParse(c.request))
+
+		checks := map[string]string{}
+		for _, check := range report.Checks {
+			checks[check.Name] = check.Status
+		}
+		if !reflect.DeepEqual(checks, c.checks) {
+			t.Errorf("checkStorageClass(%q, %s) == %v, expected %v", c.class, c.request, checks, c.checks)
+		}
+		failed := false
+		for _, status := range c.checks {
+			failed = failed || status == PreflightFailed
+		}
+		if report.Passed == failed {
+			t.Errorf("checkStorageClass(%q, %s) passed == %v", c.class, c.request, report.Passed)
+		}
+	}
+}
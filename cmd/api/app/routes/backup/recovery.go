@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -28,6 +29,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/klog/v2"
 
 	"github.com/karmada-io/dashboard/cmd/api/app/router"
@@ -90,6 +92,42 @@ type CreateRecoveryRequest struct {
 	RecoveryType    string `json:"recoveryType" binding:"required,oneof=restore migrate"`
 	TargetName      string `json:"targetName,omitempty"`      // Optional: different name for recovered resource
 	TargetNamespace string `json:"targetNamespace,omitempty"` // Optional: different namespace
+	// Remap rewrites storage classes, images, labels and annotations for the target cluster
+	Remap *RecoveryRemap `json:"remap,omitempty"`
+	// SkipPreflight creates the recovery even if the target fails the preflight checks
+	SkipPreflight bool `json:"skipPreflight,omitempty"`
+	// CheckpointImage restores a specific backup execution instead of the latest one
+	CheckpointImage string `json:"checkpointImage,omitempty"`
+}
+
+// RecoveryTarget is a cluster a backup is restored to
+type RecoveryTarget struct {
+	Cluster         string         `json:"cluster" binding:"required"`
+	TargetName      string         `json:"targetName,omitempty"`
+	TargetNamespace string         `json:"targetNamespace,omitempty"`
+	Remap           *RecoveryRemap `json:"remap,omitempty"` // Entries override the remapping shared by all targets
+}
+
+// FanOutRecoveryRequest represents the request to restore one backup to several clusters
+type FanOutRecoveryRequest struct {
+	Name         string           `json:"name" binding:"required"`
+	BackupID     string           `json:"backupId" binding:"required"`
+	RecoveryType string           `json:"recoveryType" binding:"required,oneof=restore migrate"`
+	Targets      []RecoveryTarget `json:"targets" binding:"required,min=1,dive"`
+	Remap        *RecoveryRemap   `json:"remap,omitempty"`
+	// AllOrNothing creates no recovery unless every target passes the preflight checks, otherwise
+	// only targets that fail are skipped
+	AllOrNothing bool `json:"allOrNothing,omitempty"`
+	// PreflightOnly runs the preflight checks without creating recoveries
+	PreflightOnly bool `json:"preflightOnly,omitempty"`
+}
+
+// RecoveryTargetReport is the outcome of a fan-out recovery for one target
+type RecoveryTargetReport struct {
+	Cluster   string          `json:"cluster"`
+	Preflight PreflightReport `json:"preflight"`
+	Recovery  *RecoveryRecord `json:"recovery,omitempty"`
+	Error     string          `json:"error,omitempty"`
 }
 
 // RecoveryExecutionRequest represents a request to start recovery execution
@@ -580,6 +618,11 @@ func handleCreateRecovery(c *gin.Context) {
 		common.Fail(c, err)
 		return
 	}
+	// The recovery restores into the target cluster as the dashboard, preflight or not
+	if status, err := authorizeClusterWrite(c, req.TargetCluster); err != nil {
+		common.FailWithStatus(c, err, status)
+		return
+	}
 
 	// Get backup configuration to extract source information
 	backup, err := getBackupByID(req.BackupID)
@@ -589,55 +632,219 @@ func handleCreateRecovery(c *gin.Context) {
 		return
 	}
 
+	if req.Remap != nil {
+		if err := req.Remap.validate(); err != nil {
+			common.Fail(c, err)
+			return
+		}
+	}
+	if !req.SkipPreflight {
+		target := RecoveryTarget{Cluster: req.TargetCluster, TargetName: req.TargetName, TargetNamespace: req.TargetNamespace}
+		report := runPreflight(c, target, backup, sourceStorageRequirements(c, backup), req.Remap)
+		if !report.Passed {
+			common.Fail(c, fmt.Errorf("preflight checks failed for cluster %s: %s", req.TargetCluster, report.failures()))
+			return
+		}
+	}
+
 	// Generate unique ID for the recovery
 	recoveryID := generateRecoveryID(req.Name)
 
-	// Create StatefulMigration CR for recovery
-	statefulMigration := createRecoveryStatefulMigrationCR(recoveryID, req, backup)
-
-	dynamicClient, err := client.GetDynamicClient()
+	recovery, err := createRecovery(recoveryID, req, backup, nil)
 	if err != nil {
-		klog.ErrorS(err, "Failed to get dynamic client")
+		klog.ErrorS(err, "Failed to create recovery StatefulMigration CR")
 		common.Fail(c, err)
 		return
 	}
-	_, err = dynamicClient.Resource(recoveryStatefulMigrationGVR).Namespace("karmada-system").Create(context.TODO(),
-		statefulMigration, metav1.CreateOptions{})
+	common.Success(c, recovery)
+}
+
+// handleCreateFanOutRecovery restores one backup to several clusters. Every target is checked
+// first, and the response has one report per target.
+func handleCreateFanOutRecovery(c *gin.Context) {
+	var req FanOutRecoveryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		klog.ErrorS(err, "Failed to bind fan-out recovery request")
+		common.Fail(c, err)
+		return
+	}
+	seen := map[string]bool{}
+	for _, target := range req.Targets {
+		if seen[target.Cluster] {
+			common.Fail(c, fmt.Errorf("cluster %s is targeted more than once", target.Cluster))
+			return
+		}
+		seen[target.Cluster] = true
+		if !req.PreflightOnly {
+			if status, err := authorizeClusterWrite(c, target.Cluster); err != nil {
+				common.FailWithStatus(c, err, status)
+				return
+			}
+		}
+		if remap := mergeRemap(req.Remap, target.Remap); remap != nil {
+			if err := remap.validate(); err != nil {
+				common.Fail(c, fmt.Errorf("invalid remapping for cluster %s: %v", target.Cluster, err))
+				return
+			}
+		}
+	}
+
+	backup, err := getBackupByID(req.BackupID)
 	if err != nil {
-		klog.ErrorS(err, "Failed to create recovery StatefulMigration CR")
+		klog.ErrorS(err, "Failed to get backup configuration", "backupID", req.BackupID)
 		common.Fail(c, err)
 		return
 	}
 
-	recovery := statefulMigrationToRecovery(statefulMigration)
-	common.Success(c, recovery)
+	// The checks of the targets are independent, so they run in parallel
+	requirements := sourceStorageRequirements(c, backup)
+	reports := make([]RecoveryTargetReport, len(req.Targets))
+	var wg sync.WaitGroup
+	for i, target := range req.Targets {
+		wg.Add(1)
+		go func(i int, target RecoveryTarget) {
+			defer wg.Done()
+			reports[i] = RecoveryTargetReport{
+				Cluster:   target.Cluster,
+				Preflight: runPreflight(c, target, backup, requirements, mergeRemap(req.Remap, target.Remap)),
+			}
+		}(i, target)
+	}
+	wg.Wait()
+
+	allPassed := true
+	for _, report := range reports {
+		allPassed = allPassed && report.Preflight.Passed
+	}
+	groupID := generateRecoveryID(req.Name)
+	for i, target := range req.Targets {
+		report := &reports[i]
+		switch {
+		case req.PreflightOnly:
+			continue
+		case !report.Preflight.Passed:
+			report.Error = "preflight checks failed: " + report.Preflight.failures()
+			continue
+		case req.AllOrNothing && !allPassed:
+			report.Error = "skipped because other targets failed the preflight checks"
+			continue
+		}
+
+		targetRequest := CreateRecoveryRequest{
+			Name:            req.Name,
+			BackupID:        req.BackupID,
+			TargetCluster:   target.Cluster,
+			RecoveryType:    req.RecoveryType,
+			TargetName:      target.TargetName,
+			TargetNamespace: target.TargetNamespace,
+			Remap:           mergeRemap(req.Remap, target.Remap),
+		}
+		recovery, err := createRecovery(fmt.Sprintf("%s-%s", groupID, target.Cluster), targetRequest, backup,
+			map[string]string{"recovery-group": groupID})
+		if err != nil {
+			klog.ErrorS(err, "Failed to create recovery StatefulMigration CR", "cluster", target.Cluster)
+			report.Error = err.Error()
+			continue
+		}
+		report.Recovery = &recovery
+	}
+
+	common.Success(c, map[string]interface{}{
+		"groupId": groupID,
+		"targets": reports,
+		"total":   len(reports),
+	})
+}
+
+// createRecovery creates the StatefulMigration CR of a recovery with extra labels.
+func createRecovery(recoveryID string, req CreateRecoveryRequest, backup BackupConfiguration, labels map[string]string) (RecoveryRecord, error) {
+	statefulMigration := createRecoveryStatefulMigrationCR(recoveryID, req, backup)
+	if len(labels) > 0 {
+		merged := statefulMigration.GetLabels()
+		for key, value := range labels {
+			merged[key] = value
+		}
+		statefulMigration.SetLabels(merged)
+	}
+
+	dynamicClient, err := client.GetDynamicClient()
+	if err != nil {
+		return RecoveryRecord{}, err
+	}
+	created, err := dynamicClient.Resource(recoveryStatefulMigrationGVR).Namespace("karmada-system").Create(context.TODO(),
+		statefulMigration, metav1.CreateOptions{})
+	if err != nil {
+		return RecoveryRecord{}, err
+	}
+	// The phase is in the spec as well, the recovery is usable without its initial status
+	status, _, _ := unstructured.NestedMap(statefulMigration.Object, "status")
+	if err := updateRecoveryStatus(context.TODO(), dynamicClient, created, status); err != nil {
+		klog.ErrorS(err, "Failed to set the initial status of recovery", "recoveryID", recoveryID)
+	}
+	return statefulMigrationToRecovery(statefulMigration), nil
+}
+
+// updateRecoveryStatus writes the status of a recovery StatefulMigration CR. The CRD has a status
+// subresource, so creates and updates of the CR leave its status alone.
+func updateRecoveryStatus(ctx context.Context, dynamicClient dynamic.Interface, sm *unstructured.Unstructured, status map[string]interface{}) error {
+	if err := unstructured.SetNestedMap(sm.Object, status, "status"); err != nil {
+		return err
+	}
+	_, err := dynamicClient.Resource(recoveryStatefulMigrationGVR).Namespace("karmada-system").UpdateStatus(ctx,
+		sm, metav1.UpdateOptions{})
+	return err
+}
+
+// sourceStorageRequirements reads the storage requirements of a backup from its source cluster,
+// nil when they cannot be determined.
+func sourceStorageRequirements(c *gin.Context, backup BackupConfiguration) storageRequirements {
+	kube := client.InClusterClientForMemberCluster(c, backup.Cluster)
+	if kube == nil {
+		klog.InfoS("Source cluster of backup is not accessible for preflight checks", "cluster", backup.Cluster)
+		return nil
+	}
+	requirements, err := getStorageRequirements(c, kube, backup)
+	if err != nil {
+		klog.InfoS("Failed to get storage requirements of backup", "backupID", backup.ID, "error", err)
+		return nil
+	}
+	return requirements
 }
 
 // handleExecuteRecovery starts the execution of a recovery operation
 func handleExecuteRecovery(c *gin.Context) {
 	recoveryID := c.Param("id")
-	dynamicClient, err := client.GetDynamicClient()
-	if err != nil {
-		klog.ErrorS(err, "Failed to get dynamic client")
+	if err := executeRecovery(c, recoveryID); err != nil {
+		klog.ErrorS(err, "Failed to trigger recovery execution", "recoveryID", recoveryID)
 		common.Fail(c, err)
 		return
 	}
 
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Recovery execution started successfully",
+	})
+}
+
+// executeRecovery marks the StatefulMigration CR of a recovery for execution.
+func executeRecovery(ctx context.Context, recoveryID string) error {
+	dynamicClient, err := client.GetDynamicClient()
+	if err != nil {
+		return fmt.Errorf("failed to get dynamic client: %v", err)
+	}
+
 	// Get the StatefulMigration CR
 	smName := fmt.Sprintf("recovery-%s", recoveryID)
-	unstructuredObj, err := dynamicClient.Resource(recoveryStatefulMigrationGVR).Namespace("karmada-system").Get(context.TODO(),
+	unstructuredObj, err := dynamicClient.Resource(recoveryStatefulMigrationGVR).Namespace("karmada-system").Get(ctx,
 		smName, metav1.GetOptions{})
 	if err != nil {
-		klog.ErrorS(err, "Failed to get recovery StatefulMigration CR", "recoveryID", recoveryID)
-		common.Fail(c, err)
-		return
+		return err
 	}
 
 	// Update the CR to trigger recovery execution
 	spec, found, err := unstructured.NestedMap(unstructuredObj.Object, "spec")
 	if err != nil || !found {
-		common.Fail(c, fmt.Errorf("failed to get spec from recovery StatefulMigration CR"))
-		return
+		return fmt.Errorf("failed to get spec from recovery StatefulMigration CR")
 	}
 
 	// Add execution trigger
@@ -645,25 +852,17 @@ func handleExecuteRecovery(c *gin.Context) {
 	spec["phase"] = "running"
 	unstructured.SetNestedMap(unstructuredObj.Object, spec, "spec")
 
-	// Update status
-	status := map[string]interface{}{
-		"phase":     "running",
-		"startedAt": time.Now().Format(time.RFC3339),
-		"progress":  0,
-	}
-	unstructured.SetNestedMap(unstructuredObj.Object, status, "status")
-
-	_, err = dynamicClient.Resource(recoveryStatefulMigrationGVR).Namespace("karmada-system").Update(context.TODO(),
+	updated, err := dynamicClient.Resource(recoveryStatefulMigrationGVR).Namespace("karmada-system").Update(ctx,
 		unstructuredObj, metav1.UpdateOptions{})
 	if err != nil {
-		klog.ErrorS(err, "Failed to trigger recovery execution")
-		common.Fail(c, err)
-		return
+		return err
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"success": true,
-		"message": "Recovery execution started successfully",
+	// Update status
+	return updateRecoveryStatus(ctx, dynamicClient, updated, map[string]interface{}{
+		"phase":     "running",
+		"startedAt": time.Now().Format(time.RFC3339),
+		"progress":  int64(0),
 	})
 }
 
@@ -722,15 +921,15 @@ func handleCancelRecovery(c *gin.Context) {
 	spec["phase"] = "cancelled"
 	unstructured.SetNestedMap(unstructuredObj.Object, spec, "spec")
 
-	// Update status
-	status := map[string]interface{}{
-		"phase":       "cancelled",
-		"completedAt": time.Now().Format(time.RFC3339),
-	}
-	unstructured.SetNestedMap(unstructuredObj.Object, status, "status")
-
-	_, err = dynamicClient.Resource(recoveryStatefulMigrationGVR).Namespace("karmada-system").Update(context.TODO(),
+	updated, err := dynamicClient.Resource(recoveryStatefulMigrationGVR).Namespace("karmada-system").Update(context.TODO(),
 		unstructuredObj, metav1.UpdateOptions{})
+	if err == nil {
+		// Update status
+		err = updateRecoveryStatus(context.TODO(), dynamicClient, updated, map[string]interface{}{
+			"phase":       "cancelled",
+			"completedAt": time.Now().Format(time.RFC3339),
+		})
+	}
 	if err != nil {
 		klog.ErrorS(err, "Failed to cancel recovery")
 		common.Fail(c, err)
@@ -875,6 +1074,10 @@ func createRecoveryStatefulMigrationCR(recoveryID string, req CreateRecoveryRequ
 	if req.TargetNamespace != "" {
 		targetNamespace = req.TargetNamespace
 	}
+	// A namespace backup is named after its namespace
+	if strings.EqualFold(backup.ResourceType, resourceTypeNamespace) && req.TargetName == "" {
+		targetName = targetNamespace
+	}
 
 	// Create spec
 	spec := map[string]interface{}{
@@ -892,11 +1095,25 @@ func createRecoveryStatefulMigrationCR(recoveryID string, req CreateRecoveryRequ
 		"registryID":      backup.Registry.ID,
 		"phase":           "pending",
 	}
+	if req.CheckpointImage != "" {
+		spec["checkpointImage"] = req.CheckpointImage
+	}
+	if req.Remap != nil {
+		if remap := req.Remap.toSpec(); len(remap) > 0 {
+			spec["remap"] = remap
+		}
+	}
+	// Namespace backups restore the objects their scope selected
+	if backup.Scope != nil {
+		if err := backup.Scope.toSpec(spec); err != nil {
+			klog.ErrorS(err, "Failed to set backup scope on recovery", "backupID", req.BackupID)
+		}
+	}
 
 	// Create initial status
 	status := map[string]interface{}{
 		"phase":    "pending",
-		"progress": 0,
+		"progress": int64(0),
 	}
 
 	sm.Object = map[string]interface{}{
@@ -921,7 +1138,7 @@ func getBackupByID(backupID string) (BackupConfiguration, error) {
 	}
 	smName := fmt.Sprintf("backup-%s", backupID)
 
-	unstructuredObj, err := dynamicClient.Resource(statefulMigrationGVR).Namespace("karmada-system").Get(context.TODO(),
+	unstructuredObj, err := dynamicClient.Resource(statefulMigrationGVR).Namespace(defaultNamespace).Get(context.TODO(),
 		smName, metav1.GetOptions{})
 	if err != nil {
 		return BackupConfiguration{}, err
@@ -941,6 +1158,7 @@ func configMapToBackupHistory(cm *unstructured.Unstructured) map[string]interfac
 		"size":           data["size"],
 		"error":          data["error"],
 		"checkpointPath": data["checkpointPath"],
+		"verification":   verificationFromHistory(data),
 	}
 }
 
@@ -953,6 +1171,7 @@ func init() {
 	{
 		recoveryGroup.GET("", handleGetRecoveryHistory)
 		recoveryGroup.POST("", handleCreateRecovery)
+		recoveryGroup.POST("/fan-out", handleCreateFanOutRecovery)
 		recoveryGroup.GET("/:id", handleGetRecoveryRecord)
 		recoveryGroup.POST("/:id/execute", handleExecuteRecovery)
 		recoveryGroup.POST("/:id/cancel", handleCancelRecovery)
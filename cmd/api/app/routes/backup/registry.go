@@ -48,6 +48,11 @@ type RegistryCredentials struct {
 	UpdatedAt       string `json:"updatedAt"`
 	SecretName      string `json:"secretName"`
 	SecretNamespace string `json:"secretNamespace"`
+	// Status is the result of the last health check: "Healthy", "Degraded", "Unauthorized" or
+	// "Unreachable", empty if the registry was never checked
+	Status        string `json:"status,omitempty"`
+	StatusMessage string `json:"statusMessage,omitempty"`
+	LastCheckedAt string `json:"lastCheckedAt,omitempty"`
 }
 
 // CreateRegistryRequest represents the request to create a new registry
@@ -314,6 +319,10 @@ func handleUpdateRegistry(c *gin.Context) {
 	}
 
 	secret.Annotations["backup.dcnlab.com/updated-at"] = metav1.Now().Format(time.RFC3339)
+	// The health of the old credentials says nothing about the new ones
+	if req.Registry != "" || req.Username != "" || req.Password != "" {
+		clearRegistryHealth(secret)
+	}
 
 	// Convert back to unstructured and update in Karmada
 	updatedSecretUnstructured, err := convertSecretToUnstructured(secret)
@@ -387,6 +396,9 @@ func secretToRegistry(secret *corev1.Secret) RegistryCredentials {
 		UpdatedAt:       secret.Annotations["backup.dcnlab.com/updated-at"],
 		SecretName:      secret.Name,
 		SecretNamespace: secret.Namespace,
+		Status:          secret.Annotations[registryHealthStatusAnnotation],
+		StatusMessage:   secret.Annotations[registryHealthMessageAnnotation],
+		LastCheckedAt:   secret.Annotations[registryHealthCheckedAnnotation],
 	}
 
 	if registry.CreatedAt == "" {
@@ -486,5 +498,8 @@ func init() {
 		registryGroup.GET("/:id", handleGetRegistry)
 		registryGroup.PUT("/:id", handleUpdateRegistry)
 		registryGroup.DELETE("/:id", handleDeleteRegistry)
+		// Testing runs authenticated handshakes with the stored credentials. Registries serve
+		// every member cluster, so like notification channels this is for dashboard admins
+		registryGroup.POST("/:id/test", router.EnsureDashboardAdminMiddleware(), handleTestRegistry)
 	}
 }
@@ -0,0 +1,444 @@
+/*
+Copyright 2024 The Karmada Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// manifestMediaTypes are the manifest types accepted from registries, so that the digest of
+// OCI images and Docker image lists can be resolved as well.
+var manifestMediaTypes = []string{
+	"application/vnd.oci.image.manifest.v1+json",
+	"application/vnd.oci.image.index.v1+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+}
+
+// errManifestNotFound is returned when a tag does not exist in the registry.
+var errManifestNotFound = errors.New("manifest not found")
+
+// errRepositoryNotFound is returned when a repository does not exist in the registry.
+var errRepositoryNotFound = errors.New("repository not found")
+
+// errRegistryUnauthorized is returned when the registry rejects the credentials.
+var errRegistryUnauthorized = errors.New("registry rejected the credentials")
+
+// errBlobNotFound is returned when a layer or config blob does not exist in the registry.
+var errBlobNotFound = errors.New("blob not found")
+
+// maxManifestSize bounds the manifests read from registries, which are a few KiB in practice.
+const maxManifestSize = 4 << 20
+
+// registryClient talks to the Docker Registry HTTP API V2, which OCI distribution registries
+// implement as well. It authenticates with basic auth, or with a bearer token when the registry
+// challenges for one.
+type registryClient struct {
+	baseURL    string
+	username   string
+	password   string
+	httpClient *http.Client
+
+	// authScheme is how the last request authenticated: "anonymous", "basic" or "bearer"
+	authScheme string
+	// tokenExpiresAt is when the last bearer token expires, if the token server said so
+	tokenExpiresAt time.Time
+}
+
+// newRegistryClient creates a client for registry credentials that include the password.
+func newRegistryClient(registry RegistryCredentials) *registryClient {
+	baseURL := strings.TrimSuffix(registry.Registry, "/")
+	if !strings.HasPrefix(baseURL, "http://") && !strings.HasPrefix(baseURL, "https://") {
+		baseURL = "https://" + baseURL
+	}
+	return &registryClient{
+		baseURL:    baseURL,
+		username:   registry.Username,
+		password:   registry.Password,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// host returns the host of the registry, as used in image references.
+func (rc *registryClient) host() string {
+	u, err := url.Parse(rc.baseURL)
+	if err != nil {
+		return ""
+	}
+	return u.Host
+}
+
+// manifestDigest resolves a tag to the digest of its manifest.
+func (rc *registryClient) manifestDigest(ctx context.Context, repository, tag string) (string, error) {
+	resp, err := rc.do(ctx, http.MethodHead, fmt.Sprintf("/v2/%s/manifests/%s", repository, tag), func(req *http.Request) {
+		req.Header.Set("Accept", strings.Join(manifestMediaTypes, ", "))
+	})
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return "", errManifestNotFound
+	default:
+		return "", fmt.Errorf("failed to get manifest %s:%s: registry returned %s", repository, tag, resp.Status)
+	}
+	digest := resp.Header.Get("Docker-Content-Digest")
+	if digest == "" {
+		return "", fmt.Errorf("registry returned no digest for %s:%s", repository, tag)
+	}
+	return digest, nil
+}
+
+// getManifest downloads a manifest by tag or digest. It returns the raw manifest, so that its
+// digest can be checked, along with the media type and the digest the registry reports.
+func (rc *registryClient) getManifest(ctx context.Context, repository, reference string) (body []byte, mediaType, digest string, err error) {
+	resp, err := rc.do(ctx, http.MethodGet, fmt.Sprintf("/v2/%s/manifests/%s", repository, reference), func(req *http.Request) {
+		req.Header.Set("Accept", strings.Join(manifestMediaTypes, ", "))
+	})
+	if err != nil {
+		return nil, "", "", err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, "", "", errManifestNotFound
+	default:
+		return nil, "", "", fmt.Errorf("failed to get manifest %s:%s: registry returned %s", repository, reference, resp.Status)
+	}
+	body, err = io.ReadAll(io.LimitReader(resp.Body, maxManifestSize+1))
+	if err != nil {
+		return nil, "", "", fmt.Errorf("failed to read manifest %s:%s: %v", repository, reference, err)
+	}
+	if len(body) > maxManifestSize {
+		return nil, "", "", fmt.Errorf("manifest %s:%s is larger than %d bytes", repository, reference, maxManifestSize)
+	}
+	return body, resp.Header.Get("Content-Type"), resp.Header.Get("Docker-Content-Digest"), nil
+}
+
+// blobSize returns the size of a blob as stored in the registry.
+func (rc *registryClient) blobSize(ctx context.Context, repository, digest string) (int64, error) {
+	resp, err := rc.do(ctx, http.MethodHead, fmt.Sprintf("/v2/%s/blobs/%s", repository, digest), nil)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return resp.ContentLength, nil
+	case http.StatusNotFound:
+		return 0, errBlobNotFound
+	default:
+		return 0, fmt.Errorf("failed to get blob %s@%s: registry returned %s", repository, digest, resp.Status)
+	}
+}
+
+// ping checks that the registry implements the V2 API and accepts the credentials.
+func (rc *registryClient) ping(ctx context.Context) error {
+	resp, err := rc.do(ctx, http.MethodGet, "/v2/", nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return nil
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return errRegistryUnauthorized
+	case http.StatusNotFound:
+		return fmt.Errorf("%s does not implement the registry V2 API", rc.host())
+	default:
+		return fmt.Errorf("registry %s returned %s", rc.host(), resp.Status)
+	}
+}
+
+// listRepositories lists up to limit repositories of the registry catalog. Many registries
+// restrict the catalog to administrators or do not implement it.
+func (rc *registryClient) listRepositories(ctx context.Context, limit int) ([]string, error) {
+	var body struct {
+		Repositories []string `json:"repositories"`
+	}
+	if err := rc.getJSON(ctx, fmt.Sprintf("/v2/_catalog?n=%d", limit), &body); err != nil {
+		return nil, err
+	}
+	return body.Repositories, nil
+}
+
+// listTags lists up to limit tags of a repository.
+func (rc *registryClient) listTags(ctx context.Context, repository string, limit int) ([]string, error) {
+	var body struct {
+		Tags []string `json:"tags"`
+	}
+	err := rc.getJSON(ctx, fmt.Sprintf("/v2/%s/tags/list?n=%d", repository, limit), &body)
+	if errors.Is(err, errManifestNotFound) {
+		return nil, errRepositoryNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return body.Tags, nil
+}
+
+// getJSON decodes the JSON response of a GET request. A missing resource fails with
+// errManifestNotFound.
+func (rc *registryClient) getJSON(ctx context.Context, path string, target interface{}) error {
+	resp, err := rc.do(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return errRegistryUnauthorized
+	case http.StatusNotFound:
+		return errManifestNotFound
+	default:
+		return fmt.Errorf("registry %s returned %s for %s", rc.host(), resp.Status, path)
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxManifestSize)).Decode(target); err != nil {
+		return fmt.Errorf("invalid response from registry %s: %v", rc.host(), err)
+	}
+	return nil
+}
+
+// resolveDigest returns the digest of the manifest reference points to. References that are
+// digests already are returned as they are.
+func (rc *registryClient) resolveDigest(ctx context.Context, repository, reference string) (string, error) {
+	if strings.Contains(reference, ":") {
+		return reference, nil
+	}
+	return rc.manifestDigest(ctx, repository, reference)
+}
+
+// deleteManifest deletes a manifest by digest. Registries only delete manifests by digest, which
+// also removes every tag of the manifest.
+func (rc *registryClient) deleteManifest(ctx context.Context, repository, digest string) error {
+	resp, err := rc.do(ctx, http.MethodDelete, fmt.Sprintf("/v2/%s/manifests/%s", repository, digest), nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusAccepted, http.StatusOK, http.StatusNoContent:
+		return nil
+	case http.StatusNotFound:
+		return errManifestNotFound
+	case http.StatusMethodNotAllowed:
+		return fmt.Errorf("registry %s does not allow deleting images", rc.host())
+	default:
+		return fmt.Errorf("failed to delete %s@%s: registry returned %s", repository, digest, resp.Status)
+	}
+}
+
+// do sends a request to the registry, answering a bearer token challenge if there is one.
+func (rc *registryClient) do(ctx context.Context, method, path string, prepare func(*http.Request)) (*http.Response, error) {
+	send := func(authorization string) (*http.Response, error) {
+		req, err := http.NewRequestWithContext(ctx, method, rc.baseURL+path, nil)
+		if err != nil {
+			return nil, err
+		}
+		if prepare != nil {
+			prepare(req)
+		}
+		rc.authScheme = "anonymous"
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+			rc.authScheme = "bearer"
+		} else if rc.username != "" {
+			req.SetBasicAuth(rc.username, rc.password)
+			rc.authScheme = "basic"
+		}
+		resp, err := rc.httpClient.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to reach registry %s: %v", rc.host(), err)
+		}
+		return resp, nil
+	}
+
+	resp, err := send("")
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+	challenge := resp.Header.Get("WWW-Authenticate")
+	if !strings.HasPrefix(strings.ToLower(challenge), "bearer ") {
+		return resp, nil
+	}
+	resp.Body.Close()
+
+	token, err := rc.fetchBearerToken(ctx, parseAuthChallenge(challenge))
+	if err != nil {
+		return nil, err
+	}
+	return send("Bearer " + token)
+}
+
+// fetchBearerToken gets a token from the authorization server named in a challenge.
+func (rc *registryClient) fetchBearerToken(ctx context.Context, params map[string]string) (string, error) {
+	realm := params["realm"]
+	if realm == "" {
+		return "", fmt.Errorf("registry %s sent a bearer challenge without realm", rc.host())
+	}
+	tokenURL, err := url.Parse(realm)
+	if err != nil {
+		return "", fmt.Errorf("invalid token realm %q: %v", realm, err)
+	}
+	query := tokenURL.Query()
+	for _, key := range []string{"service", "scope"} {
+		if value := params[key]; value != "" {
+			query.Set(key, value)
+		}
+	}
+	tokenURL.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, tokenURL.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	if rc.username != "" {
+		req.SetBasicAuth(rc.username, rc.password)
+	}
+	resp, err := rc.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to reach token server of registry %s: %v", rc.host(), err)
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return "", fmt.Errorf("token server of registry %s returned %s: %w", rc.host(), resp.Status, errRegistryUnauthorized)
+	default:
+		return "", fmt.Errorf("token server of registry %s returned %s", rc.host(), resp.Status)
+	}
+
+	var body struct {
+		Token       string    `json:"token"`
+		AccessToken string    `json:"access_token"`
+		ExpiresIn   int       `json:"expires_in"`
+		IssuedAt    time.Time `json:"issued_at"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&body); err != nil {
+		return "", fmt.Errorf("invalid token response from registry %s: %v", rc.host(), err)
+	}
+	if body.ExpiresIn > 0 {
+		issuedAt := body.IssuedAt
+		if issuedAt.IsZero() {
+			issuedAt = time.Now()
+		}
+		rc.tokenExpiresAt = issuedAt.Add(time.Duration(body.ExpiresIn) * time.Second)
+	}
+	if body.Token != "" {
+		return body.Token, nil
+	}
+	if body.AccessToken != "" {
+		return body.AccessToken, nil
+	}
+	return "", fmt.Errorf("token server of registry %s returned no token", rc.host())
+}
+
+// parseAuthChallenge parses the parameters of a WWW-Authenticate header such as
+// Bearer realm="https://auth.docker.io/token",service="registry.docker.io",scope="repository:a/b:pull".
+func parseAuthChallenge(header string) map[string]string {
+	params := map[string]string{}
+	_, rest, found := strings.Cut(header, " ")
+	if !found {
+		return params
+	}
+	for rest != "" {
+		key, value, ok := strings.Cut(strings.TrimLeft(rest, " ,"), "=")
+		if !ok {
+			break
+		}
+		if strings.HasPrefix(value, `"`) {
+			end := strings.Index(value[1:], `"`)
+			if end < 0 {
+				params[strings.ToLower(key)] = value[1:]
+				break
+			}
+			params[strings.ToLower(key)] = value[1 : end+1]
+			rest = value[end+2:]
+		} else {
+			value, rest, _ = strings.Cut(value, ",")
+			params[strings.ToLower(key)] = value
+		}
+	}
+	return params
+}
+
+// parseImageReference splits an image reference like registry.example.com/backups/web:20240101
+// into repository and tag, or digest for references like backups/web@sha256:... The registry
+// host is dropped when the reference names one.
+func parseImageReference(reference string) (repository, tag string, err error) {
+	reference = strings.TrimPrefix(strings.TrimPrefix(reference, "https://"), "http://")
+	if first, rest, found := strings.Cut(reference, "/"); found &&
+		(strings.ContainsAny(first, ".:") || first == "localhost") {
+		reference = rest
+	}
+
+	if name, digest, found := strings.Cut(reference, "@"); found {
+		repository, tag = name, digest
+	} else if i := strings.LastIndex(reference, ":"); i > strings.LastIndex(reference, "/") {
+		repository, tag = reference[:i], reference[i+1:]
+	}
+	if repository == "" || tag == "" {
+		return "", "", fmt.Errorf("image reference %q names no tag or digest", reference)
+	}
+	return repository, tag, nil
+}
+
+// getRegistryWithPassword gets the credentials of a registry including the password, which
+// secretToRegistry leaves out for API responses. The result must not be returned to clients.
+func getRegistryWithPassword(secretName string) (RegistryCredentials, error) {
+	karmadaDynamicClient, err := getKarmadaDynamicClient()
+	if err != nil {
+		return RegistryCredentials{}, fmt.Errorf("failed to get Karmada dynamic client: %v", err)
+	}
+
+	secretUnstructured, err := karmadaDynamicClient.Resource(schema.GroupVersionResource{Version: "v1", Resource: "secrets"}).
+		Namespace(registryNamespace).Get(context.TODO(), secretName, metav1.GetOptions{})
+	if err != nil {
+		return RegistryCredentials{}, err
+	}
+	secret := &corev1.Secret{}
+	if err := convertUnstructuredToTyped(secretUnstructured, secret); err != nil {
+		return RegistryCredentials{}, fmt.Errorf("failed to convert secret: %v", err)
+	}
+
+	registry := secretToRegistry(secret)
+	registry.Password = string(secret.Data["password"])
+	return registry, nil
+}
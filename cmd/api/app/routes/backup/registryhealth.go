@@ -0,0 +1,303 @@
+/*
+Copyright 2024 The Karmada Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/klog/v2"
+
+	"github.com/karmada-io/dashboard/cmd/api/app/types/common"
+	"github.com/karmada-io/dashboard/pkg/client"
+)
+
+// Health statuses of registries
+const (
+	RegistryHealthy      = "Healthy"
+	RegistryDegraded     = "Degraded"
+	RegistryUnauthorized = "Unauthorized"
+	RegistryUnreachable  = "Unreachable"
+)
+
+const (
+	registryHealthStatusAnnotation  = "backup.dcnlab.com/health-status"
+	registryHealthMessageAnnotation = "backup.dcnlab.com/health-message"
+	registryHealthCheckedAnnotation = "backup.dcnlab.com/health-checked-at"
+
+	// registryListLimit bounds the repositories and tags listed by a registry test
+	registryListLimit = 100
+)
+
+// RepositoryTags lists the tags of a backup repository
+type RepositoryTags struct {
+	Repository string   `json:"repository"`
+	Tags       []string `json:"tags"`
+	Error      string   `json:"error,omitempty"`
+}
+
+// RegistryTestReport is the outcome of testing the credentials of a registry
+type RegistryTestReport struct {
+	RegistryID     string           `json:"registryId"`
+	Registry       string           `json:"registry"`
+	Status         string           `json:"status"`
+	Message        string           `json:"message"`
+	AuthScheme     string           `json:"authScheme,omitempty"` // "anonymous", "basic" or "bearer"
+	TokenExpiresAt *time.Time       `json:"tokenExpiresAt,omitempty"`
+	CheckedAt      time.Time        `json:"checkedAt"`
+	Checks         []PreflightCheck `json:"checks"`
+	Repositories   []string         `json:"repositories,omitempty"` // From the catalog, if the registry allows listing it
+	Backups        []RepositoryTags `json:"backups"`
+}
+
+// add records a check.
+func (r *RegistryTestReport) add(name, status, message string) {
+	r.Checks = append(r.Checks, PreflightCheck{Name: name, Status: status, Message: message})
+}
+
+// testRegistry authenticates against a registry and lists the tags of the backup repositories
+// stored in it.
+func testRegistry(ctx context.Context, credentials RegistryCredentials, repositories []string) RegistryTestReport {
+	report := RegistryTestReport{
+		RegistryID: credentials.ID,
+		Registry:   credentials.Registry,
+		CheckedAt:  time.Now(),
+		Checks:     []PreflightCheck{},
+		Backups:    []RepositoryTags{},
+	}
+	registry := newRegistryClient(credentials)
+
+	err := registry.ping(ctx)
+	report.AuthScheme = registry.authScheme
+	if !registry.tokenExpiresAt.IsZero() {
+		report.TokenExpiresAt = &registry.tokenExpiresAt
+	}
+	switch {
+	case errors.Is(err, errRegistryUnauthorized):
+		report.add("authentication", PreflightFailed, err.Error())
+		report.Status, report.Message = RegistryUnauthorized, "the registry rejected the credentials, they may have expired"
+		return report
+	case err != nil:
+		report.add("authentication", PreflightFailed, err.Error())
+		report.Status, report.Message = RegistryUnreachable, err.Error()
+		return report
+	}
+	report.add("authentication", PreflightPassed, fmt.Sprintf("authenticated with %s auth", registry.authScheme))
+
+	if catalog, err := registry.listRepositories(ctx, registryListLimit); err != nil {
+		report.add("catalog", PreflightWarning, fmt.Sprintf("repositories cannot be listed: %v", err))
+	} else {
+		report.Repositories = catalog
+		report.add("catalog", PreflightPassed, fmt.Sprintf("%d repositories listed", len(catalog)))
+	}
+
+	report.Status, report.Message = RegistryHealthy, "credentials are valid"
+	for _, repository := range repositories {
+		tags, err := registry.listTags(ctx, repository, registryListLimit)
+		entry := RepositoryTags{Repository: repository, Tags: tags}
+		switch {
+		case err == nil:
+			report.add("repository:"+repository, PreflightPassed, fmt.Sprintf("%d tags", len(tags)))
+		case errors.Is(err, errRepositoryNotFound):
+			// Repositories are created by the first push
+			entry.Error = err.Error()
+			report.add("repository:"+repository, PreflightWarning, "repository has no backups yet")
+		case errors.Is(err, errRegistryUnauthorized):
+			entry.Error = err.Error()
+			report.add("repository:"+repository, PreflightFailed, "credentials do not grant access to the repository")
+			report.Status, report.Message = RegistryUnauthorized, fmt.Sprintf("credentials do not grant access to %s", repository)
+		default:
+			entry.Error = err.Error()
+			report.add("repository:"+repository, PreflightFailed, err.Error())
+			if report.Status == RegistryHealthy {
+				report.Status, report.Message = RegistryDegraded, err.Error()
+			}
+		}
+		if entry.Tags == nil {
+			entry.Tags = []string{}
+		}
+		report.Backups = append(report.Backups, entry)
+	}
+	return report
+}
+
+// backupRepositories lists the repositories the backups stored in a registry push to.
+func backupRepositories(ctx context.Context, secretName string) ([]string, error) {
+	dynamicClient, err := client.GetDynamicClient()
+	if err != nil {
+		return nil, err
+	}
+	backups, err := dynamicClient.Resource(statefulMigrationGVR).Namespace(defaultNamespace).List(ctx, metav1.ListOptions{
+		LabelSelector: "app=backup-migration",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	seen := map[string]bool{}
+	for _, backup := range backups.Items {
+		name, _, _ := unstructured.NestedString(backup.Object, "spec", "registry", "secretRef", "name")
+		repository, _, _ := unstructured.NestedString(backup.Object, "spec", "registry", "repository")
+		if name == secretName && repository != "" {
+			seen[repository] = true
+		}
+	}
+	repositories := make([]string, 0, len(seen))
+	for repository := range seen {
+		repositories = append(repositories, repository)
+	}
+	sort.Strings(repositories)
+	return repositories, nil
+}
+
+// clearRegistryHealth removes the recorded health of a registry secret.
+func clearRegistryHealth(secret *corev1.Secret) {
+	delete(secret.Annotations, registryHealthStatusAnnotation)
+	delete(secret.Annotations, registryHealthMessageAnnotation)
+	delete(secret.Annotations, registryHealthCheckedAnnotation)
+}
+
+// recordRegistryHealth stores the result of a test on the registry secret and returns the
+// status recorded before. It fails on conflicts, so that only one replica reports a change.
+func recordRegistryHealth(ctx context.Context, secretName string, report RegistryTestReport) (string, error) {
+	karmadaDynamicClient, err := getKarmadaDynamicClient()
+	if err != nil {
+		return "", err
+	}
+	secrets := karmadaDynamicClient.Resource(secretGVR).Namespace(registryNamespace)
+	obj, err := secrets.Get(ctx, secretName, metav1.GetOptions{})
+	if err != nil {
+		return "", err
+	}
+
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	previous := annotations[registryHealthStatusAnnotation]
+	annotations[registryHealthStatusAnnotation] = report.Status
+	annotations[registryHealthMessageAnnotation] = report.Message
+	annotations[registryHealthCheckedAnnotation] = report.CheckedAt.Format(time.RFC3339)
+	obj.SetAnnotations(annotations)
+	if _, err := secrets.Update(ctx, obj, metav1.UpdateOptions{}); err != nil {
+		return "", err
+	}
+	return previous, nil
+}
+
+// checkRegistry tests a registry, records the result and notifies when its credentials stop
+// working.
+func checkRegistry(ctx context.Context, credentials RegistryCredentials) RegistryTestReport {
+	repositories, err := backupRepositories(ctx, credentials.SecretName)
+	if err != nil {
+		klog.ErrorS(err, "Failed to list backup repositories of registry", "registryID", credentials.ID)
+	}
+	report := testRegistry(ctx, credentials, repositories)
+
+	previous, err := recordRegistryHealth(ctx, credentials.SecretName, report)
+	if apierrors.IsConflict(err) {
+		return report
+	}
+	if err != nil {
+		klog.ErrorS(err, "Failed to record registry health", "registryID", credentials.ID)
+		return report
+	}
+	if report.Status == RegistryUnauthorized && previous != RegistryUnauthorized {
+		klog.InfoS("Registry credentials were rejected", "registryID", credentials.ID, "registry", credentials.Registry)
+		notify(ctx, Notification{
+			Event:     EventRegistryCredentialsExpired,
+			Name:      credentials.Name,
+			Message:   fmt.Sprintf("Registry %s rejected the credentials of user %s: %s.", credentials.Registry, credentials.Username, report.Message),
+			Timestamp: report.CheckedAt,
+		})
+	}
+	return report
+}
+
+// checkAllRegistries tests the credentials of every registry.
+func checkAllRegistries(ctx context.Context) {
+	karmadaDynamicClient, err := getKarmadaDynamicClient()
+	if err != nil {
+		klog.ErrorS(err, "Failed to get Karmada dynamic client for registry health checks")
+		return
+	}
+	list, err := karmadaDynamicClient.Resource(secretGVR).Namespace(registryNamespace).List(ctx, metav1.ListOptions{
+		LabelSelector: "app=backup-registry",
+	})
+	if err != nil {
+		klog.ErrorS(err, "Failed to list registries for health checks")
+		return
+	}
+
+	for i := range list.Items {
+		secret := &corev1.Secret{}
+		if err := convertUnstructuredToTyped(&list.Items[i], secret); err != nil {
+			klog.ErrorS(err, "Failed to convert secret", "secretName", list.Items[i].GetName())
+			continue
+		}
+		credentials := secretToRegistry(secret)
+		credentials.Password = string(secret.Data["password"])
+		report := checkRegistry(ctx, credentials)
+		klog.V(2).InfoS("Checked registry health", "registryID", credentials.ID, "status", report.Status)
+	}
+}
+
+// StartRegistryHealthChecker tests the credentials of all registries every interval until ctx
+// is done, so that expired credentials are noticed before a backup fails. 0 disables the checks.
+func StartRegistryHealthChecker(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				checkAllRegistries(ctx)
+			}
+		}
+	}()
+}
+
+// handleTestRegistry tests the credentials of a registry and records the result. The repository
+// query parameter, which may be repeated, lists other repositories than those of the backups.
+func handleTestRegistry(c *gin.Context) {
+	registryID := c.Param("id")
+	credentials, err := getRegistryWithPassword(fmt.Sprintf("%s-%s", registrySecretPrefix, registryID))
+	if err != nil {
+		klog.ErrorS(err, "Failed to get registry", "registryID", registryID)
+		common.Fail(c, err)
+		return
+	}
+
+	if repositories := c.QueryArray("repository"); len(repositories) > 0 {
+		common.Success(c, testRegistry(c, credentials, repositories))
+		return
+	}
+	common.Success(c, checkRegistry(c, credentials))
+}
@@ -0,0 +1,180 @@
+/*
+Copyright 2024 The Karmada Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/karmada-io/dashboard/cmd/api/app/router"
+	"github.com/karmada-io/dashboard/pkg/auth"
+	"github.com/karmada-io/dashboard/pkg/auth/fga"
+)
+
+func TestTestRegistryRequiresDashboardAdmin(t *testing.T) {
+	fga.FGAService = fga.NewServiceWithClient(fga.NewFakeClient(
+		fga.Tuple{User: "bob", Relation: fga.RelationEditor, ObjectType: fga.TypeCluster, ObjectID: "member1"},
+	))
+	t.Cleanup(func() { fga.FGAService = nil })
+
+	cases := []struct {
+		username string
+		status   int
+	}{
+		{"", http.StatusUnauthorized},
+		{"bob", http.StatusForbidden},
+	}
+
+	for _, c := range cases {
+		engine := gin.New()
+		engine.Use(func(ctx *gin.Context) {
+			ctx.Request = ctx.Request.WithContext(auth.WithUser(ctx.Request.Context(), c.username))
+		})
+		engine.POST("/registry/:id/test", router.EnsureDashboardAdminMiddleware(), handleTestRegistry)
+		recorder := httptest.NewRecorder()
+		engine.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/registry/r1/test", nil))
+		if recorder.Code != c.status {
+			t.Errorf("POST as %q: got %d %s, expected %d", c.username, recorder.Code, recorder.Body.String(), c.status)
+		}
+	}
+
+	recorder := httptest.NewRecorder()
+	router.Router().ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/api/v1/backup/registry/r1/test", nil))
+	if recorder.Code != http.StatusUnauthorized {
+		t.Errorf("POST /api/v1/backup/registry/r1/test without credentials: got %d %s, expected 401", recorder.Code, recorder.Body.String())
+	}
+}
+
+// newRegistryStandIn serves the parts of the registry API used by registry tests. With bearer
+// set, it sends clients to its token endpoint like Docker Hub or Harbor do, otherwise it checks
+// basic auth on every request.
+func newRegistryStandIn(bearer bool) *httptest.Server {
+	const username, password, token = "robot", "s3cret", "token-1"
+	repositories := map[string][]string{"backups/web": {"20240101", "20240102"}}
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			if user, pass, ok := r.BasicAuth(); !ok || user != username || pass != password {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"token": token, "expires_in": 300})
+			return
+		}
+
+		authorized := false
+		if bearer {
+			authorized = r.Header.Get("Authorization") == "Bearer "+token
+		} else {
+			user, pass, ok := r.BasicAuth()
+			authorized = ok && user == username && pass == password
+		}
+		if !authorized {
+			if bearer {
+				w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="registry"`, server.URL))
+			} else {
+				w.Header().Set("WWW-Authenticate", `Basic realm="registry"`)
+			}
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		switch r.URL.Path {
+		case "/v2/":
+			w.WriteHeader(http.StatusOK)
+		case "/v2/_catalog":
+			if bearer {
+				// Catalogs are often reserved to administrators
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"repositories": []string{"backups/web"}})
+		default:
+			for repository, tags := range repositories {
+				if r.URL.Path == "/v2/"+repository+"/tags/list" {
+					json.NewEncoder(w).Encode(map[string]interface{}{"name": repository, "tags": tags})
+					return
+				}
+			}
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	return server
+}
+
+func TestTestRegistry(t *testing.T) {
+	cases := []struct {
+		name       string
+		bearer     bool
+		password   string
+		status     string
+		authScheme string
+		checks     map[string]string
+	}{
+		{"basic auth", false, "s3cret", RegistryHealthy, "basic", map[string]string{
+			"authentication": PreflightPassed, "catalog": PreflightPassed,
+			"repository:backups/web": PreflightPassed, "repository:backups/db": PreflightWarning,
+		}},
+		{"bearer token", true, "s3cret", RegistryHealthy, "bearer", map[string]string{
+			"authentication": PreflightPassed, "catalog": PreflightWarning,
+			"repository:backups/web": PreflightPassed, "repository:backups/db": PreflightWarning,
+		}},
+		{"expired basic credentials", false, "expired", RegistryUnauthorized, "basic", map[string]string{
+			"authentication": PreflightFailed,
+		}},
+		{"expired bearer credentials", true, "expired", RegistryUnauthorized, "basic", map[string]string{
+			"authentication": PreflightFailed,
+		}},
+	}
+
+	for _, c := range cases {
+		server := newRegistryStandIn(c.bearer)
+		credentials := RegistryCredentials{ID: "r1", Registry: server.URL, Username: "robot", Password: c.password}
+		report := testRegistry(context.TODO(), credentials, []string{"backups/db", "backups/web"})
+		server.Close()
+
+		checks := map[string]string{}
+		for _, check := range report.Checks {
+			checks[check.Name] = check.Status
+		}
+		if report.Status != c.status || report.AuthScheme != c.authScheme || !reflect.DeepEqual(checks, c.checks) {
+			t.Errorf("%s: testRegistry() == %s %s %v, expected %s %s %v", c.name,
+				report.Status, report.AuthScheme, checks, c.status, c.authScheme, c.checks)
+		}
+		if c.status == RegistryHealthy && !reflect.DeepEqual(report.Backups[1].Tags, []string{"20240101", "20240102"}) {
+			t.Errorf("%s: tags of backups/web == %v", c.name, report.Backups[1].Tags)
+		}
+		if c.status == RegistryHealthy && c.bearer && report.TokenExpiresAt == nil {
+			t.Errorf("%s: token expiry not reported", c.name)
+		}
+	}
+
+	server := newRegistryStandIn(false)
+	server.Close()
+	report := testRegistry(context.TODO(), RegistryCredentials{Registry: server.URL}, nil)
+	if report.Status != RegistryUnreachable {
+		t.Errorf("testRegistry() of a stopped registry == %s, expected %s", report.Status, RegistryUnreachable)
+	}
+}
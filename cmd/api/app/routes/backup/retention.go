@@ -0,0 +1,470 @@
+/*
+Copyright 2024 The Karmada Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/klog/v2"
+
+	"github.com/karmada-io/dashboard/cmd/api/app/types/common"
+	"github.com/karmada-io/dashboard/pkg/client"
+)
+
+const (
+	// backupHistoryNamespace is where the history ConfigMaps of backup executions are kept
+	backupHistoryNamespace = "karmada-system"
+)
+
+var configMapGVR = schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+
+// RetentionPolicy decides which executions of a scheduled backup are kept. A successful execution
+// is kept when any of the keep rules selects it, the daily, weekly and monthly rules select the
+// newest execution of each period. Failed executions are kept until a later one succeeds.
+// Executions older than MaxAgeDays are pruned even if a rule selects them, except for the newest
+// successful one which is always kept. Running executions are never pruned.
+type RetentionPolicy struct {
+	// KeepLast keeps the newest executions
+	KeepLast int `json:"keepLast,omitempty" form:"keepLast" binding:"min=0"`
+	// KeepDaily keeps the newest execution of each of the last days with executions
+	KeepDaily int `json:"keepDaily,omitempty" form:"keepDaily" binding:"min=0"`
+	// KeepWeekly keeps the newest execution of each of the last ISO weeks with executions
+	KeepWeekly int `json:"keepWeekly,omitempty" form:"keepWeekly" binding:"min=0"`
+	// KeepMonthly keeps the newest execution of each of the last months with executions
+	KeepMonthly int `json:"keepMonthly,omitempty" form:"keepMonthly" binding:"min=0"`
+	// MaxAgeDays prunes executions older than this many days
+	MaxAgeDays int `json:"maxAgeDays,omitempty" form:"maxAgeDays" binding:"min=0"`
+}
+
+// isEmpty reports whether the policy keeps everything.
+func (p RetentionPolicy) isEmpty() bool {
+	return p == RetentionPolicy{}
+}
+
+// hasKeepRules reports whether the policy selects executions to keep, rather than keeping
+// everything younger than MaxAgeDays.
+func (p RetentionPolicy) hasKeepRules() bool {
+	return p.KeepLast > 0 || p.KeepDaily > 0 || p.KeepWeekly > 0 || p.KeepMonthly > 0
+}
+
+// toSpec stores the policy in a StatefulMigration spec, removing it when empty.
+func (p RetentionPolicy) toSpec(spec map[string]interface{}) {
+	if p.isEmpty() {
+		delete(spec, "retention")
+		return
+	}
+	retention := map[string]interface{}{}
+	for field, value := range map[string]int{
+		"keepLast":    p.KeepLast,
+		"keepDaily":   p.KeepDaily,
+		"keepWeekly":  p.KeepWeekly,
+		"keepMonthly": p.KeepMonthly,
+		"maxAgeDays":  p.MaxAgeDays,
+	} {
+		if value > 0 {
+			retention[field] = int64(value)
+		}
+	}
+	spec["retention"] = retention
+}
+
+// retentionFromSpec reads the policy stored by toSpec, nil when the backup has none.
+func retentionFromSpec(sm *unstructured.Unstructured) *RetentionPolicy {
+	if _, found, _ := unstructured.NestedMap(sm.Object, "spec", "retention"); !found {
+		return nil
+	}
+	policy := &RetentionPolicy{}
+	for field, target := range map[string]*int{
+		"keepLast":    &policy.KeepLast,
+		"keepDaily":   &policy.KeepDaily,
+		"keepWeekly":  &policy.KeepWeekly,
+		"keepMonthly": &policy.KeepMonthly,
+		"maxAgeDays":  &policy.MaxAgeDays,
+	} {
+		if value, found, _ := unstructured.NestedInt64(sm.Object, "spec", "retention", field); found {
+			*target = int(value)
+		}
+	}
+	return policy
+}
+
+// BackupExecution is an execution of a backup as recorded in its history ConfigMap.
+type BackupExecution struct {
+	ID             string    `json:"id"`
+	Timestamp      time.Time `json:"timestamp"`
+	Status         string    `json:"status"`
+	CheckpointPath string    `json:"checkpointPath,omitempty"`
+}
+
+// succeeded reports whether the execution produced a checkpoint.
+func (e BackupExecution) succeeded() bool {
+	switch strings.ToLower(e.Status) {
+	case "completed", "succeeded", "success":
+		return true
+	}
+	return false
+}
+
+// inProgress reports whether the execution is still running, which is never pruned.
+func (e BackupExecution) inProgress() bool {
+	switch strings.ToLower(e.Status) {
+	case "pending", "running", "inprogress", "in-progress":
+		return true
+	}
+	return false
+}
+
+// PrunedExecution is an execution selected for pruning.
+type PrunedExecution struct {
+	BackupExecution
+	Reason string `json:"reason"`
+	// Error is set when the execution could not be pruned, it is retried on the next run
+	Error string `json:"error,omitempty"`
+}
+
+// PruneReport lists what pruning a backup removed, or would remove in a dry run.
+type PruneReport struct {
+	BackupID string            `json:"backupId"`
+	Policy   *RetentionPolicy  `json:"policy,omitempty"`
+	DryRun   bool              `json:"dryRun"`
+	Kept     int               `json:"kept"`
+	Pruned   []PrunedExecution `json:"pruned"`
+}
+
+// planRetention splits the executions of a backup into those the policy keeps and those it prunes.
+func planRetention(policy RetentionPolicy, executions []BackupExecution, now time.Time) (kept []BackupExecution, pruned []PrunedExecution) {
+	sorted := make([]BackupExecution, len(executions))
+	copy(sorted, executions)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Timestamp.After(sorted[j].Timestamp) })
+	if policy.isEmpty() {
+		return sorted, nil
+	}
+
+	buckets := []struct {
+		remaining int
+		key       func(time.Time) string
+		last      string
+	}{
+		{remaining: policy.KeepDaily, key: func(t time.Time) string { return t.Format("2006-01-02") }},
+		{remaining: policy.KeepWeekly, key: func(t time.Time) string {
+			year, week := t.ISOWeek()
+			return fmt.Sprintf("%d-W%02d", year, week)
+		}},
+		{remaining: policy.KeepMonthly, key: func(t time.Time) string { return t.Format("2006-01") }},
+	}
+	remainingLast := policy.KeepLast
+	seenSuccessful := false
+
+	for _, execution := range sorted {
+		if execution.inProgress() {
+			kept = append(kept, execution)
+			continue
+		}
+
+		// Keep rules count successful executions only, failed ones are kept until a later
+		// execution succeeds so that recent failures stay visible
+		keep, reason := !seenSuccessful, "failed execution"
+		newestSuccessful := false
+		if execution.succeeded() {
+			newestSuccessful = !seenSuccessful
+			seenSuccessful = true
+			keep, reason = !policy.hasKeepRules(), "not selected by the retention policy"
+			if remainingLast > 0 {
+				remainingLast--
+				keep = true
+			}
+			for b := range buckets {
+				key := buckets[b].key(execution.Timestamp.UTC())
+				if buckets[b].remaining > 0 && key != buckets[b].last {
+					buckets[b].remaining--
+					buckets[b].last = key
+					keep = true
+				}
+			}
+		}
+
+		if policy.MaxAgeDays > 0 && now.Sub(execution.Timestamp) > time.Duration(policy.MaxAgeDays)*24*time.Hour {
+			keep = false
+			reason = fmt.Sprintf("older than %d days", policy.MaxAgeDays)
+		}
+		if keep || newestSuccessful {
+			kept = append(kept, execution)
+			continue
+		}
+		pruned = append(pruned, PrunedExecution{BackupExecution: execution, Reason: reason})
+	}
+	return kept, pruned
+}
+
+// configMapToBackupExecution reads an execution from its history ConfigMap.
+func configMapToBackupExecution(cm *unstructured.Unstructured) BackupExecution {
+	data, _, _ := unstructured.NestedStringMap(cm.Object, "data")
+	execution := BackupExecution{
+		ID:             cm.GetName(),
+		Status:         data["status"],
+		CheckpointPath: data["checkpointPath"],
+		Timestamp:      cm.GetCreationTimestamp().Time,
+	}
+	if timestamp, err := time.Parse(time.RFC3339, data["timestamp"]); err == nil {
+		execution.Timestamp = timestamp
+	}
+	return execution
+}
+
+// pruneBackup applies the retention policy of a backup. Registry tags are deleted before the
+// history entry, so that an entry whose image could not be deleted is retried on the next run.
+func pruneBackup(ctx context.Context, sm *unstructured.Unstructured, policy RetentionPolicy, dryRun bool) (*PruneReport, error) {
+	backupID := sm.GetLabels()["backup-id"]
+	report := &PruneReport{BackupID: backupID, Policy: &policy, DryRun: dryRun, Pruned: []PrunedExecution{}}
+
+	dynamicClient, err := client.GetDynamicClient()
+	if err != nil {
+		return nil, err
+	}
+	historyClient := dynamicClient.Resource(configMapGVR).Namespace(backupHistoryNamespace)
+	history, err := historyClient.List(ctx, metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("app=backup-history,backup-id=%s", backupID),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list backup history: %v", err)
+	}
+	executions := make([]BackupExecution, 0, len(history.Items))
+	for i := range history.Items {
+		executions = append(executions, configMapToBackupExecution(&history.Items[i]))
+	}
+
+	kept, pruned := planRetention(policy, executions, time.Now())
+	report.Kept = len(kept)
+	if dryRun || len(pruned) == 0 {
+		report.Pruned = append(report.Pruned, pruned...)
+		return report, nil
+	}
+
+	var registry *registryClient
+	if secretName, found, _ := unstructured.NestedString(sm.Object, "spec", "registry", "secretRef", "name"); found {
+		credentials, err := getRegistryWithPassword(secretName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get registry credentials: %v", err)
+		}
+		registry = newRegistryClient(credentials)
+	}
+
+	var keptImages map[string]bool
+	if registry != nil {
+		if keptImages, err = checkpointImages(ctx, registry, kept); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, execution := range pruned {
+		if execution.CheckpointPath != "" && registry != nil {
+			if err := deleteCheckpointImage(ctx, registry, execution.CheckpointPath, keptImages); err != nil {
+				execution.Error = err.Error()
+				report.Pruned = append(report.Pruned, execution)
+				continue
+			}
+		}
+		if err := historyClient.Delete(ctx, execution.ID, metav1.DeleteOptions{}); err != nil {
+			execution.Error = fmt.Sprintf("failed to delete history entry: %v", err)
+		}
+		report.Pruned = append(report.Pruned, execution)
+	}
+	return report, nil
+}
+
+// checkpointImages resolves the checkpoint images of executions to their manifests, as
+// repository@digest. Images that are gone already are left out.
+func checkpointImages(ctx context.Context, registry *registryClient, executions []BackupExecution) (map[string]bool, error) {
+	images := map[string]bool{}
+	for _, execution := range executions {
+		if execution.CheckpointPath == "" {
+			continue
+		}
+		image, err := checkpointImage(ctx, registry, execution.CheckpointPath)
+		if errors.Is(err, errManifestNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve the checkpoint image of execution %s: %v", execution.ID, err)
+		}
+		images[image] = true
+	}
+	return images, nil
+}
+
+// checkpointImage resolves the checkpoint image at checkpointPath to its manifest, as
+// repository@digest.
+func checkpointImage(ctx context.Context, registry *registryClient, checkpointPath string) (string, error) {
+	repository, tag, err := parseImageReference(checkpointPath)
+	if err != nil {
+		return "", err
+	}
+	digest, err := registry.resolveDigest(ctx, repository, tag)
+	if err != nil {
+		return "", err
+	}
+	return repository + "@" + digest, nil
+}
+
+// deleteCheckpointImage deletes the registry image of a checkpoint. Deleting an image deletes its
+// manifest along with every tag of it, so images that are also the checkpoint of a kept execution,
+// listed in keptImages, are left alone. Images that are gone already count as deleted.
+func deleteCheckpointImage(ctx context.Context, registry *registryClient, checkpointPath string, keptImages map[string]bool) error {
+	image, err := checkpointImage(ctx, registry, checkpointPath)
+	if errors.Is(err, errManifestNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if keptImages[image] {
+		return nil
+	}
+	repository, digest, _ := strings.Cut(image, "@")
+	if err := registry.deleteManifest(ctx, repository, digest); err != nil && !errors.Is(err, errManifestNotFound) {
+		return err
+	}
+	return nil
+}
+
+// pruneAllBackups applies the retention policies of all backups that have one.
+func pruneAllBackups(ctx context.Context) {
+	dynamicClient, err := client.GetDynamicClient()
+	if err != nil {
+		klog.ErrorS(err, "Failed to get dynamic client for backup pruning")
+		return
+	}
+	backups, err := dynamicClient.Resource(statefulMigrationGVR).List(ctx, metav1.ListOptions{
+		LabelSelector: "app=backup-migration",
+	})
+	if err != nil {
+		klog.ErrorS(err, "Failed to list backups for pruning")
+		return
+	}
+
+	for i := range backups.Items {
+		sm := &backups.Items[i]
+		policy := retentionFromSpec(sm)
+		if policy == nil || policy.isEmpty() {
+			continue
+		}
+		report, err := pruneBackup(ctx, sm, *policy, false)
+		if err != nil {
+			klog.ErrorS(err, "Failed to prune backup", "backupID", sm.GetLabels()["backup-id"])
+			continue
+		}
+		for _, execution := range report.Pruned {
+			if execution.Error != "" {
+				klog.InfoS("Failed to prune backup execution", "backupID", report.BackupID, "execution", execution.ID, "error", execution.Error)
+			}
+		}
+		if len(report.Pruned) > 0 {
+			klog.V(2).InfoS("Pruned backup executions", "backupID", report.BackupID, "count", len(report.Pruned), "kept", report.Kept)
+		}
+	}
+}
+
+// StartPruner periodically applies the retention policies of all backups until ctx is done.
+// Pruning is idempotent, so running it in several API server replicas is safe.
+func StartPruner(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				pruneAllBackups(ctx)
+			}
+		}
+	}()
+}
+
+// handlePreviewPrune shows what the retention policy of a backup would prune. Retention query
+// parameters, e.g. ?keepLast=5&maxAgeDays=30, preview another policy instead.
+func handlePreviewPrune(c *gin.Context) {
+	prune(c, true)
+}
+
+// handlePrune applies the retention policy of a backup now.
+func handlePrune(c *gin.Context) {
+	prune(c, false)
+}
+
+// authorizePrune checks that the caller may prune a backup. Pruning deletes checkpoint images
+// and history of the source clusters, so it requires write access to all of them.
+func authorizePrune(c *gin.Context, sm *unstructured.Unstructured) (int, error) {
+	clusters, _, _ := unstructured.NestedStringSlice(sm.Object, "spec", "sourceClusters")
+	return authorizeClustersWrite(c, clusters)
+}
+
+func prune(c *gin.Context, dryRun bool) {
+	backupID := c.Param("id")
+	dynamicClient, err := client.GetDynamicClient()
+	if err != nil {
+		klog.ErrorS(err, "Failed to get dynamic client")
+		common.Fail(c, err)
+		return
+	}
+	sm, err := dynamicClient.Resource(statefulMigrationGVR).Namespace(defaultNamespace).Get(c,
+		fmt.Sprintf("backup-%s", backupID), metav1.GetOptions{})
+	if err != nil {
+		klog.ErrorS(err, "Failed to get StatefulMigration CR", "backupID", backupID)
+		common.Fail(c, err)
+		return
+	}
+	if !dryRun {
+		if status, err := authorizePrune(c, sm); err != nil {
+			common.FailWithStatus(c, err, status)
+			return
+		}
+	}
+
+	policy := retentionFromSpec(sm)
+	if dryRun && len(c.Request.URL.Query()) > 0 {
+		policy = &RetentionPolicy{}
+		if err := c.ShouldBindQuery(policy); err != nil {
+			common.Fail(c, err)
+			return
+		}
+	}
+	if policy == nil || policy.isEmpty() {
+		common.Fail(c, fmt.Errorf("backup %s has no retention policy", backupID))
+		return
+	}
+
+	report, err := pruneBackup(c, sm, *policy, dryRun)
+	if err != nil {
+		klog.ErrorS(err, "Failed to prune backup", "backupID", backupID)
+		common.Fail(c, err)
+		return
+	}
+	common.Success(c, report)
+}
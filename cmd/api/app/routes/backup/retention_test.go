@@ -0,0 +1,222 @@
+/*
+Copyright 2024 The Karmada Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestPlanRetention(t *testing.T) {
+	now := time.Date(2024, 6, 30, 12, 0, 0, 0, time.UTC)
+	// Two successful executions a day for ten days, the newest first
+	executions := []BackupExecution{}
+	for day := 0; day < 10; day++ {
+		for _, hour := range []int{6, 0} {
+			executions = append(executions, BackupExecution{
+				ID:        fmt.Sprintf("d%d-h%d", day, hour),
+				Timestamp: now.Add(-time.Hour - time.Duration(day)*24*time.Hour - time.Duration(hour)*time.Hour),
+				Status:    "Completed",
+			})
+		}
+	}
+	failed := BackupExecution{ID: "failed", Timestamp: now.Add(-30 * time.Minute), Status: "Failed"}
+	oldFailed := BackupExecution{ID: "old-failed", Timestamp: now.Add(-50 * time.Hour), Status: "Failed"}
+	running := BackupExecution{ID: "running", Timestamp: now.Add(-40 * 24 * time.Hour), Status: "Running"}
+
+	cases := []struct {
+		name       string
+		policy     RetentionPolicy
+		executions []BackupExecution
+		kept       []string
+	}{
+		{
+			name:       "empty policy keeps everything",
+			policy:     RetentionPolicy{},
+			executions: executions[:3],
+			kept:       []string{"d0-h0", "d0-h6", "d1-h6"},
+		},
+		{
+			name:       "keep last",
+			policy:     RetentionPolicy{KeepLast: 3},
+			executions: executions,
+			kept:       []string{"d0-h0", "d0-h6", "d1-h0"},
+		},
+		{
+			name:       "keep daily keeps the newest of each day",
+			policy:     RetentionPolicy{KeepDaily: 3},
+			executions: executions,
+			kept:       []string{"d0-h0", "d1-h0", "d2-h0"},
+		},
+		{
+			name:       "rules are combined",
+			policy:     RetentionPolicy{KeepLast: 2, KeepDaily: 2},
+			executions: executions,
+			kept:       []string{"d0-h0", "d0-h6", "d1-h0"},
+		},
+		{
+			name:       "max age alone keeps younger executions",
+			policy:     RetentionPolicy{MaxAgeDays: 2},
+			executions: executions,
+			kept:       []string{"d0-h0", "d0-h6", "d1-h0", "d1-h6"},
+		},
+		{
+			name:       "max age overrides keep rules",
+			policy:     RetentionPolicy{KeepLast: 10, MaxAgeDays: 1},
+			executions: executions,
+			kept:       []string{"d0-h0", "d0-h6"},
+		},
+		{
+			name:       "newest successful execution is always kept",
+			policy:     RetentionPolicy{MaxAgeDays: 1},
+			executions: executions[10:],
+			kept:       []string{"d5-h0"},
+		},
+		{
+			name:       "failed executions are kept until a later one succeeds",
+			policy:     RetentionPolicy{KeepLast: 1},
+			executions: append([]BackupExecution{failed, oldFailed, running}, executions...),
+			kept:       []string{"d0-h0", "failed", "running"},
+		},
+	}
+
+	for _, c := range cases {
+		kept, pruned := planRetention(c.policy, c.executions, now)
+		ids := []string{}
+		for _, execution := range kept {
+			ids = append(ids, execution.ID)
+		}
+		sort.Strings(ids)
+		if !reflect.DeepEqual(ids, c.kept) {
+			t.Errorf("%s: kept %v, expected %v", c.name, ids, c.kept)
+		}
+		if len(kept)+len(pruned) != len(c.executions) {
+			t.Errorf("%s: kept %d and pruned %d of %d executions", c.name, len(kept), len(pruned), len(c.executions))
+		}
+	}
+}
+
+func TestRetentionPolicySpec(t *testing.T) {
+	cases := []RetentionPolicy{
+		{KeepLast: 7},
+		{KeepDaily: 7, KeepWeekly: 4, KeepMonthly: 12, MaxAgeDays: 365},
+	}
+
+	for _, policy := range cases {
+		spec := map[string]interface{}{}
+		policy.toSpec(spec)
+		got := retentionFromSpec(&unstructured.Unstructured{Object: map[string]interface{}{"spec": spec}})
+		if got == nil || *got != policy {
+			t.Errorf("retentionFromSpec(toSpec(%+v)) == %+v", policy, got)
+		}
+	}
+
+	spec := map[string]interface{}{"retention": map[string]interface{}{"keepLast": int64(1)}}
+	RetentionPolicy{}.toSpec(spec)
+	if _, found := spec["retention"]; found {
+		t.Errorf("an empty policy was not removed from the spec")
+	}
+}
+
+func TestParseImageReference(t *testing.T) {
+	cases := []struct {
+		reference  string
+		repository string
+		tag        string
+		valid      bool
+	}{
+		{"registry.example.com/backups/web:20240630", "backups/web", "20240630", true},
+		{"localhost:5000/web:v1", "web", "v1", true},
+		{"https://harbor.example.com/project/db:checkpoint-1", "project/db", "checkpoint-1", true},
+		{"backups/web@sha256:abcdef", "backups/web", "sha256:abcdef", true},
+		{"registry.example.com:5000/backups/web", "", "", false},
+	}
+
+	for _, c := range cases {
+		repository, tag, err := parseImageReference(c.reference)
+		if (err == nil) != c.valid || repository != c.repository || tag != c.tag {
+			t.Errorf("parseImageReference(%q) == %q, %q, %v, expected %q, %q, valid %v", c.reference, repository, tag, err, c.repository, c.tag, c.valid)
+		}
+	}
+}
+
+func TestParseAuthChallenge(t *testing.T) {
+	got := parseAuthChallenge(`Bearer realm="https://auth.example.com/token",service="registry.example.com",scope="repository:backups/web:pull,delete"`)
+	expected := map[string]string{
+		"realm":   "https://auth.example.com/token",
+		"service": "registry.example.com",
+		"scope":   "repository:backups/web:pull,delete",
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("parseAuthChallenge() == %v, expected %v", got, expected)
+	}
+}
+
+func TestDeleteCheckpointImage(t *testing.T) {
+	// Runs that changed nothing push the same image under a new tag
+	tags := map[string]string{"20240101": "sha256:aaa", "20240102": "sha256:bbb", "20240103": "sha256:bbb"}
+	var deleted []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reference := strings.TrimPrefix(r.URL.Path, "/v2/backups/web/manifests/")
+		switch r.Method {
+		case http.MethodHead:
+			digest, found := tags[reference]
+			if !found {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Header().Set("Docker-Content-Digest", digest)
+		case http.MethodDelete:
+			deleted = append(deleted, reference)
+			for tag, digest := range tags {
+				if digest == reference {
+					delete(tags, tag)
+				}
+			}
+			w.WriteHeader(http.StatusAccepted)
+		}
+	}))
+	defer server.Close()
+	registry := newRegistryClient(RegistryCredentials{Registry: server.URL})
+
+	kept := []BackupExecution{{ID: "e3", CheckpointPath: "registry.example.com/backups/web:20240103"}}
+	keptImages, err := checkpointImages(context.TODO(), registry, kept)
+	if err != nil {
+		t.Fatalf("checkpointImages: %v", err)
+	}
+	for _, path := range []string{"registry.example.com/backups/web:20240101", "registry.example.com/backups/web:20240102", "registry.example.com/backups/web:20231231"} {
+		if err := deleteCheckpointImage(context.TODO(), registry, path, keptImages); err != nil {
+			t.Errorf("deleteCheckpointImage(%q): %v", path, err)
+		}
+	}
+
+	if !reflect.DeepEqual(deleted, []string{"sha256:aaa"}) {
+		t.Errorf("deleted manifests %v, expected only sha256:aaa", deleted)
+	}
+	if tags["20240103"] != "sha256:bbb" {
+		t.Errorf("the image of the kept execution was deleted")
+	}
+}
@@ -0,0 +1,161 @@
+/*
+Copyright 2024 The Karmada Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronField is the set of values a field of a cron expression matches
+type cronField map[int]bool
+
+// cronSchedule is a parsed five field cron expression, evaluated in UTC like the migration
+// controller does.
+type cronSchedule struct {
+	minute, hour, dayOfMonth, month, dayOfWeek cronField
+	// anyDayOfMonth and anyDayOfWeek record a "*" field, since a day matches when either
+	// restricted day field matches
+	anyDayOfMonth, anyDayOfWeek bool
+}
+
+var (
+	cronMonthNames = map[string]int{"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12}
+	cronDayNames = map[string]int{"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6}
+)
+
+// parseCronSchedule parses an expression like "*/15 2-6 * * mon-fri". Fields support "*",
+// values, ranges, steps and lists, and month and weekday names.
+func parseCronSchedule(expression string) (*cronSchedule, error) {
+	fields := strings.Fields(expression)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron expression must have 5 fields")
+	}
+
+	schedule := &cronSchedule{anyDayOfMonth: fields[2] == "*", anyDayOfWeek: fields[4] == "*"}
+	var err error
+	if schedule.minute, err = parseCronField(fields[0], 0, 59, nil); err != nil {
+		return nil, fmt.Errorf("invalid minute field: %v", err)
+	}
+	if schedule.hour, err = parseCronField(fields[1], 0, 23, nil); err != nil {
+		return nil, fmt.Errorf("invalid hour field: %v", err)
+	}
+	if schedule.dayOfMonth, err = parseCronField(fields[2], 1, 31, nil); err != nil {
+		return nil, fmt.Errorf("invalid day of month field: %v", err)
+	}
+	if schedule.month, err = parseCronField(fields[3], 1, 12, cronMonthNames); err != nil {
+		return nil, fmt.Errorf("invalid month field: %v", err)
+	}
+	// 7 is Sunday as well
+	if schedule.dayOfWeek, err = parseCronField(fields[4], 0, 7, cronDayNames); err != nil {
+		return nil, fmt.Errorf("invalid day of week field: %v", err)
+	}
+	if schedule.dayOfWeek[7] {
+		schedule.dayOfWeek[0] = true
+	}
+	return schedule, nil
+}
+
+// parseCronField parses one field of a cron expression.
+func parseCronField(field string, min, max int, names map[string]int) (cronField, error) {
+	values := cronField{}
+	for _, part := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			var err error
+			if step, err = strconv.Atoi(stepPart); err != nil || step <= 0 {
+				return nil, fmt.Errorf("invalid step %q", stepPart)
+			}
+		}
+
+		low, high := min, max
+		if rangePart != "*" {
+			lowPart, highPart, isRange := strings.Cut(rangePart, "-")
+			var err error
+			if low, err = parseCronValue(lowPart, min, max, names); err != nil {
+				return nil, err
+			}
+			high = low
+			if isRange {
+				if high, err = parseCronValue(highPart, min, max, names); err != nil {
+					return nil, err
+				}
+			} else if hasStep {
+				high = max
+			}
+			if high < low {
+				return nil, fmt.Errorf("invalid range %q", rangePart)
+			}
+		}
+		for value := low; value <= high; value += step {
+			values[value] = true
+		}
+	}
+	return values, nil
+}
+
+// parseCronValue parses a number or name of a cron field.
+func parseCronValue(value string, min, max int, names map[string]int) (int, error) {
+	if number, ok := names[strings.ToLower(value)]; ok {
+		return number, nil
+	}
+	number, err := strconv.Atoi(value)
+	if err != nil || number < min || number > max {
+		return 0, fmt.Errorf("value %q is not between %d and %d", value, min, max)
+	}
+	return number, nil
+}
+
+// matchesDay reports whether the schedule runs on the day of t.
+func (s *cronSchedule) matchesDay(t time.Time) bool {
+	dayOfMonth, dayOfWeek := s.dayOfMonth[t.Day()], s.dayOfWeek[int(t.Weekday())]
+	switch {
+	case s.anyDayOfMonth && s.anyDayOfWeek:
+		return true
+	case s.anyDayOfMonth:
+		return dayOfWeek
+	case s.anyDayOfWeek:
+		return dayOfMonth
+	}
+	return dayOfMonth || dayOfWeek
+}
+
+// next returns the first time after t the schedule runs, or the zero time if it never runs,
+// e.g. for February 30th.
+func (s *cronSchedule) next(t time.Time) time.Time {
+	t = t.UTC().Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		switch {
+		case !s.month[int(t.Month())]:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+		case !s.matchesDay(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, time.UTC)
+		case !s.hour[t.Hour()]:
+			t = t.Truncate(time.Hour).Add(time.Hour)
+		case !s.minute[t.Minute()]:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
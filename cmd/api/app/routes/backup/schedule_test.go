@@ -0,0 +1,66 @@
+/*
+Copyright 2024 The Karmada Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCronScheduleNext(t *testing.T) {
+	// A Wednesday
+	from := time.Date(2024, 5, 15, 10, 7, 30, 0, time.UTC)
+
+	cases := []struct {
+		expression string
+		next       string
+	}{
+		{"*/15 * * * *", "2024-05-15T10:15:00Z"},
+		{"0 * * * *", "2024-05-15T11:00:00Z"},
+		{"0 0 * * *", "2024-05-16T00:00:00Z"},
+		{"30 2 * * mon-fri", "2024-05-16T02:30:00Z"},
+		{"0 9 * * 0", "2024-05-19T09:00:00Z"},
+		{"0 9 * * 7", "2024-05-19T09:00:00Z"},
+		{"0 0 1 jan *", "2025-01-01T00:00:00Z"},
+		{"0 0 13 * 5", "2024-05-17T00:00:00Z"},
+		{"5,10 10 * * *", "2024-05-15T10:10:00Z"},
+		{"0 0 30 2 *", ""},
+	}
+
+	for _, c := range cases {
+		schedule, err := parseCronSchedule(c.expression)
+		if err != nil {
+			t.Errorf("parseCronSchedule(%q) failed: %v", c.expression, err)
+			continue
+		}
+		next := ""
+		if n := schedule.next(from); !n.IsZero() {
+			next = n.Format(time.RFC3339)
+		}
+		if next != c.next {
+			t.Errorf("next(%q) == %q, expected %q", c.expression, next, c.next)
+		}
+	}
+}
+
+func TestParseCronScheduleInvalid(t *testing.T) {
+	for _, expression := range []string{"* * * *", "60 * * * *", "* 24 * * *", "*/0 * * * *", "5-1 * * * *", "* * * foo *"} {
+		if _, err := parseCronSchedule(expression); err == nil {
+			t.Errorf("parseCronSchedule(%q) succeeded", expression)
+		}
+	}
+}
@@ -0,0 +1,304 @@
+/*
+Copyright 2024 The Karmada Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+const (
+	// resourceTypeNamespace is the resource type of backups that capture a whole namespace
+	resourceTypeNamespace = "namespace"
+)
+
+// backupResourceType describes a kind that can be backed up on its own or as part of a namespace.
+type backupResourceType struct {
+	Kind       string
+	APIVersion string
+	GVR        schema.GroupVersionResource
+	// Workload is set for the kinds a single-resource backup can target
+	Workload bool
+}
+
+// backupResourceTypes are the kinds known to backups, by the resource type used in the API.
+var backupResourceTypes = map[string]backupResourceType{
+	"pod": {
+		Kind: "Pod", APIVersion: "v1", Workload: true,
+		GVR: schema.GroupVersionResource{Version: "v1", Resource: "pods"},
+	},
+	"statefulset": {
+		Kind: "StatefulSet", APIVersion: "apps/v1", Workload: true,
+		GVR: schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "statefulsets"},
+	},
+	"deployment": {
+		Kind: "Deployment", APIVersion: "apps/v1", Workload: true,
+		GVR: schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"},
+	},
+	"daemonset": {
+		Kind: "DaemonSet", APIVersion: "apps/v1", Workload: true,
+		GVR: schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "daemonsets"},
+	},
+	"configmap": {
+		Kind: "ConfigMap", APIVersion: "v1",
+		GVR: schema.GroupVersionResource{Version: "v1", Resource: "configmaps"},
+	},
+	"secret": {
+		Kind: "Secret", APIVersion: "v1",
+		GVR: schema.GroupVersionResource{Version: "v1", Resource: "secrets"},
+	},
+	"persistentvolumeclaim": {
+		Kind: "PersistentVolumeClaim", APIVersion: "v1",
+		GVR: schema.GroupVersionResource{Version: "v1", Resource: "persistentvolumeclaims"},
+	},
+	resourceTypeNamespace: {
+		Kind: "Namespace", APIVersion: "v1",
+		GVR: schema.GroupVersionResource{Version: "v1", Resource: "namespaces"},
+	},
+}
+
+// namespaceBackupResources are the resource types a namespace backup captures unless it lists
+// its own.
+var namespaceBackupResources = []string{"deployment", "statefulset", "daemonset", "pod", "configmap", "secret", "persistentvolumeclaim"}
+
+// resourceAPIVersion returns the apiVersion of a backup resource type.
+func resourceAPIVersion(resourceType string) string {
+	if t, ok := backupResourceTypes[strings.ToLower(resourceType)]; ok {
+		return t.APIVersion
+	}
+	return "v1" // Default fallback
+}
+
+// BackupScope selects what a namespace backup captures.
+type BackupScope struct {
+	// IncludedResources are resource types, e.g. deployment or configmap. All of
+	// namespaceBackupResources when empty.
+	IncludedResources []string `json:"includedResources,omitempty"`
+	// LabelSelector limits the backup to matching objects, in kubectl syntax like app=web
+	LabelSelector string `json:"labelSelector,omitempty"`
+	// ExcludeLabelSelector skips matching objects, e.g. backup.dcnlab.com/exclude=true
+	ExcludeLabelSelector string `json:"excludeLabelSelector,omitempty"`
+}
+
+// validate normalizes the scope and checks its selectors.
+func (s *BackupScope) validate() error {
+	included := []string{}
+	for _, resourceType := range s.IncludedResources {
+		resourceType = strings.ToLower(strings.TrimSpace(resourceType))
+		if resourceType == resourceTypeNamespace {
+			return fmt.Errorf("namespaces cannot be included in a namespace backup")
+		}
+		if _, ok := backupResourceTypes[resourceType]; !ok {
+			return fmt.Errorf("unsupported resource type %q, expected one of %s", resourceType, strings.Join(namespaceBackupResources, ", "))
+		}
+		included = append(included, resourceType)
+	}
+	sort.Strings(included)
+	s.IncludedResources = included
+
+	// Selectors must be representable as metav1.LabelSelector, which rules out != for example
+	if _, err := metav1.ParseToLabelSelector(s.LabelSelector); err != nil {
+		return fmt.Errorf("invalid label selector: %v", err)
+	}
+	if _, err := metav1.ParseToLabelSelector(s.ExcludeLabelSelector); err != nil {
+		return fmt.Errorf("invalid exclude label selector: %v", err)
+	}
+	return nil
+}
+
+// validateBackupTarget checks what a backup targets. Namespace backups are named after their
+// namespace and are the only ones that take a scope.
+func validateBackupTarget(resourceType, resourceName, namespace string, scope *BackupScope) error {
+	resourceType = strings.ToLower(resourceType)
+	t, ok := backupResourceTypes[resourceType]
+	if !ok || (!t.Workload && resourceType != resourceTypeNamespace) {
+		return fmt.Errorf("unsupported resource type: %s", resourceType)
+	}
+	if resourceType != resourceTypeNamespace {
+		if scope != nil {
+			return fmt.Errorf("a scope can only be set for namespace backups")
+		}
+		return nil
+	}
+	if resourceName != "" && resourceName != namespace {
+		return fmt.Errorf("the resource name of a namespace backup must be its namespace %s", namespace)
+	}
+	if scope == nil {
+		return nil
+	}
+	return scope.validate()
+}
+
+// resources returns the resource types the scope captures.
+func (s BackupScope) resources() []string {
+	if len(s.IncludedResources) == 0 {
+		return namespaceBackupResources
+	}
+	return s.IncludedResources
+}
+
+// matches reports whether an object with the given labels is captured.
+func (s BackupScope) matches(objectLabels map[string]string) bool {
+	set := labels.Set(objectLabels)
+	if s.LabelSelector != "" {
+		if selector, err := labels.Parse(s.LabelSelector); err != nil || !selector.Matches(set) {
+			return false
+		}
+	}
+	if s.ExcludeLabelSelector != "" {
+		if selector, err := labels.Parse(s.ExcludeLabelSelector); err != nil || selector.Matches(set) {
+			return false
+		}
+	}
+	return true
+}
+
+// toSpec stores the scope in a StatefulMigration spec. Selectors are stored as
+// metav1.LabelSelector, which is what controllers consume.
+func (s BackupScope) toSpec(spec map[string]interface{}) error {
+	delete(spec, "includedResources")
+	delete(spec, "labelSelector")
+	delete(spec, "excludeLabelSelector")
+	if len(s.IncludedResources) > 0 {
+		resources := make([]interface{}, 0, len(s.IncludedResources))
+		for _, resourceType := range s.IncludedResources {
+			resources = append(resources, resourceType)
+		}
+		spec["includedResources"] = resources
+	}
+	for field, value := range map[string]string{"labelSelector": s.LabelSelector, "excludeLabelSelector": s.ExcludeLabelSelector} {
+		if value == "" {
+			continue
+		}
+		selector, err := metav1.ParseToLabelSelector(value)
+		if err != nil {
+			return fmt.Errorf("invalid %s: %v", field, err)
+		}
+		converted, err := runtime.DefaultUnstructuredConverter.ToUnstructured(selector)
+		if err != nil {
+			return fmt.Errorf("failed to convert %s: %v", field, err)
+		}
+		spec[field] = converted
+	}
+	return nil
+}
+
+// backupScopeFromSpec reads the scope stored by toSpec.
+func backupScopeFromSpec(sm *unstructured.Unstructured) BackupScope {
+	scope := BackupScope{}
+	if resources, found, _ := unstructured.NestedStringSlice(sm.Object, "spec", "includedResources"); found {
+		scope.IncludedResources = resources
+	}
+	for field, target := range map[string]*string{"labelSelector": &scope.LabelSelector, "excludeLabelSelector": &scope.ExcludeLabelSelector} {
+		raw, found, _ := unstructured.NestedMap(sm.Object, "spec", field)
+		if !found {
+			continue
+		}
+		selector := &metav1.LabelSelector{}
+		if err := convertUnstructuredToTyped(&unstructured.Unstructured{Object: raw}, selector); err == nil {
+			*target = metav1.FormatLabelSelector(selector)
+		}
+	}
+	return scope
+}
+
+// getNamespaceBackupContents lists the objects a namespace backup with the given scope captures.
+func getNamespaceBackupContents(dynamicClient dynamic.Interface, namespace string, scope BackupScope) ([]map[string]interface{}, error) {
+	resources := []map[string]interface{}{}
+	for _, resourceType := range scope.resources() {
+		t := backupResourceTypes[resourceType]
+		list, err := dynamicClient.Resource(t.GVR).Namespace(namespace).List(context.TODO(), metav1.ListOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s: %v", t.GVR.Resource, err)
+		}
+		for i := range list.Items {
+			item := &list.Items[i]
+			if !scope.matches(item.GetLabels()) {
+				continue
+			}
+			resources = append(resources, summarizeBackupResource(item, t))
+		}
+	}
+	return resources, nil
+}
+
+// getResourcesOfType lists the objects of a backup resource type for the resource picker.
+func getResourcesOfType(dynamicClient dynamic.Interface, resourceType, namespace, labelSelector string) ([]map[string]interface{}, error) {
+	t, ok := backupResourceTypes[resourceType]
+	if !ok {
+		return nil, fmt.Errorf("unsupported resource type: %s", resourceType)
+	}
+	opts := metav1.ListOptions{LabelSelector: labelSelector}
+
+	var list *unstructured.UnstructuredList
+	var err error
+	if namespace != "" && resourceType != resourceTypeNamespace {
+		list, err = dynamicClient.Resource(t.GVR).Namespace(namespace).List(context.TODO(), opts)
+	} else {
+		list, err = dynamicClient.Resource(t.GVR).List(context.TODO(), opts)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s: %v", t.GVR.Resource, err)
+	}
+
+	resources := make([]map[string]interface{}, 0, len(list.Items))
+	for i := range list.Items {
+		resources = append(resources, summarizeBackupResource(&list.Items[i], t))
+	}
+	return resources, nil
+}
+
+// summarizeBackupResource describes an object for the resource picker.
+func summarizeBackupResource(item *unstructured.Unstructured, t backupResourceType) map[string]interface{} {
+	resource := map[string]interface{}{
+		"name":       item.GetName(),
+		"namespace":  item.GetNamespace(),
+		"kind":       t.Kind,
+		"apiVersion": t.APIVersion,
+	}
+
+	switch t.Kind {
+	case "Pod", "Namespace", "PersistentVolumeClaim":
+		if status, found, _ := unstructured.NestedString(item.Object, "status", "phase"); found {
+			resource["status"] = status
+		}
+	case "DaemonSet":
+		if replicas, found, _ := unstructured.NestedInt64(item.Object, "status", "desiredNumberScheduled"); found {
+			resource["replicas"] = replicas
+		}
+		if readyReplicas, found, _ := unstructured.NestedInt64(item.Object, "status", "numberReady"); found {
+			resource["readyReplicas"] = readyReplicas
+		}
+	case "Deployment", "StatefulSet":
+		if replicas, found, _ := unstructured.NestedInt64(item.Object, "status", "replicas"); found {
+			resource["replicas"] = replicas
+		}
+		if readyReplicas, found, _ := unstructured.NestedInt64(item.Object, "status", "readyReplicas"); found {
+			resource["readyReplicas"] = readyReplicas
+		}
+	}
+	return resource
+}
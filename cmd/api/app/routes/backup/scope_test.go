@@ -0,0 +1,83 @@
+/*
+Copyright 2024 The Karmada Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestValidateBackupTarget(t *testing.T) {
+	cases := []struct {
+		resourceType string
+		resourceName string
+		namespace    string
+		scope        *BackupScope
+		valid        bool
+	}{
+		{"deployment", "web", "default", nil, true},
+		{"daemonset", "agent", "kube-system", nil, true},
+		{"configmap", "settings", "default", nil, false},
+		{"statefulset", "db", "default", &BackupScope{LabelSelector: "app=db"}, false},
+		{"namespace", "", "shop", nil, true},
+		{"namespace", "other", "shop", nil, false},
+		{"namespace", "shop", "shop", &BackupScope{IncludedResources: []string{"Deployment", "secret"}, LabelSelector: "tier in (web,api)", ExcludeLabelSelector: "backup=skip"}, true},
+		{"namespace", "", "shop", &BackupScope{IncludedResources: []string{"namespace"}}, false},
+		{"namespace", "", "shop", &BackupScope{IncludedResources: []string{"ingress"}}, false},
+		{"namespace", "", "shop", &BackupScope{LabelSelector: "app in web"}, false},
+		{"namespace", "", "shop", &BackupScope{ExcludeLabelSelector: "tier!=cache"}, false},
+	}
+
+	for _, c := range cases {
+		err := validateBackupTarget(c.resourceType, c.resourceName, c.namespace, c.scope)
+		if (err == nil) != c.valid {
+			t.Errorf("validateBackupTarget(%q, %q, %q, %+v) == %v, expected valid %v", c.resourceType, c.resourceName, c.namespace, c.scope, err, c.valid)
+		}
+	}
+}
+
+func TestBackupScopeSpec(t *testing.T) {
+	cases := []BackupScope{
+		{},
+		{IncludedResources: []string{"configmap", "deployment"}},
+		{LabelSelector: "app=web,tier notin (cache)", ExcludeLabelSelector: "backup.dcnlab.com/exclude"},
+	}
+
+	for _, scope := range cases {
+		spec := map[string]interface{}{"labelSelector": "stale"}
+		if err := scope.toSpec(spec); err != nil {
+			t.Fatalf("toSpec(%+v) failed: %v", scope, err)
+		}
+		got := backupScopeFromSpec(&unstructured.Unstructured{Object: map[string]interface{}{"spec": spec}})
+		if !reflect.DeepEqual(got, scope) {
+			t.Errorf("backupScopeFromSpec(toSpec(%+v)) == %+v", scope, got)
+		}
+	}
+
+	scope := BackupScope{LabelSelector: "app=web", ExcludeLabelSelector: "backup=skip"}
+	if !scope.matches(map[string]string{"app": "web"}) {
+		t.Errorf("matches() rejected a selected object")
+	}
+	if scope.matches(map[string]string{"app": "web", "backup": "skip"}) {
+		t.Errorf("matches() accepted an excluded object")
+	}
+	if scope.matches(map[string]string{"app": "db"}) {
+		t.Errorf("matches() accepted an object outside the selector")
+	}
+}
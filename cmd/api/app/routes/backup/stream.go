@@ -0,0 +1,579 @@
+/*
+Copyright 2024 The Karmada Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	clusterv1alpha1 "github.com/karmada-io/karmada/pkg/apis/cluster/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+
+	"github.com/karmada-io/dashboard/cmd/api/app/router"
+	"github.com/karmada-io/dashboard/cmd/api/app/types/common"
+	"github.com/karmada-io/dashboard/pkg/auth"
+	"github.com/karmada-io/dashboard/pkg/auth/fga"
+	"github.com/karmada-io/dashboard/pkg/client"
+)
+
+// Kinds of progress events
+const (
+	progressKindBackup            = "backup"
+	progressKindBackupExecution   = "backupExecution"
+	progressKindRecovery          = "recovery"
+	progressKindCheckpointBackup  = "checkpointBackup"
+	progressKindCheckpointRestore = "checkpointRestore"
+)
+
+const (
+	// progressHeartbeatInterval keeps idle streams from being closed by proxies
+	progressHeartbeatInterval = 15 * time.Second
+	// progressClusterResync is how often member clusters are checked for informers to start or stop
+	progressClusterResync = time.Minute
+	// progressSubscriberBuffer is how many events a slow client can fall behind before it is
+	// disconnected, it gets a fresh snapshot when it reconnects
+	progressSubscriberBuffer = 256
+	// progressAccessRefresh is how often the cluster access of subscribers is checked again, so
+	// that revoked grants stop the events of a cluster
+	progressAccessRefresh = time.Minute
+)
+
+var (
+	checkpointBackupGVR  = schema.GroupVersionResource{Group: "migration.dcnlab.com", Version: "v1", Resource: "checkpointbackups"}
+	checkpointRestoreGVR = schema.GroupVersionResource{Group: "migration.dcnlab.com", Version: "v1", Resource: "checkpointrestores"}
+)
+
+// ProgressEvent reports a change of a backup or recovery object.
+type ProgressEvent struct {
+	Kind   string `json:"kind"`   // "backup", "backupExecution", "recovery", "checkpointBackup" or "checkpointRestore"
+	Action string `json:"action"` // "snapshot", "added", "updated" or "deleted"
+	// Cluster is the cluster the object is about: the source of a backup, the target of a
+	// recovery, or the member cluster a checkpoint object lives in
+	Cluster    string      `json:"cluster,omitempty"`
+	Namespace  string      `json:"namespace"`
+	Name       string      `json:"name"`
+	BackupID   string      `json:"backupId,omitempty"`
+	RecoveryID string      `json:"recoveryId,omitempty"`
+	Phase      string      `json:"phase,omitempty"`
+	Progress   int64       `json:"progress,omitempty"`
+	Size       string      `json:"size,omitempty"`
+	Error      string      `json:"error,omitempty"`
+	Timestamp  time.Time   `json:"timestamp"`
+	Object     interface{} `json:"object,omitempty"`
+}
+
+// sameProgress reports whether two events of the same object show the same progress, so that
+// updates of unrelated fields are not pushed.
+func (e ProgressEvent) sameProgress(other ProgressEvent) bool {
+	return e.Phase == other.Phase && e.Progress == other.Progress && e.Size == other.Size && e.Error == other.Error
+}
+
+// nestedString returns the first of several string fields that is set.
+func nestedString(obj map[string]interface{}, paths ...[]string) string {
+	for _, path := range paths {
+		if value, found, _ := unstructured.NestedString(obj, path...); found && value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// nestedNumber reads a number field, whether JSON decoding made it an int64 or a float64.
+func nestedNumber(obj map[string]interface{}, path ...string) int64 {
+	value, found, _ := unstructured.NestedFieldNoCopy(obj, path...)
+	if !found {
+		return 0
+	}
+	switch number := value.(type) {
+	case int64:
+		return number
+	case float64:
+		return int64(number)
+	}
+	return 0
+}
+
+// toProgressEvent summarizes an object for the progress stream.
+func toProgressEvent(kind, cluster string, obj *unstructured.Unstructured) ProgressEvent {
+	event := ProgressEvent{
+		Kind:       kind,
+		Cluster:    cluster,
+		Namespace:  obj.GetNamespace(),
+		Name:       obj.GetName(),
+		BackupID:   obj.GetLabels()["backup-id"],
+		RecoveryID: obj.GetLabels()["recovery-id"],
+		Timestamp:  time.Now(),
+	}
+
+	if kind == progressKindBackupExecution {
+		data, _, _ := unstructured.NestedStringMap(obj.Object, "data")
+		event.Phase, event.Size, event.Error = data["status"], data["size"], data["error"]
+		event.Object = configMapToBackupHistory(obj)
+		return event
+	}
+
+	event.Phase = nestedString(obj.Object, []string{"status", "phase"}, []string{"spec", "phase"})
+	event.Progress = nestedNumber(obj.Object, "status", "progress")
+	event.Size = nestedString(obj.Object, []string{"status", "size"}, []string{"status", "checkpointSize"})
+	event.Error = nestedString(obj.Object, []string{"status", "error"})
+	if event.Error == "" && strings.EqualFold(event.Phase, "failed") {
+		event.Error = nestedString(obj.Object, []string{"status", "message"})
+	}
+
+	switch kind {
+	case progressKindBackup:
+		if clusters, found, _ := unstructured.NestedStringSlice(obj.Object, "spec", "sourceClusters"); found && len(clusters) > 0 {
+			event.Cluster = clusters[0]
+		}
+	case progressKindRecovery:
+		recovery := statefulMigrationToRecovery(obj)
+		event.Cluster = recovery.TargetCluster
+		event.Object = recovery
+	case progressKindCheckpointRestore:
+		event.Object = convertCheckpointRestoreToEvent(obj, cluster)
+	}
+	return event
+}
+
+// progressSubscriber is a client of the progress stream.
+type progressSubscriber struct {
+	// ctx is the context of the stream request, which carries the caller for access checks
+	ctx        context.Context
+	username   string
+	kinds      map[string]bool
+	cluster    string
+	backupID   string
+	recoveryID string
+	events     chan ProgressEvent
+
+	// mu guards the access cache. Access is resolved outside of the hub lock, so that OpenFGA
+	// calls never hold up the informers.
+	mu sync.Mutex
+	// admin is whether the subscriber may see events that are not about a cluster
+	admin bool
+	// access caches whether the subscriber may see each cluster
+	access map[string]bool
+}
+
+// matches reports whether the subscriber asked for an event.
+func (s *progressSubscriber) matches(event ProgressEvent) bool {
+	if len(s.kinds) > 0 && !s.kinds[event.Kind] {
+		return false
+	}
+	if s.cluster != "" && event.Cluster != s.cluster {
+		return false
+	}
+	if s.backupID != "" && event.BackupID != s.backupID {
+		return false
+	}
+	if s.recoveryID != "" && event.RecoveryID != s.recoveryID {
+		return false
+	}
+	return true
+}
+
+// canSee reports whether the subscriber may see an event, from the access cache. Events that are
+// not about a cluster are shown to dashboard admins only. Clusters that are not cached yet are
+// hidden and checked in the background.
+func (s *progressSubscriber) canSee(event ProgressEvent) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if event.Cluster == "" {
+		return s.admin
+	}
+	allowed, checked := s.access[event.Cluster]
+	if !checked {
+		// Checked once until the next refresh
+		s.access[event.Cluster] = false
+		go s.resolveAccess([]string{event.Cluster})
+	}
+	return allowed
+}
+
+// resolveAccess checks whether the subscriber is a dashboard admin and which of clusters it may
+// access, and caches the results. It calls OpenFGA, so it must not be called with the hub lock
+// held. Without OpenFGA everything is allowed, like client.CheckClusterAccess does.
+func (s *progressSubscriber) resolveAccess(clusters []string) {
+	admin := true
+	if fga.FGAService != nil && fga.FGAService.GetClient() != nil {
+		var err error
+		if admin, err = fga.IsDashboardAdmin(s.ctx, fga.FGAService.GetClient(), s.username); err != nil {
+			klog.ErrorS(err, "Failed to check dashboard admin for progress streaming", "username", s.username)
+		}
+	}
+	access := make(map[string]bool, len(clusters))
+	for _, cluster := range clusters {
+		access[cluster] = client.CheckClusterAccess(s.ctx, s.username, cluster) == nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.admin = admin
+	for cluster, allowed := range access {
+		s.access[cluster] = allowed
+	}
+}
+
+// refreshAccess resolves the access of the subscriber to every member cluster, and again every
+// progressAccessRefresh until the stream ends.
+func (s *progressSubscriber) refreshAccess() {
+	ticker := time.NewTicker(progressAccessRefresh)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+			s.resolveAccess(memberClusterNames(s.ctx))
+		}
+	}
+}
+
+// memberClusterNames returns the names of the member clusters, none if they cannot be listed.
+func memberClusterNames(ctx context.Context) []string {
+	clusters, err := client.InClusterKarmadaClient().ClusterV1alpha1().Clusters().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		klog.ErrorS(err, "Failed to list member clusters for progress streaming")
+		return nil
+	}
+	names := make([]string, 0, len(clusters.Items))
+	for _, cluster := range clusters.Items {
+		names = append(names, cluster.Name)
+	}
+	return names
+}
+
+// progressInformer is an informer of the progress stream with the kind and cluster of its objects.
+type progressInformer struct {
+	kind     string
+	cluster  string
+	informer cache.SharedIndexInformer
+}
+
+// progressHub watches backup and recovery objects in the management and member clusters with
+// shared informers, and fans changes out to subscribers. Informers are started with the first
+// subscriber and keep running afterwards.
+type progressHub struct {
+	mu          sync.Mutex
+	started     bool
+	subscribers map[*progressSubscriber]struct{}
+	informers   []progressInformer
+	// members has a cancel function per member cluster with running informers
+	members map[string]context.CancelFunc
+}
+
+var hub = &progressHub{
+	subscribers: map[*progressSubscriber]struct{}{},
+	members:     map[string]context.CancelFunc{},
+}
+
+// ensureStarted starts the informers of the management cluster and the loop that manages those
+// of the member clusters.
+func (h *progressHub) ensureStarted() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.started {
+		return nil
+	}
+
+	dynamicClient, err := client.GetDynamicClient()
+	if err != nil {
+		return fmt.Errorf("failed to get dynamic client: %v", err)
+	}
+	ctx := context.Background()
+	factory := dynamicinformer.NewFilteredDynamicSharedInformerFactory(dynamicClient, 0, metav1.NamespaceAll, nil)
+	h.watch(factory, statefulMigrationGVR, progressKindBackup, "")
+	h.watch(factory, recoveryStatefulMigrationGVR, progressKindRecovery, "")
+	historyFactory := dynamicinformer.NewFilteredDynamicSharedInformerFactory(dynamicClient, 0, backupHistoryNamespace,
+		func(options *metav1.ListOptions) { options.LabelSelector = "app=backup-history" })
+	h.watch(historyFactory, configMapGVR, progressKindBackupExecution, "")
+	factory.Start(ctx.Done())
+	historyFactory.Start(ctx.Done())
+
+	go func() {
+		for {
+			h.syncMemberInformers(ctx)
+			time.Sleep(progressClusterResync)
+		}
+	}()
+	h.started = true
+	return nil
+}
+
+// syncMemberInformers starts informers for ready member clusters and stops those of clusters
+// that are gone or not ready.
+func (h *progressHub) syncMemberInformers(ctx context.Context) {
+	clusters, err := client.InClusterKarmadaClient().ClusterV1alpha1().Clusters().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		klog.ErrorS(err, "Failed to list member clusters for progress streaming")
+		return
+	}
+
+	ready := map[string]bool{}
+	for _, cluster := range clusters.Items {
+		for _, condition := range cluster.Status.Conditions {
+			if condition.Type == clusterv1alpha1.ClusterConditionReady && condition.Status == metav1.ConditionTrue {
+				ready[cluster.Name] = true
+			}
+		}
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for name, cancel := range h.members {
+		if !ready[name] {
+			cancel()
+			delete(h.members, name)
+			h.removeInformers(name)
+		}
+	}
+	for name := range ready {
+		if _, running := h.members[name]; running {
+			continue
+		}
+		// Informers run as the dashboard, subscribers only get events of clusters they can access
+		memberClient, err := client.GetDynamicClientForMemberAsUser(ctx, "", name)
+		if err != nil {
+			klog.ErrorS(err, "Failed to get dynamic client for progress streaming", "cluster", name)
+			continue
+		}
+		h.startMemberInformers(memberClient, name)
+	}
+}
+
+// startMemberInformers starts the checkpoint informers of a member cluster. Called with the lock held.
+func (h *progressHub) startMemberInformers(memberClient dynamic.Interface, cluster string) {
+	memberCtx, cancel := context.WithCancel(context.Background())
+	factory := dynamicinformer.NewDynamicSharedInformerFactory(memberClient, 0)
+	h.watch(factory, checkpointBackupGVR, progressKindCheckpointBackup, cluster)
+	h.watch(factory, checkpointRestoreGVR, progressKindCheckpointRestore, cluster)
+	factory.Start(memberCtx.Done())
+	h.members[cluster] = cancel
+}
+
+// removeInformers forgets the informers of a member cluster. Called with the lock held.
+func (h *progressHub) removeInformers(cluster string) {
+	kept := h.informers[:0]
+	for _, informer := range h.informers {
+		if informer.cluster != cluster {
+			kept = append(kept, informer)
+		}
+	}
+	h.informers = kept
+}
+
+// watch registers an informer whose changes are broadcast. Called with the lock held.
+func (h *progressHub) watch(factory dynamicinformer.DynamicSharedInformerFactory, gvr schema.GroupVersionResource, kind, cluster string) {
+	informer := factory.ForResource(gvr).Informer()
+	_, err := informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			if u, ok := obj.(*unstructured.Unstructured); ok {
+				h.broadcast("added", toProgressEvent(kind, cluster, u))
+			}
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			oldU, ok := oldObj.(*unstructured.Unstructured)
+			newU, ok2 := newObj.(*unstructured.Unstructured)
+			if !ok || !ok2 {
+				return
+			}
+			event := toProgressEvent(kind, cluster, newU)
+			if event.sameProgress(toProgressEvent(kind, cluster, oldU)) {
+				return
+			}
+			h.broadcast("updated", event)
+		},
+		DeleteFunc: func(obj interface{}) {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			if u, ok := obj.(*unstructured.Unstructured); ok {
+				h.broadcast("deleted", toProgressEvent(kind, cluster, u))
+			}
+		},
+	})
+	if err != nil {
+		klog.ErrorS(err, "Failed to add progress event handler", "resource", gvr.String(), "cluster", cluster)
+		return
+	}
+	h.informers = append(h.informers, progressInformer{kind: kind, cluster: cluster, informer: informer})
+}
+
+// backupCluster returns the source cluster of the backup with backupID, empty if it is not known.
+// Called with the lock held.
+func (h *progressHub) backupCluster(backupID string) string {
+	if backupID == "" {
+		return ""
+	}
+	for _, informer := range h.informers {
+		if informer.kind != progressKindBackup {
+			continue
+		}
+		for _, obj := range informer.informer.GetStore().List() {
+			if u, ok := obj.(*unstructured.Unstructured); ok && u.GetLabels()["backup-id"] == backupID {
+				return toProgressEvent(progressKindBackup, "", u).Cluster
+			}
+		}
+	}
+	return ""
+}
+
+// withCluster fills in the cluster of backup executions, which is the source cluster of their
+// backup, so that they are only shown to those who may see it. Called with the lock held.
+func (h *progressHub) withCluster(event ProgressEvent) ProgressEvent {
+	if event.Cluster == "" && event.Kind == progressKindBackupExecution {
+		event.Cluster = h.backupCluster(event.BackupID)
+	}
+	return event
+}
+
+// broadcast sends an event to the subscribers that asked for it. Subscribers that fall too far
+// behind are disconnected rather than slowing down the informers.
+func (h *progressHub) broadcast(action string, event ProgressEvent) {
+	event.Action = action
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	event = h.withCluster(event)
+	for subscriber := range h.subscribers {
+		if !subscriber.matches(event) || !subscriber.canSee(event) {
+			continue
+		}
+		select {
+		case subscriber.events <- event:
+		default:
+			klog.InfoS("Disconnecting slow progress stream subscriber", "username", subscriber.username)
+			delete(h.subscribers, subscriber)
+			close(subscriber.events)
+		}
+	}
+}
+
+// subscribe registers a subscriber and returns the current state of the objects it asked for. The
+// access of the subscriber has to be resolved beforehand, see progressSubscriber.resolveAccess.
+func (h *progressHub) subscribe(subscriber *progressSubscriber) []ProgressEvent {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.subscribers[subscriber] = struct{}{}
+
+	snapshot := []ProgressEvent{}
+	for _, informer := range h.informers {
+		for _, obj := range informer.informer.GetStore().List() {
+			u, ok := obj.(*unstructured.Unstructured)
+			if !ok {
+				continue
+			}
+			event := h.withCluster(toProgressEvent(informer.kind, informer.cluster, u))
+			event.Action = "snapshot"
+			if subscriber.matches(event) && subscriber.canSee(event) {
+				snapshot = append(snapshot, event)
+			}
+		}
+	}
+	return snapshot
+}
+
+// unsubscribe removes a subscriber unless it was disconnected already.
+func (h *progressHub) unsubscribe(subscriber *progressSubscriber) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.subscribers[subscriber]; ok {
+		delete(h.subscribers, subscriber)
+		close(subscriber.events)
+	}
+}
+
+// handleProgressStream streams backup and recovery progress as Server-Sent Events. The stream
+// starts with a "snapshot" event per existing object and continues with changes. The kinds
+// (comma separated), cluster, backupId and recoveryId query parameters limit the events. Callers
+// only get events of the clusters they may access.
+func handleProgressStream(c *gin.Context) {
+	username := auth.UserFromContext(c)
+	if username == "" {
+		common.FailWithStatus(c, fmt.Errorf("authentication required for progress streaming"), http.StatusUnauthorized)
+		return
+	}
+	if err := hub.ensureStarted(); err != nil {
+		klog.ErrorS(err, "Failed to start progress streaming")
+		common.Fail(c, err)
+		return
+	}
+
+	subscriber := &progressSubscriber{
+		ctx:        c.Request.Context(),
+		username:   username,
+		kinds:      map[string]bool{},
+		cluster:    c.Query("cluster"),
+		backupID:   c.Query("backupId"),
+		recoveryID: c.Query("recoveryId"),
+		events:     make(chan ProgressEvent, progressSubscriberBuffer),
+		access:     map[string]bool{},
+	}
+	if kinds := c.Query("kinds"); kinds != "" {
+		for _, kind := range strings.Split(kinds, ",") {
+			subscriber.kinds[strings.TrimSpace(kind)] = true
+		}
+	}
+	subscriber.resolveAccess(memberClusterNames(c))
+	go subscriber.refreshAccess()
+	snapshot := hub.subscribe(subscriber)
+	defer hub.unsubscribe(subscriber)
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	for _, event := range snapshot {
+		c.SSEvent("progress", event)
+	}
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(progressHeartbeatInterval)
+	defer heartbeat.Stop()
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-c.Request.Context().Done():
+			return false
+		case event, ok := <-subscriber.events:
+			if !ok {
+				return false
+			}
+			c.SSEvent("progress", event)
+			return true
+		case <-heartbeat.C:
+			_, err := io.WriteString(w, ": ping\n\n")
+			return err == nil
+		}
+	})
+}
+
+func init() {
+	r := router.V1()
+	// Progress of backups and recoveries, replaces polling of the recovery and checkpoint restore lists
+	r.GET("/backup/events/stream", handleProgressStream)
+}
@@ -0,0 +1,151 @@
+/*
+Copyright 2024 The Karmada Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"context"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/karmada-io/dashboard/pkg/auth"
+	"github.com/karmada-io/dashboard/pkg/auth/fga"
+)
+
+func TestToProgressEvent(t *testing.T) {
+	cases := []struct {
+		kind     string
+		object   map[string]interface{}
+		cluster  string
+		phase    string
+		progress int64
+		size     string
+		err      string
+	}{
+		{
+			progressKindBackup,
+			map[string]interface{}{
+				"spec":   map[string]interface{}{"sourceClusters": []interface{}{"member1"}},
+				"status": map[string]interface{}{"phase": "Checkpointing", "progress": float64(40)},
+			},
+			"member1", "Checkpointing", 40, "", "",
+		},
+		{
+			progressKindCheckpointBackup,
+			map[string]interface{}{
+				"status": map[string]interface{}{"phase": "Failed", "message": "registry unreachable", "checkpointSize": "1Gi"},
+			},
+			"member2", "Failed", 0, "1Gi", "registry unreachable",
+		},
+		{
+			progressKindBackupExecution,
+			map[string]interface{}{
+				"data": map[string]interface{}{"status": "Completed", "size": "512Mi"},
+			},
+			"", "Completed", 0, "512Mi", "",
+		},
+	}
+
+	for _, c := range cases {
+		obj := &unstructured.Unstructured{Object: c.object}
+		obj.SetName("shop")
+		obj.SetLabels(map[string]string{"backup-id": "backup-1"})
+		cluster := ""
+		if c.kind == progressKindCheckpointBackup {
+			cluster = "member2"
+		}
+		event := toProgressEvent(c.kind, cluster, obj)
+		if event.Cluster != c.cluster || event.Phase != c.phase || event.Progress != c.progress || event.Size != c.size || event.Error != c.err {
+			t.Errorf("toProgressEvent(%s) == %+v", c.kind, event)
+		}
+		if event.BackupID != "backup-1" {
+			t.Errorf("toProgressEvent(%s) backup ID == %q, expected backup-1", c.kind, event.BackupID)
+		}
+	}
+}
+
+func TestProgressSubscriberMatches(t *testing.T) {
+	event := ProgressEvent{Kind: progressKindRecovery, Cluster: "member1", BackupID: "backup-1", RecoveryID: "recovery-1"}
+
+	cases := []struct {
+		subscriber *progressSubscriber
+		matches    bool
+	}{
+		{&progressSubscriber{}, true},
+		{&progressSubscriber{kinds: map[string]bool{progressKindRecovery: true}, recoveryID: "recovery-1"}, true},
+		{&progressSubscriber{kinds: map[string]bool{progressKindBackup: true}}, false},
+		{&progressSubscriber{cluster: "member2"}, false},
+		{&progressSubscriber{backupID: "backup-2"}, false},
+	}
+
+	for _, c := range cases {
+		if matches := c.subscriber.matches(event); matches != c.matches {
+			t.Errorf("matches(%+v) == %v, expected %v", c.subscriber, matches, c.matches)
+		}
+	}
+
+	if !event.sameProgress(ProgressEvent{Kind: progressKindRecovery}) {
+		t.Errorf("sameProgress() == false for events without progress")
+	}
+	if event.sameProgress(ProgressEvent{Phase: "Restoring"}) {
+		t.Errorf("sameProgress() == true for a phase change")
+	}
+}
+
+func TestProgressSubscriberCanSee(t *testing.T) {
+	fga.FGAService = fga.NewServiceWithClient(fga.NewFakeClient(
+		fga.Tuple{User: "root", Relation: fga.RelationAdmin, ObjectType: fga.TypeDashboard, ObjectID: fga.TypeDashboard},
+		fga.Tuple{User: "alice", Relation: fga.RelationViewer, ObjectType: fga.TypeCluster, ObjectID: "member1"},
+	))
+	t.Cleanup(func() { fga.FGAService = nil })
+
+	// Backup executions have no cluster of their own, they belong to the source cluster of their backup
+	backup := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{"sourceClusters": []interface{}{"member2"}},
+	}}
+	backup.SetName("shop")
+	backup.SetLabels(map[string]string{"backup-id": "backup-2"})
+	informer := cache.NewSharedIndexInformer(&cache.ListWatch{}, &unstructured.Unstructured{}, 0, cache.Indexers{})
+	if err := informer.GetStore().Add(backup); err != nil {
+		t.Fatal(err)
+	}
+	h := &progressHub{informers: []progressInformer{{kind: progressKindBackup, informer: informer}}}
+	execution := h.withCluster(ProgressEvent{Kind: progressKindBackupExecution, BackupID: "backup-2"})
+
+	cases := []struct {
+		username string
+		event    ProgressEvent
+		canSee   bool
+	}{
+		{"alice", ProgressEvent{Cluster: "member1"}, true},
+		{"alice", ProgressEvent{Cluster: "member2"}, false},
+		{"alice", ProgressEvent{}, false},
+		{"alice", execution, false},
+		{"root", ProgressEvent{Cluster: "member2"}, true},
+		{"root", ProgressEvent{}, true},
+		{"root", execution, true},
+	}
+
+	for _, c := range cases {
+		subscriber := &progressSubscriber{ctx: auth.WithUser(context.Background(), c.username), username: c.username, access: map[string]bool{}}
+		subscriber.resolveAccess([]string{"member1", "member2"})
+		if canSee := subscriber.canSee(c.event); canSee != c.canSee {
+			t.Errorf("%s: canSee(%+v) == %v, expected %v", c.username, c.event, canSee, c.canSee)
+		}
+	}
+}
@@ -0,0 +1,557 @@
+/*
+Copyright 2024 The Karmada Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+
+	"github.com/karmada-io/dashboard/cmd/api/app/types/common"
+	"github.com/karmada-io/dashboard/pkg/client"
+)
+
+// Verification statuses, recorded in the backup history
+const (
+	VerificationRunning  = "Running"
+	VerificationVerified = "Verified"
+	VerificationFailed   = "Failed"
+)
+
+const (
+	// defaultSandboxTimeout bounds how long a sandbox restore may take
+	defaultSandboxTimeout = 30 * time.Minute
+	// sandboxPollInterval is how often a sandbox restore is checked for completion
+	sandboxPollInterval = 10 * time.Second
+	// sizeTolerance is how far the registry size of a checkpoint may be off the recorded size,
+	// which is usually rounded
+	sizeTolerance = 0.05
+	// verificationLabel marks the recoveries and namespaces of sandbox restores
+	verificationLabel = "backup.dcnlab.com/verification"
+)
+
+// VerifyBackupRequest represents a request to verify a backup execution
+type VerifyBackupRequest struct {
+	// ExecutionID is the backup history entry to verify, the latest successful one by default
+	ExecutionID string `json:"executionId,omitempty"`
+	// SandboxRestore restores the checkpoint into a scratch namespace, which is deleted afterwards
+	SandboxRestore bool `json:"sandboxRestore,omitempty"`
+	// SandboxCluster is the cluster of the scratch namespace, the source cluster by default
+	SandboxCluster string `json:"sandboxCluster,omitempty"`
+	// TimeoutMinutes bounds the sandbox restore, 30 minutes by default
+	TimeoutMinutes int `json:"timeoutMinutes,omitempty" binding:"min=0"`
+}
+
+// VerificationResult is the outcome of verifying a backup execution
+type VerificationResult struct {
+	ExecutionID    string           `json:"executionId"`
+	CheckpointPath string           `json:"checkpointPath"`
+	Status         string           `json:"status"` // "Running", "Verified" or "Failed"
+	StartedAt      time.Time        `json:"startedAt"`
+	Deadline       time.Time        `json:"deadline"`
+	CompletedAt    *time.Time       `json:"completedAt,omitempty"`
+	Digest         string           `json:"digest,omitempty"`
+	Size           int64            `json:"size,omitempty"` // Bytes of the manifest, config and layers
+	Checks         []PreflightCheck `json:"checks"`
+	Sandbox        *SandboxResult   `json:"sandbox,omitempty"`
+}
+
+// SandboxResult is the outcome of a sandbox restore
+type SandboxResult struct {
+	Cluster    string `json:"cluster"`
+	Namespace  string `json:"namespace"`
+	RecoveryID string `json:"recoveryId"`
+	Phase      string `json:"phase"`
+}
+
+// add records a check.
+func (r *VerificationResult) add(name, status, message string) {
+	r.Checks = append(r.Checks, PreflightCheck{Name: name, Status: status, Message: message})
+}
+
+// finish sets the final status from the checks.
+func (r *VerificationResult) finish() {
+	r.Status = VerificationVerified
+	for _, check := range r.Checks {
+		if check.Status == PreflightFailed {
+			r.Status = VerificationFailed
+		}
+	}
+	now := time.Now()
+	r.CompletedAt = &now
+}
+
+// ociDescriptor references a blob or manifest from a manifest
+type ociDescriptor struct {
+	MediaType string `json:"mediaType"`
+	Digest    string `json:"digest"`
+	Size      int64  `json:"size"`
+}
+
+// ociManifest covers image manifests and image indexes of both OCI and Docker
+type ociManifest struct {
+	MediaType string          `json:"mediaType"`
+	Config    *ociDescriptor  `json:"config,omitempty"`
+	Layers    []ociDescriptor `json:"layers,omitempty"`
+	Manifests []ociDescriptor `json:"manifests,omitempty"`
+}
+
+// manifestContentDigest computes the digest of a manifest the way registries do.
+func manifestContentDigest(body []byte) string {
+	sum := sha256.Sum256(body)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// verifyCheckpointImage checks that the checkpoint image is complete in the registry: the
+// manifest matches its digest, and every blob it references exists with the expected size.
+// Image indexes are followed one level deep.
+func verifyCheckpointImage(ctx context.Context, registry *registryClient, checkpointPath string, result *VerificationResult) {
+	repository, reference, err := parseImageReference(checkpointPath)
+	if err != nil {
+		result.add("manifest", PreflightFailed, err.Error())
+		return
+	}
+
+	digest, size, err := verifyManifest(ctx, registry, repository, reference, true, result)
+	if err != nil {
+		result.add("manifest", PreflightFailed, err.Error())
+		return
+	}
+	result.Digest, result.Size = digest, size
+}
+
+// verifyManifest verifies a manifest and the blobs it references, returning its digest and the
+// total size of the image.
+func verifyManifest(ctx context.Context, registry *registryClient, repository, reference string, followIndex bool, result *VerificationResult) (string, int64, error) {
+	body, _, reportedDigest, err := registry.getManifest(ctx, repository, reference)
+	if errors.Is(err, errManifestNotFound) {
+		return "", 0, fmt.Errorf("checkpoint image %s:%s does not exist in the registry", repository, reference)
+	}
+	if err != nil {
+		return "", 0, err
+	}
+
+	digest := manifestContentDigest(body)
+	switch {
+	case strings.HasPrefix(reference, "sha256:") && reference != digest:
+		result.add("manifest-digest", PreflightFailed, fmt.Sprintf("manifest %s has digest %s", reference, digest))
+	case reportedDigest != "" && reportedDigest != digest:
+		result.add("manifest-digest", PreflightFailed,
+			fmt.Sprintf("registry reports digest %s for %s:%s, content has digest %s", reportedDigest, repository, reference, digest))
+	default:
+		result.add("manifest-digest", PreflightPassed, fmt.Sprintf("%s:%s has digest %s", repository, reference, digest))
+	}
+
+	var manifest ociManifest
+	if err := json.Unmarshal(body, &manifest); err != nil {
+		return digest, 0, fmt.Errorf("invalid manifest %s:%s: %v", repository, reference, err)
+	}
+
+	size := int64(len(body))
+	if len(manifest.Manifests) > 0 {
+		if !followIndex {
+			return digest, size, fmt.Errorf("manifest %s:%s is a nested image index", repository, reference)
+		}
+		for _, child := range manifest.Manifests {
+			_, childSize, err := verifyManifest(ctx, registry, repository, child.Digest, false, result)
+			if err != nil {
+				return digest, size, err
+			}
+			size += childSize
+		}
+		return digest, size, nil
+	}
+
+	blobs := manifest.Layers
+	if manifest.Config != nil {
+		blobs = append([]ociDescriptor{*manifest.Config}, blobs...)
+	}
+	if len(manifest.Layers) == 0 {
+		result.add("blobs", PreflightFailed, fmt.Sprintf("manifest %s:%s has no layers", repository, reference))
+		return digest, size, nil
+	}
+	var problems []string
+	for _, blob := range blobs {
+		size += blob.Size
+		stored, err := registry.blobSize(ctx, repository, blob.Digest)
+		switch {
+		case errors.Is(err, errBlobNotFound):
+			problems = append(problems, fmt.Sprintf("blob %s is missing", blob.Digest))
+		case err != nil:
+			problems = append(problems, err.Error())
+		case stored >= 0 && stored != blob.Size:
+			problems = append(problems, fmt.Sprintf("blob %s has %d bytes, manifest says %d", blob.Digest, stored, blob.Size))
+		}
+	}
+	if len(problems) > 0 {
+		result.add("blobs", PreflightFailed, strings.Join(problems, "; "))
+	} else {
+		result.add("blobs", PreflightPassed, fmt.Sprintf("%d blobs of %s:%s are intact", len(blobs), repository, reference))
+	}
+	return digest, size, nil
+}
+
+// checkRecordedSize compares the size of the image in the registry with the size recorded in
+// the backup history. A mismatch is only a warning, the recorded size can be an estimate.
+func checkRecordedSize(recorded string, size int64, result *VerificationResult) {
+	if recorded == "" || size == 0 {
+		return
+	}
+	quantity, err := resource.ParseQuantity(strings.ReplaceAll(recorded, " ", ""))
+	if err != nil {
+		result.add("size", PreflightWarning, fmt.Sprintf("recorded size %q cannot be compared", recorded))
+		return
+	}
+	expected := quantity.Value()
+	if expected > 0 && math.Abs(float64(size-expected))/float64(expected) > sizeTolerance {
+		result.add("size", PreflightWarning, fmt.Sprintf("image has %d bytes, backup recorded %s", size, recorded))
+		return
+	}
+	result.add("size", PreflightPassed, fmt.Sprintf("image has %d bytes, backup recorded %s", size, recorded))
+}
+
+// runSandboxRestore restores a checkpoint into a scratch namespace, checks that the restored
+// pods come up and deletes the namespace and the recovery again. It runs as the dashboard, callers
+// are authorized for the cluster beforehand, see authorizeClusterWrite.
+func runSandboxRestore(ctx context.Context, backup BackupConfiguration, execution BackupExecution, cluster string, result *VerificationResult) {
+	kube := client.InClusterClientForMemberClusterAsUser(ctx, "", cluster)
+	if kube == nil {
+		result.add("sandbox-restore", PreflightFailed, fmt.Sprintf("cluster %s is not accessible", cluster))
+		return
+	}
+
+	suffix := fmt.Sprintf("%d", time.Now().Unix())
+	sandbox := &SandboxResult{
+		Cluster:    cluster,
+		Namespace:  fmt.Sprintf("backup-verify-%s", suffix),
+		RecoveryID: fmt.Sprintf("verify-%s-%s", backup.ID, suffix),
+	}
+	result.Sandbox = sandbox
+
+	namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+		Name:   sandbox.Namespace,
+		Labels: map[string]string{verificationLabel: "true", "backup-id": backup.ID},
+	}}
+	if _, err := kube.CoreV1().Namespaces().Create(ctx, namespace, metav1.CreateOptions{}); err != nil {
+		result.add("sandbox-restore", PreflightFailed, fmt.Sprintf("failed to create namespace %s: %v", sandbox.Namespace, err))
+		return
+	}
+	defer func() {
+		// The context may be done already, cleanup must still happen
+		cleanupCtx := context.Background()
+		if err := kube.CoreV1().Namespaces().Delete(cleanupCtx, sandbox.Namespace, metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+			klog.ErrorS(err, "Failed to delete sandbox namespace", "cluster", cluster, "namespace", sandbox.Namespace)
+		}
+		dynamicClient, err := client.GetDynamicClient()
+		if err != nil {
+			return
+		}
+		err = dynamicClient.Resource(recoveryStatefulMigrationGVR).Namespace("karmada-system").Delete(cleanupCtx,
+			fmt.Sprintf("recovery-%s", sandbox.RecoveryID), metav1.DeleteOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			klog.ErrorS(err, "Failed to delete sandbox recovery", "recoveryID", sandbox.RecoveryID)
+		}
+	}()
+
+	req := CreateRecoveryRequest{
+		Name:            sandbox.RecoveryID,
+		BackupID:        backup.ID,
+		TargetCluster:   cluster,
+		RecoveryType:    "restore",
+		TargetNamespace: sandbox.Namespace,
+		CheckpointImage: execution.CheckpointPath,
+	}
+	if _, err := createRecovery(sandbox.RecoveryID, req, backup, map[string]string{verificationLabel: "true"}); err != nil {
+		result.add("sandbox-restore", PreflightFailed, fmt.Sprintf("failed to create recovery: %v", err))
+		return
+	}
+	if err := executeRecovery(ctx, sandbox.RecoveryID); err != nil {
+		result.add("sandbox-restore", PreflightFailed, fmt.Sprintf("failed to start recovery: %v", err))
+		return
+	}
+
+	recovery, err := waitForRecovery(ctx, sandbox.RecoveryID)
+	if recovery != nil {
+		sandbox.Phase = recovery.Status
+	}
+	if err != nil {
+		result.add("sandbox-restore", PreflightFailed, err.Error())
+		return
+	}
+	if !strings.EqualFold(recovery.Status, "completed") {
+		message := fmt.Sprintf("recovery ended in phase %s", recovery.Status)
+		if recovery.Error != "" {
+			message += ": " + recovery.Error
+		}
+		result.add("sandbox-restore", PreflightFailed, message)
+		return
+	}
+	result.add("sandbox-restore", PreflightPassed, fmt.Sprintf("restored into %s/%s", cluster, sandbox.Namespace))
+
+	pods, err := kube.CoreV1().Pods(sandbox.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		result.add("sandbox-pods", PreflightWarning, fmt.Sprintf("failed to list restored pods: %v", err))
+		return
+	}
+	running := 0
+	for _, pod := range pods.Items {
+		if pod.Status.Phase == corev1.PodRunning {
+			running++
+		}
+	}
+	switch {
+	case len(pods.Items) == 0:
+		result.add("sandbox-pods", PreflightWarning, "no pods were restored")
+	case running < len(pods.Items):
+		result.add("sandbox-pods", PreflightFailed, fmt.Sprintf("%d of %d restored pods are running", running, len(pods.Items)))
+	default:
+		result.add("sandbox-pods", PreflightPassed, fmt.Sprintf("%d restored pods are running", running))
+	}
+}
+
+// waitForRecovery waits until a recovery completed, failed or was cancelled.
+func waitForRecovery(ctx context.Context, recoveryID string) (*RecoveryRecord, error) {
+	dynamicClient, err := client.GetDynamicClient()
+	if err != nil {
+		return nil, err
+	}
+	ticker := time.NewTicker(sandboxPollInterval)
+	defer ticker.Stop()
+	for {
+		sm, err := dynamicClient.Resource(recoveryStatefulMigrationGVR).Namespace("karmada-system").Get(ctx,
+			fmt.Sprintf("recovery-%s", recoveryID), metav1.GetOptions{})
+		if err != nil && ctx.Err() == nil {
+			return nil, fmt.Errorf("failed to get recovery: %v", err)
+		}
+		var recovery *RecoveryRecord
+		if err == nil {
+			record := statefulMigrationToRecovery(sm)
+			recovery = &record
+			switch strings.ToLower(record.Status) {
+			case "completed", "failed", "cancelled":
+				return recovery, nil
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return recovery, fmt.Errorf("sandbox restore did not finish in time")
+		case <-ticker.C:
+		}
+	}
+}
+
+// verifyExecution runs a verification and records its result in the history entry of the
+// execution.
+func verifyExecution(ctx context.Context, backup BackupConfiguration, execution BackupExecution, recordedSize string,
+	req VerifyBackupRequest, result *VerificationResult) {
+	credentials, err := getRegistryWithPassword(fmt.Sprintf("%s-%s", registrySecretPrefix, backup.Registry.ID))
+	if err != nil {
+		result.add("registry", PreflightFailed, fmt.Sprintf("failed to get registry credentials: %v", err))
+	} else {
+		verifyCheckpointImage(ctx, newRegistryClient(credentials), execution.CheckpointPath, result)
+		checkRecordedSize(recordedSize, result.Size, result)
+	}
+
+	// A sandbox restore of an image that failed verification would fail as well
+	if req.SandboxRestore && result.Digest != "" {
+		cluster := req.SandboxCluster
+		if cluster == "" {
+			cluster = backup.Cluster
+		}
+		runSandboxRestore(ctx, backup, execution, cluster, result)
+	}
+
+	result.finish()
+	if err := recordVerification(context.Background(), execution.ID, result); err != nil {
+		klog.ErrorS(err, "Failed to record backup verification", "backupID", backup.ID, "execution", execution.ID)
+		return
+	}
+	klog.InfoS("Verified backup execution", "backupID", backup.ID, "execution", execution.ID, "status", result.Status)
+}
+
+// recordVerification stores a verification result in the history entry of the execution.
+func recordVerification(ctx context.Context, executionID string, result *VerificationResult) error {
+	encoded, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	patch, err := json.Marshal(map[string]interface{}{
+		"data": map[string]string{
+			"verification":       string(encoded),
+			"verificationStatus": result.Status,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	dynamicClient, err := client.GetDynamicClient()
+	if err != nil {
+		return err
+	}
+	_, err = dynamicClient.Resource(configMapGVR).Namespace(backupHistoryNamespace).Patch(ctx, executionID,
+		types.MergePatchType, patch, metav1.PatchOptions{})
+	return err
+}
+
+// verificationFromHistory reads the verification result of a history entry, nil if it has none.
+func verificationFromHistory(data map[string]string) *VerificationResult {
+	if data["verification"] == "" {
+		return nil
+	}
+	result := &VerificationResult{}
+	if err := json.Unmarshal([]byte(data["verification"]), result); err != nil {
+		return nil
+	}
+	return result
+}
+
+// selectExecution picks the history entry to verify: the requested one, or the latest
+// successful one.
+func selectExecution(history []unstructured.Unstructured, executionID string) (*unstructured.Unstructured, error) {
+	var selected *unstructured.Unstructured
+	var latest time.Time
+	for i := range history {
+		execution := configMapToBackupExecution(&history[i])
+		if executionID != "" {
+			if execution.ID == executionID {
+				selected = &history[i]
+				break
+			}
+			continue
+		}
+		if execution.succeeded() && (selected == nil || execution.Timestamp.After(latest)) {
+			selected, latest = &history[i], execution.Timestamp
+		}
+	}
+
+	switch {
+	case selected == nil && executionID != "":
+		return nil, fmt.Errorf("backup execution %s not found", executionID)
+	case selected == nil:
+		return nil, fmt.Errorf("backup has no successful execution to verify")
+	}
+	execution := configMapToBackupExecution(selected)
+	if !execution.succeeded() {
+		return nil, fmt.Errorf("backup execution %s did not succeed", execution.ID)
+	}
+	if execution.CheckpointPath == "" {
+		return nil, fmt.Errorf("backup execution %s recorded no checkpoint image", execution.ID)
+	}
+	return selected, nil
+}
+
+// handleVerifyBackup starts the verification of a backup execution. The verification runs in
+// the background, its result is recorded in the backup history.
+func handleVerifyBackup(c *gin.Context) {
+	backupID := c.Param("id")
+	var req VerifyBackupRequest
+	// The request body is optional
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		common.Fail(c, err)
+		return
+	}
+
+	backup, err := getBackupByID(backupID)
+	if err != nil {
+		klog.ErrorS(err, "Failed to get backup", "backupID", backupID)
+		common.Fail(c, err)
+		return
+	}
+	if req.SandboxRestore {
+		cluster := req.SandboxCluster
+		if cluster == "" {
+			cluster = backup.Cluster
+		}
+		if status, err := authorizeClusterWrite(c, cluster); err != nil {
+			common.FailWithStatus(c, err, status)
+			return
+		}
+	}
+
+	dynamicClient, err := client.GetDynamicClient()
+	if err != nil {
+		klog.ErrorS(err, "Failed to get dynamic client")
+		common.Fail(c, err)
+		return
+	}
+	history, err := dynamicClient.Resource(configMapGVR).Namespace(backupHistoryNamespace).List(c, metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("app=backup-history,backup-id=%s", backupID),
+	})
+	if err != nil {
+		klog.ErrorS(err, "Failed to list backup history", "backupID", backupID)
+		common.Fail(c, err)
+		return
+	}
+	selected, err := selectExecution(history.Items, req.ExecutionID)
+	if err != nil {
+		common.Fail(c, err)
+		return
+	}
+
+	data, _, _ := unstructured.NestedStringMap(selected.Object, "data")
+	// A verification left running by a restarted API server is stale once past its deadline
+	if previous := verificationFromHistory(data); previous != nil && previous.Status == VerificationRunning &&
+		time.Now().Before(previous.Deadline) {
+		common.Fail(c, fmt.Errorf("backup execution %s is being verified already", selected.GetName()))
+		return
+	}
+
+	timeout := defaultSandboxTimeout
+	if req.TimeoutMinutes > 0 {
+		timeout = time.Duration(req.TimeoutMinutes) * time.Minute
+	}
+	execution := configMapToBackupExecution(selected)
+	result := &VerificationResult{
+		ExecutionID:    execution.ID,
+		CheckpointPath: execution.CheckpointPath,
+		Status:         VerificationRunning,
+		StartedAt:      time.Now(),
+		Deadline:       time.Now().Add(timeout),
+		Checks:         []PreflightCheck{},
+	}
+	if err := recordVerification(c, execution.ID, result); err != nil {
+		klog.ErrorS(err, "Failed to record backup verification", "backupID", backupID)
+		common.Fail(c, err)
+		return
+	}
+
+	response := *result
+	go func() {
+		ctx, cancel := context.WithDeadline(context.Background(), result.Deadline)
+		defer cancel()
+		verifyExecution(ctx, backup, execution, data["size"], req, result)
+	}()
+	common.Success(c, response)
+}
@@ -0,0 +1,119 @@
+/*
+Copyright 2024 The Karmada Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestVerifyCheckpointImage(t *testing.T) {
+	manifest := []byte(`{"schemaVersion":2,"mediaType":"application/vnd.oci.image.manifest.v1+json",` +
+		`"config":{"digest":"sha256:config","size":100},"layers":[{"digest":"sha256:layer","size":2000}]}`)
+	digest := manifestContentDigest(manifest)
+
+	cases := []struct {
+		name           string
+		reportedDigest string
+		blobs          map[string]int64
+		reference      string
+		status         string
+		checks         map[string]string
+	}{
+		{"intact", digest, map[string]int64{"sha256:config": 100, "sha256:layer": 2000}, "backups/web:v1",
+			VerificationVerified, map[string]string{"manifest-digest": PreflightPassed, "blobs": PreflightPassed}},
+		{"by digest", "", map[string]int64{"sha256:config": 100, "sha256:layer": 2000}, "backups/web@" + digest,
+			VerificationVerified, map[string]string{"manifest-digest": PreflightPassed, "blobs": PreflightPassed}},
+		{"missing layer", digest, map[string]int64{"sha256:config": 100}, "backups/web:v1",
+			VerificationFailed, map[string]string{"manifest-digest": PreflightPassed, "blobs": PreflightFailed}},
+		{"truncated layer", digest, map[string]int64{"sha256:config": 100, "sha256:layer": 1000}, "backups/web:v1",
+			VerificationFailed, map[string]string{"manifest-digest": PreflightPassed, "blobs": PreflightFailed}},
+		{"digest mismatch", "sha256:other", map[string]int64{"sha256:config": 100, "sha256:layer": 2000}, "backups/web:v1",
+			VerificationFailed, map[string]string{"manifest-digest": PreflightFailed, "blobs": PreflightPassed}},
+		{"missing tag", digest, nil, "backups/web:v2",
+			VerificationFailed, map[string]string{"manifest": PreflightFailed}},
+	}
+
+	for _, c := range cases {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch {
+			case r.URL.Path == "/v2/backups/web/manifests/v1" || r.URL.Path == "/v2/backups/web/manifests/"+digest:
+				if c.reportedDigest != "" {
+					w.Header().Set("Docker-Content-Digest", c.reportedDigest)
+				}
+				w.Write(manifest)
+			case strings.HasPrefix(r.URL.Path, "/v2/backups/web/blobs/"):
+				size, ok := c.blobs[strings.TrimPrefix(r.URL.Path, "/v2/backups/web/blobs/")]
+				if !ok {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+				w.Header().Set("Content-Length", fmt.Sprint(size))
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+
+		result := &VerificationResult{}
+		registry := newRegistryClient(RegistryCredentials{Registry: server.URL})
+		verifyCheckpointImage(context.TODO(), registry, c.reference, result)
+		result.finish()
+		server.Close()
+
+		checks := map[string]string{}
+		for _, check := range result.Checks {
+			checks[check.Name] = check.Status
+		}
+		if result.Status != c.status || !reflect.DeepEqual(checks, c.checks) {
+			t.Errorf("%s: verifyCheckpointImage() == %s %v, expected %s %v", c.name, result.Status, checks, c.status, c.checks)
+		}
+		if c.status == VerificationVerified && (result.Digest != digest || result.Size != int64(len(manifest))+2100) {
+			t.Errorf("%s: verifyCheckpointImage() digest %s size %d", c.name, result.Digest, result.Size)
+		}
+	}
+}
+
+func TestCheckRecordedSize(t *testing.T) {
+	cases := []struct {
+		recorded string
+		size     int64
+		status   string
+	}{
+		{"1Gi", 1 << 30, PreflightPassed},
+		{"1 Gi", 1<<30 - 1<<20, PreflightPassed},
+		{"512Mi", 1 << 30, PreflightWarning},
+		{"about a gigabyte", 1 << 30, PreflightWarning},
+		{"", 1 << 30, ""},
+	}
+
+	for _, c := range cases {
+		result := &VerificationResult{}
+		checkRecordedSize(c.recorded, c.size, result)
+		status := ""
+		if len(result.Checks) > 0 {
+			status = result.Checks[0].Status
+		}
+		if status != c.status {
+			t.Errorf("checkRecordedSize(%q, %d) == %q, expected %q", c.recorded, c.size, status, c.status)
+		}
+	}
+}
@@ -322,8 +322,9 @@ func handleUpdateClusterUsers(c *gin.Context) {
 	// Parse the request body
 	var request struct {
 		Users []struct {
-			Username string   `json:"username"`
-			Roles    []string `json:"roles"`
+			Username   string                   `json:"username"`
+			Roles      []string                 `json:"roles"`
+			Namespaces []v1.NamespacePermission `json:"namespaces"`
 		} `json:"users"`
 	}
 
@@ -337,6 +338,17 @@ func handleUpdateClusterUsers(c *gin.Context) {
 		common.FailWithStatus(c, fmt.Errorf("users list cannot be empty"), 400)
 		return
 	}
+	for _, userUpdate := range request.Users {
+		perm := v1.ClusterPermission{
+			Cluster:    clusterName,
+			Roles:      clusterRelationsForRoles(userUpdate.Roles),
+			Namespaces: userUpdate.Namespaces,
+		}
+		if err := cluster.ValidateClusterPermission(perm); err != nil {
+			common.FailWithStatus(c, fmt.Errorf("invalid roles for user %s: %v", userUpdate.Username, err), 400)
+			return
+		}
+	}
 
 	// First, check if the cluster exists
 	_, err := karmadaClient.ClusterV1alpha1().Clusters().Get(context.TODO(), clusterName, metav1.GetOptions{})
@@ -375,27 +387,14 @@ func handleUpdateClusterUsers(c *gin.Context) {
 
 		// Update user roles using the OpenFGA service
 		if fgaService != nil {
-			// Delete all existing relations for this user
-			err := removeUserRolesFromCluster(fgaService, userUpdate.Username, clusterName)
-			if err != nil {
-				klog.ErrorS(err, "Failed to remove existing roles", "username", userUpdate.Username, "clusterName", clusterName)
-				continue
+			perm := v1.ClusterPermission{
+				Cluster:    clusterName,
+				Roles:      clusterRelationsForRoles(userUpdate.Roles),
+				Namespaces: userUpdate.Namespaces,
 			}
-
-			// Add new roles based on the request
-			for _, role := range userUpdate.Roles {
-				// Map UI role names to OpenFGA relation names
-				relation := role
-				if role == "owner" || role == "admin" {
-					relation = "owner"
-				} else if role == "member" || role == "read" || role == "write" {
-					relation = "member"
-				}
-
-				err := fgaService.GetClient().WriteTuple(context.TODO(), userUpdate.Username, relation, "cluster", clusterName)
-				if err != nil {
-					klog.ErrorS(err, "Failed to add role", "username", userUpdate.Username, "role", role, "clusterName", clusterName)
-				}
+			if err := cluster.ReplaceClusterPermission(context.TODO(), fgaService.GetClient(), userUpdate.Username, perm); err != nil {
+				klog.ErrorS(err, "Failed to update roles", "username", userUpdate.Username, "clusterName", clusterName)
+				continue
 			}
 		}
 	}
@@ -411,25 +410,21 @@ func handleUpdateClusterUsers(c *gin.Context) {
 	common.Success(c, updatedUsers)
 }
 
-func removeUserRolesFromCluster(fgaService *fga.Service, username, clusterName string) error {
-	// Remove the owner relation if it exists
-	ownerErr := fgaService.GetClient().DeleteTuple(context.TODO(), username, "owner", "cluster", clusterName)
-	if ownerErr != nil {
-		klog.V(4).InfoS("Failed to remove owner role, might not exist", "username", username, "clusterName", clusterName, "error", ownerErr)
-	}
-
-	// Remove the member relation if it exists
-	memberErr := fgaService.GetClient().DeleteTuple(context.TODO(), username, "member", "cluster", clusterName)
-	if memberErr != nil {
-		klog.V(4).InfoS("Failed to remove member role, might not exist", "username", username, "clusterName", clusterName, "error", memberErr)
-	}
-
-	// Only return an error if both operations failed
-	if ownerErr != nil && memberErr != nil {
-		return fmt.Errorf("failed to remove roles: %v, %v", ownerErr, memberErr)
+// clusterRelationsForRoles maps UI role names to OpenFGA cluster relations. The legacy "admin",
+// "read" and "write" names are kept for older clients, every other name is passed through as is.
+func clusterRelationsForRoles(roles []string) []string {
+	relations := make([]string, 0, len(roles))
+	for _, role := range roles {
+		switch role {
+		case "admin":
+			relations = append(relations, fga.RelationOwner)
+		case "read", "write":
+			relations = append(relations, fga.RelationMember)
+		default:
+			relations = append(relations, role)
+		}
 	}
-
-	return nil
+	return relations
 }
 
 func parseEndpointFromKubeconfig(kubeconfigContents string) (string, error) {
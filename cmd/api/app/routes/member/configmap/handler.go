@@ -14,7 +14,7 @@ func handleGetMemberConfigMaps(c *gin.Context) {
 	namespace := common.ParseNamespacePathParameter(c)
 	dataSelect := common.ParseDataSelectPathParameter(c)
 
-	memberClient := client.InClusterClientForMemberCluster(clusterName)
+	memberClient := client.InClusterClientForMemberCluster(c, clusterName)
 	result, err := configmap.GetConfigMapList(memberClient, namespace, dataSelect)
 	if err != nil {
 		common.Fail(c, err)
@@ -29,7 +29,7 @@ func handleGetMemberConfigMapDetail(c *gin.Context) {
 	namespace := c.Param("namespace")
 	name := c.Param("name")
 
-	memberClient := client.InClusterClientForMemberCluster(clusterName)
+	memberClient := client.InClusterClientForMemberCluster(c, clusterName)
 	result, err := configmap.GetConfigMapDetail(memberClient, namespace, name)
 	if err != nil {
 		common.Fail(c, err)
@@ -14,7 +14,7 @@ func handleGetMemberCronJobs(c *gin.Context) {
 	namespace := common.ParseNamespacePathParameter(c)
 	dataSelect := common.ParseDataSelectPathParameter(c)
 
-	memberClient := client.InClusterClientForMemberCluster(clusterName)
+	memberClient := client.InClusterClientForMemberCluster(c, clusterName)
 	result, err := cronjob.GetCronJobList(memberClient, namespace, dataSelect)
 	if err != nil {
 		common.Fail(c, err)
@@ -29,7 +29,7 @@ func handleGetMemberCronJobDetail(c *gin.Context) {
 	namespace := c.Param("namespace")
 	name := c.Param("cronjob")
 
-	memberClient := client.InClusterClientForMemberCluster(clusterName)
+	memberClient := client.InClusterClientForMemberCluster(c, clusterName)
 	result, err := cronjob.GetCronJobDetail(memberClient, namespace, name)
 	if err != nil {
 		common.Fail(c, err)
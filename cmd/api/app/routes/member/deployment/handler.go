@@ -35,7 +35,7 @@ import (
 )
 
 func handleGetMemberDeployments(c *gin.Context) {
-	memberClient := client.InClusterClientForMemberCluster(c.Param("clustername"))
+	memberClient := client.InClusterClientForMemberCluster(c, c.Param("clustername"))
 	namespace := common.ParseNamespacePathParameter(c)
 	dataSelect := common.ParseDataSelectPathParameter(c)
 	result, err := deployment.GetDeploymentList(memberClient, namespace, dataSelect)
@@ -47,7 +47,7 @@ func handleGetMemberDeployments(c *gin.Context) {
 }
 
 func handleGetMemberDeploymentDetail(c *gin.Context) {
-	memberClient := client.InClusterClientForMemberCluster(c.Param("clustername"))
+	memberClient := client.InClusterClientForMemberCluster(c, c.Param("clustername"))
 	namespace := c.Param("namespace")
 	name := c.Param("deployment")
 
@@ -103,7 +103,7 @@ func handleGetMemberDeploymentDetail(c *gin.Context) {
 }
 
 func handleGetMemberDeploymentEvents(c *gin.Context) {
-	memberClient := client.InClusterClientForMemberCluster(c.Param("clustername"))
+	memberClient := client.InClusterClientForMemberCluster(c, c.Param("clustername"))
 	namespace := c.Param("namespace")
 	name := c.Param("deployment")
 	dataSelect := common.ParseDataSelectPathParameter(c)
@@ -116,7 +116,7 @@ func handleGetMemberDeploymentEvents(c *gin.Context) {
 }
 
 func handleRestartMemberDeployment(c *gin.Context) {
-	memberClient := client.InClusterClientForMemberCluster(c.Param("clustername"))
+	memberClient := client.InClusterClientForMemberCluster(c, c.Param("clustername"))
 	namespace := c.Param("namespace")
 	name := c.Param("deployment")
 
@@ -14,7 +14,7 @@ func handleGetMemberIngresses(c *gin.Context) {
 	namespace := common.ParseNamespacePathParameter(c)
 	dataSelect := common.ParseDataSelectPathParameter(c)
 
-	memberClient := client.InClusterClientForMemberCluster(clusterName)
+	memberClient := client.InClusterClientForMemberCluster(c, clusterName)
 	result, err := ingress.GetIngressList(memberClient, namespace, dataSelect)
 	if err != nil {
 		common.Fail(c, err)
@@ -29,7 +29,7 @@ func handleGetMemberIngressDetail(c *gin.Context) {
 	namespace := c.Param("namespace")
 	name := c.Param("name")
 
-	memberClient := client.InClusterClientForMemberCluster(clusterName)
+	memberClient := client.InClusterClientForMemberCluster(c, clusterName)
 	result, err := ingress.GetIngressDetail(memberClient, namespace, name)
 	if err != nil {
 		common.Fail(c, err)
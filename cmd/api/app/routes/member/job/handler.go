@@ -14,7 +14,7 @@ func handleGetMemberJobs(c *gin.Context) {
 	namespace := common.ParseNamespacePathParameter(c)
 	dataSelect := common.ParseDataSelectPathParameter(c)
 
-	memberClient := client.InClusterClientForMemberCluster(clusterName)
+	memberClient := client.InClusterClientForMemberCluster(c, clusterName)
 	result, err := job.GetJobList(memberClient, namespace, dataSelect)
 	if err != nil {
 		common.Fail(c, err)
@@ -29,7 +29,7 @@ func handleGetMemberJobDetail(c *gin.Context) {
 	namespace := c.Param("namespace")
 	name := c.Param("job")
 
-	memberClient := client.InClusterClientForMemberCluster(clusterName)
+	memberClient := client.InClusterClientForMemberCluster(c, clusterName)
 	result, err := job.GetJobDetail(memberClient, namespace, name)
 	if err != nil {
 		common.Fail(c, err)
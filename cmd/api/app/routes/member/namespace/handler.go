@@ -29,7 +29,7 @@ import (
 )
 
 func handleGetMemberNamespace(c *gin.Context) {
-	memberClient := client.InClusterClientForMemberCluster(c.Param("clustername"))
+	memberClient := client.InClusterClientForMemberCluster(c, c.Param("clustername"))
 
 	dataSelect := common.ParseDataSelectPathParameter(c)
 	result, err := ns.GetNamespaceList(memberClient, dataSelect)
@@ -41,7 +41,7 @@ func handleGetMemberNamespace(c *gin.Context) {
 }
 
 func handleGetMemberNamespaceDetail(c *gin.Context) {
-	memberClient := client.InClusterClientForMemberCluster(c.Param("clustername"))
+	memberClient := client.InClusterClientForMemberCluster(c, c.Param("clustername"))
 
 	name := c.Param("name")
 	result, err := ns.GetNamespaceDetail(memberClient, name)
@@ -53,7 +53,7 @@ func handleGetMemberNamespaceDetail(c *gin.Context) {
 }
 
 func handleGetMemberNamespaceEvents(c *gin.Context) {
-	memberClient := client.InClusterClientForMemberCluster(c.Param("clustername"))
+	memberClient := client.InClusterClientForMemberCluster(c, c.Param("clustername"))
 
 	name := c.Param("name")
 	dataSelect := common.ParseDataSelectPathParameter(c)
@@ -66,7 +66,7 @@ func handleGetMemberNamespaceEvents(c *gin.Context) {
 }
 
 func handleCreateNamespace(c *gin.Context) {
-	memberClient := client.InClusterClientForMemberCluster(c.Param("clustername"))
+	memberClient := client.InClusterClientForMemberCluster(c, c.Param("clustername"))
 
 	createNamespaceRequest := new(v1.CreateNamesapceRequest)
 
@@ -86,7 +86,7 @@ func handleCreateNamespace(c *gin.Context) {
 }
 
 func handleDeleteNamespace(c *gin.Context) {
-	memberClient := client.InClusterClientForMemberCluster(c.Param("clustername"))
+	memberClient := client.InClusterClientForMemberCluster(c, c.Param("clustername"))
 	namespaceName := c.Param("name")
 
 	// Delete the namespace
@@ -29,7 +29,7 @@ import (
 )
 
 func handleGetClusterNode(c *gin.Context) {
-	memberClient := client.InClusterClientForMemberCluster(c.Param("clustername"))
+	memberClient := client.InClusterClientForMemberCluster(c, c.Param("clustername"))
 	dataSelect := common.ParseDataSelectPathParameter(c)
 	result, err := node.GetNodeList(memberClient, dataSelect)
 	if err != nil {
@@ -40,7 +40,7 @@ func handleGetClusterNode(c *gin.Context) {
 }
 
 func handleGetClusterNodeDetail(c *gin.Context) {
-	memberClient := client.InClusterClientForMemberCluster(c.Param("clustername"))
+	memberClient := client.InClusterClientForMemberCluster(c, c.Param("clustername"))
 	nodeName := c.Param("nodename")
 
 	// Get node details
@@ -70,7 +70,7 @@ func handleGetClusterNodeDetail(c *gin.Context) {
 }
 
 func handleGetClusterNodeEvents(c *gin.Context) {
-	memberClient := client.InClusterClientForMemberCluster(c.Param("clustername"))
+	memberClient := client.InClusterClientForMemberCluster(c, c.Param("clustername"))
 	nodeName := c.Param("nodename")
 
 	// Get all events
@@ -95,7 +95,7 @@ func handleGetClusterNodeEvents(c *gin.Context) {
 }
 
 func handleGetClusterNodePods(c *gin.Context) {
-	memberClient := client.InClusterClientForMemberCluster(c.Param("clustername"))
+	memberClient := client.InClusterClientForMemberCluster(c, c.Param("clustername"))
 	nodeName := c.Param("nodename")
 
 	// Get pods with field selector
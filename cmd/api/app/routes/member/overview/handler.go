@@ -39,13 +39,13 @@ func HandleGetMemberOverview(c *gin.Context) {
 	}
 
 	// Get deployment count for this member cluster
-	deploymentCount, err := GetMemberDeploymentCount(clusterName)
+	deploymentCount, err := GetMemberDeploymentCount(c, clusterName)
 	if err != nil {
 		deploymentCount = 0
 	}
 
 	// Get member cluster resource status information
-	memberClusterStatus, err := GetMemberClusterStatus(clusterName)
+	memberClusterStatus, err := GetMemberClusterStatus(c, clusterName)
 	if err != nil {
 		// Don't fail completely, create an empty status
 		memberClusterStatus = &v1.MemberClusterStatus{
@@ -76,7 +76,7 @@ func HandleGetMemberOverview(c *gin.Context) {
 	}
 	
 	// Get namespace count
-	namespaceCount, err := GetMemberNamespaceCount(clusterName)
+	namespaceCount, err := GetMemberNamespaceCount(c, clusterName)
 	if err != nil {
 		namespaceCount = 0
 	}
@@ -138,11 +138,11 @@ func GetMemberArgoMetrics(c *gin.Context, clusterName string) (*v1.ArgoMetrics,
 }
 
 // GetMemberDeploymentCount returns the count of deployments in a specific member cluster
-func GetMemberDeploymentCount(clusterName string) (int, error) {
+func GetMemberDeploymentCount(c context.Context, clusterName string) (int, error) {
 	ctx := context.TODO()
 
 	// Get client for the member cluster
-	memberClient := client.InClusterClientForMemberCluster(clusterName)
+	memberClient := client.InClusterClientForMemberCluster(c, clusterName)
 	if memberClient == nil {
 		return 0, nil
 	}
@@ -165,11 +165,11 @@ func GetMemberDeploymentCount(clusterName string) (int, error) {
 }
 
 // GetMemberNodeSummary returns the node summary for a specific member cluster
-func GetMemberNodeSummary(clusterName string) (*v1.NodeSummary, error) {
+func GetMemberNodeSummary(c context.Context, clusterName string) (*v1.NodeSummary, error) {
 	ctx := context.TODO()
 
 	// Get client for the member cluster
-	memberClient := client.InClusterClientForMemberCluster(clusterName)
+	memberClient := client.InClusterClientForMemberCluster(c, clusterName)
 	if memberClient == nil {
 		return nil, fmt.Errorf("failed to get client for member cluster %s", clusterName)
 	}
@@ -200,11 +200,11 @@ func GetMemberNodeSummary(clusterName string) (*v1.NodeSummary, error) {
 }
 
 // GetMemberClusterStatus retrieves resource status information from a specific member cluster
-func GetMemberClusterStatus(clusterName string) (*v1.MemberClusterStatus, error) {
+func GetMemberClusterStatus(c context.Context, clusterName string) (*v1.MemberClusterStatus, error) {
 	ctx := context.TODO()
 
 	// Get client for the member cluster
-	memberClient := client.InClusterClientForMemberCluster(clusterName)
+	memberClient := client.InClusterClientForMemberCluster(c, clusterName)
 	if memberClient == nil {
 		return nil, fmt.Errorf("failed to get client for member cluster %s", clusterName)
 	}
@@ -312,11 +312,11 @@ func GetMemberClusterStatus(clusterName string) (*v1.MemberClusterStatus, error)
 }
 
 // GetMemberNamespaceCount returns the number of namespaces in a specific member cluster
-func GetMemberNamespaceCount(clusterName string) (int, error) {
+func GetMemberNamespaceCount(c context.Context, clusterName string) (int, error) {
 	ctx := context.TODO()
 	
 	// Get client for the member cluster
-	memberClient := client.InClusterClientForMemberCluster(clusterName)
+	memberClient := client.InClusterClientForMemberCluster(c, clusterName)
 	if memberClient == nil {
 		return 0, fmt.Errorf("failed to get client for member cluster %s", clusterName)
 	}
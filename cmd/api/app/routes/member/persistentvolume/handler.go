@@ -27,7 +27,7 @@ import (
 )
 
 func handleGetMemberPersistentVolumes(c *gin.Context) {
-	memberClient := client.InClusterClientForMemberCluster(c.Param("clustername"))
+	memberClient := client.InClusterClientForMemberCluster(c, c.Param("clustername"))
 	dataSelect := common.ParseDataSelectPathParameter(c)
 	result, err := persistentvolume.GetPersistentVolumeList(memberClient, dataSelect)
 	if err != nil {
@@ -38,7 +38,7 @@ func handleGetMemberPersistentVolumes(c *gin.Context) {
 }
 
 func handleGetMemberPersistentVolumeDetail(c *gin.Context) {
-	memberClient := client.InClusterClientForMemberCluster(c.Param("clustername"))
+	memberClient := client.InClusterClientForMemberCluster(c, c.Param("clustername"))
 	name := c.Param("name")
 
 	// Get persistent volume details
@@ -52,7 +52,7 @@ func handleGetMemberPersistentVolumeDetail(c *gin.Context) {
 }
 
 func handleGetMemberPersistentVolumeEvents(c *gin.Context) {
-	memberClient := client.InClusterClientForMemberCluster(c.Param("clustername"))
+	memberClient := client.InClusterClientForMemberCluster(c, c.Param("clustername"))
 	name := c.Param("name")
 	dataSelect := common.ParseDataSelectPathParameter(c)
 	result, err := event.GetResourceEvents(memberClient, dataSelect, "", name)
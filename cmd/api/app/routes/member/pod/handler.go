@@ -17,9 +17,17 @@ limitations under the License.
 package pod
 
 import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
 
 	"github.com/karmada-io/dashboard/cmd/api/app/router"
 	"github.com/karmada-io/dashboard/cmd/api/app/types/common"
@@ -29,7 +37,7 @@ import (
 
 // return a pods list
 func handleGetMemberPod(c *gin.Context) {
-	memberClient := client.InClusterClientForMemberCluster(c.Param("clustername"))
+	memberClient := client.InClusterClientForMemberCluster(c, c.Param("clustername"))
 	dataSelect := common.ParseDataSelectPathParameter(c)
 	nsQuery := common.ParseNamespacePathParameter(c)
 	result, err := pod.GetPodList(memberClient, nsQuery, dataSelect)
@@ -42,7 +50,7 @@ func handleGetMemberPod(c *gin.Context) {
 
 // return a pod detail
 func handleGetMemberPodDetail(c *gin.Context) {
-	memberClient := client.InClusterClientForMemberCluster(c.Param("clustername"))
+	memberClient := client.InClusterClientForMemberCluster(c, c.Param("clustername"))
 	namespace := c.Param("namespace")
 	name := c.Param("name")
 	result, err := pod.GetPodDetail(memberClient, namespace, name)
@@ -55,7 +63,7 @@ func handleGetMemberPodDetail(c *gin.Context) {
 
 // handleGetPodContainerLogs returns logs from a specific container in a pod with paging support
 func handleGetPodContainerLogs(c *gin.Context) {
-	memberClient := client.InClusterClientForMemberCluster(c.Param("clustername"))
+	memberClient := client.InClusterClientForMemberCluster(c, c.Param("clustername"))
 	namespace := c.Param("namespace")
 	name := c.Param("name")
 	container := c.Query("container")
@@ -100,10 +108,185 @@ func handleGetPodContainerLogs(c *gin.Context) {
 	})
 }
 
+// getLogPod returns the client of the member cluster and the pod of a log request.
+func getLogPod(c *gin.Context) (kubernetes.Interface, *corev1.Pod, error) {
+	clusterName := c.Param("clustername")
+	memberClient := client.InClusterClientForMemberCluster(c, clusterName)
+	if memberClient == nil {
+		return nil, nil, fmt.Errorf("failed to get client for cluster %s", clusterName)
+	}
+	target, err := memberClient.CoreV1().Pods(c.Param("namespace")).Get(c, c.Param("name"), metav1.GetOptions{})
+	if err != nil {
+		return nil, nil, err
+	}
+	return memberClient, target, nil
+}
+
+// getLogContainer returns the client, the pod and the single container a log search or
+// download reads, and the HTTP status of the error if there is one.
+func getLogContainer(c *gin.Context) (kubernetes.Interface, *corev1.Pod, string, pod.LogStreamOptions, int, error) {
+	opts, err := common.ParseLogStreamOptions(c)
+	if err != nil {
+		return nil, nil, "", opts, http.StatusBadRequest, err
+	}
+	if opts.AllContainers {
+		return nil, nil, "", opts, http.StatusBadRequest, fmt.Errorf("the log of a single container is read, allContainers is not supported")
+	}
+	opts.Follow = false
+	memberClient, target, err := getLogPod(c)
+	if err != nil {
+		return nil, nil, "", opts, http.StatusInternalServerError, err
+	}
+	containers, err := pod.LogContainers(target, opts)
+	if err != nil {
+		return nil, nil, "", opts, http.StatusBadRequest, err
+	}
+	return memberClient, target, containers[0], opts, http.StatusOK, nil
+}
+
+// handleSearchPodLogs searches the log of a container for the q query parameter, a substring
+// or with regex=true a regular expression, case-insensitively unless caseSensitive=true. Matching
+// lines are returned in groups with context lines around them and the character offsets of the
+// matches. It takes the container, previous, sinceSeconds, tailLines and timestamps parameters
+// of the log stream.
+func handleSearchPodLogs(c *gin.Context) {
+	search, err := common.ParseLogSearchOptions(c)
+	if err != nil {
+		common.FailWithStatus(c, err, http.StatusBadRequest)
+		return
+	}
+	if _, err := search.Compile(); err != nil {
+		common.FailWithStatus(c, err, http.StatusBadRequest)
+		return
+	}
+	memberClient, target, container, opts, status, err := getLogContainer(c)
+	if err != nil {
+		if status == http.StatusInternalServerError {
+			common.Fail(c, err)
+		} else {
+			common.FailWithStatus(c, err, status)
+		}
+		return
+	}
+
+	result, err := pod.SearchContainerLogs(c, memberClient, target.Namespace, target.Name, container, opts, search)
+	if err != nil {
+		klog.ErrorS(err, "Failed to search pod logs", "cluster", c.Param("clustername"), "namespace", target.Namespace, "pod", target.Name, "container", container)
+		common.Fail(c, err)
+		return
+	}
+	common.Success(c, gin.H{
+		"container": container,
+		"result":    result,
+	})
+}
+
+// handleDownloadPodLogs sends the log of a container as a file, gzip-compressed with gzip=true.
+// The log is copied as it is read rather than buffered. It takes the container, previous,
+// sinceSeconds, tailLines and timestamps parameters of the log stream.
+func handleDownloadPodLogs(c *gin.Context) {
+	compress := false
+	if value := c.Query("gzip"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			common.FailWithStatus(c, fmt.Errorf("invalid gzip parameter %q", value), http.StatusBadRequest)
+			return
+		}
+		compress = parsed
+	}
+	memberClient, target, container, opts, status, err := getLogContainer(c)
+	if err != nil {
+		if status == http.StatusInternalServerError {
+			common.Fail(c, err)
+		} else {
+			common.FailWithStatus(c, err, status)
+		}
+		return
+	}
+
+	logs, err := pod.OpenContainerLogs(c, memberClient, target.Namespace, target.Name, container, opts)
+	if err != nil {
+		common.Fail(c, err)
+		return
+	}
+	defer logs.Close()
+
+	filename := fmt.Sprintf("%s-%s.log", target.Name, container)
+	contentType := "text/plain; charset=utf-8"
+	if compress {
+		filename += ".gz"
+		contentType = "application/gzip"
+	}
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Status(http.StatusOK)
+
+	var w io.Writer = c.Writer
+	if compress {
+		gz := gzip.NewWriter(c.Writer)
+		defer gz.Close()
+		w = gz
+	}
+	if _, err := io.Copy(w, logs); err != nil {
+		klog.ErrorS(err, "Failed to send pod logs", "cluster", c.Param("clustername"), "namespace", target.Namespace, "pod", target.Name, "container", container)
+	}
+}
+
+// logLineBuffer is how many lines log streams may get ahead of a slow client
+const logLineBuffer = 1024
+
+// logEvent names the event of a log line: "log", or "error" when the stream of a container failed.
+func logEvent(line pod.LogLine) string {
+	if line.Error != "" {
+		return "error"
+	}
+	return "log"
+}
+
+// handleStreamPodLogs streams the logs of a pod as Server-Sent Events, or as WebSocket messages
+// when the client upgrades the connection. Lines are sent as "log" events tagged with their
+// container, and an "end" event follows the last one unless the client went away first.
+func handleStreamPodLogs(c *gin.Context) {
+	opts, err := common.ParseLogStreamOptions(c)
+	if err != nil {
+		common.FailWithStatus(c, err, http.StatusBadRequest)
+		return
+	}
+	clusterName := c.Param("clustername")
+	memberClient, target, err := getLogPod(c)
+	if err != nil {
+		common.Fail(c, err)
+		return
+	}
+	if _, err := pod.LogContainers(target, opts); err != nil {
+		common.FailWithStatus(c, err, http.StatusBadRequest)
+		return
+	}
+
+	stream, err := common.NewEventStream(c)
+	if err != nil {
+		klog.ErrorS(err, "Failed to start log stream", "cluster", clusterName, "namespace", target.Namespace, "pod", target.Name)
+		return
+	}
+	defer stream.Close()
+
+	lines := make(chan pod.LogLine, logLineBuffer)
+	go func() {
+		defer close(lines)
+		_ = pod.StreamPodLogs(stream.Context(), memberClient, clusterName, target, opts, lines)
+	}()
+	if common.ForwardEvents(stream, lines, logEvent) {
+		_ = stream.Send("end", gin.H{})
+	}
+}
+
 func init() {
 	r := router.MemberV1()
 	r.GET("/pod", handleGetMemberPod)
 	r.GET("/pod/:namespace", handleGetMemberPod)
 	r.GET("/pod/:namespace/:name", handleGetMemberPodDetail)
 	r.GET("/pod/:namespace/:name/logs", handleGetPodContainerLogs)
+	r.GET("/pod/:namespace/:name/logs/stream", handleStreamPodLogs)
+	r.GET("/pod/:namespace/:name/logs/search", handleSearchPodLogs)
+	r.GET("/pod/:namespace/:name/logs/download", handleDownloadPodLogs)
 }
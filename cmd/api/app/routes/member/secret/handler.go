@@ -14,7 +14,7 @@ func handleGetMemberSecrets(c *gin.Context) {
 	namespace := common.ParseNamespacePathParameter(c)
 	dataSelect := common.ParseDataSelectPathParameter(c)
 
-	memberClient := client.InClusterClientForMemberCluster(clusterName)
+	memberClient := client.InClusterClientForMemberCluster(c, clusterName)
 	result, err := secret.GetSecretList(memberClient, namespace, dataSelect)
 	if err != nil {
 		common.Fail(c, err)
@@ -29,7 +29,7 @@ func handleGetMemberSecretDetail(c *gin.Context) {
 	namespace := c.Param("namespace")
 	name := c.Param("name")
 
-	memberClient := client.InClusterClientForMemberCluster(clusterName)
+	memberClient := client.InClusterClientForMemberCluster(c, clusterName)
 	result, err := secret.GetSecretDetail(memberClient, namespace, name)
 	if err != nil {
 		common.Fail(c, err)
@@ -26,7 +26,7 @@ import (
 )
 
 func handleGetMemberStatefulSets(c *gin.Context) {
-	memberClient := client.InClusterClientForMemberCluster(c.Param("clustername"))
+	memberClient := client.InClusterClientForMemberCluster(c, c.Param("clustername"))
 	namespace := common.ParseNamespacePathParameter(c)
 	dataSelect := common.ParseDataSelectPathParameter(c)
 	result, err := statefulset.GetStatefulSetList(memberClient, namespace, dataSelect)
@@ -38,7 +38,7 @@ func handleGetMemberStatefulSets(c *gin.Context) {
 }
 
 func handleGetMemberStatefulSetDetail(c *gin.Context) {
-	memberClient := client.InClusterClientForMemberCluster(c.Param("clustername"))
+	memberClient := client.InClusterClientForMemberCluster(c, c.Param("clustername"))
 	namespace := c.Param("namespace")
 	name := c.Param("name")
 	result, err := statefulset.GetStatefulSetDetail(memberClient, namespace, name)
@@ -19,156 +19,65 @@ package unstructured
 import (
 	"context"
 	"fmt"
-	"strings"
+	"net/http"
 
 	"github.com/gin-gonic/gin"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
-	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/klog/v2"
 
 	"github.com/karmada-io/dashboard/cmd/api/app/router"
 	"github.com/karmada-io/dashboard/cmd/api/app/types/common"
 	"github.com/karmada-io/dashboard/pkg/client"
+	"github.com/karmada-io/dashboard/pkg/resource/diff"
 )
 
-const proxyURL = "/apis/cluster.karmada.io/v1alpha1/clusters/%s/proxy/"
-
 // setupMemberClient creates a dynamic client for the member cluster
 func setupMemberClient(c *gin.Context) (dynamic.Interface, error) {
-	memberConfig, err := client.GetMemberConfig()
-	if err != nil {
-		klog.ErrorS(err, "Failed to get member config")
-		return nil, fmt.Errorf("failed to get member config: %w", err)
-	}
-
-	karmadaConfig, _, err := client.GetKarmadaConfig()
-	if err != nil {
-		klog.ErrorS(err, "Failed to get karmada config")
-		return nil, fmt.Errorf("failed to get karmada config: %w", err)
-	}
-
 	clusterName := c.Param("clustername")
 	if clusterName == "" {
 		return nil, fmt.Errorf("cluster name is required")
 	}
+	return client.GetDynamicClientForMember(c, clusterName)
+}
 
-	memberConfig.Host = karmadaConfig.Host + fmt.Sprintf(proxyURL, clusterName)
-	klog.V(4).InfoS("Using member config", "host", memberConfig.Host)
+// resolveResource returns the REST mapping of the resource the request addresses, by a kind,
+// resource or short name in the kind parameter, or by a group, version and kind on the gvk routes,
+// where the core group is named "core".
+func resolveResource(c *gin.Context) (*meta.RESTMapping, error) {
+	return client.MemberMapping(c.Param("clustername"), c.Param("group"), c.Param("version"), c.Param("kind"))
+}
 
-	return dynamic.NewForConfig(memberConfig)
+// failResolve fails a request whose resource could not be resolved, with 404 if the cluster has
+// no such resource.
+func failResolve(c *gin.Context, err error) {
+	if meta.IsNoMatchError(err) || meta.IsAmbiguousError(err) {
+		common.FailWithStatus(c, err, http.StatusNotFound)
+		return
+	}
+	common.Fail(c, err)
 }
 
-// validateResourceParams validates the required resource parameters
-func validateResourceParams(kind, namespace, name string) error {
-	if kind == "" {
-		return fmt.Errorf("kind is required")
+// resourceClient returns the client of the resource of a request, in its namespace if the resource
+// is namespaced. It fails the request if the resource cannot be resolved.
+func resourceClient(c *gin.Context, dynamicClient dynamic.Interface, namespace string) (dynamic.ResourceInterface, *meta.RESTMapping, bool) {
+	mapping, err := resolveResource(c)
+	if err != nil {
+		klog.ErrorS(err, "Failed to resolve resource", "cluster", c.Param("clustername"), "kind", c.Param("kind"))
+		failResolve(c, err)
+		return nil, nil, false
 	}
-	if namespace == "" {
-		return fmt.Errorf("namespace is required")
+	if mapping.Scope.Name() != meta.RESTScopeNameNamespace {
+		return dynamicClient.Resource(mapping.Resource), mapping, true
 	}
-	if name == "" {
-		return fmt.Errorf("name is required")
+	if namespace == "" {
+		common.FailWithStatus(c, fmt.Errorf("namespace is required for %s", mapping.Resource.String()), http.StatusBadRequest)
+		return nil, nil, false
 	}
-	return nil
-}
-
-// getGroupVersionResource returns the GroupVersionResource for a given kind
-func getGroupVersionResource(kind string) schema.GroupVersionResource {
-	lowerKind := strings.ToLower(kind)
-	kindToGVR := map[string]schema.GroupVersionResource{
-		"deployment": {
-			Group:    "apps",
-			Version:  "v1",
-			Resource: "deployments",
-		},
-		"statefulset": {
-			Group:    "apps",
-			Version:  "v1",
-			Resource: "statefulsets",
-		},
-		"daemonset": {
-			Group:    "apps",
-			Version:  "v1",
-			Resource: "daemonsets",
-		},
-		"job": {
-			Group:    "batch",
-			Version:  "v1",
-			Resource: "jobs",
-		},
-		"cronjob": {
-			Group:    "batch",
-			Version:  "v1",
-			Resource: "cronjobs",
-		},
-		"service": {
-			Group:    "",
-			Version:  "v1",
-			Resource: "services",
-		},
-		"ingress": {
-			Group:    "networking.k8s.io",
-			Version:  "v1",
-			Resource: "ingresses",
-		},
-		"pod": {
-			Group:    "",
-			Version:  "v1",
-			Resource: "pods",
-		},
-		"replicaset": {
-			Group:    "apps",
-			Version:  "v1",
-			Resource: "replicasets",
-		},
-		"configmap": {
-			Group:    "",
-			Version:  "v1",
-			Resource: "configmaps",
-		},
-		"secret": {
-			Group:    "",
-			Version:  "v1",
-			Resource: "secrets",
-		},
-		"persistentvolume": {
-			Group:    "",
-			Version:  "v1",
-			Resource: "persistentvolumes",
-		},
-		// Add more mappings as needed
-	}
-
-	if gvr, ok := kindToGVR[lowerKind]; ok {
-		klog.V(4).InfoS("Using predefined GVR", "kind", kind, "group", gvr.Group, "version", gvr.Version, "resource", gvr.Resource)
-		return gvr
-	}
-
-	// Default to core v1 group
-	defaultGVR := schema.GroupVersionResource{
-		Group:    "",
-		Version:  "v1",
-		Resource: lowerKind + "s", // Simple pluralization, might need more complex logic
-	}
-	klog.V(4).InfoS("Using default GVR", "kind", kind, "group", defaultGVR.Group, "version", defaultGVR.Version, "resource", defaultGVR.Resource)
-	return defaultGVR
-}
-
-// isClusterScopedResource returns true if the resource is cluster-scoped (not namespaced)
-func isClusterScopedResource(kind string) bool {
-	// List of known cluster-scoped resources
-	clusterScopedResources := map[string]bool{
-		"persistentvolume": true,
-		"node":             true,
-		"clusterrole":      true,
-		"clusterrolebinding": true,
-		"storageclass":     true,
-		"namespace":        true,
-	}
-	
-	return clusterScopedResources[strings.ToLower(kind)]
+	return dynamicClient.Resource(mapping.Resource).Namespace(namespace), mapping, true
 }
 
 func handleGetResource(c *gin.Context) {
@@ -178,32 +87,17 @@ func handleGetResource(c *gin.Context) {
 		return
 	}
 
-	kind := c.Param("kind")
 	namespace := c.Param("namespace")
 	name := c.Param("name")
-
-	if err := validateResourceParams(kind, namespace, name); err != nil {
-		klog.ErrorS(err, "Invalid resource parameters")
-		common.Fail(c, err)
+	resource, mapping, ok := resourceClient(c, dynamicClient, namespace)
+	if !ok {
 		return
 	}
 
-	klog.V(4).InfoS("Getting resource", "kind", kind, "namespace", namespace, "name", name)
-
-	gvr := getGroupVersionResource(strings.ToLower(kind))
-	
-	var result *unstructured.Unstructured
-	
-	if isClusterScopedResource(kind) {
-		// Handle cluster-scoped resources without namespace
-		result, err = dynamicClient.Resource(gvr).Get(context.Background(), name, metav1.GetOptions{})
-	} else {
-		// Handle namespaced resources
-		result, err = dynamicClient.Resource(gvr).Namespace(namespace).Get(context.Background(), name, metav1.GetOptions{})
-	}
-	
+	klog.V(4).InfoS("Getting resource", "gvr", mapping.Resource, "namespace", namespace, "name", name)
+	result, err := resource.Get(context.Background(), name, metav1.GetOptions{})
 	if err != nil {
-		klog.ErrorS(err, "Failed to get resource", "kind", kind, "namespace", namespace, "name", name, "gvr", gvr)
+		klog.ErrorS(err, "Failed to get resource", "gvr", mapping.Resource, "namespace", namespace, "name", name)
 		common.Fail(c, err)
 		return
 	}
@@ -218,21 +112,16 @@ func handleDeleteResource(c *gin.Context) {
 		return
 	}
 
-	kind := c.Param("kind")
-	namespace := c.Param("namespace")
-	name := c.Param("name")
-
-	gvr := getGroupVersionResource(kind)
-	
-	if isClusterScopedResource(kind) {
-		// Handle cluster-scoped resources without namespace
-		err = dynamicClient.Resource(gvr).Delete(context.Background(), name, metav1.DeleteOptions{})
-	} else {
-		// Handle namespaced resources
-		err = dynamicClient.Resource(gvr).Namespace(namespace).Delete(context.Background(), name, metav1.DeleteOptions{})
-	}
-	
+	dryRun, err := common.ParseDryRun(c)
 	if err != nil {
+		common.FailWithStatus(c, err, http.StatusBadRequest)
+		return
+	}
+	resource, _, ok := resourceClient(c, dynamicClient, c.Param("namespace"))
+	if !ok {
+		return
+	}
+	if err := resource.Delete(context.Background(), c.Param("name"), metav1.DeleteOptions{DryRun: dryRun}); err != nil {
 		klog.ErrorS(err, "Failed to delete resource")
 		common.Fail(c, err)
 		return
@@ -247,28 +136,23 @@ func handlePutResource(c *gin.Context) {
 		return
 	}
 
-	kind := c.Param("kind")
-	namespace := c.Param("namespace")
-
 	var obj *unstructured.Unstructured
 	if err := c.ShouldBindJSON(&obj); err != nil {
 		klog.ErrorS(err, "Failed to bind JSON")
 		common.Fail(c, err)
 		return
 	}
+	dryRun, err := common.ParseDryRun(c)
+	if err != nil {
+		common.FailWithStatus(c, err, http.StatusBadRequest)
+		return
+	}
 
-	gvr := getGroupVersionResource(kind)
-	
-	var result *unstructured.Unstructured
-	
-	if isClusterScopedResource(kind) {
-		// Handle cluster-scoped resources without namespace
-		result, err = dynamicClient.Resource(gvr).Update(context.Background(), obj, metav1.UpdateOptions{})
-	} else {
-		// Handle namespaced resources
-		result, err = dynamicClient.Resource(gvr).Namespace(namespace).Update(context.Background(), obj, metav1.UpdateOptions{})
+	resource, _, ok := resourceClient(c, dynamicClient, c.Param("namespace"))
+	if !ok {
+		return
 	}
-	
+	result, err := resource.Update(context.Background(), obj, metav1.UpdateOptions{DryRun: dryRun})
 	if err != nil {
 		klog.ErrorS(err, "Failed to update resource")
 		common.Fail(c, err)
@@ -284,28 +168,23 @@ func handleCreateResource(c *gin.Context) {
 		return
 	}
 
-	kind := c.Param("kind")
-	namespace := c.Param("namespace")
-
 	var obj *unstructured.Unstructured
 	if err := c.ShouldBindJSON(&obj); err != nil {
 		klog.ErrorS(err, "Failed to bind JSON")
 		common.Fail(c, err)
 		return
 	}
+	dryRun, err := common.ParseDryRun(c)
+	if err != nil {
+		common.FailWithStatus(c, err, http.StatusBadRequest)
+		return
+	}
 
-	gvr := getGroupVersionResource(kind)
-	
-	var result *unstructured.Unstructured
-	
-	if isClusterScopedResource(kind) {
-		// Handle cluster-scoped resources without namespace
-		result, err = dynamicClient.Resource(gvr).Create(context.Background(), obj, metav1.CreateOptions{})
-	} else {
-		// Handle namespaced resources
-		result, err = dynamicClient.Resource(gvr).Namespace(namespace).Create(context.Background(), obj, metav1.CreateOptions{})
+	resource, _, ok := resourceClient(c, dynamicClient, c.Param("namespace"))
+	if !ok {
+		return
 	}
-	
+	result, err := resource.Create(context.Background(), obj, metav1.CreateOptions{DryRun: dryRun})
 	if err != nil {
 		klog.ErrorS(err, "Failed to create resource")
 		common.Fail(c, err)
@@ -314,16 +193,136 @@ func handleCreateResource(c *gin.Context) {
 	common.Success(c, result)
 }
 
+// applyTarget parses the manifest of a server-side apply or diff request and returns the client of
+// its resource. The manifest must be of the kind the path addresses.
+func applyTarget(c *gin.Context) (dynamic.ResourceInterface, *unstructured.Unstructured, bool) {
+	dynamicClient, err := setupMemberClient(c)
+	if err != nil {
+		common.Fail(c, err)
+		return nil, nil, false
+	}
+	namespace := c.Param("namespace")
+	obj, err := common.ParseObjectBody(c, namespace, c.Param("name"))
+	if err != nil {
+		common.FailWithStatus(c, err, http.StatusBadRequest)
+		return nil, nil, false
+	}
+	mapping, err := resolveResource(c)
+	if err != nil {
+		klog.ErrorS(err, "Failed to resolve resource", "cluster", c.Param("clustername"), "kind", c.Param("kind"))
+		failResolve(c, err)
+		return nil, nil, false
+	}
+	gvk := obj.GroupVersionKind()
+	if gvk.GroupKind() != mapping.GroupVersionKind.GroupKind() {
+		common.FailWithStatus(c, fmt.Errorf("the manifest is a %s, not a %s", gvk.GroupKind(), mapping.GroupVersionKind.GroupKind()), http.StatusBadRequest)
+		return nil, nil, false
+	}
+
+	// Apply in the version of the manifest, which may not be the preferred one
+	resource := dynamicClient.Resource(mapping.Resource.GroupResource().WithVersion(gvk.Version))
+	if mapping.Scope.Name() != meta.RESTScopeNameNamespace {
+		return resource, obj, true
+	}
+	if namespace == "" {
+		common.FailWithStatus(c, fmt.Errorf("namespace is required for %s", mapping.Resource.String()), http.StatusBadRequest)
+		return nil, nil, false
+	}
+	return resource.Namespace(namespace), obj, true
+}
+
+// handleApplyResource applies the JSON or YAML manifest of the body with server-side apply, as the
+// dashboard field manager. Conflicts with the fields of other managers fail the request unless
+// force=true. It supports dryRun=All, as the other writes do.
+func handleApplyResource(c *gin.Context) {
+	force, err := common.ParseForce(c)
+	if err != nil {
+		common.FailWithStatus(c, err, http.StatusBadRequest)
+		return
+	}
+	dryRun, err := common.ParseDryRun(c)
+	if err != nil {
+		common.FailWithStatus(c, err, http.StatusBadRequest)
+		return
+	}
+	resource, obj, ok := applyTarget(c)
+	if !ok {
+		return
+	}
+
+	result, err := resource.Apply(context.Background(), obj.GetName(), obj, client.ApplyOptions(force, dryRun))
+	if err != nil {
+		klog.ErrorS(err, "Failed to apply resource", "cluster", c.Param("clustername"), "kind", obj.GetKind(), "namespace", obj.GetNamespace(), "name", obj.GetName())
+		common.Fail(c, err)
+		return
+	}
+	common.Success(c, result)
+}
+
+// handleDiffResource returns the difference between the live resource and the resource the JSON or
+// YAML manifest of the body would make once applied, from a dry-run server-side apply. It takes
+// the force parameter of the apply.
+func handleDiffResource(c *gin.Context) {
+	force, err := common.ParseForce(c)
+	if err != nil {
+		common.FailWithStatus(c, err, http.StatusBadRequest)
+		return
+	}
+	resource, obj, ok := applyTarget(c)
+	if !ok {
+		return
+	}
+
+	live, err := resource.Get(context.Background(), obj.GetName(), metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		live = nil
+	} else if err != nil {
+		klog.ErrorS(err, "Failed to get resource", "cluster", c.Param("clustername"), "kind", obj.GetKind(), "namespace", obj.GetNamespace(), "name", obj.GetName())
+		common.Fail(c, err)
+		return
+	}
+	merged, err := resource.Apply(context.Background(), obj.GetName(), obj, client.ApplyOptions(force, []string{metav1.DryRunAll}))
+	if err != nil {
+		klog.ErrorS(err, "Failed to dry-run apply resource", "cluster", c.Param("clustername"), "kind", obj.GetKind(), "namespace", obj.GetNamespace(), "name", obj.GetName())
+		common.Fail(c, err)
+		return
+	}
+	result, err := diff.Objects(live, merged)
+	if err != nil {
+		common.Fail(c, err)
+		return
+	}
+	common.Success(c, result)
+}
+
 func init() {
 	r := router.MemberV1()
 	r.DELETE("/_raw/:kind/:namespace/:name", handleDeleteResource)
 	r.GET("/_raw/:kind/:namespace/:name", handleGetResource)
 	r.PUT("/_raw/:kind/:namespace/:name", handlePutResource)
 	r.POST("/_raw/:kind/:namespace", handleCreateResource)
-	
+
 	// Add routes for cluster-scoped resources
 	r.DELETE("/_raw/:kind/name/:name", handleDeleteResource)
 	r.GET("/_raw/:kind/name/:name", handleGetResource)
 	r.PUT("/_raw/:kind/name/:name", handlePutResource)
 	r.POST("/_raw/:kind", handleCreateResource)
+	r.PATCH("/_raw/:kind/:namespace/:name", handleApplyResource)
+	r.PATCH("/_raw/:kind/name/:name", handleApplyResource)
+	r.POST("/_raw/:kind/:namespace/:name/diff", handleDiffResource)
+	r.POST("/_raw/:kind/name/:name/diff", handleDiffResource)
+
+	// Resources addressed by group, version and kind, e.g. /_raw/gvk/apps/v1/Deployment/default/web
+	r.DELETE("/_raw/gvk/:group/:version/:kind/:namespace/:name", handleDeleteResource)
+	r.GET("/_raw/gvk/:group/:version/:kind/:namespace/:name", handleGetResource)
+	r.PUT("/_raw/gvk/:group/:version/:kind/:namespace/:name", handlePutResource)
+	r.POST("/_raw/gvk/:group/:version/:kind/:namespace", handleCreateResource)
+	r.DELETE("/_raw/gvk/:group/:version/:kind/name/:name", handleDeleteResource)
+	r.GET("/_raw/gvk/:group/:version/:kind/name/:name", handleGetResource)
+	r.PUT("/_raw/gvk/:group/:version/:kind/name/:name", handlePutResource)
+	r.POST("/_raw/gvk/:group/:version/:kind", handleCreateResource)
+	r.PATCH("/_raw/gvk/:group/:version/:kind/:namespace/:name", handleApplyResource)
+	r.PATCH("/_raw/gvk/:group/:version/:kind/name/:name", handleApplyResource)
+	r.POST("/_raw/gvk/:group/:version/:kind/:namespace/:name/diff", handleDiffResource)
+	r.POST("/_raw/gvk/:group/:version/:kind/name/:name/diff", handleDiffResource)
 }
@@ -22,6 +22,7 @@ import (
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -32,6 +33,7 @@ import (
 	"github.com/karmada-io/dashboard/cmd/api/app/types/common"
 	"github.com/karmada-io/dashboard/pkg/client"
 	"github.com/karmada-io/dashboard/pkg/common/errors"
+	"github.com/karmada-io/dashboard/pkg/resource/diff"
 )
 
 // validateResourceParams validates the required resource parameters
@@ -274,7 +276,12 @@ func HandleDeleteMgmtResource(c *gin.Context) {
 
 	gvr := getGroupVersionResource(kind)
 
-	deleteOptions := metav1.DeleteOptions{}
+	dryRun, err := common.ParseDryRun(c)
+	if err != nil {
+		common.Fail(c, errors.NewBadRequest(err.Error()))
+		return
+	}
+	deleteOptions := metav1.DeleteOptions{DryRun: dryRun}
 
 	// Determine if the resource is cluster-scoped or namespaced
 	if isClusterScopedResource(kind) {
@@ -310,6 +317,11 @@ func HandlePutMgmtResource(c *gin.Context) {
 		common.Fail(c, errors.NewBadRequest(err.Error()))
 		return
 	}
+	dryRun, err := common.ParseDryRun(c)
+	if err != nil {
+		common.Fail(c, errors.NewBadRequest(err.Error()))
+		return
+	}
 
 	// Create dynamic client for management cluster
 	dynamicClient, err := createDynamicClient()
@@ -326,9 +338,9 @@ func HandlePutMgmtResource(c *gin.Context) {
 
 	// Determine if the resource is cluster-scoped or namespaced
 	if isClusterScopedResource(kind) {
-		result, err = dynamicClient.Resource(gvr).Update(context.TODO(), obj, metav1.UpdateOptions{})
+		result, err = dynamicClient.Resource(gvr).Update(context.TODO(), obj, metav1.UpdateOptions{DryRun: dryRun})
 	} else {
-		result, err = dynamicClient.Resource(gvr).Namespace(namespace).Update(context.TODO(), obj, metav1.UpdateOptions{})
+		result, err = dynamicClient.Resource(gvr).Namespace(namespace).Update(context.TODO(), obj, metav1.UpdateOptions{DryRun: dryRun})
 	}
 
 	if err != nil {
@@ -357,6 +369,11 @@ func HandleCreateMgmtResource(c *gin.Context) {
 		common.Fail(c, errors.NewBadRequest(err.Error()))
 		return
 	}
+	dryRun, err := common.ParseDryRun(c)
+	if err != nil {
+		common.Fail(c, errors.NewBadRequest(err.Error()))
+		return
+	}
 
 	// Create dynamic client for management cluster
 	dynamicClient, err := createDynamicClient()
@@ -373,14 +390,14 @@ func HandleCreateMgmtResource(c *gin.Context) {
 
 	// Determine if the resource is cluster-scoped or namespaced
 	if isClusterScopedResource(kind) {
-		result, err = dynamicClient.Resource(gvr).Create(context.TODO(), obj, metav1.CreateOptions{})
+		result, err = dynamicClient.Resource(gvr).Create(context.TODO(), obj, metav1.CreateOptions{DryRun: dryRun})
 	} else {
 		if namespace == "" {
 			klog.Error("Namespace is required for namespaced resources")
 			common.Fail(c, errors.NewBadRequest("Namespace is required for namespaced resources"))
 			return
 		}
-		result, err = dynamicClient.Resource(gvr).Namespace(namespace).Create(context.TODO(), obj, metav1.CreateOptions{})
+		result, err = dynamicClient.Resource(gvr).Namespace(namespace).Create(context.TODO(), obj, metav1.CreateOptions{DryRun: dryRun})
 	}
 
 	if err != nil {
@@ -392,6 +409,103 @@ func HandleCreateMgmtResource(c *gin.Context) {
 	common.Success(c, result)
 }
 
+// mgmtApplyTarget parses the manifest of a server-side apply or diff request and returns the
+// client of its resource, in the version of the manifest.
+func mgmtApplyTarget(c *gin.Context) (dynamic.ResourceInterface, *unstructured.Unstructured, bool) {
+	kind := c.Param("kind")
+	namespace := c.Param("namespace")
+	if namespace == "" && !isClusterScopedResource(kind) {
+		common.Fail(c, errors.NewBadRequest("Namespace is required for namespaced resources"))
+		return nil, nil, false
+	}
+	obj, err := common.ParseObjectBody(c, namespace, c.Param("name"))
+	if err != nil {
+		common.Fail(c, errors.NewBadRequest(err.Error()))
+		return nil, nil, false
+	}
+
+	dynamicClient, err := createDynamicClient()
+	if err != nil {
+		klog.ErrorS(err, "Failed to create dynamic client")
+		common.Fail(c, errors.NewInternal(fmt.Sprintf("Failed to create client: %v", err)))
+		return nil, nil, false
+	}
+	gvr := getGroupVersionResource(kind)
+	if version := obj.GroupVersionKind().Version; version != "" {
+		gvr.Version = version
+	}
+	if isClusterScopedResource(kind) {
+		return dynamicClient.Resource(gvr), obj, true
+	}
+	return dynamicClient.Resource(gvr).Namespace(namespace), obj, true
+}
+
+// HandleApplyMgmtResource applies a JSON or YAML manifest in the management cluster with
+// server-side apply, as the dashboard field manager. Conflicts with the fields of other managers
+// fail the request unless force=true. It supports dryRun=All, as the other writes do.
+func HandleApplyMgmtResource(c *gin.Context) {
+	force, err := common.ParseForce(c)
+	if err != nil {
+		common.Fail(c, errors.NewBadRequest(err.Error()))
+		return
+	}
+	dryRun, err := common.ParseDryRun(c)
+	if err != nil {
+		common.Fail(c, errors.NewBadRequest(err.Error()))
+		return
+	}
+	resource, obj, ok := mgmtApplyTarget(c)
+	if !ok {
+		return
+	}
+
+	result, err := resource.Apply(context.TODO(), obj.GetName(), obj, client.ApplyOptions(force, dryRun))
+	if err != nil {
+		klog.ErrorS(err, "Failed to apply resource", "kind", obj.GetKind(), "namespace", obj.GetNamespace(), "name", obj.GetName())
+		common.Fail(c, errors.NewInternal(fmt.Sprintf("Failed to apply resource: %v", err)))
+		return
+	}
+
+	common.Success(c, result)
+}
+
+// HandleDiffMgmtResource returns the difference between a live resource of the management cluster
+// and the resource a JSON or YAML manifest would make once applied, from a dry-run server-side
+// apply. It takes the force parameter of the apply.
+func HandleDiffMgmtResource(c *gin.Context) {
+	force, err := common.ParseForce(c)
+	if err != nil {
+		common.Fail(c, errors.NewBadRequest(err.Error()))
+		return
+	}
+	resource, obj, ok := mgmtApplyTarget(c)
+	if !ok {
+		return
+	}
+
+	live, err := resource.Get(context.TODO(), obj.GetName(), metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		live = nil
+	} else if err != nil {
+		klog.ErrorS(err, "Failed to get resource", "kind", obj.GetKind(), "namespace", obj.GetNamespace(), "name", obj.GetName())
+		common.Fail(c, errors.NewInternal(fmt.Sprintf("Failed to get resource: %v", err)))
+		return
+	}
+	merged, err := resource.Apply(context.TODO(), obj.GetName(), obj, client.ApplyOptions(force, []string{metav1.DryRunAll}))
+	if err != nil {
+		klog.ErrorS(err, "Failed to dry-run apply resource", "kind", obj.GetKind(), "namespace", obj.GetNamespace(), "name", obj.GetName())
+		common.Fail(c, errors.NewInternal(fmt.Sprintf("Failed to apply resource: %v", err)))
+		return
+	}
+	result, err := diff.Objects(live, merged)
+	if err != nil {
+		common.Fail(c, errors.NewInternal(err.Error()))
+		return
+	}
+
+	common.Success(c, result)
+}
+
 func init() {
 	mgmtRouter := router.Mgmt()
 	{
@@ -400,6 +514,10 @@ func init() {
 		mgmtRouter.PUT("/_raw/:kind/:namespace/:name", HandlePutMgmtResource)
 		mgmtRouter.POST("/_raw/:kind/:namespace", HandleCreateMgmtResource)
 		mgmtRouter.POST("/_raw/:kind", HandleCreateMgmtResource) // For cluster-scoped resources
+		mgmtRouter.PATCH("/_raw/:kind/:namespace/:name", HandleApplyMgmtResource)
+		mgmtRouter.PATCH("/_raw/:kind/name/:name", HandleApplyMgmtResource) // For cluster-scoped resources
+		mgmtRouter.POST("/_raw/:kind/:namespace/:name/diff", HandleDiffMgmtResource)
+		mgmtRouter.POST("/_raw/:kind/name/:name/diff", HandleDiffMgmtResource) // For cluster-scoped resources
 	}
 	klog.InfoS("Registered management cluster unstructured resource routes")
 }
@@ -49,7 +49,7 @@ func handleGetOverview(c *gin.Context) {
 		common.Fail(c, err)
 		return
 	}
-	memberClusterStatus, err := GetMemberClusterInfo(dataSelect)
+	memberClusterStatus, err := GetMemberClusterInfo(c, dataSelect)
 	if err != nil {
 		common.Fail(c, err)
 		return
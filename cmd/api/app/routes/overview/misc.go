@@ -40,6 +40,7 @@ import (
 	"k8s.io/client-go/tools/remotecommand"
 	"k8s.io/klog/v2"
 
+	"github.com/karmada-io/dashboard/pkg/auth"
 	"github.com/karmada-io/dashboard/pkg/auth/fga"
 )
 
@@ -166,8 +167,8 @@ func GetControllerManagerInfo() (*v1.KarmadaInfo, error) {
 	return karmadaInfo, nil
 }
 
-// GetMemberClusterInfo returns the status of member clusters.
-func GetMemberClusterInfo(ds *dataselect.DataSelectQuery) (*v1.MemberClusterStatus, error) {
+// GetMemberClusterInfo returns the status of the member clusters the caller carried in ctx can access.
+func GetMemberClusterInfo(ctx context.Context, ds *dataselect.DataSelectQuery) (*v1.MemberClusterStatus, error) {
 	karmadaClient := client.InClusterKarmadaClient()
 	result, err := cluster.GetClusterList(karmadaClient, ds)
 	if err != nil {
@@ -180,8 +181,8 @@ func GetMemberClusterInfo(ds *dataselect.DataSelectQuery) (*v1.MemberClusterStat
 		PodSummary:    &v1.PodSummary{},
 	}
 
-	// Get the current user for permission checks
-	username := client.GetCurrentUser()
+	// Get the caller for permission checks
+	username := auth.UserFromContext(ctx)
 	var fgaClient fga.Client
 	if username != "" && fga.FGAService != nil && fga.FGAService.GetClient() != nil {
 		fgaClient = fga.FGAService.GetClient()
@@ -190,7 +191,7 @@ func GetMemberClusterInfo(ds *dataselect.DataSelectQuery) (*v1.MemberClusterStat
 	for _, clusterItem := range result.Clusters {
 		// Check if user has access to this cluster
 		if username != "" && fgaClient != nil {
-			allowed, err := fga.HasClusterAccess(context.Background(), fgaClient, username, clusterItem.ObjectMeta.Name)
+			allowed, err := fga.HasClusterAccess(ctx, fgaClient, username, clusterItem.ObjectMeta.Name)
 			if err != nil {
 				klog.ErrorS(err, "Failed to check cluster access", "user", username, "cluster", clusterItem.ObjectMeta.Name)
 				continue // Skip this cluster on error
@@ -0,0 +1,143 @@
+/*
+Copyright 2024 The Karmada Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package group
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"k8s.io/klog/v2"
+
+	"github.com/karmada-io/dashboard/cmd/api/app/router"
+	v1 "github.com/karmada-io/dashboard/cmd/api/app/types/api/v1"
+	"github.com/karmada-io/dashboard/cmd/api/app/types/common"
+	"github.com/karmada-io/dashboard/pkg/resource/setting"
+)
+
+// handleGetGroups lists all groups
+func handleGetGroups(c *gin.Context) {
+	groups, err := setting.ListGroups(c)
+	if err != nil {
+		klog.ErrorS(err, "ListGroups failed")
+		common.Fail(c, err)
+		return
+	}
+	common.Success(c, groups)
+}
+
+// handleGetGroup retrieves a single group
+func handleGetGroup(c *gin.Context) {
+	name := c.Param("name")
+	group, err := setting.GetGroup(c, name)
+	if err != nil {
+		klog.ErrorS(err, "GetGroup failed", "group", name)
+		common.Fail(c, err)
+		return
+	}
+	common.Success(c, group)
+}
+
+// handlePostGroup creates a group
+func handlePostGroup(c *gin.Context) {
+	request := new(v1.CreateGroupRequest)
+	if err := c.ShouldBindJSON(request); err != nil {
+		klog.ErrorS(err, "Could not read create group request")
+		common.FailWithStatus(c, err, http.StatusBadRequest)
+		return
+	}
+
+	group, err := setting.CreateGroup(c, *request)
+	if err != nil {
+		klog.ErrorS(err, "CreateGroup failed", "group", request.Name)
+		common.Fail(c, err)
+		return
+	}
+	common.Success(c, group)
+}
+
+// handlePutGroup updates a group
+func handlePutGroup(c *gin.Context) {
+	name := c.Param("name")
+	request := new(v1.UpdateGroupRequest)
+	if err := c.ShouldBindJSON(request); err != nil {
+		klog.ErrorS(err, "Could not read update group request")
+		common.FailWithStatus(c, err, http.StatusBadRequest)
+		return
+	}
+
+	group, err := setting.UpdateGroup(c, name, *request)
+	if err != nil {
+		klog.ErrorS(err, "UpdateGroup failed", "group", name)
+		common.Fail(c, err)
+		return
+	}
+	common.Success(c, group)
+}
+
+// handleDeleteGroup deletes a group and revokes everything granted to it
+func handleDeleteGroup(c *gin.Context) {
+	name := c.Param("name")
+	if err := setting.DeleteGroup(c, name); err != nil {
+		klog.ErrorS(err, "DeleteGroup failed", "group", name)
+		common.Fail(c, err)
+		return
+	}
+	common.Success(c, "Group deleted successfully")
+}
+
+// handlePostGroupMembers adds users to a group
+func handlePostGroupMembers(c *gin.Context) {
+	name := c.Param("name")
+	request := new(v1.GroupMembersRequest)
+	if err := c.ShouldBindJSON(request); err != nil {
+		klog.ErrorS(err, "Could not read group members request")
+		common.FailWithStatus(c, err, http.StatusBadRequest)
+		return
+	}
+
+	group, err := setting.AddGroupMembers(c, name, request.Members)
+	if err != nil {
+		klog.ErrorS(err, "AddGroupMembers failed", "group", name)
+		common.Fail(c, err)
+		return
+	}
+	common.Success(c, group)
+}
+
+// handleDeleteGroupMember removes a user from a group
+func handleDeleteGroupMember(c *gin.Context) {
+	name := c.Param("name")
+	username := c.Param("username")
+	if err := setting.RemoveGroupMember(c, name, username); err != nil {
+		klog.ErrorS(err, "RemoveGroupMember failed", "group", name, "username", username)
+		common.Fail(c, err)
+		return
+	}
+	common.Success(c, "Group member removed successfully")
+}
+
+func init() {
+	r := router.V1().Group("/setting/groups")
+	r.Use(router.EnsureDashboardAdminMiddleware())
+	r.GET("", handleGetGroups)
+	r.POST("", handlePostGroup)
+	r.GET("/:name", handleGetGroup)
+	r.PUT("/:name", handlePutGroup)
+	r.DELETE("/:name", handleDeleteGroup)
+	r.POST("/:name/members", handlePostGroupMembers)
+	r.DELETE("/:name/members/:username", handleDeleteGroupMember)
+}
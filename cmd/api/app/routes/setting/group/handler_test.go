@@ -0,0 +1,132 @@
+/*
+Copyright 2024 The Karmada Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package group
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/karmada-io/dashboard/cmd/api/app/router"
+	v1 "github.com/karmada-io/dashboard/cmd/api/app/types/api/v1"
+	"github.com/karmada-io/dashboard/pkg/auth"
+	"github.com/karmada-io/dashboard/pkg/auth/fga"
+	"github.com/karmada-io/dashboard/pkg/etcd"
+	"github.com/karmada-io/dashboard/pkg/etcd/etcdtest"
+)
+
+// newTestRouter serves the group routes as registered in init, the caller being taken from the
+// X-Test-User header.
+func newTestRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	r := engine.Group("/api/v1/setting/groups", func(c *gin.Context) {
+		if username := c.GetHeader("X-Test-User"); username != "" {
+			c.Request = c.Request.WithContext(auth.WithUser(c.Request.Context(), username))
+		}
+	})
+	r.Use(router.EnsureDashboardAdminMiddleware())
+	r.GET("", handleGetGroups)
+	r.POST("", handlePostGroup)
+	r.GET("/:name", handleGetGroup)
+	r.PUT("/:name", handlePutGroup)
+	r.DELETE("/:name", handleDeleteGroup)
+	r.POST("/:name/members", handlePostGroupMembers)
+	r.DELETE("/:name/members/:username", handleDeleteGroupMember)
+	return engine
+}
+
+func TestGroupRoutes(t *testing.T) {
+	etcdClient := etcdtest.NewClient()
+	etcd.SetEtcdClient(etcdClient)
+	fga.FGAService = fga.NewServiceWithClient(fga.NewFakeClient(
+		fga.Tuple{User: "root", Relation: fga.RelationAdmin, ObjectType: fga.TypeDashboard, ObjectID: fga.TypeDashboard},
+	))
+	t.Cleanup(func() {
+		etcd.SetEtcdClient(nil)
+		fga.FGAService = nil
+	})
+	userManager := etcd.NewUserManager(etcdClient)
+	for _, username := range []string{"alice", "bob"} {
+		if err := userManager.CreateUser(context.Background(), username, "password", username+"@example.com", "user"); err != nil {
+			t.Fatalf("CreateUser %s: %v", username, err)
+		}
+	}
+
+	cases := []struct {
+		method          string
+		path            string
+		user            string
+		body            string
+		expectedStatus  int
+		expectedCode    int
+		expectedMembers []string
+	}{
+		{http.MethodGet, "/api/v1/setting/groups", "", "", http.StatusUnauthorized, http.StatusUnauthorized, nil},
+		{http.MethodGet, "/api/v1/setting/groups", "alice", "", http.StatusForbidden, http.StatusForbidden, nil},
+		{http.MethodPost, "/api/v1/setting/groups", "root", `{"name":`, http.StatusBadRequest, http.StatusInternalServerError, nil},
+		{http.MethodPost, "/api/v1/setting/groups", "root", `{"name":"sre","members":["alice"]}`, http.StatusOK, http.StatusOK, []string{"alice"}},
+		{http.MethodPost, "/api/v1/setting/groups", "root", `{"name":"sre"}`, http.StatusOK, http.StatusInternalServerError, nil},
+		{http.MethodGet, "/api/v1/setting/groups/sre", "root", "", http.StatusOK, http.StatusOK, []string{"alice"}},
+		{http.MethodPost, "/api/v1/setting/groups/sre/members", "root", `{}`, http.StatusBadRequest, http.StatusInternalServerError, nil},
+		{http.MethodPost, "/api/v1/setting/groups/sre/members", "root", `{"members":["bob"]}`, http.StatusOK, http.StatusOK, []string{"alice", "bob"}},
+		{http.MethodDelete, "/api/v1/setting/groups/sre/members/alice", "root", "", http.StatusOK, http.StatusOK, nil},
+		{http.MethodPut, "/api/v1/setting/groups/sre", "root", `{"description":"on call"}`, http.StatusOK, http.StatusOK, []string{"bob"}},
+		{http.MethodPut, "/api/v1/setting/groups/sre", "alice", `{"members":["alice"]}`, http.StatusForbidden, http.StatusForbidden, nil},
+		{http.MethodDelete, "/api/v1/setting/groups/sre", "root", "", http.StatusOK, http.StatusOK, nil},
+		{http.MethodGet, "/api/v1/setting/groups/sre", "root", "", http.StatusOK, http.StatusInternalServerError, nil},
+	}
+
+	engine := newTestRouter()
+	for _, c := range cases {
+		req := httptest.NewRequest(c.method, c.path, strings.NewReader(c.body))
+		req.Header.Set("Content-Type", "application/json")
+		if c.user != "" {
+			req.Header.Set("X-Test-User", c.user)
+		}
+		recorder := httptest.NewRecorder()
+		engine.ServeHTTP(recorder, req)
+
+		var response struct {
+			Code int             `json:"code"`
+			Data json.RawMessage `json:"data"`
+		}
+		if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+			t.Fatalf("%s %s: failed to decode response %q: %v", c.method, c.path, recorder.Body.String(), err)
+		}
+		if recorder.Code != c.expectedStatus || response.Code != c.expectedCode {
+			t.Errorf("%s %s as %q: got status %d code %d, expected %d and %d: %s", c.method, c.path, c.user,
+				recorder.Code, response.Code, c.expectedStatus, c.expectedCode, recorder.Body.String())
+			continue
+		}
+		if c.expectedMembers != nil {
+			var group v1.Group
+			if err := json.Unmarshal(response.Data, &group); err != nil {
+				t.Fatalf("%s %s: failed to decode group: %v", c.method, c.path, err)
+			}
+			if !slices.Equal(group.Members, c.expectedMembers) {
+				t.Errorf("%s %s: got members %v, expected %v", c.method, c.path, group.Members, c.expectedMembers)
+			}
+		}
+	}
+}
@@ -19,6 +19,7 @@ package user
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"strings"
 	"time"
 
@@ -300,6 +301,46 @@ func getAuthenticatedUser(c *gin.Context) string {
 	return claims.Username // Fallback
 }
 
+// handleUnlockUser lifts the lockout of a user
+func handleUnlockUser(c *gin.Context) {
+	username := c.Param("username")
+	if err := auth.UnlockUser(c, username); err != nil {
+		klog.ErrorS(err, "UnlockUser failed", "username", username)
+		common.Fail(c, err)
+		return
+	}
+	common.Success(c, "User unlocked successfully")
+}
+
+// handleResetUserPassword sets a temporary password that the user has to change on the next login
+func handleResetUserPassword(c *gin.Context) {
+	username := c.Param("username")
+	request := new(v1.ResetPasswordRequest)
+	if err := c.ShouldBindJSON(request); err != nil {
+		klog.ErrorS(err, "Could not read reset password request")
+		common.FailWithStatus(c, err, http.StatusBadRequest)
+		return
+	}
+
+	if err := auth.ResetPassword(c, username, request.Password); err != nil {
+		klog.ErrorS(err, "ResetPassword failed", "username", username)
+		common.Fail(c, err)
+		return
+	}
+	common.Success(c, "Password reset successfully")
+}
+
+// handleResetUserTwoFactor removes the two-factor authentication of a user who lost their device
+func handleResetUserTwoFactor(c *gin.Context) {
+	username := c.Param("username")
+	if err := auth.ResetTwoFactor(c, username); err != nil {
+		klog.ErrorS(err, "ResetTwoFactor failed", "username", username)
+		common.Fail(c, err)
+		return
+	}
+	common.Success(c, "Two-factor authentication reset successfully")
+}
+
 func init() {
 	r := router.V1()
 	r.GET("/setting/user", handleGetUserSetting)
@@ -307,4 +348,10 @@ func init() {
 	r.PUT("/setting/user", handlePutUserSetting)
 	r.DELETE("/setting/user", handleDeleteUserSetting)
 	r.GET("/setting/users", handleGetAllUsers)
+
+	admin := r.Group("/setting/users/:username")
+	admin.Use(router.EnsureDashboardAdminMiddleware())
+	admin.POST("/unlock", handleUnlockUser)
+	admin.POST("/reset-password", handleResetUserPassword)
+	admin.DELETE("/2fa", handleResetUserTwoFactor)
 }
@@ -21,6 +21,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -35,7 +37,10 @@ import (
 
 	"github.com/karmada-io/dashboard/cmd/api/app/router"
 	"github.com/karmada-io/dashboard/cmd/api/app/types/common"
+	"github.com/karmada-io/dashboard/pkg/auth"
+	"github.com/karmada-io/dashboard/pkg/auth/fga"
 	"github.com/karmada-io/dashboard/pkg/client"
+	"github.com/karmada-io/dashboard/pkg/recording"
 )
 
 // TerminalMessage represents the message structure for terminal communication
@@ -52,6 +57,23 @@ type TerminalSession struct {
 	sizeChan   chan remotecommand.TerminalSize
 	doneChan   chan struct{}
 	clientGone chan struct{}
+	// recording records the session, nil if it is not recorded
+	recording *recording.Recording
+	// writeMu serializes writes to the connection once the session is streaming
+	writeMu sync.Mutex
+	// lastActivity is when the user last typed or resized the terminal, in Unix nanoseconds
+	lastActivity atomic.Int64
+}
+
+// idleTimeout closes sessions nothing was typed in for that long, 0 keeps them open.
+var idleTimeout time.Duration
+
+// idleCheckInterval is how often sessions are checked for inactivity
+const idleCheckInterval = 10 * time.Second
+
+// SetIdleTimeout sets after how long without input terminal sessions are closed, 0 disables it.
+func SetIdleTimeout(timeout time.Duration) {
+	idleTimeout = timeout
 }
 
 // upgrader configures the websocket connection
@@ -81,8 +103,17 @@ func (t *TerminalSession) Read(p []byte) (int, error) {
 
 	switch msg.Operation {
 	case "stdin":
-		return copy(p, msg.Data), nil
+		t.lastActivity.Store(time.Now().UnixNano())
+		n := copy(p, msg.Data)
+		if t.recording != nil {
+			t.recording.Input(p[:n])
+		}
+		return n, nil
 	case "resize":
+		t.lastActivity.Store(time.Now().UnixNano())
+		if t.recording != nil {
+			t.recording.Resize(msg.Cols, msg.Rows)
+		}
 		t.sizeChan <- remotecommand.TerminalSize{Width: msg.Cols, Height: msg.Rows}
 		return 0, nil
 	case "ping":
@@ -100,10 +131,15 @@ func (t *TerminalSession) Write(p []byte) (int, error) {
 		Data:      string(p),
 	}
 
+	t.writeMu.Lock()
+	defer t.writeMu.Unlock()
 	if err := t.wsConn.WriteJSON(msg); err != nil {
 		klog.V(4).Infof("write message err: %v", err)
 		return 0, err
 	}
+	if t.recording != nil {
+		t.recording.Output(p)
+	}
 	return len(p), nil
 }
 
@@ -122,9 +158,82 @@ func (t *TerminalSession) Done() {
 	close(t.doneChan)
 }
 
+// notify shows a message to the user without recording it.
+func (t *TerminalSession) notify(data string) {
+	t.writeMu.Lock()
+	defer t.writeMu.Unlock()
+	_ = t.wsConn.WriteJSON(TerminalMessage{
+		Operation: "stdout",
+		Data:      data,
+	})
+}
+
+// closeWhenIdle closes the connection once nothing was typed for timeout, which ends the session.
+// Pings keep the connection alive but do not count as activity.
+func (t *TerminalSession) closeWhenIdle(timeout time.Duration) {
+	if timeout <= 0 {
+		return
+	}
+	t.lastActivity.Store(time.Now().UnixNano())
+	go func() {
+		ticker := time.NewTicker(min(idleCheckInterval, timeout))
+		defer ticker.Stop()
+		for {
+			select {
+			case <-t.doneChan:
+				return
+			case <-ticker.C:
+				if time.Since(time.Unix(0, t.lastActivity.Load())) < timeout {
+					continue
+				}
+				klog.InfoS("Closing idle terminal session", "timeout", timeout)
+				t.notify(fmt.Sprintf("\r\nSession closed after %s without input\r\n", timeout))
+				t.writeMu.Lock()
+				_ = t.wsConn.WriteControl(websocket.CloseMessage,
+					websocket.FormatCloseMessage(websocket.CloseNormalClosure, "idle timeout"), time.Now().Add(time.Second))
+				t.writeMu.Unlock()
+				_ = t.wsConn.Close()
+				return
+			}
+		}
+	}()
+}
+
 // endOfTransmission is sent when the connection is closed
 var endOfTransmission = []byte{4}
 
+// authorizeExec checks that the caller may exec into the pods of a namespace of a cluster: exec
+// requires the operator relation on the pods of the namespace, and dashboard admin on the
+// management cluster. It returns the HTTP status to fail the request with otherwise.
+func authorizeExec(c *gin.Context, clusterName, namespace string) (int, error) {
+	if clusterName == "" {
+		return http.StatusBadRequest, fmt.Errorf("cluster parameter is required")
+	}
+	username := auth.UserFromContext(c)
+	if username == "" {
+		return http.StatusUnauthorized, fmt.Errorf("authentication required for terminal access")
+	}
+	if fga.FGAService == nil || fga.FGAService.GetClient() == nil {
+		return http.StatusInternalServerError, fmt.Errorf("authorization service unavailable")
+	}
+
+	var allowed bool
+	var err error
+	if clusterName == "mgmt-cluster" {
+		allowed, err = fga.IsDashboardAdmin(c, fga.FGAService.GetClient(), username)
+	} else {
+		allowed, err = fga.HasResourcePermission(c, fga.FGAService.GetClient(), username, fga.RelationOperator, clusterName, namespace, "pod")
+	}
+	if err != nil {
+		klog.ErrorS(err, "Failed to check terminal access", "username", username, "cluster", clusterName, "namespace", namespace)
+		return http.StatusInternalServerError, fmt.Errorf("failed to verify terminal permissions")
+	}
+	if !allowed {
+		return http.StatusForbidden, fmt.Errorf("user %s is not allowed to exec into pods of namespace %s in cluster %s", username, namespace, clusterName)
+	}
+	return http.StatusOK, nil
+}
+
 // handleTerminalConnection handles WebSocket connections for terminal access
 func handleTerminalConnection(c *gin.Context) {
 	klog.Infof("Terminal connection request received from %s", c.ClientIP())
@@ -142,6 +251,13 @@ func handleTerminalConnection(c *gin.Context) {
 		common.Fail(c, fmt.Errorf("namespace and pod parameters are required"))
 		return
 	}
+	if status, err := authorizeExec(c, clusterName, namespace); err != nil {
+		klog.InfoS("Terminal connection denied", "username", auth.UserFromContext(c), "cluster", clusterName, "namespace", namespace, "reason", err.Error())
+		common.FailWithStatus(c, err, status)
+		return
+	}
+	// The caller may hold the operator relation on the namespace only, not on the whole cluster
+	c.Request = c.Request.WithContext(auth.WithAuthorizedCluster(c.Request.Context(), clusterName))
 
 	// Upgrade HTTP connection to WebSocket
 	wsConn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
@@ -175,7 +291,7 @@ func handleTerminalConnection(c *gin.Context) {
 		}
 	} else if clusterName != "" {
 		// Use member cluster client
-		k8sClient = client.InClusterClientForMemberCluster(clusterName)
+		k8sClient = client.InClusterClientForMemberCluster(c, clusterName)
 		if k8sClient == nil {
 			klog.Errorf("Failed to get member cluster client for %s", clusterName)
 			session.wsConn.WriteJSON(TerminalMessage{
@@ -238,6 +354,31 @@ func handleTerminalConnection(c *gin.Context) {
 		return
 	}
 
+	// Record the session if requested or required by a policy
+	rec, policy, err := startSessionRecording(c, recording.Metadata{
+		Cluster:   clusterName,
+		Namespace: namespace,
+		Pod:       podName,
+		Container: containerName,
+		Command:   []string{shell},
+	})
+	if err != nil {
+		klog.Errorf("Failed to record terminal session: %v", err)
+		session.wsConn.WriteJSON(TerminalMessage{
+			Operation: "stdout",
+			Data:      fmt.Sprintf("Error: %v\r\n", err),
+		})
+		return
+	}
+	if rec != nil {
+		session.recording = rec
+		defer rec.Close()
+		session.wsConn.WriteJSON(TerminalMessage{
+			Operation: "stdout",
+			Data:      recordingNotice(rec, policy),
+		})
+	}
+
 	// Send connection success message
 	session.wsConn.WriteJSON(TerminalMessage{
 		Operation: "stdout",
@@ -271,8 +412,20 @@ func handleTerminalConnection(c *gin.Context) {
 		return
 	}
 
+	// Stop streaming when the client is gone, including when the session is closed for inactivity
+	streamCtx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-clientGone:
+			cancel()
+		case <-streamCtx.Done():
+		}
+	}()
+	session.closeWhenIdle(idleTimeout)
+
 	// Start the exec session
-	err = executor.Stream(remotecommand.StreamOptions{
+	err = executor.StreamWithContext(streamCtx, remotecommand.StreamOptions{
 		Stdin:             session,
 		Stdout:            session,
 		Stderr:            session,
@@ -282,10 +435,7 @@ func handleTerminalConnection(c *gin.Context) {
 
 	if err != nil {
 		klog.Errorf("Stream error: %v", err)
-		session.wsConn.WriteJSON(TerminalMessage{
-			Operation: "stdout",
-			Data:      fmt.Sprintf("Connection closed: %v\r\n", err),
-		})
+		session.notify(fmt.Sprintf("Connection closed: %v\r\n", err))
 	}
 }
 
@@ -330,7 +480,7 @@ func handleNodeTerminalConnection(c *gin.Context) {
 			return
 		}
 	} else if clusterName != "" {
-		k8sClient = client.InClusterClientForMemberCluster(clusterName)
+		k8sClient = client.InClusterClientForMemberCluster(c, clusterName)
 		if k8sClient == nil {
 			klog.Errorf("Failed to get member cluster client for %s", clusterName)
 			session.wsConn.WriteJSON(TerminalMessage{
@@ -350,41 +500,46 @@ func handleNodeTerminalConnection(c *gin.Context) {
 		return
 	}
 
-	podName := fmt.Sprintf("node-shell-%s-%s", nodeName, common.GenerateName())
-	namespace := "default"
-	image := "ubuntu"
-
-	shellPod := &corev1.Pod{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      podName,
-			Namespace: namespace,
-		},
-		Spec: corev1.PodSpec{
-			NodeName:      nodeName,
-			HostPID:       true,
-			HostIPC:       true,
-			HostNetwork:   true,
-			RestartPolicy: corev1.RestartPolicyNever,
-			Tolerations: []corev1.Toleration{
-				{
-					Operator: corev1.TolerationOpExists,
-				},
-			},
-			Containers: []corev1.Container{
-				{
-					Name:    "shell",
-					Image:   image,
-					Command: []string{"sleep", "3600"},
-					SecurityContext: &corev1.SecurityContext{
-						Privileged: &[]bool{true}[0],
-					},
-					Stdin: true,
-					TTY:   true,
-				},
-			},
-		},
+	shellConfig, err := getNodeShellConfig(c)
+	if err != nil {
+		klog.Errorf("Failed to get node shell settings: %v", err)
+		session.wsConn.WriteJSON(TerminalMessage{
+			Operation: "stdout",
+			Data:      fmt.Sprintf("Error: Failed to get node shell settings: %v\r\n", err),
+		})
+		return
+	}
+	settings := shellConfig.Settings(clusterName)
+	podName := fmt.Sprintf("%s%s-%s", nodeShellPodPrefix, nodeName, common.GenerateName())
+	namespace := settings.Namespace
+
+	// Record the session if requested or required by a policy, before creating the shell pod
+	rec, policy, err := startSessionRecording(c, recording.Metadata{
+		Cluster:   clusterName,
+		Namespace: namespace,
+		Pod:       podName,
+		Node:      nodeName,
+		Command:   []string{shell},
+	})
+	if err != nil {
+		klog.Errorf("Failed to record node terminal session: %v", err)
+		session.wsConn.WriteJSON(TerminalMessage{
+			Operation: "stdout",
+			Data:      fmt.Sprintf("Error: %v\r\n", err),
+		})
+		return
+	}
+	if rec != nil {
+		session.recording = rec
+		defer rec.Close()
+		session.wsConn.WriteJSON(TerminalMessage{
+			Operation: "stdout",
+			Data:      recordingNotice(rec, policy),
+		})
 	}
 
+	shellPod := nodeShellPod(podName, nodeName, auth.UserFromContext(c), settings, time.Now())
+
 	ctx := context.Background()
 	_, err = k8sClient.CoreV1().Pods(namespace).Create(ctx, shellPod, metav1.CreateOptions{})
 	if err != nil {
@@ -399,7 +554,12 @@ func handleNodeTerminalConnection(c *gin.Context) {
 	// Use a context that is cancelled when the client disconnects
 	requestCtx := c.Request.Context()
 
+	// Keep the pod from being collected while the session lasts
+	heartbeatCtx, stopHeartbeat := context.WithCancel(context.Background())
+	go keepNodeShellAlive(heartbeatCtx, k8sClient, namespace, podName)
+
 	defer func() {
+		stopHeartbeat()
 		klog.Infof("Cleaning up shell pod %s", podName)
 		deleteCtx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
 		defer cancel()
@@ -433,7 +593,7 @@ func handleNodeTerminalConnection(c *gin.Context) {
 		SubResource("exec")
 
 	req.VersionedParams(&corev1.PodExecOptions{
-		Container: "shell",
+		Container: nodeShellContainer,
 		Command: []string{
 			"nsenter",
 			"--target", "1",
@@ -474,6 +634,7 @@ func handleNodeTerminalConnection(c *gin.Context) {
 			cancel()
 		}
 	}()
+	session.closeWhenIdle(idleTimeout)
 
 	err = executor.StreamWithContext(streamCtx, remotecommand.StreamOptions{
 		Stdin:             session,
@@ -484,10 +645,7 @@ func handleNodeTerminalConnection(c *gin.Context) {
 	})
 	if err != nil {
 		klog.Errorf("Stream error: %v", err)
-		session.wsConn.WriteJSON(TerminalMessage{
-			Operation: "stdout",
-			Data:      fmt.Sprintf("Connection closed: %v\r\n", err),
-		})
+		session.notify(fmt.Sprintf("Connection closed: %v\r\n", err))
 	}
 }
 
@@ -515,7 +673,7 @@ func waitForPodRunning(ctx context.Context, clientset kubernetes.Interface, name
 			if pod.Status.Phase == corev1.PodRunning {
 				// Check if container is ready
 				for _, status := range pod.Status.ContainerStatuses {
-					if status.Name == "shell" && status.Ready {
+					if status.Name == nodeShellContainer && status.Ready {
 						return nil
 					}
 				}
@@ -533,6 +691,8 @@ func waitForPodRunning(ctx context.Context, clientset kubernetes.Interface, name
 
 func init() {
 	r := router.V1()
+	// Exec requires operator on the pods of the namespace, see authorizeExec
 	r.GET("/terminal", handleTerminalConnection)
-	r.GET("/node-terminal", handleNodeTerminalConnection)
+	// Node shells run privileged in the host namespaces, only dashboard admins may open them
+	r.GET("/node-terminal", router.EnsureDashboardAdminMiddleware(), handleNodeTerminalConnection)
 }
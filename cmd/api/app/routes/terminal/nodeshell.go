@@ -0,0 +1,465 @@
+/*
+Copyright 2024 The Karmada Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package terminal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+
+	"github.com/karmada-io/dashboard/cmd/api/app/router"
+	"github.com/karmada-io/dashboard/cmd/api/app/types/common"
+	"github.com/karmada-io/dashboard/pkg/auth"
+	"github.com/karmada-io/dashboard/pkg/client"
+)
+
+const (
+	// nodeShellConfigMap holds the node shell settings, per cluster
+	nodeShellConfigMap       = "karmada-dashboard-node-shell"
+	nodeShellConfigNamespace = "karmada-system"
+
+	nodeShellPodPrefix = "node-shell-"
+	nodeShellContainer = "shell"
+
+	nodeShellLabel               = "dashboard.karmada.io/node-shell"
+	nodeShellOwnerLabel          = "dashboard.karmada.io/node-shell-owner"
+	nodeShellOwnerAnnotation     = "dashboard.karmada.io/node-shell-owner"
+	nodeShellHeartbeatAnnotation = "dashboard.karmada.io/node-shell-heartbeat"
+
+	// Sessions refresh the heartbeat of their pod, pods whose heartbeat is older than
+	// nodeShellOrphanAfter have lost their session and are deleted by the collector.
+	nodeShellHeartbeatInterval = time.Minute
+	nodeShellOrphanAfter       = 5 * time.Minute
+	// legacyNodeShellLifetime is how long the unlabeled shell pods of earlier versions ran
+	legacyNodeShellLifetime = time.Hour
+)
+
+// NodeShellSettings configures the pods node shells run in. Empty fields keep the value of the
+// defaults.
+type NodeShellSettings struct {
+	Image            string                      `json:"image,omitempty"`
+	Namespace        string                      `json:"namespace,omitempty"`
+	ImagePullSecrets []string                    `json:"imagePullSecrets,omitempty"`
+	Resources        corev1.ResourceRequirements `json:"resources,omitempty"`
+	// MaxLifetimeSeconds bounds how long a shell pod runs, however active its session
+	MaxLifetimeSeconds int64 `json:"maxLifetimeSeconds,omitempty"`
+}
+
+// NodeShellConfig holds the node shell settings of every cluster, those of a cluster override
+// the defaults.
+type NodeShellConfig struct {
+	Defaults NodeShellSettings            `json:"defaults"`
+	Clusters map[string]NodeShellSettings `json:"clusters,omitempty"`
+}
+
+// defaultNodeShellSettings are used for settings that are not configured.
+func defaultNodeShellSettings() NodeShellSettings {
+	return NodeShellSettings{
+		Image:     "ubuntu",
+		Namespace: "default",
+		Resources: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("10m"),
+				corev1.ResourceMemory: resource.MustParse("32Mi"),
+			},
+			Limits: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("500m"),
+				corev1.ResourceMemory: resource.MustParse("256Mi"),
+			},
+		},
+		MaxLifetimeSeconds: int64(legacyNodeShellLifetime.Seconds()),
+	}
+}
+
+// merge returns s with the fields set in override replaced.
+func (s NodeShellSettings) merge(override NodeShellSettings) NodeShellSettings {
+	if override.Image != "" {
+		s.Image = override.Image
+	}
+	if override.Namespace != "" {
+		s.Namespace = override.Namespace
+	}
+	if override.ImagePullSecrets != nil {
+		s.ImagePullSecrets = override.ImagePullSecrets
+	}
+	if override.Resources.Requests != nil {
+		s.Resources.Requests = override.Resources.Requests
+	}
+	if override.Resources.Limits != nil {
+		s.Resources.Limits = override.Resources.Limits
+	}
+	if override.MaxLifetimeSeconds > 0 {
+		s.MaxLifetimeSeconds = override.MaxLifetimeSeconds
+	}
+	return s
+}
+
+func (s NodeShellSettings) validate() error {
+	if strings.ContainsAny(s.Image, " \t\n") {
+		return fmt.Errorf("invalid image %q", s.Image)
+	}
+	if s.Namespace != "" {
+		if errs := validation.IsDNS1123Label(s.Namespace); len(errs) > 0 {
+			return fmt.Errorf("invalid namespace %q: %s", s.Namespace, strings.Join(errs, ", "))
+		}
+	}
+	for _, secret := range s.ImagePullSecrets {
+		if errs := validation.IsDNS1123Subdomain(secret); len(errs) > 0 {
+			return fmt.Errorf("invalid image pull secret %q: %s", secret, strings.Join(errs, ", "))
+		}
+	}
+	for name, request := range s.Resources.Requests {
+		if limit, ok := s.Resources.Limits[name]; ok && request.Cmp(limit) > 0 {
+			return fmt.Errorf("the %s request exceeds its limit", name)
+		}
+	}
+	if s.MaxLifetimeSeconds < 0 {
+		return fmt.Errorf("maxLifetimeSeconds cannot be negative")
+	}
+	return nil
+}
+
+// Settings returns the node shell settings of a cluster.
+func (c NodeShellConfig) Settings(cluster string) NodeShellSettings {
+	return defaultNodeShellSettings().merge(c.Defaults).merge(c.Clusters[cluster])
+}
+
+func (c NodeShellConfig) validate() error {
+	if err := c.Defaults.validate(); err != nil {
+		return fmt.Errorf("defaults: %v", err)
+	}
+	for cluster, settings := range c.Clusters {
+		if cluster == "" {
+			return fmt.Errorf("cluster settings need a cluster name")
+		}
+		if err := settings.validate(); err != nil {
+			return fmt.Errorf("cluster %s: %v", cluster, err)
+		}
+		// Requests of a cluster may be checked against limits of the defaults
+		if err := c.Settings(cluster).validate(); err != nil {
+			return fmt.Errorf("cluster %s: %v", cluster, err)
+		}
+	}
+	return nil
+}
+
+// getNodeShellConfig reads the node shell settings, the defaults if they were never set.
+func getNodeShellConfig(ctx context.Context) (NodeShellConfig, error) {
+	config := NodeShellConfig{}
+	configMap, err := client.InClusterClient().CoreV1().ConfigMaps(nodeShellConfigNamespace).Get(ctx, nodeShellConfigMap, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return config, nil
+	}
+	if err != nil {
+		return config, err
+	}
+	if data := configMap.Data["config"]; data != "" {
+		if err := json.Unmarshal([]byte(data), &config); err != nil {
+			return config, fmt.Errorf("invalid node shell settings: %v", err)
+		}
+	}
+	return config, nil
+}
+
+// saveNodeShellConfig replaces the node shell settings.
+func saveNodeShellConfig(ctx context.Context, config NodeShellConfig) error {
+	encoded, err := json.Marshal(config)
+	if err != nil {
+		return err
+	}
+	configMaps := client.InClusterClient().CoreV1().ConfigMaps(nodeShellConfigNamespace)
+	configMap, err := configMaps.Get(ctx, nodeShellConfigMap, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		_, err = configMaps.Create(ctx, &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: nodeShellConfigMap, Namespace: nodeShellConfigNamespace},
+			Data:       map[string]string{"config": string(encoded)},
+		}, metav1.CreateOptions{})
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	if configMap.Data == nil {
+		configMap.Data = map[string]string{}
+	}
+	configMap.Data["config"] = string(encoded)
+	_, err = configMaps.Update(ctx, configMap, metav1.UpdateOptions{})
+	return err
+}
+
+// ownerLabelValue turns a username into a label value. Characters labels do not allow are
+// replaced, the annotation keeps the exact username.
+func ownerLabelValue(username string) string {
+	value := []byte(username)
+	for i, c := range value {
+		if !(('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9') || c == '-' || c == '_' || c == '.') {
+			value[i] = '_'
+		}
+	}
+	if len(value) > validation.LabelValueMaxLength {
+		value = value[:validation.LabelValueMaxLength]
+	}
+	return strings.Trim(string(value), "-_.")
+}
+
+// nodeShellPod returns the pod a node shell of username runs in. The pod joins the host
+// namespaces so that nsenter reaches the node, and is otherwise as constrained as it can be: no
+// service account token, bounded resources and a deadline after which the kubelet kills it.
+func nodeShellPod(name, node, username string, settings NodeShellSettings, now time.Time) *corev1.Pod {
+	var pullSecrets []corev1.LocalObjectReference
+	for _, secret := range settings.ImagePullSecrets {
+		pullSecrets = append(pullSecrets, corev1.LocalObjectReference{Name: secret})
+	}
+	lifetime := settings.MaxLifetimeSeconds
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: settings.Namespace,
+			Labels: map[string]string{
+				"app.kubernetes.io/managed-by": "karmada-dashboard",
+				nodeShellLabel:                 "true",
+				nodeShellOwnerLabel:            ownerLabelValue(username),
+			},
+			Annotations: map[string]string{
+				nodeShellOwnerAnnotation:     username,
+				nodeShellHeartbeatAnnotation: now.UTC().Format(time.RFC3339),
+			},
+		},
+		Spec: corev1.PodSpec{
+			NodeName:                      node,
+			HostPID:                       true,
+			HostIPC:                       true,
+			HostNetwork:                   true,
+			RestartPolicy:                 corev1.RestartPolicyNever,
+			AutomountServiceAccountToken:  &[]bool{false}[0],
+			EnableServiceLinks:            &[]bool{false}[0],
+			TerminationGracePeriodSeconds: &[]int64{0}[0],
+			ActiveDeadlineSeconds:         &lifetime,
+			ImagePullSecrets:              pullSecrets,
+			Tolerations: []corev1.Toleration{
+				{
+					Operator: corev1.TolerationOpExists,
+				},
+			},
+			Containers: []corev1.Container{
+				{
+					Name:      nodeShellContainer,
+					Image:     settings.Image,
+					Command:   []string{"sleep", strconv.FormatInt(lifetime, 10)},
+					Resources: settings.Resources,
+					SecurityContext: &corev1.SecurityContext{
+						Privileged: &[]bool{true}[0],
+					},
+					Stdin: true,
+					TTY:   true,
+				},
+			},
+		},
+	}
+}
+
+// nodeShellOrphaned reports whether a node shell pod has outlived its session, and why.
+func nodeShellOrphaned(pod *corev1.Pod, now time.Time) (bool, string) {
+	if !strings.HasPrefix(pod.Name, nodeShellPodPrefix) || pod.DeletionTimestamp != nil {
+		return false, ""
+	}
+	if pod.Labels[nodeShellLabel] != "true" {
+		// Only unlabeled pods shaped like the shell pods of earlier versions are theirs
+		if len(pod.Spec.Containers) != 1 || pod.Spec.Containers[0].Name != nodeShellContainer || !pod.Spec.HostPID {
+			return false, ""
+		}
+		if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			return true, "terminated"
+		}
+		if now.Sub(pod.CreationTimestamp.Time) > legacyNodeShellLifetime {
+			return true, "expired"
+		}
+		return false, ""
+	}
+	if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+		return true, "terminated"
+	}
+	heartbeat, err := time.Parse(time.RFC3339, pod.Annotations[nodeShellHeartbeatAnnotation])
+	if err != nil {
+		heartbeat = pod.CreationTimestamp.Time
+	}
+	if now.Sub(heartbeat) > nodeShellOrphanAfter {
+		return true, "no heartbeat"
+	}
+	return false, ""
+}
+
+// keepNodeShellAlive refreshes the heartbeat of a shell pod until ctx is done.
+func keepNodeShellAlive(ctx context.Context, k8sClient kubernetes.Interface, namespace, name string) {
+	ticker := time.NewTicker(nodeShellHeartbeatInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			patch := fmt.Sprintf(`{"metadata":{"annotations":{%q:%q}}}`, nodeShellHeartbeatAnnotation, now.UTC().Format(time.RFC3339))
+			_, err := k8sClient.CoreV1().Pods(namespace).Patch(ctx, name, types.MergePatchType, []byte(patch), metav1.PatchOptions{})
+			if err != nil && ctx.Err() == nil {
+				klog.ErrorS(err, "Failed to refresh node shell heartbeat", "namespace", namespace, "pod", name)
+			}
+		}
+	}
+}
+
+// StartNodeShellCollector periodically deletes the node shell pods of every cluster whose session
+// is gone, e.g. because the dashboard restarted during the session. An interval of 0 disables it.
+func StartNodeShellCollector(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				collectNodeShells(ctx)
+			}
+		}
+	}()
+}
+
+func collectNodeShells(ctx context.Context) {
+	config, err := getNodeShellConfig(ctx)
+	if err != nil {
+		klog.ErrorS(err, "Failed to get node shell settings, collecting with the defaults")
+	}
+	clusters := []string{"mgmt-cluster"}
+	clusterList, err := client.InClusterKarmadaClient().ClusterV1alpha1().Clusters().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		klog.ErrorS(err, "Failed to list clusters for node shell collection")
+	} else {
+		for _, cluster := range clusterList.Items {
+			clusters = append(clusters, cluster.Name)
+		}
+	}
+	for _, cluster := range clusters {
+		k8sClient := client.InClusterClientForMemberClusterAsUser(ctx, "", cluster)
+		if k8sClient == nil {
+			continue
+		}
+		if err := collectClusterNodeShells(ctx, k8sClient, cluster, config.Settings(cluster).Namespace); err != nil {
+			klog.ErrorS(err, "Failed to collect node shell pods", "cluster", cluster)
+		}
+	}
+}
+
+// collectClusterNodeShells deletes the orphaned node shell pods of a cluster. Labeled pods are
+// found in every namespace, the unlabeled pods of earlier versions in namespace and default.
+func collectClusterNodeShells(ctx context.Context, k8sClient kubernetes.Interface, cluster, namespace string) error {
+	pods, err := k8sClient.CoreV1().Pods(metav1.NamespaceAll).List(ctx, metav1.ListOptions{LabelSelector: nodeShellLabel + "=true"})
+	if err != nil {
+		return err
+	}
+	candidates := pods.Items
+	for _, ns := range []string{namespace, metav1.NamespaceDefault} {
+		legacy, err := k8sClient.CoreV1().Pods(ns).List(ctx, metav1.ListOptions{LabelSelector: "!" + nodeShellLabel})
+		if err != nil {
+			return err
+		}
+		candidates = append(candidates, legacy.Items...)
+		if namespace == metav1.NamespaceDefault {
+			break
+		}
+	}
+
+	now := time.Now()
+	for i := range candidates {
+		pod := &candidates[i]
+		orphaned, reason := nodeShellOrphaned(pod, now)
+		if !orphaned {
+			continue
+		}
+		err := k8sClient.CoreV1().Pods(pod.Namespace).Delete(ctx, pod.Name, metav1.DeleteOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			klog.ErrorS(err, "Failed to delete orphaned node shell pod", "cluster", cluster, "namespace", pod.Namespace, "pod", pod.Name)
+			continue
+		}
+		klog.InfoS("Deleted orphaned node shell pod", "cluster", cluster, "namespace", pod.Namespace, "pod", pod.Name,
+			"owner", pod.Annotations[nodeShellOwnerAnnotation], "reason", reason)
+	}
+	return nil
+}
+
+// handleGetNodeShellConfig returns the node shell settings, with those in effect for each
+// configured cluster.
+func handleGetNodeShellConfig(c *gin.Context) {
+	config, err := getNodeShellConfig(c)
+	if err != nil {
+		klog.ErrorS(err, "Failed to get node shell settings")
+		common.Fail(c, err)
+		return
+	}
+	effective := map[string]NodeShellSettings{}
+	for cluster := range config.Clusters {
+		effective[cluster] = config.Settings(cluster)
+	}
+	common.Success(c, gin.H{
+		"config":    config,
+		"defaults":  config.Settings(""),
+		"effective": effective,
+	})
+}
+
+// handleSetNodeShellConfig replaces the node shell settings. Running shells keep their pods.
+func handleSetNodeShellConfig(c *gin.Context) {
+	var config NodeShellConfig
+	if err := c.ShouldBindJSON(&config); err != nil {
+		common.FailWithStatus(c, err, http.StatusBadRequest)
+		return
+	}
+	if err := config.validate(); err != nil {
+		common.FailWithStatus(c, err, http.StatusBadRequest)
+		return
+	}
+	if err := saveNodeShellConfig(c, config); err != nil {
+		klog.ErrorS(err, "Failed to save node shell settings")
+		common.Fail(c, err)
+		return
+	}
+	klog.InfoS("Node shell settings updated", "user", auth.UserFromContext(c), "clusters", len(config.Clusters))
+	common.Success(c, config)
+}
+
+func init() {
+	r := router.V1().Group("/terminal/node-shell")
+	r.Use(router.EnsureDashboardAdminMiddleware())
+	r.GET("/config", handleGetNodeShellConfig)
+	r.PUT("/config", handleSetNodeShellConfig)
+}
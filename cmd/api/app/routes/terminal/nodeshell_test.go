@@ -0,0 +1,132 @@
+/*
+Copyright 2024 The Karmada Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package terminal
+
+import (
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestNodeShellConfigSettings(t *testing.T) {
+	config := NodeShellConfig{
+		Defaults: NodeShellSettings{Image: "registry.local/debug:1.0", ImagePullSecrets: []string{"registry"}},
+		Clusters: map[string]NodeShellSettings{
+			"prod": {
+				Namespace: "node-shells",
+				Resources: corev1.ResourceRequirements{
+					Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("128Mi")},
+				},
+				MaxLifetimeSeconds: 600,
+			},
+		},
+	}
+	if err := config.validate(); err != nil {
+		t.Fatalf("validate() failed: %v", err)
+	}
+
+	dev := config.Settings("dev")
+	if dev.Image != "registry.local/debug:1.0" || dev.Namespace != "default" || dev.MaxLifetimeSeconds != 3600 || len(dev.ImagePullSecrets) != 1 {
+		t.Errorf("Settings(dev) == %+v, expected the defaults with the configured image", dev)
+	}
+	prod := config.Settings("prod")
+	if prod.Image != "registry.local/debug:1.0" || prod.Namespace != "node-shells" || prod.MaxLifetimeSeconds != 600 {
+		t.Errorf("Settings(prod) == %+v, expected the cluster settings over the defaults", prod)
+	}
+	if limit := prod.Resources.Limits[corev1.ResourceMemory]; limit.String() != "128Mi" {
+		t.Errorf("Settings(prod) memory limit == %s, expected 128Mi", limit.String())
+	}
+	if _, ok := prod.Resources.Limits[corev1.ResourceCPU]; ok {
+		t.Errorf("Settings(prod) kept the default CPU limit, limits are replaced as a whole")
+	}
+
+	pod := nodeShellPod("node-shell-n1-abc", "n1", "alice@example.com", prod, time.Now())
+	if pod.Namespace != "node-shells" || *pod.Spec.ActiveDeadlineSeconds != 600 || pod.Spec.Containers[0].Command[1] != "600" ||
+		*pod.Spec.AutomountServiceAccountToken || pod.Spec.ImagePullSecrets[0].Name != "registry" {
+		t.Errorf("nodeShellPod() == %+v, expected it to follow the settings", pod)
+	}
+	if pod.Labels[nodeShellOwnerLabel] != "alice_example.com" || pod.Annotations[nodeShellOwnerAnnotation] != "alice@example.com" {
+		t.Errorf("nodeShellPod() owner == %q/%q", pod.Labels[nodeShellOwnerLabel], pod.Annotations[nodeShellOwnerAnnotation])
+	}
+}
+
+func TestNodeShellConfigValidate(t *testing.T) {
+	cases := []struct {
+		name   string
+		config NodeShellConfig
+		valid  bool
+	}{
+		{"empty", NodeShellConfig{}, true},
+		{"invalid namespace", NodeShellConfig{Defaults: NodeShellSettings{Namespace: "Node_Shells"}}, false},
+		{"invalid pull secret", NodeShellConfig{Defaults: NodeShellSettings{ImagePullSecrets: []string{"a b"}}}, false},
+		{"negative lifetime", NodeShellConfig{Clusters: map[string]NodeShellSettings{"m1": {MaxLifetimeSeconds: -1}}}, false},
+		{"request over the default limit", NodeShellConfig{Clusters: map[string]NodeShellSettings{"m1": {
+			Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")}},
+		}}}, false},
+	}
+	for _, c := range cases {
+		if err := c.config.validate(); (err == nil) != c.valid {
+			t.Errorf("%s: validate() returned %v, expected valid to be %t", c.name, err, c.valid)
+		}
+	}
+}
+
+func TestNodeShellOrphaned(t *testing.T) {
+	now := time.Date(2024, 5, 15, 10, 0, 0, 0, time.UTC)
+	shellPod := func(name string, labeled bool, created time.Time, heartbeat string, phase corev1.PodPhase) *corev1.Pod {
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, CreationTimestamp: metav1.NewTime(created)},
+			Spec:       corev1.PodSpec{HostPID: true, Containers: []corev1.Container{{Name: nodeShellContainer}}},
+			Status:     corev1.PodStatus{Phase: phase},
+		}
+		if labeled {
+			pod.Labels = map[string]string{nodeShellLabel: "true"}
+			pod.Annotations = map[string]string{nodeShellHeartbeatAnnotation: heartbeat}
+		}
+		return pod
+	}
+	recent := now.Add(-time.Minute).Format(time.RFC3339)
+	stale := now.Add(-10 * time.Minute).Format(time.RFC3339)
+
+	cases := []struct {
+		pod      *corev1.Pod
+		orphaned bool
+	}{
+		{shellPod("node-shell-n1-a", true, now.Add(-time.Hour), recent, corev1.PodRunning), false},
+		{shellPod("node-shell-n1-b", true, now.Add(-time.Hour), stale, corev1.PodRunning), true},
+		{shellPod("node-shell-n1-c", true, now.Add(-time.Minute), recent, corev1.PodSucceeded), true},
+		{shellPod("node-shell-n1-d", true, now.Add(-10*time.Minute), "", corev1.PodPending), true},
+		{shellPod("node-shell-n1-e", false, now.Add(-2*time.Hour), "", corev1.PodRunning), true},
+		{shellPod("node-shell-n1-f", false, now.Add(-time.Minute), "", corev1.PodRunning), false},
+		{shellPod("web-0", true, now.Add(-time.Hour), stale, corev1.PodRunning), false},
+	}
+	for _, c := range cases {
+		if orphaned, reason := nodeShellOrphaned(c.pod, now); orphaned != c.orphaned {
+			t.Errorf("nodeShellOrphaned(%s) == %t (%s), expected %t", c.pod.Name, orphaned, reason, c.orphaned)
+		}
+	}
+
+	// Unlabeled pods that do not look like node shells are left alone
+	other := shellPod("node-shell-backup", false, now.Add(-48*time.Hour), "", corev1.PodSucceeded)
+	other.Spec.HostPID = false
+	if orphaned, _ := nodeShellOrphaned(other, now); orphaned {
+		t.Errorf("nodeShellOrphaned() collected an unrelated pod")
+	}
+}
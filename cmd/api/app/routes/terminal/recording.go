@@ -0,0 +1,253 @@
+/*
+Copyright 2024 The Karmada Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package terminal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+
+	"github.com/karmada-io/dashboard/cmd/api/app/router"
+	"github.com/karmada-io/dashboard/cmd/api/app/types/common"
+	"github.com/karmada-io/dashboard/pkg/auth"
+	"github.com/karmada-io/dashboard/pkg/client"
+	"github.com/karmada-io/dashboard/pkg/recording"
+)
+
+const (
+	// recordingPolicyConfigMap holds the policies that force recording terminal sessions
+	recordingPolicyConfigMap = "karmada-dashboard-terminal-recording"
+	recordingPolicyNamespace = "karmada-system"
+)
+
+// RecordingPoliciesRequest replaces the recording policies
+type RecordingPoliciesRequest struct {
+	Policies []recording.Policy `json:"policies" binding:"dive"`
+}
+
+// getRecordingPolicies reads the recording policies, none if they were never set.
+func getRecordingPolicies(ctx context.Context) ([]recording.Policy, error) {
+	configMap, err := client.InClusterClient().CoreV1().ConfigMaps(recordingPolicyNamespace).Get(ctx, recordingPolicyConfigMap, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return []recording.Policy{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	policies := []recording.Policy{}
+	if data := configMap.Data["policies"]; data != "" {
+		if err := json.Unmarshal([]byte(data), &policies); err != nil {
+			return nil, fmt.Errorf("invalid terminal recording policies: %v", err)
+		}
+	}
+	return policies, nil
+}
+
+// saveRecordingPolicies replaces the recording policies.
+func saveRecordingPolicies(ctx context.Context, policies []recording.Policy) error {
+	encoded, err := json.Marshal(policies)
+	if err != nil {
+		return err
+	}
+	configMaps := client.InClusterClient().CoreV1().ConfigMaps(recordingPolicyNamespace)
+	configMap, err := configMaps.Get(ctx, recordingPolicyConfigMap, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		_, err = configMaps.Create(ctx, &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: recordingPolicyConfigMap, Namespace: recordingPolicyNamespace},
+			Data:       map[string]string{"policies": string(encoded)},
+		}, metav1.CreateOptions{})
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	if configMap.Data == nil {
+		configMap.Data = map[string]string{}
+	}
+	configMap.Data["policies"] = string(encoded)
+	_, err = configMaps.Update(ctx, configMap, metav1.UpdateOptions{})
+	return err
+}
+
+// startSessionRecording starts recording a session if the user asked for it with the record
+// query parameter or a policy requires it. It returns nil if the session is not recorded, and an
+// error if it has to be recorded but cannot be, in which case the session must not start.
+func startSessionRecording(c *gin.Context, metadata recording.Metadata) (*recording.Recording, *recording.Policy, error) {
+	metadata.User = auth.UserFromContext(c)
+	policies, err := getRecordingPolicies(c)
+	if err != nil {
+		// Fail closed, a policy may require the recording
+		return nil, nil, fmt.Errorf("failed to read terminal recording policies: %v", err)
+	}
+	policy := recording.Required(policies, metadata.User, metadata.Cluster)
+	if policy == nil && c.Query("record") != "true" {
+		return nil, nil, nil
+	}
+	if !recording.Enabled() {
+		return nil, nil, fmt.Errorf("the session has to be recorded but terminal recording is disabled")
+	}
+
+	metadata.Forced = policy != nil
+	rec, err := recording.Start(metadata)
+	if err != nil {
+		return nil, nil, err
+	}
+	klog.InfoS("Recording terminal session", "id", rec.ID(), "user", metadata.User, "cluster", metadata.Cluster, "forced", metadata.Forced)
+	return rec, policy, nil
+}
+
+// recordingNotice tells the user that the session is recorded.
+func recordingNotice(rec *recording.Recording, policy *recording.Policy) string {
+	if policy != nil {
+		return fmt.Sprintf("This session is recorded as %s, as required by policy %s\r\n", rec.ID(), policy.Name)
+	}
+	return fmt.Sprintf("This session is recorded as %s\r\n", rec.ID())
+}
+
+// handleListRecordings lists recorded sessions, most recent first. The user and cluster query
+// parameters filter them, since and until take RFC 3339 times.
+func handleListRecordings(c *gin.Context) {
+	query := recording.Query{User: c.Query("user"), Cluster: c.Query("cluster")}
+	for name, target := range map[string]*time.Time{"since": &query.Since, "until": &query.Until} {
+		if value := c.Query(name); value != "" {
+			t, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				common.FailWithStatus(c, fmt.Errorf("invalid %s parameter, expected an RFC 3339 time: %v", name, err), http.StatusBadRequest)
+				return
+			}
+			*target = t
+		}
+	}
+
+	recordings, err := recording.List(c, query)
+	if err != nil {
+		klog.ErrorS(err, "Failed to list terminal recordings")
+		common.Fail(c, err)
+		return
+	}
+	common.Success(c, gin.H{
+		"recordings": recordings,
+		"listMax":    recording.MaxListedRecordings,
+	})
+}
+
+// handleGetRecording returns the metadata of a recorded session.
+func handleGetRecording(c *gin.Context) {
+	metadata, err := recording.Get(c, c.Param("id"))
+	if errors.Is(err, recording.ErrNotFound) {
+		common.FailWithStatus(c, err, http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		klog.ErrorS(err, "Failed to get terminal recording", "id", c.Param("id"))
+		common.Fail(c, err)
+		return
+	}
+	common.Success(c, metadata)
+}
+
+// handlePlayRecording returns the asciicast v2 file of a recorded session, which players like
+// asciinema-player load directly. download=true makes browsers save it instead.
+func handlePlayRecording(c *gin.Context) {
+	id := c.Param("id")
+	body, err := recording.Open(c, id)
+	if errors.Is(err, recording.ErrNotFound) {
+		common.FailWithStatus(c, err, http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		klog.ErrorS(err, "Failed to open terminal recording", "id", id)
+		common.Fail(c, err)
+		return
+	}
+	defer body.Close()
+
+	c.Header("Content-Type", "application/x-asciicast")
+	if c.Query("download") == "true" {
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", id+".cast"))
+	}
+	c.Status(http.StatusOK)
+	if _, err := io.Copy(c.Writer, body); err != nil {
+		klog.ErrorS(err, "Failed to send terminal recording", "id", id)
+	}
+}
+
+// handleGetRecordingPolicies returns the policies that force recording sessions.
+func handleGetRecordingPolicies(c *gin.Context) {
+	policies, err := getRecordingPolicies(c)
+	if err != nil {
+		klog.ErrorS(err, "Failed to get terminal recording policies")
+		common.Fail(c, err)
+		return
+	}
+	common.Success(c, gin.H{
+		"policies": policies,
+		"enabled":  recording.Enabled(),
+	})
+}
+
+// handleSetRecordingPolicies replaces the policies that force recording sessions.
+func handleSetRecordingPolicies(c *gin.Context) {
+	var req RecordingPoliciesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.FailWithStatus(c, err, http.StatusBadRequest)
+		return
+	}
+	names := map[string]bool{}
+	for _, policy := range req.Policies {
+		if policy.Name == "" || names[policy.Name] {
+			common.FailWithStatus(c, fmt.Errorf("policies need unique names"), http.StatusBadRequest)
+			return
+		}
+		names[policy.Name] = true
+	}
+	if len(req.Policies) > 0 && !recording.Enabled() {
+		common.FailWithStatus(c, fmt.Errorf("terminal recording is disabled, sessions covered by the policies could not start"), http.StatusBadRequest)
+		return
+	}
+	if req.Policies == nil {
+		req.Policies = []recording.Policy{}
+	}
+
+	if err := saveRecordingPolicies(c, req.Policies); err != nil {
+		klog.ErrorS(err, "Failed to save terminal recording policies")
+		common.Fail(c, err)
+		return
+	}
+	klog.InfoS("Terminal recording policies updated", "user", auth.UserFromContext(c), "policies", len(req.Policies))
+	common.Success(c, gin.H{"policies": req.Policies})
+}
+
+func init() {
+	r := router.V1().Group("/terminal/recordings")
+	r.Use(router.EnsureDashboardAdminMiddleware())
+	r.GET("", handleListRecordings)
+	r.GET("/policies", handleGetRecordingPolicies)
+	r.PUT("/policies", handleSetRecordingPolicies)
+	r.GET("/:id", handleGetRecording)
+	r.GET("/:id/cast", handlePlayRecording)
+}
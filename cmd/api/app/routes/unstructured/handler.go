@@ -18,9 +18,11 @@ package unstructured
 
 import (
 	"io"
+	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/client-go/util/retry"
 	"k8s.io/klog/v2"
@@ -28,6 +30,7 @@ import (
 	"github.com/karmada-io/dashboard/cmd/api/app/router"
 	"github.com/karmada-io/dashboard/cmd/api/app/types/common"
 	"github.com/karmada-io/dashboard/pkg/client"
+	"github.com/karmada-io/dashboard/pkg/resource/diff"
 )
 
 func handleDeleteResource(c *gin.Context) {
@@ -41,12 +44,21 @@ func handleDeleteResource(c *gin.Context) {
 	namespace := c.Param("namespace")
 	name := c.Param("name")
 	deleteNow := c.Param("deleteNow") == "true"
+	dryRun, err := common.ParseDryRun(c)
+	if err != nil {
+		common.FailWithStatus(c, err, http.StatusBadRequest)
+		return
+	}
 
-	if err := verber.Delete(kind, namespace, name, deleteNow); err != nil {
+	if err := verber.Delete(kind, namespace, name, deleteNow, dryRun); err != nil {
 		klog.ErrorS(err, "Failed to delete resource")
 		common.Fail(c, err)
 		return
 	}
+	if len(dryRun) > 0 {
+		common.Success(c, "ok")
+		return
+	}
 	err = retry.OnError(
 		retry.DefaultRetry,
 		func(err error) bool {
@@ -104,7 +116,12 @@ func handlePutResource(c *gin.Context) {
 		common.Fail(c, err)
 		return
 	}
-	if err = verber.Update(raw); err != nil {
+	dryRun, err := common.ParseDryRun(c)
+	if err != nil {
+		common.FailWithStatus(c, err, http.StatusBadRequest)
+		return
+	}
+	if err = verber.Update(raw, dryRun); err != nil {
 		klog.ErrorS(err, "Failed to update resource")
 		common.Fail(c, err)
 		return
@@ -132,8 +149,14 @@ func handleCreateResource(c *gin.Context) {
 	if err != nil {
 		klog.ErrorS(err, "Failed to unmarshal request body")
 		common.Fail(c, err)
+		return
 	}
-	if _, err = verber.Create(raw); err != nil {
+	dryRun, err := common.ParseDryRun(c)
+	if err != nil {
+		common.FailWithStatus(c, err, http.StatusBadRequest)
+		return
+	}
+	if _, err = verber.Create(raw, dryRun); err != nil {
 		klog.ErrorS(err, "Failed to create resource")
 		common.Fail(c, err)
 		return
@@ -141,16 +164,111 @@ func handleCreateResource(c *gin.Context) {
 	common.Success(c, "ok")
 }
 
+// handleApplyResource applies the JSON or YAML manifest of the body with server-side apply, as the
+// dashboard field manager. Conflicts with the fields of other managers fail the request unless
+// force=true. It supports dryRun=All, as the other writes do.
+func handleApplyResource(c *gin.Context) {
+	verber, err := client.VerberClient(c.Request)
+	if err != nil {
+		klog.ErrorS(err, "Failed to init VerberClient")
+		common.Fail(c, err)
+		return
+	}
+	opts, ok := parseApplyOptions(c)
+	if !ok {
+		return
+	}
+	raw, err := common.ParseObjectBody(c, c.Param("namespace"), c.Param("name"))
+	if err != nil {
+		common.FailWithStatus(c, err, http.StatusBadRequest)
+		return
+	}
+
+	result, err := verber.Apply(raw, opts)
+	if err != nil {
+		klog.ErrorS(err, "Failed to apply resource", "kind", raw.GetKind(), "namespace", raw.GetNamespace(), "name", raw.GetName())
+		common.Fail(c, err)
+		return
+	}
+	common.Success(c, result)
+}
+
+// handleDiffResource returns the difference between the live resource and the resource the JSON or
+// YAML manifest of the body would make once applied, from a dry-run server-side apply. It takes
+// the force parameter of the apply.
+func handleDiffResource(c *gin.Context) {
+	verber, err := client.VerberClient(c.Request)
+	if err != nil {
+		klog.ErrorS(err, "Failed to init VerberClient")
+		common.Fail(c, err)
+		return
+	}
+	force, err := common.ParseForce(c)
+	if err != nil {
+		common.FailWithStatus(c, err, http.StatusBadRequest)
+		return
+	}
+	raw, err := common.ParseObjectBody(c, c.Param("namespace"), c.Param("name"))
+	if err != nil {
+		common.FailWithStatus(c, err, http.StatusBadRequest)
+		return
+	}
+
+	var live *unstructured.Unstructured
+	current, err := verber.Get(c.Param("kind"), raw.GetNamespace(), raw.GetName())
+	switch {
+	case errors.IsNotFound(err):
+	case err != nil:
+		klog.ErrorS(err, "Failed to get resource")
+		common.Fail(c, err)
+		return
+	default:
+		live, _ = current.(*unstructured.Unstructured)
+	}
+	merged, err := verber.Apply(raw, client.ApplyOptions(force, []string{metav1.DryRunAll}))
+	if err != nil {
+		klog.ErrorS(err, "Failed to dry-run apply resource", "kind", raw.GetKind(), "namespace", raw.GetNamespace(), "name", raw.GetName())
+		common.Fail(c, err)
+		return
+	}
+	result, err := diff.Objects(live, merged)
+	if err != nil {
+		common.Fail(c, err)
+		return
+	}
+	common.Success(c, result)
+}
+
+// parseApplyOptions parses the force and dryRun parameters of a server-side apply, failing the
+// request if they are invalid.
+func parseApplyOptions(c *gin.Context) (metav1.ApplyOptions, bool) {
+	force, err := common.ParseForce(c)
+	if err != nil {
+		common.FailWithStatus(c, err, http.StatusBadRequest)
+		return metav1.ApplyOptions{}, false
+	}
+	dryRun, err := common.ParseDryRun(c)
+	if err != nil {
+		common.FailWithStatus(c, err, http.StatusBadRequest)
+		return metav1.ApplyOptions{}, false
+	}
+	return client.ApplyOptions(force, dryRun), true
+}
+
 func init() {
 	r := router.V1()
 	r.DELETE("/_raw/:kind/namespace/:namespace/name/:name", handleDeleteResource)
 	r.GET("/_raw/:kind/namespace/:namespace/name/:name", handleGetResource)
 	r.PUT("/_raw/:kind/namespace/:namespace/name/:name", handlePutResource)
 	r.POST("/_raw/:kind/namespace/:namespace/name/:name", handleCreateResource)
+	r.PATCH("/_raw/:kind/namespace/:namespace/name/:name", handleApplyResource)
+	r.POST("/_raw/:kind/namespace/:namespace/name/:name/diff", handleDiffResource)
 
 	// Verber (non-namespaced)
 	r.DELETE("/_raw/:kind/name/:name", handleDeleteResource)
 	r.GET("/_raw/:kind/name/:name", handleGetResource)
 	r.PUT("/_raw/:kind/name/:name", handlePutResource)
 	r.POST("/_raw/:kind/name/:name", handleCreateResource)
+	r.PATCH("/_raw/:kind/name/:name", handleApplyResource)
+	r.POST("/_raw/:kind/name/:name/diff", handleDiffResource)
 }
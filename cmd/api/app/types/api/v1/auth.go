@@ -16,6 +16,8 @@ limitations under the License.
 
 package v1
 
+import "time"
+
 // LoginRequest is the request for login.
 type LoginRequest struct {
 	// Token is the bearer token for authentication
@@ -29,10 +31,96 @@ type LoginRequest struct {
 // LoginResponse is the response for login.
 type LoginResponse struct {
 	Token string `json:"token"`
+	// RefreshToken obtains a new token pair once Token expires, it is single-use
+	RefreshToken string `json:"refreshToken,omitempty"`
+	// ExpiresAt is when Token expires
+	ExpiresAt time.Time `json:"expiresAt,omitempty"`
 	// Username of the authenticated user
 	Username string `json:"username,omitempty"`
 	// Role of the authenticated user
 	Role string `json:"role,omitempty"`
+	// PasswordChangeRequired is set instead of a token when the password has to be changed
+	// through /password/change before signing in
+	PasswordChangeRequired bool `json:"passwordChangeRequired,omitempty"`
+	// TwoFactorRequired is set instead of a token when TwoFactorToken has to be exchanged
+	// through /login/2fa with a TOTP or recovery code
+	TwoFactorRequired bool `json:"twoFactorRequired,omitempty"`
+	// TwoFactorEnrollmentRequired is set instead of a token when the user has to enroll through
+	// /2fa/enroll with TwoFactorToken as bearer token before signing in
+	TwoFactorEnrollmentRequired bool `json:"twoFactorEnrollmentRequired,omitempty"`
+	// TwoFactorToken is a partial token that is only accepted for the second login step
+	TwoFactorToken string `json:"twoFactorToken,omitempty"`
+}
+
+// RefreshTokenRequest is the request to redeem a refresh token.
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refreshToken" binding:"required"`
+}
+
+// LogoutRequest is the request to end login sessions.
+type LogoutRequest struct {
+	// All ends every session of the user instead of only the current one
+	All bool `json:"all,omitempty"`
+}
+
+// ChangePasswordRequest is the request to change the password of a user.
+type ChangePasswordRequest struct {
+	Username        string `json:"username" binding:"required"`
+	CurrentPassword string `json:"currentPassword" binding:"required"`
+	NewPassword     string `json:"newPassword" binding:"required"`
+	// Code is a TOTP or recovery code, required when two-factor authentication is enabled
+	Code string `json:"code,omitempty"`
+}
+
+// SecondFactorRequest is the second login step of users with two-factor authentication.
+type SecondFactorRequest struct {
+	// Token is the TwoFactorToken of the login response
+	Token string `json:"token" binding:"required"`
+	// Code is a TOTP or recovery code
+	Code string `json:"code" binding:"required"`
+}
+
+// TwoFactorCodeRequest carries a TOTP or recovery code to confirm a two-factor change.
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// TwoFactorEnrollmentResponse is the TOTP secret to add to an authenticator app.
+type TwoFactorEnrollmentResponse struct {
+	Secret string `json:"secret"`
+	// OTPAuthURL is the otpauth URL of the secret, usually shown as a QR code
+	OTPAuthURL string `json:"otpauthURL"`
+}
+
+// RecoveryCodesResponse holds recovery codes, they are shown only once.
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recoveryCodes"`
+	// Login is the session started when the enrollment was part of a login
+	Login *LoginResponse `json:"login,omitempty"`
+}
+
+// TwoFactorStatus is the two-factor authentication status of a user.
+type TwoFactorStatus struct {
+	Enabled                bool `json:"enabled"`
+	Required               bool `json:"required"`
+	RemainingRecoveryCodes int  `json:"remainingRecoveryCodes"`
+}
+
+// ResetPasswordRequest is the request of an administrator to reset the password of a user.
+type ResetPasswordRequest struct {
+	// Password is a temporary password the user has to change on the next login
+	Password string `json:"password" binding:"required"`
+}
+
+// PasswordPolicy describes the password rules for clients.
+type PasswordPolicy struct {
+	MinLength        int  `json:"minLength"`
+	RequireMixedCase bool `json:"requireMixedCase"`
+	RequireDigit     bool `json:"requireDigit"`
+	RequireSymbol    bool `json:"requireSymbol"`
+	HistorySize      int  `json:"historySize"`
+	// MaxAgeDays is the number of days a password stays valid, 0 when passwords do not expire
+	MaxAgeDays int `json:"maxAgeDays"`
 }
 
 // User is the user info.
@@ -62,3 +150,46 @@ type InitTokenResponse struct {
 	// Message provides additional information about the operation
 	Message string `json:"message,omitempty"`
 }
+
+// OIDCConfigResponse tells the UI whether single sign-on is available.
+type OIDCConfigResponse struct {
+	// Enabled indicates whether an OIDC identity provider is configured
+	Enabled bool `json:"enabled"`
+	// LoginURL is the endpoint that starts the single sign-on flow
+	LoginURL string `json:"loginURL,omitempty"`
+}
+
+// CreateAPITokenRequest is the request to create a personal access token.
+type CreateAPITokenRequest struct {
+	Name        string `json:"name" binding:"required"`
+	Description string `json:"description,omitempty"`
+	// Scopes are read and write, read-only when empty
+	Scopes []string `json:"scopes,omitempty"`
+	// Clusters limits the member clusters the token can access, all when empty
+	Clusters []string `json:"clusters,omitempty"`
+	// ExpiresInDays is the lifetime of the token, 90 days when 0
+	ExpiresInDays int `json:"expiresInDays,omitempty"`
+	// Username is the owner of the token, only administrators can create tokens for other users
+	Username string `json:"username,omitempty"`
+}
+
+// APIToken describes a personal access token, without its secret.
+type APIToken struct {
+	ID          string     `json:"id"`
+	Name        string     `json:"name"`
+	Description string     `json:"description,omitempty"`
+	Username    string     `json:"username"`
+	Scopes      []string   `json:"scopes"`
+	Clusters    []string   `json:"clusters,omitempty"`
+	CreatedBy   string     `json:"createdBy"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	ExpiresAt   time.Time  `json:"expiresAt"`
+	LastUsedAt  *time.Time `json:"lastUsedAt,omitempty"`
+}
+
+// CreateAPITokenResponse returns a new token. Token is the secret sent as bearer token, it
+// cannot be retrieved again.
+type CreateAPITokenResponse struct {
+	APIToken `json:",inline"`
+	Token    string `json:"token"`
+}
@@ -0,0 +1,54 @@
+/*
+Copyright 2024 The Karmada Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import "time"
+
+// Group is a named set of users whose cluster permissions are granted once for all members.
+type Group struct {
+	// Name is the unique identifier for the group
+	Name string `json:"name"`
+	// Description is a human-readable description of the group
+	Description string `json:"description,omitempty"`
+	// Members is the list of usernames in the group
+	Members []string `json:"members"`
+	// ClusterPermissions contains the roles granted to every member of the group
+	ClusterPermissions []ClusterPermission `json:"clusterPermissions,omitempty"`
+	CreatedAt          time.Time           `json:"createdAt"`
+	UpdatedAt          time.Time           `json:"updatedAt"`
+}
+
+// CreateGroupRequest is the request body for creating a group.
+type CreateGroupRequest struct {
+	Name               string              `json:"name" binding:"required"`
+	Description        string              `json:"description"`
+	Members            []string            `json:"members"`
+	ClusterPermissions []ClusterPermission `json:"clusterPermissions"`
+}
+
+// UpdateGroupRequest is the request body for updating a group. Fields left out are not changed,
+// Members and ClusterPermissions replace the current ones when present.
+type UpdateGroupRequest struct {
+	Description        *string             `json:"description"`
+	Members            []string            `json:"members"`
+	ClusterPermissions []ClusterPermission `json:"clusterPermissions"`
+}
+
+// GroupMembersRequest is the request body for adding members to a group.
+type GroupMembersRequest struct {
+	Members []string `json:"members" binding:"required"`
+}
@@ -16,6 +16,8 @@ limitations under the License.
 
 package v1
 
+import "time"
+
 // UserSetting represents user-specific settings in the system
 type UserSetting struct {
 	// Username is the unique identifier for the user
@@ -38,13 +40,50 @@ type UserSetting struct {
 	Dashboard *DashboardSettings `json:"dashboard,omitempty"`
 	// ClusterPermissions contains the user's cluster-specific roles and permissions
 	ClusterPermissions []ClusterPermission `json:"clusterPermissions,omitempty"`
+	// Account is the login state of the user, only returned to administrators
+	Account *AccountStatus `json:"account,omitempty"`
+}
+
+// AccountStatus is the login state of a password user
+type AccountStatus struct {
+	// Locked is set while the user is locked out after too many failed logins
+	Locked      bool       `json:"locked"`
+	LockedUntil *time.Time `json:"lockedUntil,omitempty"`
+	// FailedLogins is the number of consecutive failed logins
+	FailedLogins int `json:"failedLogins"`
+	// MustChangePassword is set when the password has to be changed on the next login
+	MustChangePassword bool      `json:"mustChangePassword"`
+	PasswordChangedAt  time.Time `json:"passwordChangedAt,omitempty"`
+	// TwoFactorEnabled is set when the user signs in with a TOTP code
+	TwoFactorEnabled bool `json:"twoFactorEnabled"`
 }
 
 // ClusterPermission represents a user's permissions for a specific cluster
 type ClusterPermission struct {
 	// Cluster is the name of the cluster
 	Cluster string `json:"cluster"`
-	// Roles is a list of roles assigned to the user for this cluster (e.g., "owner", "member")
+	// Roles is a list of roles assigned to the user for this cluster
+	// ("owner", "member", "editor", "operator" or "viewer")
+	Roles []string `json:"roles"`
+	// Namespaces contains permissions scoped to individual namespaces of the cluster
+	Namespaces []NamespacePermission `json:"namespaces,omitempty"`
+}
+
+// NamespacePermission represents a user's permissions for a namespace of a cluster
+type NamespacePermission struct {
+	// Namespace is the name of the namespace
+	Namespace string `json:"namespace"`
+	// Roles is a list of roles assigned to the user for the whole namespace ("editor", "operator" or "viewer")
+	Roles []string `json:"roles,omitempty"`
+	// Resources contains permissions scoped to individual resource kinds within the namespace
+	Resources []ResourcePermission `json:"resources,omitempty"`
+}
+
+// ResourcePermission represents a user's permissions for a resource kind within a namespace
+type ResourcePermission struct {
+	// Kind is the lowercase resource kind as used in routes (e.g., "deployment", "configmap")
+	Kind string `json:"kind"`
+	// Roles is a list of roles assigned to the user for this resource kind ("editor", "operator" or "viewer")
 	Roles []string `json:"roles"`
 }
 
@@ -17,13 +17,19 @@ limitations under the License.
 package common
 
 import (
+	"fmt"
+	"io"
 	"strconv"
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
 
 	"github.com/karmada-io/dashboard/pkg/dataselect"
 	"github.com/karmada-io/dashboard/pkg/resource/common"
+	"github.com/karmada-io/dashboard/pkg/resource/pod"
 )
 
 func parsePaginationPathParameter(request *gin.Context) *dataselect.PaginationQuery {
@@ -73,3 +79,145 @@ func ParseNamespacePathParameter(request *gin.Context) *common.NamespaceQuery {
 	}
 	return common.NewNamespaceQuery(nonEmptyNamespaces)
 }
+
+// ParseLogStreamOptions parses the container, allContainers, previous, follow (true unless set to
+// false), timestamps, sinceSeconds and tailLines query parameters of a log stream request.
+func ParseLogStreamOptions(request *gin.Context) (pod.LogStreamOptions, error) {
+	opts := pod.LogStreamOptions{
+		Container: request.Query("container"),
+		Follow:    true,
+	}
+	flags := map[string]*bool{
+		"allContainers": &opts.AllContainers,
+		"previous":      &opts.Previous,
+		"follow":        &opts.Follow,
+		"timestamps":    &opts.Timestamps,
+	}
+	for name, flag := range flags {
+		if value := request.Query(name); value != "" {
+			parsed, err := strconv.ParseBool(value)
+			if err != nil {
+				return opts, fmt.Errorf("invalid %s parameter %q", name, value)
+			}
+			*flag = parsed
+		}
+	}
+	numbers := map[string]**int64{
+		"sinceSeconds": &opts.SinceSeconds,
+		"tailLines":    &opts.TailLines,
+	}
+	for name, number := range numbers {
+		if value := request.Query(name); value != "" {
+			parsed, err := strconv.ParseInt(value, 10, 64)
+			if err != nil || parsed < 0 || (name == "sinceSeconds" && parsed == 0) {
+				return opts, fmt.Errorf("invalid %s parameter %q", name, value)
+			}
+			*number = &parsed
+		}
+	}
+	return opts, nil
+}
+
+// ParseLogSearchOptions parses the q, regex, caseSensitive, context and maxMatches query
+// parameters of a log search request.
+func ParseLogSearchOptions(request *gin.Context) (pod.LogSearchOptions, error) {
+	opts := pod.LogSearchOptions{Query: request.Query("q")}
+	flags := map[string]*bool{
+		"regex":         &opts.Regex,
+		"caseSensitive": &opts.CaseSensitive,
+	}
+	for name, flag := range flags {
+		if value := request.Query(name); value != "" {
+			parsed, err := strconv.ParseBool(value)
+			if err != nil {
+				return opts, fmt.Errorf("invalid %s parameter %q", name, value)
+			}
+			*flag = parsed
+		}
+	}
+	numbers := map[string]struct {
+		target *int
+		max    int
+	}{
+		"context":    {&opts.Context, pod.MaxLogSearchContext},
+		"maxMatches": {&opts.MaxMatches, pod.MaxLogSearchMatches},
+	}
+	for name, number := range numbers {
+		if value := request.Query(name); value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil || parsed < 0 || parsed > number.max {
+				return opts, fmt.Errorf("invalid %s parameter %q, expected 0 to %d", name, value, number.max)
+			}
+			*number.target = parsed
+		}
+	}
+	return opts, nil
+}
+
+// maxObjectBodySize bounds the manifest of a single object in a request body
+const maxObjectBodySize = 3 * 1024 * 1024
+
+// ParseDryRun parses the dryRun query parameter of a write request. "All" has the API server
+// validate and admit the change without persisting it, as kubectl --dry-run=server.
+func ParseDryRun(request *gin.Context) ([]string, error) {
+	switch value := request.Query("dryRun"); value {
+	case "":
+		return nil, nil
+	case metav1.DryRunAll:
+		return []string{metav1.DryRunAll}, nil
+	default:
+		return nil, fmt.Errorf("invalid dryRun parameter %q, expected %q", value, metav1.DryRunAll)
+	}
+}
+
+// ParseForce parses the force query parameter of a server-side apply request.
+func ParseForce(request *gin.Context) (bool, error) {
+	value := request.Query("force")
+	if value == "" {
+		return false, nil
+	}
+	force, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid force parameter %q", value)
+	}
+	return force, nil
+}
+
+// ParseObjectBody decodes the object manifest, JSON or YAML, of a request body. The namespace and
+// name of the object default to the given ones and must match them when set in the manifest.
+func ParseObjectBody(request *gin.Context, namespace, name string) (*unstructured.Unstructured, error) {
+	data, err := io.ReadAll(io.LimitReader(request.Request.Body, maxObjectBodySize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read request body: %w", err)
+	}
+	if len(data) > maxObjectBodySize {
+		return nil, fmt.Errorf("the manifest is larger than %d bytes", maxObjectBodySize)
+	}
+	data, err = yaml.YAMLToJSON(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid manifest: %w", err)
+	}
+	obj := &unstructured.Unstructured{}
+	if err := obj.UnmarshalJSON(data); err != nil {
+		return nil, fmt.Errorf("invalid manifest: %w", err)
+	}
+
+	if name != "" {
+		if obj.GetName() == "" {
+			obj.SetName(name)
+		} else if obj.GetName() != name {
+			return nil, fmt.Errorf("the manifest is named %s, not %s", obj.GetName(), name)
+		}
+	}
+	if namespace != "" {
+		if obj.GetNamespace() == "" {
+			obj.SetNamespace(namespace)
+		} else if obj.GetNamespace() != namespace {
+			return nil, fmt.Errorf("the manifest is in namespace %s, not %s", obj.GetNamespace(), namespace)
+		}
+	}
+	if obj.GetName() == "" {
+		return nil, fmt.Errorf("the manifest has no name")
+	}
+	return obj, nil
+}
@@ -0,0 +1,142 @@
+/*
+Copyright 2024 The Karmada Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+// streamHeartbeatInterval keeps idle streams from being closed by proxies
+const streamHeartbeatInterval = 15 * time.Second
+
+// StreamMessage is a WebSocket message of an EventStream
+type StreamMessage struct {
+	Event string      `json:"event"`
+	Data  interface{} `json:"data"`
+}
+
+// EventStream sends events to a client as Server-Sent Events, or as JSON WebSocket messages
+// when the client asks to upgrade the connection.
+type EventStream struct {
+	c      *gin.Context
+	ws     *websocket.Conn
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// streamUpgrader only accepts same-origin WebSocket connections, the default of gorilla.
+var streamUpgrader = websocket.Upgrader{}
+
+// NewEventStream starts streaming events to the client of c. The context of the stream is done
+// when the client goes away.
+func NewEventStream(c *gin.Context) (*EventStream, error) {
+	ctx, cancel := context.WithCancel(c.Request.Context())
+	s := &EventStream{c: c, ctx: ctx, cancel: cancel}
+	if !websocket.IsWebSocketUpgrade(c.Request) {
+		c.Header("Content-Type", "text/event-stream")
+		c.Header("Cache-Control", "no-cache")
+		c.Header("Connection", "keep-alive")
+		c.Header("X-Accel-Buffering", "no")
+		c.Status(200)
+		c.Writer.Flush()
+		return s, nil
+	}
+
+	ws, err := streamUpgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	s.ws = ws
+	// Read until the client closes the connection, WebSocket clients send nothing else
+	go func() {
+		defer cancel()
+		for {
+			if _, _, err := ws.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+	return s, nil
+}
+
+// Context returns a context that is done when the client went away.
+func (s *EventStream) Context() context.Context {
+	return s.ctx
+}
+
+// Send sends an event. Sends are not safe for concurrent use.
+func (s *EventStream) Send(event string, data interface{}) error {
+	if s.ws != nil {
+		return s.ws.WriteJSON(StreamMessage{Event: event, Data: data})
+	}
+	s.c.SSEvent(event, data)
+	s.c.Writer.Flush()
+	return s.ctx.Err()
+}
+
+// ping keeps the connection alive.
+func (s *EventStream) ping() error {
+	if s.ws != nil {
+		return s.ws.WriteControl(websocket.PingMessage, nil, time.Now().Add(streamHeartbeatInterval))
+	}
+	if _, err := io.WriteString(s.c.Writer, ": ping\n\n"); err != nil {
+		return err
+	}
+	s.c.Writer.Flush()
+	return nil
+}
+
+// Close ends the stream.
+func (s *EventStream) Close() {
+	s.cancel()
+	if s.ws != nil {
+		_ = s.ws.WriteControl(websocket.CloseMessage,
+			websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(time.Second))
+		_ = s.ws.Close()
+	}
+}
+
+// ForwardEvents sends every value received from values as an event, named by the event function,
+// until values is closed or the client goes away. It pings the client while no value arrives.
+// It reports whether values was drained.
+func ForwardEvents[T any](s *EventStream, values <-chan T, event func(T) string) bool {
+	heartbeat := time.NewTicker(streamHeartbeatInterval)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-s.ctx.Done():
+			return false
+		case value, ok := <-values:
+			if !ok {
+				return true
+			}
+			if err := s.Send(event(value), value); err != nil {
+				return false
+			}
+		case <-heartbeat.C:
+			if err := s.ping(); err != nil {
+				return false
+			}
+		}
+	}
+}
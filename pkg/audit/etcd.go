@@ -0,0 +1,153 @@
+/*
+Copyright 2024 The Karmada Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"k8s.io/klog/v2"
+)
+
+// EventKeyPrefix is the prefix for audit event keys in etcd. Keys end with the zero padded event
+// time so that they sort chronologically and a time range maps to a key range.
+const EventKeyPrefix = "/karmada/dashboard/audit/"
+
+// EtcdSink stores audit events in etcd and deletes them once they are older than the retention.
+type EtcdSink struct {
+	client    *clientv3.Client
+	retention time.Duration
+}
+
+// NewEtcdSink creates a sink keeping events for retention, 0 keeps them forever.
+func NewEtcdSink(client *clientv3.Client, retention time.Duration) *EtcdSink {
+	return &EtcdSink{
+		client:    client,
+		retention: retention,
+	}
+}
+
+// listBatchSize is how many keys List reads from etcd at once
+const listBatchSize = 500
+
+// timeKey returns the key events at t sort after.
+func timeKey(t time.Time) string {
+	return fmt.Sprintf("%s%020d", EventKeyPrefix, t.UnixNano())
+}
+
+// Write stores an event.
+func (s *EtcdSink) Write(ctx context.Context, event *Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit event: %v", err)
+	}
+	if _, err := s.client.Put(ctx, EventKeyPrefix+eventCursor(event), string(data)); err != nil {
+		return fmt.Errorf("failed to save audit event: %v", err)
+	}
+	return nil
+}
+
+// List reads the events between the query bounds from the most recent one down, in batches, until
+// the page is full. A page continues below the key of the last event of the previous page, so that
+// every event of the range can be reached whatever its age.
+func (s *EtcdSink) List(ctx context.Context, query Query) (*Page, error) {
+	if err := validateContinue(query.Continue); err != nil {
+		return nil, err
+	}
+	start := EventKeyPrefix
+	if !query.Since.IsZero() {
+		start = timeKey(query.Since)
+	}
+	end := clientv3.GetPrefixRangeEnd(EventKeyPrefix)
+	if !query.Until.IsZero() {
+		end = timeKey(query.Until)
+	}
+	if query.Continue != "" && EventKeyPrefix+query.Continue < end {
+		end = EventKeyPrefix + query.Continue
+	}
+
+	limit := query.limit()
+	page := &Page{Events: []Event{}}
+	for start < end {
+		resp, err := s.client.Get(ctx, start,
+			clientv3.WithRange(end),
+			clientv3.WithSort(clientv3.SortByKey, clientv3.SortDescend),
+			clientv3.WithLimit(listBatchSize))
+		if err != nil {
+			return nil, fmt.Errorf("failed to list audit events: %v", err)
+		}
+
+		for _, kv := range resp.Kvs {
+			event := Event{}
+			if err := json.Unmarshal(kv.Value, &event); err != nil {
+				klog.ErrorS(err, "Failed to unmarshal audit event", "key", string(kv.Key))
+				continue
+			}
+			if query.Match != nil && !query.Match(&event) {
+				continue
+			}
+			if len(page.Events) == limit {
+				page.Continue = eventCursor(&page.Events[limit-1])
+				return page, nil
+			}
+			page.Events = append(page.Events, event)
+		}
+		if !resp.More || len(resp.Kvs) == 0 {
+			break
+		}
+		end = string(resp.Kvs[len(resp.Kvs)-1].Key)
+	}
+	return page, nil
+}
+
+// Prune deletes the events older than the retention.
+func (s *EtcdSink) Prune(ctx context.Context) (int64, error) {
+	if s.retention <= 0 {
+		return 0, nil
+	}
+	resp, err := s.client.Delete(ctx, EventKeyPrefix, clientv3.WithRange(timeKey(time.Now().Add(-s.retention))))
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune audit events: %v", err)
+	}
+	return resp.Deleted, nil
+}
+
+// StartPruning prunes expired events every interval until ctx is done.
+func (s *EtcdSink) StartPruning(ctx context.Context, interval time.Duration) {
+	if s.retention <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			if deleted, err := s.Prune(ctx); err != nil {
+				klog.ErrorS(err, "Failed to prune audit events")
+			} else if deleted > 0 {
+				klog.V(2).InfoS("Pruned audit events", "count", deleted, "retention", s.retention)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
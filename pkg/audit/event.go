@@ -0,0 +1,143 @@
+/*
+Copyright 2024 The Karmada Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package audit
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// OutcomeSuccess is the outcome of a request that did what it was asked to
+	OutcomeSuccess = "success"
+	// OutcomeFailure is the outcome of a request that was rejected or failed
+	OutcomeFailure = "failure"
+
+	// maxSummaryDepth is how deep request body fields are listed in the summary
+	maxSummaryDepth = 3
+	// maxSummaryFields bounds the number of fields listed in the summary
+	maxSummaryFields = 50
+)
+
+// Event records a single mutating API call.
+type Event struct {
+	ID        string    `json:"id"`
+	Timestamp time.Time `json:"timestamp"`
+	// User is empty for anonymous calls, such as logins
+	User string `json:"user,omitempty"`
+	// APIToken is the ID of the API token the call was made with, empty for interactive sessions
+	APIToken  string `json:"apiToken,omitempty"`
+	SourceIP  string `json:"sourceIP"`
+	UserAgent string `json:"userAgent,omitempty"`
+	// Verb is create, update, patch or delete
+	Verb   string `json:"verb"`
+	Method string `json:"method"`
+	// Route is the matched route pattern, Path the requested path
+	Route string `json:"route"`
+	Path  string `json:"path"`
+
+	Cluster   string `json:"cluster,omitempty"`
+	Namespace string `json:"namespace,omitempty"`
+	Resource  string `json:"resource,omitempty"`
+	Name      string `json:"name,omitempty"`
+
+	Request *RequestSummary `json:"request,omitempty"`
+
+	StatusCode int    `json:"statusCode"`
+	Outcome    string `json:"outcome"`
+	Error      string `json:"error,omitempty"`
+	LatencyMs  int64  `json:"latencyMs"`
+}
+
+// RequestSummary describes a request body without recording its values, which may hold secrets.
+// Not even a hash of the body is kept, since it could be used to guess weak passwords.
+type RequestSummary struct {
+	Size int `json:"size"`
+	// Truncated is set when the body was too large to be summarized completely
+	Truncated bool `json:"truncated,omitempty"`
+	// Fields lists the fields the request sets, like spec.replicas
+	Fields []string `json:"fields,omitempty"`
+	// Kind, Namespace and Name are taken from Kubernetes objects in the body
+	Kind      string `json:"kind,omitempty"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name,omitempty"`
+}
+
+// Summarize describes a request body. Only the size of bodies that are not JSON objects is kept.
+func Summarize(body []byte, truncated bool) *RequestSummary {
+	if len(body) == 0 {
+		return nil
+	}
+	summary := &RequestSummary{Size: len(body), Truncated: truncated}
+
+	object := map[string]interface{}{}
+	if err := json.Unmarshal(body, &object); err != nil {
+		return summary
+	}
+	fields := map[string]struct{}{}
+	collectFields(object, "", 1, fields)
+	for field := range fields {
+		summary.Fields = append(summary.Fields, field)
+	}
+	sort.Strings(summary.Fields)
+	if len(summary.Fields) > maxSummaryFields {
+		summary.Fields = summary.Fields[:maxSummaryFields]
+	}
+
+	summary.Kind, _ = object["kind"].(string)
+	metadata, _ := object["metadata"].(map[string]interface{})
+	if metadata == nil {
+		// Dashboard requests often carry the target at the top level
+		metadata = object
+	}
+	summary.Namespace, _ = metadata["namespace"].(string)
+	summary.Name, _ = metadata["name"].(string)
+	return summary
+}
+
+// collectFields adds the dotted paths of the leaves of object, stopping at maxSummaryDepth.
+func collectFields(object map[string]interface{}, prefix string, depth int, fields map[string]struct{}) {
+	for key, value := range object {
+		path := key
+		if prefix != "" {
+			path = prefix + "." + key
+		}
+		if nested, ok := value.(map[string]interface{}); ok && depth < maxSummaryDepth && len(nested) > 0 {
+			collectFields(nested, path, depth+1, fields)
+			continue
+		}
+		fields[path] = struct{}{}
+	}
+}
+
+// VerbForMethod returns the audit verb of an HTTP method.
+func VerbForMethod(method string) string {
+	switch strings.ToUpper(method) {
+	case "POST":
+		return "create"
+	case "PUT":
+		return "update"
+	case "PATCH":
+		return "patch"
+	case "DELETE":
+		return "delete"
+	default:
+		return strings.ToLower(method)
+	}
+}
@@ -0,0 +1,105 @@
+/*
+Copyright 2024 The Karmada Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package audit
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+const (
+	// queueSize bounds the events waiting to be written, events are dropped beyond it so that a
+	// slow sink never blocks API calls
+	queueSize    = 1024
+	writeTimeout = 5 * time.Second
+)
+
+var (
+	sink   Sink
+	queue  chan *Event
+	initMu sync.Mutex
+)
+
+// Init starts recording events to s. Without Init, events are dropped.
+func Init(s Sink) {
+	initMu.Lock()
+	defer initMu.Unlock()
+	if sink != nil {
+		return
+	}
+	sink = s
+	queue = make(chan *Event, queueSize)
+	go func() {
+		for event := range queue {
+			ctx, cancel := context.WithTimeout(context.Background(), writeTimeout)
+			if err := sink.Write(ctx, event); err != nil {
+				klog.ErrorS(err, "Failed to write audit event", "user", event.User, "path", event.Path)
+			}
+			cancel()
+		}
+	}()
+}
+
+// Enabled reports whether events are recorded.
+func Enabled() bool {
+	initMu.Lock()
+	defer initMu.Unlock()
+	return sink != nil
+}
+
+// Record queues an event for writing, filling in its ID.
+func Record(event *Event) {
+	initMu.Lock()
+	q := queue
+	initMu.Unlock()
+	if q == nil {
+		return
+	}
+	if event.ID == "" {
+		event.ID = newEventID()
+	}
+	select {
+	case q <- event:
+	default:
+		klog.InfoS("Audit queue is full, dropping event", "user", event.User, "verb", event.Verb, "path", event.Path)
+	}
+}
+
+// List reads a page of recorded events.
+func List(ctx context.Context, query Query) (*Page, error) {
+	initMu.Lock()
+	s := sink
+	initMu.Unlock()
+	if s == nil {
+		return nil, fmt.Errorf("audit log is disabled")
+	}
+	return s.List(ctx, query)
+}
+
+func newEventID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,188 @@
+/*
+Copyright 2024 The Karmada Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package audit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+// MaxListedEvents bounds how many events a single List call returns.
+const MaxListedEvents = 1000
+
+// ErrInvalidContinue is returned for continue tokens that were not returned by List.
+var ErrInvalidContinue = errors.New("invalid continue token")
+
+// Query selects events by time, both bounds are optional, and pages through them.
+type Query struct {
+	Since time.Time
+	Until time.Time
+	// Match further selects events when set
+	Match func(event *Event) bool
+	// Limit is the size of a page, MaxListedEvents when 0 or larger
+	Limit int
+	// Continue is the token of the previous page, empty for the first page
+	Continue string
+}
+
+// Page is a page of events, most recent first.
+type Page struct {
+	Events []Event
+	// Continue lists the next page, it is empty on the last page
+	Continue string
+}
+
+func (q Query) matches(event *Event) bool {
+	if !q.Since.IsZero() && event.Timestamp.Before(q.Since) {
+		return false
+	}
+	if !q.Until.IsZero() && !event.Timestamp.Before(q.Until) {
+		return false
+	}
+	if q.Continue != "" && eventCursor(event) >= q.Continue {
+		return false
+	}
+	return q.Match == nil || q.Match(event)
+}
+
+func (q Query) limit() int {
+	if q.Limit <= 0 || q.Limit > MaxListedEvents {
+		return MaxListedEvents
+	}
+	return q.Limit
+}
+
+// validateContinue checks that token has the form of an eventCursor.
+func validateContinue(token string) error {
+	if token == "" {
+		return nil
+	}
+	nanos, id, ok := strings.Cut(token, "-")
+	if !ok || len(nanos) != 20 || id == "" || strings.ContainsAny(nanos+id, "/-") {
+		return ErrInvalidContinue
+	}
+	if _, err := strconv.ParseUint(nanos, 10, 64); err != nil {
+		return ErrInvalidContinue
+	}
+	return nil
+}
+
+// eventCursor orders events chronologically, it is also the continue token of a page ending with
+// the event.
+func eventCursor(event *Event) string {
+	return fmt.Sprintf("%020d-%s", event.Timestamp.UnixNano(), event.ID)
+}
+
+// Sink persists audit events.
+type Sink interface {
+	Write(ctx context.Context, event *Event) error
+	// List returns a page of the events matching query, most recent first
+	List(ctx context.Context, query Query) (*Page, error)
+}
+
+// FileSink appends events as JSON lines to a file, for shipping them with a log collector.
+type FileSink struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewFileSink creates a sink writing to path.
+func NewFileSink(path string) (*FileSink, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log %s: %w", path, err)
+	}
+	_ = file.Close()
+	return &FileSink{path: path}, nil
+}
+
+// Write appends an event.
+func (s *FileSink) Write(_ context.Context, event *Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit event: %w", err)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// Reopen for every event so that external log rotation is picked up
+	file, err := os.OpenFile(s.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer file.Close()
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write audit event: %w", err)
+	}
+	return nil
+}
+
+// List reads the events of the current file. The file is read to its end for every page, only
+// the page being listed is kept in memory.
+func (s *FileSink) List(_ context.Context, query Query) (*Page, error) {
+	if err := validateContinue(query.Continue); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	file, err := os.Open(s.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer file.Close()
+
+	// The file is in chronological order, the last limit+1 matches tell whether a page follows
+	limit := query.limit()
+	events := []Event{}
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		event := Event{}
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			klog.V(4).InfoS("Skipping malformed audit log line", "error", err)
+			continue
+		}
+		if !query.matches(&event) {
+			continue
+		}
+		events = append(events, event)
+		if len(events) > limit+1 {
+			events = events[1:]
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read audit log: %w", err)
+	}
+
+	slices.Reverse(events)
+	page := &Page{Events: events}
+	if len(events) > limit {
+		page.Events = events[:limit]
+		page.Continue = eventCursor(&page.Events[limit-1])
+	}
+	return page, nil
+}
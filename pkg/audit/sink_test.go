@@ -0,0 +1,104 @@
+/*
+Copyright 2024 The Karmada Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package audit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/karmada-io/dashboard/pkg/etcd/etcdtest"
+)
+
+func TestListPages(t *testing.T) {
+	fileSink, err := NewFileSink(filepath.Join(t.TempDir(), "audit.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sinks := map[string]Sink{
+		"etcd": NewEtcdSink(etcdtest.NewClient(), 0),
+		"file": fileSink,
+	}
+
+	// More events than a batch of the etcd sink, on alternating clusters
+	const total = 3*listBatchSize + 7
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, sink := range sinks {
+		for i := 0; i < total; i++ {
+			event := &Event{ID: fmt.Sprintf("%08x", i), Timestamp: start.Add(time.Duration(i) * time.Second), Cluster: fmt.Sprintf("member%d", i%2)}
+			if err := sink.Write(context.TODO(), event); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	cases := []struct {
+		query         Query
+		expectedCount int
+		expectedFirst int
+		expectedLast  int
+	}{
+		{Query{Limit: 100}, total, total - 1, 0},
+		{Query{Limit: 250, Match: func(e *Event) bool { return e.Cluster == "member0" }}, (total + 1) / 2, total - 1, 0},
+		{Query{Limit: 7, Since: start.Add(10 * time.Second), Until: start.Add(30 * time.Second)}, 20, 29, 10},
+		{Query{Since: start.Add(time.Hour)}, 0, -1, -1},
+	}
+	for name, sink := range sinks {
+		for _, c := range cases {
+			var events []Event
+			query := c.query
+			for pages := 0; ; pages++ {
+				if pages > total {
+					t.Fatalf("%s: listing does not end", name)
+				}
+				page, err := sink.List(context.TODO(), query)
+				if err != nil {
+					t.Fatalf("%s: List: %v", name, err)
+				}
+				if len(page.Events) > query.limit() {
+					t.Errorf("%s: got a page of %d events, limit is %d", name, len(page.Events), query.limit())
+				}
+				events = append(events, page.Events...)
+				if page.Continue == "" {
+					break
+				}
+				query.Continue = page.Continue
+			}
+
+			if len(events) != c.expectedCount {
+				t.Errorf("%s %+v: got %d events, expected %d", name, c.query, len(events), c.expectedCount)
+				continue
+			}
+			for i := 1; i < len(events); i++ {
+				if !events[i].Timestamp.Before(events[i-1].Timestamp) {
+					t.Errorf("%s: events are not listed most recent first at %d", name, i)
+					break
+				}
+			}
+			if len(events) > 0 && (events[0].ID != fmt.Sprintf("%08x", c.expectedFirst) || events[len(events)-1].ID != fmt.Sprintf("%08x", c.expectedLast)) {
+				t.Errorf("%s %+v: got events %s to %s, expected %08x to %08x", name, c.query, events[0].ID, events[len(events)-1].ID, c.expectedFirst, c.expectedLast)
+			}
+		}
+
+		if _, err := sink.List(context.TODO(), Query{Continue: "/karmada/dashboard/users/"}); !errors.Is(err, ErrInvalidContinue) {
+			t.Errorf("%s: List with a forged continue token: got %v, expected ErrInvalidContinue", name, err)
+		}
+	}
+}
@@ -0,0 +1,263 @@
+/*
+Copyright 2024 The Karmada Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	"github.com/karmada-io/dashboard/pkg/etcd"
+)
+
+const (
+	// APITokenPrefix starts every API token, so that they are told apart from JWTs and found by
+	// secret scanners
+	APITokenPrefix = "kdp_"
+	// ScopeRead lets a token make read-only requests
+	ScopeRead = "read"
+	// ScopeWrite lets a token make requests that change state, it implies ScopeRead
+	ScopeWrite = "write"
+
+	// DefaultAPITokenLifetime is how long a token lasts when no lifetime is requested
+	DefaultAPITokenLifetime = 90 * 24 * time.Hour
+	// MaxAPITokenLifetime bounds the lifetime of a token
+	MaxAPITokenLifetime = 365 * 24 * time.Hour
+	// apiTokenTouchInterval is how often the last use of a token is written to etcd
+	apiTokenTouchInterval = time.Minute
+	maxAPITokenNameLength = 64
+)
+
+// ErrInvalidAPIToken is returned for unknown, expired and malformed API tokens
+var ErrInvalidAPIToken = errors.New("invalid or expired API token")
+
+// apiTokenStore persists API tokens.
+type apiTokenStore interface {
+	CreateToken(ctx context.Context, token *etcd.APIToken) error
+	GetToken(ctx context.Context, id string) (*etcd.APIToken, error)
+	TouchToken(ctx context.Context, token *etcd.APIToken, usedAt time.Time) error
+	DeleteToken(ctx context.Context, id string) error
+	ListTokens(ctx context.Context, username string) ([]*etcd.APIToken, error)
+}
+
+var apiTokens apiTokenStore
+
+// APITokenOptions describes a token to create.
+type APITokenOptions struct {
+	Name        string
+	Description string
+	// Scopes default to read-only
+	Scopes   []string
+	Clusters []string
+	// Lifetime defaults to DefaultAPITokenLifetime
+	Lifetime time.Duration
+}
+
+// IsAPIToken reports whether a bearer token is an API token rather than a JWT.
+func IsAPIToken(token string) bool {
+	return strings.HasPrefix(token, APITokenPrefix)
+}
+
+// CreateAPIToken creates a token acting as owner on behalf of createdBy, who is either the owner
+// or an administrator. The returned secret is shown once, only its hash is stored.
+func CreateAPIToken(ctx context.Context, owner, createdBy string, opts APITokenOptions) (*etcd.APIToken, string, error) {
+	if apiTokens == nil {
+		return nil, "", fmt.Errorf("API tokens are not available")
+	}
+	name := strings.TrimSpace(opts.Name)
+	if name == "" || len(name) > maxAPITokenNameLength {
+		return nil, "", fmt.Errorf("token name must be between 1 and %d characters long", maxAPITokenNameLength)
+	}
+	scopes, err := normalizeScopes(opts.Scopes)
+	if err != nil {
+		return nil, "", err
+	}
+	lifetime := opts.Lifetime
+	if lifetime == 0 {
+		lifetime = DefaultAPITokenLifetime
+	}
+	if lifetime < 0 || lifetime > MaxAPITokenLifetime {
+		return nil, "", fmt.Errorf("token lifetime must be positive and at most %d days", int(MaxAPITokenLifetime.Hours()/24))
+	}
+	if _, err := userRole(ctx, owner); err != nil {
+		return nil, "", fmt.Errorf("unknown user %s: %w", owner, err)
+	}
+
+	existing, err := apiTokens.ListTokens(ctx, owner)
+	if err != nil {
+		return nil, "", err
+	}
+	for _, token := range existing {
+		if token.Name == name {
+			return nil, "", fmt.Errorf("user %s already has a token named %s", owner, name)
+		}
+	}
+
+	id, err := randomToken(12)
+	if err != nil {
+		return nil, "", err
+	}
+	secret, err := randomToken(32)
+	if err != nil {
+		return nil, "", err
+	}
+	now := time.Now()
+	token := &etcd.APIToken{
+		ID:          id,
+		Name:        name,
+		Description: opts.Description,
+		Username:    owner,
+		SecretHash:  hashSecret(secret),
+		Scopes:      scopes,
+		Clusters:    opts.Clusters,
+		CreatedBy:   createdBy,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(lifetime),
+	}
+	if err := apiTokens.CreateToken(ctx, token); err != nil {
+		return nil, "", err
+	}
+	klog.InfoS("API token created", "id", id, "name", name, "username", owner, "createdBy", createdBy, "scopes", scopes)
+	return token, APITokenPrefix + id + "." + secret, nil
+}
+
+// normalizeScopes validates scopes and sorts them, defaulting to read-only.
+func normalizeScopes(scopes []string) ([]string, error) {
+	if len(scopes) == 0 {
+		return []string{ScopeRead}, nil
+	}
+	normalized := []string{}
+	for _, scope := range scopes {
+		if scope != ScopeRead && scope != ScopeWrite {
+			return nil, fmt.Errorf("unknown scope %q, expected %s or %s", scope, ScopeRead, ScopeWrite)
+		}
+		if !slices.Contains(normalized, scope) {
+			normalized = append(normalized, scope)
+		}
+	}
+	slices.Sort(normalized)
+	return normalized, nil
+}
+
+// AuthenticateAPIToken resolves an API token to its stored record. The owner has to still exist.
+func AuthenticateAPIToken(ctx context.Context, token string) (*etcd.APIToken, error) {
+	if apiTokens == nil {
+		return nil, ErrInvalidAPIToken
+	}
+	id, secret, ok := strings.Cut(strings.TrimPrefix(token, APITokenPrefix), ".")
+	if !IsAPIToken(token) || !ok || id == "" || secret == "" {
+		return nil, ErrInvalidAPIToken
+	}
+
+	stored, err := apiTokens.GetToken(ctx, id)
+	if err != nil {
+		klog.V(4).InfoS("API token lookup failed", "id", id, "error", err)
+		return nil, ErrInvalidAPIToken
+	}
+	if subtle.ConstantTimeCompare([]byte(stored.SecretHash), []byte(hashSecret(secret))) != 1 {
+		return nil, ErrInvalidAPIToken
+	}
+	now := time.Now()
+	if !now.Before(stored.ExpiresAt) {
+		return nil, ErrInvalidAPIToken
+	}
+	if _, err := userRole(ctx, stored.Username); err != nil {
+		klog.InfoS("Rejecting API token of unknown user", "id", id, "username", stored.Username)
+		return nil, ErrInvalidAPIToken
+	}
+
+	if now.Sub(stored.LastUsedAt) > apiTokenTouchInterval {
+		if err := apiTokens.TouchToken(ctx, stored, now); err != nil {
+			klog.ErrorS(err, "Failed to record API token use", "id", id)
+		}
+	}
+	return stored, nil
+}
+
+// AuthorizeAPIToken checks a request against the scopes of a token. cluster is the member
+// cluster the request targets, if any. The permissions of the owner are checked separately.
+func AuthorizeAPIToken(token *etcd.APIToken, mutating bool, cluster string) error {
+	if mutating && !slices.Contains(token.Scopes, ScopeWrite) {
+		return fmt.Errorf("API token %s is read-only", token.Name)
+	}
+	if cluster != "" && len(token.Clusters) > 0 && !slices.Contains(token.Clusters, cluster) {
+		return fmt.Errorf("API token %s is not allowed to access cluster %s", token.Name, cluster)
+	}
+	return nil
+}
+
+// AuthorizeAPITokenCluster checks that the API token of the request carried in ctx, if any, is
+// allowed to access cluster. Unlike AuthorizeAPIToken it applies to every cluster a request
+// reaches, not only the one in its path, e.g. the clusters of aggregated lists or log streams.
+func AuthorizeAPITokenCluster(ctx context.Context, cluster string) error {
+	clusters := APITokenClustersFromContext(ctx)
+	if cluster != "" && len(clusters) > 0 && !slices.Contains(clusters, cluster) {
+		return fmt.Errorf("API token is not allowed to access cluster %s", cluster)
+	}
+	return nil
+}
+
+// ListAPITokens lists the tokens of a user, or of all users when username is empty.
+func ListAPITokens(ctx context.Context, username string) ([]*etcd.APIToken, error) {
+	if apiTokens == nil {
+		return nil, fmt.Errorf("API tokens are not available")
+	}
+	return apiTokens.ListTokens(ctx, username)
+}
+
+// DeleteAPIToken deletes a token. Unless username is empty, which is for administrators, the
+// token has to belong to username.
+func DeleteAPIToken(ctx context.Context, id, username string) error {
+	if apiTokens == nil {
+		return fmt.Errorf("API tokens are not available")
+	}
+	token, err := apiTokens.GetToken(ctx, id)
+	if err != nil {
+		return err
+	}
+	if username != "" && token.Username != username {
+		return fmt.Errorf("API token %s not found", id)
+	}
+	if err := apiTokens.DeleteToken(ctx, id); err != nil {
+		return err
+	}
+	klog.InfoS("API token deleted", "id", id, "name", token.Name, "username", token.Username)
+	return nil
+}
+
+// DeleteUserAPITokens deletes every token of a user, e.g. when the user is deleted.
+func DeleteUserAPITokens(ctx context.Context, username string) error {
+	if apiTokens == nil {
+		return nil
+	}
+	tokens, err := apiTokens.ListTokens(ctx, username)
+	if err != nil {
+		return err
+	}
+	for _, token := range tokens {
+		if err := apiTokens.DeleteToken(ctx, token.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,195 @@
+/*
+Copyright 2024 The Karmada Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auth
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/karmada-io/dashboard/pkg/etcd"
+)
+
+// fakeAPITokenStore keeps API tokens in memory.
+type fakeAPITokenStore struct {
+	mu     sync.Mutex
+	tokens map[string]etcd.APIToken
+}
+
+func (f *fakeAPITokenStore) CreateToken(_ context.Context, token *etcd.APIToken) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.tokens[token.ID] = *token
+	return nil
+}
+
+func (f *fakeAPITokenStore) GetToken(_ context.Context, id string) (*etcd.APIToken, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	token, ok := f.tokens[id]
+	if !ok {
+		return nil, fmt.Errorf("API token %s not found", id)
+	}
+	return &token, nil
+}
+
+func (f *fakeAPITokenStore) TouchToken(_ context.Context, token *etcd.APIToken, usedAt time.Time) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	updated := *token
+	updated.LastUsedAt = usedAt
+	f.tokens[token.ID] = updated
+	return nil
+}
+
+func (f *fakeAPITokenStore) DeleteToken(_ context.Context, id string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.tokens, id)
+	return nil
+}
+
+func (f *fakeAPITokenStore) ListTokens(_ context.Context, username string) ([]*etcd.APIToken, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	result := []*etcd.APIToken{}
+	for _, token := range f.tokens {
+		if username == "" || token.Username == username {
+			t := token
+			result = append(result, &t)
+		}
+	}
+	return result, nil
+}
+
+func useFakeAPITokens(t *testing.T) *fakeAPITokenStore {
+	store := &fakeAPITokenStore{tokens: map[string]etcd.APIToken{}}
+	previousStore, previousRole := apiTokens, userRole
+	apiTokens = store
+	userRole = func(_ context.Context, username string) (string, error) {
+		if username == "deleted" {
+			return "", fmt.Errorf("user %s not found", username)
+		}
+		return "basic_user", nil
+	}
+	t.Cleanup(func() {
+		apiTokens, userRole = previousStore, previousRole
+	})
+	return store
+}
+
+func TestAuthenticateAPIToken(t *testing.T) {
+	store := useFakeAPITokens(t)
+	ctx := context.TODO()
+
+	token, secret, err := CreateAPIToken(ctx, "alice", "alice", APITokenOptions{Name: "ci"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !IsAPIToken(secret) {
+		t.Errorf("IsAPIToken(%q) == false", secret)
+	}
+	if len(token.Scopes) != 1 || token.Scopes[0] != ScopeRead {
+		t.Errorf("default scopes == %v, expected [%s]", token.Scopes, ScopeRead)
+	}
+	if _, _, err := CreateAPIToken(ctx, "alice", "alice", APITokenOptions{Name: "ci"}); err == nil {
+		t.Errorf("CreateAPIToken() accepted a duplicate name")
+	}
+
+	authenticated, err := AuthenticateAPIToken(ctx, secret)
+	if err != nil {
+		t.Fatalf("AuthenticateAPIToken() failed: %v", err)
+	}
+	if authenticated.Username != "alice" {
+		t.Errorf("token owner == %s, expected alice", authenticated.Username)
+	}
+	if store.tokens[token.ID].LastUsedAt.IsZero() {
+		t.Errorf("token use was not recorded")
+	}
+
+	for _, invalid := range []string{"", APITokenPrefix, APITokenPrefix + token.ID, APITokenPrefix + token.ID + ".wrong", APITokenPrefix + "unknown.secret"} {
+		if _, err := AuthenticateAPIToken(ctx, invalid); err == nil {
+			t.Errorf("AuthenticateAPIToken(%q) succeeded", invalid)
+		}
+	}
+
+	expired := store.tokens[token.ID]
+	expired.ExpiresAt = time.Now().Add(-time.Second)
+	store.tokens[token.ID] = expired
+	if _, err := AuthenticateAPIToken(ctx, secret); err == nil {
+		t.Errorf("AuthenticateAPIToken() accepted an expired token")
+	}
+
+	orphan, orphanSecret, err := CreateAPIToken(ctx, "alice", "admin", APITokenOptions{Name: "orphan"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	orphan.Username = "deleted"
+	store.tokens[orphan.ID] = *orphan
+	if _, err := AuthenticateAPIToken(ctx, orphanSecret); err == nil {
+		t.Errorf("AuthenticateAPIToken() accepted a token of a deleted user")
+	}
+}
+
+func TestAuthorizeAPIToken(t *testing.T) {
+	cases := []struct {
+		scopes   []string
+		clusters []string
+		mutating bool
+		cluster  string
+		allowed  bool
+	}{
+		{[]string{ScopeRead}, nil, false, "member1", true},
+		{[]string{ScopeRead}, nil, true, "", false},
+		{[]string{ScopeRead, ScopeWrite}, nil, true, "member1", true},
+		{[]string{ScopeWrite}, []string{"member1"}, true, "member1", true},
+		{[]string{ScopeWrite}, []string{"member1"}, false, "member2", false},
+		{[]string{ScopeRead}, []string{"member1"}, false, "", true},
+	}
+
+	for _, c := range cases {
+		token := &etcd.APIToken{Name: "ci", Scopes: c.scopes, Clusters: c.clusters}
+		err := AuthorizeAPIToken(token, c.mutating, c.cluster)
+		if (err == nil) != c.allowed {
+			t.Errorf("AuthorizeAPIToken(%v, %v, %v, %q) == %v, expected allowed %v", c.scopes, c.clusters, c.mutating, c.cluster, err, c.allowed)
+		}
+	}
+}
+
+func TestAuthorizeAPITokenCluster(t *testing.T) {
+	restricted := WithAPITokenClusters(context.Background(), []string{"member1"})
+	cases := []struct {
+		ctx     context.Context
+		cluster string
+		allowed bool
+	}{
+		{context.Background(), "member2", true},
+		{WithAPITokenClusters(context.Background(), nil), "member2", true},
+		{restricted, "member1", true},
+		{restricted, "member2", false},
+		{restricted, "", true},
+	}
+
+	for _, c := range cases {
+		err := AuthorizeAPITokenCluster(c.ctx, c.cluster)
+		if (err == nil) != c.allowed {
+			t.Errorf("AuthorizeAPITokenCluster(%v, %q) == %v, expected allowed %v", APITokenClustersFromContext(c.ctx), c.cluster, err, c.allowed)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+/*
+Copyright 2024 The Karmada Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auth
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+)
+
+// userContextKey is the context key under which the authenticated username is stored.
+type userContextKey struct{}
+
+// WithUser returns a copy of ctx that carries the username of the authenticated caller.
+func WithUser(ctx context.Context, username string) context.Context {
+	return context.WithValue(ctx, userContextKey{}, username)
+}
+
+// UserFromContext returns the username of the authenticated caller carried in ctx.
+// A *gin.Context is resolved through its underlying request context.
+// An empty string means the call is anonymous or issued by the dashboard itself.
+func UserFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	if c, ok := ctx.(*gin.Context); ok {
+		if c.Request == nil {
+			return ""
+		}
+		ctx = c.Request.Context()
+	}
+	username, _ := ctx.Value(userContextKey{}).(string)
+	return username
+}
+
+// apiTokenContextKey is the context key under which the ID of the API token a request
+// authenticated with is stored.
+type apiTokenContextKey struct{}
+
+// WithAPIToken returns a copy of ctx that records the API token the caller authenticated with.
+func WithAPIToken(ctx context.Context, tokenID string) context.Context {
+	return context.WithValue(ctx, apiTokenContextKey{}, tokenID)
+}
+
+// APITokenFromContext returns the ID of the API token the caller authenticated with, empty when
+// the caller signed in interactively.
+func APITokenFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	if c, ok := ctx.(*gin.Context); ok {
+		if c.Request == nil {
+			return ""
+		}
+		ctx = c.Request.Context()
+	}
+	tokenID, _ := ctx.Value(apiTokenContextKey{}).(string)
+	return tokenID
+}
+
+// authorizedClusterContextKey is the context key under which the cluster a request was
+// authorized for by its route is stored.
+type authorizedClusterContextKey struct{}
+
+// WithAuthorizedCluster returns a copy of ctx that records that the caller was authorized for the
+// scope of a request on clusterName, e.g. by namespace or resource grants for the namespace the
+// request is limited to. Clients of that cluster are then created for the request without
+// requiring cluster-wide access.
+func WithAuthorizedCluster(ctx context.Context, clusterName string) context.Context {
+	return context.WithValue(ctx, authorizedClusterContextKey{}, clusterName)
+}
+
+// AuthorizedClusterFromContext returns the cluster the request carried in ctx was authorized for,
+// empty if none.
+func AuthorizedClusterFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	if c, ok := ctx.(*gin.Context); ok {
+		if c.Request == nil {
+			return ""
+		}
+		ctx = c.Request.Context()
+	}
+	clusterName, _ := ctx.Value(authorizedClusterContextKey{}).(string)
+	return clusterName
+}
+
+// apiTokenClustersContextKey is the context key under which the clusters the API token of a
+// request is restricted to are stored.
+type apiTokenClustersContextKey struct{}
+
+// WithAPITokenClusters returns a copy of ctx that restricts the request to the member clusters an
+// API token is limited to. An empty list does not restrict the request.
+func WithAPITokenClusters(ctx context.Context, clusters []string) context.Context {
+	return context.WithValue(ctx, apiTokenClustersContextKey{}, clusters)
+}
+
+// APITokenClustersFromContext returns the member clusters the API token of the request carried in
+// ctx is restricted to, nil when the request is not restricted.
+func APITokenClustersFromContext(ctx context.Context) []string {
+	if ctx == nil {
+		return nil
+	}
+	if c, ok := ctx.(*gin.Context); ok {
+		if c.Request == nil {
+			return nil
+		}
+		ctx = c.Request.Context()
+	}
+	clusters, _ := ctx.Value(apiTokenClustersContextKey{}).([]string)
+	return clusters
+}
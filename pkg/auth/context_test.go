@@ -0,0 +1,78 @@
+/*
+Copyright 2024 The Karmada Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auth
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestUserFromContext(t *testing.T) {
+	ginCtx := func(username string) context.Context {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest("GET", "/", nil)
+		if username != "" {
+			c.Request = c.Request.WithContext(WithUser(c.Request.Context(), username))
+		}
+		return c
+	}
+
+	cases := []struct {
+		name     string
+		ctx      context.Context
+		expected string
+	}{
+		{"nil context", nil, ""},
+		{"anonymous context", context.Background(), ""},
+		{"plain context", WithUser(context.Background(), "alice"), "alice"},
+		{"overridden user", WithUser(WithUser(context.Background(), "alice"), "bob"), "bob"},
+		{"gin context", ginCtx("carol"), "carol"},
+		{"anonymous gin context", ginCtx(""), ""},
+	}
+
+	for _, c := range cases {
+		if actual := UserFromContext(c.ctx); actual != c.expected {
+			t.Errorf("%s: UserFromContext() == %q, expected %q", c.name, actual, c.expected)
+		}
+	}
+}
+
+func TestAuthorizedClusterFromContext(t *testing.T) {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("GET", "/", nil)
+	c.Request = c.Request.WithContext(WithAuthorizedCluster(WithUser(c.Request.Context(), "alice"), "member1"))
+
+	cases := []struct {
+		name     string
+		ctx      context.Context
+		expected string
+	}{
+		{"nil context", nil, ""},
+		{"unauthorized context", WithUser(context.Background(), "alice"), ""},
+		{"plain context", WithAuthorizedCluster(context.Background(), "member1"), "member1"},
+		{"gin context", c, "member1"},
+	}
+
+	for _, c := range cases {
+		if actual := AuthorizedClusterFromContext(c.ctx); actual != c.expected {
+			t.Errorf("%s: AuthorizedClusterFromContext() == %q, expected %q", c.name, actual, c.expected)
+		}
+	}
+}
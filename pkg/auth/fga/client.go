@@ -18,10 +18,11 @@ package fga
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"net/url"
+	"strings"
 
+	openfga "github.com/openfga/go-sdk"
 	"github.com/openfga/go-sdk/client"
 	"k8s.io/klog/v2"
 )
@@ -38,6 +39,60 @@ type Client interface {
 	WriteTuple(ctx context.Context, user, relation, objectType, objectID string) error
 	// DeleteTuple deletes a tuple from OpenFGA
 	DeleteTuple(ctx context.Context, user, relation, objectType, objectID string) error
+	// CheckWithContextualTuples determines if a user has a particular relation with an object,
+	// taking the given tuples into account without persisting them
+	CheckWithContextualTuples(ctx context.Context, user, relation, objectType, objectID string, contextualTuples []Tuple) (bool, error)
+	// ReadTuples returns the stored tuples matching the filter. Empty fields act as wildcards
+	// within the limits of the OpenFGA read API
+	ReadTuples(ctx context.Context, user, relation, objectType, objectID string) ([]Tuple, error)
+}
+
+// Tuple is a relationship tuple between a subject and an object.
+type Tuple struct {
+	// User is a username, a group subject built by GroupSubject or, for the parent relations,
+	// an object such as "cluster:member1"
+	User       string `json:"user"`
+	Relation   string `json:"relation"`
+	ObjectType string `json:"objectType"`
+	ObjectID   string `json:"objectID"`
+}
+
+// GroupSubject returns the subject that stands for every member of the group.
+func GroupSubject(group string) string {
+	return fmt.Sprintf("%s:%s#%s", TypeGroup, group, RelationMember)
+}
+
+// isGroupSubject reports whether subject has the form built by GroupSubject.
+func isGroupSubject(subject string) bool {
+	rest, ok := strings.CutPrefix(subject, TypeGroup+":")
+	if !ok {
+		return false
+	}
+	group, ok := strings.CutSuffix(rest, "#"+RelationMember)
+	return ok && group != "" && !strings.ContainsAny(group, ":#")
+}
+
+// formatSubject qualifies a username with the user type. Group subjects are passed on as they
+// are, usernames never take their form as they cannot contain ":" or "#".
+func formatSubject(user string) string {
+	if isGroupSubject(user) {
+		return user
+	}
+	return fmt.Sprintf("%s:%s", TypeUser, user)
+}
+
+// formatTupleUser qualifies the user of a tuple. The parent relations link objects, their user
+// is an object rather than a subject.
+func formatTupleUser(t Tuple) string {
+	if t.Relation == RelationParentCluster || t.Relation == RelationParentNamespace {
+		return t.User
+	}
+	return formatSubject(t.User)
+}
+
+// parseSubject strips the user type from a user subject and leaves other subjects untouched.
+func parseSubject(subject string) string {
+	return strings.TrimPrefix(subject, TypeUser+":")
 }
 
 // OpenFGAClient implements the Client interface using OpenFGA
@@ -145,113 +200,89 @@ func (c *OpenFGAClient) initializeStore(ctx context.Context, apiURL string) erro
 	// Update the client
 	c.fgaClient = newClient
 
-	// Create basic authorization model for Karmada Dashboard if it doesn't exist
-	if c.authModelID == "" {
-		if err := c.createAuthorizationModel(ctx); err != nil {
-			return err
-		}
-	}
-
-	return nil
+	// Make sure the store serves the authorization model this version of the dashboard expects
+	return c.ensureAuthorizationModel(ctx)
 }
 
-// createAuthorizationModel creates a minimal authorization model for Karmada Dashboard
-func (c *OpenFGAClient) createAuthorizationModel(ctx context.Context) error {
-	minimalModel := `{
-  "schema_version": "1.1",
-  "type_definitions": [
-    {
-      "type": "user"
-    },
-    {
-      "type": "dashboard",
-      "relations": {
-        "admin": {
-          "this": {}
-        },
-        "basic_user": {
-          "this": {}
-        }
-      },
-	  "metadata": {
-        "relations": {
-          "admin": {
-            "directly_related_user_types": [
-              {
-                "type": "user"
-              }
-            ]
-          },
-          "basic_user": {
-            "directly_related_user_types": [
-              {
-                "type": "user"
-              }
-            ]
-          }
-        }
-      }
-    },
-    {
-      "type": "cluster",
-      "relations": {
-        "owner": {
-          "this": {}
-        },
-        "member": {
-          "this": {}
-        }
-      },
-	  "metadata": {
-        "relations": {
-          "owner": {
-            "directly_related_user_types": [
-              {
-                "type": "user"
-              }
-            ]
-          },
-          "member": {
-            "directly_related_user_types": [
-              {
-                "type": "user"
-              }
-            ]
-          }
-        }
-      }
-    }
-  ]
-}`
-
-	apiURL := c.fgaClient.GetConfig().ApiUrl
-	if apiURL == "" {
-		apiURL = fmt.Sprintf("%s://%s", c.fgaClient.GetConfig().ApiScheme, c.fgaClient.GetConfig().ApiHost)
+// ensureAuthorizationModel makes sure the store serves the latest authorization model and pins the client to it.
+// Stores that still serve an older known model are migrated by writing the new model and running the
+// tuple migrations of every version in between. A store whose latest model is unknown gets the latest model
+// written on top of it without migrations.
+func (c *OpenFGAClient) ensureAuthorizationModel(ctx context.Context) error {
+	latest, err := c.fgaClient.ReadLatestAuthorizationModel(ctx).Execute()
+	if err != nil {
+		return fmt.Errorf("failed to read latest authorization model: %w", err)
 	}
 
-	storeURL := fmt.Sprintf("%s/stores/%s/authorization-models", apiURL, c.storeID)
-	klog.InfoS("Creating authorization model", "url", storeURL)
-
-	var body client.ClientWriteAuthorizationModelRequest
-	if err := json.Unmarshal([]byte(minimalModel), &body); err != nil {
-		return fmt.Errorf("failed to unmarshal authorization model: %w", err)
+	target := modelVersions[len(modelVersions)-1]
+	current := detectModelVersion(latest.AuthorizationModel)
+	if current == target.version {
+		klog.InfoS("Reusing authorization model", "version", current, "authModelID", latest.AuthorizationModel.GetId())
+		return c.pinAuthorizationModel(latest.AuthorizationModel.GetId())
 	}
 
-	_, err := c.fgaClient.WriteAuthorizationModel(context.Background()).Body(body).Execute()
+	body := client.ClientWriteAuthorizationModelRequest{
+		SchemaVersion:   target.model.SchemaVersion,
+		TypeDefinitions: target.model.TypeDefinitions,
+	}
+	response, err := c.fgaClient.WriteAuthorizationModel(ctx).Body(body).Execute()
 	if err != nil {
 		return fmt.Errorf("failed to write authorization model: %w", err)
 	}
+	if err := c.pinAuthorizationModel(response.GetAuthorizationModelId()); err != nil {
+		return err
+	}
 
+	if current == 0 {
+		if latest.AuthorizationModel != nil {
+			klog.InfoS("Existing authorization model is unknown, skipping tuple migrations", "previousAuthModelID", latest.AuthorizationModel.GetId())
+		}
+		klog.InfoS("Created authorization model", "version", target.version, "authModelID", c.authModelID)
+		return nil
+	}
+
+	for _, v := range modelVersions {
+		if v.version <= current || v.migrate == nil {
+			continue
+		}
+		klog.InfoS("Migrating OpenFGA tuples", "toVersion", v.version)
+		if err := v.migrate(ctx, c); err != nil {
+			return fmt.Errorf("failed to migrate tuples to authorization model version %d: %w", v.version, err)
+		}
+	}
+	klog.InfoS("Migrated authorization model", "fromVersion", current, "toVersion", target.version, "authModelID", c.authModelID)
+	return nil
+}
+
+// pinAuthorizationModel makes every subsequent request evaluate against the given authorization model.
+func (c *OpenFGAClient) pinAuthorizationModel(authModelID string) error {
+	if err := c.fgaClient.SetAuthorizationModelId(authModelID); err != nil {
+		return fmt.Errorf("failed to set authorization model ID: %w", err)
+	}
+	c.authModelID = authModelID
 	return nil
 }
 
 // Check determines if a user has a particular relation with an object
 func (c *OpenFGAClient) Check(ctx context.Context, user, relation, objectType, objectID string) (bool, error) {
+	return c.CheckWithContextualTuples(ctx, user, relation, objectType, objectID, nil)
+}
+
+// CheckWithContextualTuples determines if a user has a particular relation with an object,
+// taking the given tuples into account without persisting them
+func (c *OpenFGAClient) CheckWithContextualTuples(ctx context.Context, user, relation, objectType, objectID string, contextualTuples []Tuple) (bool, error) {
 	params := client.ClientCheckRequest{
-		User:     fmt.Sprintf("%s:%s", "user", user),
+		User:     formatSubject(user),
 		Relation: relation,
 		Object:   fmt.Sprintf("%s:%s", objectType, objectID),
 	}
+	for _, t := range contextualTuples {
+		params.ContextualTuples = append(params.ContextualTuples, client.ClientContextualTupleKey{
+			User:     formatTupleUser(t),
+			Relation: t.Relation,
+			Object:   fmt.Sprintf("%s:%s", t.ObjectType, t.ObjectID),
+		})
+	}
 
 	response, err := c.fgaClient.Check(ctx).Body(params).Execute()
 	if err != nil {
@@ -266,7 +297,7 @@ func (c *OpenFGAClient) WriteTuple(ctx context.Context, user, relation, objectTy
 	klog.V(4).InfoS("Writing tuple", "user", user, "relation", relation, "objectType", objectType, "objectID", objectID)
 
 	// Format user and object according to OpenFGA requirements
-	formattedUser := fmt.Sprintf("user:%s", user)
+	formattedUser := formatSubject(user)
 	formattedObject := fmt.Sprintf("%s:%s", objectType, objectID)
 
 	// Create the tuple to write
@@ -296,7 +327,7 @@ func (c *OpenFGAClient) DeleteTuple(ctx context.Context, user, relation, objectT
 	klog.V(4).InfoS("Deleting tuple", "user", user, "relation", relation, "objectType", objectType, "objectID", objectID)
 
 	// Format user and object according to OpenFGA requirements
-	formattedUser := fmt.Sprintf("user:%s", user)
+	formattedUser := formatSubject(user)
 	formattedObject := fmt.Sprintf("%s:%s", objectType, objectID)
 
 	// Create the tuple to delete (without condition since we're dealing with a simple tuple)
@@ -321,6 +352,45 @@ func (c *OpenFGAClient) DeleteTuple(ctx context.Context, user, relation, objectT
 	return nil
 }
 
+// ReadTuples returns the stored tuples matching the filter. Empty fields act as wildcards
+// within the limits of the OpenFGA read API
+func (c *OpenFGAClient) ReadTuples(ctx context.Context, user, relation, objectType, objectID string) ([]Tuple, error) {
+	request := client.ClientReadRequest{}
+	if user != "" {
+		request.User = openfga.PtrString(formatSubject(user))
+	}
+	if relation != "" {
+		request.Relation = openfga.PtrString(relation)
+	}
+	if objectType != "" {
+		request.Object = openfga.PtrString(fmt.Sprintf("%s:%s", objectType, objectID))
+	}
+
+	var tuples []Tuple
+	options := client.ClientReadOptions{}
+	for {
+		response, err := c.fgaClient.Read(ctx).Body(request).Options(options).Execute()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read tuples: %w", err)
+		}
+		for _, t := range response.GetTuples() {
+			key := t.GetKey()
+			objType, objID, _ := strings.Cut(key.GetObject(), ":")
+			tuples = append(tuples, Tuple{
+				User:       parseSubject(key.GetUser()),
+				Relation:   key.GetRelation(),
+				ObjectType: objType,
+				ObjectID:   objID,
+			})
+		}
+		token := response.GetContinuationToken()
+		if token == "" {
+			return tuples, nil
+		}
+		options.ContinuationToken = openfga.PtrString(token)
+	}
+}
+
 // GetStoreID returns the OpenFGA store ID
 func (c *OpenFGAClient) GetStoreID() string {
 	return c.storeID
@@ -0,0 +1,157 @@
+/*
+Copyright 2024 The Karmada Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fga
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+
+	openfga "github.com/openfga/go-sdk"
+)
+
+// FakeClient is an in-memory Client that evaluates checks against the latest authorization model.
+// It is meant for tests.
+type FakeClient struct {
+	mu     sync.Mutex
+	model  openfga.AuthorizationModel
+	tuples []Tuple
+}
+
+var _ Client = &FakeClient{}
+
+// NewFakeClient returns a FakeClient storing tuples.
+func NewFakeClient(tuples ...Tuple) *FakeClient {
+	c := &FakeClient{model: modelVersions[len(modelVersions)-1].model}
+	for _, t := range tuples {
+		c.tuples = append(c.tuples, c.normalize(t))
+	}
+	return c
+}
+
+func (c *FakeClient) normalize(t Tuple) Tuple {
+	t.User = formatTupleUser(t)
+	return t
+}
+
+// Check determines if a user has a particular relation with an object
+func (c *FakeClient) Check(ctx context.Context, user, relation, objectType, objectID string) (bool, error) {
+	return c.CheckWithContextualTuples(ctx, user, relation, objectType, objectID, nil)
+}
+
+// CheckWithContextualTuples determines if a user has a particular relation with an object,
+// taking the given tuples into account without persisting them
+func (c *FakeClient) CheckWithContextualTuples(_ context.Context, user, relation, objectType, objectID string, contextualTuples []Tuple) (bool, error) {
+	c.mu.Lock()
+	tuples := slices.Clone(c.tuples)
+	c.mu.Unlock()
+	for _, t := range contextualTuples {
+		tuples = append(tuples, c.normalize(t))
+	}
+	return c.check(tuples, formatSubject(user), relation, objectType, objectID), nil
+}
+
+// check resolves relation on the object by walking the rewrites of the model.
+func (c *FakeClient) check(tuples []Tuple, subject, relation, objectType, objectID string) bool {
+	for _, def := range c.model.TypeDefinitions {
+		if def.Type != objectType || def.Relations == nil {
+			continue
+		}
+		if rewrite, ok := (*def.Relations)[relation]; ok {
+			return c.eval(tuples, rewrite, subject, relation, objectType, objectID)
+		}
+	}
+	return false
+}
+
+func (c *FakeClient) eval(tuples []Tuple, rewrite openfga.Userset, subject, relation, objectType, objectID string) bool {
+	switch {
+	case rewrite.This != nil:
+		for _, t := range tuples {
+			if t.Relation != relation || t.ObjectType != objectType || t.ObjectID != objectID {
+				continue
+			}
+			if t.User == subject {
+				return true
+			}
+			if object, userset, ok := strings.Cut(t.User, "#"); ok {
+				objType, objID, _ := strings.Cut(object, ":")
+				if c.check(tuples, subject, userset, objType, objID) {
+					return true
+				}
+			}
+		}
+	case rewrite.ComputedUserset != nil:
+		return c.check(tuples, subject, rewrite.ComputedUserset.GetRelation(), objectType, objectID)
+	case rewrite.TupleToUserset != nil:
+		for _, t := range tuples {
+			if t.Relation != rewrite.TupleToUserset.Tupleset.GetRelation() || t.ObjectType != objectType || t.ObjectID != objectID {
+				continue
+			}
+			parentType, parentID, _ := strings.Cut(t.User, ":")
+			if c.check(tuples, subject, rewrite.TupleToUserset.ComputedUserset.GetRelation(), parentType, parentID) {
+				return true
+			}
+		}
+	case rewrite.Union != nil:
+		for _, child := range rewrite.Union.Child {
+			if c.eval(tuples, child, subject, relation, objectType, objectID) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// GetStoreID returns an empty store ID
+func (c *FakeClient) GetStoreID() string { return "" }
+
+// GetAuthModelID returns an empty authorization model ID
+func (c *FakeClient) GetAuthModelID() string { return "" }
+
+// WriteTuple stores a tuple
+func (c *FakeClient) WriteTuple(_ context.Context, user, relation, objectType, objectID string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.tuples = append(c.tuples, c.normalize(Tuple{User: user, Relation: relation, ObjectType: objectType, ObjectID: objectID}))
+	return nil
+}
+
+// DeleteTuple deletes a stored tuple
+func (c *FakeClient) DeleteTuple(_ context.Context, user, relation, objectType, objectID string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	target := c.normalize(Tuple{User: user, Relation: relation, ObjectType: objectType, ObjectID: objectID})
+	c.tuples = slices.DeleteFunc(c.tuples, func(t Tuple) bool { return t == target })
+	return nil
+}
+
+// ReadTuples returns the stored tuples matching the filter, empty fields acting as wildcards
+func (c *FakeClient) ReadTuples(_ context.Context, user, relation, objectType, objectID string) ([]Tuple, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var result []Tuple
+	for _, t := range c.tuples {
+		if (user == "" || t.User == formatSubject(user)) && (relation == "" || t.Relation == relation) &&
+			(objectType == "" || t.ObjectType == objectType) && (objectID == "" || t.ObjectID == objectID) {
+			t.User = parseSubject(t.User)
+			result = append(result, t)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,255 @@
+/*
+Copyright 2024 The Karmada Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fga
+
+import (
+	"context"
+	"encoding/json"
+
+	openfga "github.com/openfga/go-sdk"
+)
+
+// Object types of the Karmada Dashboard authorization model.
+const (
+	TypeUser      = "user"
+	TypeGroup     = "group"
+	TypeDashboard = "dashboard"
+	TypeCluster   = "cluster"
+	TypeNamespace = "namespace"
+	TypeResource  = "resource"
+)
+
+// Relations of the Karmada Dashboard authorization model.
+const (
+	RelationAdmin     = "admin"
+	RelationBasicUser = "basic_user"
+	RelationOwner     = "owner"
+	RelationMember    = "member"
+	RelationViewer    = "viewer"
+	RelationOperator  = "operator"
+	RelationEditor    = "editor"
+	// RelationGuest marks a subject that holds a namespace or resource grant inside a cluster
+	// without holding any cluster-wide role.
+	RelationGuest = "guest"
+	// RelationParentCluster links a namespace to the cluster it belongs to.
+	RelationParentCluster = "cluster"
+	// RelationParentNamespace links a resource kind to the namespace it belongs to.
+	RelationParentNamespace = "namespace"
+)
+
+// modelVersion is a released revision of the authorization model together with the
+// migration that moves the tuples of the previous revision onto it.
+type modelVersion struct {
+	version int
+	model   openfga.AuthorizationModel
+	// migrate rewrites tuples written against the previous version. It may be nil when the
+	// previous tuples stay valid as they are.
+	migrate func(ctx context.Context, c *OpenFGAClient) error
+}
+
+// modelVersions lists every released revision of the authorization model, oldest first.
+// The last entry is the model written to new stores and migrated to by existing ones.
+var modelVersions = []modelVersion{
+	{version: 1, model: authorizationModelV1()},
+	// Version 2 only adds types and relations, so tuples written against version 1 stay valid.
+	{version: 2, model: authorizationModelV2()},
+}
+
+// LatestModelVersion returns the version of the authorization model the dashboard expects.
+func LatestModelVersion() int {
+	return modelVersions[len(modelVersions)-1].version
+}
+
+// authorizationModelV1 is the original model with dashboard roles and cluster owner/member only.
+func authorizationModelV1() openfga.AuthorizationModel {
+	users := []openfga.RelationReference{{Type: TypeUser}}
+	return newModel(
+		typeDef(TypeUser),
+		typeDef(TypeDashboard,
+			relation(RelationAdmin, direct(), users...),
+			relation(RelationBasicUser, direct(), users...),
+		),
+		typeDef(TypeCluster,
+			relation(RelationOwner, direct(), users...),
+			relation(RelationMember, direct(), users...),
+		),
+	)
+}
+
+// authorizationModelV2 adds groups with nested membership, cluster-wide viewer/operator/editor
+// permissions and namespace and resource kind objects that inherit them from their parents.
+//
+// editor implies operator and operator implies viewer on every level. On clusters, owner implies
+// editor and member implies viewer, so grants written against version 1 keep their meaning.
+func authorizationModelV2() openfga.AuthorizationModel {
+	subjects := []openfga.RelationReference{{Type: TypeUser}, groupMembers()}
+	return newModel(
+		typeDef(TypeUser),
+		typeDef(TypeGroup,
+			relation(RelationMember, direct(), subjects...),
+		),
+		typeDef(TypeDashboard,
+			relation(RelationAdmin, direct(), subjects...),
+			relation(RelationBasicUser, direct(), subjects...),
+		),
+		typeDef(TypeCluster,
+			relation(RelationOwner, direct(), subjects...),
+			relation(RelationMember, direct(), subjects...),
+			relation(RelationEditor, union(direct(), computed(RelationOwner)), subjects...),
+			relation(RelationOperator, union(direct(), computed(RelationEditor)), subjects...),
+			relation(RelationViewer, union(direct(), computed(RelationOperator), computed(RelationMember)), subjects...),
+			relation(RelationGuest, direct(), subjects...),
+		),
+		typeDef(TypeNamespace,
+			relation(RelationParentCluster, direct(), openfga.RelationReference{Type: TypeCluster}),
+			relation(RelationEditor, union(direct(), fromParent(RelationParentCluster, RelationEditor)), subjects...),
+			relation(RelationOperator, union(direct(), computed(RelationEditor), fromParent(RelationParentCluster, RelationOperator)), subjects...),
+			relation(RelationViewer, union(direct(), computed(RelationOperator), fromParent(RelationParentCluster, RelationViewer)), subjects...),
+		),
+		typeDef(TypeResource,
+			relation(RelationParentNamespace, direct(), openfga.RelationReference{Type: TypeNamespace}),
+			relation(RelationEditor, union(direct(), fromParent(RelationParentNamespace, RelationEditor)), subjects...),
+			relation(RelationOperator, union(direct(), computed(RelationEditor), fromParent(RelationParentNamespace, RelationOperator)), subjects...),
+			relation(RelationViewer, union(direct(), computed(RelationOperator), fromParent(RelationParentNamespace, RelationViewer)), subjects...),
+		),
+	)
+}
+
+// detectModelVersion returns the version whose type definitions match model, or 0 if none does.
+func detectModelVersion(model *openfga.AuthorizationModel) int {
+	if model == nil {
+		return 0
+	}
+	fingerprint := modelFingerprint(*model)
+	for _, v := range modelVersions {
+		if modelFingerprint(v.model) == fingerprint {
+			return v.version
+		}
+	}
+	return 0
+}
+
+// modelFingerprint returns a canonical JSON form of the model's type definitions. Empty maps,
+// empty lists and nulls are dropped so that a model read back from OpenFGA matches its source.
+func modelFingerprint(model openfga.AuthorizationModel) string {
+	data, err := json.Marshal(model.TypeDefinitions)
+	if err != nil {
+		return ""
+	}
+	var generic interface{}
+	if err := json.Unmarshal(data, &generic); err != nil {
+		return ""
+	}
+	canonical, err := json.Marshal(pruneEmpty(generic))
+	if err != nil {
+		return ""
+	}
+	return string(canonical)
+}
+
+// pruneEmpty recursively removes nulls, empty maps and empty lists from a decoded JSON value.
+// Direct relations ("this": {}) are kept because an empty map is their whole meaning.
+func pruneEmpty(v interface{}) interface{} {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		pruned := map[string]interface{}{}
+		for k, child := range value {
+			if k == "this" {
+				pruned[k] = map[string]interface{}{}
+				continue
+			}
+			if child = pruneEmpty(child); child != nil {
+				pruned[k] = child
+			}
+		}
+		if len(pruned) == 0 {
+			return nil
+		}
+		return pruned
+	case []interface{}:
+		pruned := make([]interface{}, 0, len(value))
+		for _, child := range value {
+			if child = pruneEmpty(child); child != nil {
+				pruned = append(pruned, child)
+			}
+		}
+		if len(pruned) == 0 {
+			return nil
+		}
+		return pruned
+	default:
+		return value
+	}
+}
+
+// relationDef is a relation rewrite together with the subject types that may be assigned directly.
+type relationDef struct {
+	name         string
+	rewrite      openfga.Userset
+	relatedTypes []openfga.RelationReference
+}
+
+func newModel(typeDefinitions ...openfga.TypeDefinition) openfga.AuthorizationModel {
+	return openfga.AuthorizationModel{
+		SchemaVersion:   "1.1",
+		TypeDefinitions: typeDefinitions,
+	}
+}
+
+func typeDef(name string, relations ...relationDef) openfga.TypeDefinition {
+	def := openfga.TypeDefinition{Type: name}
+	if len(relations) == 0 {
+		return def
+	}
+	rewrites := map[string]openfga.Userset{}
+	metadata := map[string]openfga.RelationMetadata{}
+	for _, r := range relations {
+		rewrites[r.name] = r.rewrite
+		relatedTypes := r.relatedTypes
+		metadata[r.name] = openfga.RelationMetadata{DirectlyRelatedUserTypes: &relatedTypes}
+	}
+	def.Relations = &rewrites
+	def.Metadata = &openfga.Metadata{Relations: &metadata}
+	return def
+}
+
+func relation(name string, rewrite openfga.Userset, relatedTypes ...openfga.RelationReference) relationDef {
+	return relationDef{name: name, rewrite: rewrite, relatedTypes: relatedTypes}
+}
+
+func direct() openfga.Userset {
+	return openfga.Userset{This: &map[string]interface{}{}}
+}
+
+func computed(relation string) openfga.Userset {
+	return openfga.Userset{ComputedUserset: &openfga.ObjectRelation{Relation: openfga.PtrString(relation)}}
+}
+
+func fromParent(tupleset, relation string) openfga.Userset {
+	return openfga.Userset{TupleToUserset: &openfga.TupleToUserset{
+		Tupleset:        openfga.ObjectRelation{Relation: openfga.PtrString(tupleset)},
+		ComputedUserset: openfga.ObjectRelation{Relation: openfga.PtrString(relation)},
+	}}
+}
+
+func union(children ...openfga.Userset) openfga.Userset {
+	return openfga.Userset{Union: &openfga.Usersets{Child: children}}
+}
+
+func groupMembers() openfga.RelationReference {
+	return openfga.RelationReference{Type: TypeGroup, Relation: openfga.PtrString(RelationMember)}
+}
@@ -0,0 +1,116 @@
+/*
+Copyright 2024 The Karmada Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fga
+
+import (
+	"context"
+	"slices"
+	"strings"
+)
+
+// ClusterRoles are the relations that can be granted on a cluster.
+var ClusterRoles = []string{RelationOwner, RelationMember, RelationEditor, RelationOperator, RelationViewer}
+
+// ScopedRoles are the relations that can be granted on a namespace or a resource kind.
+var ScopedRoles = []string{RelationEditor, RelationOperator, RelationViewer}
+
+// IsClusterRole reports whether role can be granted on a cluster.
+func IsClusterRole(role string) bool {
+	return slices.Contains(ClusterRoles, role)
+}
+
+// IsScopedRole reports whether role can be granted on a namespace or a resource kind.
+func IsScopedRole(role string) bool {
+	return slices.Contains(ScopedRoles, role)
+}
+
+// NamespaceObjectID returns the ID of the namespace object for a namespace of a cluster.
+func NamespaceObjectID(clusterName, namespace string) string {
+	return clusterName + "/" + namespace
+}
+
+// ResourceObjectID returns the ID of the resource object for a resource kind in a namespace of a cluster.
+// Kinds are case-insensitive and stored in lower case.
+func ResourceObjectID(clusterName, namespace, kind string) string {
+	return NamespaceObjectID(clusterName, namespace) + "/" + strings.ToLower(kind)
+}
+
+// ParseNamespaceObjectID splits a namespace object ID into its cluster and namespace.
+func ParseNamespaceObjectID(objectID string) (clusterName, namespace string, ok bool) {
+	return strings.Cut(objectID, "/")
+}
+
+// ParseResourceObjectID splits a resource object ID into its cluster, namespace and kind.
+func ParseResourceObjectID(objectID string) (clusterName, namespace, kind string, ok bool) {
+	parts := strings.SplitN(objectID, "/", 3)
+	if len(parts) != 3 {
+		return "", "", "", false
+	}
+	return parts[0], parts[1], parts[2], true
+}
+
+// NamespaceParentTuples returns the tuples that link a namespace to its cluster.
+// They are passed as contextual tuples so that cluster-wide grants apply to every
+// namespace without having to persist a tuple per namespace.
+func NamespaceParentTuples(clusterName, namespace string) []Tuple {
+	return []Tuple{{
+		User:       TypeCluster + ":" + clusterName,
+		Relation:   RelationParentCluster,
+		ObjectType: TypeNamespace,
+		ObjectID:   NamespaceObjectID(clusterName, namespace),
+	}}
+}
+
+// ResourceParentTuples returns the tuples that link a resource kind to its namespace and cluster.
+func ResourceParentTuples(clusterName, namespace, kind string) []Tuple {
+	return append(NamespaceParentTuples(clusterName, namespace), Tuple{
+		User:       TypeNamespace + ":" + NamespaceObjectID(clusterName, namespace),
+		Relation:   RelationParentNamespace,
+		ObjectType: TypeResource,
+		ObjectID:   ResourceObjectID(clusterName, namespace, kind),
+	})
+}
+
+// IsDashboardAdmin checks if the user is an admin of the dashboard.
+func IsDashboardAdmin(ctx context.Context, fgaClient Client, username string) (bool, error) {
+	return fgaClient.Check(ctx, username, RelationAdmin, TypeDashboard, TypeDashboard)
+}
+
+// HasClusterPermission checks if the user is an admin or holds relation (viewer, operator, editor, ...) on the cluster.
+func HasClusterPermission(ctx context.Context, fgaClient Client, username, relation, clusterName string) (bool, error) {
+	return hasAnyClusterRelation(ctx, fgaClient, username, clusterName, relation)
+}
+
+// HasNamespacePermission checks if the user is an admin or holds relation on the namespace,
+// either directly or inherited from the cluster.
+func HasNamespacePermission(ctx context.Context, fgaClient Client, username, relation, clusterName, namespace string) (bool, error) {
+	if isAdmin, err := IsDashboardAdmin(ctx, fgaClient, username); err != nil || isAdmin {
+		return isAdmin, err
+	}
+	return fgaClient.CheckWithContextualTuples(ctx, username, relation, TypeNamespace,
+		NamespaceObjectID(clusterName, namespace), NamespaceParentTuples(clusterName, namespace))
+}
+
+// HasResourcePermission checks if the user is an admin or holds relation on the resource kind,
+// either directly or inherited from the namespace or the cluster.
+func HasResourcePermission(ctx context.Context, fgaClient Client, username, relation, clusterName, namespace, kind string) (bool, error) {
+	if isAdmin, err := IsDashboardAdmin(ctx, fgaClient, username); err != nil || isAdmin {
+		return isAdmin, err
+	}
+	return fgaClient.CheckWithContextualTuples(ctx, username, relation, TypeResource,
+		ResourceObjectID(clusterName, namespace, kind), ResourceParentTuples(clusterName, namespace, kind))
+}
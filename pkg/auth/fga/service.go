@@ -45,6 +45,11 @@ func NewService(apiURL string) (*Service, error) {
 	return &Service{client: client}, nil
 }
 
+// NewServiceWithClient creates an OpenFGA service on top of an existing client
+func NewServiceWithClient(client Client) *Service {
+	return &Service{client: client}
+}
+
 // InitFGAService initializes the global FGA service
 func InitFGAService(apiURL string) error {
 	var initErr error
@@ -5,11 +5,25 @@ import (
 	"k8s.io/klog/v2"
 )
 
-// HasClusterAccess checks if the user is an admin or has any role on the given cluster.
-// Returns true if the user is an admin or has a role (owner/member) on the cluster.
+// HasClusterAccess checks if the user is allowed to read the whole of the given cluster.
+// Returns true if the user is an admin or can view the cluster (owner, member, editor, operator
+// and viewer all can). Guests, who only hold namespace or resource grants inside the cluster, are
+// not: their access is checked with HasNamespacePermission and HasResourcePermission.
 func HasClusterAccess(ctx context.Context, fgaClient Client, username, clusterName string) (bool, error) {
+	return hasAnyClusterRelation(ctx, fgaClient, username, clusterName, RelationViewer)
+}
+
+// HasClusterWriteAccess checks if the user is allowed to mutate resources in the given cluster.
+// Returns true if the user is an admin or an editor of the cluster, which owners are;
+// members only have read access.
+func HasClusterWriteAccess(ctx context.Context, fgaClient Client, username, clusterName string) (bool, error) {
+	return hasAnyClusterRelation(ctx, fgaClient, username, clusterName, RelationEditor)
+}
+
+// hasAnyClusterRelation checks if the user is an admin or holds one of the relations on the given cluster.
+func hasAnyClusterRelation(ctx context.Context, fgaClient Client, username, clusterName string, relations ...string) (bool, error) {
 	// Check if user is admin
-	isAdmin, err := fgaClient.Check(ctx, username, "admin", "dashboard", "dashboard")
+	isAdmin, err := IsDashboardAdmin(ctx, fgaClient, username)
 	if err != nil {
 		klog.ErrorS(err, "Failed to check admin role in OpenFGA", "user", username)
 		return false, err
@@ -18,23 +32,15 @@ func HasClusterAccess(ctx context.Context, fgaClient Client, username, clusterNa
 		return true, nil
 	}
 
-	// Check if user is owner or member of the cluster
-	isOwner, err := fgaClient.Check(ctx, username, "owner", "cluster", clusterName)
-	if err != nil {
-		klog.ErrorS(err, "Failed to check owner role in OpenFGA", "user", username, "cluster", clusterName)
-		return false, err
-	}
-	if isOwner {
-		return true, nil
-	}
-
-	isMember, err := fgaClient.Check(ctx, username, "member", "cluster", clusterName)
-	if err != nil {
-		klog.ErrorS(err, "Failed to check member role in OpenFGA", "user", username, "cluster", clusterName)
-		return false, err
-	}
-	if isMember {
-		return true, nil
+	for _, relation := range relations {
+		allowed, err := fgaClient.Check(ctx, username, relation, TypeCluster, clusterName)
+		if err != nil {
+			klog.ErrorS(err, "Failed to check "+relation+" role in OpenFGA", "user", username, "cluster", clusterName)
+			return false, err
+		}
+		if allowed {
+			return true, nil
+		}
 	}
 
 	return false, nil
@@ -0,0 +1,127 @@
+package fga
+
+import (
+	"context"
+	"testing"
+
+	openfga "github.com/openfga/go-sdk"
+)
+
+func tuple(user, relation, objectType, objectID string) Tuple {
+	return Tuple{User: user, Relation: relation, ObjectType: objectType, ObjectID: objectID}
+}
+
+func TestFormatSubject(t *testing.T) {
+	cases := []struct {
+		user     string
+		expected string
+	}{
+		{"alice", "user:alice"},
+		{"user:alice", "user:user:alice"},
+		{GroupSubject("sre"), "group:sre#member"},
+		{"group:sre", "user:group:sre"},
+		{"group:sre#owner", "user:group:sre#owner"},
+		{"group:a:b#member", "user:group:a:b#member"},
+	}
+
+	for _, c := range cases {
+		if actual := formatSubject(c.user); actual != c.expected {
+			t.Errorf("formatSubject(%q) == %q, expected %q", c.user, actual, c.expected)
+		}
+	}
+}
+
+func TestClusterAccess(t *testing.T) {
+	client := NewFakeClient(
+		tuple("root", RelationAdmin, TypeDashboard, TypeDashboard),
+		tuple("alice", RelationOwner, TypeCluster, "member1"),
+		tuple("bob", RelationMember, TypeCluster, "member1"),
+		tuple("dave", RelationOperator, TypeCluster, "member1"),
+		tuple("erin", RelationGuest, TypeCluster, "member1"),
+		tuple("frank", RelationMember, TypeGroup, "sre"),
+		tuple(GroupSubject("sre"), RelationEditor, TypeCluster, "member1"),
+	)
+
+	cases := []struct {
+		user          string
+		cluster       string
+		expectedRead  bool
+		expectedWrite bool
+	}{
+		{"root", "member1", true, true},
+		{"root", "member2", true, true},
+		{"alice", "member1", true, true},
+		{"alice", "member2", false, false},
+		{"bob", "member1", true, false},
+		{"carol", "member1", false, false},
+		{"dave", "member1", true, false},
+		{"erin", "member1", false, false},
+		{"frank", "member1", true, true},
+		{"frank", "member2", false, false},
+		{"user:root", "member1", false, false},
+	}
+
+	for _, c := range cases {
+		read, err := HasClusterAccess(context.TODO(), client, c.user, c.cluster)
+		if err != nil || read != c.expectedRead {
+			t.Errorf("HasClusterAccess(%s, %s) == %v (err %v), expected %v", c.user, c.cluster, read, err, c.expectedRead)
+		}
+		write, err := HasClusterWriteAccess(context.TODO(), client, c.user, c.cluster)
+		if err != nil || write != c.expectedWrite {
+			t.Errorf("HasClusterWriteAccess(%s, %s) == %v (err %v), expected %v", c.user, c.cluster, write, err, c.expectedWrite)
+		}
+	}
+}
+
+func TestScopedPermission(t *testing.T) {
+	client := NewFakeClient(
+		tuple("alice", RelationOwner, TypeCluster, "member1"),
+		tuple("bob", RelationViewer, TypeCluster, "member1"),
+		tuple("bob", RelationEditor, TypeNamespace, NamespaceObjectID("member1", "team-a")),
+		tuple("carol", RelationOperator, TypeResource, ResourceObjectID("member1", "team-a", "Deployment")),
+	)
+
+	cases := []struct {
+		user      string
+		relation  string
+		namespace string
+		kind      string
+		expected  bool
+	}{
+		{"alice", RelationEditor, "team-a", "deployment", true},
+		{"alice", RelationEditor, "team-b", "configmap", true},
+		{"bob", RelationViewer, "team-b", "deployment", true},
+		{"bob", RelationEditor, "team-b", "deployment", false},
+		{"bob", RelationEditor, "team-a", "secret", true},
+		{"carol", RelationOperator, "team-a", "deployment", true},
+		{"carol", RelationViewer, "team-a", "deployment", true},
+		{"carol", RelationEditor, "team-a", "deployment", false},
+		{"carol", RelationViewer, "team-a", "secret", false},
+		{"carol", RelationViewer, "team-b", "deployment", false},
+	}
+
+	for _, c := range cases {
+		allowed, err := HasResourcePermission(context.TODO(), client, c.user, c.relation, "member1", c.namespace, c.kind)
+		if err != nil || allowed != c.expected {
+			t.Errorf("HasResourcePermission(%s, %s, %s, %s) == %v (err %v), expected %v",
+				c.user, c.relation, c.namespace, c.kind, allowed, err, c.expected)
+		}
+	}
+
+	nsAllowed, err := HasNamespacePermission(context.TODO(), client, "carol", RelationViewer, "member1", "team-a")
+	if err != nil || nsAllowed {
+		t.Errorf("HasNamespacePermission(carol, viewer, team-a) == %v (err %v), expected false", nsAllowed, err)
+	}
+}
+
+func TestDetectModelVersion(t *testing.T) {
+	for _, v := range modelVersions {
+		model := v.model
+		if actual := detectModelVersion(&model); actual != v.version {
+			t.Errorf("detectModelVersion(v%d) == %d", v.version, actual)
+		}
+	}
+	if actual := detectModelVersion(&openfga.AuthorizationModel{}); actual != 0 {
+		t.Errorf("detectModelVersion(empty) == %d, expected 0", actual)
+	}
+}
@@ -0,0 +1,226 @@
+/*
+Copyright 2024 The Karmada Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	jose "github.com/go-jose/go-jose/v4"
+	"github.com/golang-jwt/jwt/v5"
+	"k8s.io/klog/v2"
+)
+
+// signingKey is a key that dashboard tokens are signed or verified with.
+type signingKey struct {
+	id     string
+	method jwt.SigningMethod
+	// sign is the private key, or the secret for HMAC keys
+	sign interface{}
+	// verify is the public key, or the secret for HMAC keys
+	verify interface{}
+}
+
+// keyRing holds the active signing key and every key tokens are still accepted from.
+type keyRing struct {
+	active *signingKey
+	keys   map[string]*signingKey
+	// legacy verifies tokens without a kid header, issued before keys were identified
+	legacy *signingKey
+}
+
+var (
+	signingKeys      *keyRing
+	signingKeysMutex sync.RWMutex
+)
+
+// InitSigningKeys loads the keys dashboard tokens are signed with. Every file holds a PEM encoded
+// RSA, ECDSA P-256 or Ed25519 private key. The first key signs new tokens, the others only verify
+// tokens issued before a rotation. Without files, tokens are signed with the HMAC secret from
+// KARMADA_DASHBOARD_JWT_SECRET, and KARMADA_DASHBOARD_JWT_PREVIOUS_SECRETS lists comma-separated
+// secrets that are still accepted.
+func InitSigningKeys(keyFiles []string) error {
+	ring, err := newKeyRing(keyFiles)
+	if err != nil {
+		return err
+	}
+	signingKeysMutex.Lock()
+	signingKeys = ring
+	signingKeysMutex.Unlock()
+	klog.InfoS("Token signing keys initialized", "activeKeyID", ring.active.id, "algorithm", ring.active.method.Alg(), "keys", len(ring.keys))
+	return nil
+}
+
+// getKeyRing returns the loaded key ring, falling back to the HMAC secret from the environment.
+func getKeyRing() *keyRing {
+	signingKeysMutex.RLock()
+	ring := signingKeys
+	signingKeysMutex.RUnlock()
+	if ring != nil {
+		return ring
+	}
+
+	signingKeysMutex.Lock()
+	defer signingKeysMutex.Unlock()
+	if signingKeys == nil {
+		signingKeys, _ = newKeyRing(nil)
+	}
+	return signingKeys
+}
+
+func newKeyRing(keyFiles []string) (*keyRing, error) {
+	ring := &keyRing{keys: map[string]*signingKey{}}
+	add := func(key *signingKey) {
+		if ring.active == nil {
+			ring.active = key
+		}
+		ring.keys[key.id] = key
+	}
+
+	for _, file := range keyFiles {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read signing key %s: %w", file, err)
+		}
+		key, err := parsePrivateKey(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse signing key %s: %w", file, err)
+		}
+		add(key)
+	}
+
+	// An explicitly configured HMAC secret stays accepted after moving to asymmetric keys so that
+	// existing sessions survive, the built-in default secret never is
+	if len(keyFiles) > 0 && os.Getenv("KARMADA_DASHBOARD_JWT_SECRET") == "" {
+		return ring, nil
+	}
+	current := newHMACKey(jwtSecret)
+	add(current)
+	ring.legacy = current
+	for _, previous := range strings.Split(os.Getenv("KARMADA_DASHBOARD_JWT_PREVIOUS_SECRETS"), ",") {
+		if previous = strings.TrimSpace(previous); previous != "" {
+			add(newHMACKey([]byte(previous)))
+		}
+	}
+	return ring, nil
+}
+
+func newHMACKey(secret []byte) *signingKey {
+	sum := sha256.Sum256(append([]byte("kid:"), secret...))
+	return &signingKey{
+		id:     "hs256-" + hex.EncodeToString(sum[:8]),
+		method: jwt.SigningMethodHS256,
+		sign:   secret,
+		verify: secret,
+	}
+}
+
+// parsePrivateKey parses a PEM encoded private key and derives its key ID from the public key.
+func parsePrivateKey(data []byte) (*signingKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM data found")
+	}
+
+	var private interface{}
+	var err error
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		private, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		private, err = x509.ParseECPrivateKey(block.Bytes)
+	default:
+		private, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	key := &signingKey{sign: private}
+	switch k := private.(type) {
+	case *rsa.PrivateKey:
+		key.method, key.verify = jwt.SigningMethodRS256, &k.PublicKey
+	case *ecdsa.PrivateKey:
+		if k.Curve != elliptic.P256() {
+			return nil, fmt.Errorf("only P-256 ECDSA keys are supported")
+		}
+		key.method, key.verify = jwt.SigningMethodES256, &k.PublicKey
+	case ed25519.PrivateKey:
+		key.method, key.verify = jwt.SigningMethodEdDSA, k.Public()
+	default:
+		return nil, fmt.Errorf("unsupported private key type %T", private)
+	}
+
+	jwk := jose.JSONWebKey{Key: key.verify}
+	thumbprint, err := jwk.Thumbprint(crypto.SHA256)
+	if err != nil {
+		return nil, err
+	}
+	key.id = base64.RawURLEncoding.EncodeToString(thumbprint)
+	return key, nil
+}
+
+// signToken signs claims with the active key and sets its kid header.
+func (r *keyRing) signToken(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(r.active.method, claims)
+	token.Header["kid"] = r.active.id
+	return token.SignedString(r.active.sign)
+}
+
+// keyFunc selects the verification key by the kid header and checks the token uses its algorithm.
+func (r *keyRing) keyFunc(token *jwt.Token) (interface{}, error) {
+	key := r.legacy
+	if kid, ok := token.Header["kid"].(string); ok {
+		if key, ok = r.keys[kid]; !ok {
+			return nil, fmt.Errorf("unknown signing key %q", kid)
+		}
+	}
+	if key == nil || token.Method.Alg() != key.method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+	return key.verify, nil
+}
+
+// JWKS returns the public keys dashboard tokens are verified with. HMAC secrets are never published.
+func JWKS() jose.JSONWebKeySet {
+	ring := getKeyRing()
+	set := jose.JSONWebKeySet{Keys: []jose.JSONWebKey{}}
+	for _, key := range ring.keys {
+		if key.method == jwt.SigningMethodHS256 {
+			continue
+		}
+		set.Keys = append(set.Keys, jose.JSONWebKey{
+			Key:       key.verify,
+			KeyID:     key.id,
+			Algorithm: key.method.Alg(),
+			Use:       "sig",
+		})
+	}
+	return set
+}
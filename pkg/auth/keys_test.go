@@ -0,0 +1,118 @@
+/*
+Copyright 2024 The Karmada Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func writeKeyFile(t *testing.T, name string, private interface{}) string {
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(file, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
+func TestKeyRing(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaFile := writeKeyFile(t, "rsa.pem", rsaKey)
+	ecFile := writeKeyFile(t, "ec.pem", ecKey)
+	edFile := writeKeyFile(t, "ed.pem", edKey)
+
+	cases := []struct {
+		signFiles   []string
+		verifyFiles []string
+		expectedAlg string
+		valid       bool
+	}{
+		{nil, nil, "HS256", true},
+		{[]string{rsaFile}, []string{rsaFile}, "RS256", true},
+		{[]string{ecFile}, []string{ecFile}, "ES256", true},
+		{[]string{edFile}, []string{edFile}, "EdDSA", true},
+		// After a rotation tokens signed with the previous key stay valid while it is listed
+		{[]string{rsaFile}, []string{edFile, rsaFile}, "RS256", true},
+		{[]string{rsaFile}, []string{edFile}, "RS256", false},
+		// The default HMAC secret is not accepted once asymmetric keys are configured
+		{nil, []string{rsaFile}, "HS256", false},
+	}
+
+	for _, c := range cases {
+		signer, err := newKeyRing(c.signFiles)
+		if err != nil {
+			t.Fatal(err)
+		}
+		verifier, err := newKeyRing(c.verifyFiles)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		signed, err := signer.signToken(&Claims{Username: "alice"})
+		if err != nil {
+			t.Fatalf("signToken() with %v failed: %v", c.signFiles, err)
+		}
+		token, err := jwt.ParseWithClaims(signed, &Claims{}, verifier.keyFunc)
+		if valid := err == nil && token.Valid; valid != c.valid {
+			t.Errorf("token signed with %v verified with %v: valid == %t, expected %t (%v)", c.signFiles, c.verifyFiles, valid, c.valid, err)
+		}
+		if alg := signer.active.method.Alg(); alg != c.expectedAlg {
+			t.Errorf("newKeyRing(%v) signs with %s, expected %s", c.signFiles, alg, c.expectedAlg)
+		}
+	}
+}
+
+func TestKeyRingRejectsAlgorithmMismatch(t *testing.T) {
+	ring, err := newKeyRing(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A token without kid must use the algorithm of the legacy key
+	token := jwt.NewWithClaims(jwt.SigningMethodHS512, &Claims{Username: "alice"})
+	signed, err := token.SignedString(jwtSecret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := jwt.ParseWithClaims(signed, &Claims{}, ring.keyFunc); err == nil {
+		t.Errorf("token signed with HS512 was accepted")
+	}
+}
@@ -0,0 +1,292 @@
+/*
+Copyright 2024 The Karmada Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	gooidc "github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/oauth2"
+	"k8s.io/klog/v2"
+
+	"github.com/karmada-io/dashboard/pkg/auth"
+	"github.com/karmada-io/dashboard/pkg/etcd"
+)
+
+// PendingLoginTTL is how long a user has to complete the login at the identity provider.
+const PendingLoginTTL = 10 * time.Minute
+
+var (
+	// OIDCProvider is the global OIDC provider, nil when single sign-on is not configured
+	OIDCProvider *Provider
+)
+
+// Config contains the settings of the OIDC identity provider.
+type Config struct {
+	// IssuerURL is the URL of the identity provider, used for discovery
+	IssuerURL string
+	// ClientID and ClientSecret identify the dashboard at the identity provider
+	ClientID     string
+	ClientSecret string
+	// RedirectURL is the callback URL registered at the identity provider
+	RedirectURL string
+	// Scopes are requested in addition to "openid"
+	Scopes []string
+	// UsernameClaim is the ID token claim used as dashboard username
+	UsernameClaim string
+	// GroupsClaim is the ID token claim that lists the groups of the user
+	GroupsClaim string
+	// GroupRoles maps identity provider groups to dashboard roles, see ParseGroupRoles
+	GroupRoles map[string][]string
+}
+
+// Identity is the user described by a verified ID token.
+type Identity struct {
+	Username string
+	Email    string
+	Groups   []string
+}
+
+// loginStore keeps the authorization requests that have not come back to the callback yet.
+type loginStore interface {
+	SaveLogin(ctx context.Context, state string, login *etcd.OIDCLogin) error
+	// TakeLogin removes the login of state and returns it, nil if there is none
+	TakeLogin(ctx context.Context, state string) (*etcd.OIDCLogin, error)
+}
+
+// memoryLoginStore keeps logins in memory. It is used when etcd is not available, which only
+// works with a single replica of the dashboard.
+type memoryLoginStore struct {
+	mu     sync.Mutex
+	logins map[string]etcd.OIDCLogin
+}
+
+func (s *memoryLoginStore) SaveLogin(_ context.Context, state string, login *etcd.OIDCLogin) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	for key, l := range s.logins {
+		if now.After(l.ExpiresAt) {
+			delete(s.logins, key)
+		}
+	}
+	s.logins[state] = *login
+	return nil
+}
+
+func (s *memoryLoginStore) TakeLogin(_ context.Context, state string) (*etcd.OIDCLogin, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	login, ok := s.logins[state]
+	if !ok {
+		return nil, nil
+	}
+	delete(s.logins, state)
+	return &login, nil
+}
+
+// Provider runs the authorization code flow with PKCE against an OIDC identity provider.
+type Provider struct {
+	config   Config
+	oauth2   oauth2.Config
+	verifier *gooidc.IDTokenVerifier
+	logins   loginStore
+}
+
+// NewProvider discovers the identity provider at config.IssuerURL and returns a Provider for it.
+func NewProvider(ctx context.Context, config Config) (*Provider, error) {
+	if config.IssuerURL == "" || config.ClientID == "" || config.RedirectURL == "" {
+		return nil, fmt.Errorf("OIDC issuer URL, client ID and redirect URL are required")
+	}
+	if config.UsernameClaim == "" {
+		config.UsernameClaim = "email"
+	}
+	if config.GroupsClaim == "" {
+		config.GroupsClaim = "groups"
+	}
+
+	provider, err := gooidc.NewProvider(ctx, config.IssuerURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover OIDC provider %s: %w", config.IssuerURL, err)
+	}
+
+	scopes := []string{gooidc.ScopeOpenID}
+	for _, scope := range config.Scopes {
+		if scope != gooidc.ScopeOpenID {
+			scopes = append(scopes, scope)
+		}
+	}
+
+	return &Provider{
+		config: config,
+		oauth2: oauth2.Config{
+			ClientID:     config.ClientID,
+			ClientSecret: config.ClientSecret,
+			RedirectURL:  config.RedirectURL,
+			Endpoint:     provider.Endpoint(),
+			Scopes:       scopes,
+		},
+		verifier: provider.Verifier(&gooidc.Config{ClientID: config.ClientID}),
+		logins:   &memoryLoginStore{logins: map[string]etcd.OIDCLogin{}},
+	}, nil
+}
+
+// InitOIDCProvider initializes the global OIDC provider. Pending logins are kept in etcd when it
+// is available, so that the callback can reach any replica of the dashboard.
+func InitOIDCProvider(ctx context.Context, config Config) error {
+	provider, err := NewProvider(ctx, config)
+	if err != nil {
+		return err
+	}
+	if auth.GetUserManager() != nil {
+		etcdClient, err := etcd.GetEtcdClient(nil)
+		if err != nil {
+			return fmt.Errorf("failed to get etcd client for OIDC logins: %w", err)
+		}
+		provider.logins = etcd.NewOIDCLoginManager(etcdClient)
+	} else {
+		klog.InfoS("etcd is not available, pending OIDC logins are kept in memory")
+	}
+	OIDCProvider = provider
+	klog.InfoS("OIDC provider initialized", "issuer", config.IssuerURL, "clientID", config.ClientID)
+	return nil
+}
+
+// AuthCodeURL starts a login and returns the URL of the identity provider to send the browser to,
+// together with the state of the login. The state must be bound to the browser, e.g. with a
+// cookie, and checked in the callback before calling Exchange. redirect is the dashboard path
+// the browser returns to once the login has completed.
+func (p *Provider) AuthCodeURL(ctx context.Context, redirect string) (string, string, error) {
+	state, err := randomString()
+	if err != nil {
+		return "", "", err
+	}
+	nonce, err := randomString()
+	if err != nil {
+		return "", "", err
+	}
+	verifier := oauth2.GenerateVerifier()
+
+	login := &etcd.OIDCLogin{
+		Verifier:  verifier,
+		Nonce:     nonce,
+		Redirect:  redirect,
+		ExpiresAt: time.Now().Add(PendingLoginTTL),
+	}
+	if err := p.logins.SaveLogin(ctx, state, login); err != nil {
+		return "", "", err
+	}
+	return p.oauth2.AuthCodeURL(state, gooidc.Nonce(nonce), oauth2.S256ChallengeOption(verifier)), state, nil
+}
+
+// Exchange completes the login identified by state: it redeems the authorization code, verifies
+// the ID token and returns the identity in it together with the redirect given to AuthCodeURL.
+func (p *Provider) Exchange(ctx context.Context, code, state string) (*Identity, string, error) {
+	if state == "" {
+		return nil, "", fmt.Errorf("unknown or expired login state")
+	}
+	login, err := p.logins.TakeLogin(ctx, state)
+	if err != nil {
+		return nil, "", err
+	}
+	if login == nil || time.Now().After(login.ExpiresAt) {
+		return nil, "", fmt.Errorf("unknown or expired login state")
+	}
+
+	token, err := p.oauth2.Exchange(ctx, code, oauth2.VerifierOption(login.Verifier))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to exchange authorization code: %w", err)
+	}
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok || rawIDToken == "" {
+		return nil, "", fmt.Errorf("token response does not contain an ID token")
+	}
+	idToken, err := p.verifier.Verify(ctx, rawIDToken)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to verify ID token: %w", err)
+	}
+	if idToken.Nonce != login.Nonce {
+		return nil, "", fmt.Errorf("ID token nonce does not match")
+	}
+
+	var claims map[string]interface{}
+	if err := idToken.Claims(&claims); err != nil {
+		return nil, "", fmt.Errorf("failed to decode ID token claims: %w", err)
+	}
+	identity, err := p.identityFromClaims(claims)
+	if err != nil {
+		return nil, "", err
+	}
+	return identity, login.Redirect, nil
+}
+
+// identityFromClaims maps the configured claims onto an Identity.
+func (p *Provider) identityFromClaims(claims map[string]interface{}) (*Identity, error) {
+	username, _ := claims[p.config.UsernameClaim].(string)
+	if username == "" {
+		return nil, fmt.Errorf("ID token has no %q claim", p.config.UsernameClaim)
+	}
+	if verified, ok := claims["email_verified"].(bool); ok && !verified && p.config.UsernameClaim == "email" {
+		return nil, fmt.Errorf("email %s is not verified", username)
+	}
+
+	identity := &Identity{Username: username}
+	identity.Email, _ = claims["email"].(string)
+	switch groups := claims[p.config.GroupsClaim].(type) {
+	case []interface{}:
+		for _, g := range groups {
+			if name, ok := g.(string); ok {
+				identity.Groups = append(identity.Groups, name)
+			}
+		}
+	case string:
+		identity.Groups = []string{groups}
+	}
+	return identity, nil
+}
+
+// ParseGroupRoles parses group-to-role mappings of the form "<idp group>=<role>". A role is
+// "admin" or "basic_user" for the dashboard role, or "group:<name>" for membership of a
+// dashboard group.
+func ParseGroupRoles(mappings []string) (map[string][]string, error) {
+	result := map[string][]string{}
+	for _, mapping := range mappings {
+		group, role, ok := strings.Cut(mapping, "=")
+		if !ok || group == "" || role == "" {
+			return nil, fmt.Errorf("invalid group role mapping %q, expected <group>=<role>", mapping)
+		}
+		if role != RoleAdmin && role != RoleBasicUser && !strings.HasPrefix(role, groupRolePrefix) {
+			return nil, fmt.Errorf("invalid role %q in group role mapping %q", role, mapping)
+		}
+		result[group] = append(result[group], role)
+	}
+	return result, nil
+}
+
+func randomString() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate random string: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,281 @@
+/*
+Copyright 2024 The Karmada Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	jose "github.com/go-jose/go-jose/v4"
+
+	"github.com/karmada-io/dashboard/pkg/etcd"
+	"github.com/karmada-io/dashboard/pkg/etcd/etcdtest"
+)
+
+// testIssuer is a local stand-in for an OIDC identity provider. It issues a single
+// authorization code per authorize request and checks the PKCE verifier on redemption.
+type testIssuer struct {
+	*httptest.Server
+	key    *rsa.PrivateKey
+	claims map[string]interface{}
+
+	mu    sync.Mutex
+	codes map[string]url.Values
+}
+
+func newTestIssuer(t *testing.T, claims map[string]interface{}) *testIssuer {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	issuer := &testIssuer{key: key, claims: claims, codes: map[string]url.Values{}}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"issuer":                                issuer.URL,
+			"authorization_endpoint":                issuer.URL + "/authorize",
+			"token_endpoint":                        issuer.URL + "/token",
+			"jwks_uri":                              issuer.URL + "/keys",
+			"id_token_signing_alg_values_supported": []string{"RS256"},
+		})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{
+			{Key: &key.PublicKey, KeyID: "test", Algorithm: string(jose.RS256), Use: "sig"},
+		}})
+	})
+	mux.HandleFunc("/token", issuer.handleToken)
+	issuer.Server = httptest.NewServer(mux)
+	t.Cleanup(issuer.Close)
+	return issuer
+}
+
+// authorize plays the user approving the login and returns the code sent to the callback.
+func (i *testIssuer) authorize(authURL string) (code, state string, err error) {
+	u, err := url.Parse(authURL)
+	if err != nil {
+		return "", "", err
+	}
+	query := u.Query()
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	code = "code-" + query.Get("state")
+	i.codes[code] = query
+	return code, query.Get("state"), nil
+}
+
+func (i *testIssuer) handleToken(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	i.mu.Lock()
+	authorize, ok := i.codes[r.PostForm.Get("code")]
+	delete(i.codes, r.PostForm.Get("code"))
+	i.mu.Unlock()
+
+	sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if !ok || authorize.Get("code_challenge_method") != "S256" ||
+		authorize.Get("code_challenge") != base64.RawURLEncoding.EncodeToString(sum[:]) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"error":"invalid_grant"}`))
+		return
+	}
+
+	claims := map[string]interface{}{
+		"iss":   i.URL,
+		"aud":   authorize.Get("client_id"),
+		"sub":   "1234",
+		"nonce": authorize.Get("nonce"),
+		"iat":   time.Now().Unix(),
+		"exp":   time.Now().Add(time.Hour).Unix(),
+	}
+	for k, v := range i.claims {
+		claims[k] = v
+	}
+	payload, _ := json.Marshal(claims)
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.RS256, Key: i.key},
+		(&jose.SignerOptions{}).WithType("JWT").WithHeader("kid", "test"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	signed, err := signer.Sign(payload)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	idToken, _ := signed.CompactSerialize()
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"access_token": "access",
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+		"id_token":     idToken,
+	})
+}
+
+func newTestProvider(t *testing.T, issuer *testIssuer) *Provider {
+	provider, err := NewProvider(context.TODO(), Config{
+		IssuerURL:   issuer.URL,
+		ClientID:    "dashboard",
+		RedirectURL: "http://dashboard.local/api/v1/oidc/callback",
+		Scopes:      []string{"email", "groups"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return provider
+}
+
+func TestExchange(t *testing.T) {
+	issuer := newTestIssuer(t, map[string]interface{}{
+		"email":          "alice@example.com",
+		"email_verified": true,
+		"groups":         []string{"sre", "dev"},
+	})
+	provider := newTestProvider(t, issuer)
+
+	authURL, loginState, err := provider.AuthCodeURL(context.TODO(), "/clusters")
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, state, err := issuer.authorize(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state != loginState {
+		t.Errorf("AuthCodeURL() state == %q, the identity provider got %q", loginState, state)
+	}
+
+	identity, redirect, err := provider.Exchange(context.TODO(), code, state)
+	if err != nil {
+		t.Fatalf("Exchange() failed: %v", err)
+	}
+	expected := &Identity{Username: "alice@example.com", Email: "alice@example.com", Groups: []string{"sre", "dev"}}
+	if !reflect.DeepEqual(identity, expected) {
+		t.Errorf("Exchange() identity == %+v, expected %+v", identity, expected)
+	}
+	if redirect != "/clusters" {
+		t.Errorf("Exchange() redirect == %q, expected /clusters", redirect)
+	}
+
+	if _, _, err := provider.Exchange(context.TODO(), code, state); err == nil {
+		t.Errorf("Exchange() with a used state succeeded")
+	}
+}
+
+func TestExchangeRejectsWrongVerifier(t *testing.T) {
+	issuer := newTestIssuer(t, map[string]interface{}{"email": "alice@example.com"})
+	provider := newTestProvider(t, issuer)
+
+	authURL, _, err := provider.AuthCodeURL(context.TODO(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, state, err := issuer.authorize(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Another login's verifier must not redeem this code
+	logins := provider.logins.(*memoryLoginStore)
+	logins.mu.Lock()
+	login := logins.logins[state]
+	login.Verifier = "not-the-verifier-that-was-used-for-the-challenge-of-this-code"
+	logins.logins[state] = login
+	logins.mu.Unlock()
+
+	if _, _, err := provider.Exchange(context.TODO(), code, state); err == nil {
+		t.Errorf("Exchange() with a wrong PKCE verifier succeeded")
+	}
+}
+
+func TestExchangeOnAnotherReplica(t *testing.T) {
+	issuer := newTestIssuer(t, map[string]interface{}{"email": "alice@example.com"})
+	logins := etcd.NewOIDCLoginManager(etcdtest.NewClient())
+	started, completed := newTestProvider(t, issuer), newTestProvider(t, issuer)
+	started.logins, completed.logins = logins, logins
+
+	authURL, _, err := started.AuthCodeURL(context.TODO(), "/clusters")
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, state, err := issuer.authorize(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	identity, redirect, err := completed.Exchange(context.TODO(), code, state)
+	if err != nil {
+		t.Fatalf("Exchange() on another replica failed: %v", err)
+	}
+	if identity.Username != "alice@example.com" || redirect != "/clusters" {
+		t.Errorf("Exchange() == (%+v, %q), expected alice@example.com and /clusters", identity, redirect)
+	}
+	if _, _, err := started.Exchange(context.TODO(), code, state); err == nil {
+		t.Errorf("Exchange() of a login completed on another replica succeeded")
+	}
+}
+
+func TestAssign(t *testing.T) {
+	groupRoles, err := ParseGroupRoles([]string{"platform=admin", "platform=group:platform", "dev=group:developers"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	provider := &Provider{config: Config{GroupRoles: groupRoles}}
+
+	cases := []struct {
+		groups         []string
+		expectedRole   string
+		expectedGroups []string
+	}{
+		{[]string{"platform"}, RoleAdmin, []string{"platform"}},
+		{[]string{"dev", "other"}, RoleBasicUser, []string{"developers"}},
+		{nil, RoleBasicUser, nil},
+	}
+
+	for _, c := range cases {
+		actual := provider.assign(c.groups)
+		if actual.role != c.expectedRole || !reflect.DeepEqual(actual.groups, c.expectedGroups) {
+			t.Errorf("assign(%v) == (%s, %v), expected (%s, %v)", c.groups, actual.role, actual.groups, c.expectedRole, c.expectedGroups)
+		}
+		if !reflect.DeepEqual(actual.managedGroups, []string{"developers", "platform"}) {
+			t.Errorf("assign(%v) managed groups == %v", c.groups, actual.managedGroups)
+		}
+	}
+
+	for _, invalid := range []string{"platform", "=admin", "platform=root"} {
+		if _, err := ParseGroupRoles([]string{invalid}); err == nil {
+			t.Errorf("ParseGroupRoles(%q) succeeded, expected an error", invalid)
+		}
+	}
+}
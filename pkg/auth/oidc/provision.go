@@ -0,0 +1,179 @@
+/*
+Copyright 2024 The Karmada Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oidc
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"k8s.io/klog/v2"
+
+	"github.com/karmada-io/dashboard/pkg/auth"
+	"github.com/karmada-io/dashboard/pkg/auth/fga"
+	"github.com/karmada-io/dashboard/pkg/etcd"
+	"github.com/karmada-io/dashboard/pkg/resource/setting"
+)
+
+const (
+	// ProviderName is stored on users provisioned through OIDC
+	ProviderName = "oidc"
+	// RoleAdmin and RoleBasicUser are the dashboard roles a group can be mapped to
+	RoleAdmin     = "admin"
+	RoleBasicUser = "basic_user"
+
+	groupRolePrefix = "group:"
+)
+
+// assignment is what the groups of an identity map to.
+type assignment struct {
+	// role is the dashboard role
+	role string
+	// groups are the dashboard groups the user should be a member of
+	groups []string
+	// managedGroups are all dashboard groups that appear in the mapping, membership of
+	// any other group is left alone
+	managedGroups []string
+}
+
+// assign maps identity provider groups onto a dashboard role and dashboard groups.
+func (p *Provider) assign(identityGroups []string) assignment {
+	result := assignment{role: RoleBasicUser}
+	for idpGroup, roles := range p.config.GroupRoles {
+		matched := slices.Contains(identityGroups, idpGroup)
+		for _, role := range roles {
+			switch {
+			case role == RoleAdmin && matched:
+				result.role = RoleAdmin
+			case strings.HasPrefix(role, groupRolePrefix):
+				group := strings.TrimPrefix(role, groupRolePrefix)
+				if !slices.Contains(result.managedGroups, group) {
+					result.managedGroups = append(result.managedGroups, group)
+				}
+				if matched && !slices.Contains(result.groups, group) {
+					result.groups = append(result.groups, group)
+				}
+			}
+		}
+	}
+	sort.Strings(result.groups)
+	sort.Strings(result.managedGroups)
+	return result
+}
+
+// Provision creates or updates the dashboard user for identity, applies the group role mapping
+// and returns a dashboard token for it. Users that exist with a password are never taken over.
+func (p *Provider) Provision(ctx context.Context, identity *Identity) (*auth.TokenPair, error) {
+	userManager := auth.GetUserManager()
+	if userManager == nil {
+		return nil, fmt.Errorf("user manager not initialized")
+	}
+
+	// The username becomes an authorization subject, it must not pass for another one
+	if err := etcd.ValidateUsername(identity.Username); err != nil {
+		return nil, fmt.Errorf("cannot provision user %q: %w", identity.Username, err)
+	}
+
+	mapped := p.assign(identity.Groups)
+	exists, err := userManager.UserExists(ctx, identity.Username)
+	if err != nil {
+		return nil, err
+	}
+
+	role := mapped.role
+	if !exists {
+		if err := userManager.CreateExternalUser(ctx, identity.Username, identity.Email, role, ProviderName); err != nil {
+			return nil, fmt.Errorf("failed to provision user %s: %w", identity.Username, err)
+		}
+		klog.InfoS("Provisioned OIDC user", "username", identity.Username, "role", role)
+	} else {
+		user, err := userManager.GetUser(ctx, identity.Username)
+		if err != nil {
+			return nil, err
+		}
+		if user.Provider != ProviderName {
+			return nil, fmt.Errorf("user %s exists and does not sign in through OIDC", identity.Username)
+		}
+		// Without a mapping the stored role is managed in the dashboard
+		if len(p.config.GroupRoles) == 0 {
+			role = user.Role
+		}
+		if user.Role != role || (identity.Email != "" && user.Email != identity.Email) {
+			user.Role = role
+			if identity.Email != "" {
+				user.Email = identity.Email
+			}
+			if err := userManager.UpdateUser(ctx, user); err != nil {
+				return nil, fmt.Errorf("failed to update user %s: %w", identity.Username, err)
+			}
+		}
+	}
+
+	if err := p.syncPermissions(ctx, identity.Username, role, mapped); err != nil {
+		// The user can still sign in, only the mapped permissions may be stale
+		klog.ErrorS(err, "Failed to apply OIDC group role mapping", "username", identity.Username)
+	}
+
+	return auth.StartSession(ctx, identity.Username, role)
+}
+
+// syncPermissions makes the dashboard admin relation and the mapped group memberships in
+// OpenFGA match the assignment.
+func (p *Provider) syncPermissions(ctx context.Context, username, role string, mapped assignment) error {
+	if fga.FGAService == nil || fga.FGAService.GetClient() == nil {
+		return fmt.Errorf("OpenFGA service not initialized")
+	}
+	fgaClient := fga.FGAService.GetClient()
+
+	isAdmin, err := fga.IsDashboardAdmin(ctx, fgaClient, username)
+	if err != nil {
+		return err
+	}
+	switch {
+	case role == RoleAdmin && !isAdmin:
+		if err := fgaClient.WriteTuple(ctx, username, fga.RelationAdmin, fga.TypeDashboard, fga.TypeDashboard); err != nil {
+			return err
+		}
+	case role != RoleAdmin && isAdmin && len(p.config.GroupRoles) > 0:
+		if err := fgaClient.DeleteTuple(ctx, username, fga.RelationAdmin, fga.TypeDashboard, fga.TypeDashboard); err != nil {
+			return err
+		}
+	}
+
+	for _, name := range mapped.managedGroups {
+		group, err := setting.GetGroup(ctx, name)
+		if err != nil {
+			klog.ErrorS(err, "Mapped dashboard group not available", "group", name)
+			continue
+		}
+		isMember := slices.Contains(group.Members, username)
+		wanted := slices.Contains(mapped.groups, name)
+		switch {
+		case wanted && !isMember:
+			if _, err := setting.AddGroupMembers(ctx, name, []string{username}); err != nil {
+				return err
+			}
+		case !wanted && isMember:
+			if err := setting.RemoveGroupMember(ctx, name, username); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -18,6 +18,7 @@ package auth
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"sync"
@@ -35,13 +36,16 @@ var (
 	userManagerMutex       sync.RWMutex
 	userManagerInitialized bool
 	jwtSecret              = []byte(getJWTSecret())
-	tokenExpiryDuration    = 24 * time.Hour
 )
 
 // Claims represents the JWT claims
 type Claims struct {
 	Username string `json:"username"`
 	Role     string `json:"role"`
+	// SessionID identifies the login session the token was issued for
+	SessionID string `json:"sid,omitempty"`
+	// Purpose restricts a partial login token to the login step it was issued for
+	Purpose string `json:"purpose,omitempty"`
 	jwt.RegisteredClaims
 }
 
@@ -73,6 +77,8 @@ func InitUserManager(etcdOpts *etcd.Options) error {
 
 	// Create the user manager instance
 	userManager = etcd.NewUserManager(client)
+	sessions = etcd.NewSessionManager(client)
+	apiTokens = etcd.NewAPITokenManager(client)
 
 	// Try to ping etcd with a simple operation to verify connectivity
 	// but don't use UserExists yet since it requires a working connection
@@ -84,6 +90,8 @@ func InitUserManager(etcdOpts *etcd.Options) error {
 	if err != nil {
 		klog.ErrorS(err, "Etcd ping test failed")
 		userManager = nil // Reset to nil on failure
+		sessions = nil
+		apiTokens = nil
 		return fmt.Errorf("etcd ping test failed: %v", err)
 	}
 
@@ -93,6 +101,7 @@ func InitUserManager(etcdOpts *etcd.Options) error {
 	// Initialize admin user with default password if specified
 	defaultAdminPassword := os.Getenv("KARMADA_DASHBOARD_ADMIN_PASSWORD")
 	if defaultAdminPassword == "" {
+		// The bootstrapped admin has to change this password on the first login
 		defaultAdminPassword = "admin123"
 	}
 	if defaultAdminPassword != "" {
@@ -116,6 +125,9 @@ func InitUserManager(etcdOpts *etcd.Options) error {
 			} else {
 				klog.InfoS("Admin user created successfully")
 				adminUserCreated = true
+				if err := userManager.RequirePasswordChange(ctx, "admin"); err != nil {
+					klog.ErrorS(err, "Failed to require password change for admin user")
+				}
 			}
 		} else {
 			klog.InfoS("Admin user already exists")
@@ -168,56 +180,80 @@ func GetUserManager() *etcd.UserManager {
 	return userManager
 }
 
-// AuthenticateUser authenticates a user with username and password
-func AuthenticateUser(ctx context.Context, username, password string) (string, error) {
+// AuthenticateUser authenticates a user with username and password and starts a login session.
+// It returns ErrPasswordChangeRequired when the user has to choose a new password through
+// ChangePassword first, and a *SecondFactorRequiredError when a second login step follows.
+func AuthenticateUser(ctx context.Context, username, password string) (*TokenPair, error) {
+	user, err := verifyCredentials(ctx, username, password)
+	if err != nil {
+		return nil, err
+	}
+	return completeLogin(ctx, user)
+}
+
+// verifyCredentials checks a password login against the lockout and the password policy.
+// The user is returned along with ErrPasswordChangeRequired when only the password change is missing.
+func verifyCredentials(ctx context.Context, username, password string) (*etcd.User, error) {
 	userMgr := GetUserManager()
 	if userMgr == nil {
-		return "", fmt.Errorf("user manager not initialized")
+		return nil, fmt.Errorf("user manager not initialized")
 	}
+	policy := GetPasswordPolicy()
 
-	// Verify password against etcd
-	valid, err := userMgr.VerifyPassword(ctx, username, password)
+	// Get user details
+	user, err := userMgr.GetUser(ctx, username)
 	if err != nil {
-		return "", fmt.Errorf("authentication failed: %v", err)
+		klog.V(4).InfoS("Login for unknown user", "username", username)
+		return nil, ErrInvalidCredentials
+	}
+	if user.Provider != "" {
+		return nil, ErrInvalidCredentials
 	}
 
-	if !valid {
-		return "", fmt.Errorf("invalid username or password")
+	// The attempt is counted before the password is verified, so that attempts which are
+	// already in flight when the lockout starts cannot get through
+	reserved, err := userMgr.ReserveLoginAttempt(ctx, username, policy.MaxFailedLogins, policy.LockoutDuration)
+	if errors.Is(err, etcd.ErrUserLocked) {
+		return nil, ErrAccountLocked
+	}
+	if err != nil {
+		return nil, fmt.Errorf("authentication failed: %v", err)
 	}
 
-	// Get user details
-	user, err := userMgr.GetUser(ctx, username)
+	// Verify password against etcd
+	valid, err := userMgr.VerifyPassword(ctx, username, password)
 	if err != nil {
-		return "", fmt.Errorf("failed to get user: %v", err)
+		return nil, fmt.Errorf("authentication failed: %v", err)
+	}
+	if !valid {
+		if reserved.IsLocked(time.Now()) {
+			return nil, ErrAccountLocked
+		}
+		return nil, ErrInvalidCredentials
 	}
 
-	// Generate JWT token
-	return generateToken(user.Username, user.Role)
+	if err := userMgr.ResetFailedLogins(ctx, username); err != nil {
+		klog.ErrorS(err, "Failed to reset failed logins", "username", username)
+	}
+	if user.MustChangePassword || policy.expired(user.PasswordChangedAt, time.Now()) {
+		return user, ErrPasswordChangeRequired
+	}
+	return user, nil
 }
 
-// ValidateToken validates a JWT token
+// ValidateToken validates a JWT token and checks that it has not been revoked
 func ValidateToken(tokenString string) (*Claims, error) {
 	claims := &Claims{}
+	keyFunc := getKeyRing().keyFunc
 
 	// Parse the token with our custom Claims type
-	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-		// Validate the signing method
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return jwtSecret, nil
-	})
+	token, err := jwt.ParseWithClaims(tokenString, claims, keyFunc)
 
 	// If we're using MapClaims format (with service account info), handle that format
 	if err != nil && err.Error() == "token is malformed" {
 		// Try parsing as MapClaims instead
 		mapClaims := jwt.MapClaims{}
-		token, err = jwt.ParseWithClaims(tokenString, &mapClaims, func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-			}
-			return jwtSecret, nil
-		})
+		token, err = jwt.ParseWithClaims(tokenString, &mapClaims, keyFunc)
 
 		if err != nil {
 			return nil, err
@@ -246,18 +282,39 @@ func ValidateToken(tokenString string) (*Claims, error) {
 	if !token.Valid {
 		return nil, fmt.Errorf("invalid token")
 	}
+	// Partial login tokens only work for their login step
+	if claims.Purpose != "" {
+		return nil, fmt.Errorf("invalid token")
+	}
+
+	revoked, err := isTokenRevoked(claims.ID)
+	if err != nil {
+		// Fail closed, a token that cannot be checked is not trusted
+		return nil, fmt.Errorf("failed to check token revocation: %w", err)
+	}
+	if revoked {
+		return nil, fmt.Errorf("token has been revoked")
+	}
 
 	return claims, nil
 }
 
-// generateToken generates a JWT token for a user
-func generateToken(username, role string) (string, error) {
+// generateToken generates a JWT access token for a user and returns it with its ID and expiry
+func generateToken(username, role, sessionID string) (string, string, time.Time, error) {
+	tokenID, err := randomToken(16)
+	if err != nil {
+		return "", "", time.Time{}, err
+	}
+
 	now := time.Now()
+	expiresAt := now.Add(accessTokenTTL)
 	claims := &Claims{
-		Username: username,
-		Role:     role,
+		Username:  username,
+		Role:      role,
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(now.Add(tokenExpiryDuration)),
+			ID:        tokenID,
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
 			IssuedAt:  jwt.NewNumericDate(now),
 			NotBefore: jwt.NewNumericDate(now),
 			Issuer:    "karmada-dashboard-api",
@@ -265,13 +322,12 @@ func generateToken(username, role string) (string, error) {
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString(jwtSecret)
+	tokenString, err := getKeyRing().signToken(claims)
 	if err != nil {
-		return "", err
+		return "", "", time.Time{}, err
 	}
 
-	return tokenString, nil
+	return tokenString, tokenID, expiresAt, nil
 }
 
 // getJWTSecret returns the JWT secret from environment or a default value
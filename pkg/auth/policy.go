@@ -0,0 +1,237 @@
+/*
+Copyright 2024 The Karmada Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"k8s.io/klog/v2"
+)
+
+var (
+	// ErrInvalidCredentials is returned when the username or password is wrong
+	ErrInvalidCredentials = errors.New("invalid username or password")
+	// ErrAccountLocked is returned while a user is locked out after too many failed logins
+	ErrAccountLocked = errors.New("account is temporarily locked because of too many failed logins")
+	// ErrPasswordChangeRequired is returned when the password is correct but has to be changed
+	// before the user can sign in
+	ErrPasswordChangeRequired = errors.New("password change required")
+)
+
+// PasswordPolicy holds the login rules for etcd backed users: password rules, failed logins and
+// two-factor authentication.
+type PasswordPolicy struct {
+	MinLength        int
+	RequireMixedCase bool
+	RequireDigit     bool
+	RequireSymbol    bool
+	// HistorySize is the number of previous passwords that cannot be used again, 0 allows reuse
+	HistorySize int
+	// MaxAge forces a password change once a password is older, 0 disables expiry
+	MaxAge time.Duration
+	// MaxFailedLogins locks a user out after that many consecutive failed logins, 0 disables lockout
+	MaxFailedLogins int
+	LockoutDuration time.Duration
+	// RequireTwoFactor makes users enroll in TOTP two-factor authentication on their next login
+	RequireTwoFactor bool
+}
+
+// DefaultPasswordPolicy returns the policy used unless SetPasswordPolicy is called.
+func DefaultPasswordPolicy() PasswordPolicy {
+	return PasswordPolicy{
+		MinLength:        8,
+		RequireMixedCase: true,
+		RequireDigit:     true,
+		HistorySize:      5,
+		MaxFailedLogins:  5,
+		LockoutDuration:  15 * time.Minute,
+	}
+}
+
+var (
+	passwordPolicy      = DefaultPasswordPolicy()
+	passwordPolicyMutex sync.RWMutex
+)
+
+// SetPasswordPolicy replaces the password policy.
+func SetPasswordPolicy(policy PasswordPolicy) {
+	passwordPolicyMutex.Lock()
+	defer passwordPolicyMutex.Unlock()
+	passwordPolicy = policy
+}
+
+// GetPasswordPolicy returns the password policy in effect.
+func GetPasswordPolicy() PasswordPolicy {
+	passwordPolicyMutex.RLock()
+	defer passwordPolicyMutex.RUnlock()
+	return passwordPolicy
+}
+
+// Validate checks password against the complexity rules of the policy.
+func (p PasswordPolicy) Validate(username, password string) error {
+	if len([]rune(password)) < p.MinLength {
+		return fmt.Errorf("password must be at least %d characters long", p.MinLength)
+	}
+	if username != "" && strings.Contains(strings.ToLower(password), strings.ToLower(username)) {
+		return fmt.Errorf("password must not contain the username")
+	}
+
+	var upper, lower, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			symbol = true
+		}
+	}
+	if p.RequireMixedCase && !(upper && lower) {
+		return fmt.Errorf("password must contain upper and lower case letters")
+	}
+	if p.RequireDigit && !digit {
+		return fmt.Errorf("password must contain a digit")
+	}
+	if p.RequireSymbol && !symbol {
+		return fmt.Errorf("password must contain a symbol")
+	}
+	return nil
+}
+
+// expired reports whether a password changed at changedAt has to be changed at now.
+func (p PasswordPolicy) expired(changedAt, now time.Time) bool {
+	return p.MaxAge > 0 && !changedAt.IsZero() && now.Sub(changedAt) > p.MaxAge
+}
+
+// SetPassword sets a new password for a user after checking it against the policy and the
+// password history. Every session of the user ends.
+func SetPassword(ctx context.Context, username, password string) error {
+	userMgr := GetUserManager()
+	if userMgr == nil {
+		return fmt.Errorf("user manager not initialized")
+	}
+
+	policy := GetPasswordPolicy()
+	if err := policy.Validate(username, password); err != nil {
+		return err
+	}
+	reused, err := userMgr.IsPasswordReused(ctx, username, password, policy.HistorySize)
+	if err != nil {
+		return err
+	}
+	if reused {
+		return fmt.Errorf("password must differ from the last %d passwords", policy.HistorySize)
+	}
+
+	if err := userMgr.UpdatePassword(ctx, username, password); err != nil {
+		return err
+	}
+	revokeUserSessions(ctx, username)
+	return nil
+}
+
+// ChangePassword lets a user replace their password by proving the current one, and a TOTP or
+// recovery code when two-factor authentication is enabled. It is how users with an expired or
+// reset password sign in again, so it counts failed attempts like a login does.
+func ChangePassword(ctx context.Context, username, currentPassword, newPassword, code string) (*TokenPair, error) {
+	user, err := verifyCredentials(ctx, username, currentPassword)
+	if err != nil && !errors.Is(err, ErrPasswordChangeRequired) {
+		return nil, err
+	}
+	if currentPassword == newPassword {
+		return nil, fmt.Errorf("new password must differ from the current password")
+	}
+	if user.TOTPEnabled {
+		if _, err := verifyCode(ctx, username, code); err != nil {
+			return nil, err
+		}
+	}
+	if err := SetPassword(ctx, username, newPassword); err != nil {
+		return nil, err
+	}
+	klog.InfoS("Password changed", "username", username)
+
+	if user.TOTPEnabled {
+		return StartSession(ctx, user.Username, user.Role)
+	}
+	return completeLogin(ctx, user)
+}
+
+// ResetPassword sets a temporary password for a user, lifts a lockout and makes the user
+// choose a new password on the next login. It is meant for administrators.
+func ResetPassword(ctx context.Context, username, temporaryPassword string) error {
+	userMgr := GetUserManager()
+	if userMgr == nil {
+		return fmt.Errorf("user manager not initialized")
+	}
+	user, err := userMgr.GetUser(ctx, username)
+	if err != nil {
+		return err
+	}
+	if user.Provider != "" {
+		return fmt.Errorf("user %s signs in through %s and has no password", username, user.Provider)
+	}
+	if err := GetPasswordPolicy().Validate(username, temporaryPassword); err != nil {
+		return err
+	}
+
+	if err := userMgr.UpdatePassword(ctx, username, temporaryPassword); err != nil {
+		return err
+	}
+	if err := userMgr.RequirePasswordChange(ctx, username); err != nil {
+		return err
+	}
+	if err := userMgr.ResetFailedLogins(ctx, username); err != nil {
+		return err
+	}
+	revokeUserSessions(ctx, username)
+	klog.InfoS("Password reset", "username", username)
+	return nil
+}
+
+// UnlockUser lifts the lockout of a user and clears the failed login counter.
+func UnlockUser(ctx context.Context, username string) error {
+	userMgr := GetUserManager()
+	if userMgr == nil {
+		return fmt.Errorf("user manager not initialized")
+	}
+	if err := userMgr.ResetFailedLogins(ctx, username); err != nil {
+		return err
+	}
+	klog.InfoS("User unlocked", "username", username)
+	return nil
+}
+
+// revokeUserSessions ends every session of a user, failures are only logged since the
+// sessions expire on their own.
+func revokeUserSessions(ctx context.Context, username string) {
+	if sessions == nil {
+		return
+	}
+	if err := Logout(ctx, &Claims{Username: username}, true); err != nil {
+		klog.ErrorS(err, "Failed to revoke sessions", "username", username)
+	}
+}
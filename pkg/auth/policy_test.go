@@ -0,0 +1,75 @@
+/*
+Copyright 2024 The Karmada Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auth
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPasswordPolicyValidate(t *testing.T) {
+	strict := DefaultPasswordPolicy()
+	strict.MinLength = 10
+	strict.RequireSymbol = true
+
+	cases := []struct {
+		policy   PasswordPolicy
+		username string
+		password string
+		valid    bool
+	}{
+		{DefaultPasswordPolicy(), "alice", "Secret123", true},
+		{DefaultPasswordPolicy(), "alice", "Sec12", false},
+		{DefaultPasswordPolicy(), "alice", "secret123", false},
+		{DefaultPasswordPolicy(), "alice", "SecretPass", false},
+		{DefaultPasswordPolicy(), "alice", "Alice12345", false},
+		{DefaultPasswordPolicy(), "admin", "admin123", false},
+		{strict, "alice", "Secret1234", false},
+		{strict, "alice", "Secret12-34", true},
+		{PasswordPolicy{}, "alice", "x", true},
+	}
+
+	for _, c := range cases {
+		err := c.policy.Validate(c.username, c.password)
+		if (err == nil) != c.valid {
+			t.Errorf("Validate(%q, %q) == %v, expected valid %t", c.username, c.password, err, c.valid)
+		}
+	}
+}
+
+func TestPasswordPolicyExpired(t *testing.T) {
+	now := time.Now()
+	policy := PasswordPolicy{MaxAge: 90 * 24 * time.Hour}
+
+	cases := []struct {
+		policy    PasswordPolicy
+		changedAt time.Time
+		expected  bool
+	}{
+		{policy, now.Add(-24 * time.Hour), false},
+		{policy, now.Add(-91 * 24 * time.Hour), true},
+		// Users stored before passwords were tracked are not expired
+		{policy, time.Time{}, false},
+		{PasswordPolicy{}, now.Add(-365 * 24 * time.Hour), false},
+	}
+
+	for _, c := range cases {
+		if actual := c.policy.expired(c.changedAt, now); actual != c.expected {
+			t.Errorf("expired(%v) == %t, expected %t", c.changedAt, actual, c.expected)
+		}
+	}
+}
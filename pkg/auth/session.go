@@ -0,0 +1,292 @@
+/*
+Copyright 2024 The Karmada Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	"github.com/karmada-io/dashboard/pkg/etcd"
+)
+
+// revocationCacheTTL is how long a token that was found not revoked is trusted without asking
+// etcd again. It bounds how long a logout on another replica takes to apply.
+const revocationCacheTTL = 5 * time.Second
+
+// sessionStore persists login sessions and the access token denylist.
+type sessionStore interface {
+	SaveSession(ctx context.Context, session *etcd.Session) error
+	ReplaceSession(ctx context.Context, current, updated *etcd.Session) (bool, error)
+	GetSession(ctx context.Context, id string) (*etcd.Session, error)
+	DeleteSession(ctx context.Context, id string) error
+	ListSessions(ctx context.Context, username string) ([]*etcd.Session, error)
+	RevokeToken(ctx context.Context, tokenID string, expiresAt time.Time) error
+	IsTokenRevoked(ctx context.Context, tokenID string) (bool, error)
+}
+
+var (
+	sessions         sessionStore
+	accessTokenTTL   = 15 * time.Minute
+	refreshTokenTTL  = 7 * 24 * time.Hour
+	revocationCache  = map[string]revocationEntry{}
+	revocationsMutex sync.Mutex
+	// userRole looks up the current role of a user when a session is refreshed
+	userRole = func(ctx context.Context, username string) (string, error) {
+		userMgr := GetUserManager()
+		if userMgr == nil {
+			return "", fmt.Errorf("user manager not initialized")
+		}
+		user, err := userMgr.GetUser(ctx, username)
+		if err != nil {
+			return "", err
+		}
+		return user.Role, nil
+	}
+)
+
+type revocationEntry struct {
+	revoked   bool
+	checkedAt time.Time
+}
+
+// TokenPair is what a successful login or refresh returns.
+type TokenPair struct {
+	// AccessToken authenticates API requests until ExpiresAt
+	AccessToken string
+	// RefreshToken obtains a new token pair, it is empty when sessions are not available
+	RefreshToken string
+	ExpiresAt    time.Time
+}
+
+// SetTokenLifetimes sets how long access tokens and login sessions last.
+func SetTokenLifetimes(access, refresh time.Duration) {
+	if access > 0 {
+		accessTokenTTL = access
+	}
+	if refresh > 0 {
+		refreshTokenTTL = refresh
+	}
+}
+
+// StartSession creates a login session for an authenticated user and returns its first token pair.
+func StartSession(ctx context.Context, username, role string) (*TokenPair, error) {
+	if sessions == nil {
+		klog.V(4).InfoS("Session store not initialized, issuing access token only", "username", username)
+		token, _, expiresAt, err := generateToken(username, role, "")
+		if err != nil {
+			return nil, err
+		}
+		return &TokenPair{AccessToken: token, ExpiresAt: expiresAt}, nil
+	}
+
+	id, err := randomToken(16)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	session := &etcd.Session{
+		ID:        id,
+		Username:  username,
+		CreatedAt: now,
+		ExpiresAt: now.Add(refreshTokenTTL),
+	}
+	pair, err := issueTokenPair(session, role)
+	if err != nil {
+		return nil, err
+	}
+	if err := sessions.SaveSession(ctx, session); err != nil {
+		return nil, err
+	}
+	return pair, nil
+}
+
+// RefreshSession redeems a refresh token for a new token pair. Each refresh token works once:
+// presenting one that was already redeemed ends the whole session, since it means the token leaked.
+func RefreshSession(ctx context.Context, refreshToken string) (*TokenPair, error) {
+	if sessions == nil {
+		return nil, fmt.Errorf("sessions are not available")
+	}
+	id, secret, ok := strings.Cut(refreshToken, ".")
+	if !ok || id == "" || secret == "" {
+		return nil, fmt.Errorf("malformed refresh token")
+	}
+
+	session, err := sessions.GetSession(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("session expired or revoked")
+	}
+	if subtle.ConstantTimeCompare([]byte(hashSecret(secret)), []byte(session.RefreshTokenHash)) != 1 {
+		klog.InfoS("Refresh token reused, revoking session", "username", session.Username, "session", session.ID)
+		if err := revokeSession(ctx, session); err != nil {
+			klog.ErrorS(err, "Failed to revoke session", "session", session.ID)
+		}
+		return nil, fmt.Errorf("session expired or revoked")
+	}
+
+	// Pick up role changes and deleted users on every refresh
+	role, err := userRole(ctx, session.Username)
+	if err != nil {
+		_ = revokeSession(ctx, session)
+		return nil, fmt.Errorf("session expired or revoked")
+	}
+
+	updated := *session
+	updated.ExpiresAt = time.Now().Add(refreshTokenTTL)
+	pair, err := issueTokenPair(&updated, role)
+	if err != nil {
+		return nil, err
+	}
+	replaced, err := sessions.ReplaceSession(ctx, session, &updated)
+	if err != nil {
+		return nil, err
+	}
+	if !replaced {
+		return nil, fmt.Errorf("session expired or revoked")
+	}
+	return pair, nil
+}
+
+// Logout revokes the access token in claims and its session. With all set, every session of the
+// user is revoked.
+func Logout(ctx context.Context, claims *Claims, all bool) error {
+	if sessions == nil {
+		return fmt.Errorf("sessions are not available")
+	}
+	if claims.ID != "" && claims.ExpiresAt != nil {
+		if err := revokeToken(ctx, claims.ID, claims.ExpiresAt.Time); err != nil {
+			return err
+		}
+	}
+
+	if !all {
+		if claims.SessionID == "" {
+			return nil
+		}
+		session, err := sessions.GetSession(ctx, claims.SessionID)
+		if err != nil {
+			// Already expired or revoked
+			return nil
+		}
+		return revokeSession(ctx, session)
+	}
+
+	userSessions, err := sessions.ListSessions(ctx, claims.Username)
+	if err != nil {
+		return err
+	}
+	for _, session := range userSessions {
+		if err := revokeSession(ctx, session); err != nil {
+			return err
+		}
+	}
+	klog.InfoS("Revoked all sessions", "username", claims.Username, "sessions", len(userSessions))
+	return nil
+}
+
+// issueTokenPair signs an access token for the session and rotates its refresh token.
+func issueTokenPair(session *etcd.Session, role string) (*TokenPair, error) {
+	token, tokenID, expiresAt, err := generateToken(session.Username, role, session.ID)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := randomToken(32)
+	if err != nil {
+		return nil, err
+	}
+	session.RefreshTokenHash = hashSecret(secret)
+	session.AccessTokenID = tokenID
+	return &TokenPair{
+		AccessToken:  token,
+		RefreshToken: session.ID + "." + secret,
+		ExpiresAt:    expiresAt,
+	}, nil
+}
+
+// revokeSession deletes a session and denies the last access token issued for it.
+func revokeSession(ctx context.Context, session *etcd.Session) error {
+	if session.AccessTokenID != "" {
+		if err := revokeToken(ctx, session.AccessTokenID, time.Now().Add(accessTokenTTL)); err != nil {
+			return err
+		}
+	}
+	return sessions.DeleteSession(ctx, session.ID)
+}
+
+func revokeToken(ctx context.Context, tokenID string, expiresAt time.Time) error {
+	if err := sessions.RevokeToken(ctx, tokenID, expiresAt); err != nil {
+		return err
+	}
+	revocationsMutex.Lock()
+	revocationCache[tokenID] = revocationEntry{revoked: true, checkedAt: time.Now()}
+	revocationsMutex.Unlock()
+	return nil
+}
+
+// isTokenRevoked checks the denylist, trusting recent answers for revocationCacheTTL.
+func isTokenRevoked(tokenID string) (bool, error) {
+	if sessions == nil || tokenID == "" {
+		return false, nil
+	}
+
+	now := time.Now()
+	revocationsMutex.Lock()
+	entry, ok := revocationCache[tokenID]
+	revocationsMutex.Unlock()
+	if ok && (entry.revoked || now.Sub(entry.checkedAt) < revocationCacheTTL) {
+		return entry.revoked, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	revoked, err := sessions.IsTokenRevoked(ctx, tokenID)
+	if err != nil {
+		return false, err
+	}
+
+	revocationsMutex.Lock()
+	for id, e := range revocationCache {
+		if now.Sub(e.checkedAt) > accessTokenTTL {
+			delete(revocationCache, id)
+		}
+	}
+	revocationCache[tokenID] = revocationEntry{revoked: revoked, checkedAt: now}
+	revocationsMutex.Unlock()
+	return revoked, nil
+}
+
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+func randomToken(size int) (string, error) {
+	b := make([]byte, size)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate random token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
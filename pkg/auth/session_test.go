@@ -0,0 +1,193 @@
+/*
+Copyright 2024 The Karmada Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auth
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/karmada-io/dashboard/pkg/etcd"
+)
+
+// fakeSessionStore keeps sessions and revoked tokens in memory.
+type fakeSessionStore struct {
+	mu       sync.Mutex
+	sessions map[string]etcd.Session
+	revoked  map[string]time.Time
+}
+
+func newFakeSessionStore() *fakeSessionStore {
+	return &fakeSessionStore{sessions: map[string]etcd.Session{}, revoked: map[string]time.Time{}}
+}
+
+func (f *fakeSessionStore) SaveSession(_ context.Context, session *etcd.Session) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.sessions[session.ID] = *session
+	return nil
+}
+
+func (f *fakeSessionStore) ReplaceSession(_ context.Context, current, updated *etcd.Session) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	stored, ok := f.sessions[current.ID]
+	if !ok || !reflect.DeepEqual(stored, *current) {
+		return false, nil
+	}
+	f.sessions[updated.ID] = *updated
+	return true, nil
+}
+
+func (f *fakeSessionStore) GetSession(_ context.Context, id string) (*etcd.Session, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	session, ok := f.sessions[id]
+	if !ok {
+		return nil, fmt.Errorf("session %s not found", id)
+	}
+	return &session, nil
+}
+
+func (f *fakeSessionStore) DeleteSession(_ context.Context, id string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.sessions, id)
+	return nil
+}
+
+func (f *fakeSessionStore) ListSessions(_ context.Context, username string) ([]*etcd.Session, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	result := []*etcd.Session{}
+	for _, session := range f.sessions {
+		if session.Username == username {
+			s := session
+			result = append(result, &s)
+		}
+	}
+	return result, nil
+}
+
+func (f *fakeSessionStore) RevokeToken(_ context.Context, tokenID string, expiresAt time.Time) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.revoked[tokenID] = expiresAt
+	return nil
+}
+
+func (f *fakeSessionStore) IsTokenRevoked(_ context.Context, tokenID string) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	_, ok := f.revoked[tokenID]
+	return ok, nil
+}
+
+func useFakeSessions(t *testing.T) *fakeSessionStore {
+	store := newFakeSessionStore()
+	previousStore, previousRole := sessions, userRole
+	sessions = store
+	userRole = func(context.Context, string) (string, error) { return "basic_user", nil }
+	t.Cleanup(func() {
+		sessions, userRole = previousStore, previousRole
+	})
+	return store
+}
+
+func TestRefreshSession(t *testing.T) {
+	useFakeSessions(t)
+	ctx := context.TODO()
+
+	first, err := StartSession(ctx, "alice", "admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := RefreshSession(ctx, first.RefreshToken)
+	if err != nil {
+		t.Fatalf("RefreshSession() failed: %v", err)
+	}
+	claims, err := ValidateToken(second.AccessToken)
+	if err != nil {
+		t.Fatalf("ValidateToken() of refreshed token failed: %v", err)
+	}
+	if claims.Username != "alice" || claims.Role != "basic_user" {
+		t.Errorf("refreshed token claims == (%s, %s), expected (alice, basic_user)", claims.Username, claims.Role)
+	}
+
+	// Redeeming a refresh token twice ends the session
+	if _, err := RefreshSession(ctx, first.RefreshToken); err == nil {
+		t.Errorf("RefreshSession() with a used refresh token succeeded")
+	}
+	if _, err := RefreshSession(ctx, second.RefreshToken); err == nil {
+		t.Errorf("RefreshSession() succeeded after the session was revoked")
+	}
+	if _, err := ValidateToken(second.AccessToken); err == nil {
+		t.Errorf("ValidateToken() accepted the access token of a revoked session")
+	}
+
+	for _, malformed := range []string{"", "session", ".secret", "unknown.secret"} {
+		if _, err := RefreshSession(ctx, malformed); err == nil {
+			t.Errorf("RefreshSession(%q) succeeded", malformed)
+		}
+	}
+}
+
+func TestLogout(t *testing.T) {
+	store := useFakeSessions(t)
+	ctx := context.TODO()
+
+	cases := []struct {
+		all               bool
+		expectedRemaining int
+	}{
+		{false, 1},
+		{true, 0},
+	}
+
+	for _, c := range cases {
+		store.sessions = map[string]etcd.Session{}
+		current, err := StartSession(ctx, "alice", "admin")
+		if err != nil {
+			t.Fatal(err)
+		}
+		other, err := StartSession(ctx, "alice", "admin")
+		if err != nil {
+			t.Fatal(err)
+		}
+		claims, err := ValidateToken(current.AccessToken)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := Logout(ctx, claims, c.all); err != nil {
+			t.Fatalf("Logout(all=%t) failed: %v", c.all, err)
+		}
+		if _, err := ValidateToken(current.AccessToken); err == nil {
+			t.Errorf("ValidateToken() accepted a token after Logout(all=%t)", c.all)
+		}
+		if _, err := ValidateToken(other.AccessToken); (err == nil) != (c.expectedRemaining == 1) {
+			t.Errorf("ValidateToken() of the other session after Logout(all=%t) returned %v", c.all, err)
+		}
+		remaining, _ := store.ListSessions(ctx, "alice")
+		if len(remaining) != c.expectedRemaining {
+			t.Errorf("Logout(all=%t) left %d sessions, expected %d", c.all, len(remaining), c.expectedRemaining)
+		}
+	}
+}
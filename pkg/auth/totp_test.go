@@ -0,0 +1,121 @@
+/*
+Copyright 2024 The Karmada Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auth
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfcSecret is the SHA-1 secret of the RFC 6238 test vectors, "12345678901234567890" in base32
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestTOTPCode(t *testing.T) {
+	// RFC 6238 appendix B, truncated to six digits
+	cases := []struct {
+		unix     int64
+		expected string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+	}
+
+	for _, c := range cases {
+		actual, err := totpCode(rfcSecret, totpStep(time.Unix(c.unix, 0)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if actual != c.expected {
+			t.Errorf("totpCode(%d) == %s, expected %s", c.unix, actual, c.expected)
+		}
+	}
+}
+
+func TestVerifyTOTP(t *testing.T) {
+	now := time.Unix(1234567890, 0)
+	step := totpStep(now)
+	code := func(step int64) string {
+		code, err := totpCode(rfcSecret, step)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return code
+	}
+
+	cases := []struct {
+		code     string
+		lastStep int64
+		valid    bool
+	}{
+		{code(step), 0, true},
+		{code(step - 1), 0, true},
+		{code(step + 1), 0, true},
+		{code(step - 2), 0, false},
+		// A code cannot be used again
+		{code(step), step, false},
+		{code(step + 1), step, true},
+		{"000 000", 0, false},
+		{"12345", 0, false},
+	}
+
+	for _, c := range cases {
+		if _, valid := verifyTOTP(rfcSecret, c.code, now, c.lastStep); valid != c.valid {
+			t.Errorf("verifyTOTP(%q, lastStep %d) == %t, expected %t", c.code, c.lastStep, valid, c.valid)
+		}
+	}
+}
+
+func TestRecoveryCodes(t *testing.T) {
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(codes) != recoveryCodeCount || len(hashes) != recoveryCodeCount {
+		t.Fatalf("newRecoveryCodes() returned %d codes and %d hashes", len(codes), len(hashes))
+	}
+
+	remaining, ok := useRecoveryCode(hashes, strings.ToUpper(codes[3]))
+	if !ok || len(remaining) != recoveryCodeCount-1 {
+		t.Errorf("useRecoveryCode() == (%d hashes, %t), expected (%d, true)", len(remaining), ok, recoveryCodeCount-1)
+	}
+	if _, ok := useRecoveryCode(remaining, codes[3]); ok {
+		t.Errorf("useRecoveryCode() accepted a used code")
+	}
+	if len(hashes) != recoveryCodeCount {
+		t.Errorf("useRecoveryCode() modified the hashes it was given")
+	}
+}
+
+func TestPartialTokenIsNotAnAccessToken(t *testing.T) {
+	token, _, err := issuePartialToken("alice", PurposeSecondFactor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ValidateToken(token); err == nil {
+		t.Errorf("ValidateToken() accepted a partial login token")
+	}
+	if _, err := ParseEnrollmentToken(token); err == nil {
+		t.Errorf("ParseEnrollmentToken() accepted a token issued for another purpose")
+	}
+	if _, err := parsePartialToken(token, PurposeSecondFactor); err != nil {
+		t.Errorf("parsePartialToken() failed: %v", err)
+	}
+}
@@ -0,0 +1,389 @@
+/*
+Copyright 2024 The Karmada Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"k8s.io/klog/v2"
+
+	"github.com/karmada-io/dashboard/pkg/etcd"
+)
+
+const (
+	// PurposeSecondFactor marks a partial token that is exchanged for a session with a TOTP or
+	// recovery code
+	PurposeSecondFactor = "2fa"
+	// PurposeEnrollment marks a partial token of a user who has to enroll in two-factor
+	// authentication before signing in
+	PurposeEnrollment = "2fa-enroll"
+	// partialTokenTTL is how long the second login step may take
+	partialTokenTTL = 5 * time.Minute
+)
+
+// ErrInvalidCode is returned for wrong, reused or expired TOTP and recovery codes
+var ErrInvalidCode = errors.New("invalid verification code")
+
+// SecondFactorRequiredError is returned by a login whose password was correct but that needs a
+// second step. Token is a partial token for that step, it authenticates nothing else.
+type SecondFactorRequiredError struct {
+	Token     string
+	ExpiresAt time.Time
+	// Enroll is set when the user has to enroll in two-factor authentication first
+	Enroll bool
+}
+
+func (e *SecondFactorRequiredError) Error() string {
+	if e.Enroll {
+		return "two-factor enrollment required"
+	}
+	return "two-factor verification required"
+}
+
+// Enrollment is a TOTP secret that waits for confirmation with a first code.
+type Enrollment struct {
+	Secret string
+	// URL is the otpauth URL authenticator apps enroll from
+	URL string
+}
+
+// TwoFactorStatus describes the two-factor authentication of a user.
+type TwoFactorStatus struct {
+	Enabled bool
+	// Required is set when the policy does not allow users without two-factor authentication
+	Required               bool
+	RemainingRecoveryCodes int
+}
+
+// completeLogin finishes a login whose password was verified, either with a session or with
+// the second step the user still has to pass.
+func completeLogin(ctx context.Context, user *etcd.User) (*TokenPair, error) {
+	purpose := ""
+	switch {
+	case user.TOTPEnabled:
+		purpose = PurposeSecondFactor
+	case GetPasswordPolicy().RequireTwoFactor:
+		purpose = PurposeEnrollment
+	default:
+		return StartSession(ctx, user.Username, user.Role)
+	}
+
+	token, expiresAt, err := issuePartialToken(user.Username, purpose)
+	if err != nil {
+		return nil, err
+	}
+	return nil, &SecondFactorRequiredError{Token: token, ExpiresAt: expiresAt, Enroll: purpose == PurposeEnrollment}
+}
+
+// issuePartialToken signs a short lived token for the second login step. It carries no role and
+// ValidateToken rejects it.
+func issuePartialToken(username, purpose string) (string, time.Time, error) {
+	tokenID, err := randomToken(16)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	now := time.Now()
+	expiresAt := now.Add(partialTokenTTL)
+	token, err := getKeyRing().signToken(&Claims{
+		Username: username,
+		Purpose:  purpose,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        tokenID,
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			Issuer:    "karmada-dashboard-api",
+			Subject:   username,
+		},
+	})
+	return token, expiresAt, err
+}
+
+// parsePartialToken validates a partial token issued for purpose.
+func parsePartialToken(tokenString, purpose string) (*Claims, error) {
+	claims := &Claims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, getKeyRing().keyFunc)
+	if err != nil || !token.Valid || claims.Purpose != purpose {
+		return nil, fmt.Errorf("invalid or expired login token")
+	}
+	revoked, err := isTokenRevoked(claims.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check token revocation: %w", err)
+	}
+	if revoked {
+		return nil, fmt.Errorf("invalid or expired login token")
+	}
+	return claims, nil
+}
+
+// ParseEnrollmentToken validates the partial token of a user who has to enroll before signing in.
+func ParseEnrollmentToken(tokenString string) (*Claims, error) {
+	return parsePartialToken(tokenString, PurposeEnrollment)
+}
+
+// VerifySecondFactor exchanges a partial login token and a TOTP or recovery code for a session.
+// Wrong codes count as failed logins.
+func VerifySecondFactor(ctx context.Context, partialToken, code string) (*TokenPair, error) {
+	claims, err := parsePartialToken(partialToken, PurposeSecondFactor)
+	if err != nil {
+		return nil, err
+	}
+	user, err := verifyCode(ctx, claims.Username, code)
+	if err != nil {
+		return nil, err
+	}
+
+	finishPartialToken(ctx, claims)
+	return StartSession(ctx, user.Username, user.Role)
+}
+
+// verifyCode checks a TOTP or recovery code of an enrolled user. Wrong codes count as failed logins.
+func verifyCode(ctx context.Context, username, code string) (*etcd.User, error) {
+	userMgr := GetUserManager()
+	if userMgr == nil {
+		return nil, fmt.Errorf("user manager not initialized")
+	}
+
+	var user *etcd.User
+	err := userMgr.ModifyUser(ctx, username, func(u *etcd.User) error {
+		if u.IsLocked(time.Now()) {
+			return ErrAccountLocked
+		}
+		if !u.TOTPEnabled || !useCode(u, code) {
+			return ErrInvalidCode
+		}
+		u.FailedLogins = 0
+		u.LockedUntil = nil
+		user = u
+		return nil
+	})
+	if errors.Is(err, ErrInvalidCode) {
+		policy := GetPasswordPolicy()
+		if locked, recordErr := userMgr.RecordFailedLogin(ctx, username, policy.MaxFailedLogins, policy.LockoutDuration); recordErr != nil {
+			klog.ErrorS(recordErr, "Failed to record failed login", "username", username)
+		} else if locked.IsLocked(time.Now()) {
+			return nil, ErrAccountLocked
+		}
+		return nil, err
+	}
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// FinishEnrollment starts the session of a user who signed in with an enrollment token and has
+// confirmed the enrollment since.
+func FinishEnrollment(ctx context.Context, claims *Claims) (*TokenPair, error) {
+	userMgr := GetUserManager()
+	if userMgr == nil {
+		return nil, fmt.Errorf("user manager not initialized")
+	}
+	user, err := userMgr.GetUser(ctx, claims.Username)
+	if err != nil {
+		return nil, err
+	}
+	if !user.TOTPEnabled {
+		return nil, fmt.Errorf("two-factor enrollment is not confirmed")
+	}
+	finishPartialToken(ctx, claims)
+	return StartSession(ctx, user.Username, user.Role)
+}
+
+// BeginEnrollment generates a new TOTP secret for a user. It takes effect once ConfirmEnrollment
+// receives a code generated from it.
+func BeginEnrollment(ctx context.Context, username string) (*Enrollment, error) {
+	userMgr := GetUserManager()
+	if userMgr == nil {
+		return nil, fmt.Errorf("user manager not initialized")
+	}
+	secret, err := newTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+	err = userMgr.ModifyUser(ctx, username, func(u *etcd.User) error {
+		if u.Provider != "" {
+			return fmt.Errorf("user %s signs in through %s", username, u.Provider)
+		}
+		if u.TOTPEnabled {
+			return fmt.Errorf("two-factor authentication is already enabled")
+		}
+		u.TOTPSecret = secret
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Enrollment{Secret: secret, URL: totpURL(username, secret)}, nil
+}
+
+// ConfirmEnrollment enables two-factor authentication with the first code from the enrolled
+// secret and returns the recovery codes. They are not stored and cannot be shown again.
+func ConfirmEnrollment(ctx context.Context, username, code string) ([]string, error) {
+	userMgr := GetUserManager()
+	if userMgr == nil {
+		return nil, fmt.Errorf("user manager not initialized")
+	}
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	err = userMgr.ModifyUser(ctx, username, func(u *etcd.User) error {
+		if u.TOTPEnabled {
+			return fmt.Errorf("two-factor authentication is already enabled")
+		}
+		if u.TOTPSecret == "" {
+			return fmt.Errorf("two-factor enrollment was not started")
+		}
+		step, ok := verifyTOTP(u.TOTPSecret, code, time.Now(), 0)
+		if !ok {
+			return ErrInvalidCode
+		}
+		u.TOTPEnabled = true
+		u.TOTPLastStep = step
+		u.RecoveryCodeHashes = hashes
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	klog.InfoS("Two-factor authentication enabled", "username", username)
+	return codes, nil
+}
+
+// DisableTwoFactor turns off two-factor authentication after checking a current code. It is
+// refused while the policy requires two-factor authentication.
+func DisableTwoFactor(ctx context.Context, username, code string) error {
+	if GetPasswordPolicy().RequireTwoFactor {
+		return fmt.Errorf("two-factor authentication is required and cannot be disabled")
+	}
+	userMgr := GetUserManager()
+	if userMgr == nil {
+		return fmt.Errorf("user manager not initialized")
+	}
+	err := userMgr.ModifyUser(ctx, username, func(u *etcd.User) error {
+		if !u.TOTPEnabled {
+			return fmt.Errorf("two-factor authentication is not enabled")
+		}
+		if !useCode(u, code) {
+			return ErrInvalidCode
+		}
+		clearTwoFactor(u)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	klog.InfoS("Two-factor authentication disabled", "username", username)
+	return nil
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes of a user after checking a current code.
+func RegenerateRecoveryCodes(ctx context.Context, username, code string) ([]string, error) {
+	userMgr := GetUserManager()
+	if userMgr == nil {
+		return nil, fmt.Errorf("user manager not initialized")
+	}
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	err = userMgr.ModifyUser(ctx, username, func(u *etcd.User) error {
+		if !u.TOTPEnabled {
+			return fmt.Errorf("two-factor authentication is not enabled")
+		}
+		if !useCode(u, code) {
+			return ErrInvalidCode
+		}
+		u.RecoveryCodeHashes = hashes
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// ResetTwoFactor removes the two-factor authentication of a user who lost their device, and ends
+// their sessions. It is meant for administrators.
+func ResetTwoFactor(ctx context.Context, username string) error {
+	userMgr := GetUserManager()
+	if userMgr == nil {
+		return fmt.Errorf("user manager not initialized")
+	}
+	if err := userMgr.ModifyUser(ctx, username, func(u *etcd.User) error {
+		clearTwoFactor(u)
+		return nil
+	}); err != nil {
+		return err
+	}
+	revokeUserSessions(ctx, username)
+	klog.InfoS("Two-factor authentication reset", "username", username)
+	return nil
+}
+
+// GetTwoFactorStatus returns the two-factor authentication status of a user.
+func GetTwoFactorStatus(ctx context.Context, username string) (*TwoFactorStatus, error) {
+	userMgr := GetUserManager()
+	if userMgr == nil {
+		return nil, fmt.Errorf("user manager not initialized")
+	}
+	user, err := userMgr.GetUser(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	return &TwoFactorStatus{
+		Enabled:                user.TOTPEnabled,
+		Required:               GetPasswordPolicy().RequireTwoFactor && user.Provider == "",
+		RemainingRecoveryCodes: len(user.RecoveryCodeHashes),
+	}, nil
+}
+
+// useCode accepts a TOTP code or consumes a recovery code of an enrolled user.
+func useCode(user *etcd.User, code string) bool {
+	if step, ok := verifyTOTP(user.TOTPSecret, code, time.Now(), user.TOTPLastStep); ok {
+		user.TOTPLastStep = step
+		return true
+	}
+	remaining, ok := useRecoveryCode(user.RecoveryCodeHashes, code)
+	if ok {
+		user.RecoveryCodeHashes = remaining
+		klog.InfoS("Recovery code used", "username", user.Username, "remaining", len(remaining))
+	}
+	return ok
+}
+
+func clearTwoFactor(user *etcd.User) {
+	user.TOTPSecret = ""
+	user.TOTPEnabled = false
+	user.TOTPLastStep = 0
+	user.RecoveryCodeHashes = nil
+}
+
+// finishPartialToken makes a partial token unusable once its login step is done.
+func finishPartialToken(ctx context.Context, claims *Claims) {
+	if sessions == nil || claims.ID == "" || claims.ExpiresAt == nil {
+		return
+	}
+	if err := revokeToken(ctx, claims.ID, claims.ExpiresAt.Time); err != nil {
+		klog.ErrorS(err, "Failed to revoke login token", "username", claims.Username)
+	}
+}
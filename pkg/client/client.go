@@ -17,11 +17,10 @@ limitations under the License.
 package client
 
 import (
+	"context"
 	"fmt"
 	"net/http"
-	"strings"
 
-	"github.com/gin-gonic/gin"
 	karmadaclientset "github.com/karmada-io/karmada/pkg/generated/clientset/versioned"
 	"k8s.io/client-go/dynamic"
 	kubeclient "k8s.io/client-go/kubernetes"
@@ -30,10 +29,8 @@ import (
 	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
 	"k8s.io/klog/v2"
 
-	// Added for user extraction and FGA
 	"github.com/karmada-io/dashboard/pkg/auth"
 	"github.com/karmada-io/dashboard/pkg/auth/fga"
-	v1 "k8s.io/api/authentication/v1"
 )
 
 // LoadRestConfig creates a rest.Config using the passed kubeconfig. If context is empty, current context in kubeconfig will be used.
@@ -152,81 +149,33 @@ func GetDynamicClient() (dynamic.Interface, error) {
 	return dynamicClient, nil
 }
 
-// GetDynamicClientForMember returns a dynamic client for a member cluster.
+// GetKarmadaDynamicClient returns a dynamic client for the Karmada control plane.
+func GetKarmadaDynamicClient() (dynamic.Interface, error) {
+	restConfig, _, err := GetKarmadaConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get karmada config: %v", err)
+	}
+	return dynamic.NewForConfig(restConfig)
+}
+
+// GetDynamicClientForMember returns a dynamic client for a member cluster on behalf of the caller carried in ctx.
+// Anonymous callers are denied access to member clusters.
 //
 // If clusterName is provided, it will configure the client to use the Karmada proxy to access the member cluster.
 // If clusterName is empty, it will return a regular dynamic client for the member cluster.
-func GetDynamicClientForMember(ctx *gin.Context, clusterName string) (dynamic.Interface, error) {
-	// Get the authenticated username using a comprehensive approach that checks:
-	// 1. User object in context (set by middleware)
-	// 2. JWT claims in context
-	// 3. Authorization header (Bearer token)
-	usernameRaw, exists := ctx.Get("user")
-	var username string
-	if exists {
-		if userObj, ok := usernameRaw.(*v1.UserInfo); ok {
-			username = userObj.Username
-		}
-	}
-	if username == "" {
-		// fallback: check JWT claims or other context fields as in getAuthenticatedUser
-		claimsRaw, exists := ctx.Get("claims")
-		if exists {
-			if claims, ok := claimsRaw.(map[string]interface{}); ok {
-				if uname, ok := claims["username"].(string); ok {
-					username = uname
-				}
-			}
-		}
-	}
-	// If still no username, try getting it from the Authorization header
-	if username == "" {
-		// Avoid nil pointer dereference if ctx.Request is nil (happens with empty contexts)
-		if ctx != nil && ctx.Request != nil {
-			authHeader := ctx.GetHeader("Authorization")
-			if authHeader != "" {
-				// The header format should be "Bearer <token>"
-				const prefix = "Bearer "
-				if len(authHeader) > len(prefix) && strings.HasPrefix(authHeader, prefix) {
-					tokenString := authHeader[len(prefix):]
-
-					// Validate the token
-					claims, err := auth.ValidateToken(tokenString)
-					if err == nil && claims != nil {
-						username = claims.Username
-					}
-				}
-			}
-		}
+func GetDynamicClientForMember(ctx context.Context, clusterName string) (dynamic.Interface, error) {
+	username := auth.UserFromContext(ctx)
+	if username == "" && clusterName != "" {
+		return nil, fmt.Errorf("authentication required to access cluster %s", clusterName)
 	}
+	return GetDynamicClientForMemberAsUser(ctx, username, clusterName)
+}
 
-	// Check cluster access permission if clusterName is provided and username is available
-	if clusterName != "" && username != "" {
-		fgaServiceRaw, exists := ctx.Get("fgaService")
-		var fgaClient fga.Client
-		if exists {
-			if svc, ok := fgaServiceRaw.(*fga.Service); ok {
-				fgaClient = svc.GetClient()
-			}
-		}
-		// Fallback to global FGA service if available
-		if fgaClient == nil && fga.FGAService != nil && fga.FGAService.GetClient() != nil {
-			fgaClient = fga.FGAService.GetClient()
-		}
-		if fgaClient == nil {
-			klog.Warning("OpenFGA client is not initialized, skipping permission check")
-			// Continue without permission check if OpenFGA is not available
-			// This is a fallback to maintain backward compatibility
-		} else {
-			// Only check permissions if we have an FGA client
-			allowed, err := fga.HasClusterAccess(ctx, fgaClient, username, clusterName)
-			if err != nil {
-				return nil, fmt.Errorf("failed to check cluster access: %w", err)
-			}
-			if !allowed {
-				return nil, fmt.Errorf("user %s does not have access to cluster %s", username, clusterName)
-			}
-		}
+// GetDynamicClientForMemberAsUser returns a dynamic client for a member cluster on behalf of username.
+// An empty username skips the cluster access check, which is reserved for calls issued by the dashboard itself.
+func GetDynamicClientForMemberAsUser(ctx context.Context, username, clusterName string) (dynamic.Interface, error) {
+	if err := ensureClusterAccess(ctx, username, clusterName); err != nil {
+		return nil, err
 	}
 
 	memberConfig, err := GetMemberConfig()
@@ -243,9 +192,50 @@ func GetDynamicClientForMember(ctx *gin.Context, clusterName string) (dynamic.In
 			return nil, fmt.Errorf("failed to get karmada config: %w", err)
 		}
 
-		memberConfig.Host = karmadaConfig.Host + fmt.Sprintf("/apis/cluster.karmada.io/v1alpha1/clusters/%s/proxy/", clusterName)
+		// Copy the shared member config so that concurrent requests never see each other's host
+		memberConfig = rest.CopyConfig(memberConfig)
+		memberConfig.Host = karmadaConfig.Host + fmt.Sprintf(proxyURL, clusterName)
 		klog.V(4).InfoS("Using member config with proxy", "host", memberConfig.Host)
 	}
 
 	return dynamic.NewForConfig(memberConfig)
 }
+
+// CheckClusterAccess checks that username is allowed to access clusterName, for callers that
+// filter data of several clusters instead of creating a client per cluster.
+func CheckClusterAccess(ctx context.Context, username, clusterName string) error {
+	return ensureClusterAccess(ctx, username, clusterName)
+}
+
+// ensureClusterAccess checks that username is allowed to access the whole of clusterName, which
+// takes the viewer relation: namespace and resource grants do not give access to the cluster.
+// Requests whose route already authorized the caller for their scope of the cluster, see
+// auth.WithAuthorizedCluster, are allowed. Requests authenticated with an API token are first
+// limited to the clusters of the token, also when the client is created as the dashboard itself.
+// The permission check is skipped when either value is empty or OpenFGA is not available.
+func ensureClusterAccess(ctx context.Context, username, clusterName string) error {
+	if clusterName == "" {
+		return nil
+	}
+	if err := auth.AuthorizeAPITokenCluster(ctx, clusterName); err != nil {
+		return err
+	}
+	if username == "" {
+		return nil
+	}
+	if auth.AuthorizedClusterFromContext(ctx) == clusterName {
+		return nil
+	}
+	if fga.FGAService == nil || fga.FGAService.GetClient() == nil {
+		klog.Warning("OpenFGA client is not initialized, skipping permission check")
+		return nil
+	}
+	allowed, err := fga.HasClusterAccess(ctx, fga.FGAService.GetClient(), username, clusterName)
+	if err != nil {
+		return fmt.Errorf("failed to check cluster access: %w", err)
+	}
+	if !allowed {
+		return fmt.Errorf("user %s does not have access to cluster %s", username, clusterName)
+	}
+	return nil
+}
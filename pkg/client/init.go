@@ -23,6 +23,7 @@ import (
 	"os"
 	"sync"
 
+	"github.com/karmada-io/dashboard/pkg/auth"
 	"github.com/karmada-io/dashboard/pkg/auth/fga"
 	karmadaclientset "github.com/karmada-io/karmada/pkg/generated/clientset/versioned"
 	kubeclient "k8s.io/client-go/kubernetes"
@@ -42,12 +43,7 @@ var (
 	karmadaMemberConfig                *rest.Config
 	inClusterKarmadaClient             karmadaclientset.Interface
 	inClusterClientForKarmadaAPIServer kubeclient.Interface
-	inClusterClientForMemberAPIServer  kubeclient.Interface
 	memberClients                      sync.Map
-	// CurrentUser stores the username for permission checks when context isn't available
-	CurrentUser string
-	// CurrentUserMutex protects concurrent access to CurrentUser
-	CurrentUserMutex sync.RWMutex
 )
 
 type configBuilder struct {
@@ -288,8 +284,74 @@ func InClusterClientForKarmadaAPIServer() kubeclient.Interface {
 	return inClusterClientForKarmadaAPIServer
 }
 
-// InClusterClientForMemberCluster returns a kubernetes client for member apiserver.
-func InClusterClientForMemberCluster(clusterName string) kubeclient.Interface {
+// InClusterClientForMemberCluster returns a kubernetes client for member apiserver on behalf of the caller carried in ctx.
+// It returns nil for anonymous callers.
+func InClusterClientForMemberCluster(ctx context.Context, clusterName string) kubeclient.Interface {
+	username := auth.UserFromContext(ctx)
+	if username == "" {
+		klog.InfoS("Access denied to anonymous caller", "cluster", clusterName)
+		return nil
+	}
+	return InClusterClientForMemberClusterAsUser(ctx, username, clusterName)
+}
+
+// NamespaceFilter reports whether the caller may see the objects of a namespace.
+type NamespaceFilter func(namespace string) bool
+
+// MemberClusterView returns a client of a member cluster to list objects of kind across namespaces
+// on behalf of the caller carried in ctx, and the filter of the namespaces whose objects the caller
+// may see. Callers with cluster-wide access see every namespace. Callers holding only namespace or
+// resource grants in the cluster get a client of the dashboard and see the namespaces they are
+// viewers of, of the objects of kind or, when kind is empty, of the namespace itself. The client is
+// nil if the caller has no access to the cluster.
+func MemberClusterView(ctx context.Context, clusterName, kind string) (kubeclient.Interface, NamespaceFilter) {
+	if memberClient := InClusterClientForMemberCluster(ctx, clusterName); memberClient != nil {
+		return memberClient, func(string) bool { return true }
+	}
+	username := auth.UserFromContext(ctx)
+	if username == "" || fga.FGAService == nil || fga.FGAService.GetClient() == nil {
+		return nil, nil
+	}
+	fgaClient := fga.FGAService.GetClient()
+	isGuest, err := fgaClient.Check(ctx, username, fga.RelationGuest, fga.TypeCluster, clusterName)
+	if err != nil || !isGuest {
+		if err != nil {
+			klog.ErrorS(err, "Failed to check guest role in OpenFGA", "user", username, "cluster", clusterName)
+		}
+		return nil, nil
+	}
+	memberClient := InClusterClientForMemberClusterAsUser(ctx, "", clusterName)
+	if memberClient == nil {
+		return nil, nil
+	}
+
+	visible := map[string]bool{}
+	return memberClient, func(namespace string) bool {
+		if namespace == "" {
+			return false
+		}
+		if allowed, found := visible[namespace]; found {
+			return allowed
+		}
+		var allowed bool
+		var err error
+		if kind == "" {
+			allowed, err = fga.HasNamespacePermission(ctx, fgaClient, username, fga.RelationViewer, clusterName, namespace)
+		} else {
+			allowed, err = fga.HasResourcePermission(ctx, fgaClient, username, fga.RelationViewer, clusterName, namespace, kind)
+		}
+		if err != nil {
+			klog.ErrorS(err, "Failed to check namespace access", "user", username, "cluster", clusterName, "namespace", namespace)
+		}
+		visible[namespace] = allowed && err == nil
+		return visible[namespace]
+	}
+}
+
+// InClusterClientForMemberClusterAsUser returns a kubernetes client for member apiserver on behalf of username.
+// It returns nil if username is not allowed to access the cluster. An empty username skips the access check,
+// which is reserved for calls issued by the dashboard itself.
+func InClusterClientForMemberClusterAsUser(ctx context.Context, username, clusterName string) kubeclient.Interface {
 	if !isKarmadaInitialized() {
 		return nil
 	}
@@ -299,30 +361,15 @@ func InClusterClientForMemberCluster(clusterName string) kubeclient.Interface {
 		return InClusterClient()
 	}
 
-	// Check permissions if we have a cluster name and a current user
-	if clusterName != "" {
-		// Get current username for permission check
-		CurrentUserMutex.RLock()
-		username := CurrentUser
-		CurrentUserMutex.RUnlock()
-		if username != "" && fga.FGAService != nil && fga.FGAService.GetClient() != nil {
-			// Check if the user has access to this cluster
-			allowed, err := fga.HasClusterAccess(context.Background(), fga.FGAService.GetClient(), username, clusterName)
-			if err != nil {
-				klog.ErrorS(err, "Failed to check cluster access", "user", username, "cluster", clusterName)
-				return nil
-			}
-			if !allowed {
-				klog.InfoS("Access denied", "user", username, "cluster", clusterName)
-				return nil
-			}
-		}
+	if err := ensureClusterAccess(ctx, username, clusterName); err != nil {
+		klog.InfoS("Access denied", "user", username, "cluster", clusterName, "reason", err.Error())
+		return nil
 	}
 
 	// Load and return Interface for member apiserver if already exist
 	if value, ok := memberClients.Load(clusterName); ok {
-		if inClusterClientForMemberAPIServer, ok = value.(kubeclient.Interface); ok {
-			return inClusterClientForMemberAPIServer
+		if c, ok := value.(kubeclient.Interface); ok {
+			return c
 		}
 		klog.Error("Could not get client for member apiserver")
 		return nil
@@ -339,15 +386,15 @@ func InClusterClientForMemberCluster(clusterName string) kubeclient.Interface {
 		klog.ErrorS(err, "Could not get member restConfig")
 		return nil
 	}
+	memberConfig = rest.CopyConfig(memberConfig)
 	memberConfig.Host = restConfig.Host + fmt.Sprintf(proxyURL, clusterName)
 	c, err := kubeclient.NewForConfig(memberConfig)
 	if err != nil {
 		klog.ErrorS(err, "Could not init kubernetes in-cluster client for member apiserver")
 		return nil
 	}
-	inClusterClientForMemberAPIServer = c
-	memberClients.Store(clusterName, inClusterClientForMemberAPIServer)
-	return inClusterClientForMemberAPIServer
+	actual, _ := memberClients.LoadOrStore(clusterName, kubeclient.Interface(c))
+	return actual.(kubeclient.Interface)
 }
 
 // ConvertRestConfigToAPIConfig converts a rest.Config to a clientcmdapi.Config.
@@ -371,19 +418,3 @@ func ConvertRestConfigToAPIConfig(restConfig *rest.Config) *clientcmdapi.Config
 	clientcmdConfig.CurrentContext = "contextName"
 	return clientcmdConfig
 }
-
-// SetCurrentUser sets the current user for permission checks
-// This should be called during authentication to ensure username is available for cluster access checks
-func SetCurrentUser(username string) {
-	CurrentUserMutex.Lock()
-	defer CurrentUserMutex.Unlock()
-	CurrentUser = username
-	klog.V(4).InfoS("Current user set", "username", username)
-}
-
-// GetCurrentUser returns the currently set username
-func GetCurrentUser() string {
-	CurrentUserMutex.RLock()
-	defer CurrentUserMutex.RUnlock()
-	return CurrentUser
-}
@@ -0,0 +1,289 @@
+/*
+Copyright 2024 The Karmada Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/metadata"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/restmapper"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// DefaultDiscoveryCacheTTL is how long the discovered resources of a cluster are used before
+	// being discovered again
+	DefaultDiscoveryCacheTTL = 10 * time.Minute
+	// discoveryMissRefreshInterval bounds how often an unknown kind triggers a new discovery, and
+	// how often a failed CRD watch is retried
+	discoveryMissRefreshInterval = 30 * time.Second
+)
+
+var (
+	discoveryCacheTTL = DefaultDiscoveryCacheTTL
+	clusterMappers    sync.Map
+
+	crdResource = schema.GroupVersionResource{Group: "apiextensions.k8s.io", Version: "v1", Resource: "customresourcedefinitions"}
+)
+
+// SetDiscoveryCacheTTL sets how long the discovered resources of a cluster are cached.
+func SetDiscoveryCacheTTL(ttl time.Duration) {
+	if ttl > 0 {
+		discoveryCacheTTL = ttl
+	}
+}
+
+// ClusterRESTMapper maps the kinds and resources of a cluster to their REST mappings from the
+// discovery information of the cluster. The discovery is cached, refreshed after the cache TTL,
+// when a CRD of the cluster changes or when an unknown kind is looked up.
+type ClusterRESTMapper struct {
+	cluster string
+	mapper  meta.ResettableRESTMapper
+	// crds watches the CRDs of the cluster, nil if they are not watched
+	crds metadata.ResourceInterface
+	ttl  time.Duration
+	now  func() time.Time
+
+	mu          sync.Mutex
+	refreshedAt time.Time
+	stale       bool
+	watching    bool
+	watchAfter  time.Time
+}
+
+// MemberRESTMapper returns the RESTMapper of a member cluster, or of the management cluster for
+// "mgmt-cluster". Mappers are shared by every caller, the discovery is done as the dashboard.
+func MemberRESTMapper(clusterName string) (*ClusterRESTMapper, error) {
+	if clusterName == "" {
+		return nil, fmt.Errorf("cluster name is required")
+	}
+	return restMapperFor(clusterName, clusterName, func() (*rest.Config, error) {
+		return clusterRestConfig(clusterName)
+	})
+}
+
+// MemberMapping returns the REST mapping of a kind of a member cluster, as addressed by the raw
+// resource routes: by a kind, resource or short name, or by a group, version and kind when version
+// is set, where the core group is named "core".
+func MemberMapping(clusterName, group, version, kind string) (*meta.RESTMapping, error) {
+	mapper, err := MemberRESTMapper(clusterName)
+	if err != nil {
+		return nil, err
+	}
+	if version != "" {
+		if group == "core" {
+			group = ""
+		}
+		return mapper.MappingForGroupVersionKind(group, version, kind)
+	}
+	return mapper.MappingFor(kind)
+}
+
+// KarmadaRESTMapper returns the RESTMapper of the Karmada control plane.
+func KarmadaRESTMapper() (*ClusterRESTMapper, error) {
+	// Cluster names are DNS labels, so the key cannot collide with one
+	return restMapperFor("karmada/apiserver", "karmada", func() (*rest.Config, error) {
+		config, _, err := GetKarmadaConfig()
+		return config, err
+	})
+}
+
+func restMapperFor(key, cluster string, configFunc func() (*rest.Config, error)) (*ClusterRESTMapper, error) {
+	if value, ok := clusterMappers.Load(key); ok {
+		return value.(*ClusterRESTMapper), nil
+	}
+
+	config, err := configFunc()
+	if err != nil {
+		return nil, err
+	}
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	metadataClient, err := metadata.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	mapper := newClusterRESTMapper(cluster, discoveryClient, metadataClient.Resource(crdResource), discoveryCacheTTL)
+	actual, _ := clusterMappers.LoadOrStore(key, mapper)
+	return actual.(*ClusterRESTMapper), nil
+}
+
+// clusterRestConfig returns the config of the management cluster for "mgmt-cluster" and of the
+// Karmada proxy of the cluster otherwise.
+func clusterRestConfig(clusterName string) (*rest.Config, error) {
+	if clusterName == "mgmt-cluster" {
+		config, _, err := GetKubeConfig()
+		return config, err
+	}
+	memberConfig, err := GetMemberConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get member config: %w", err)
+	}
+	karmadaConfig, _, err := GetKarmadaConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get karmada config: %w", err)
+	}
+	memberConfig = rest.CopyConfig(memberConfig)
+	memberConfig.Host = karmadaConfig.Host + fmt.Sprintf(proxyURL, clusterName)
+	return memberConfig, nil
+}
+
+func newClusterRESTMapper(cluster string, discoveryClient discovery.DiscoveryInterface, crds metadata.ResourceInterface, ttl time.Duration) *ClusterRESTMapper {
+	cached := memory.NewMemCacheClient(discoveryClient)
+	return &ClusterRESTMapper{
+		cluster: cluster,
+		mapper:  restmapper.NewShortcutExpander(restmapper.NewDeferredDiscoveryRESTMapper(cached), cached, nil).(meta.ResettableRESTMapper),
+		crds:    crds,
+		ttl:     ttl,
+		now:     time.Now,
+	}
+}
+
+// MappingFor returns the REST mapping of a kind, e.g. "Deployment", "deployment", a resource, e.g.
+// "deployments", "deployments.apps", or a short name, e.g. "deploy". Matching is case-insensitive.
+func (m *ClusterRESTMapper) MappingFor(kind string) (*meta.RESTMapping, error) {
+	if kind == "" {
+		return nil, fmt.Errorf("kind is required")
+	}
+	return m.lookup(schema.ParseGroupResource(strings.ToLower(kind)).WithVersion(""))
+}
+
+// MappingForGroupVersionKind returns the REST mapping of a kind of a group version. The kind may
+// also be given as its resource, and is matched case-insensitively.
+func (m *ClusterRESTMapper) MappingForGroupVersionKind(group, version, kind string) (*meta.RESTMapping, error) {
+	if version == "" || kind == "" {
+		return nil, fmt.Errorf("version and kind are required")
+	}
+	mapping, err := m.lookup(schema.GroupVersionResource{Group: group, Version: version, Resource: strings.ToLower(kind)})
+	if err != nil {
+		return nil, err
+	}
+	// An empty group matches every group in lookups, while here it is the core group
+	if gvk := mapping.GroupVersionKind; gvk.Group != group || gvk.Version != version {
+		return nil, &meta.NoKindMatchError{GroupKind: schema.GroupKind{Group: group, Kind: kind}, SearchedVersions: []string{version}}
+	}
+	return mapping, nil
+}
+
+// lookup returns the mapping of resource, discovering the cluster again if the resource is not
+// known yet.
+func (m *ClusterRESTMapper) lookup(resource schema.GroupVersionResource) (*meta.RESTMapping, error) {
+	m.refresh(false)
+	mapping, err := m.mapping(resource)
+	if meta.IsNoMatchError(err) && m.refresh(true) {
+		mapping, err = m.mapping(resource)
+	}
+	return mapping, err
+}
+
+func (m *ClusterRESTMapper) mapping(resource schema.GroupVersionResource) (*meta.RESTMapping, error) {
+	gvk, err := m.mapper.KindFor(resource)
+	if err != nil {
+		return nil, err
+	}
+	return m.mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+}
+
+// refresh drops the cached discovery if it expired, was invalidated by a CRD change or, on a miss,
+// was not refreshed recently. It reports whether it was dropped.
+func (m *ClusterRESTMapper) refresh(miss bool) bool {
+	m.watchCRDs()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := m.now()
+
+	if m.refreshedAt.IsZero() {
+		// The first lookup discovers the cluster anyway
+		m.refreshedAt = now
+		return false
+	}
+	if !m.stale && now.Sub(m.refreshedAt) < m.ttl && (!miss || now.Sub(m.refreshedAt) < discoveryMissRefreshInterval) {
+		return false
+	}
+	klog.V(4).InfoS("Refreshing discovery", "cluster", m.cluster, "stale", m.stale, "miss", miss)
+	m.mapper.Reset()
+	m.refreshedAt = now
+	m.stale = false
+	return true
+}
+
+// watchCRDs starts watching the CRDs of the cluster if they are not watched, so that the mapper
+// learns about added and removed custom resources. The watch is restarted by the next lookup after
+// it ends, and the cache is dropped then since changes may have been missed. The list and watch
+// requests are sent without holding m.mu, so that a slow cluster does not hold up other lookups.
+func (m *ClusterRESTMapper) watchCRDs() {
+	m.mu.Lock()
+	if m.crds == nil || m.watching || m.now().Before(m.watchAfter) {
+		m.mu.Unlock()
+		return
+	}
+	// Claim the watch, so that concurrent lookups do not start another one
+	m.watching = true
+	m.mu.Unlock()
+
+	w, err := m.startCRDWatch()
+	if err != nil {
+		klog.V(4).InfoS("Failed to watch CRDs, relying on the discovery cache TTL", "cluster", m.cluster, "err", err)
+		m.mu.Lock()
+		m.watching = false
+		m.watchAfter = m.now().Add(discoveryMissRefreshInterval)
+		m.mu.Unlock()
+		return
+	}
+	go func() {
+		defer w.Stop()
+		for range w.ResultChan() {
+			m.Invalidate()
+		}
+		m.mu.Lock()
+		m.watching = false
+		m.stale = true
+		m.mu.Unlock()
+	}()
+}
+
+// startCRDWatch watches the CRDs of the cluster from the current version of their list, so that
+// existing CRDs are not replayed.
+func (m *ClusterRESTMapper) startCRDWatch() (watch.Interface, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	list, err := m.crds.List(ctx, metav1.ListOptions{Limit: 1})
+	if err != nil {
+		return nil, err
+	}
+	return m.crds.Watch(context.Background(), metav1.ListOptions{ResourceVersion: list.ResourceVersion})
+}
+
+// Invalidate marks the cached discovery stale, it is refreshed by the next lookup.
+func (m *ClusterRESTMapper) Invalidate() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.stale = true
+}
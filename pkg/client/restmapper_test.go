@@ -0,0 +1,212 @@
+/*
+Copyright 2024 The Karmada Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	fakemetadata "k8s.io/client-go/metadata/fake"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+var widgetResources = &metav1.APIResourceList{
+	GroupVersion: "example.io/v1alpha1",
+	APIResources: []metav1.APIResource{{Name: "widgets", SingularName: "widget", Kind: "Widget", Namespaced: true}},
+}
+
+func testDiscovery() *fakediscovery.FakeDiscovery {
+	return &fakediscovery.FakeDiscovery{Fake: &clienttesting.Fake{Resources: []*metav1.APIResourceList{
+		{GroupVersion: "v1", APIResources: []metav1.APIResource{
+			{Name: "pods", SingularName: "pod", Kind: "Pod", Namespaced: true, ShortNames: []string{"po"}},
+			{Name: "nodes", SingularName: "node", Kind: "Node"},
+		}},
+		{GroupVersion: "apps/v1", APIResources: []metav1.APIResource{
+			{Name: "deployments", SingularName: "deployment", Kind: "Deployment", Namespaced: true, ShortNames: []string{"deploy"}},
+			{Name: "deployments/scale", Kind: "Scale", Namespaced: true},
+		}},
+	}}}
+}
+
+// testClock returns a clock that is moved forward by the returned function.
+func testClock() (func() time.Time, func(time.Duration)) {
+	now := time.Date(2024, 5, 15, 10, 0, 0, 0, time.UTC)
+	return func() time.Time { return now }, func(d time.Duration) { now = now.Add(d) }
+}
+
+func TestClusterRESTMapperMappingFor(t *testing.T) {
+	mapper := newClusterRESTMapper("member1", testDiscovery(), nil, time.Hour)
+	cases := []struct {
+		kind     string
+		resource schema.GroupVersionResource
+		scope    meta.RESTScopeName
+		valid    bool
+	}{
+		{"Deployment", schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}, meta.RESTScopeNameNamespace, true},
+		{"deployments", schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}, meta.RESTScopeNameNamespace, true},
+		{"deployments.apps", schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}, meta.RESTScopeNameNamespace, true},
+		{"deploy", schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}, meta.RESTScopeNameNamespace, true},
+		{"po", schema.GroupVersionResource{Version: "v1", Resource: "pods"}, meta.RESTScopeNameNamespace, true},
+		{"node", schema.GroupVersionResource{Version: "v1", Resource: "nodes"}, meta.RESTScopeNameRoot, true},
+		{"widget", schema.GroupVersionResource{}, "", false},
+		{"", schema.GroupVersionResource{}, "", false},
+	}
+	for _, c := range cases {
+		mapping, err := mapper.MappingFor(c.kind)
+		if (err == nil) != c.valid {
+			t.Errorf("MappingFor(%q) failed: %v, expected valid %t", c.kind, err, c.valid)
+			continue
+		}
+		if err == nil && (mapping.Resource != c.resource || mapping.Scope.Name() != c.scope) {
+			t.Errorf("MappingFor(%q) == %v %s, expected %v %s", c.kind, mapping.Resource, mapping.Scope.Name(), c.resource, c.scope)
+		}
+	}
+}
+
+func TestClusterRESTMapperMappingForGroupVersionKind(t *testing.T) {
+	mapper := newClusterRESTMapper("member1", testDiscovery(), nil, time.Hour)
+	cases := []struct {
+		group, version, kind string
+		resource             string
+		valid                bool
+	}{
+		{"apps", "v1", "Deployment", "deployments", true},
+		{"apps", "v1", "deployment", "deployments", true},
+		{"", "v1", "Pod", "pods", true},
+		{"apps", "v2", "Deployment", "", false},
+		{"", "v1", "Deployment", "", false},
+		{"apps", "", "Deployment", "", false},
+	}
+	for _, c := range cases {
+		mapping, err := mapper.MappingForGroupVersionKind(c.group, c.version, c.kind)
+		if (err == nil) != c.valid || (err == nil && mapping.Resource.Resource != c.resource) {
+			t.Errorf("MappingForGroupVersionKind(%q, %q, %q) == %v, %v, expected %q", c.group, c.version, c.kind, mapping, err, c.resource)
+		}
+	}
+}
+
+func TestClusterRESTMapperRefresh(t *testing.T) {
+	discoveryClient := testDiscovery()
+	mapper := newClusterRESTMapper("member1", discoveryClient, nil, time.Hour)
+	now, advance := testClock()
+	mapper.now = now
+
+	if _, err := mapper.MappingFor("widget"); !meta.IsNoMatchError(err) {
+		t.Fatalf("MappingFor(widget) failed with %v, expected no match", err)
+	}
+	discoveryClient.Resources = append(discoveryClient.Resources, widgetResources)
+	// Misses right after a discovery do not discover the cluster again
+	if _, err := mapper.MappingFor("widget"); !meta.IsNoMatchError(err) {
+		t.Errorf("MappingFor(widget) failed with %v, expected no match until the miss refresh interval", err)
+	}
+	advance(discoveryMissRefreshInterval)
+	if _, err := mapper.MappingFor("widget"); err != nil {
+		t.Errorf("MappingFor(widget) failed after the miss refresh interval: %v", err)
+	}
+
+	// Removed resources are forgotten on invalidation or once the cache expired
+	discoveryClient.Resources = discoveryClient.Resources[:2]
+	if _, err := mapper.MappingFor("widget"); err != nil {
+		t.Errorf("MappingFor(widget) failed before invalidation: %v", err)
+	}
+	mapper.Invalidate()
+	if _, err := mapper.MappingFor("widget"); !meta.IsNoMatchError(err) {
+		t.Errorf("MappingFor(widget) failed with %v after invalidation, expected no match", err)
+	}
+	discoveryClient.Resources = append(discoveryClient.Resources, widgetResources)
+	advance(time.Hour)
+	if _, err := mapper.MappingFor("Widget.example.io"); err != nil {
+		t.Errorf("MappingFor(Widget.example.io) failed after the cache expired: %v", err)
+	}
+}
+
+func TestClusterRESTMapperWatchCRDs(t *testing.T) {
+	scheme := runtime.NewScheme()
+	metav1.AddMetaToScheme(scheme)
+	crds := fakemetadata.NewSimpleMetadataClient(scheme).Resource(crdResource)
+	mapper := newClusterRESTMapper("member1", testDiscovery(), crds, time.Hour)
+
+	if _, err := mapper.MappingFor("pod"); err != nil {
+		t.Fatalf("MappingFor(pod) failed: %v", err)
+	}
+	crd := &metav1.PartialObjectMetadata{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "apiextensions.k8s.io/v1", Kind: "CustomResourceDefinition"},
+		ObjectMeta: metav1.ObjectMeta{Name: "widgets.example.io"},
+	}
+	if _, err := crds.(fakemetadata.MetadataClient).CreateFake(crd, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("failed to create CRD: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	for {
+		mapper.mu.Lock()
+		stale := mapper.stale
+		mapper.mu.Unlock()
+		if stale {
+			return
+		}
+		select {
+		case <-ctx.Done():
+			t.Fatal("the mapper was not invalidated by the CRD change")
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
+func TestClusterRESTMapperWatchCRDsUnlocked(t *testing.T) {
+	scheme := runtime.NewScheme()
+	metav1.AddMetaToScheme(scheme)
+	metadataClient := fakemetadata.NewSimpleMetadataClient(scheme)
+	listing, release := make(chan struct{}), make(chan struct{})
+	metadataClient.PrependReactor("list", "customresourcedefinitions", func(clienttesting.Action) (bool, runtime.Object, error) {
+		close(listing)
+		<-release
+		return false, nil, nil
+	})
+	mapper := newClusterRESTMapper("member1", testDiscovery(), metadataClient.Resource(crdResource), time.Hour)
+
+	done := make(chan error)
+	go func() {
+		_, err := mapper.MappingFor("pod")
+		done <- err
+	}()
+	<-listing
+
+	// A cluster slow to list its CRDs does not hold up the mapper
+	invalidated := make(chan struct{})
+	go func() {
+		mapper.Invalidate()
+		close(invalidated)
+	}()
+	select {
+	case <-invalidated:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Invalidate() waited for the CRD list")
+	}
+
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatalf("MappingFor(pod) failed: %v", err)
+	}
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package client
 
 import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 )
@@ -30,6 +31,8 @@ const (
 	DefaultBurst = 1e6
 	// DefaultUserAgent is the default http header for user-agent
 	DefaultUserAgent = "dashboard"
+	// FieldManager is the field manager of the changes applied through the dashboard
+	FieldManager = "karmada-dashboard"
 	// DefaultCmdConfigName is the default cluster/context/auth name to be set in clientcmd config
 	DefaultCmdConfigName = "kubernetes"
 	// ImpersonateUserHeader is the header name to identify username to act as.
@@ -43,9 +46,18 @@ const (
 )
 
 // ResourceVerber is responsible for performing generic CRUD operations on all supported resources.
+// The dryRun arguments are passed on to the API server, e.g. []string{"All"} to validate a change
+// without persisting it.
 type ResourceVerber interface {
-	Update(object *unstructured.Unstructured) error
+	Update(object *unstructured.Unstructured, dryRun []string) error
 	Get(kind string, namespace string, name string) (runtime.Object, error)
-	Delete(kind string, namespace string, name string, deleteNow bool) error
-	Create(object *unstructured.Unstructured) (*unstructured.Unstructured, error)
+	Delete(kind string, namespace string, name string, deleteNow bool, dryRun []string) error
+	Create(object *unstructured.Unstructured, dryRun []string) (*unstructured.Unstructured, error)
+	Apply(object *unstructured.Unstructured, opts metav1.ApplyOptions) (*unstructured.Unstructured, error)
+}
+
+// ApplyOptions returns the options of a server-side apply by the dashboard. force takes over the
+// fields other managers own instead of failing on conflicts.
+func ApplyOptions(force bool, dryRun []string) metav1.ApplyOptions {
+	return metav1.ApplyOptions{FieldManager: FieldManager, Force: force, DryRun: dryRun}
 }
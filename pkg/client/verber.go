@@ -112,7 +112,7 @@ func (v *resourceVerber) buildGroupVersionResourceCache(resourceList []*metav1.A
 }
 
 // Delete deletes the resource of the given kind in the given namespace with the given name.
-func (v *resourceVerber) Delete(kind string, namespace string, name string, deleteNow bool) error {
+func (v *resourceVerber) Delete(kind string, namespace string, name string, deleteNow bool, dryRun []string) error {
 	gvr, err := v.groupVersionResourceFromKind(kind)
 	if err != nil {
 		return err
@@ -122,6 +122,7 @@ func (v *resourceVerber) Delete(kind string, namespace string, name string, dele
 	defaultPropagationPolicy := metav1.DeletePropagationForeground
 	defaultDeleteOptions := metav1.DeleteOptions{
 		PropagationPolicy: &defaultPropagationPolicy,
+		DryRun:            dryRun,
 	}
 
 	if deleteNow {
@@ -133,7 +134,7 @@ func (v *resourceVerber) Delete(kind string, namespace string, name string, dele
 }
 
 // Update patches resource of the given kind in the given namespace with the given name.
-func (v *resourceVerber) Update(object *unstructured.Unstructured) error {
+func (v *resourceVerber) Update(object *unstructured.Unstructured, dryRun []string) error {
 	name := object.GetName()
 	namespace := object.GetNamespace()
 	gvr := v.groupVersionResourceFromUnstructured(object)
@@ -163,7 +164,7 @@ func (v *resourceVerber) Update(object *unstructured.Unstructured) error {
 		}
 
 		klog.V(3).InfoS("patching resource", "group", gvr.Group, "version", gvr.Version, "resource", gvr.Resource, "name", name, "namespace", namespace, "patch", string(patchBytes))
-		_, updateErr := v.client.Resource(gvr).Namespace(namespace).Patch(context.TODO(), name, k8stypes.MergePatchType, patchBytes, metav1.PatchOptions{})
+		_, updateErr := v.client.Resource(gvr).Namespace(namespace).Patch(context.TODO(), name, k8stypes.MergePatchType, patchBytes, metav1.PatchOptions{DryRun: dryRun})
 		return updateErr
 	})
 }
@@ -178,11 +179,19 @@ func (v *resourceVerber) Get(kind string, namespace string, name string) (runtim
 }
 
 // Create creates the resource of the given kind in the given namespace with the given name.
-func (v *resourceVerber) Create(object *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+func (v *resourceVerber) Create(object *unstructured.Unstructured, dryRun []string) (*unstructured.Unstructured, error) {
 	namespace := object.GetNamespace()
 	gvr := v.groupVersionResourceFromUnstructured(object)
 
-	return v.client.Resource(gvr).Namespace(namespace).Create(context.TODO(), object, metav1.CreateOptions{})
+	return v.client.Resource(gvr).Namespace(namespace).Create(context.TODO(), object, metav1.CreateOptions{DryRun: dryRun})
+}
+
+// Apply applies the given object with server-side apply.
+func (v *resourceVerber) Apply(object *unstructured.Unstructured, opts metav1.ApplyOptions) (*unstructured.Unstructured, error) {
+	namespace := object.GetNamespace()
+	gvr := v.groupVersionResourceFromUnstructured(object)
+
+	return v.client.Resource(gvr).Namespace(namespace).Apply(context.TODO(), object.GetName(), object, opts)
 }
 
 // VerberClient returns a resourceVerber client.
@@ -28,4 +28,10 @@ const (
 	FirstSeenProperty         = "firstSeen"
 	LastSeenProperty          = "lastSeen"
 	ReasonProperty            = "reason"
+	TimestampProperty         = "timestamp"
+	UserProperty              = "user"
+	VerbProperty              = "verb"
+	ClusterProperty           = "cluster"
+	ResourceProperty          = "resource"
+	OutcomeProperty           = "outcome"
 )
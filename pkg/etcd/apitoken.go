@@ -0,0 +1,156 @@
+/*
+Copyright 2024 The Karmada Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package etcd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"k8s.io/klog/v2"
+)
+
+// APITokenKeyPrefix is the prefix for API token keys in etcd
+const APITokenKeyPrefix = "/karmada/dashboard/api-tokens/"
+
+// APIToken is a personal access token that lets automation call the dashboard API as its owner
+type APIToken struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	// Username is the owner, whose permissions the token has
+	Username string `json:"username"`
+	// SecretHash is the SHA-256 hash of the token secret
+	SecretHash string `json:"secretHash"`
+	// Scopes limit what the token can do on top of the permissions of the owner
+	Scopes []string `json:"scopes"`
+	// Clusters limits the member clusters the token can access, empty allows all
+	Clusters   []string  `json:"clusters,omitempty"`
+	CreatedBy  string    `json:"createdBy"`
+	CreatedAt  time.Time `json:"createdAt"`
+	ExpiresAt  time.Time `json:"expiresAt"`
+	LastUsedAt time.Time `json:"lastUsedAt,omitempty"`
+}
+
+// APITokenManager stores API tokens. Tokens are attached to etcd leases so that they disappear
+// once they expire.
+type APITokenManager struct {
+	client *clientv3.Client
+}
+
+// NewAPITokenManager creates a new APITokenManager
+func NewAPITokenManager(client *clientv3.Client) *APITokenManager {
+	return &APITokenManager{
+		client: client,
+	}
+}
+
+// CreateToken saves a new token until its expiry
+func (tm *APITokenManager) CreateToken(ctx context.Context, token *APIToken) error {
+	data, err := json.Marshal(token)
+	if err != nil {
+		return fmt.Errorf("failed to marshal API token: %v", err)
+	}
+	ttl := int64(time.Until(token.ExpiresAt).Seconds()) + 1
+	if ttl < 1 {
+		return fmt.Errorf("API token %s is already expired", token.Name)
+	}
+	lease, err := tm.client.Grant(ctx, ttl)
+	if err != nil {
+		return fmt.Errorf("failed to grant lease: %v", err)
+	}
+
+	key := APITokenKeyPrefix + token.ID
+	txn := tm.client.Txn(ctx).
+		If(clientv3.Compare(clientv3.CreateRevision(key), "=", 0)).
+		Then(clientv3.OpPut(key, string(data), clientv3.WithLease(lease.ID)))
+	resp, err := txn.Commit()
+	if err != nil {
+		return fmt.Errorf("failed to save API token: %v", err)
+	}
+	if !resp.Succeeded {
+		return fmt.Errorf("API token %s already exists", token.ID)
+	}
+	return nil
+}
+
+// GetToken gets a token by ID
+func (tm *APITokenManager) GetToken(ctx context.Context, id string) (*APIToken, error) {
+	resp, err := tm.client.Get(ctx, APITokenKeyPrefix+id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get API token from etcd: %v", err)
+	}
+	if len(resp.Kvs) == 0 {
+		return nil, fmt.Errorf("API token %s not found", id)
+	}
+
+	var token APIToken
+	if err := json.Unmarshal(resp.Kvs[0].Value, &token); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal API token: %v", err)
+	}
+	return &token, nil
+}
+
+// TouchToken records that a token was used, keeping its lease
+func (tm *APITokenManager) TouchToken(ctx context.Context, token *APIToken, usedAt time.Time) error {
+	updated := *token
+	updated.LastUsedAt = usedAt
+	data, err := json.Marshal(&updated)
+	if err != nil {
+		return fmt.Errorf("failed to marshal API token: %v", err)
+	}
+	key := APITokenKeyPrefix + token.ID
+	// Only update a token that still exists, so that a concurrent delete is not undone
+	txn := tm.client.Txn(ctx).
+		If(clientv3.Compare(clientv3.CreateRevision(key), ">", 0)).
+		Then(clientv3.OpPut(key, string(data), clientv3.WithIgnoreLease()))
+	if _, err := txn.Commit(); err != nil {
+		return fmt.Errorf("failed to update API token: %v", err)
+	}
+	return nil
+}
+
+// DeleteToken deletes a token
+func (tm *APITokenManager) DeleteToken(ctx context.Context, id string) error {
+	if _, err := tm.client.Delete(ctx, APITokenKeyPrefix+id); err != nil {
+		return fmt.Errorf("failed to delete API token: %v", err)
+	}
+	return nil
+}
+
+// ListTokens lists the tokens of a user, or of all users when username is empty
+func (tm *APITokenManager) ListTokens(ctx context.Context, username string) ([]*APIToken, error) {
+	resp, err := tm.client.Get(ctx, APITokenKeyPrefix, clientv3.WithPrefix())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list API tokens: %v", err)
+	}
+
+	tokens := []*APIToken{}
+	for _, kv := range resp.Kvs {
+		token := &APIToken{}
+		if err := json.Unmarshal(kv.Value, token); err != nil {
+			klog.ErrorS(err, "Failed to unmarshal API token", "key", string(kv.Key))
+			continue
+		}
+		if username == "" || token.Username == username {
+			tokens = append(tokens, token)
+		}
+	}
+	return tokens, nil
+}
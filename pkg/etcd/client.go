@@ -129,6 +129,13 @@ func GetEtcdClient(opts *Options) (*clientv3.Client, error) {
 	return etcdClient, err
 }
 
+// SetEtcdClient replaces the client GetEtcdClient returns, e.g. with an in-memory one in tests
+func SetEtcdClient(client *clientv3.Client) {
+	etcdClientMutex.Lock()
+	defer etcdClientMutex.Unlock()
+	etcdClient = client
+}
+
 // newEtcdClient creates a new etcd client
 func newEtcdClient(opts *Options) (*clientv3.Client, error) {
 	if opts == nil {
@@ -0,0 +1,307 @@
+/*
+Copyright 2024 The Karmada Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package etcdtest provides an in-memory etcd key-value store for tests.
+package etcdtest
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"sync"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/api/v3/mvccpb"
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+// NewClient returns an etcd client whose key-value API is served from memory. Gets, puts,
+// deletes and transactions comparing single keys are supported. Gets can be limited and sorted by
+// key, and deletes always return the deleted key-values. Leases can be granted but never expire. The other APIs of the client, such
+// as watches, are not available.
+func NewClient() *clientv3.Client {
+	return &clientv3.Client{KV: &KV{kvs: map[string]*mvccpb.KeyValue{}}, Lease: &Lease{}}
+}
+
+// Lease grants leases that never expire, the keys attached to them are kept until deleted.
+// Only Grant and Revoke are supported.
+type Lease struct {
+	clientv3.Lease
+
+	mu     sync.Mutex
+	nextID clientv3.LeaseID
+}
+
+// Grant returns a new lease.
+func (l *Lease) Grant(_ context.Context, ttl int64) (*clientv3.LeaseGrantResponse, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.nextID++
+	return &clientv3.LeaseGrantResponse{ResponseHeader: &pb.ResponseHeader{}, ID: l.nextID, TTL: ttl}, nil
+}
+
+// Revoke does nothing, the keys attached to the lease are kept.
+func (l *Lease) Revoke(_ context.Context, _ clientv3.LeaseID) (*clientv3.LeaseRevokeResponse, error) {
+	return &clientv3.LeaseRevokeResponse{Header: &pb.ResponseHeader{}}, nil
+}
+
+// KV is an in-memory clientv3.KV. Every write bumps the revision of the store, like etcd does.
+type KV struct {
+	mu       sync.Mutex
+	revision int64
+	kvs      map[string]*mvccpb.KeyValue
+}
+
+var _ clientv3.KV = &KV{}
+
+// Put puts a key-value pair into the store.
+func (kv *KV) Put(ctx context.Context, key, val string, opts ...clientv3.OpOption) (*clientv3.PutResponse, error) {
+	resp, err := kv.Do(ctx, clientv3.OpPut(key, val, opts...))
+	return resp.Put(), err
+}
+
+// Get retrieves a key or a range of keys.
+func (kv *KV) Get(ctx context.Context, key string, opts ...clientv3.OpOption) (*clientv3.GetResponse, error) {
+	resp, err := kv.Do(ctx, clientv3.OpGet(key, opts...))
+	return resp.Get(), err
+}
+
+// Delete deletes a key or a range of keys.
+func (kv *KV) Delete(ctx context.Context, key string, opts ...clientv3.OpOption) (*clientv3.DeleteResponse, error) {
+	resp, err := kv.Do(ctx, clientv3.OpDelete(key, opts...))
+	return resp.Del(), err
+}
+
+// Compact does nothing, the store keeps no history.
+func (kv *KV) Compact(_ context.Context, _ int64, _ ...clientv3.CompactOption) (*clientv3.CompactResponse, error) {
+	return &clientv3.CompactResponse{}, nil
+}
+
+// Do applies a single operation.
+func (kv *KV) Do(ctx context.Context, op clientv3.Op) (clientv3.OpResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return clientv3.OpResponse{}, err
+	}
+	kv.mu.Lock()
+	defer kv.mu.Unlock()
+	if op.IsTxn() {
+		cmps, thenOps, elseOps := op.Txn()
+		return (*clientv3.TxnResponse)(kv.txn(cmps, thenOps, elseOps)).OpResponse(), nil
+	}
+	resp := kv.apply(op)
+	switch {
+	case op.IsGet():
+		return (*clientv3.GetResponse)(resp.GetResponseRange()).OpResponse(), nil
+	case op.IsPut():
+		return (*clientv3.PutResponse)(resp.GetResponsePut()).OpResponse(), nil
+	default:
+		return (*clientv3.DeleteResponse)(resp.GetResponseDeleteRange()).OpResponse(), nil
+	}
+}
+
+// Txn creates a transaction, committed atomically.
+func (kv *KV) Txn(ctx context.Context) clientv3.Txn {
+	return &txn{kv: kv, ctx: ctx}
+}
+
+type txn struct {
+	kv      *KV
+	ctx     context.Context
+	cmps    []clientv3.Cmp
+	thenOps []clientv3.Op
+	elseOps []clientv3.Op
+}
+
+func (t *txn) If(cs ...clientv3.Cmp) clientv3.Txn {
+	t.cmps = append(t.cmps, cs...)
+	return t
+}
+
+func (t *txn) Then(ops ...clientv3.Op) clientv3.Txn {
+	t.thenOps = append(t.thenOps, ops...)
+	return t
+}
+
+func (t *txn) Else(ops ...clientv3.Op) clientv3.Txn {
+	t.elseOps = append(t.elseOps, ops...)
+	return t
+}
+
+func (t *txn) Commit() (*clientv3.TxnResponse, error) {
+	resp, err := t.kv.Do(t.ctx, clientv3.OpTxn(t.cmps, t.thenOps, t.elseOps))
+	return resp.Txn(), err
+}
+
+// txn evaluates the comparisons and applies the matching operations, kv.mu held.
+func (kv *KV) txn(cmps []clientv3.Cmp, thenOps, elseOps []clientv3.Op) *pb.TxnResponse {
+	succeeded := true
+	for _, cmp := range cmps {
+		if !kv.compare(cmp) {
+			succeeded = false
+			break
+		}
+	}
+	ops := thenOps
+	if !succeeded {
+		ops = elseOps
+	}
+	resp := &pb.TxnResponse{Header: kv.header(), Succeeded: succeeded}
+	for _, op := range ops {
+		resp.Responses = append(resp.Responses, kv.apply(op))
+	}
+	resp.Header = kv.header()
+	return resp
+}
+
+// compare evaluates a comparison on a single key, a missing key having zero revisions and version.
+func (kv *KV) compare(cmp clientv3.Cmp) bool {
+	current := kv.kvs[string(cmp.KeyBytes())]
+	if current == nil {
+		current = &mvccpb.KeyValue{}
+	}
+	var result int
+	switch target := cmp.TargetUnion.(type) {
+	case *pb.Compare_Version:
+		result = compareInt(current.Version, target.Version)
+	case *pb.Compare_CreateRevision:
+		result = compareInt(current.CreateRevision, target.CreateRevision)
+	case *pb.Compare_ModRevision:
+		result = compareInt(current.ModRevision, target.ModRevision)
+	case *pb.Compare_Value:
+		if current.Version == 0 {
+			return false
+		}
+		result = bytes.Compare(current.Value, target.Value)
+	default:
+		panic(fmt.Sprintf("etcdtest: unsupported comparison %v", cmp.Target))
+	}
+	switch cmp.Result {
+	case pb.Compare_EQUAL:
+		return result == 0
+	case pb.Compare_NOT_EQUAL:
+		return result != 0
+	case pb.Compare_GREATER:
+		return result > 0
+	default:
+		return result < 0
+	}
+}
+
+// apply applies a get, put or delete, kv.mu held.
+func (kv *KV) apply(op clientv3.Op) *pb.ResponseOp {
+	switch {
+	case op.IsGet():
+		kvs := kv.rangeKeys(op.KeyBytes(), op.RangeBytes())
+		resp := &pb.RangeResponse{Header: kv.header(), Count: int64(len(kvs))}
+		limit, descend := rangeOptions(op)
+		if descend {
+			slices.Reverse(kvs)
+		}
+		if limit > 0 && int64(len(kvs)) > limit {
+			kvs, resp.More = kvs[:limit], true
+		}
+		if !op.IsCountOnly() {
+			for _, item := range kvs {
+				copied := *item
+				if op.IsKeysOnly() {
+					copied.Value = nil
+				}
+				resp.Kvs = append(resp.Kvs, &copied)
+			}
+		}
+		return &pb.ResponseOp{Response: &pb.ResponseOp_ResponseRange{ResponseRange: resp}}
+	case op.IsPut():
+		kv.revision++
+		key := string(op.KeyBytes())
+		item := &mvccpb.KeyValue{Key: op.KeyBytes(), Value: op.ValueBytes(), CreateRevision: kv.revision, ModRevision: kv.revision, Version: 1}
+		if previous, ok := kv.kvs[key]; ok {
+			item.CreateRevision = previous.CreateRevision
+			item.Version = previous.Version + 1
+		}
+		kv.kvs[key] = item
+		return &pb.ResponseOp{Response: &pb.ResponseOp_ResponsePut{ResponsePut: &pb.PutResponse{Header: kv.header()}}}
+	case op.IsDelete():
+		kvs := kv.rangeKeys(op.KeyBytes(), op.RangeBytes())
+		if len(kvs) > 0 {
+			kv.revision++
+		}
+		for _, item := range kvs {
+			delete(kv.kvs, string(item.Key))
+		}
+		resp := &pb.DeleteRangeResponse{Header: kv.header(), Deleted: int64(len(kvs)), PrevKvs: kvs}
+		return &pb.ResponseOp{Response: &pb.ResponseOp_ResponseDeleteRange{ResponseDeleteRange: resp}}
+	default:
+		panic("etcdtest: unsupported operation")
+	}
+}
+
+// rangeKeys returns the key-values of key, or of [key, end) when end is set, in ascending order.
+func (kv *KV) rangeKeys(key, end []byte) []*mvccpb.KeyValue {
+	var result []*mvccpb.KeyValue
+	for k, item := range kv.kvs {
+		switch {
+		case len(end) == 0:
+			if k != string(key) {
+				continue
+			}
+		// A range end of "\x00" selects every key from key on
+		case bytes.Equal(end, []byte{0}):
+			if k < string(key) {
+				continue
+			}
+		default:
+			if k < string(key) || k >= string(end) {
+				continue
+			}
+		}
+		result = append(result, item)
+	}
+	sort.Slice(result, func(i, j int) bool { return bytes.Compare(result[i].Key, result[j].Key) < 0 })
+	return result
+}
+
+// rangeOptions returns the limit of a get and whether it sorts keys in descending order. Op does
+// not expose them, they are read from its fields.
+func rangeOptions(op clientv3.Op) (int64, bool) {
+	value := reflect.ValueOf(op)
+	limit := value.FieldByName("limit").Int()
+	descend := false
+	if sortOption := value.FieldByName("sort"); !sortOption.IsNil() {
+		if target := clientv3.SortTarget(sortOption.Elem().FieldByName("Target").Int()); target != clientv3.SortByKey {
+			panic(fmt.Sprintf("etcdtest: unsupported sort target %v", target))
+		}
+		descend = clientv3.SortOrder(sortOption.Elem().FieldByName("Order").Int()) == clientv3.SortDescend
+	}
+	return limit, descend
+}
+
+func (kv *KV) header() *pb.ResponseHeader {
+	return &pb.ResponseHeader{Revision: kv.revision}
+}
+
+func compareInt(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,233 @@
+/*
+Copyright 2024 The Karmada Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package etcd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// GroupKeyPrefix is the prefix for group keys in etcd
+	GroupKeyPrefix = "/karmada/dashboard/groups/"
+)
+
+// Group represents a named set of users that can be granted permissions together
+type Group struct {
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	Members     []string  `json:"members"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+// GroupManager handles group operations
+type GroupManager struct {
+	client *clientv3.Client
+}
+
+// NewGroupManager creates a new GroupManager
+func NewGroupManager(client *clientv3.Client) *GroupManager {
+	return &GroupManager{
+		client: client,
+	}
+}
+
+// CreateGroup creates a new group without members
+func (gm *GroupManager) CreateGroup(ctx context.Context, name, description string) (*Group, error) {
+	now := time.Now()
+	group := &Group{
+		Name:        name,
+		Description: description,
+		Members:     []string{},
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	groupData, err := json.Marshal(group)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal group: %v", err)
+	}
+
+	key := GroupKeyPrefix + name
+	txn, err := gm.client.Txn(ctx).
+		If(clientv3.Compare(clientv3.CreateRevision(key), "=", 0)).
+		Then(clientv3.OpPut(key, string(groupData))).
+		Commit()
+	if err != nil {
+		return nil, fmt.Errorf("failed to save group: %v", err)
+	}
+	if !txn.Succeeded {
+		return nil, fmt.Errorf("group %s already exists", name)
+	}
+	return group, nil
+}
+
+// GroupExists checks if a group exists
+func (gm *GroupManager) GroupExists(ctx context.Context, name string) (bool, error) {
+	if gm == nil || gm.client == nil {
+		return false, fmt.Errorf("etcd client is nil")
+	}
+
+	resp, err := gm.client.Get(ctx, GroupKeyPrefix+name)
+	if err != nil {
+		return false, fmt.Errorf("failed to check if group exists: %v", err)
+	}
+	return len(resp.Kvs) > 0, nil
+}
+
+// GetGroup gets a group by name
+func (gm *GroupManager) GetGroup(ctx context.Context, name string) (*Group, error) {
+	resp, err := gm.client.Get(ctx, GroupKeyPrefix+name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get group from etcd: %v", err)
+	}
+
+	if len(resp.Kvs) == 0 {
+		return nil, fmt.Errorf("group %s not found", name)
+	}
+
+	var group Group
+	if err := json.Unmarshal(resp.Kvs[0].Value, &group); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal group: %v", err)
+	}
+
+	return &group, nil
+}
+
+// UpdateGroup saves the description of a group. Members are changed with AddMember and
+// RemoveMember, so that an update does not overwrite concurrent membership changes.
+func (gm *GroupManager) UpdateGroup(ctx context.Context, group *Group) error {
+	return gm.modifyGroup(ctx, group.Name, func(stored *Group) bool {
+		if stored.Description == group.Description {
+			return false
+		}
+		stored.Description = group.Description
+		return true
+	})
+}
+
+// DeleteGroup deletes a group
+func (gm *GroupManager) DeleteGroup(ctx context.Context, name string) error {
+	_, err := gm.client.Delete(ctx, GroupKeyPrefix+name)
+	if err != nil {
+		return fmt.Errorf("failed to delete group: %v", err)
+	}
+	return nil
+}
+
+// ListGroups lists all groups
+func (gm *GroupManager) ListGroups(ctx context.Context) ([]*Group, error) {
+	resp, err := gm.client.Get(ctx, GroupKeyPrefix, clientv3.WithPrefix())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list groups: %v", err)
+	}
+
+	groups := make([]*Group, 0, len(resp.Kvs))
+	for _, kv := range resp.Kvs {
+		group := &Group{}
+		if err := json.Unmarshal(kv.Value, group); err != nil {
+			klog.ErrorS(err, "Failed to unmarshal group", "key", string(kv.Key))
+			continue
+		}
+		groups = append(groups, group)
+	}
+
+	return groups, nil
+}
+
+// ListGroupsForUser lists the groups the user is a member of
+func (gm *GroupManager) ListGroupsForUser(ctx context.Context, username string) ([]*Group, error) {
+	groups, err := gm.ListGroups(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return slices.DeleteFunc(groups, func(g *Group) bool {
+		return !slices.Contains(g.Members, username)
+	}), nil
+}
+
+// AddMember adds a user to a group. Adding an existing member is a no-op.
+func (gm *GroupManager) AddMember(ctx context.Context, name, username string) error {
+	return gm.modifyGroup(ctx, name, func(group *Group) bool {
+		if slices.Contains(group.Members, username) {
+			return false
+		}
+		group.Members = append(group.Members, username)
+		return true
+	})
+}
+
+// RemoveMember removes a user from a group. Removing a user that is not a member is a no-op.
+func (gm *GroupManager) RemoveMember(ctx context.Context, name, username string) error {
+	return gm.modifyGroup(ctx, name, func(group *Group) bool {
+		if !slices.Contains(group.Members, username) {
+			return false
+		}
+		group.Members = slices.DeleteFunc(group.Members, func(m string) bool { return m == username })
+		return true
+	})
+}
+
+// maxGroupModifyAttempts bounds the retries of modifyGroup when the group keeps changing
+const maxGroupModifyAttempts = 5
+
+// modifyGroup applies modify to the stored group and saves the result if modify reports a change.
+// The group is saved only if it was not changed since it was read; on a concurrent change modify
+// is applied again to the new version, so that concurrent membership edits are not lost.
+func (gm *GroupManager) modifyGroup(ctx context.Context, name string, modify func(group *Group) bool) error {
+	key := GroupKeyPrefix + name
+	for attempt := 0; attempt < maxGroupModifyAttempts; attempt++ {
+		resp, err := gm.client.Get(ctx, key)
+		if err != nil {
+			return fmt.Errorf("failed to get group from etcd: %v", err)
+		}
+		if len(resp.Kvs) == 0 {
+			return fmt.Errorf("group %s not found", name)
+		}
+
+		group := &Group{}
+		if err := json.Unmarshal(resp.Kvs[0].Value, group); err != nil {
+			return fmt.Errorf("failed to unmarshal group: %v", err)
+		}
+		if !modify(group) {
+			return nil
+		}
+		group.UpdatedAt = time.Now()
+		groupData, err := json.Marshal(group)
+		if err != nil {
+			return fmt.Errorf("failed to marshal group: %v", err)
+		}
+
+		txn, err := gm.client.Txn(ctx).
+			If(clientv3.Compare(clientv3.ModRevision(key), "=", resp.Kvs[0].ModRevision)).
+			Then(clientv3.OpPut(key, string(groupData))).
+			Commit()
+		if err != nil {
+			return fmt.Errorf("failed to save group: %v", err)
+		}
+		if txn.Succeeded {
+			return nil
+		}
+	}
+	return fmt.Errorf("group %s was modified concurrently", name)
+}
@@ -0,0 +1,176 @@
+/*
+Copyright 2024 The Karmada Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package etcd
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+
+	"github.com/karmada-io/dashboard/pkg/etcd/etcdtest"
+)
+
+func TestGroupCRUD(t *testing.T) {
+	ctx := context.Background()
+	gm := NewGroupManager(etcdtest.NewClient())
+
+	if _, err := gm.CreateGroup(ctx, "sre", "on call"); err != nil {
+		t.Fatalf("CreateGroup: %v", err)
+	}
+	if _, err := gm.CreateGroup(ctx, "sre", "again"); err == nil {
+		t.Errorf("CreateGroup of an existing group succeeded")
+	}
+	if _, err := gm.CreateGroup(ctx, "dev", ""); err != nil {
+		t.Fatalf("CreateGroup: %v", err)
+	}
+
+	if err := gm.UpdateGroup(ctx, &Group{Name: "sre", Description: "site reliability"}); err != nil {
+		t.Fatalf("UpdateGroup: %v", err)
+	}
+	if err := gm.UpdateGroup(ctx, &Group{Name: "missing"}); err == nil {
+		t.Errorf("UpdateGroup of a missing group succeeded")
+	}
+	group, err := gm.GetGroup(ctx, "sre")
+	if err != nil {
+		t.Fatalf("GetGroup: %v", err)
+	}
+	if group.Description != "site reliability" || len(group.Members) != 0 {
+		t.Errorf("GetGroup: got %+v", group)
+	}
+
+	groups, err := gm.ListGroups(ctx)
+	if err != nil {
+		t.Fatalf("ListGroups: %v", err)
+	}
+	if len(groups) != 2 {
+		t.Errorf("ListGroups: got %d groups, expected 2", len(groups))
+	}
+
+	if err := gm.DeleteGroup(ctx, "sre"); err != nil {
+		t.Fatalf("DeleteGroup: %v", err)
+	}
+	if exists, err := gm.GroupExists(ctx, "sre"); err != nil || exists {
+		t.Errorf("GroupExists after delete: got (%v, %v)", exists, err)
+	}
+	if _, err := gm.GetGroup(ctx, "sre"); err == nil {
+		t.Errorf("GetGroup of a deleted group succeeded")
+	}
+}
+
+func TestGroupMembership(t *testing.T) {
+	ctx := context.Background()
+	gm := NewGroupManager(etcdtest.NewClient())
+	for _, name := range []string{"sre", "dev"} {
+		if _, err := gm.CreateGroup(ctx, name, ""); err != nil {
+			t.Fatalf("CreateGroup: %v", err)
+		}
+	}
+
+	cases := []struct {
+		add             bool
+		group           string
+		username        string
+		expectedMembers []string
+		expectErr       bool
+	}{
+		{true, "sre", "alice", []string{"alice"}, false},
+		{true, "sre", "bob", []string{"alice", "bob"}, false},
+		{true, "sre", "alice", []string{"alice", "bob"}, false},
+		{false, "sre", "carol", []string{"alice", "bob"}, false},
+		{false, "sre", "alice", []string{"bob"}, false},
+		{true, "dev", "bob", []string{"bob"}, false},
+		{true, "missing", "bob", nil, true},
+		{false, "missing", "bob", nil, true},
+	}
+	for _, c := range cases {
+		var err error
+		if c.add {
+			err = gm.AddMember(ctx, c.group, c.username)
+		} else {
+			err = gm.RemoveMember(ctx, c.group, c.username)
+		}
+		if c.expectErr {
+			if err == nil {
+				t.Errorf("add=%v %s %s: expected an error", c.add, c.group, c.username)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("add=%v %s %s: %v", c.add, c.group, c.username, err)
+		}
+		group, err := gm.GetGroup(ctx, c.group)
+		if err != nil {
+			t.Fatalf("GetGroup: %v", err)
+		}
+		if !slices.Equal(group.Members, c.expectedMembers) {
+			t.Errorf("add=%v %s %s: got members %v, expected %v", c.add, c.group, c.username, group.Members, c.expectedMembers)
+		}
+	}
+
+	groups, err := gm.ListGroupsForUser(ctx, "bob")
+	if err != nil {
+		t.Fatalf("ListGroupsForUser: %v", err)
+	}
+	if len(groups) != 2 {
+		t.Errorf("ListGroupsForUser: got %d groups, expected 2", len(groups))
+	}
+}
+
+// racingKV runs race before the first transaction, as if another replica wrote in between.
+type racingKV struct {
+	clientv3.KV
+	race func()
+}
+
+func (r *racingKV) Txn(ctx context.Context) clientv3.Txn {
+	if race := r.race; race != nil {
+		r.race = nil
+		race()
+	}
+	return r.KV.Txn(ctx)
+}
+
+func TestGroupMembershipConcurrentChange(t *testing.T) {
+	ctx := context.Background()
+	client := etcdtest.NewClient()
+	gm := NewGroupManager(client)
+	if _, err := gm.CreateGroup(ctx, "sre", ""); err != nil {
+		t.Fatalf("CreateGroup: %v", err)
+	}
+
+	other := NewGroupManager(client)
+	kv := &racingKV{KV: client.KV}
+	kv.race = func() {
+		if err := other.AddMember(ctx, "sre", "bob"); err != nil {
+			t.Errorf("concurrent AddMember: %v", err)
+		}
+	}
+	gm.client = &clientv3.Client{KV: kv}
+
+	if err := gm.AddMember(ctx, "sre", "alice"); err != nil {
+		t.Fatalf("AddMember: %v", err)
+	}
+	group, err := gm.GetGroup(ctx, "sre")
+	if err != nil {
+		t.Fatalf("GetGroup: %v", err)
+	}
+	if !slices.Equal(group.Members, []string{"bob", "alice"}) {
+		t.Errorf("got members %v, expected the concurrent change to be kept", group.Members)
+	}
+}
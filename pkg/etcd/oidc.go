@@ -0,0 +1,88 @@
+/*
+Copyright 2024 The Karmada Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package etcd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+const (
+	// OIDCLoginKeyPrefix is the prefix for pending OIDC login keys in etcd
+	OIDCLoginKeyPrefix = "/karmada/dashboard/oidc-logins/"
+)
+
+// OIDCLogin is an OIDC authorization request that has not come back to the callback yet
+type OIDCLogin struct {
+	// Verifier is the PKCE code verifier of the request
+	Verifier  string    `json:"verifier"`
+	Nonce     string    `json:"nonce"`
+	Redirect  string    `json:"redirect,omitempty"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// OIDCLoginManager stores pending OIDC logins by state, so that the callback of a login can be
+// handled by any replica of the dashboard. Logins are attached to etcd leases so that abandoned
+// ones disappear once they expire.
+type OIDCLoginManager struct {
+	client *clientv3.Client
+}
+
+// NewOIDCLoginManager creates a new OIDCLoginManager
+func NewOIDCLoginManager(client *clientv3.Client) *OIDCLoginManager {
+	return &OIDCLoginManager{
+		client: client,
+	}
+}
+
+// SaveLogin stores the login started with state until it expires
+func (lm *OIDCLoginManager) SaveLogin(ctx context.Context, state string, login *OIDCLogin) error {
+	data, err := json.Marshal(login)
+	if err != nil {
+		return fmt.Errorf("failed to marshal OIDC login: %v", err)
+	}
+	lease, err := grantUntil(ctx, lm.client, login.ExpiresAt)
+	if err != nil {
+		return err
+	}
+	if _, err := lm.client.Put(ctx, OIDCLoginKeyPrefix+state, string(data), clientv3.WithLease(lease)); err != nil {
+		return fmt.Errorf("failed to save OIDC login: %v", err)
+	}
+	return nil
+}
+
+// TakeLogin deletes the login started with state and returns it, nil if there is none. The
+// delete is atomic, so that a login completes once only, even across replicas.
+func (lm *OIDCLoginManager) TakeLogin(ctx context.Context, state string) (*OIDCLogin, error) {
+	resp, err := lm.client.Delete(ctx, OIDCLoginKeyPrefix+state, clientv3.WithPrevKV())
+	if err != nil {
+		return nil, fmt.Errorf("failed to take OIDC login: %v", err)
+	}
+	if len(resp.PrevKvs) == 0 {
+		return nil, nil
+	}
+
+	login := &OIDCLogin{}
+	if err := json.Unmarshal(resp.PrevKvs[0].Value, login); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal OIDC login: %v", err)
+	}
+	return login, nil
+}
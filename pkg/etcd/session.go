@@ -0,0 +1,182 @@
+/*
+Copyright 2024 The Karmada Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package etcd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// SessionKeyPrefix is the prefix for login session keys in etcd
+	SessionKeyPrefix = "/karmada/dashboard/sessions/"
+	// RevokedTokenKeyPrefix is the prefix for revoked access token IDs in etcd
+	RevokedTokenKeyPrefix = "/karmada/dashboard/revoked-tokens/"
+)
+
+// Session is a login session that access tokens can be refreshed from
+type Session struct {
+	ID       string `json:"id"`
+	Username string `json:"username"`
+	// RefreshTokenHash is the SHA-256 hash of the current refresh token
+	RefreshTokenHash string `json:"refreshTokenHash"`
+	// AccessTokenID is the ID of the last access token issued for the session
+	AccessTokenID string    `json:"accessTokenID"`
+	CreatedAt     time.Time `json:"createdAt"`
+	ExpiresAt     time.Time `json:"expiresAt"`
+}
+
+// SessionManager stores login sessions and revoked access tokens. Both are attached to etcd
+// leases so that they disappear once they expire.
+type SessionManager struct {
+	client *clientv3.Client
+}
+
+// NewSessionManager creates a new SessionManager
+func NewSessionManager(client *clientv3.Client) *SessionManager {
+	return &SessionManager{
+		client: client,
+	}
+}
+
+// SaveSession creates or overwrites a session until its expiry
+func (sm *SessionManager) SaveSession(ctx context.Context, session *Session) error {
+	data, err := json.Marshal(session)
+	if err != nil {
+		return fmt.Errorf("failed to marshal session: %v", err)
+	}
+	lease, err := grantUntil(ctx, sm.client, session.ExpiresAt)
+	if err != nil {
+		return err
+	}
+	if _, err := sm.client.Put(ctx, SessionKeyPrefix+session.ID, string(data), clientv3.WithLease(lease)); err != nil {
+		return fmt.Errorf("failed to save session: %v", err)
+	}
+	return nil
+}
+
+// ReplaceSession overwrites a session only if it still matches current, so that a refresh token
+// cannot be redeemed twice by concurrent requests. It reports whether the session was replaced.
+func (sm *SessionManager) ReplaceSession(ctx context.Context, current, updated *Session) (bool, error) {
+	currentData, err := json.Marshal(current)
+	if err != nil {
+		return false, fmt.Errorf("failed to marshal session: %v", err)
+	}
+	updatedData, err := json.Marshal(updated)
+	if err != nil {
+		return false, fmt.Errorf("failed to marshal session: %v", err)
+	}
+	lease, err := grantUntil(ctx, sm.client, updated.ExpiresAt)
+	if err != nil {
+		return false, err
+	}
+
+	key := SessionKeyPrefix + updated.ID
+	resp, err := sm.client.Txn(ctx).
+		If(clientv3.Compare(clientv3.Value(key), "=", string(currentData))).
+		Then(clientv3.OpPut(key, string(updatedData), clientv3.WithLease(lease))).
+		Commit()
+	if err != nil {
+		return false, fmt.Errorf("failed to replace session: %v", err)
+	}
+	return resp.Succeeded, nil
+}
+
+// GetSession gets a session by ID
+func (sm *SessionManager) GetSession(ctx context.Context, id string) (*Session, error) {
+	resp, err := sm.client.Get(ctx, SessionKeyPrefix+id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get session from etcd: %v", err)
+	}
+	if len(resp.Kvs) == 0 {
+		return nil, fmt.Errorf("session %s not found", id)
+	}
+
+	var session Session
+	if err := json.Unmarshal(resp.Kvs[0].Value, &session); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal session: %v", err)
+	}
+	return &session, nil
+}
+
+// DeleteSession deletes a session
+func (sm *SessionManager) DeleteSession(ctx context.Context, id string) error {
+	if _, err := sm.client.Delete(ctx, SessionKeyPrefix+id); err != nil {
+		return fmt.Errorf("failed to delete session: %v", err)
+	}
+	return nil
+}
+
+// ListSessions lists the sessions of a user
+func (sm *SessionManager) ListSessions(ctx context.Context, username string) ([]*Session, error) {
+	resp, err := sm.client.Get(ctx, SessionKeyPrefix, clientv3.WithPrefix())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %v", err)
+	}
+
+	sessions := []*Session{}
+	for _, kv := range resp.Kvs {
+		session := &Session{}
+		if err := json.Unmarshal(kv.Value, session); err != nil {
+			klog.ErrorS(err, "Failed to unmarshal session", "key", string(kv.Key))
+			continue
+		}
+		if session.Username == username {
+			sessions = append(sessions, session)
+		}
+	}
+	return sessions, nil
+}
+
+// RevokeToken adds an access token ID to the denylist until the token expires
+func (sm *SessionManager) RevokeToken(ctx context.Context, tokenID string, expiresAt time.Time) error {
+	lease, err := grantUntil(ctx, sm.client, expiresAt)
+	if err != nil {
+		return err
+	}
+	if _, err := sm.client.Put(ctx, RevokedTokenKeyPrefix+tokenID, expiresAt.Format(time.RFC3339), clientv3.WithLease(lease)); err != nil {
+		return fmt.Errorf("failed to revoke token: %v", err)
+	}
+	return nil
+}
+
+// IsTokenRevoked checks if an access token ID is on the denylist
+func (sm *SessionManager) IsTokenRevoked(ctx context.Context, tokenID string) (bool, error) {
+	resp, err := sm.client.Get(ctx, RevokedTokenKeyPrefix+tokenID, clientv3.WithCountOnly())
+	if err != nil {
+		return false, fmt.Errorf("failed to check revoked token: %v", err)
+	}
+	return resp.Count > 0, nil
+}
+
+// grantUntil grants a lease that expires at the given time, at least one second from now
+func grantUntil(ctx context.Context, client *clientv3.Client, expiresAt time.Time) (clientv3.LeaseID, error) {
+	ttl := int64(time.Until(expiresAt).Seconds()) + 1
+	if ttl < 1 {
+		ttl = 1
+	}
+	lease, err := client.Grant(ctx, ttl)
+	if err != nil {
+		return 0, fmt.Errorf("failed to grant lease: %v", err)
+	}
+	return lease.ID, nil
+}
@@ -19,7 +19,9 @@ package etcd
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	clientv3 "go.etcd.io/etcd/client/v3"
@@ -32,16 +34,57 @@ const (
 	UserKeyPrefix = "/karmada/dashboard/users/"
 	// DefaultBcryptCost is the default cost for bcrypt
 	DefaultBcryptCost = 10
+	// MaxPasswordHistory is the number of previous password hashes kept per user
+	MaxPasswordHistory = 24
 )
 
+// ErrInvalidUsername is returned for usernames that could be mistaken for other subjects
+var ErrInvalidUsername = errors.New("username must not be empty or contain ':' or '#'")
+
+// ErrUserLocked is returned by ReserveLoginAttempt while the user is locked out
+var ErrUserLocked = errors.New("user is locked out")
+
+// ErrUserModified is returned by ModifyUser when the user changed while it was being modified
+var ErrUserModified = errors.New("user was modified concurrently")
+
 // User represents a user in the system
 type User struct {
 	Username     string    `json:"username"`
 	PasswordHash string    `json:"passwordHash"`
 	Email        string    `json:"email,omitempty"`
 	Role         string    `json:"role,omitempty"`
+	Provider     string    `json:"provider,omitempty"` // external identity provider, empty for password users
 	CreatedAt    time.Time `json:"createdAt"`
 	UpdatedAt    time.Time `json:"updatedAt"`
+	// PasswordHistory holds the hashes of previous passwords, the most recent first
+	PasswordHistory   []string   `json:"passwordHistory,omitempty"`
+	PasswordChangedAt time.Time  `json:"passwordChangedAt,omitempty"`
+	// MustChangePassword is set for bootstrapped and reset accounts until the user picks a password
+	MustChangePassword bool       `json:"mustChangePassword,omitempty"`
+	FailedLogins       int        `json:"failedLogins,omitempty"`
+	LockedUntil        *time.Time `json:"lockedUntil,omitempty"`
+	// TOTPSecret is the base32 encoded TOTP secret, set from enrollment on
+	TOTPSecret string `json:"totpSecret,omitempty"`
+	// TOTPEnabled is set once enrollment was confirmed with a valid code
+	TOTPEnabled bool `json:"totpEnabled,omitempty"`
+	// TOTPLastStep is the time step of the last accepted code, codes cannot be replayed
+	TOTPLastStep int64 `json:"totpLastStep,omitempty"`
+	// RecoveryCodeHashes holds the SHA-256 hashes of the unused recovery codes
+	RecoveryCodeHashes []string `json:"recoveryCodeHashes,omitempty"`
+}
+
+// IsLocked reports whether the user is locked out at the given time
+func (u *User) IsLocked(now time.Time) bool {
+	return u.LockedUntil != nil && now.Before(*u.LockedUntil)
+}
+
+// ValidateUsername checks that a username can be used as a subject in authorization checks.
+// ':' and '#' separate types and relations there.
+func ValidateUsername(username string) error {
+	if username == "" || strings.ContainsAny(username, ":#") {
+		return ErrInvalidUsername
+	}
+	return nil
 }
 
 // UserManager handles user operations
@@ -58,6 +101,9 @@ func NewUserManager(client *clientv3.Client) *UserManager {
 
 // CreateUser creates a new user with a bcrypt hashed password
 func (um *UserManager) CreateUser(ctx context.Context, username, password, email, role string) error {
+	if err := ValidateUsername(username); err != nil {
+		return err
+	}
 	// Check if user already exists
 	if exists, err := um.UserExists(ctx, username); err != nil {
 		return err
@@ -80,6 +126,33 @@ func (um *UserManager) CreateUser(ctx context.Context, username, password, email
 		Role:         role,
 		CreatedAt:    now,
 		UpdatedAt:    now,
+		// Creation sets the first password
+		PasswordChangedAt: now,
+	}
+
+	return um.saveUser(ctx, user)
+}
+
+// CreateExternalUser creates a user that signs in through an external identity provider.
+// The user has no password, so password login is impossible for it.
+func (um *UserManager) CreateExternalUser(ctx context.Context, username, email, role, provider string) error {
+	if err := ValidateUsername(username); err != nil {
+		return err
+	}
+	if exists, err := um.UserExists(ctx, username); err != nil {
+		return err
+	} else if exists {
+		return fmt.Errorf("user %s already exists", username)
+	}
+
+	now := time.Now()
+	user := &User{
+		Username:  username,
+		Email:     email,
+		Role:      role,
+		Provider:  provider,
+		CreatedAt: now,
+		UpdatedAt: now,
 	}
 
 	return um.saveUser(ctx, user)
@@ -135,22 +208,193 @@ func (um *UserManager) UpdateUser(ctx context.Context, user *User) error {
 	return um.saveUser(ctx, user)
 }
 
-// UpdatePassword updates a user's password
+// UpdatePassword updates a user's password, keeps the previous one in the password history
+// and clears a pending forced password change
 func (um *UserManager) UpdatePassword(ctx context.Context, username, password string) error {
+	// Hash the password
+	passwordHash, err := hashPassword(password)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %v", err)
+	}
+
+	// The failed login counter may change meanwhile, it must not be overwritten
+	return um.modifyUserUntilSaved(ctx, username, func(user *User) error {
+		if user.PasswordHash != "" {
+			user.PasswordHistory = append([]string{user.PasswordHash}, user.PasswordHistory...)
+			if len(user.PasswordHistory) > MaxPasswordHistory {
+				user.PasswordHistory = user.PasswordHistory[:MaxPasswordHistory]
+			}
+		}
+		user.PasswordHash = passwordHash
+		user.PasswordChangedAt = time.Now()
+		user.MustChangePassword = false
+		return nil
+	})
+}
+
+// IsPasswordReused checks whether password matches the current password or one of the
+// depth-1 passwords before it
+func (um *UserManager) IsPasswordReused(ctx context.Context, username, password string, depth int) (bool, error) {
+	if depth <= 0 {
+		return false, nil
+	}
 	user, err := um.GetUser(ctx, username)
 	if err != nil {
-		return err
+		return false, err
 	}
 
-	// Hash the password
-	passwordHash, err := hashPassword(password)
+	hashes := append([]string{user.PasswordHash}, user.PasswordHistory...)
+	if len(hashes) > depth {
+		hashes = hashes[:depth]
+	}
+	for _, hash := range hashes {
+		if hash != "" && bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// RequirePasswordChange makes the user pick a new password on the next login
+func (um *UserManager) RequirePasswordChange(ctx context.Context, username string) error {
+	return um.modifyUserUntilSaved(ctx, username, func(user *User) error {
+		user.MustChangePassword = true
+		return nil
+	})
+}
+
+// RecordFailedLogin counts a failed login and locks the user for lockout once maxAttempts
+// consecutive logins failed. A maxAttempts of 0 disables the lockout. Concurrent failed logins
+// are all counted, so that the lockout cannot be bypassed by guessing in parallel.
+func (um *UserManager) RecordFailedLogin(ctx context.Context, username string, maxAttempts int, lockout time.Duration) (*User, error) {
+	var result *User
+	locked := false
+	err := um.modifyUserUntilSaved(ctx, username, func(user *User) error {
+		locked = countFailedLogin(user, time.Now(), maxAttempts, lockout)
+		result = user
+		return nil
+	})
 	if err != nil {
-		return fmt.Errorf("failed to hash password: %v", err)
+		return nil, err
+	}
+	if locked {
+		klog.InfoS("User locked out after failed logins", "username", username, "failedLogins", result.FailedLogins, "lockedUntil", *result.LockedUntil)
+	}
+	return result, nil
+}
+
+// ReserveLoginAttempt counts a login attempt as failed before its password is verified and
+// returns the user as of the reservation. Locked users get ErrUserLocked and are not counted.
+// As every attempt is counted up front, no more than maxAttempts passwords are verified per
+// lockout, however many attempts run in parallel. ResetFailedLogins undoes the count once a
+// password turned out right.
+func (um *UserManager) ReserveLoginAttempt(ctx context.Context, username string, maxAttempts int, lockout time.Duration) (*User, error) {
+	var result *User
+	locked := false
+	err := um.modifyUserUntilSaved(ctx, username, func(user *User) error {
+		now := time.Now()
+		if user.IsLocked(now) {
+			return ErrUserLocked
+		}
+		locked = countFailedLogin(user, now, maxAttempts, lockout)
+		result = user
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if locked {
+		klog.InfoS("User locked out after failed logins", "username", username, "failedLogins", result.FailedLogins, "lockedUntil", *result.LockedUntil)
+	}
+	return result, nil
+}
+
+// countFailedLogin counts a failed login of user and locks it for lockout once maxAttempts
+// consecutive logins failed. It reports whether the user got locked.
+func countFailedLogin(user *User, now time.Time, maxAttempts int, lockout time.Duration) bool {
+	// A lockout that ran out starts a new count
+	if user.LockedUntil != nil && !user.IsLocked(now) {
+		user.LockedUntil = nil
+		user.FailedLogins = 0
+	}
+	user.FailedLogins++
+	if maxAttempts <= 0 || user.FailedLogins < maxAttempts {
+		return false
+	}
+	lockedUntil := now.Add(lockout)
+	user.LockedUntil = &lockedUntil
+	return true
+}
+
+// errUnchanged makes ModifyUser leave a user that needs no change as it is
+var errUnchanged = errors.New("user unchanged")
+
+// ResetFailedLogins clears the failed login counter and any lockout of the user
+func (um *UserManager) ResetFailedLogins(ctx context.Context, username string) error {
+	err := um.modifyUserUntilSaved(ctx, username, func(user *User) error {
+		if user.FailedLogins == 0 && user.LockedUntil == nil {
+			return errUnchanged
+		}
+		user.FailedLogins = 0
+		user.LockedUntil = nil
+		return nil
+	})
+	if errors.Is(err, errUnchanged) {
+		return nil
+	}
+	return err
+}
+
+// modifyUserUntilSaved runs ModifyUser again on the new version of the user for as long as the
+// user keeps changing concurrently and ctx is not done.
+func (um *UserManager) modifyUserUntilSaved(ctx context.Context, username string, modify func(user *User) error) error {
+	for {
+		err := um.ModifyUser(ctx, username, modify)
+		if !errors.Is(err, ErrUserModified) {
+			return err
+		}
+		if ctx.Err() != nil {
+			return err
+		}
+	}
+}
+
+// ModifyUser applies modify to the stored user and saves the result, unless modify returns an
+// error. Concurrent changes to the user make it fail instead of being overwritten.
+func (um *UserManager) ModifyUser(ctx context.Context, username string, modify func(user *User) error) error {
+	key := UserKeyPrefix + username
+	resp, err := um.client.Get(ctx, key)
+	if err != nil {
+		return fmt.Errorf("failed to get user from etcd: %v", err)
+	}
+	if len(resp.Kvs) == 0 {
+		return fmt.Errorf("user %s not found", username)
 	}
 
-	user.PasswordHash = passwordHash
+	user := &User{}
+	if err := json.Unmarshal(resp.Kvs[0].Value, user); err != nil {
+		return fmt.Errorf("failed to unmarshal user: %v", err)
+	}
+	if err := modify(user); err != nil {
+		return err
+	}
 	user.UpdatedAt = time.Now()
-	return um.saveUser(ctx, user)
+	userData, err := json.Marshal(user)
+	if err != nil {
+		return fmt.Errorf("failed to marshal user: %v", err)
+	}
+
+	txn, err := um.client.Txn(ctx).
+		If(clientv3.Compare(clientv3.ModRevision(key), "=", resp.Kvs[0].ModRevision)).
+		Then(clientv3.OpPut(key, string(userData))).
+		Commit()
+	if err != nil {
+		return fmt.Errorf("failed to save user: %v", err)
+	}
+	if !txn.Succeeded {
+		return fmt.Errorf("failed to save user %s: %w", username, ErrUserModified)
+	}
+	return nil
 }
 
 // DeleteUser deletes a user
@@ -252,6 +496,9 @@ func (um *UserManager) InitializeRootUser(ctx context.Context, password string)
 		if err := um.CreateUser(ctx, "admin", password, "admin@example.com", "admin"); err != nil {
 			return fmt.Errorf("failed to create admin user: %v", err)
 		}
+		if err := um.RequirePasswordChange(ctx, "admin"); err != nil {
+			return fmt.Errorf("failed to require password change for admin user: %v", err)
+		}
 		klog.InfoS("Admin user created successfully")
 	} else {
 		klog.InfoS("Admin user already exists")
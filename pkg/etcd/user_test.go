@@ -0,0 +1,167 @@
+/*
+Copyright 2024 The Karmada Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package etcd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/karmada-io/dashboard/pkg/etcd/etcdtest"
+)
+
+func TestValidateUsername(t *testing.T) {
+	cases := []struct {
+		username string
+		valid    bool
+	}{
+		{"alice", true},
+		{"alice@example.com", true},
+		{"", false},
+		{"user:admin", false},
+		{"group:sre#member", false},
+		{"alice#1", false},
+	}
+
+	for _, c := range cases {
+		if err := ValidateUsername(c.username); (err == nil) != c.valid {
+			t.Errorf("ValidateUsername(%q) == %v, expected valid %v", c.username, err, c.valid)
+		}
+	}
+}
+
+func TestRecordFailedLoginConcurrently(t *testing.T) {
+	ctx := context.Background()
+	um := NewUserManager(etcdtest.NewClient())
+	if err := um.CreateUser(ctx, "alice", "password", "alice@example.com", "user"); err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+
+	const attempts = 20
+	var wg sync.WaitGroup
+	for i := 0; i < attempts; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := um.RecordFailedLogin(ctx, "alice", 5, time.Hour); err != nil {
+				t.Errorf("RecordFailedLogin: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	user, err := um.GetUser(ctx, "alice")
+	if err != nil {
+		t.Fatalf("GetUser: %v", err)
+	}
+	if user.FailedLogins != attempts {
+		t.Errorf("got %d failed logins, expected every one of the %d concurrent attempts to be counted", user.FailedLogins, attempts)
+	}
+	if !user.IsLocked(time.Now()) {
+		t.Errorf("user is not locked after %d failed logins", attempts)
+	}
+
+	if err := um.ResetFailedLogins(ctx, "alice"); err != nil {
+		t.Fatalf("ResetFailedLogins: %v", err)
+	}
+	if user, err = um.GetUser(ctx, "alice"); err != nil || user.FailedLogins != 0 || user.LockedUntil != nil {
+		t.Errorf("ResetFailedLogins: got (%+v, %v)", user, err)
+	}
+}
+
+func TestReserveLoginAttemptConcurrently(t *testing.T) {
+	ctx := context.Background()
+	um := NewUserManager(etcdtest.NewClient())
+	if err := um.CreateUser(ctx, "alice", "password", "alice@example.com", "user"); err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+
+	const maxAttempts = 5
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	reserved := 0
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := um.ReserveLoginAttempt(ctx, "alice", maxAttempts, time.Hour)
+			switch {
+			case err == nil:
+				mu.Lock()
+				reserved++
+				mu.Unlock()
+			case !errors.Is(err, ErrUserLocked):
+				t.Errorf("ReserveLoginAttempt: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if reserved != maxAttempts {
+		t.Errorf("got %d reserved attempts, expected %d before the lockout", reserved, maxAttempts)
+	}
+	if user, err := um.GetUser(ctx, "alice"); err != nil || !user.IsLocked(time.Now()) {
+		t.Errorf("user is not locked after %d attempts: (%+v, %v)", maxAttempts, user, err)
+	}
+}
+
+func TestPasswordChangesKeepFailedLogins(t *testing.T) {
+	ctx := context.Background()
+	um := NewUserManager(etcdtest.NewClient())
+	if err := um.CreateUser(ctx, "alice", "password", "alice@example.com", "user"); err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+
+	const attempts = 10
+	var wg sync.WaitGroup
+	for i := 0; i < attempts; i++ {
+		wg.Add(3)
+		go func() {
+			defer wg.Done()
+			if _, err := um.RecordFailedLogin(ctx, "alice", 0, time.Hour); err != nil {
+				t.Errorf("RecordFailedLogin: %v", err)
+			}
+		}()
+		go func(i int) {
+			defer wg.Done()
+			if err := um.UpdatePassword(ctx, "alice", fmt.Sprintf("password-%d", i)); err != nil {
+				t.Errorf("UpdatePassword: %v", err)
+			}
+		}(i)
+		go func() {
+			defer wg.Done()
+			if err := um.RequirePasswordChange(ctx, "alice"); err != nil {
+				t.Errorf("RequirePasswordChange: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	user, err := um.GetUser(ctx, "alice")
+	if err != nil {
+		t.Fatalf("GetUser: %v", err)
+	}
+	if user.FailedLogins != attempts {
+		t.Errorf("got %d failed logins, expected the %d concurrent ones to survive the password changes", user.FailedLogins, attempts)
+	}
+	if len(user.PasswordHistory) != attempts {
+		t.Errorf("got %d previous passwords, expected %d", len(user.PasswordHistory), attempts)
+	}
+}
@@ -0,0 +1,157 @@
+/*
+Copyright 2024 The Karmada Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package recording
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// Event codes of asciicast v2
+const (
+	EventOutput = "o"
+	EventInput  = "i"
+	EventResize = "r"
+)
+
+// Header is the first line of an asciicast v2 recording, see
+// https://docs.asciinema.org/manual/asciicast/v2/
+type Header struct {
+	Version   int               `json:"version"`
+	Width     uint16            `json:"width"`
+	Height    uint16            `json:"height"`
+	Timestamp int64             `json:"timestamp"`
+	Title     string            `json:"title,omitempty"`
+	Env       map[string]string `json:"env,omitempty"`
+}
+
+// Writer writes a terminal session as asciicast v2: a header line followed by one
+// [seconds, code, data] line per event. It is safe for concurrent use, since input and output
+// are copied by different goroutines.
+type Writer struct {
+	mu    sync.Mutex
+	w     *bufio.Writer
+	start time.Time
+	now   func() time.Time
+	// pending holds the bytes of a UTF-8 sequence split across writes, per event code, since
+	// JSON strings cannot hold partial sequences
+	pending map[string][]byte
+	err     error
+}
+
+// NewWriter writes the header and returns a writer for the events. now is the clock, time.Now
+// if nil.
+func NewWriter(w io.Writer, header Header, now func() time.Time) (*Writer, error) {
+	if now == nil {
+		now = time.Now
+	}
+	start := now()
+	header.Version = 2
+	if header.Timestamp == 0 {
+		header.Timestamp = start.Unix()
+	}
+	encoded, err := json.Marshal(header)
+	if err != nil {
+		return nil, err
+	}
+	writer := &Writer{w: bufio.NewWriter(w), start: start, now: now, pending: map[string][]byte{}}
+	if _, err := writer.w.Write(append(encoded, '\n')); err != nil {
+		return nil, err
+	}
+	return writer, nil
+}
+
+// Output records data shown by the terminal.
+func (w *Writer) Output(data []byte) error {
+	return w.text(EventOutput, data)
+}
+
+// Input records data typed into the terminal.
+func (w *Writer) Input(data []byte) error {
+	return w.text(EventInput, data)
+}
+
+// Resize records a change of the terminal size.
+func (w *Writer) Resize(width, height uint16) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.event(EventResize, fmt.Sprintf("%dx%d", width, height))
+}
+
+// Flush writes buffered events.
+func (w *Writer) Flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for code, pending := range w.pending {
+		if len(pending) > 0 {
+			delete(w.pending, code)
+			if err := w.event(code, string(pending)); err != nil {
+				return err
+			}
+		}
+	}
+	if w.err != nil {
+		return w.err
+	}
+	w.err = w.w.Flush()
+	return w.err
+}
+
+// text records an output or input event, holding back a trailing partial UTF-8 sequence until
+// the next event of the same code.
+func (w *Writer) text(code string, data []byte) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	data = append(w.pending[code], data...)
+	cut := len(data)
+	// A sequence is at most 4 bytes, look for its start among the last ones
+	for i := len(data) - 1; i >= 0 && i >= len(data)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(data[i]) {
+			if !utf8.FullRune(data[i:]) {
+				cut = i
+			}
+			break
+		}
+	}
+	w.pending[code] = append([]byte(nil), data[cut:]...)
+	if cut == 0 {
+		return w.err
+	}
+	return w.event(code, string(data[:cut]))
+}
+
+// event writes an event line, the caller holds the lock.
+func (w *Writer) event(code, data string) error {
+	if w.err != nil {
+		return w.err
+	}
+	// Microsecond precision, as asciinema writes
+	elapsed := math.Round(w.now().Sub(w.start).Seconds()*1e6) / 1e6
+	encoded, err := json.Marshal([]interface{}{elapsed, code, data})
+	if err != nil {
+		w.err = err
+		return err
+	}
+	_, w.err = w.w.Write(append(encoded, '\n'))
+	return w.err
+}
@@ -0,0 +1,332 @@
+/*
+Copyright 2024 The Karmada Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package recording
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+const (
+	castSuffix     = ".cast"
+	metadataSuffix = ".json"
+
+	// MaxListedRecordings bounds how many recordings a query returns, the most recent ones are kept.
+	MaxListedRecordings = 1000
+
+	defaultWidth  = 80
+	defaultHeight = 24
+	uploadTimeout = 5 * time.Minute
+)
+
+var (
+	store  Store
+	initMu sync.Mutex
+)
+
+// Init starts storing recordings in s. Without Init, sessions cannot be recorded.
+func Init(s Store) {
+	initMu.Lock()
+	defer initMu.Unlock()
+	store = s
+}
+
+// Enabled reports whether sessions can be recorded.
+func Enabled() bool {
+	return currentStore() != nil
+}
+
+func currentStore() Store {
+	initMu.Lock()
+	defer initMu.Unlock()
+	return store
+}
+
+// Metadata describes a recorded terminal session
+type Metadata struct {
+	ID        string `json:"id"`
+	User      string `json:"user"`
+	Cluster   string `json:"cluster"`
+	Namespace string `json:"namespace,omitempty"`
+	Pod       string `json:"pod,omitempty"`
+	Container string `json:"container,omitempty"`
+	// Node is set for node shells, whose pod is created for the session
+	Node    string   `json:"node,omitempty"`
+	Command []string `json:"command,omitempty"`
+	// Forced is set when a policy required the recording rather than the user asking for it
+	Forced    bool      `json:"forced"`
+	StartedAt time.Time `json:"startedAt"`
+	EndedAt   time.Time `json:"endedAt"`
+	// Duration is the length of the session in seconds
+	Duration float64 `json:"duration"`
+	Size     int64   `json:"size"`
+}
+
+// Query selects recordings, all fields are optional.
+type Query struct {
+	User    string
+	Cluster string
+	Since   time.Time
+	Until   time.Time
+}
+
+func (q Query) matches(metadata *Metadata) bool {
+	if q.User != "" && metadata.User != q.User {
+		return false
+	}
+	if q.Cluster != "" && metadata.Cluster != q.Cluster {
+		return false
+	}
+	if !q.Since.IsZero() && metadata.StartedAt.Before(q.Since) {
+		return false
+	}
+	if !q.Until.IsZero() && !metadata.StartedAt.Before(q.Until) {
+		return false
+	}
+	return true
+}
+
+// Policy requires recording the sessions of the listed users on the listed clusters. An empty
+// list matches everything, so a policy without users and clusters records every session.
+type Policy struct {
+	Name     string   `json:"name"`
+	Users    []string `json:"users,omitempty"`
+	Clusters []string `json:"clusters,omitempty"`
+}
+
+// Matches reports whether the policy applies to a session of user on cluster.
+func (p Policy) Matches(user, cluster string) bool {
+	return (len(p.Users) == 0 || slices.Contains(p.Users, user)) &&
+		(len(p.Clusters) == 0 || slices.Contains(p.Clusters, cluster))
+}
+
+// Required returns the first policy requiring to record a session of user on cluster, nil if none.
+func Required(policies []Policy, user, cluster string) *Policy {
+	for i := range policies {
+		if policies[i].Matches(user, cluster) {
+			return &policies[i]
+		}
+	}
+	return nil
+}
+
+// Recording is a terminal session being recorded. Events are written to a temporary file, which
+// is uploaded to the store when the session ends.
+type Recording struct {
+	metadata Metadata
+	file     *os.File
+	writer   *Writer
+	store    Store
+	now      func() time.Time
+	closed   sync.Once
+	failed   sync.Once
+}
+
+// Start starts recording a session. The ID and start time of metadata are filled in.
+func Start(metadata Metadata) (*Recording, error) {
+	s := currentStore()
+	if s == nil {
+		return nil, fmt.Errorf("terminal recording is disabled")
+	}
+	return start(s, metadata, time.Now)
+}
+
+func start(s Store, metadata Metadata, now func() time.Time) (*Recording, error) {
+	file, err := os.CreateTemp("", "terminal-recording-*"+castSuffix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create recording: %w", err)
+	}
+	metadata.StartedAt = now()
+	metadata.ID = newRecordingID(metadata.StartedAt)
+
+	title := metadata.User + "@" + metadata.Cluster
+	if metadata.Node != "" {
+		title += " node/" + metadata.Node
+	} else {
+		title += " " + metadata.Namespace + "/" + metadata.Pod
+	}
+	writer, err := NewWriter(file, Header{
+		Width:     defaultWidth,
+		Height:    defaultHeight,
+		Timestamp: metadata.StartedAt.Unix(),
+		Title:     title,
+		Env:       map[string]string{"TERM": "xterm-256color", "SHELL": strings.Join(metadata.Command, " ")},
+	}, now)
+	if err != nil {
+		_ = file.Close()
+		_ = os.Remove(file.Name())
+		return nil, fmt.Errorf("failed to start recording: %w", err)
+	}
+	return &Recording{metadata: metadata, file: file, writer: writer, store: s, now: now}, nil
+}
+
+// ID returns the ID of the recording.
+func (r *Recording) ID() string {
+	return r.metadata.ID
+}
+
+// Input records typed data.
+func (r *Recording) Input(data []byte) {
+	r.record(r.writer.Input(data))
+}
+
+// Output records shown data.
+func (r *Recording) Output(data []byte) {
+	r.record(r.writer.Output(data))
+}
+
+// Resize records a change of the terminal size.
+func (r *Recording) Resize(width, height uint16) {
+	r.record(r.writer.Resize(width, height))
+}
+
+// record logs the first failure to write an event, the session goes on regardless.
+func (r *Recording) record(err error) {
+	if err != nil {
+		r.failed.Do(func() {
+			klog.ErrorS(err, "Failed to write terminal recording", "id", r.metadata.ID)
+		})
+	}
+}
+
+// Close ends the recording and uploads it with its metadata. The temporary file is kept if the
+// upload fails, so that the recording is not lost. It is safe to call more than once.
+func (r *Recording) Close() error {
+	var err error
+	r.closed.Do(func() {
+		if err = r.upload(); err != nil {
+			klog.ErrorS(err, "Failed to store terminal recording, keeping it locally", "id", r.metadata.ID, "path", r.file.Name())
+			return
+		}
+		_ = os.Remove(r.file.Name())
+	})
+	return err
+}
+
+func (r *Recording) upload() error {
+	flushErr := r.writer.Flush()
+	closeErr := r.file.Close()
+	if err := errors.Join(flushErr, closeErr); err != nil {
+		return fmt.Errorf("failed to write recording %s: %w", r.metadata.ID, err)
+	}
+
+	info, err := os.Stat(r.file.Name())
+	if err != nil {
+		return err
+	}
+	r.metadata.EndedAt = r.now()
+	r.metadata.Duration = r.metadata.EndedAt.Sub(r.metadata.StartedAt).Seconds()
+	r.metadata.Size = info.Size()
+
+	ctx, cancel := context.WithTimeout(context.Background(), uploadTimeout)
+	defer cancel()
+	cast, err := os.Open(r.file.Name())
+	if err != nil {
+		return err
+	}
+	defer cast.Close()
+	if err := r.store.Put(ctx, r.metadata.ID+castSuffix, cast, info.Size()); err != nil {
+		return fmt.Errorf("failed to store recording %s: %w", r.metadata.ID, err)
+	}
+	// The metadata is written last, so that listed recordings can always be played
+	encoded, err := json.Marshal(r.metadata)
+	if err != nil {
+		return err
+	}
+	if err := r.store.Put(ctx, r.metadata.ID+metadataSuffix, bytes.NewReader(encoded), int64(len(encoded))); err != nil {
+		return fmt.Errorf("failed to store metadata of recording %s: %w", r.metadata.ID, err)
+	}
+	return nil
+}
+
+// newRecordingID returns an ID that sorts by start time.
+func newRecordingID(startedAt time.Time) string {
+	b := make([]byte, 6)
+	if _, err := rand.Read(b); err != nil {
+		return startedAt.UTC().Format("20060102T150405.000000000Z")
+	}
+	return startedAt.UTC().Format("20060102T150405Z") + "-" + hex.EncodeToString(b)
+}
+
+// List returns the recordings matching query, most recent first and at most MaxListedRecordings.
+func List(ctx context.Context, query Query) ([]Metadata, error) {
+	s := currentStore()
+	if s == nil {
+		return nil, fmt.Errorf("terminal recording is disabled")
+	}
+	names, err := s.List(ctx, metadataSuffix)
+	if err != nil {
+		return nil, err
+	}
+
+	recordings := []Metadata{}
+	for i := len(names) - 1; i >= 0 && len(recordings) < MaxListedRecordings; i-- {
+		metadata, err := readMetadata(ctx, s, names[i])
+		if err != nil {
+			klog.ErrorS(err, "Failed to read recording metadata", "name", names[i])
+			continue
+		}
+		if query.matches(metadata) {
+			recordings = append(recordings, *metadata)
+		}
+	}
+	return recordings, nil
+}
+
+// Get returns the metadata of a recording.
+func Get(ctx context.Context, id string) (*Metadata, error) {
+	s := currentStore()
+	if s == nil {
+		return nil, fmt.Errorf("terminal recording is disabled")
+	}
+	return readMetadata(ctx, s, id+metadataSuffix)
+}
+
+// Open returns the asciicast file of a recording.
+func Open(ctx context.Context, id string) (io.ReadCloser, error) {
+	s := currentStore()
+	if s == nil {
+		return nil, fmt.Errorf("terminal recording is disabled")
+	}
+	return s.Get(ctx, id+castSuffix)
+}
+
+func readMetadata(ctx context.Context, s Store, name string) (*Metadata, error) {
+	body, err := s.Get(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	metadata := &Metadata{}
+	if err := json.NewDecoder(io.LimitReader(body, 1<<20)).Decode(metadata); err != nil {
+		return nil, fmt.Errorf("invalid recording metadata %s: %v", name, err)
+	}
+	return metadata, nil
+}
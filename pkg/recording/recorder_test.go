@@ -0,0 +1,176 @@
+/*
+Copyright 2024 The Karmada Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package recording
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeClock advances by a second on every reading.
+func fakeClock() func() time.Time {
+	t := time.Date(2024, 5, 15, 10, 0, 0, 0, time.UTC)
+	return func() time.Time {
+		current := t
+		t = t.Add(time.Second)
+		return current
+	}
+}
+
+func TestWriter(t *testing.T) {
+	var out bytes.Buffer
+	writer, err := NewWriter(&out, Header{Width: 80, Height: 24, Title: "alice@member1"}, fakeClock())
+	if err != nil {
+		t.Fatalf("NewWriter() failed: %v", err)
+	}
+	euro := []byte("€")
+	steps := []func() error{
+		func() error { return writer.Input([]byte("ls\r")) },
+		func() error { return writer.Output([]byte("a.txt\r\n")) },
+		func() error { return writer.Resize(120, 40) },
+		// A character split across writes is written once complete
+		func() error { return writer.Output(euro[:1]) },
+		func() error { return writer.Output(append(euro[1:], '!')) },
+		func() error { return writer.Output([]byte("bye")) },
+		writer.Flush,
+	}
+	for i, step := range steps {
+		if err := step(); err != nil {
+			t.Fatalf("step %d failed: %v", i, err)
+		}
+	}
+
+	expected := `{"version":2,"width":80,"height":24,"timestamp":1715767200,"title":"alice@member1"}
+[1,"i","ls\r"]
+[2,"o","a.txt\r\n"]
+[3,"r","120x40"]
+[4,"o","€!"]
+[5,"o","bye"]
+`
+	if out.String() != expected {
+		t.Errorf("recording ==\n%s\nexpected\n%s", out.String(), expected)
+	}
+}
+
+func TestPolicyRequired(t *testing.T) {
+	policies := []Policy{
+		{Name: "contractors", Users: []string{"bob", "carol"}},
+		{Name: "production", Clusters: []string{"prod"}},
+		{Name: "admin-on-staging", Users: []string{"admin"}, Clusters: []string{"staging"}},
+	}
+
+	cases := []struct {
+		user, cluster string
+		policy        string
+	}{
+		{"bob", "dev", "contractors"},
+		{"alice", "prod", "production"},
+		{"carol", "prod", "contractors"},
+		{"admin", "staging", "admin-on-staging"},
+		{"admin", "dev", ""},
+		{"alice", "staging", ""},
+	}
+	for _, c := range cases {
+		policy := ""
+		if p := Required(policies, c.user, c.cluster); p != nil {
+			policy = p.Name
+		}
+		if policy != c.policy {
+			t.Errorf("Required(%s, %s) == %q, expected %q", c.user, c.cluster, policy, c.policy)
+		}
+	}
+
+	if Required([]Policy{{Name: "all"}}, "anyone", "anywhere") == nil {
+		t.Errorf("a policy without users and clusters does not apply to every session")
+	}
+}
+
+// testRecordingStore records two sessions in store and checks that they are listed and played.
+func testRecordingStore(t *testing.T, store Store) {
+	Init(store)
+	defer Init(nil)
+
+	sessions := []Metadata{
+		{User: "alice", Cluster: "member1", Namespace: "default", Pod: "web-0", Command: []string{"/bin/sh"}},
+		{User: "bob", Cluster: "member2", Node: "node-1", Pod: "node-shell-node-1-abc", Forced: true},
+	}
+	var ids []string
+	for _, metadata := range sessions {
+		rec, err := start(store, metadata, fakeClock())
+		if err != nil {
+			t.Fatalf("start() failed: %v", err)
+		}
+		rec.Output([]byte("$ "))
+		rec.Input([]byte("exit\r"))
+		if err := rec.Close(); err != nil {
+			t.Fatalf("Close() failed: %v", err)
+		}
+		ids = append(ids, rec.ID())
+	}
+
+	ctx := context.Background()
+	listed, err := List(ctx, Query{})
+	if err != nil {
+		t.Fatalf("List() failed: %v", err)
+	}
+	if len(listed) != 2 {
+		t.Fatalf("List() returned %d recordings, expected 2", len(listed))
+	}
+	byUser, err := List(ctx, Query{User: "bob"})
+	if err != nil || len(byUser) != 1 || byUser[0].Node != "node-1" || !byUser[0].Forced {
+		t.Errorf("List(user=bob) == %+v, %v, expected the node shell of bob", byUser, err)
+	}
+
+	metadata, err := Get(ctx, ids[0])
+	if err != nil {
+		t.Fatalf("Get() failed: %v", err)
+	}
+	if metadata.User != "alice" || metadata.Duration != 4 || metadata.Size == 0 {
+		t.Errorf("Get() == %+v, expected a 4 second recording of alice", metadata)
+	}
+
+	body, err := Open(ctx, ids[0])
+	if err != nil {
+		t.Fatalf("Open() failed: %v", err)
+	}
+	cast, _ := io.ReadAll(body)
+	body.Close()
+	lines := strings.Split(strings.TrimSpace(string(cast)), "\n")
+	if len(lines) != 3 || !strings.Contains(lines[0], `"title":"alice@member1 default/web-0"`) || lines[2] != `[2,"i","exit\r"]` {
+		t.Errorf("recording ==\n%s", cast)
+	}
+
+	if _, err := Open(ctx, "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Open(missing) returned %v, expected ErrNotFound", err)
+	}
+	if _, err := Open(ctx, "../etc/passwd"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Open(../etc/passwd) returned %v, expected ErrNotFound", err)
+	}
+}
+
+func TestDirectoryStore(t *testing.T) {
+	store, err := NewDirectoryStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewDirectoryStore() failed: %v", err)
+	}
+	testRecordingStore(t, store)
+}
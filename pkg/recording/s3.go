@@ -0,0 +1,278 @@
+/*
+Copyright 2024 The Karmada Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package recording
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	s3Service        = "s3"
+	s3Algorithm      = "AWS4-HMAC-SHA256"
+	unsignedPayload  = "UNSIGNED-PAYLOAD"
+	s3RequestTimeout = 5 * time.Minute
+)
+
+// S3Config configures a store in an S3 compatible object store
+type S3Config struct {
+	// Endpoint is the URL of the object store, e.g. https://s3.eu-west-1.amazonaws.com or
+	// http://minio.minio.svc:9000. Buckets are addressed in the path, which all S3 compatible
+	// stores support.
+	Endpoint        string
+	Bucket          string
+	Region          string
+	Prefix          string
+	AccessKeyID     string
+	SecretAccessKey string
+}
+
+// S3Store keeps recordings in a bucket of an S3 compatible object store. Requests are signed with
+// AWS signature version 4.
+type S3Store struct {
+	config   S3Config
+	endpoint *url.URL
+	client   *http.Client
+	now      func() time.Time
+}
+
+// NewS3Store creates a store in the bucket of config.
+func NewS3Store(config S3Config) (*S3Store, error) {
+	endpoint, err := url.Parse(config.Endpoint)
+	if err != nil || endpoint.Host == "" || (endpoint.Scheme != "http" && endpoint.Scheme != "https") {
+		return nil, fmt.Errorf("invalid S3 endpoint %q", config.Endpoint)
+	}
+	if config.Bucket == "" {
+		return nil, fmt.Errorf("an S3 bucket is required")
+	}
+	if config.Region == "" {
+		config.Region = "us-east-1"
+	}
+	return &S3Store{
+		config:   config,
+		endpoint: endpoint,
+		client:   &http.Client{Timeout: s3RequestTimeout},
+		now:      time.Now,
+	}, nil
+}
+
+// Put uploads an object.
+func (s *S3Store) Put(ctx context.Context, name string, body io.Reader, size int64) error {
+	if !validName(name) {
+		return fmt.Errorf("invalid recording name %q", name)
+	}
+	req, err := s.request(ctx, http.MethodPut, s.config.Prefix+name, nil, body)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	resp, err := s.do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// Get downloads an object.
+func (s *S3Store) Get(ctx context.Context, name string) (io.ReadCloser, error) {
+	if !validName(name) {
+		return nil, ErrNotFound
+	}
+	req, err := s.request(ctx, http.MethodGet, s.config.Prefix+name, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.do(req)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// listResult is the part of a ListObjectsV2 response the store reads
+type listResult struct {
+	Contents []struct {
+		Key string `xml:"Key"`
+	} `xml:"Contents"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+// List returns the names of the objects under the prefix ending with suffix.
+func (s *S3Store) List(ctx context.Context, suffix string) ([]string, error) {
+	var names []string
+	token := ""
+	for {
+		query := url.Values{"list-type": {"2"}, "prefix": {s.config.Prefix}}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+		req, err := s.request(ctx, http.MethodGet, "", query, nil)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := s.do(req)
+		if err != nil {
+			return nil, err
+		}
+		var result listResult
+		err = xml.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("invalid S3 list response: %v", err)
+		}
+
+		for _, object := range result.Contents {
+			name := strings.TrimPrefix(object.Key, s.config.Prefix)
+			if validName(name) && strings.HasSuffix(name, suffix) {
+				names = append(names, name)
+			}
+		}
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			break
+		}
+		token = result.NextContinuationToken
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// request builds a signed request for a key of the bucket, the bucket itself if key is empty.
+func (s *S3Store) request(ctx context.Context, method, key string, query url.Values, body io.Reader) (*http.Request, error) {
+	segments := []string{s.config.Bucket}
+	if key != "" {
+		segments = append(segments, strings.Split(key, "/")...)
+	}
+	target := *s.endpoint
+	basePath := strings.TrimSuffix(target.Path, "/")
+	target.Path, target.RawPath = basePath, basePath
+	for _, segment := range segments {
+		target.Path += "/" + segment
+		target.RawPath += "/" + uriEncode(segment)
+	}
+	target.RawQuery = canonicalQuery(query)
+
+	req, err := http.NewRequestWithContext(ctx, method, target.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	s.sign(req, target.RawPath, target.RawQuery)
+	return req, nil
+}
+
+// do sends a request and turns error responses into errors.
+func (s *S3Store) do(req *http.Request) (*http.Response, error) {
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("S3 request failed: %v", err)
+	}
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound && req.Method == http.MethodGet && req.URL.RawQuery == "" {
+		return nil, ErrNotFound
+	}
+	message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return nil, fmt.Errorf("S3 %s %s failed with HTTP %d: %s", req.Method, req.URL.Path, resp.StatusCode, strings.TrimSpace(string(message)))
+}
+
+// sign adds the signature version 4 headers to a request. The payload is not signed, so that
+// uploads are streamed without hashing them first.
+func (s *S3Store) sign(req *http.Request, escapedPath, rawQuery string) {
+	now := s.now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	req.Header.Set("x-amz-date", amzDate)
+	req.Header.Set("x-amz-content-sha256", unsignedPayload)
+
+	signedHeaders := "host;x-amz-content-sha256;x-amz-date"
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		escapedPath,
+		rawQuery,
+		"host:" + req.URL.Host + "\n" +
+			"x-amz-content-sha256:" + unsignedPayload + "\n" +
+			"x-amz-date:" + amzDate + "\n",
+		signedHeaders,
+		unsignedPayload,
+	}, "\n")
+
+	scope := date + "/" + s.config.Region + "/" + s3Service + "/aws4_request"
+	hashed := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := s3Algorithm + "\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(hashed[:])
+
+	key := hmacSHA256([]byte("AWS4"+s.config.SecretAccessKey), date)
+	key = hmacSHA256(key, s.config.Region)
+	key = hmacSHA256(key, s3Service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s3Algorithm, s.config.AccessKeyID, scope, signedHeaders, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// canonicalQuery encodes a query as signature version 4 requires: sorted by key, with spaces as
+// %20 rather than +.
+func canonicalQuery(query url.Values) string {
+	if len(query) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var parts []string
+	for _, key := range keys {
+		for _, value := range query[key] {
+			parts = append(parts, uriEncode(key)+"="+uriEncode(value))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// uriEncode percent-encodes everything but the unreserved characters of RFC 3986.
+func uriEncode(value string) string {
+	var encoded strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		if ('A' <= c && c <= 'Z') || ('a' <= c && c <= 'z') || ('0' <= c && c <= '9') || c == '-' || c == '_' || c == '.' || c == '~' {
+			encoded.WriteByte(c)
+		} else {
+			fmt.Fprintf(&encoded, "%%%02X", c)
+		}
+	}
+	return encoded.String()
+}
@@ -0,0 +1,149 @@
+/*
+Copyright 2024 The Karmada Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package recording
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// s3StandIn is an in-memory S3 compatible bucket serving the requests the store makes. It checks
+// that requests are signed for the expected credentials, and pages object listings.
+type s3StandIn struct {
+	bucket      string
+	accessKeyID string
+	pageSize    int
+
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func (s *s3StandIn) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	authorization := r.Header.Get("Authorization")
+	if !strings.HasPrefix(authorization, s3Algorithm+" Credential="+s.accessKeyID+"/") ||
+		!strings.Contains(authorization, "/us-east-1/s3/aws4_request") ||
+		r.Header.Get("x-amz-date") == "" || r.Header.Get("x-amz-content-sha256") != unsignedPayload {
+		http.Error(w, "<Error><Code>AccessDenied</Code></Error>", http.StatusForbidden)
+		return
+	}
+	key, found := strings.CutPrefix(r.URL.Path, "/"+s.bucket)
+	if !found {
+		http.Error(w, "<Error><Code>NoSuchBucket</Code></Error>", http.StatusNotFound)
+		return
+	}
+	key = strings.TrimPrefix(key, "/")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch {
+	case r.Method == http.MethodPut && key != "":
+		if r.ContentLength < 0 {
+			http.Error(w, "<Error><Code>MissingContentLength</Code></Error>", http.StatusLengthRequired)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		s.objects[key] = body
+	case r.Method == http.MethodGet && key != "":
+		body, ok := s.objects[key]
+		if !ok {
+			http.Error(w, "<Error><Code>NoSuchKey</Code></Error>", http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write(body)
+	case r.Method == http.MethodGet && r.URL.Query().Get("list-type") == "2":
+		s.list(w, r)
+	default:
+		http.Error(w, "<Error><Code>NotImplemented</Code></Error>", http.StatusNotImplemented)
+	}
+}
+
+func (s *s3StandIn) list(w http.ResponseWriter, r *http.Request) {
+	var keys []string
+	for key := range s.objects {
+		if strings.HasPrefix(key, r.URL.Query().Get("prefix")) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	start := 0
+	if token := r.URL.Query().Get("continuation-token"); token != "" {
+		fmt.Sscanf(token, "page-%d", &start)
+	}
+	end := min(start+s.pageSize, len(keys))
+	var result listResult
+	for _, key := range keys[start:end] {
+		result.Contents = append(result.Contents, struct {
+			Key string `xml:"Key"`
+		}{Key: key})
+	}
+	if end < len(keys) {
+		result.IsTruncated = true
+		result.NextContinuationToken = fmt.Sprintf("page-%d", end)
+	}
+	_ = xml.NewEncoder(w).Encode(result)
+}
+
+func TestS3Store(t *testing.T) {
+	standIn := &s3StandIn{bucket: "recordings", accessKeyID: "AKIDEXAMPLE", pageSize: 1, objects: map[string][]byte{}}
+	server := httptest.NewServer(standIn)
+	defer server.Close()
+
+	store, err := NewS3Store(S3Config{
+		Endpoint:        server.URL,
+		Bucket:          "recordings",
+		Prefix:          "terminal/",
+		AccessKeyID:     "AKIDEXAMPLE",
+		SecretAccessKey: "secret",
+	})
+	if err != nil {
+		t.Fatalf("NewS3Store() failed: %v", err)
+	}
+	testRecordingStore(t, store)
+
+	for key := range standIn.objects {
+		if !strings.HasPrefix(key, "terminal/") {
+			t.Errorf("object %s is not under the prefix", key)
+		}
+	}
+	if len(standIn.objects) != 4 {
+		t.Errorf("the bucket holds %d objects, expected a recording and its metadata per session", len(standIn.objects))
+	}
+}
+
+func TestCanonicalQuery(t *testing.T) {
+	cases := []struct {
+		query    map[string][]string
+		expected string
+	}{
+		{nil, ""},
+		{map[string][]string{"prefix": {"terminal recordings/"}, "list-type": {"2"}}, "list-type=2&prefix=terminal%20recordings%2F"},
+		{map[string][]string{"continuation-token": {"a+b=~"}}, "continuation-token=a%2Bb%3D~"},
+	}
+	for _, c := range cases {
+		if actual := canonicalQuery(c.query); actual != c.expected {
+			t.Errorf("canonicalQuery(%v) == %q, expected %q", c.query, actual, c.expected)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+/*
+Copyright 2024 The Karmada Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package recording
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ErrNotFound is returned for objects missing from a store.
+var ErrNotFound = errors.New("recording not found")
+
+// Store keeps the files of recordings. Names are flat, without path separators.
+type Store interface {
+	Put(ctx context.Context, name string, body io.Reader, size int64) error
+	// Get returns ErrNotFound if the object does not exist
+	Get(ctx context.Context, name string) (io.ReadCloser, error)
+	// List returns the names ending with suffix, sorted
+	List(ctx context.Context, suffix string) ([]string, error)
+}
+
+// validName reports whether name can be used as an object name.
+func validName(name string) bool {
+	return name != "" && name != "." && name != ".." && !strings.ContainsAny(name, `/\`)
+}
+
+// DirectoryStore keeps recordings in a directory, usually on a persistent volume.
+type DirectoryStore struct {
+	dir string
+}
+
+// NewDirectoryStore creates a store in dir, creating the directory if needed.
+func NewDirectoryStore(dir string) (*DirectoryStore, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create recording directory %s: %w", dir, err)
+	}
+	return &DirectoryStore{dir: dir}, nil
+}
+
+// Put writes an object, replacing it atomically so that readers never see a partial file.
+func (s *DirectoryStore) Put(_ context.Context, name string, body io.Reader, _ int64) error {
+	if !validName(name) {
+		return fmt.Errorf("invalid recording name %q", name)
+	}
+	file, err := os.CreateTemp(s.dir, ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+	if _, err := io.Copy(file, body); err != nil {
+		_ = file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(file.Name(), filepath.Join(s.dir, name))
+}
+
+// Get opens an object.
+func (s *DirectoryStore) Get(_ context.Context, name string) (io.ReadCloser, error) {
+	if !validName(name) {
+		return nil, ErrNotFound
+	}
+	file, err := os.Open(filepath.Join(s.dir, name))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return file, err
+}
+
+// List returns the names of the objects ending with suffix.
+func (s *DirectoryStore) List(_ context.Context, suffix string) ([]string, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, entry := range entries {
+		if entry.Type().IsRegular() && !strings.HasPrefix(entry.Name(), ".") && strings.HasSuffix(entry.Name(), suffix) {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
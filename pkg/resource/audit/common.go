@@ -0,0 +1,66 @@
+/*
+Copyright 2024 The Karmada Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package audit
+
+import (
+	"github.com/karmada-io/dashboard/pkg/audit"
+	"github.com/karmada-io/dashboard/pkg/dataselect"
+)
+
+// EventCell is a wrapper around audit Event type
+type EventCell audit.Event
+
+// GetProperty returns the given property of the audit Event.
+func (c EventCell) GetProperty(name dataselect.PropertyName) dataselect.ComparableValue {
+	switch name {
+	case dataselect.TimestampProperty:
+		return dataselect.StdComparableTime(c.Timestamp)
+	case dataselect.UserProperty:
+		return dataselect.StdComparableString(c.User)
+	case dataselect.VerbProperty:
+		return dataselect.StdComparableString(c.Verb)
+	case dataselect.ClusterProperty:
+		return dataselect.StdComparableString(c.Cluster)
+	case dataselect.NamespaceProperty:
+		return dataselect.StdComparableString(c.Namespace)
+	case dataselect.ResourceProperty:
+		return dataselect.StdComparableString(c.Resource)
+	case dataselect.NameProperty:
+		return dataselect.StdComparableString(c.Name)
+	case dataselect.OutcomeProperty:
+		return dataselect.StdComparableString(c.Outcome)
+	default:
+		// if name is not supported then just return a constant dummy value, sort will have no effect.
+		return nil
+	}
+}
+
+func toCells(std []audit.Event) []dataselect.DataCell {
+	cells := make([]dataselect.DataCell, len(std))
+	for i := range std {
+		cells[i] = EventCell(std[i])
+	}
+	return cells
+}
+
+func fromCells(cells []dataselect.DataCell) []audit.Event {
+	std := make([]audit.Event, len(cells))
+	for i := range std {
+		std[i] = audit.Event(cells[i].(EventCell))
+	}
+	return std
+}
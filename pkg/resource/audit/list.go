@@ -0,0 +1,72 @@
+/*
+Copyright 2024 The Karmada Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package audit
+
+import (
+	"context"
+
+	"github.com/karmada-io/dashboard/pkg/audit"
+	"github.com/karmada-io/dashboard/pkg/common/types"
+	"github.com/karmada-io/dashboard/pkg/dataselect"
+)
+
+// EventList contains a page of audit events.
+type EventList struct {
+	ListMeta types.ListMeta `json:"listMeta"`
+
+	// Events, most recent first unless sorted otherwise.
+	Events []audit.Event `json:"events"`
+	// Continue is passed back to list the next page, it is empty on the last page.
+	Continue string `json:"continue,omitempty"`
+}
+
+// newestFirst is the order events are listed in when the query does not sort them.
+var newestFirst = dataselect.NewSortQuery([]string{"d", dataselect.TimestampProperty})
+
+// GetEventList returns a page of the audit events matching query, most recent first. Events are
+// filtered by dsQuery while they are read, so that pages are full whatever the filter; the sort of
+// dsQuery applies to the events of the page.
+func GetEventList(ctx context.Context, query audit.Query, dsQuery *dataselect.DataSelectQuery) (*EventList, error) {
+	if dsQuery.FilterQuery != nil && len(dsQuery.FilterQuery.FilterByList) > 0 {
+		query.Match = func(event *audit.Event) bool {
+			selector := dataselect.DataSelector{
+				GenericDataList: []dataselect.DataCell{EventCell(*event)},
+				DataSelectQuery: dsQuery,
+			}
+			return len(selector.Filter().GenericDataList) == 1
+		}
+	}
+	page, err := audit.List(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return toEventList(page, dsQuery), nil
+}
+
+func toEventList(page *audit.Page, dsQuery *dataselect.DataSelectQuery) *EventList {
+	sortQuery := dsQuery.SortQuery
+	if sortQuery == nil || len(sortQuery.SortByList) == 0 {
+		sortQuery = newestFirst
+	}
+	query := dataselect.NewDataSelectQuery(dataselect.NoPagination, sortQuery, dataselect.NoFilter)
+	cells := dataselect.GenericDataSelect(toCells(page.Events), query)
+	return &EventList{
+		ListMeta: types.ListMeta{TotalItems: len(cells)},
+		Events:   fromCells(cells),
+		Continue: page.Continue,
+	}
+}
@@ -0,0 +1,228 @@
+/*
+Copyright 2024 The Karmada Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	v1 "github.com/karmada-io/dashboard/cmd/api/app/types/api/v1"
+	"github.com/karmada-io/dashboard/pkg/auth/fga"
+)
+
+// ValidateClusterPermission checks that every role in perm can be granted at its level.
+func ValidateClusterPermission(perm v1.ClusterPermission) error {
+	if perm.Cluster == "" {
+		return fmt.Errorf("cluster name cannot be empty")
+	}
+	for _, role := range perm.Roles {
+		if !fga.IsClusterRole(role) {
+			return fmt.Errorf("invalid role %q for cluster %s", role, perm.Cluster)
+		}
+	}
+	for _, ns := range perm.Namespaces {
+		if ns.Namespace == "" {
+			return fmt.Errorf("namespace cannot be empty in cluster %s", perm.Cluster)
+		}
+		for _, role := range ns.Roles {
+			if !fga.IsScopedRole(role) {
+				return fmt.Errorf("invalid role %q for namespace %s/%s", role, perm.Cluster, ns.Namespace)
+			}
+		}
+		for _, res := range ns.Resources {
+			if res.Kind == "" || strings.Contains(res.Kind, "/") {
+				return fmt.Errorf("invalid resource kind %q in namespace %s/%s", res.Kind, perm.Cluster, ns.Namespace)
+			}
+			for _, role := range res.Roles {
+				if !fga.IsScopedRole(role) {
+					return fmt.Errorf("invalid role %q for %s in namespace %s/%s", role, res.Kind, perm.Cluster, ns.Namespace)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// GetClusterPermission returns the grants subject holds directly in the cluster. The returned
+// permission has no roles and no namespaces when subject holds none.
+func GetClusterPermission(ctx context.Context, fgaClient fga.Client, subject, clusterName string) (*v1.ClusterPermission, error) {
+	tuples, err := readClusterTuples(ctx, fgaClient, subject, clusterName)
+	if err != nil {
+		return nil, err
+	}
+
+	perm := &v1.ClusterPermission{Cluster: clusterName, Roles: []string{}}
+	namespaces := map[string]*v1.NamespacePermission{}
+	namespaceFor := func(name string) *v1.NamespacePermission {
+		if ns, ok := namespaces[name]; ok {
+			return ns
+		}
+		ns := &v1.NamespacePermission{Namespace: name}
+		namespaces[name] = ns
+		return ns
+	}
+	for _, t := range tuples {
+		switch t.ObjectType {
+		case fga.TypeCluster:
+			if fga.IsClusterRole(t.Relation) {
+				perm.Roles = append(perm.Roles, t.Relation)
+			}
+		case fga.TypeNamespace:
+			_, namespace, _ := fga.ParseNamespaceObjectID(t.ObjectID)
+			ns := namespaceFor(namespace)
+			ns.Roles = append(ns.Roles, t.Relation)
+		case fga.TypeResource:
+			_, namespace, kind, _ := fga.ParseResourceObjectID(t.ObjectID)
+			ns := namespaceFor(namespace)
+			idx := slices.IndexFunc(ns.Resources, func(r v1.ResourcePermission) bool { return r.Kind == kind })
+			if idx < 0 {
+				ns.Resources = append(ns.Resources, v1.ResourcePermission{Kind: kind})
+				idx = len(ns.Resources) - 1
+			}
+			ns.Resources[idx].Roles = append(ns.Resources[idx].Roles, t.Relation)
+		}
+	}
+
+	for _, ns := range namespaces {
+		perm.Namespaces = append(perm.Namespaces, *ns)
+	}
+	sort.Slice(perm.Namespaces, func(i, j int) bool { return perm.Namespaces[i].Namespace < perm.Namespaces[j].Namespace })
+	return perm, nil
+}
+
+// GrantClusterPermission writes the grants in perm for subject, keeping the grants it already holds.
+// Subjects with namespace or resource grants also get the guest relation on the cluster so that
+// they can reach the cluster at all.
+func GrantClusterPermission(ctx context.Context, fgaClient fga.Client, subject string, perm v1.ClusterPermission) error {
+	if err := ValidateClusterPermission(perm); err != nil {
+		return err
+	}
+	existing, err := readClusterTuples(ctx, fgaClient, subject, perm.Cluster)
+	if err != nil {
+		return err
+	}
+
+	var wanted []fga.Tuple
+	for _, role := range perm.Roles {
+		wanted = append(wanted, fga.Tuple{User: subject, Relation: role, ObjectType: fga.TypeCluster, ObjectID: perm.Cluster})
+	}
+	for _, ns := range perm.Namespaces {
+		for _, role := range ns.Roles {
+			wanted = append(wanted, fga.Tuple{User: subject, Relation: role, ObjectType: fga.TypeNamespace,
+				ObjectID: fga.NamespaceObjectID(perm.Cluster, ns.Namespace)})
+		}
+		for _, res := range ns.Resources {
+			for _, role := range res.Roles {
+				wanted = append(wanted, fga.Tuple{User: subject, Relation: role, ObjectType: fga.TypeResource,
+					ObjectID: fga.ResourceObjectID(perm.Cluster, ns.Namespace, res.Kind)})
+			}
+		}
+	}
+	if len(wanted) > len(perm.Roles) {
+		wanted = append(wanted, fga.Tuple{User: subject, Relation: fga.RelationGuest, ObjectType: fga.TypeCluster, ObjectID: perm.Cluster})
+	}
+
+	for _, t := range wanted {
+		// OpenFGA rejects writing a tuple that already exists
+		if slices.Contains(existing, t) {
+			continue
+		}
+		if err := fgaClient.WriteTuple(ctx, t.User, t.Relation, t.ObjectType, t.ObjectID); err != nil {
+			return fmt.Errorf("failed to grant %s on %s:%s to %s: %w", t.Relation, t.ObjectType, t.ObjectID, subject, err)
+		}
+		existing = append(existing, t)
+	}
+	return nil
+}
+
+// RevokeClusterPermission removes every grant subject holds in the cluster, including the ones
+// on its namespaces and resource kinds.
+func RevokeClusterPermission(ctx context.Context, fgaClient fga.Client, subject, clusterName string) error {
+	tuples, err := readClusterTuples(ctx, fgaClient, subject, clusterName)
+	if err != nil {
+		return err
+	}
+	for _, t := range tuples {
+		if err := fgaClient.DeleteTuple(ctx, t.User, t.Relation, t.ObjectType, t.ObjectID); err != nil {
+			return fmt.Errorf("failed to revoke %s on %s:%s from %s: %w", t.Relation, t.ObjectType, t.ObjectID, subject, err)
+		}
+	}
+	return nil
+}
+
+// ReplaceClusterPermission makes perm the only grants subject holds in the cluster.
+func ReplaceClusterPermission(ctx context.Context, fgaClient fga.Client, subject string, perm v1.ClusterPermission) error {
+	if err := ValidateClusterPermission(perm); err != nil {
+		return err
+	}
+	if err := RevokeClusterPermission(ctx, fgaClient, subject, perm.Cluster); err != nil {
+		return err
+	}
+	return GrantClusterPermission(ctx, fgaClient, subject, perm)
+}
+
+// ListClusterPermissions returns the grants subject holds directly, one entry per cluster.
+func ListClusterPermissions(ctx context.Context, fgaClient fga.Client, subject string) ([]v1.ClusterPermission, error) {
+	var clusterNames []string
+	for _, objectType := range []string{fga.TypeCluster, fga.TypeNamespace, fga.TypeResource} {
+		tuples, err := fgaClient.ReadTuples(ctx, subject, "", objectType, "")
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range tuples {
+			clusterName, _, _ := strings.Cut(t.ObjectID, "/")
+			if !slices.Contains(clusterNames, clusterName) {
+				clusterNames = append(clusterNames, clusterName)
+			}
+		}
+	}
+	sort.Strings(clusterNames)
+
+	perms := make([]v1.ClusterPermission, 0, len(clusterNames))
+	for _, clusterName := range clusterNames {
+		perm, err := GetClusterPermission(ctx, fgaClient, subject, clusterName)
+		if err != nil {
+			return nil, err
+		}
+		perms = append(perms, *perm)
+	}
+	return perms, nil
+}
+
+// readClusterTuples returns the tuples subject holds on the cluster and on its namespaces and resource kinds.
+func readClusterTuples(ctx context.Context, fgaClient fga.Client, subject, clusterName string) ([]fga.Tuple, error) {
+	tuples, err := fgaClient.ReadTuples(ctx, subject, "", fga.TypeCluster, clusterName)
+	if err != nil {
+		return nil, err
+	}
+	prefix := clusterName + "/"
+	for _, objectType := range []string{fga.TypeNamespace, fga.TypeResource} {
+		scoped, err := fgaClient.ReadTuples(ctx, subject, "", objectType, "")
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range scoped {
+			if strings.HasPrefix(t.ObjectID, prefix) {
+				tuples = append(tuples, t)
+			}
+		}
+	}
+	return tuples, nil
+}
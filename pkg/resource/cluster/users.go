@@ -24,6 +24,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/klog/v2"
 
+	v1 "github.com/karmada-io/dashboard/cmd/api/app/types/api/v1"
 	"github.com/karmada-io/dashboard/pkg/auth/fga"
 	"github.com/karmada-io/dashboard/pkg/common/errors"
 	"github.com/karmada-io/dashboard/pkg/etcd"
@@ -35,12 +36,23 @@ type ClusterUser struct {
 	DisplayName string   `json:"displayName"`
 	Email       string   `json:"email,omitempty"`
 	Roles       []string `json:"roles"`
+	// Namespaces contains the grants the user holds on namespaces and resource kinds of the cluster
+	Namespaces []v1.NamespacePermission `json:"namespaces,omitempty"`
+}
+
+// ClusterGroup represents a group with access to a cluster and the roles granted to it.
+type ClusterGroup struct {
+	Name       string                   `json:"name"`
+	Members    []string                 `json:"members"`
+	Roles      []string                 `json:"roles"`
+	Namespaces []v1.NamespacePermission `json:"namespaces,omitempty"`
 }
 
 // ClusterUserList represents a list of users with access to a specific cluster.
 type ClusterUserList struct {
-	Users  []ClusterUser `json:"users"`
-	Errors []error       `json:"errors"`
+	Users  []ClusterUser  `json:"users"`
+	Groups []ClusterGroup `json:"groups"`
+	Errors []error        `json:"errors"`
 }
 
 // GetClusterUsers returns a list of users that have access to the specified cluster.
@@ -58,7 +70,8 @@ func GetClusterUsers(client karmadaclientset.Interface, clusterName string) (*Cl
 
 	// Initialize user list
 	userList := &ClusterUserList{
-		Users: []ClusterUser{},
+		Users:  []ClusterUser{},
+		Groups: []ClusterGroup{},
 	}
 
 	// Get the FGA service
@@ -127,62 +140,66 @@ func GetClusterUsers(client karmadaclientset.Interface, clusterName string) (*Cl
 		}
 	}
 
-	// Map FGA relation names to our role names for display
-	fgaRelationToRole := map[string]string{
-		"owner":  "owner",
-		"member": "member",
+	// Get cluster-specific role assignments, including grants scoped to namespaces and resource kinds
+	users, err := userManager.ListUsers(context.Background())
+	if err != nil {
+		klog.ErrorS(err, "Failed to list users from etcd")
+		return nil, err
 	}
+	for _, user := range users {
+		if user.Username == "" {
+			continue
+		}
 
-	// Get cluster-specific role assignments
-	// Based on the OpenFGA schema, cluster has "owner" and "member" relations
-	fgaRelations := []string{"owner", "member"}
-	for _, relation := range fgaRelations {
-		users, err := getUsersWithRole(fgaService, relation, "cluster", clusterName, userManager)
+		perm, err := GetClusterPermission(context.Background(), fgaService.GetClient(), user.Username, clusterName)
 		if err != nil {
-			nonCriticalErrors, criticalError := errors.ExtractErrors(err)
-			if criticalError != nil {
-				return nil, criticalError
-			}
-			userList.Errors = append(userList.Errors, nonCriticalErrors...)
+			klog.ErrorS(err, "Failed to read cluster permissions", "user", user.Username, "cluster", clusterName)
+			userList.Errors = append(userList.Errors, err)
+			continue
+		}
+		if len(perm.Roles) == 0 && len(perm.Namespaces) == 0 {
 			continue
 		}
 
-		// Get the corresponding role for this relation
-		role := fgaRelationToRole[relation]
-
-		// Add users with this role to the map
-		for _, username := range users {
-			// Get user details
-			userInfo, err := userManager.GetUser(context.Background(), username)
-			if err != nil {
-				klog.ErrorS(err, "Failed to get user details", "username", username)
-				// Add user without details if we can't get them
-				if _, exists := userMap[username]; !exists {
-					userMap[username] = &ClusterUser{
-						Username: username,
-						Roles:    []string{role},
-					}
-				} else {
-					userMap[username].Roles = append(userMap[username].Roles, role)
-				}
-				continue
-			}
-			
-			// If user already exists in map, add the role
-			if existingUser, exists := userMap[username]; exists {
-				existingUser.Roles = append(existingUser.Roles, role)
-			} else {
-				// Otherwise create a new entry
-				userMap[username] = &ClusterUser{
-					Username:    username,
-					DisplayName: userInfo.Email, // Use email as display name if no display name field exists
-					Email:       userInfo.Email,
-					Roles:       []string{role},
-				}
-			}
+		// If user already exists in map, add the roles
+		if existingUser, exists := userMap[user.Username]; exists {
+			existingUser.Roles = append(existingUser.Roles, perm.Roles...)
+			existingUser.Namespaces = perm.Namespaces
+			continue
+		}
+		userMap[user.Username] = &ClusterUser{
+			Username:    user.Username,
+			DisplayName: user.Email, // Use email as display name if no display name field exists
+			Email:       user.Email,
+			Roles:       perm.Roles,
+			Namespaces:  perm.Namespaces,
 		}
 	}
 	
+	// Groups grant their roles to every member, list them separately from direct grants
+	groups, err := etcd.NewGroupManager(etcdClient).ListGroups(context.Background())
+	if err != nil {
+		klog.ErrorS(err, "Failed to list groups from etcd")
+		userList.Errors = append(userList.Errors, err)
+	}
+	for _, group := range groups {
+		perm, err := GetClusterPermission(context.Background(), fgaService.GetClient(), fga.GroupSubject(group.Name), clusterName)
+		if err != nil {
+			klog.ErrorS(err, "Failed to read cluster permissions", "group", group.Name, "cluster", clusterName)
+			userList.Errors = append(userList.Errors, err)
+			continue
+		}
+		if len(perm.Roles) == 0 && len(perm.Namespaces) == 0 {
+			continue
+		}
+		userList.Groups = append(userList.Groups, ClusterGroup{
+			Name:       group.Name,
+			Members:    group.Members,
+			Roles:      perm.Roles,
+			Namespaces: perm.Namespaces,
+		})
+	}
+
 	// Convert the map to a list
 	for _, user := range userMap {
 		userList.Users = append(userList.Users, *user)
@@ -0,0 +1,348 @@
+/*
+Copyright 2024 The Karmada Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package diff
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// contextLines is how many unchanged lines surround the changes of a unified diff hunk
+	contextLines = 3
+	// maxDiffCells bounds the line comparisons of a unified diff, larger changed regions are
+	// shown as replaced as a whole
+	maxDiffCells = 4 * 1024 * 1024
+)
+
+// Operation is the kind of a change, named as in JSON Patch.
+type Operation string
+
+const (
+	// OperationAdd is a field set only in the new object
+	OperationAdd Operation = "add"
+	// OperationRemove is a field set only in the old object
+	OperationRemove Operation = "remove"
+	// OperationReplace is a field set to different values
+	OperationReplace Operation = "replace"
+)
+
+// Change is a changed field between two objects.
+type Change struct {
+	// Path is the JSON Pointer of the field, e.g. /spec/template/spec/containers/0/image
+	Path string      `json:"path"`
+	Op   Operation   `json:"op"`
+	Old  interface{} `json:"old,omitempty"`
+	New  interface{} `json:"new,omitempty"`
+}
+
+// Result is the difference between the live version of an object and the version it would have
+// once a submitted manifest is applied.
+type Result struct {
+	// Live is nil if the object does not exist yet
+	Live    *unstructured.Unstructured `json:"live"`
+	Merged  *unstructured.Unstructured `json:"merged"`
+	Changes []Change                   `json:"changes"`
+	// Unified is the diff of the YAML of both versions, empty if they are equal
+	Unified string `json:"unified"`
+}
+
+// Objects compares the live and merged versions of an object. Managed fields are left out, they
+// change on every apply and are of no interest to the reader.
+func Objects(live, merged *unstructured.Unstructured) (*Result, error) {
+	live, merged = normalize(live), normalize(merged)
+	// A missing object is nil rather than a nil map, so that its creation is a single addition
+	var before, after interface{}
+	if live != nil {
+		before = live.Object
+	}
+	if merged != nil {
+		after = merged.Object
+	}
+
+	result := &Result{Live: live, Merged: merged, Changes: []Change{}}
+	compare("", before, after, &result.Changes)
+
+	oldYAML, err := toYAML(live)
+	if err != nil {
+		return nil, err
+	}
+	newYAML, err := toYAML(merged)
+	if err != nil {
+		return nil, err
+	}
+	result.Unified = Unified(oldYAML, newYAML, "live", "merged")
+	return result, nil
+}
+
+func normalize(obj *unstructured.Unstructured) *unstructured.Unstructured {
+	if obj == nil {
+		return nil
+	}
+	obj = obj.DeepCopy()
+	unstructured.RemoveNestedField(obj.Object, "metadata", "managedFields")
+	return obj
+}
+
+func toYAML(obj *unstructured.Unstructured) (string, error) {
+	if obj == nil {
+		return "", nil
+	}
+	data, err := yaml.Marshal(obj.Object)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal object: %w", err)
+	}
+	return string(data), nil
+}
+
+// compare appends the changes from before to after below path. Lists are compared by index.
+func compare(path string, before, after interface{}, changes *[]Change) {
+	oldMap, oldIsMap := before.(map[string]interface{})
+	newMap, newIsMap := after.(map[string]interface{})
+	if oldIsMap && newIsMap {
+		keys := make([]string, 0, len(oldMap)+len(newMap))
+		for key := range oldMap {
+			keys = append(keys, key)
+		}
+		for key := range newMap {
+			if _, found := oldMap[key]; !found {
+				keys = append(keys, key)
+			}
+		}
+		slices.Sort(keys)
+		for _, key := range keys {
+			oldValue, inOld := oldMap[key]
+			newValue, inNew := newMap[key]
+			child := path + "/" + escapePointer(key)
+			switch {
+			case !inNew:
+				*changes = append(*changes, Change{Path: child, Op: OperationRemove, Old: oldValue})
+			case !inOld:
+				*changes = append(*changes, Change{Path: child, Op: OperationAdd, New: newValue})
+			default:
+				compare(child, oldValue, newValue, changes)
+			}
+		}
+		return
+	}
+
+	oldList, oldIsList := before.([]interface{})
+	newList, newIsList := after.([]interface{})
+	if oldIsList && newIsList {
+		for i := 0; i < max(len(oldList), len(newList)); i++ {
+			child := path + "/" + strconv.Itoa(i)
+			switch {
+			case i >= len(newList):
+				*changes = append(*changes, Change{Path: child, Op: OperationRemove, Old: oldList[i]})
+			case i >= len(oldList):
+				*changes = append(*changes, Change{Path: child, Op: OperationAdd, New: newList[i]})
+			default:
+				compare(child, oldList[i], newList[i], changes)
+			}
+		}
+		return
+	}
+
+	if !reflect.DeepEqual(before, after) {
+		switch {
+		case before == nil:
+			*changes = append(*changes, Change{Path: path, Op: OperationAdd, New: after})
+		case after == nil:
+			*changes = append(*changes, Change{Path: path, Op: OperationRemove, Old: before})
+		default:
+			*changes = append(*changes, Change{Path: path, Op: OperationReplace, Old: before, New: after})
+		}
+	}
+}
+
+// escapePointer escapes a key for a JSON Pointer (RFC 6901).
+func escapePointer(key string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(key, "~", "~0"), "/", "~1")
+}
+
+// Unified returns the unified diff of two texts, as diff -u, or an empty string if they are equal.
+func Unified(before, after, oldName, newName string) string {
+	if before == after {
+		return ""
+	}
+	oldLines, newLines := splitLines(before), splitLines(after)
+	edits := diffLines(oldLines, newLines)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- %s\n+++ %s\n", oldName, newName)
+	for start := 0; start < len(edits); {
+		// Find the next change and the end of its hunk, where changes are more than twice the
+		// context apart
+		first := start
+		for first < len(edits) && edits[first].op == ' ' {
+			first++
+		}
+		if first == len(edits) {
+			break
+		}
+		last := first
+		for i := first; i < len(edits); i++ {
+			if edits[i].op != ' ' {
+				last = i
+			} else if i-last > 2*contextLines {
+				break
+			}
+		}
+		from, to := max(first-contextLines, start), min(last+contextLines+1, len(edits))
+		writeHunk(&b, edits, from, to)
+		start = to
+	}
+	return b.String()
+}
+
+type edit struct {
+	op   byte
+	line string
+	// oldLine and newLine are the line numbers, from 1, of the line before the edit in both texts
+	oldLine, newLine int
+}
+
+func writeHunk(b *strings.Builder, edits []edit, from, to int) {
+	oldStart, newStart := edits[from].oldLine, edits[from].newLine
+	oldCount, newCount := 0, 0
+	for _, e := range edits[from:to] {
+		if e.op != '+' {
+			oldCount++
+		}
+		if e.op != '-' {
+			newCount++
+		}
+	}
+	// Empty ranges start at the line before them
+	if oldCount > 0 {
+		oldStart++
+	}
+	if newCount > 0 {
+		newStart++
+	}
+	fmt.Fprintf(b, "@@ -%d,%d +%d,%d @@\n", oldStart, oldCount, newStart, newCount)
+	for _, e := range edits[from:to] {
+		b.WriteByte(e.op)
+		b.WriteString(e.line)
+		b.WriteByte('\n')
+	}
+}
+
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+// diffLines returns the edits turning before into after, from a longest common subsequence of lines.
+func diffLines(before, after []string) []edit {
+	prefix := 0
+	for prefix < len(before) && prefix < len(after) && before[prefix] == after[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(before)-prefix && suffix < len(after)-prefix && before[len(before)-1-suffix] == after[len(after)-1-suffix] {
+		suffix++
+	}
+	a, b := before[prefix:len(before)-suffix], after[prefix:len(after)-suffix]
+
+	var ops []byte
+	if len(a)*len(b) > maxDiffCells {
+		ops = append(ops, slices.Repeat([]byte{'-'}, len(a))...)
+		ops = append(ops, slices.Repeat([]byte{'+'}, len(b))...)
+	} else {
+		ops = lcsOps(a, b)
+	}
+
+	edits := make([]edit, 0, len(before)+len(b))
+	i, j := 0, 0
+	add := func(op byte, line string) {
+		edits = append(edits, edit{op: op, line: line, oldLine: i, newLine: j})
+		if op != '+' {
+			i++
+		}
+		if op != '-' {
+			j++
+		}
+	}
+	for k := 0; k < prefix; k++ {
+		add(' ', before[k])
+	}
+	for _, op := range ops {
+		switch op {
+		case '-':
+			add(op, before[i])
+		case '+':
+			add(op, after[j])
+		default:
+			add(op, before[i])
+		}
+	}
+	for k := len(before) - suffix; k < len(before); k++ {
+		add(' ', before[k])
+	}
+	return edits
+}
+
+// lcsOps returns the operations turning a into b, removals before additions within a change.
+func lcsOps(a, b []string) []byte {
+	// lengths[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	lengths := make([][]int32, len(a)+1)
+	for i := range lengths {
+		lengths[i] = make([]int32, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lengths[i][j] = lengths[i+1][j+1] + 1
+			} else {
+				lengths[i][j] = max(lengths[i+1][j], lengths[i][j+1])
+			}
+		}
+	}
+
+	ops := make([]byte, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			ops = append(ops, ' ')
+			i++
+			j++
+		case lengths[i+1][j] >= lengths[i][j+1]:
+			ops = append(ops, '-')
+			i++
+		default:
+			ops = append(ops, '+')
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		ops = append(ops, '-')
+	}
+	for ; j < len(b); j++ {
+		ops = append(ops, '+')
+	}
+	return ops
+}
@@ -0,0 +1,105 @@
+/*
+Copyright 2024 The Karmada Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package diff
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func testDeployment(replicas int64, labels map[string]interface{}, containers ...string) *unstructured.Unstructured {
+	var list []interface{}
+	for _, name := range containers {
+		list = append(list, map[string]interface{}{"name": name})
+	}
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata": map[string]interface{}{
+			"name":          "web",
+			"labels":        labels,
+			"managedFields": []interface{}{map[string]interface{}{"manager": fmt.Sprint(replicas)}},
+		},
+		"spec": map[string]interface{}{"replicas": replicas, "containers": list},
+	}}
+}
+
+func TestObjects(t *testing.T) {
+	live := testDeployment(1, map[string]interface{}{"app": "web"}, "web", "sidecar")
+	cases := []struct {
+		live, merged *unstructured.Unstructured
+		changes      []Change
+	}{
+		{live, testDeployment(1, map[string]interface{}{"app": "web"}, "web", "sidecar"), []Change{}},
+		{live, testDeployment(3, map[string]interface{}{"app": "web", "app.kubernetes.io/part-of": "shop"}, "web"), []Change{
+			{Path: "/metadata/labels/app.kubernetes.io~1part-of", Op: OperationAdd, New: "shop"},
+			{Path: "/spec/containers/1", Op: OperationRemove, Old: map[string]interface{}{"name": "sidecar"}},
+			{Path: "/spec/replicas", Op: OperationReplace, Old: int64(1), New: int64(3)},
+		}},
+		{nil, live, []Change{{Path: "", Op: OperationAdd, New: testDeployment(1, map[string]interface{}{"app": "web"}, "web", "sidecar").Object}}},
+	}
+	for i, c := range cases {
+		result, err := Objects(c.live, c.merged)
+		if err != nil {
+			t.Fatalf("case %d: Objects() failed: %v", i, err)
+		}
+		// Managed fields are left out of the comparison
+		if len(c.changes) == 1 {
+			unstructured.RemoveNestedField(c.changes[0].New.(map[string]interface{}), "metadata", "managedFields")
+		}
+		if !reflect.DeepEqual(result.Changes, c.changes) {
+			t.Errorf("case %d: Objects() changes == %+v, expected %+v", i, result.Changes, c.changes)
+		}
+		if (result.Unified == "") != (len(c.changes) == 0) || strings.Contains(result.Unified, "managedFields") {
+			t.Errorf("case %d: unexpected unified diff %q", i, result.Unified)
+		}
+	}
+	if _, found := live.Object["metadata"].(map[string]interface{})["managedFields"]; !found {
+		t.Errorf("Objects() changed the live object")
+	}
+}
+
+func TestUnified(t *testing.T) {
+	var lines, changed []string
+	for i := 1; i <= 20; i++ {
+		lines = append(lines, fmt.Sprint(i))
+	}
+	changed = append(changed, lines...)
+	changed[1], changed[18] = "x", "y"
+
+	cases := []struct {
+		before, after string
+		expected      string
+	}{
+		{"a\nb\n", "a\nb\n", ""},
+		{"a\nb\nc\n", "a\nB\nc\nd\n", "--- live\n+++ merged\n@@ -1,3 +1,4 @@\n a\n-b\n+B\n c\n+d\n"},
+		{"", "a\n", "--- live\n+++ merged\n@@ -0,0 +1,1 @@\n+a\n"},
+		{"a\n", "", "--- live\n+++ merged\n@@ -1,1 +0,0 @@\n-a\n"},
+		// Changes further apart than twice the context are in separate hunks
+		{strings.Join(lines, "\n") + "\n", strings.Join(changed, "\n") + "\n",
+			"--- live\n+++ merged\n@@ -1,5 +1,5 @@\n 1\n-2\n+x\n 3\n 4\n 5\n@@ -16,5 +16,5 @@\n 16\n 17\n 18\n-19\n+y\n 20\n"},
+	}
+	for _, c := range cases {
+		if unified := Unified(c.before, c.after, "live", "merged"); unified != c.expected {
+			t.Errorf("Unified(%q, %q) ==\n%s\nexpected\n%s", c.before, c.after, unified, c.expected)
+		}
+	}
+}
@@ -0,0 +1,244 @@
+/*
+Copyright 2024 The Karmada Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manifest
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/klog/v2"
+
+	"github.com/karmada-io/dashboard/pkg/client"
+)
+
+// crdEstablishTimeout bounds the wait for an applied CRD to serve its custom resources
+var crdEstablishTimeout = 30 * time.Second
+
+// Mapper resolves the kinds of manifests to resources, as client.ClusterRESTMapper does.
+type Mapper interface {
+	MappingForGroupVersionKind(group, version, kind string) (*meta.RESTMapping, error)
+	// Invalidate has the mapper discover the resources again, after CRDs were applied
+	Invalidate()
+}
+
+// ApplyOptions tells how manifests are applied.
+type ApplyOptions struct {
+	// Namespace is the namespace of namespaced objects that have none, "default" if empty
+	Namespace string
+	// Force takes over the fields other managers own instead of failing on conflicts
+	Force  bool
+	DryRun []string
+	// Rollback stops at the first failure and reverts the objects applied before it
+	Rollback bool
+}
+
+// Action is what applying an object did.
+type Action string
+
+const (
+	// ActionCreated is an object that did not exist
+	ActionCreated Action = "created"
+	// ActionConfigured is an existing object that was changed
+	ActionConfigured Action = "configured"
+	// ActionUnchanged is an existing object the manifest did not change
+	ActionUnchanged Action = "unchanged"
+	// ActionFailed is an object that could not be applied
+	ActionFailed Action = "failed"
+	// ActionSkipped is an object left out after an earlier failure, with rollback
+	ActionSkipped Action = "skipped"
+)
+
+// ObjectResult is the result of applying an object.
+type ObjectResult struct {
+	Source     string `json:"source"`
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Namespace  string `json:"namespace,omitempty"`
+	Name       string `json:"name"`
+	Action     Action `json:"action"`
+	Error      string `json:"error,omitempty"`
+	// RolledBack is set on applied objects that were reverted after a failure
+	RolledBack    bool   `json:"rolledBack,omitempty"`
+	RollbackError string `json:"rollbackError,omitempty"`
+}
+
+// ApplyResult is the result of applying manifests, with one result per object in the order they
+// were applied.
+type ApplyResult struct {
+	Objects    []ObjectResult `json:"objects"`
+	Failed     int            `json:"failed"`
+	RolledBack bool           `json:"rolledBack"`
+}
+
+// applied is an applied object, with what it was before for rollback.
+type applied struct {
+	result   *ObjectResult
+	resource dynamic.ResourceInterface
+	// previous is nil for created objects
+	previous *unstructured.Unstructured
+}
+
+// Apply sorts manifests by dependency and applies them with server-side apply, as the dashboard
+// field manager. Without rollback every object is tried, with rollback the objects after the first
+// failure are skipped and the ones before it reverted: created objects are deleted, changed ones
+// restored to their previous version.
+func Apply(ctx context.Context, dynamicClient dynamic.Interface, mapper Mapper, manifests []Manifest, opts ApplyOptions) *ApplyResult {
+	Sort(manifests)
+	namespace := opts.Namespace
+	if namespace == "" {
+		namespace = metav1.NamespaceDefault
+	}
+	dryRun := len(opts.DryRun) > 0
+
+	result := &ApplyResult{Objects: make([]ObjectResult, len(manifests))}
+	var done []applied
+	for i, manifest := range manifests {
+		obj := manifest.Object.DeepCopy()
+		objResult := &result.Objects[i]
+		*objResult = ObjectResult{Source: manifest.Source, APIVersion: obj.GetAPIVersion(), Kind: obj.GetKind(), Name: obj.GetName()}
+		if opts.Rollback && result.Failed > 0 {
+			objResult.Namespace = obj.GetNamespace()
+			objResult.Action = ActionSkipped
+			continue
+		}
+
+		resource, previous, err := applyObject(ctx, dynamicClient, mapper, obj, namespace, opts)
+		objResult.Namespace = obj.GetNamespace()
+		if err != nil {
+			objResult.Action, objResult.Error = ActionFailed, err.Error()
+			result.Failed++
+			continue
+		}
+		switch {
+		case previous == nil:
+			objResult.Action = ActionCreated
+		case previous.GetResourceVersion() == obj.GetResourceVersion():
+			objResult.Action = ActionUnchanged
+		default:
+			objResult.Action = ActionConfigured
+		}
+		if objResult.Action != ActionUnchanged {
+			done = append(done, applied{result: objResult, resource: resource, previous: previous})
+		}
+
+		if obj.GetKind() == "CustomResourceDefinition" && !dryRun {
+			if err := waitEstablished(ctx, resource, obj.GetName()); err != nil {
+				klog.ErrorS(err, "Applied CRD is not established", "name", obj.GetName())
+			}
+			mapper.Invalidate()
+		}
+	}
+
+	if opts.Rollback && result.Failed > 0 && !dryRun {
+		for i := len(done) - 1; i >= 0; i-- {
+			if err := rollback(ctx, done[i]); err != nil {
+				done[i].result.RollbackError = err.Error()
+				continue
+			}
+			done[i].result.RolledBack = true
+		}
+		result.RolledBack = true
+	}
+	return result
+}
+
+// applyObject applies obj, replacing it with the applied object, and returns the client of its
+// resource and its previous version, nil if it did not exist.
+func applyObject(ctx context.Context, dynamicClient dynamic.Interface, mapper Mapper, obj *unstructured.Unstructured, namespace string, opts ApplyOptions) (dynamic.ResourceInterface, *unstructured.Unstructured, error) {
+	gvk := obj.GroupVersionKind()
+	mapping, err := mapper.MappingForGroupVersionKind(gvk.Group, gvk.Version, gvk.Kind)
+	if err != nil {
+		return nil, nil, err
+	}
+	var resource dynamic.ResourceInterface = dynamicClient.Resource(mapping.Resource)
+	if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+		if obj.GetNamespace() == "" {
+			obj.SetNamespace(namespace)
+		}
+		resource = dynamicClient.Resource(mapping.Resource).Namespace(obj.GetNamespace())
+	} else {
+		obj.SetNamespace("")
+	}
+
+	previous, err := resource.Get(ctx, obj.GetName(), metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		previous = nil
+	} else if err != nil {
+		return nil, nil, err
+	}
+	result, err := resource.Apply(ctx, obj.GetName(), obj, client.ApplyOptions(opts.Force, opts.DryRun))
+	if err != nil {
+		return nil, nil, err
+	}
+	obj.Object = result.Object
+	return resource, previous, nil
+}
+
+// rollback reverts an applied object.
+func rollback(ctx context.Context, object applied) error {
+	name := object.result.Name
+	if object.previous == nil {
+		propagation := metav1.DeletePropagationBackground
+		err := object.resource.Delete(ctx, name, metav1.DeleteOptions{PropagationPolicy: &propagation})
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	current, err := object.resource.Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	previous := object.previous.DeepCopy()
+	previous.SetManagedFields(nil)
+	previous.SetResourceVersion(current.GetResourceVersion())
+	_, err = object.resource.Update(ctx, previous, metav1.UpdateOptions{FieldManager: client.FieldManager})
+	return err
+}
+
+// waitEstablished waits for a CRD to serve its custom resources.
+func waitEstablished(ctx context.Context, crds dynamic.ResourceInterface, name string) error {
+	return wait.PollUntilContextTimeout(ctx, time.Second, crdEstablishTimeout, true, func(ctx context.Context) (bool, error) {
+		crd, err := crds.Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		conditions, _, _ := unstructured.NestedSlice(crd.Object, "status", "conditions")
+		for _, condition := range conditions {
+			if c, ok := condition.(map[string]interface{}); ok && c["type"] == "Established" && c["status"] == "True" {
+				return true, nil
+			}
+		}
+		return false, nil
+	})
+}
+
+// String returns the object as kind namespace/name.
+func (r ObjectResult) String() string {
+	if r.Namespace == "" {
+		return fmt.Sprintf("%s %s", r.Kind, r.Name)
+	}
+	return fmt.Sprintf("%s %s/%s", r.Kind, r.Namespace, r.Name)
+}
@@ -0,0 +1,285 @@
+/*
+Copyright 2024 The Karmada Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manifest
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"slices"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/yaml"
+)
+
+const (
+	// MaxSize bounds the manifests of a request, uncompressed
+	MaxSize = 16 * 1024 * 1024
+	// maxFiles bounds the manifest files of an archive
+	maxFiles = 1000
+)
+
+// Manifest is an object of a manifest, with where it was read from.
+type Manifest struct {
+	// Source is the file and the position of the object in it, e.g. "app/deploy.yaml#2"
+	Source string
+	Object *unstructured.Unstructured
+}
+
+// Read decodes the objects of a multi-document YAML or JSON stream, or of the .yaml, .yml and
+// .json files of a tar, gzip-compressed tar or zip archive, in the order of the files.
+func Read(data []byte) ([]Manifest, error) {
+	switch {
+	case bytes.HasPrefix(data, []byte("PK\x03\x04")):
+		return readZip(data)
+	case bytes.HasPrefix(data, []byte{0x1f, 0x8b}):
+		reader, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("invalid gzip archive: %w", err)
+		}
+		defer reader.Close()
+		uncompressed, err := readAll(reader, MaxSize)
+		if err != nil {
+			return nil, err
+		}
+		if isTar(uncompressed) {
+			return readTar(uncompressed)
+		}
+		return Decode("manifest", uncompressed)
+	case isTar(data):
+		return readTar(data)
+	default:
+		return Decode("manifest", data)
+	}
+}
+
+// Decode decodes the objects of a multi-document YAML or JSON stream. Empty documents are
+// skipped and the items of lists are returned as objects of their own.
+func Decode(source string, data []byte) ([]Manifest, error) {
+	decoder := yaml.NewYAMLOrJSONDecoder(bytes.NewReader(data), 4096)
+	var manifests []Manifest
+	for document := 1; ; document++ {
+		var rawObj map[string]interface{}
+		err := decoder.Decode(&rawObj)
+		if errors.Is(err, io.EOF) {
+			return manifests, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: failed to decode document %d: %w", source, document, err)
+		}
+		if len(rawObj) == 0 {
+			continue
+		}
+
+		obj := &unstructured.Unstructured{Object: rawObj}
+		where := fmt.Sprintf("%s#%d", source, document)
+		if !obj.IsList() {
+			if err := validate(obj); err != nil {
+				return nil, fmt.Errorf("%s: %w", where, err)
+			}
+			manifests = append(manifests, Manifest{Source: where, Object: obj})
+			continue
+		}
+		list, err := obj.ToList()
+		if err != nil {
+			return nil, fmt.Errorf("%s: invalid list: %w", where, err)
+		}
+		for i := range list.Items {
+			item := fmt.Sprintf("%s[%d]", where, i)
+			if err := validate(&list.Items[i]); err != nil {
+				return nil, fmt.Errorf("%s: %w", item, err)
+			}
+			manifests = append(manifests, Manifest{Source: item, Object: &list.Items[i]})
+		}
+	}
+}
+
+func validate(obj *unstructured.Unstructured) error {
+	if obj.GetAPIVersion() == "" || obj.GetKind() == "" {
+		return fmt.Errorf("apiVersion and kind are required")
+	}
+	if obj.GetName() == "" {
+		if obj.GetGenerateName() != "" {
+			return fmt.Errorf("generateName is not supported by server-side apply, a name is required")
+		}
+		return fmt.Errorf("metadata.name is required")
+	}
+	return nil
+}
+
+// isManifestFile reports whether an archive entry is a manifest, leaving out hidden files such as
+// the resource forks macOS adds to archives.
+func isManifestFile(name string) bool {
+	base := path.Base(name)
+	if strings.HasPrefix(base, ".") || strings.HasPrefix(name, "__MACOSX/") {
+		return false
+	}
+	switch strings.ToLower(path.Ext(base)) {
+	case ".yaml", ".yml", ".json":
+		return true
+	default:
+		return false
+	}
+}
+
+func isTar(data []byte) bool {
+	// The magic of POSIX and GNU tar headers
+	return len(data) > 262 && bytes.Equal(data[257:262], []byte("ustar"))
+}
+
+func readTar(data []byte) ([]Manifest, error) {
+	files := map[string][]byte{}
+	size := 0
+	reader := tar.NewReader(bytes.NewReader(data))
+	for {
+		header, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid tar archive: %w", err)
+		}
+		if header.Typeflag != tar.TypeReg || !isManifestFile(header.Name) {
+			continue
+		}
+		if len(files) == maxFiles {
+			return nil, fmt.Errorf("the archive has more than %d manifest files", maxFiles)
+		}
+		content, err := readAll(reader, MaxSize-size)
+		if err != nil {
+			return nil, err
+		}
+		size += len(content)
+		files[header.Name] = content
+	}
+	return decodeFiles(files)
+}
+
+func readZip(data []byte) ([]Manifest, error) {
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid zip archive: %w", err)
+	}
+	files := map[string][]byte{}
+	size := 0
+	for _, file := range reader.File {
+		if file.FileInfo().IsDir() || !isManifestFile(file.Name) {
+			continue
+		}
+		if len(files) == maxFiles {
+			return nil, fmt.Errorf("the archive has more than %d manifest files", maxFiles)
+		}
+		opened, err := file.Open()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", file.Name, err)
+		}
+		content, err := readAll(opened, MaxSize-size)
+		opened.Close()
+		if err != nil {
+			return nil, err
+		}
+		size += len(content)
+		files[file.Name] = content
+	}
+	return decodeFiles(files)
+}
+
+// readAll reads r, failing if it holds more than limit bytes.
+func readAll(r io.Reader, limit int) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, int64(limit)+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > limit {
+		return nil, fmt.Errorf("the manifests are larger than %d bytes", MaxSize)
+	}
+	return data, nil
+}
+
+// decodeFiles decodes the files of an archive in the order of their names.
+func decodeFiles(files map[string][]byte) ([]Manifest, error) {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	var manifests []Manifest
+	for _, name := range names {
+		decoded, err := Decode(name, files[name])
+		if err != nil {
+			return nil, err
+		}
+		manifests = append(manifests, decoded...)
+	}
+	return manifests, nil
+}
+
+// installOrder is the order kinds are applied in, so that objects come after what they depend on:
+// namespaces first, then policies, identities and configuration, CRDs and RBAC before workloads.
+// Kinds not listed, including custom resources, come last.
+var installOrder = []string{
+	"Namespace",
+	"NetworkPolicy",
+	"ResourceQuota",
+	"LimitRange",
+	"PodSecurityPolicy",
+	"PodDisruptionBudget",
+	"ServiceAccount",
+	"Secret",
+	"ConfigMap",
+	"StorageClass",
+	"PersistentVolume",
+	"PersistentVolumeClaim",
+	"CustomResourceDefinition",
+	"ClusterRole",
+	"ClusterRoleBinding",
+	"Role",
+	"RoleBinding",
+	"Service",
+	"DaemonSet",
+	"Pod",
+	"ReplicationController",
+	"ReplicaSet",
+	"Deployment",
+	"HorizontalPodAutoscaler",
+	"StatefulSet",
+	"Job",
+	"CronJob",
+	"IngressClass",
+	"Ingress",
+	"APIService",
+}
+
+// Sort orders manifests by the dependencies of their kinds, keeping the order of the manifests
+// of the same kind.
+func Sort(manifests []Manifest) {
+	rank := func(m Manifest) int {
+		if i := slices.Index(installOrder, m.Object.GetKind()); i >= 0 {
+			return i
+		}
+		return len(installOrder)
+	}
+	slices.SortStableFunc(manifests, func(a, b Manifest) int {
+		return rank(a) - rank(b)
+	})
+}
@@ -0,0 +1,300 @@
+/*
+Copyright 2024 The Karmada Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manifest
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+const testManifests = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+---
+# A comment only document
+---
+apiVersion: v1
+kind: List
+items:
+- apiVersion: v1
+  kind: ConfigMap
+  metadata:
+    name: web-config
+- apiVersion: v1
+  kind: Namespace
+  metadata:
+    name: shop
+`
+
+func sources(manifests []Manifest) []string {
+	var result []string
+	for _, m := range manifests {
+		result = append(result, fmt.Sprintf("%s %s/%s", m.Source, m.Object.GetKind(), m.Object.GetName()))
+	}
+	return result
+}
+
+func TestRead(t *testing.T) {
+	var tarData bytes.Buffer
+	tw := tar.NewWriter(&tarData)
+	for _, file := range []struct{ name, content string }{
+		{"app/b.yaml", "apiVersion: v1\nkind: Service\nmetadata:\n  name: web\n"},
+		{"app/README.md", "not a manifest"},
+		{"app/a.json", `{"apiVersion": "v1", "kind": "Secret", "metadata": {"name": "token"}}`},
+	} {
+		_ = tw.WriteHeader(&tar.Header{Name: file.name, Mode: 0o644, Size: int64(len(file.content)), Typeflag: tar.TypeReg})
+		_, _ = tw.Write([]byte(file.content))
+	}
+	_ = tw.Close()
+	var tarGz bytes.Buffer
+	gw := gzip.NewWriter(&tarGz)
+	_, _ = gw.Write(tarData.Bytes())
+	_ = gw.Close()
+	var zipData bytes.Buffer
+	zw := zip.NewWriter(&zipData)
+	for _, name := range []string{"deploy.yml", "__MACOSX/._deploy.yml"} {
+		w, _ := zw.Create(name)
+		_, _ = w.Write([]byte(testManifests))
+	}
+	_ = zw.Close()
+
+	archived := []string{"app/a.json#1 Secret/token", "app/b.yaml#1 Service/web"}
+	cases := []struct {
+		name     string
+		data     []byte
+		expected []string
+		err      string
+	}{
+		{"yaml", []byte(testManifests), []string{"manifest#1 Deployment/web", "manifest#3[0] ConfigMap/web-config", "manifest#3[1] Namespace/shop"}, ""},
+		{"tar", tarData.Bytes(), archived, ""},
+		{"tar.gz", tarGz.Bytes(), archived, ""},
+		{"zip", zipData.Bytes(), []string{"deploy.yml#1 Deployment/web", "deploy.yml#3[0] ConfigMap/web-config", "deploy.yml#3[1] Namespace/shop"}, ""},
+		{"missing kind", []byte("apiVersion: v1\nmetadata:\n  name: web\n"), nil, "manifest#1: apiVersion and kind are required"},
+		{"generate name", []byte("---\n---\napiVersion: v1\nkind: Pod\nmetadata:\n  generateName: web-\n"), nil, "manifest#2: generateName is not supported"},
+		{"invalid yaml", []byte("kind: [\n"), nil, "failed to decode document 1"},
+	}
+	for _, c := range cases {
+		manifests, err := Read(c.data)
+		if c.err != "" {
+			if err == nil || !strings.Contains(err.Error(), c.err) {
+				t.Errorf("%s: Read() error == %v, expected %q", c.name, err, c.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: Read() failed: %v", c.name, err)
+		}
+		if got := sources(manifests); !reflect.DeepEqual(got, c.expected) {
+			t.Errorf("%s: Read() == %v, expected %v", c.name, got, c.expected)
+		}
+	}
+}
+
+func TestSort(t *testing.T) {
+	manifests, err := Decode("manifest", []byte(`
+{"apiVersion": "example.io/v1", "kind": "Widget", "metadata": {"name": "a"}}
+{"apiVersion": "apps/v1", "kind": "Deployment", "metadata": {"name": "b"}}
+{"apiVersion": "rbac.authorization.k8s.io/v1", "kind": "RoleBinding", "metadata": {"name": "c"}}
+{"apiVersion": "apiextensions.k8s.io/v1", "kind": "CustomResourceDefinition", "metadata": {"name": "d"}}
+{"apiVersion": "apps/v1", "kind": "Deployment", "metadata": {"name": "e"}}
+{"apiVersion": "v1", "kind": "Namespace", "metadata": {"name": "f"}}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	Sort(manifests)
+	var names []string
+	for _, m := range manifests {
+		names = append(names, m.Object.GetName())
+	}
+	if expected := []string{"f", "d", "c", "b", "e", "a"}; !reflect.DeepEqual(names, expected) {
+		t.Errorf("Sort() == %v, expected %v", names, expected)
+	}
+}
+
+type testMapper struct {
+	*meta.DefaultRESTMapper
+	invalidated int
+}
+
+func (m *testMapper) MappingForGroupVersionKind(group, version, kind string) (*meta.RESTMapping, error) {
+	return m.RESTMapping(schema.GroupKind{Group: group, Kind: kind}, version)
+}
+
+func (m *testMapper) Invalidate() {
+	m.invalidated++
+}
+
+// newTestClient returns a fake dynamic client whose server-side apply creates or replaces objects,
+// bumping their resource version when they change, and that fails to apply deployments.
+func newTestClient(objects ...runtime.Object) *dynamicfake.FakeDynamicClient {
+	dynamicClient := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), objects...)
+	version := 100
+	dynamicClient.PrependReactor("patch", "*", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		patch := action.(clienttesting.PatchAction)
+		if patch.GetPatchType() != types.ApplyPatchType {
+			return false, nil, nil
+		}
+		if patch.GetResource().Resource == "deployments" {
+			return true, nil, apierrors.NewInvalid(schema.GroupKind{Group: "apps", Kind: "Deployment"}, patch.GetName(), nil)
+		}
+		obj := &unstructured.Unstructured{}
+		if err := json.Unmarshal(patch.GetPatch(), &obj.Object); err != nil {
+			return true, nil, err
+		}
+		tracker := dynamicClient.Tracker()
+		existing, err := tracker.Get(patch.GetResource(), patch.GetNamespace(), patch.GetName())
+		if apierrors.IsNotFound(err) {
+			version++
+			obj.SetResourceVersion(fmt.Sprint(version))
+			return true, obj, tracker.Create(patch.GetResource(), obj, patch.GetNamespace())
+		}
+		current := existing.(*unstructured.Unstructured)
+		obj.SetResourceVersion(current.GetResourceVersion())
+		if reflect.DeepEqual(obj.Object, current.Object) {
+			return true, current, nil
+		}
+		version++
+		obj.SetResourceVersion(fmt.Sprint(version))
+		return true, obj, tracker.Update(patch.GetResource(), obj, patch.GetNamespace())
+	})
+	return dynamicClient
+}
+
+func TestApply(t *testing.T) {
+	mapper := &testMapper{DefaultRESTMapper: meta.NewDefaultRESTMapper(nil)}
+	mapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "Namespace"}, meta.RESTScopeRoot)
+	mapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}, meta.RESTScopeNamespace)
+	mapper.Add(schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}, meta.RESTScopeNamespace)
+	mapper.Add(schema.GroupVersionKind{Group: "apiextensions.k8s.io", Version: "v1", Kind: "CustomResourceDefinition"}, meta.RESTScopeRoot)
+
+	configMap := func(name, value, resourceVersion string) *unstructured.Unstructured {
+		obj := &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata":   map[string]interface{}{"name": name, "namespace": "shop"},
+			"data":       map[string]interface{}{"key": value},
+		}}
+		if resourceVersion != "" {
+			obj.SetResourceVersion(resourceVersion)
+		}
+		return obj
+	}
+	manifests := `
+{"apiVersion": "apps/v1", "kind": "Deployment", "metadata": {"name": "web", "namespace": "shop"}}
+{"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": "changed"}, "data": {"key": "new"}}
+{"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": "same", "namespace": "shop"}, "data": {"key": "old"}}
+{"apiVersion": "v1", "kind": "Namespace", "metadata": {"name": "shop", "namespace": "ignored"}}
+{"apiVersion": "example.io/v1", "kind": "Widget", "metadata": {"name": "gadget"}}
+{"apiVersion": "apiextensions.k8s.io/v1", "kind": "CustomResourceDefinition", "metadata": {"name": "widgets.example.io"},
+ "status": {"conditions": [{"type": "Established", "status": "True"}]}}
+`
+	cases := []struct {
+		name       string
+		opts       ApplyOptions
+		expected   []string
+		failed     int
+		rolledBack bool
+		// shopExists and changed are the state of the cluster after the apply
+		shopExists bool
+		changed    string
+	}{
+		{
+			name: "without rollback",
+			opts: ApplyOptions{Namespace: "shop"},
+			expected: []string{
+				"Namespace shop created", "ConfigMap shop/changed configured", "ConfigMap shop/same unchanged",
+				"CustomResourceDefinition widgets.example.io created", "Deployment shop/web failed", "Widget gadget failed",
+			},
+			failed:     2,
+			shopExists: true,
+			changed:    "new",
+		},
+		{
+			name: "with rollback",
+			opts: ApplyOptions{Namespace: "shop", Rollback: true},
+			expected: []string{
+				"Namespace shop created rolled back", "ConfigMap shop/changed configured rolled back", "ConfigMap shop/same unchanged",
+				"CustomResourceDefinition widgets.example.io created rolled back", "Deployment shop/web failed", "Widget gadget skipped",
+			},
+			failed:     1,
+			rolledBack: true,
+			changed:    "old",
+		},
+	}
+	for _, c := range cases {
+		decoded, err := Decode("manifest", []byte(manifests))
+		if err != nil {
+			t.Fatal(err)
+		}
+		dynamicClient := newTestClient(configMap("changed", "old", "1"), configMap("same", "old", "2"))
+		mapper.invalidated = 0
+		result := Apply(context.Background(), dynamicClient, mapper, decoded, c.opts)
+
+		var got []string
+		for _, object := range result.Objects {
+			line := fmt.Sprintf("%s %s", object, object.Action)
+			if object.RolledBack {
+				line += " rolled back"
+			}
+			if object.RollbackError != "" {
+				t.Errorf("%s: failed to roll back %s: %s", c.name, object, object.RollbackError)
+			}
+			got = append(got, line)
+		}
+		if !reflect.DeepEqual(got, c.expected) {
+			t.Errorf("%s: Apply() ==\n%s\nexpected\n%s", c.name, strings.Join(got, "\n"), strings.Join(c.expected, "\n"))
+		}
+		if result.Failed != c.failed || result.RolledBack != c.rolledBack {
+			t.Errorf("%s: Apply() failed %d, rolled back %v", c.name, result.Failed, result.RolledBack)
+		}
+		if mapper.invalidated != 1 {
+			t.Errorf("%s: the mapper was invalidated %d times after applying a CRD, expected once", c.name, mapper.invalidated)
+		}
+
+		_, err = dynamicClient.Resource(schema.GroupVersionResource{Version: "v1", Resource: "namespaces"}).Get(context.Background(), "shop", metav1.GetOptions{})
+		if exists := err == nil; exists != c.shopExists {
+			t.Errorf("%s: namespace shop exists == %v, expected %v", c.name, exists, c.shopExists)
+		}
+		changed, err := dynamicClient.Resource(schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}).Namespace("shop").Get(context.Background(), "changed", metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("%s: failed to get the changed config map: %v", c.name, err)
+		}
+		if value, _, _ := unstructured.NestedString(changed.Object, "data", "key"); value != c.changed {
+			t.Errorf("%s: changed config map == %q, expected %q", c.name, value, c.changed)
+		}
+	}
+}
@@ -0,0 +1,227 @@
+/*
+Copyright 2024 The Karmada Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pod
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"unicode/utf8"
+
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// MaxLogSearchContext bounds the context lines around matches
+	MaxLogSearchContext = 20
+	// DefaultLogSearchMatches is how many matching lines a search returns unless told otherwise
+	DefaultLogSearchMatches = 500
+	// MaxLogSearchMatches bounds the matching lines a search returns
+	MaxLogSearchMatches = 5000
+	// maxMatchesPerLine bounds the highlighted matches of a single line
+	maxMatchesPerLine = 100
+)
+
+// LogSearchOptions describes a search in a log.
+type LogSearchOptions struct {
+	Query string
+	// Regex makes Query a regular expression (RE2 syntax) rather than a substring
+	Regex         bool
+	CaseSensitive bool
+	// Context is how many lines before and after matching lines are returned with them
+	Context int
+	// MaxMatches stops the search after that many matching lines
+	MaxMatches int
+}
+
+// Compile returns the regular expression searching for the query.
+func (o LogSearchOptions) Compile() (*regexp.Regexp, error) {
+	if o.Query == "" {
+		return nil, fmt.Errorf("the search query is empty")
+	}
+	expr := o.Query
+	if !o.Regex {
+		expr = regexp.QuoteMeta(expr)
+	}
+	if !o.CaseSensitive {
+		expr = "(?i)" + expr
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid regular expression: %v", err)
+	}
+	if re.MatchString("") {
+		return nil, fmt.Errorf("the search query matches every line")
+	}
+	return re, nil
+}
+
+// LogMatchOffset locates a match in a line, in characters from the start of the line.
+type LogMatchOffset struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+// LogSearchLine is a matching or context line of a search result.
+type LogSearchLine struct {
+	// Number is the line number in the searched log, from 1
+	Number int64  `json:"number"`
+	Line   string `json:"line"`
+	// Matches is empty for context lines
+	Matches []LogMatchOffset `json:"matches,omitempty"`
+}
+
+// LogSearchGroup is a run of consecutive lines with at least one match, like a group of grep -C.
+type LogSearchGroup struct {
+	Lines []LogSearchLine `json:"lines"`
+}
+
+// LogSearchResult is the result of a search in a log.
+type LogSearchResult struct {
+	Groups []LogSearchGroup `json:"groups"`
+	// MatchingLines is the number of lines with a match
+	MatchingLines int `json:"matchingLines"`
+	// ScannedLines is the number of lines read, less than the log if the search was truncated
+	ScannedLines int64 `json:"scannedLines"`
+	// Truncated is set when the search stopped at MaxMatches
+	Truncated bool `json:"truncated"`
+}
+
+// SearchContainerLogs searches the log of a container of a pod.
+func SearchContainerLogs(ctx context.Context, client kubernetes.Interface, namespace, name, container string, logOpts LogStreamOptions, opts LogSearchOptions) (*LogSearchResult, error) {
+	re, err := opts.Compile()
+	if err != nil {
+		return nil, err
+	}
+	logOpts.Follow = false
+	stream, err := OpenContainerLogs(ctx, client, namespace, name, container, logOpts)
+	if err != nil {
+		return nil, err
+	}
+	defer stream.Close()
+	return searchLog(stream, re, opts)
+}
+
+// matchOffsets returns the character offsets of the matches of re in line.
+func matchOffsets(re *regexp.Regexp, line string) []LogMatchOffset {
+	indexes := re.FindAllStringIndex(line, maxMatchesPerLine)
+	if len(indexes) == 0 {
+		return nil
+	}
+	offsets := make([]LogMatchOffset, 0, len(indexes))
+	position, characters := 0, 0
+	for _, index := range indexes {
+		characters += utf8.RuneCountInString(line[position:index[0]])
+		start := characters
+		characters += utf8.RuneCountInString(line[index[0]:index[1]])
+		position = index[1]
+		offsets = append(offsets, LogMatchOffset{Start: start, End: characters})
+	}
+	return offsets
+}
+
+// searchLog reads a log line by line, keeping only the context lines that may precede the next
+// match, so that logs of any size are searched in bounded memory.
+func searchLog(r io.Reader, re *regexp.Regexp, opts LogSearchOptions) (*LogSearchResult, error) {
+	contextLines := min(max(opts.Context, 0), MaxLogSearchContext)
+	maxMatches := opts.MaxMatches
+	if maxMatches <= 0 || maxMatches > MaxLogSearchMatches {
+		maxMatches = DefaultLogSearchMatches
+	}
+
+	result := &LogSearchResult{Groups: []LogSearchGroup{}}
+	var before []LogSearchLine
+	var group *LogSearchGroup
+	after := 0
+	closeGroup := func() {
+		if group != nil {
+			result.Groups = append(result.Groups, *group)
+			group = nil
+		}
+	}
+
+	reader := bufio.NewReaderSize(r, 64*1024)
+	for number := int64(1); ; number++ {
+		text, err := readLogLine(reader)
+		if errors.Is(err, io.EOF) && text == "" {
+			break
+		}
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, err
+		}
+		result.ScannedLines = number
+		line := LogSearchLine{Number: number, Line: text}
+
+		var offsets []LogMatchOffset
+		if !result.Truncated {
+			offsets = matchOffsets(re, text)
+		}
+		switch {
+		case offsets != nil:
+			line.Matches = offsets
+			result.MatchingLines++
+			if group == nil {
+				first := number
+				if len(before) > 0 {
+					first = before[0].Number
+				}
+				// Continue the previous group if the context lines join them
+				if n := len(result.Groups); n > 0 && lastLine(result.Groups[n-1]) == first-1 {
+					previous := result.Groups[n-1]
+					result.Groups = result.Groups[:n-1]
+					group = &previous
+				} else {
+					group = &LogSearchGroup{}
+				}
+				group.Lines = append(group.Lines, before...)
+				before = nil
+			}
+			group.Lines = append(group.Lines, line)
+			after = contextLines
+			if result.MatchingLines >= maxMatches {
+				result.Truncated = true
+			}
+		case group != nil && after > 0:
+			group.Lines = append(group.Lines, line)
+			after--
+		case contextLines > 0:
+			before = append(before, line)
+			if len(before) > contextLines {
+				before = before[1:]
+			}
+		}
+		if group != nil && after == 0 {
+			closeGroup()
+		}
+		if result.Truncated && group == nil {
+			break
+		}
+		if errors.Is(err, io.EOF) {
+			break
+		}
+	}
+	closeGroup()
+	return result, nil
+}
+
+// lastLine returns the number of the last line of a group.
+func lastLine(group LogSearchGroup) int64 {
+	return group.Lines[len(group.Lines)-1].Number
+}
@@ -0,0 +1,106 @@
+/*
+Copyright 2024 The Karmada Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pod
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLogSearchOptionsCompile(t *testing.T) {
+	cases := []struct {
+		opts    LogSearchOptions
+		line    string
+		matches bool
+		valid   bool
+	}{
+		{LogSearchOptions{Query: "Error"}, "an error occurred", true, true},
+		{LogSearchOptions{Query: "Error", CaseSensitive: true}, "an error occurred", false, true},
+		{LogSearchOptions{Query: "a.c"}, "abc", false, true},
+		{LogSearchOptions{Query: "a.c", Regex: true}, "abc", true, true},
+		{LogSearchOptions{Query: "status=5\\d\\d", Regex: true}, "GET / status=503", true, true},
+		{LogSearchOptions{Query: "("}, "(", true, true},
+		{LogSearchOptions{Query: "(", Regex: true}, "", false, false},
+		{LogSearchOptions{Query: "x*", Regex: true}, "", false, false},
+		{LogSearchOptions{}, "", false, false},
+	}
+	for _, c := range cases {
+		re, err := c.opts.Compile()
+		if (err == nil) != c.valid {
+			t.Errorf("Compile(%+v) failed: %v, expected valid %t", c.opts, err, c.valid)
+			continue
+		}
+		if re != nil && re.MatchString(c.line) != c.matches {
+			t.Errorf("Compile(%+v) matches %q: %t, expected %t", c.opts, c.line, !c.matches, c.matches)
+		}
+	}
+}
+
+func TestSearchLog(t *testing.T) {
+	log := "a\nb\nerror 1\nc\nd\ne\nerror 2\nf\n"
+	cases := []struct {
+		opts      LogSearchOptions
+		groups    [][]int64
+		scanned   int64
+		truncated bool
+	}{
+		{LogSearchOptions{Query: "error"}, [][]int64{{3}, {7}}, 8, false},
+		{LogSearchOptions{Query: "error", Context: 1}, [][]int64{{2, 3, 4}, {6, 7, 8}}, 8, false},
+		// The context lines of both matches join into one group
+		{LogSearchOptions{Query: "error", Context: 2}, [][]int64{{1, 2, 3, 4, 5, 6, 7, 8}}, 8, false},
+		{LogSearchOptions{Query: "error", Context: 1, MaxMatches: 1}, [][]int64{{2, 3, 4}}, 4, true},
+		{LogSearchOptions{Query: "missing", Context: 1}, [][]int64{}, 8, false},
+	}
+	for _, c := range cases {
+		re, err := c.opts.Compile()
+		if err != nil {
+			t.Fatalf("Compile(%+v) failed: %v", c.opts, err)
+		}
+		result, err := searchLog(strings.NewReader(log), re, c.opts)
+		if err != nil {
+			t.Fatalf("searchLog(%+v) failed: %v", c.opts, err)
+		}
+		groups := [][]int64{}
+		for _, group := range result.Groups {
+			var numbers []int64
+			for _, line := range group.Lines {
+				numbers = append(numbers, line.Number)
+			}
+			groups = append(groups, numbers)
+		}
+		if !reflect.DeepEqual(groups, c.groups) || result.ScannedLines != c.scanned || result.Truncated != c.truncated {
+			t.Errorf("searchLog(%+v) == %v, scanned %d, truncated %t, expected %v, scanned %d, truncated %t",
+				c.opts, groups, result.ScannedLines, result.Truncated, c.groups, c.scanned, c.truncated)
+		}
+	}
+}
+
+func TestMatchOffsets(t *testing.T) {
+	re, err := LogSearchOptions{Query: "error"}.Compile()
+	if err != nil {
+		t.Fatalf("Compile() failed: %v", err)
+	}
+	offsets := matchOffsets(re, "café error, ERROR")
+	expected := []LogMatchOffset{{Start: 5, End: 10}, {Start: 12, End: 17}}
+	if !reflect.DeepEqual(offsets, expected) {
+		t.Errorf("matchOffsets() == %v, expected %v", offsets, expected)
+	}
+	if offsets := matchOffsets(re, "ok"); offsets != nil {
+		t.Errorf("matchOffsets() == %v, expected none", offsets)
+	}
+}
@@ -0,0 +1,202 @@
+/*
+Copyright 2024 The Karmada Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pod
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// maxLogLineLength bounds the lines of a log stream, longer lines are cut
+const maxLogLineLength = 64 * 1024
+
+// LogStreamOptions selects the containers and the part of the log a stream returns.
+type LogStreamOptions struct {
+	// Container is the container to stream, the first one if empty. Ignored with AllContainers.
+	Container     string
+	AllContainers bool
+	Previous      bool
+	// Follow keeps the stream open for new lines, as kubectl logs -f
+	Follow       bool
+	Timestamps   bool
+	SinceSeconds *int64
+	TailLines    *int64
+}
+
+// LogSource identifies the container a log line comes from.
+type LogSource struct {
+	Cluster   string `json:"cluster"`
+	Namespace string `json:"namespace"`
+	Pod       string `json:"pod"`
+	Container string `json:"container"`
+}
+
+// String returns the source as cluster/namespace/pod/container.
+func (s LogSource) String() string {
+	return s.Cluster + "/" + s.Namespace + "/" + s.Pod + "/" + s.Container
+}
+
+// LogLine is a line of a container log, tagged with its source. A line with Error set reports
+// that the stream of its source failed instead.
+type LogLine struct {
+	LogSource `json:",inline"`
+	// Timestamp is the RFC 3339 time the line was logged at, set if timestamps were requested
+	Timestamp string `json:"timestamp,omitempty"`
+	Line      string `json:"line"`
+	Error     string `json:"error,omitempty"`
+}
+
+// LogContainers returns the containers of a pod to stream.
+func LogContainers(pod *v1.Pod, opts LogStreamOptions) ([]string, error) {
+	if opts.AllContainers {
+		var containers []string
+		for _, container := range pod.Spec.InitContainers {
+			containers = append(containers, container.Name)
+		}
+		for _, container := range pod.Spec.Containers {
+			containers = append(containers, container.Name)
+		}
+		return containers, nil
+	}
+	if opts.Container == "" {
+		if len(pod.Spec.Containers) == 0 {
+			return nil, fmt.Errorf("pod %s/%s has no containers", pod.Namespace, pod.Name)
+		}
+		return []string{pod.Spec.Containers[0].Name}, nil
+	}
+	for _, containers := range [][]v1.Container{pod.Spec.InitContainers, pod.Spec.Containers, ephemeralContainers(pod)} {
+		for _, container := range containers {
+			if container.Name == opts.Container {
+				return []string{opts.Container}, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("container %s not found in pod %s/%s", opts.Container, pod.Namespace, pod.Name)
+}
+
+func ephemeralContainers(pod *v1.Pod) []v1.Container {
+	var containers []v1.Container
+	for _, container := range pod.Spec.EphemeralContainers {
+		containers = append(containers, v1.Container{Name: container.Name})
+	}
+	return containers
+}
+
+// StreamPodLogs sends the log lines of the selected containers of a pod to lines, as they
+// arrive. It returns when every container stream ended, which with Follow is when the containers
+// stop or ctx is done. The streams of containers that fail are reported as error lines.
+func StreamPodLogs(ctx context.Context, client kubernetes.Interface, cluster string, pod *v1.Pod, opts LogStreamOptions, lines chan<- LogLine) error {
+	containers, err := LogContainers(pod, opts)
+	if err != nil {
+		return err
+	}
+
+	var wg sync.WaitGroup
+	for _, container := range containers {
+		source := LogSource{Cluster: cluster, Namespace: pod.Namespace, Pod: pod.Name, Container: container}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := streamContainerLogs(ctx, client, source, opts, lines); err != nil && ctx.Err() == nil {
+				select {
+				case lines <- LogLine{LogSource: source, Error: err.Error()}:
+				case <-ctx.Done():
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	return nil
+}
+
+// OpenContainerLogs returns the raw log of a container of a pod. opts.Container is ignored in
+// favor of container.
+func OpenContainerLogs(ctx context.Context, client kubernetes.Interface, namespace, name, container string, opts LogStreamOptions) (io.ReadCloser, error) {
+	req := client.CoreV1().Pods(namespace).GetLogs(name, &v1.PodLogOptions{
+		Container:    container,
+		Follow:       opts.Follow,
+		Previous:     opts.Previous,
+		Timestamps:   opts.Timestamps,
+		SinceSeconds: opts.SinceSeconds,
+		TailLines:    opts.TailLines,
+	})
+	return req.Stream(ctx)
+}
+
+// streamContainerLogs sends the log lines of a container to lines.
+func streamContainerLogs(ctx context.Context, client kubernetes.Interface, source LogSource, opts LogStreamOptions, lines chan<- LogLine) error {
+	stream, err := OpenContainerLogs(ctx, client, source.Namespace, source.Pod, source.Container, opts)
+	if err != nil {
+		return err
+	}
+	defer stream.Close()
+
+	reader := bufio.NewReaderSize(stream, 4096)
+	for {
+		raw, err := readLogLine(reader)
+		if raw != "" || err == nil {
+			select {
+			case lines <- parseLogLine(source, raw, opts.Timestamps):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// readLogLine reads a line without its line ending, cutting it at maxLogLineLength.
+func readLogLine(reader *bufio.Reader) (string, error) {
+	var line []byte
+	for {
+		chunk, isPrefix, err := reader.ReadLine()
+		if len(line) < maxLogLineLength {
+			line = append(line, chunk[:min(len(chunk), maxLogLineLength-len(line))]...)
+		}
+		if err != nil || !isPrefix {
+			return string(line), err
+		}
+	}
+}
+
+// parseLogLine splits the timestamp the API server prefixes lines with from the line.
+func parseLogLine(source LogSource, raw string, timestamps bool) LogLine {
+	line := LogLine{LogSource: source, Line: raw}
+	if !timestamps {
+		return line
+	}
+	if timestamp, rest, found := strings.Cut(raw, " "); found {
+		if _, err := time.Parse(time.RFC3339Nano, timestamp); err == nil {
+			line.Timestamp, line.Line = timestamp, rest
+		}
+	}
+	return line
+}
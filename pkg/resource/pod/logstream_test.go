@@ -0,0 +1,121 @@
+/*
+Copyright 2024 The Karmada Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pod
+
+import (
+	"bufio"
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func testPod() *v1.Pod {
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "web-0", Namespace: "default"},
+		Spec: v1.PodSpec{
+			InitContainers: []v1.Container{{Name: "migrate"}},
+			Containers:     []v1.Container{{Name: "web"}, {Name: "sidecar"}},
+		},
+	}
+}
+
+func TestLogContainers(t *testing.T) {
+	cases := []struct {
+		opts       LogStreamOptions
+		containers []string
+		valid      bool
+	}{
+		{LogStreamOptions{}, []string{"web"}, true},
+		{LogStreamOptions{Container: "sidecar"}, []string{"sidecar"}, true},
+		{LogStreamOptions{Container: "migrate"}, []string{"migrate"}, true},
+		{LogStreamOptions{Container: "missing"}, nil, false},
+		{LogStreamOptions{Container: "missing", AllContainers: true}, []string{"migrate", "web", "sidecar"}, true},
+	}
+	for _, c := range cases {
+		containers, err := LogContainers(testPod(), c.opts)
+		if (err == nil) != c.valid || !slices.Equal(containers, c.containers) {
+			t.Errorf("LogContainers(%+v) == %v, %v, expected %v", c.opts, containers, err, c.containers)
+		}
+	}
+}
+
+func TestStreamPodLogs(t *testing.T) {
+	// The fake clientset returns "fake logs" for every container
+	client := fake.NewSimpleClientset()
+	lines := make(chan LogLine, 10)
+	err := StreamPodLogs(context.Background(), client, "member1", testPod(), LogStreamOptions{AllContainers: true}, lines)
+	close(lines)
+	if err != nil {
+		t.Fatalf("StreamPodLogs() failed: %v", err)
+	}
+
+	var sources []string
+	for line := range lines {
+		if line.Line != "fake logs" || line.Error != "" {
+			t.Errorf("unexpected line %+v", line)
+		}
+		sources = append(sources, line.String())
+	}
+	slices.Sort(sources)
+	expected := []string{"member1/default/web-0/migrate", "member1/default/web-0/sidecar", "member1/default/web-0/web"}
+	if !slices.Equal(sources, expected) {
+		t.Errorf("lines came from %v, expected %v", sources, expected)
+	}
+}
+
+func TestParseLogLine(t *testing.T) {
+	source := LogSource{Cluster: "member1", Namespace: "default", Pod: "web-0", Container: "web"}
+	cases := []struct {
+		raw        string
+		timestamps bool
+		timestamp  string
+		line       string
+	}{
+		{"2024-05-15T10:00:00.123456789Z GET /healthz 200", true, "2024-05-15T10:00:00.123456789Z", "GET /healthz 200"},
+		{"2024-05-15T10:00:00.123456789Z GET /healthz 200", false, "", "2024-05-15T10:00:00.123456789Z GET /healthz 200"},
+		{"not a timestamp", true, "", "not a timestamp"},
+		{"", true, "", ""},
+	}
+	for _, c := range cases {
+		line := parseLogLine(source, c.raw, c.timestamps)
+		if line.Timestamp != c.timestamp || line.Line != c.line || line.LogSource != source {
+			t.Errorf("parseLogLine(%q, %t) == %+v, expected timestamp %q and line %q", c.raw, c.timestamps, line, c.timestamp, c.line)
+		}
+	}
+}
+
+func TestReadLogLine(t *testing.T) {
+	long := strings.Repeat("x", maxLogLineLength+100)
+	reader := bufio.NewReaderSize(strings.NewReader("first\r\n"+long+"\n\nlast"), 16)
+
+	var lines []string
+	for {
+		line, err := readLogLine(reader)
+		if err != nil {
+			break
+		}
+		lines = append(lines, line)
+	}
+	if len(lines) != 4 || lines[0] != "first" || len(lines[1]) != maxLogLineLength || lines[2] != "" || lines[3] != "last" {
+		t.Errorf("readLogLine() read %d lines: %q...", len(lines), lines[0])
+	}
+}
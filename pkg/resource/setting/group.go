@@ -0,0 +1,345 @@
+/*
+Copyright 2024 The Karmada Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package setting
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/klog/v2"
+
+	v1 "github.com/karmada-io/dashboard/cmd/api/app/types/api/v1"
+	"github.com/karmada-io/dashboard/pkg/auth/fga"
+	"github.com/karmada-io/dashboard/pkg/etcd"
+	"github.com/karmada-io/dashboard/pkg/resource/cluster"
+)
+
+// Group membership is stored twice: in etcd, where it can be listed and edited, and as
+// group#member tuples in OpenFGA, where it lets cluster roles granted to the group apply
+// to every member. The functions below keep both in sync.
+
+// ListGroups retrieves all groups together with the cluster permissions granted to them
+func ListGroups(ctx context.Context) ([]v1.Group, error) {
+	groupManager, fgaClient, err := getGroupBackends()
+	if err != nil {
+		return nil, err
+	}
+
+	groups, err := groupManager.ListGroups(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]v1.Group, 0, len(groups))
+	for _, group := range groups {
+		g, err := toGroup(ctx, fgaClient, group)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, *g)
+	}
+	slices.SortFunc(result, func(a, b v1.Group) int { return strings.Compare(a.Name, b.Name) })
+	return result, nil
+}
+
+// GetGroup retrieves a group together with the cluster permissions granted to it
+func GetGroup(ctx context.Context, name string) (*v1.Group, error) {
+	groupManager, fgaClient, err := getGroupBackends()
+	if err != nil {
+		return nil, err
+	}
+
+	group, err := groupManager.GetGroup(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	return toGroup(ctx, fgaClient, group)
+}
+
+// CreateGroup creates a group, adds its members and grants its cluster permissions
+func CreateGroup(ctx context.Context, request v1.CreateGroupRequest) (*v1.Group, error) {
+	if errs := validation.IsDNS1123Subdomain(request.Name); len(errs) > 0 {
+		return nil, fmt.Errorf("invalid group name %q: %s", request.Name, strings.Join(errs, ", "))
+	}
+	for _, perm := range request.ClusterPermissions {
+		if err := cluster.ValidateClusterPermission(perm); err != nil {
+			return nil, err
+		}
+	}
+
+	groupManager, fgaClient, err := getGroupBackends()
+	if err != nil {
+		return nil, err
+	}
+	if err := ensureUsersExist(ctx, request.Members); err != nil {
+		return nil, err
+	}
+
+	if _, err := groupManager.CreateGroup(ctx, request.Name, request.Description); err != nil {
+		return nil, err
+	}
+	klog.InfoS("Group created", "group", request.Name)
+
+	if err := addGroupMembers(ctx, groupManager, fgaClient, request.Name, request.Members); err != nil {
+		return nil, err
+	}
+	for _, perm := range request.ClusterPermissions {
+		if err := cluster.GrantClusterPermission(ctx, fgaClient, fga.GroupSubject(request.Name), perm); err != nil {
+			return nil, err
+		}
+	}
+
+	return GetGroup(ctx, request.Name)
+}
+
+// UpdateGroup updates the description of a group and replaces its members and cluster
+// permissions when they are given
+func UpdateGroup(ctx context.Context, name string, request v1.UpdateGroupRequest) (*v1.Group, error) {
+	for _, perm := range request.ClusterPermissions {
+		if err := cluster.ValidateClusterPermission(perm); err != nil {
+			return nil, err
+		}
+	}
+
+	groupManager, fgaClient, err := getGroupBackends()
+	if err != nil {
+		return nil, err
+	}
+
+	group, err := groupManager.GetGroup(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	if request.Description != nil && *request.Description != group.Description {
+		group.Description = *request.Description
+		if err := groupManager.UpdateGroup(ctx, group); err != nil {
+			return nil, err
+		}
+	}
+
+	if request.Members != nil {
+		if err := ensureUsersExist(ctx, request.Members); err != nil {
+			return nil, err
+		}
+		for _, member := range group.Members {
+			if slices.Contains(request.Members, member) {
+				continue
+			}
+			if err := RemoveGroupMember(ctx, name, member); err != nil {
+				return nil, err
+			}
+		}
+		if err := addGroupMembers(ctx, groupManager, fgaClient, name, request.Members); err != nil {
+			return nil, err
+		}
+	}
+
+	if request.ClusterPermissions != nil {
+		subject := fga.GroupSubject(name)
+		current, err := cluster.ListClusterPermissions(ctx, fgaClient, subject)
+		if err != nil {
+			return nil, err
+		}
+		for _, perm := range current {
+			wanted := slices.ContainsFunc(request.ClusterPermissions, func(p v1.ClusterPermission) bool { return p.Cluster == perm.Cluster })
+			if wanted {
+				continue
+			}
+			if err := cluster.RevokeClusterPermission(ctx, fgaClient, subject, perm.Cluster); err != nil {
+				return nil, err
+			}
+		}
+		for _, perm := range request.ClusterPermissions {
+			if err := cluster.ReplaceClusterPermission(ctx, fgaClient, subject, perm); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	klog.InfoS("Group updated", "group", name)
+	return GetGroup(ctx, name)
+}
+
+// DeleteGroup revokes every permission granted to a group, removes its members and deletes it
+func DeleteGroup(ctx context.Context, name string) error {
+	groupManager, fgaClient, err := getGroupBackends()
+	if err != nil {
+		return err
+	}
+
+	group, err := groupManager.GetGroup(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	subject := fga.GroupSubject(name)
+	perms, err := cluster.ListClusterPermissions(ctx, fgaClient, subject)
+	if err != nil {
+		return err
+	}
+	for _, perm := range perms {
+		if err := cluster.RevokeClusterPermission(ctx, fgaClient, subject, perm.Cluster); err != nil {
+			return err
+		}
+	}
+	for _, member := range group.Members {
+		if err := fgaClient.DeleteTuple(ctx, member, fga.RelationMember, fga.TypeGroup, name); err != nil {
+			return fmt.Errorf("failed to remove %s from group %s: %w", member, name, err)
+		}
+	}
+
+	if err := groupManager.DeleteGroup(ctx, name); err != nil {
+		return err
+	}
+	klog.InfoS("Group deleted", "group", name)
+	return nil
+}
+
+// AddGroupMembers adds users to a group
+func AddGroupMembers(ctx context.Context, name string, members []string) (*v1.Group, error) {
+	groupManager, fgaClient, err := getGroupBackends()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := groupManager.GetGroup(ctx, name); err != nil {
+		return nil, err
+	}
+	if err := ensureUsersExist(ctx, members); err != nil {
+		return nil, err
+	}
+	if err := addGroupMembers(ctx, groupManager, fgaClient, name, members); err != nil {
+		return nil, err
+	}
+	return GetGroup(ctx, name)
+}
+
+// RemoveGroupMember removes a user from a group
+func RemoveGroupMember(ctx context.Context, name, username string) error {
+	groupManager, fgaClient, err := getGroupBackends()
+	if err != nil {
+		return err
+	}
+
+	group, err := groupManager.GetGroup(ctx, name)
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(group.Members, username) {
+		return fmt.Errorf("user %s is not a member of group %s", username, name)
+	}
+
+	if err := fgaClient.DeleteTuple(ctx, username, fga.RelationMember, fga.TypeGroup, name); err != nil {
+		return fmt.Errorf("failed to remove %s from group %s: %w", username, name, err)
+	}
+	if err := groupManager.RemoveMember(ctx, name, username); err != nil {
+		return err
+	}
+	klog.InfoS("Removed group member", "group", name, "username", username)
+	return nil
+}
+
+// removeUserFromGroups removes a user from every group it is a member of
+func removeUserFromGroups(ctx context.Context, username string) error {
+	groupManager, _, err := getGroupBackends()
+	if err != nil {
+		return err
+	}
+	groups, err := groupManager.ListGroupsForUser(ctx, username)
+	if err != nil {
+		return err
+	}
+	for _, group := range groups {
+		if err := RemoveGroupMember(ctx, group.Name, username); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addGroupMembers writes the membership of users that are not members yet, OpenFGA first so that
+// a failed write leaves etcd unchanged
+func addGroupMembers(ctx context.Context, groupManager *etcd.GroupManager, fgaClient fga.Client, name string, members []string) error {
+	group, err := groupManager.GetGroup(ctx, name)
+	if err != nil {
+		return err
+	}
+	for _, member := range members {
+		if slices.Contains(group.Members, member) {
+			continue
+		}
+		if err := fgaClient.WriteTuple(ctx, member, fga.RelationMember, fga.TypeGroup, name); err != nil {
+			return fmt.Errorf("failed to add %s to group %s: %w", member, name, err)
+		}
+		if err := groupManager.AddMember(ctx, name, member); err != nil {
+			return err
+		}
+		group.Members = append(group.Members, member)
+		klog.InfoS("Added group member", "group", name, "username", member)
+	}
+	return nil
+}
+
+// ensureUsersExist returns an error naming the first user that does not exist
+func ensureUsersExist(ctx context.Context, usernames []string) error {
+	etcdClient, err := etcd.GetEtcdClient(nil)
+	if err != nil || etcdClient == nil {
+		return fmt.Errorf("failed to get etcd client: %w", err)
+	}
+	userManager := etcd.NewUserManager(etcdClient)
+	for _, username := range usernames {
+		exists, err := userManager.UserExists(ctx, username)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return fmt.Errorf("user %s not found", username)
+		}
+	}
+	return nil
+}
+
+// toGroup converts a stored group to its API form
+func toGroup(ctx context.Context, fgaClient fga.Client, group *etcd.Group) (*v1.Group, error) {
+	perms, err := cluster.ListClusterPermissions(ctx, fgaClient, fga.GroupSubject(group.Name))
+	if err != nil {
+		return nil, err
+	}
+	return &v1.Group{
+		Name:               group.Name,
+		Description:        group.Description,
+		Members:            group.Members,
+		ClusterPermissions: perms,
+		CreatedAt:          group.CreatedAt,
+		UpdatedAt:          group.UpdatedAt,
+	}, nil
+}
+
+// getGroupBackends returns the etcd group manager and the OpenFGA client groups are stored in
+func getGroupBackends() (*etcd.GroupManager, fga.Client, error) {
+	etcdClient, err := etcd.GetEtcdClient(nil)
+	if err != nil || etcdClient == nil {
+		return nil, nil, fmt.Errorf("failed to get etcd client: %w", err)
+	}
+	if fga.FGAService == nil || fga.FGAService.GetClient() == nil {
+		return nil, nil, fmt.Errorf("OpenFGA service not initialized")
+	}
+	return etcd.NewGroupManager(etcdClient), fga.FGAService.GetClient(), nil
+}
@@ -0,0 +1,177 @@
+/*
+Copyright 2024 The Karmada Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package setting
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	v1 "github.com/karmada-io/dashboard/cmd/api/app/types/api/v1"
+	"github.com/karmada-io/dashboard/pkg/auth/fga"
+	"github.com/karmada-io/dashboard/pkg/etcd"
+	"github.com/karmada-io/dashboard/pkg/etcd/etcdtest"
+)
+
+// useGroupBackends stores groups in memory for the test and creates usernames.
+func useGroupBackends(t *testing.T, usernames ...string) *fga.FakeClient {
+	t.Helper()
+	etcdClient := etcdtest.NewClient()
+	fgaClient := fga.NewFakeClient()
+	etcd.SetEtcdClient(etcdClient)
+	fga.FGAService = fga.NewServiceWithClient(fgaClient)
+	t.Cleanup(func() {
+		etcd.SetEtcdClient(nil)
+		fga.FGAService = nil
+	})
+
+	userManager := etcd.NewUserManager(etcdClient)
+	for _, username := range usernames {
+		if err := userManager.CreateUser(context.Background(), username, "password", username+"@example.com", "user"); err != nil {
+			t.Fatalf("CreateUser %s: %v", username, err)
+		}
+	}
+	return fgaClient
+}
+
+func TestGroupCRUD(t *testing.T) {
+	ctx := context.Background()
+	fgaClient := useGroupBackends(t, "alice", "bob")
+
+	invalid := []v1.CreateGroupRequest{
+		{Name: "Site_Reliability"},
+		{Name: "sre", Members: []string{"mallory"}},
+		{Name: "sre", ClusterPermissions: []v1.ClusterPermission{{Cluster: "member1", Roles: []string{"root"}}}},
+	}
+	for _, request := range invalid {
+		if _, err := CreateGroup(ctx, request); err == nil {
+			t.Errorf("CreateGroup %+v: expected an error", request)
+		}
+	}
+	if groups, err := ListGroups(ctx); err != nil || len(groups) != 0 {
+		t.Fatalf("ListGroups after invalid creates: got (%v, %v)", groups, err)
+	}
+
+	group, err := CreateGroup(ctx, v1.CreateGroupRequest{
+		Name:               "sre",
+		Description:        "on call",
+		Members:            []string{"alice"},
+		ClusterPermissions: []v1.ClusterPermission{{Cluster: "member1", Roles: []string{fga.RelationViewer}}},
+	})
+	if err != nil {
+		t.Fatalf("CreateGroup: %v", err)
+	}
+	if !slices.Equal(group.Members, []string{"alice"}) || len(group.ClusterPermissions) != 1 {
+		t.Errorf("CreateGroup: got %+v", group)
+	}
+	if _, err := CreateGroup(ctx, v1.CreateGroupRequest{Name: "sre"}); err == nil {
+		t.Errorf("CreateGroup of an existing group succeeded")
+	}
+	if ok, _ := fga.HasClusterAccess(ctx, fgaClient, "alice", "member1"); !ok {
+		t.Errorf("alice has no access to member1 through group sre")
+	}
+
+	description := "site reliability"
+	group, err = UpdateGroup(ctx, "sre", v1.UpdateGroupRequest{
+		Description:        &description,
+		Members:            []string{"bob"},
+		ClusterPermissions: []v1.ClusterPermission{{Cluster: "member2", Roles: []string{fga.RelationEditor}}},
+	})
+	if err != nil {
+		t.Fatalf("UpdateGroup: %v", err)
+	}
+	if group.Description != description || !slices.Equal(group.Members, []string{"bob"}) ||
+		len(group.ClusterPermissions) != 1 || group.ClusterPermissions[0].Cluster != "member2" {
+		t.Errorf("UpdateGroup: got %+v", group)
+	}
+	if ok, _ := fga.HasClusterAccess(ctx, fgaClient, "alice", "member1"); ok {
+		t.Errorf("alice kept access to member1 after leaving group sre")
+	}
+	if ok, _ := fga.HasClusterWriteAccess(ctx, fgaClient, "bob", "member2"); !ok {
+		t.Errorf("bob has no write access to member2 through group sre")
+	}
+
+	groups, err := ListGroups(ctx)
+	if err != nil || len(groups) != 1 || groups[0].Name != "sre" {
+		t.Errorf("ListGroups: got (%v, %v)", groups, err)
+	}
+
+	if err := DeleteGroup(ctx, "sre"); err != nil {
+		t.Fatalf("DeleteGroup: %v", err)
+	}
+	if _, err := GetGroup(ctx, "sre"); err == nil {
+		t.Errorf("GetGroup of a deleted group succeeded")
+	}
+	if tuples, _ := fgaClient.ReadTuples(ctx, "", "", "", ""); len(tuples) != 0 {
+		t.Errorf("DeleteGroup left tuples behind: %v", tuples)
+	}
+}
+
+func TestGroupMembers(t *testing.T) {
+	ctx := context.Background()
+	fgaClient := useGroupBackends(t, "alice", "bob", "carol")
+	if _, err := CreateGroup(ctx, v1.CreateGroupRequest{Name: "sre", Members: []string{"alice"}}); err != nil {
+		t.Fatalf("CreateGroup: %v", err)
+	}
+
+	cases := []struct {
+		add             []string
+		remove          string
+		expectedMembers []string
+		expectErr       bool
+	}{
+		{add: []string{"bob", "alice"}, expectedMembers: []string{"alice", "bob"}},
+		{add: []string{"carol", "mallory"}, expectedMembers: []string{"alice", "bob"}, expectErr: true},
+		{remove: "alice", expectedMembers: []string{"bob"}},
+		{remove: "alice", expectedMembers: []string{"bob"}, expectErr: true},
+		{add: []string{"carol"}, expectedMembers: []string{"bob", "carol"}},
+	}
+	for _, c := range cases {
+		var err error
+		if c.add != nil {
+			_, err = AddGroupMembers(ctx, "sre", c.add)
+		} else {
+			err = RemoveGroupMember(ctx, "sre", c.remove)
+		}
+		if (err != nil) != c.expectErr {
+			t.Errorf("add %v remove %q: got error %v, expected error %v", c.add, c.remove, err, c.expectErr)
+		}
+
+		group, err := GetGroup(ctx, "sre")
+		if err != nil {
+			t.Fatalf("GetGroup: %v", err)
+		}
+		if !slices.Equal(group.Members, c.expectedMembers) {
+			t.Errorf("add %v remove %q: got members %v, expected %v", c.add, c.remove, group.Members, c.expectedMembers)
+		}
+		tuples, err := fgaClient.ReadTuples(ctx, "", fga.RelationMember, fga.TypeGroup, "sre")
+		if err != nil {
+			t.Fatalf("ReadTuples: %v", err)
+		}
+		var members []string
+		for _, tuple := range tuples {
+			members = append(members, tuple.User)
+		}
+		if !slices.Equal(members, c.expectedMembers) {
+			t.Errorf("add %v remove %q: got member tuples for %v, expected %v", c.add, c.remove, members, c.expectedMembers)
+		}
+	}
+
+	if _, err := AddGroupMembers(ctx, "missing", []string{"alice"}); err == nil {
+		t.Errorf("AddGroupMembers to a missing group succeeded")
+	}
+}
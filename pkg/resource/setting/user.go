@@ -28,6 +28,7 @@ import (
 	"github.com/karmada-io/dashboard/pkg/auth"
 	"github.com/karmada-io/dashboard/pkg/etcd"
 	"github.com/karmada-io/dashboard/pkg/auth/fga"
+	"github.com/karmada-io/dashboard/pkg/resource/cluster"
 )
 
 const (
@@ -115,6 +116,9 @@ func CreateUserSetting(ctx context.Context, userSetting v1.UserSetting) error {
 	if password == "" {
 		return fmt.Errorf("password is required")
 	}
+	if err := auth.GetPasswordPolicy().Validate(userSetting.Username, password); err != nil {
+		return err
+	}
 
 	// Get role from preferences or default to basic_user
 	role, ok := userSetting.Preferences["role"]
@@ -172,13 +176,7 @@ func CreateUserSetting(ctx context.Context, userSetting v1.UserSetting) error {
 			needsUpdate = true
 		}
 
-		// Update password
-		err = userManager.UpdatePassword(ctx, userSetting.Username, password)
-		if err != nil {
-			return fmt.Errorf("failed to update password: %w", err)
-		}
-
-		// Update user if needed
+		// Update user if needed, before the password so that the stored user is not stale
 		if needsUpdate {
 			err = userManager.UpdateUser(ctx, user)
 			if err != nil {
@@ -186,6 +184,12 @@ func CreateUserSetting(ctx context.Context, userSetting v1.UserSetting) error {
 			}
 			klog.InfoS("User updated", "username", userSetting.Username)
 		}
+
+		// Update password
+		err = auth.SetPassword(ctx, userSetting.Username, password)
+		if err != nil {
+			return fmt.Errorf("failed to update password: %w", err)
+		}
 	}
 
 	// Process cluster permissions with OpenFGA (if available)
@@ -199,24 +203,12 @@ func CreateUserSetting(ctx context.Context, userSetting v1.UserSetting) error {
 			
 			// For each cluster permission
 			for _, clusterPerm := range userSetting.ClusterPermissions {
-				clusterName := clusterPerm.Cluster
-				
-				// For each role in the cluster
-				for _, roleName := range clusterPerm.Roles {
-					// Skip invalid roles
-					if roleName != "owner" && roleName != "member" {
-						klog.V(4).InfoS("Invalid cluster role, skipping", "username", userSetting.Username, "cluster", clusterName, "role", roleName)
-						continue
-					}
-					
-					// Create the relationship in OpenFGA
-					err := fgaService.GetClient().WriteTuple(ctx, userSetting.Username, roleName, "cluster", clusterName)
-					if err != nil {
-						klog.ErrorS(err, "Failed to set cluster permission", "username", userSetting.Username, "cluster", clusterName, "role", roleName)
-						// Continue anyway to avoid blocking the user creation due to permission issues
-					} else {
-						klog.InfoS("Added cluster permission", "username", userSetting.Username, "cluster", clusterName, "role", roleName)
-					}
+				err := cluster.GrantClusterPermission(ctx, fgaService.GetClient(), userSetting.Username, clusterPerm)
+				if err != nil {
+					klog.ErrorS(err, "Failed to set cluster permission", "username", userSetting.Username, "cluster", clusterPerm.Cluster)
+					// Continue anyway to avoid blocking the user creation due to permission issues
+				} else {
+					klog.InfoS("Added cluster permission", "username", userSetting.Username, "cluster", clusterPerm.Cluster)
 				}
 			}
 		}
@@ -306,16 +298,7 @@ func UpdateUserSetting(ctx context.Context, userSetting v1.UserSetting) error {
 		needsUpdate = true
 	}
 	
-	// If there's a password, update it
-	if password != "" {
-		err = userManager.UpdatePassword(ctx, userSetting.Username, password)
-		if err != nil {
-			return fmt.Errorf("failed to update password: %w", err)
-		}
-		klog.InfoS("Password updated for user", "username", userSetting.Username)
-	}
-	
-	// Update the user in etcd if needed
+	// Update the user in etcd if needed, before the password so that the stored user is not stale
 	if needsUpdate {
 		err = userManager.UpdateUser(ctx, user)
 		if err != nil {
@@ -324,6 +307,15 @@ func UpdateUserSetting(ctx context.Context, userSetting v1.UserSetting) error {
 		klog.InfoS("User updated in etcd", "username", userSetting.Username)
 	}
 
+	// If there's a password, update it
+	if password != "" {
+		err = auth.SetPassword(ctx, userSetting.Username, password)
+		if err != nil {
+			return fmt.Errorf("failed to update password: %w", err)
+		}
+		klog.InfoS("Password updated for user", "username", userSetting.Username)
+	}
+	
 	// Process cluster permissions with OpenFGA (if available)
 	if len(userSetting.ClusterPermissions) > 0 {
 		// Get FGA service
@@ -335,24 +327,12 @@ func UpdateUserSetting(ctx context.Context, userSetting v1.UserSetting) error {
 			
 			// For each cluster permission
 			for _, clusterPerm := range userSetting.ClusterPermissions {
-				clusterName := clusterPerm.Cluster
-				
-				// For each role in the cluster
-				for _, roleName := range clusterPerm.Roles {
-					// Skip invalid roles
-					if roleName != "owner" && roleName != "member" {
-						klog.V(4).InfoS("Invalid cluster role, skipping", "username", userSetting.Username, "cluster", clusterName, "role", roleName)
-						continue
-					}
-					
-					// Create the relationship in OpenFGA
-					err := fgaService.GetClient().WriteTuple(ctx, userSetting.Username, roleName, "cluster", clusterName)
-					if err != nil {
-						klog.ErrorS(err, "Failed to update cluster permission", "username", userSetting.Username, "cluster", clusterName, "role", roleName)
-						// Continue anyway to avoid blocking the user update due to permission issues
-					} else {
-						klog.InfoS("Updated cluster permission", "username", userSetting.Username, "cluster", clusterName, "role", roleName)
-					}
+				err := cluster.GrantClusterPermission(ctx, fgaService.GetClient(), userSetting.Username, clusterPerm)
+				if err != nil {
+					klog.ErrorS(err, "Failed to update cluster permission", "username", userSetting.Username, "cluster", clusterPerm.Cluster)
+					// Continue anyway to avoid blocking the user update due to permission issues
+				} else {
+					klog.InfoS("Updated cluster permission", "username", userSetting.Username, "cluster", clusterPerm.Cluster)
 				}
 			}
 		}
@@ -428,6 +408,16 @@ func DeleteUserSetting(ctx context.Context, username string) error {
 		klog.InfoS("User setting deleted", "username", username)
 	}
 
+	// Drop the user's group memberships so that groups do not keep granting it access
+	if err := removeUserFromGroups(ctx, username); err != nil {
+		klog.ErrorS(err, "Failed to remove user from groups", "username", username)
+	}
+
+	// API tokens act as the user, so they go with it
+	if err := auth.DeleteUserAPITokens(ctx, username); err != nil {
+		klog.ErrorS(err, "Failed to delete API tokens", "username", username)
+	}
+
 	// Now delete the user from etcd
 	userManager := etcd.NewUserManager(etcdClient)
 	err = userManager.DeleteUser(ctx, username)
@@ -478,6 +468,17 @@ func GetAllUsers(ctx context.Context) ([]v1.UserSetting, error) {
 		if userSetting.DisplayName == "" && etcdUser.Email != "" {
 			userSetting.DisplayName = etcdUser.Email
 		}
+
+		if etcdUser.Provider == "" {
+			userSetting.Account = &v1.AccountStatus{
+				Locked:             etcdUser.IsLocked(time.Now()),
+				LockedUntil:        etcdUser.LockedUntil,
+				FailedLogins:       etcdUser.FailedLogins,
+				MustChangePassword: etcdUser.MustChangePassword,
+				PasswordChangedAt:  etcdUser.PasswordChangedAt,
+				TwoFactorEnabled:   etcdUser.TOTPEnabled,
+			}
+		}
 		
 		userSettings = append(userSettings, *userSetting)
 	}
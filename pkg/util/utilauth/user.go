@@ -23,25 +23,27 @@ import (
 	
 	v1 "github.com/karmada-io/dashboard/cmd/api/app/types/api/v1"
 	"github.com/karmada-io/dashboard/pkg/auth"
-	"github.com/karmada-io/dashboard/pkg/client"
 )
 
 // GetAuthenticatedUser retrieves the username of the currently authenticated user
-// from the Gin context or Authorization header.
+// from the request context or Authorization header.
 func GetAuthenticatedUser(c *gin.Context) string {
-	// First check if user info is already in the context (may have been set by middleware)
+	// Prefer the identity resolved once per request by the router middleware
+	if username := auth.UserFromContext(c); username != "" {
+		return username
+	}
+
+	// Then check if user info is already in the context (may have been set by middleware)
 	user, exists := c.Get("user")
 	if exists {
 		// Check for User type
 		if userObj, ok := user.(*v1.User); ok && userObj.Name != "" {
-			client.SetCurrentUser(userObj.Name)
 			return userObj.Name
 		}
 
 		// Fallback to map for flexibility
 		if userMap, ok := user.(map[string]interface{}); ok {
 			if name, ok := userMap["Name"].(string); ok && name != "" {
-				client.SetCurrentUser(name)
 				return name
 			}
 		}
@@ -62,13 +64,20 @@ func GetAuthenticatedUser(c *gin.Context) string {
 	// Extract the token
 	tokenString := authHeader[len(prefix):]
 	
+	// API tokens act as their owner
+	if auth.IsAPIToken(tokenString) {
+		apiToken, err := auth.AuthenticateAPIToken(c, tokenString)
+		if err != nil {
+			return ""
+		}
+		return apiToken.Username
+	}
+
 	// Validate the token
 	claims, err := auth.ValidateToken(tokenString)
 	if err != nil {
 		return ""
 	}
 
-	// Store the username for use in non-context functions
-	client.SetCurrentUser(claims.Username)
 	return claims.Username
 }